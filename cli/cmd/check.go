@@ -1,27 +1,49 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/linkerd/linkerd2/pkg/healthcheck"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 const (
 	lineWidth   = 80
 	okStatus    = "[ok]"
 	retryStatus = "[retry]"
+	busyStatus  = "[....]"
+	warnStatus  = "[warn]"
 	failStatus  = "[FAIL]"
+
+	// hintBaseURL is the troubleshooting page that the HintAnchor of failed
+	// checks refers to.
+	hintBaseURL = "https://linkerd.io/checks/#"
+
+	tableOutput = "table"
+	jsonOutput  = "json"
+
+	// The values of the result of a check in the JSON output.
+	checkSuccess = "success"
+	checkWarning = "warning"
+	checkError   = "error"
 )
 
 type checkOptions struct {
 	versionOverride string
 	preInstallOnly  bool
 	dataPlaneOnly   bool
-	wait            bool
+	singleNamespace bool
+	enablePSP       bool
+	wait            time.Duration
 	namespace       string
+	output          string
+	categories      []string
 }
 
 func newCheckOptions() *checkOptions {
@@ -29,8 +51,12 @@ func newCheckOptions() *checkOptions {
 		versionOverride: "",
 		preInstallOnly:  false,
 		dataPlaneOnly:   false,
-		wait:            false,
+		singleNamespace: false,
+		enablePSP:       false,
+		wait:            0,
 		namespace:       "",
+		output:          tableOutput,
+		categories:      []string{},
 	}
 }
 
@@ -52,50 +78,117 @@ non-zero exit code.`,
   # Check that the Linkerd control plane can be installed in the "test" namespace
   linkerd check --pre --linkerd-namespace test
 
+  # Check that the Linkerd control plane can be installed with --single-namespace
+  linkerd check --pre --single-namespace --linkerd-namespace test
+
+  # Check that the Linkerd control plane can be installed with --enable-psp
+  linkerd check --pre --enable-psp
+
   # Check that the Linkerd data plane proxies in the "app" namespace are up and running
-  linkerd check --proxy --namespace app`,
+  linkerd check --proxy --namespace app
+
+  # Only run the checks of the Kubernetes version, and those they depend on
+  linkerd check --category kubernetes-version`,
 		Args: cobra.NoArgs,
-		Run: func(cmd *cobra.Command, args []string) {
-			configureAndRunChecks(options)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.output != tableOutput && options.output != jsonOutput {
+				return fmt.Errorf("output format \"%s\" not recognized, must be one of: %s, %s", options.output, tableOutput, jsonOutput)
+			}
+			return configureAndRunChecks(options)
 		},
 	}
 
 	cmd.Args = cobra.NoArgs
 	cmd.PersistentFlags().StringVar(&options.versionOverride, "expected-version", options.versionOverride, "Overrides the version used when checking if Linkerd is running the latest version (mostly for testing)")
 	cmd.PersistentFlags().BoolVar(&options.preInstallOnly, "pre", options.preInstallOnly, "Only run pre-installation checks, to determine if the control plane can be installed")
+	cmd.PersistentFlags().BoolVar(&options.singleNamespace, "single-namespace", options.singleNamespace, "Check a control plane installed, or to be installed, with --single-namespace, skipping the checks that require cluster-wide access")
+	cmd.PersistentFlags().BoolVar(&options.enablePSP, "enable-psp", options.enablePSP, "Check that the control plane can be installed with --enable-psp")
 	cmd.PersistentFlags().BoolVar(&options.dataPlaneOnly, "proxy", options.dataPlaneOnly, "Only run data-plane checks, to determine if the data plane is healthy")
-	cmd.PersistentFlags().BoolVar(&options.wait, "wait", false, "Retry and wait for some checks to succeed if they don't pass the first time")
+	cmd.PersistentFlags().DurationVar(&options.wait, "wait", options.wait, fmt.Sprintf("Retry checks that can fail transiently, such as pods becoming ready, until they succeed or the duration expires (%s if no duration is given)", defaultWaitTimeout))
+	cmd.PersistentFlags().Lookup("wait").NoOptDefVal = defaultWaitTimeout.String()
 	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace to use for --proxy checks (default: all namespaces)")
+	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, json")
+	cmd.PersistentFlags().StringSliceVar(&options.categories, "category", options.categories, "Only run the checks of this category, and of the categories it depends on (can be repeated)")
 
 	return cmd
 }
 
-func configureAndRunChecks(options *checkOptions) {
-	checks := []healthcheck.Checks{healthcheck.KubernetesAPIChecks}
+// checksFor returns the checks that options select: the pre-install checks
+// with --pre, the data plane checks with --proxy, and the control plane checks
+// otherwise.
+func checksFor(options *checkOptions) []healthcheck.Checks {
+	checks := []healthcheck.Checks{
+		healthcheck.KubernetesAPIChecks,
+		healthcheck.KubernetesClockChecks,
+		healthcheck.KubernetesDistributionChecks,
+	}
+
+	// Listing nodes requires cluster-wide access, which --single-namespace
+	// installs do without.
+	nodeChecks := []healthcheck.Checks{healthcheck.KubernetesNodeChecks}
+	if options.singleNamespace {
+		nodeChecks = nil
+	}
 
 	if options.preInstallOnly {
+		checks = append(checks, nodeChecks...)
 		checks = append(checks, healthcheck.LinkerdPreInstallChecks)
 		checks = append(checks, healthcheck.LinkerdVersionChecks)
 	} else if options.dataPlaneOnly {
 		checks = append(checks, healthcheck.LinkerdAPIChecks)
+		checks = append(checks, healthcheck.LinkerdControlPlaneChecks)
+		checks = append(checks, healthcheck.LinkerdProxyInjectorChecks)
 		checks = append(checks, healthcheck.LinkerdDataPlaneChecks)
 	} else {
+		checks = append(checks, nodeChecks...)
 		checks = append(checks, healthcheck.LinkerdAPIChecks)
+		checks = append(checks, healthcheck.LinkerdControlPlaneChecks)
+		checks = append(checks, healthcheck.LinkerdProxyInjectorChecks)
 		checks = append(checks, healthcheck.LinkerdVersionChecks)
 	}
 
+	return checks
+}
+
+func configureAndRunChecks(options *checkOptions) error {
+	checks := checksFor(options)
+
+	var retryDeadline time.Time
+	if options.wait > 0 {
+		retryDeadline = time.Now().Add(options.wait)
+	}
+
 	hc := healthcheck.NewHealthChecker(checks, &healthcheck.HealthCheckOptions{
 		ControlPlaneNamespace:        controlPlaneNamespace,
 		DataPlaneNamespace:           options.namespace,
 		KubeConfig:                   kubeconfigPath,
+		KubeAPIOptions:               kubeAPIOptions(),
 		APIAddr:                      apiAddr,
 		VersionOverride:              options.versionOverride,
-		ShouldRetry:                  options.wait,
+		RetryDeadline:                retryDeadline,
 		ShouldCheckKubeVersion:       true,
 		ShouldCheckControllerVersion: !options.preInstallOnly,
+		SingleNamespace:              options.singleNamespace,
+		EnablePSP:                    options.enablePSP,
 	})
 
-	success := runChecks(os.Stdout, hc)
+	if len(options.categories) > 0 {
+		if err := hc.SelectCategories(options.categories); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	if options.output == jsonOutput {
+		if !runChecksJSON(ctx, os.Stdout, os.Stderr, hc) {
+			os.Exit(2)
+		}
+		return nil
+	}
+
+	success := runChecks(ctx, os.Stdout, hc)
 
 	fmt.Println("")
 
@@ -105,9 +198,16 @@ func configureAndRunChecks(options *checkOptions) {
 	}
 
 	fmt.Printf("Status check results are %s\n", okStatus)
+	return nil
 }
 
-func runChecks(w io.Writer, hc *healthcheck.HealthChecker) bool {
+// runChecks prints the result of each check to w. On a terminal, the status
+// of a check that is being retried, or that reports its progress, is updated
+// in place. Elsewhere, progress is not printed.
+func runChecks(ctx context.Context, w io.Writer, hc *healthcheck.HealthChecker) bool {
+	inPlace := isTerminal(w)
+	pending := false
+
 	prettyPrintResults := func(result *healthcheck.CheckResult) {
 		checkLabel := fmt.Sprintf("%s: %s", result.Category, result.Description)
 
@@ -117,18 +217,134 @@ func runChecks(w io.Writer, hc *healthcheck.HealthChecker) bool {
 			filler = filler + "."
 		}
 
+		if pending {
+			// Clear the status of the previous attempt, or progress.
+			fmt.Fprint(w, "\r\033[K")
+			pending = false
+		}
+
+		if result.Progress != "" {
+			if inPlace {
+				fmt.Fprintf(w, "%s%s%s -- %s", checkLabel, filler, busyStatus, result.Progress)
+				pending = true
+			}
+			return
+		}
+
 		if result.Retry {
-			fmt.Fprintf(w, "%s%s%s -- %s%s", checkLabel, filler, retryStatus, result.Err, lineBreak)
+			if inPlace {
+				fmt.Fprintf(w, "%s%s%s -- attempt %d: %s", checkLabel, filler, retryStatus, result.Attempt, result.Err)
+				pending = true
+				return
+			}
+			fmt.Fprintf(w, "%s%s%s -- attempt %d: %s%s", checkLabel, filler, retryStatus, result.Attempt, result.Err, lineBreak)
+			return
+		}
+
+		if result.Err != nil && result.Warning {
+			fmt.Fprintf(w, "%s%s%s -- %s%s", checkLabel, filler, warnStatus, result.Err, lineBreak)
+			printHint(w, result)
 			return
 		}
 
 		if result.Err != nil {
 			fmt.Fprintf(w, "%s%s%s -- %s%s", checkLabel, filler, failStatus, result.Err, lineBreak)
+			printHint(w, result)
 			return
 		}
 
 		fmt.Fprintf(w, "%s%s%s%s", checkLabel, filler, okStatus, lineBreak)
 	}
 
-	return hc.RunChecks(prettyPrintResults)
+	return hc.RunChecks(ctx, prettyPrintResults)
+}
+
+// checkOutputJSON is the document written by `linkerd check -o json`.
+type checkOutputJSON struct {
+	Success    bool                 `json:"success"`
+	Categories []*checkCategoryJSON `json:"categories"`
+}
+
+type checkCategoryJSON struct {
+	Name   string             `json:"categoryName"`
+	Checks []*checkResultJSON `json:"checks"`
+}
+
+type checkResultJSON struct {
+	Description string `json:"description"`
+	Hint        string `json:"hint,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Result      string `json:"result"`
+}
+
+// add records the final result of a check, grouping it with the other checks
+// of its category, in the order the categories were first seen.
+func (o *checkOutputJSON) add(result *healthcheck.CheckResult) {
+	var category *checkCategoryJSON
+	for _, c := range o.Categories {
+		if c.Name == string(result.Category) {
+			category = c
+		}
+	}
+	if category == nil {
+		category = &checkCategoryJSON{Name: string(result.Category), Checks: []*checkResultJSON{}}
+		o.Categories = append(o.Categories, category)
+	}
+
+	check := &checkResultJSON{Description: result.Description, Result: checkSuccess}
+	if result.Err != nil {
+		check.Error = result.Err.Error()
+		check.Result = checkError
+		if result.Warning {
+			check.Result = checkWarning
+		}
+		if result.HintAnchor != "" {
+			check.Hint = hintBaseURL + result.HintAnchor
+		}
+	}
+	category.Checks = append(category.Checks, check)
+}
+
+// runChecksJSON writes the results of the checks to w as a single JSON
+// document once all of them have run. Progress, such as the retries of
+// failing checks, is written to progress, so that w only contains JSON.
+func runChecksJSON(ctx context.Context, w, progress io.Writer, hc *healthcheck.HealthChecker) bool {
+	output := &checkOutputJSON{Categories: []*checkCategoryJSON{}}
+	output.Success = hc.RunChecks(ctx, func(result *healthcheck.CheckResult) {
+		if result.Progress != "" {
+			fmt.Fprintf(progress, "%s: %s -- %s\n", result.Category, result.Description, result.Progress)
+			return
+		}
+		if result.Retry {
+			fmt.Fprintf(progress, "%s: %s -- attempt %d: %s\n", result.Category, result.Description, result.Attempt, result.Err)
+			return
+		}
+		output.add(result)
+	})
+
+	if err := output.render(w); err != nil {
+		fmt.Fprintf(progress, "Error rendering check results: %s\n", err)
+		return false
+	}
+	return output.Success
+}
+
+func (o *checkOutputJSON) render(w io.Writer) error {
+	out, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", out)
+	return err
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
+func printHint(w io.Writer, result *healthcheck.CheckResult) {
+	if result.HintAnchor != "" {
+		fmt.Fprintf(w, "    see %s%s for hints\n", hintBaseURL, result.HintAnchor)
+	}
 }
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"testing"
@@ -23,7 +25,7 @@ func TestCheckStatus(t *testing.T) {
 		})
 
 		output := bytes.NewBufferString("")
-		runChecks(output, hc)
+		runChecks(context.Background(), output, hc)
 
 		goldenFileBytes, err := ioutil.ReadFile("testdata/check_output.golden")
 		if err != nil {
@@ -36,4 +38,89 @@ func TestCheckStatus(t *testing.T) {
 			t.Fatalf("Expected function to render:\n%s\bbut got:\n%s", expectedContent, output)
 		}
 	})
+
+	t.Run("Prints expected JSON output", func(t *testing.T) {
+		output := &checkOutputJSON{Categories: []*checkCategoryJSON{}}
+		output.add(&healthcheck.CheckResult{Category: "kubernetes-api", Description: "can query the Kubernetes API"})
+		output.add(&healthcheck.CheckResult{Category: "kubernetes-clock", Description: "clock is in sync with the Kubernetes API", HintAnchor: "k8s-api-clock-skew", Warning: true, Err: fmt.Errorf("the local clock is 2m0s behind the Kubernetes API server, more than the 1m0s allowed")})
+		output.add(&healthcheck.CheckResult{Category: "linkerd-api", Description: "control plane pods are ready", Err: fmt.Errorf("No running pods for \"web\"")})
+
+		buf := bytes.NewBufferString("")
+		if err := output.render(buf); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		goldenFileBytes, err := ioutil.ReadFile("testdata/check_output_json.golden")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedContent := string(goldenFileBytes)
+
+		if expectedContent != buf.String() {
+			t.Fatalf("Expected function to render:\n%s\bbut got:\n%s", expectedContent, buf)
+		}
+	})
+
+	t.Run("Writes only JSON to the output", func(t *testing.T) {
+		hc := healthcheck.NewHealthChecker(
+			[]healthcheck.Checks{},
+			&healthcheck.HealthCheckOptions{},
+		)
+		hc.Add("category", "check1", func() error {
+			return nil
+		})
+		hc.Add("category", "check2", func() error {
+			return fmt.Errorf("This should contain instructions for fail")
+		})
+
+		output := bytes.NewBufferString("")
+		progress := bytes.NewBufferString("")
+		if runChecksJSON(context.Background(), output, progress, hc) {
+			t.Fatalf("Expected checks to fail")
+		}
+
+		var result checkOutputJSON
+		if err := json.Unmarshal(output.Bytes(), &result); err != nil {
+			t.Fatalf("Expected output to be JSON, got [%s]: %v", output, err)
+		}
+		if result.Success || len(result.Categories) != 1 || len(result.Categories[0].Checks) != 2 {
+			t.Fatalf("Unexpected output [%s]", output)
+		}
+		if progress.Len() != 0 {
+			t.Fatalf("Expected no progress output, got [%s]", progress)
+		}
+	})
+
+	t.Run("Writes only the selected categories to the JSON output", func(t *testing.T) {
+		hc := healthcheck.NewHealthChecker(
+			[]healthcheck.Checks{},
+			&healthcheck.HealthCheckOptions{},
+		)
+		hc.Add(healthcheck.KubernetesAPICategory, "can query the Kubernetes API", func() error {
+			return nil
+		})
+		hc.Add(healthcheck.KubernetesVersionCategory, "is running the minimum Kubernetes API version", func() error {
+			return nil
+		})
+		hc.Add(healthcheck.LinkerdAPICategory, "can query the control plane API", func() error {
+			return nil
+		})
+		if err := hc.SelectCategories([]string{"kubernetes-version"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output := bytes.NewBufferString("")
+		if !runChecksJSON(context.Background(), output, ioutil.Discard, hc) {
+			t.Fatalf("Expected checks to pass")
+		}
+
+		var result checkOutputJSON
+		if err := json.Unmarshal(output.Bytes(), &result); err != nil {
+			t.Fatalf("Expected output to be JSON, got [%s]: %v", output, err)
+		}
+		if len(result.Categories) != 1 || result.Categories[0].Name != "kubernetes-version" {
+			t.Fatalf("Expected only the kubernetes-version category, got [%s]", output)
+		}
+	})
 }
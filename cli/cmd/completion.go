@@ -2,12 +2,100 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/linkerd/linkerd2/controller/api/util"
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// completionTimeout bounds the lookups of the values suggested by the
+// completion scripts, so that an unreachable cluster never hangs the shell.
+const completionTimeout = 2 * time.Second
+
+// The kinds of values that `linkerd __complete` returns.
+const (
+	completeNamespaces      = "namespaces"
+	completeResources       = "resources"
+	completeToResources     = "to-resources"
+	completeFromResources   = "from-resources"
+	completeCheckCategories = "check-categories"
+)
+
+// completionFlagKinds are the kinds of the values of the flags, by name, that
+// are completed dynamically.
+var completionFlagKinds = map[string]string{
+	"linkerd-namespace": completeNamespaces,
+	"namespace":         completeNamespaces,
+	"to-namespace":      completeNamespaces,
+	"from-namespace":    completeNamespaces,
+	"to":                completeToResources,
+	"from":              completeFromResources,
+	"category":          completeCheckCategories,
+}
+
+// completionResourceCommands are the commands whose arguments are a resource,
+// and are completed dynamically.
+var completionResourceCommands = []string{"metrics", "routes", "stat", "tap", "top"}
+
+// bashCompletionFunctions are included in the bash completion script. The
+// values of the flags listed in completionFlagKinds, and the arguments of
+// completionResourceCommands, are those `linkerd __complete` returns for the
+// words of the command line.
+const bashCompletionFunctions = `__linkerd_complete()
+{
+    local values
+    values=$(linkerd __complete "$1" -- "${words[@]:1:$((cword-1))}" "${cur}" 2>/dev/null)
+    COMPREPLY=( $(compgen -W "${values}" -- "${cur}") )
+}
+
+__custom_func()
+{
+    case ${last_command} in
+        %s)
+            __linkerd_complete ` + completeResources + `
+            ;;
+    esac
+}
+`
+
+// fishCompletionFunctions are included in the fish completion script, as
+// bashCompletionFunctions are in the bash one. __linkerd_command_path returns
+// the subcommands of the command line, as listed in __linkerd_commands.
+const fishCompletionFunctions = `function __linkerd_command_path
+    set -l words (commandline -opc)
+    set -e words[1]
+    set -l path
+    for word in $words
+        set -l next (string trim -- "$path $word")
+        if contains -- $next $__linkerd_commands
+            set path $next
+        end
+    end
+    echo $path
+end
+
+function __linkerd_using_command
+    set -l path (__linkerd_command_path)
+    test "$path" = "$argv"
+end
+
+function __linkerd_complete
+    set -l words (commandline -opc)
+    set -e words[1]
+    linkerd __complete $argv[1] -- $words (commandline -ct) 2>/dev/null
+end
+`
+
 func newCmdCompletion() *cobra.Command {
 	example := `  # bash <= 3.2
   source /dev/stdin <<< "$(linkerd completion bash)"
@@ -27,15 +115,24 @@ func newCmdCompletion() *cobra.Command {
   source <(linkerd completion zsh)
 
   # zsh on osx / oh-my-zsh
-  linkerd completion zsh > "${fpath[1]}/_linkerd"`
+  linkerd completion zsh > "${fpath[1]}/_linkerd"
+
+  # fish
+  linkerd completion fish > ~/.config/fish/completions/linkerd.fish`
 
 	cmd := &cobra.Command{
-		Use:       "completion [bash|zsh]",
-		Short:     "Shell completion",
-		Long:      "Output completion code for the specified shell (bash or zsh).",
+		Use:   "completion [bash|zsh|fish]",
+		Short: "Shell completion",
+		Long: `Output completion code for the specified shell (bash, zsh or fish).
+
+The bash and fish completions also suggest the namespaces, and the resources of
+the stat, tap, top, routes and metrics commands, by querying the Kubernetes
+API, as well as the categories of the checks of linkerd check. The queries
+time out after a couple of seconds, and suggest nothing if the cluster cannot
+be reached.`,
 		Example:   example,
 		Args:      cobra.ExactArgs(1),
-		ValidArgs: []string{"bash", "zsh"},
+		ValidArgs: []string{"bash", "zsh", "fish"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			out, err := getCompletion(args[0], cmd.Parent())
 			if err != nil {
@@ -54,13 +151,18 @@ func getCompletion(sh string, parent *cobra.Command) (string, error) {
 	var err error
 	var buf bytes.Buffer
 
+	annotateCompletionFlags(parent)
+
 	switch sh {
 	case "bash":
+		parent.BashCompletionFunction = fmt.Sprintf(bashCompletionFunctions, bashCommandNames(parent, completionResourceCommands))
 		err = parent.GenBashCompletion(&buf)
 	case "zsh":
 		err = parent.GenZshCompletion(&buf)
+	case "fish":
+		err = genFishCompletion(&buf, parent)
 	default:
-		err = errors.New("unsupported shell type (must be bash or zsh): " + sh)
+		err = errors.New("unsupported shell type (must be bash, zsh or fish): " + sh)
 	}
 
 	if err != nil {
@@ -69,3 +171,411 @@ func getCompletion(sh string, parent *cobra.Command) (string, error) {
 
 	return buf.String(), nil
 }
+
+// annotateCompletionFlags marks the flags of completionFlagKinds of the
+// commands under root as completed by `linkerd __complete`, for the bash
+// completion script.
+func annotateCompletionFlags(root *cobra.Command) {
+	for _, cmd := range allCommands(root) {
+		for _, flags := range []*pflag.FlagSet{cmd.PersistentFlags(), cmd.Flags()} {
+			flags.VisitAll(func(flag *pflag.Flag) {
+				if kind, ok := completionFlagKinds[flag.Name]; ok {
+					flags.SetAnnotation(flag.Name, cobra.BashCompCustom, []string{"__linkerd_complete " + kind})
+				}
+			})
+		}
+	}
+}
+
+// allCommands returns root and the available commands under it, depth first.
+func allCommands(root *cobra.Command) []*cobra.Command {
+	commands := []*cobra.Command{root}
+	for _, cmd := range root.Commands() {
+		if cmd.IsAvailableCommand() {
+			commands = append(commands, allCommands(cmd)...)
+		}
+	}
+	return commands
+}
+
+// bashCommandNames returns the case pattern matching the last_command of the
+// given subcommands of root in the bash completion script.
+func bashCommandNames(root *cobra.Command, names []string) string {
+	patterns := make([]string, len(names))
+	for i, name := range names {
+		patterns[i] = root.Name() + "_" + name
+	}
+	return strings.Join(patterns, " | ")
+}
+
+// genFishCompletion writes the fish completion script of root to w. The
+// subcommands and flags of each command are only suggested once the command
+// line names it, except for the persistent flags of root, which apply to all
+// commands.
+func genFishCompletion(w io.Writer, root *cobra.Command) error {
+	var buf bytes.Buffer
+	name := root.Name()
+
+	fmt.Fprintf(&buf, "# fish completion for %s\n\n", name)
+
+	var paths []string
+	for _, cmd := range allCommands(root)[1:] {
+		paths = append(paths, fishQuote(commandPath(cmd)))
+	}
+	fmt.Fprintf(&buf, "set -g __linkerd_commands %s\n\n", strings.Join(paths, " "))
+	buf.WriteString(fishCompletionFunctions)
+	buf.WriteString("\n")
+
+	root.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		writeFishFlag(&buf, name, "", flag)
+	})
+
+	for _, cmd := range allCommands(root) {
+		path := commandPath(cmd)
+		condition := strings.TrimSpace("__linkerd_using_command " + path)
+
+		for _, sub := range cmd.Commands() {
+			if sub.IsAvailableCommand() {
+				fmt.Fprintf(&buf, "complete -c %s -f -n %s -a %s -d %s\n", name, fishQuote(condition), sub.Name(), fishQuote(sub.Short))
+			}
+		}
+		if len(cmd.ValidArgs) > 0 {
+			validArgs := append([]string{}, cmd.ValidArgs...)
+			sort.Strings(validArgs)
+			fmt.Fprintf(&buf, "complete -c %s -f -n %s -a %s\n", name, fishQuote(condition), fishQuote(strings.Join(validArgs, " ")))
+		}
+		if cmd.Parent() == root && containsString(completionResourceCommands, cmd.Name()) {
+			fmt.Fprintf(&buf, "complete -c %s -f -n %s -a %s\n", name, fishQuote(condition), fishQuote("(__linkerd_complete "+completeResources+")"))
+		}
+
+		if cmd != root {
+			cmd.NonInheritedFlags().VisitAll(func(flag *pflag.Flag) {
+				writeFishFlag(&buf, name, condition, flag)
+			})
+			cmd.InheritedFlags().VisitAll(func(flag *pflag.Flag) {
+				if root.PersistentFlags().Lookup(flag.Name) == nil {
+					writeFishFlag(&buf, name, condition, flag)
+				}
+			})
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func writeFishFlag(w io.Writer, name, condition string, flag *pflag.Flag) {
+	if flag.Hidden || flag.Deprecated != "" {
+		return
+	}
+
+	line := "complete -c " + name
+	if condition != "" {
+		line += " -n " + fishQuote(condition)
+	}
+	line += " -l " + flag.Name
+	if flag.Shorthand != "" {
+		line += " -s " + flag.Shorthand
+	}
+	if kind, ok := completionFlagKinds[flag.Name]; ok {
+		line += " -x -a " + fishQuote("(__linkerd_complete "+kind+")")
+	} else if flag.NoOptDefVal == "" {
+		line += " -r"
+	}
+	fmt.Fprintln(w, line+" -d "+fishQuote(flag.Usage))
+}
+
+// commandPath returns the path of cmd under the root command, e.g. "get pods".
+func commandPath(cmd *cobra.Command) string {
+	if !cmd.HasParent() {
+		return ""
+	}
+	if parent := cmd.Parent(); parent.HasParent() {
+		return commandPath(parent) + " " + cmd.Name()
+	}
+	return cmd.Name()
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+func newCmdComplete() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "__complete KIND -- [WORDS]",
+		Short: "Print the values suggested by the completion scripts",
+		Long: `Print the values suggested by the completion scripts.
+
+WORDS are the words of the command line being completed, without the "linkerd"
+command; the last one is the word being completed, possibly empty. KIND is one
+of: namespaces, resources, to-resources, from-resources, check-categories.
+Errors are not reported, as there is nothing to suggest then.`,
+		Hidden:             true,
+		DisableFlagParsing: true,
+		Args:               cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			words := args[1:]
+			if len(words) > 0 && words[0] == "--" {
+				words = words[1:]
+			}
+			line := parseCompletionLine(cmd.Root(), words)
+
+			ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+			defer cancel()
+
+			values, err := completeValues(ctx, line, args[0], newCompletionAPI)
+			if err != nil {
+				return err
+			}
+			for _, value := range values {
+				fmt.Println(value)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// completionLine is a command line being completed.
+type completionLine struct {
+	cmd   *cobra.Command
+	flags *pflag.FlagSet
+	// args are the arguments of cmd before cur, the word being completed.
+	args []string
+	cur  string
+}
+
+// completionFlagValue is set to the value of a flag of the command line being
+// completed, whatever its type.
+type completionFlagValue struct {
+	value string
+}
+
+func (v *completionFlagValue) String() string     { return v.value }
+func (v *completionFlagValue) Set(s string) error { v.value = s; return nil }
+func (v *completionFlagValue) Type() string       { return "string" }
+
+// parseCompletionLine returns the command of root that words name, and the
+// flags and arguments passed to it. Errors are ignored, so that whatever
+// precedes them is still used: the command line is incomplete anyway.
+func parseCompletionLine(root *cobra.Command, words []string) *completionLine {
+	line := &completionLine{cmd: root, flags: pflag.NewFlagSet(root.Name(), pflag.ContinueOnError)}
+	if len(words) == 0 {
+		return line
+	}
+	line.cur = words[len(words)-1]
+	// the values of flags given as --flag=value are completed without it
+	if strings.HasPrefix(line.cur, "-") && strings.Contains(line.cur, "=") {
+		line.cur = line.cur[strings.Index(line.cur, "=")+1:]
+	}
+
+	cmd, args, _ := root.Find(words[:len(words)-1])
+	if cmd == nil {
+		return line
+	}
+	line.cmd = cmd
+
+	line.flags.SetOutput(ioutil.Discard)
+	for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.PersistentFlags(), cmd.InheritedFlags()} {
+		flags.VisitAll(func(flag *pflag.Flag) {
+			if line.flags.Lookup(flag.Name) != nil {
+				return
+			}
+			line.flags.AddFlag(&pflag.Flag{
+				Name:        flag.Name,
+				Shorthand:   flag.Shorthand,
+				NoOptDefVal: flag.NoOptDefVal,
+				Value:       &completionFlagValue{value: flag.DefValue},
+			})
+		})
+	}
+	line.flags.Parse(args)
+	line.args = line.flags.Args()
+
+	return line
+}
+
+// flag returns the value of the named flag of the command line, or its
+// default value, or an empty string if the command has no such flag.
+func (l *completionLine) flag(name string) string {
+	if flag := l.flags.Lookup(name); flag != nil {
+		return flag.Value.String()
+	}
+	return ""
+}
+
+// namespace returns the namespace of the resources of the command line: that
+// of the first of the named flags that is set, or "default".
+func (l *completionLine) namespace(flags ...string) string {
+	for _, flag := range flags {
+		if namespace := l.flag(flag); namespace != "" {
+			return namespace
+		}
+	}
+	return "default"
+}
+
+// newCompletionAPI returns a client of the Kubernetes API configured by the
+// global flags of line, without retries, which would only delay suggesting
+// nothing.
+func newCompletionAPI(line *completionLine) (*k8s.KubernetesAPI, error) {
+	line.cmd.Root().PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if parsed := line.flags.Lookup(flag.Name); parsed != nil && parsed.Changed {
+			flag.Value.Set(parsed.Value.String())
+		}
+	})
+
+	options := kubeAPIOptions()
+	options.Timeout = completionTimeout
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, options)
+	if err != nil {
+		return nil, err
+	}
+	kubeAPI.RetryAttempts = 1
+	return kubeAPI, nil
+}
+
+// completeValues returns the values of kind suggested for the word being
+// completed of line. The names of resources are sorted, and none are returned
+// if they cannot be listed.
+func completeValues(ctx context.Context, line *completionLine, kind string, newAPI func(*completionLine) (*k8s.KubernetesAPI, error)) ([]string, error) {
+	switch kind {
+	case completeCheckCategories:
+		return checkCategoryValues(line), nil
+	case completeNamespaces, completeResources, completeToResources, completeFromResources:
+	default:
+		return nil, fmt.Errorf("unsupported completion kind [%s]", kind)
+	}
+
+	kubeAPI, err := newAPI(line)
+	if err != nil {
+		return nil, nil
+	}
+
+	switch kind {
+	case completeNamespaces:
+		return resourceNames(ctx, kubeAPI, k8s.Namespace, ""), nil
+	case completeToResources:
+		return resourceValues(ctx, kubeAPI, util.ValidDestinations, line.namespace("to-namespace", "namespace"), line.cur), nil
+	case completeFromResources:
+		return resourceValues(ctx, kubeAPI, util.ValidDestinations, line.namespace("from-namespace", "namespace"), line.cur), nil
+	}
+
+	namespace := line.namespace("namespace")
+	switch len(line.args) {
+	case 0:
+		return resourceValues(ctx, kubeAPI, util.ValidTargets, namespace, line.cur), nil
+	case 1:
+		// the name of a resource of the type given as the first argument
+		resourceType, err := k8s.CanonicalResourceNameFromFriendlyName(line.args[0])
+		if err != nil || strings.Contains(line.cur, "/") {
+			return nil, nil
+		}
+		return resourceNames(ctx, kubeAPI, resourceType, namespace), nil
+	}
+	return nil, nil
+}
+
+// resourceValues returns the values of a resource argument suggested for cur:
+// the names of the resources of the type cur starts with, such as
+// "deploy/web", or the given types otherwise.
+func resourceValues(ctx context.Context, kubeAPI *k8s.KubernetesAPI, types []string, namespace, cur string) []string {
+	slash := strings.Index(cur, "/")
+	if slash < 0 {
+		values := append([]string{}, types...)
+		sort.Strings(values)
+		return values
+	}
+
+	resourceType, err := k8s.CanonicalResourceNameFromFriendlyName(cur[:slash])
+	if err != nil {
+		return nil
+	}
+	var values []string
+	for _, name := range resourceNames(ctx, kubeAPI, resourceType, namespace) {
+		values = append(values, cur[:slash+1]+name)
+	}
+	return values
+}
+
+// completionResourceTypes are the types of the resources whose names are
+// suggested, other than namespaces, deployments and pods.
+var completionResourceTypes = map[string]k8s.ResourceType{
+	k8s.ReplicationController: {Kind: "ReplicationController", Resource: "replicationcontrollers", Version: "v1", Namespaced: true},
+	k8s.Service:               {Kind: "Service", Resource: "services", Version: "v1", Namespaced: true},
+}
+
+// resourceNames returns the names of the resources of resourceType in
+// namespace, sorted, or none if they cannot be listed.
+func resourceNames(ctx context.Context, kubeAPI *k8s.KubernetesAPI, resourceType, namespace string) []string {
+	var names []string
+	switch resourceType {
+	case k8s.Namespace:
+		namespaces, err := kubeAPI.ListNamespaces(ctx, "")
+		if err != nil {
+			return nil
+		}
+		for _, ns := range namespaces {
+			names = append(names, ns.Name)
+		}
+	case k8s.Deployment:
+		deployments, err := kubeAPI.ListDeployments(ctx, namespace, "")
+		if err != nil {
+			return nil
+		}
+		for _, deployment := range deployments {
+			names = append(names, deployment.Name)
+		}
+	case k8s.Pod:
+		pods, err := kubeAPI.GetPodsFor(ctx, namespace, "")
+		if err != nil {
+			return nil
+		}
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+	default:
+		listed, ok := completionResourceTypes[resourceType]
+		if !ok {
+			return nil
+		}
+		resources, err := kubeAPI.ListResources(ctx, listed, namespace, "")
+		if err != nil {
+			return nil
+		}
+		for _, resource := range resources {
+			names = append(names, resource.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkCategoryValues returns the categories of the checks that linkerd check
+// runs with the flags of line, in the order they run.
+func checkCategoryValues(line *completionLine) []string {
+	options := newCheckOptions()
+	options.preInstallOnly = line.flag("pre") == "true"
+	options.dataPlaneOnly = line.flag("proxy") == "true"
+	options.singleNamespace = line.flag("single-namespace") == "true"
+
+	hc := healthcheck.NewHealthChecker(checksFor(options), &healthcheck.HealthCheckOptions{
+		ShouldCheckKubeVersion:       true,
+		ShouldCheckControllerVersion: !options.preInstallOnly,
+	})
+	var categories []string
+	for _, category := range hc.Categories() {
+		categories = append(categories, string(category))
+	}
+	return categories
+}
@@ -1,8 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
 )
 
 func TestCompletion(t *testing.T) {
@@ -18,6 +28,11 @@ func TestCompletion(t *testing.T) {
 			t.Fatalf("Unexpected error: %+v", err)
 		}
 
+		fish, err := getCompletion("fish", RootCmd)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+
 		if !strings.Contains(bash, "# bash completion for linkerd") {
 			t.Fatalf("Unexpected bash output: %+v", bash)
 		}
@@ -25,6 +40,48 @@ func TestCompletion(t *testing.T) {
 		if !strings.Contains(zsh, "#compdef linkerd") {
 			t.Fatalf("Unexpected zsh output: %+v", zsh)
 		}
+
+		if !strings.Contains(fish, "# fish completion for linkerd") {
+			t.Fatalf("Unexpected fish output: %+v", fish)
+		}
+	})
+
+	t.Run("Completes namespaces, resources and check categories dynamically", func(t *testing.T) {
+		bash, err := getCompletion("bash", RootCmd)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		for _, expected := range []string{
+			"        linkerd_metrics | linkerd_routes | linkerd_stat | linkerd_tap | linkerd_top)\n            __linkerd_complete resources\n",
+			"    flags_with_completion+=(\"--linkerd-namespace\")\n    flags_completion+=(\"__linkerd_complete namespaces\")\n",
+			"    flags_with_completion+=(\"--to\")\n    flags_completion+=(\"__linkerd_complete to-resources\")\n",
+			"    flags_with_completion+=(\"--category\")\n    flags_completion+=(\"__linkerd_complete check-categories\")\n",
+		} {
+			if !strings.Contains(bash, expected) {
+				t.Fatalf("Expected bash output to contain [%s]", expected)
+			}
+		}
+
+		fish, err := getCompletion("fish", RootCmd)
+		if err != nil {
+			t.Fatalf("Unexpected error: %+v", err)
+		}
+		for _, expected := range []string{
+			"complete -c linkerd -l linkerd-namespace -s l -x -a '(__linkerd_complete namespaces)' -d 'Namespace in which Linkerd is installed'\n",
+			"complete -c linkerd -f -n '__linkerd_using_command' -a stat -d 'Display traffic stats about one or many resources'\n",
+			"complete -c linkerd -f -n '__linkerd_using_command stat' -a '(__linkerd_complete resources)'\n",
+			"complete -c linkerd -n '__linkerd_using_command stat' -l namespace -s n -x -a '(__linkerd_complete namespaces)' -d 'Namespace of the specified resource'\n",
+			"complete -c linkerd -n '__linkerd_using_command check' -l category -x -a '(__linkerd_complete check-categories)' -d ",
+			"complete -c linkerd -n '__linkerd_using_command check' -l pre -d ",
+			"complete -c linkerd -f -n '__linkerd_using_command completion' -a 'bash fish zsh'\n",
+		} {
+			if !strings.Contains(fish, expected) {
+				t.Fatalf("Expected fish output to contain [%s]", expected)
+			}
+		}
+		if strings.Contains(fish, "__complete") && strings.Contains(fish, "-a __complete") {
+			t.Fatalf("Expected the hidden __complete command not to be suggested")
+		}
 	})
 
 	t.Run("Fails with invalid shell type", func(t *testing.T) {
@@ -34,3 +91,133 @@ func TestCompletion(t *testing.T) {
 		}
 	})
 }
+
+func TestParseCompletionLine(t *testing.T) {
+	t.Run("Parses the command, flags and arguments before the word being completed", func(t *testing.T) {
+		line := parseCompletionLine(RootCmd, []string{"-l", "linkerd-test", "stat", "-n", "emojivoto", "deploy", "we"})
+		if line.cmd.Name() != "stat" {
+			t.Fatalf("Expected command [stat], got [%s]", line.cmd.Name())
+		}
+		if !reflect.DeepEqual(line.args, []string{"deploy"}) {
+			t.Fatalf("Expected arguments [deploy], got %v", line.args)
+		}
+		if line.cur != "we" {
+			t.Fatalf("Expected the word being completed to be [we], got [%s]", line.cur)
+		}
+		if namespace := line.namespace("namespace"); namespace != "emojivoto" {
+			t.Fatalf("Expected namespace [emojivoto], got [%s]", namespace)
+		}
+		if namespace := line.flag("linkerd-namespace"); namespace != "linkerd-test" {
+			t.Fatalf("Expected --linkerd-namespace [linkerd-test], got [%s]", namespace)
+		}
+	})
+
+	t.Run("Completes the values of flags given with an equal sign", func(t *testing.T) {
+		line := parseCompletionLine(RootCmd, []string{"stat", "deploy", "--to-namespace=emojivoto", "--to=deploy/vo"})
+		if line.cur != "deploy/vo" {
+			t.Fatalf("Expected the word being completed to be [deploy/vo], got [%s]", line.cur)
+		}
+		if namespace := line.namespace("to-namespace", "namespace"); namespace != "emojivoto" {
+			t.Fatalf("Expected namespace [emojivoto], got [%s]", namespace)
+		}
+	})
+
+	t.Run("Uses the default namespace of the command", func(t *testing.T) {
+		line := parseCompletionLine(RootCmd, []string{"stat", "--to", ""})
+		if namespace := line.namespace("to-namespace", "namespace"); namespace != "default" {
+			t.Fatalf("Expected namespace [default], got [%s]", namespace)
+		}
+	})
+}
+
+func TestCompleteValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces":
+			writeJSON(t, w, v1.NamespaceList{Items: []v1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "linkerd"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "emojivoto"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+			}})
+		case "/apis/apps/v1/namespaces/emojivoto/deployments":
+			writeJSON(t, w, appsv1.DeploymentList{Items: []appsv1.Deployment{
+				{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "emojivoto"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "voting", Namespace: "emojivoto"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "emoji", Namespace: "emojivoto"}},
+			}})
+		case "/api/v1/namespaces/emojivoto/pods":
+			writeJSON(t, w, v1.PodList{Items: []v1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "web-5f86686c4d-qh5lm", Namespace: "emojivoto"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "emoji-6bf9f47bd5-78cfk", Namespace: "emojivoto"}},
+			}})
+		case "/api/v1/namespaces/emojivoto/services":
+			writeJSON(t, w, v1.ServiceList{Items: []v1.Service{
+				{ObjectMeta: metav1.ObjectMeta{Name: "web-svc", Namespace: "emojivoto"}},
+			}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	newAPI := func(*completionLine) (*k8s.KubernetesAPI, error) {
+		return &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}, nil
+	}
+
+	testCases := []struct {
+		kind     string
+		words    []string
+		expected []string
+	}{
+		{completeNamespaces, []string{"stat", "-n", ""}, []string{"default", "emojivoto", "linkerd"}},
+		{completeResources, []string{"stat", "-n", "emojivoto", ""}, []string{"authority", "deployment", "namespace", "pod", "replicationcontroller"}},
+		{completeResources, []string{"stat", "-n", "emojivoto", "deploy/"}, []string{"deploy/emoji", "deploy/voting", "deploy/web"}},
+		{completeResources, []string{"tap", "--namespace=emojivoto", "po/web"}, []string{"po/emoji-6bf9f47bd5-78cfk", "po/web-5f86686c4d-qh5lm"}},
+		{completeResources, []string{"stat", "deployments", "-n", "emojivoto", ""}, []string{"emoji", "voting", "web"}},
+		{completeResources, []string{"stat", "ns/"}, []string{"ns/default", "ns/emojivoto", "ns/linkerd"}},
+		{completeResources, []string{"stat", "-n", "emojivoto", "deploy", "web", ""}, nil},
+		{completeResources, []string{"stat", "au/"}, nil},
+		{completeToResources, []string{"stat", "deploy", "--to-namespace", "emojivoto", "--to", "svc/"}, []string{"svc/web-svc"}},
+		{completeFromResources, []string{"stat", "-n", "emojivoto", "deploy", "--from", "deploy/"}, []string{"deploy/emoji", "deploy/voting", "deploy/web"}},
+		// the deployments of the default namespace cannot be listed
+		{completeResources, []string{"stat", "deploy/"}, nil},
+		{completeCheckCategories, []string{"check", "--category", ""}, []string{"kubernetes-api", "kubernetes-version", "kubernetes-clock", "kubernetes-distribution", "kubernetes-nodes", "linkerd-api", "linkerd-control-plane", "linkerd-proxy-injector", "linkerd-version"}},
+		{completeCheckCategories, []string{"check", "--pre", "--single-namespace", "--category", ""}, []string{"kubernetes-api", "kubernetes-version", "kubernetes-clock", "kubernetes-distribution", "linkerd-ns", "pre-kubernetes-capability", "linkerd-version"}},
+		{completeCheckCategories, []string{"check", "--proxy", "--category", ""}, []string{"kubernetes-api", "kubernetes-version", "kubernetes-clock", "kubernetes-distribution", "linkerd-api", "linkerd-control-plane", "linkerd-proxy-injector", "linkerd-data-plane"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.kind+" "+strings.Join(tc.words, " "), func(t *testing.T) {
+			values, err := completeValues(context.Background(), parseCompletionLine(RootCmd, tc.words), tc.kind, newAPI)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(values, tc.expected) {
+				t.Fatalf("Expected values %v, got %v", tc.expected, values)
+			}
+		})
+	}
+
+	t.Run("Suggests nothing when the cluster is unreachable", func(t *testing.T) {
+		unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		unreachable.Close()
+		newAPI := func(*completionLine) (*k8s.KubernetesAPI, error) {
+			return &k8s.KubernetesAPI{Config: &rest.Config{Host: unreachable.URL}, RetryAttempts: 1}, nil
+		}
+
+		for _, kind := range []string{completeNamespaces, completeResources} {
+			values, err := completeValues(context.Background(), parseCompletionLine(RootCmd, []string{"stat", "deploy/"}), kind, newAPI)
+			if err != nil || values != nil {
+				t.Fatalf("Expected no values for [%s], got %v, %v", kind, values, err)
+			}
+		}
+	})
+
+	t.Run("Returns an error for unsupported kinds", func(t *testing.T) {
+		_, err := completeValues(context.Background(), parseCompletionLine(RootCmd, []string{""}), "services", newAPI)
+		expected := "unsupported completion kind [services]"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
@@ -1,8 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/linkerd/linkerd2/pkg/k8s"
 	"github.com/pkg/browser"
@@ -19,22 +27,41 @@ const (
 
 	// showURL displays dashboard URLs without opening a browser.
 	showURL = "url"
+
+	// defaultDashboardPort is the local port the dashboard is served on,
+	// unless --port is given, so that its URL can be bookmarked.
+	defaultDashboardPort = 50750
+
+	// webPort and grafanaPort are the container ports of the web and Grafana
+	// pods, as set in the install template.
+	webPort     = 8084
+	grafanaPort = 3000
 )
 
 type dashboardOptions struct {
 	dashboardProxyPort int
+	dashboardAddress   string
 	dashboardShow      string
+	urlOnly            bool
+	apiProxy           bool
 	wait               bool
 }
 
 func newDashboardOptions() *dashboardOptions {
 	return &dashboardOptions{
-		dashboardProxyPort: 0,
+		dashboardProxyPort: defaultDashboardPort,
+		dashboardAddress:   k8s.DefaultProxyAddress,
 		dashboardShow:      showLinkerd,
+		urlOnly:            false,
+		apiProxy:           false,
 		wait:               false,
 	}
 }
 
+// openURL opens a URL in the default browser. Tests replace it to check
+// whether a browser would be started.
+var openURL = browser.OpenURL
+
 func newCmdDashboard() *cobra.Command {
 	options := newDashboardOptions()
 
@@ -51,55 +78,65 @@ func newCmdDashboard() *cobra.Command {
 					options.dashboardShow, showLinkerd, showGrafana, showURL)
 			}
 
-			kubernetesProxy, err := k8s.NewProxy(kubeconfigPath, options.dashboardProxyPort)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to initialize proxy: %s\n", err)
-				os.Exit(1)
-			}
-
-			url, err := kubernetesProxy.URLFor(controlPlaneNamespace, "/services/web:http/proxy/")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to generate URL for dashboard: %s\n", err)
-				os.Exit(1)
-			}
-
-			grafanaUrl, err := kubernetesProxy.URLFor(controlPlaneNamespace, "/services/grafana:http/proxy/")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to generate URL for Grafana: %s\n", err)
-				os.Exit(1)
+			if options.urlOnly {
+				if cmd.Flags().Changed("show") && options.dashboardShow != showURL {
+					return fmt.Errorf("--url cannot be combined with --show %s", options.dashboardShow)
+				}
+				options.dashboardShow = showURL
 			}
 
-			// ensure we can connect to the public API before starting the proxy
-			validatedPublicAPIClient(options.wait)
+			var linkerdURL, grafanaURL string
+			var run func() error
+			if options.apiProxy {
+				kubernetesProxy, err := newDashboardProxy(os.Stderr, options)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to initialize proxy: %s\n", err)
+					os.Exit(1)
+				}
 
-			fmt.Printf("Linkerd dashboard available at:\n%s\n", url.String())
-			fmt.Printf("Grafana dashboard available at:\n%s\n", grafanaUrl.String())
+				url, err := kubernetesProxy.ServiceProxyURLFor(controlPlaneNamespace, "web", "http", "", "")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to generate URL for dashboard: %s\n", err)
+					os.Exit(1)
+				}
 
-			switch options.dashboardShow {
-			case showLinkerd:
-				fmt.Println("Opening Linkerd dashboard in the default browser")
+				grafanaUrl, err := kubernetesProxy.ServiceProxyURLFor(controlPlaneNamespace, "grafana", "http", "", "")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to generate URL for Grafana: %s\n", err)
+					os.Exit(1)
+				}
 
-				err = browser.OpenURL(url.String())
+				linkerdURL, grafanaURL, run = url.String(), grafanaUrl.String(), kubernetesProxy.Run
+			} else {
+				kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeAPIOptions())
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to open Linkerd URL %s in the default browser: %s", url, err)
+					fmt.Fprintf(os.Stderr, "Failed to initialize Kubernetes API client: %s\n", err)
 					os.Exit(1)
 				}
-			case showGrafana:
-				fmt.Println("Opening Grafana dashboard in the default browser")
 
-				err = browser.OpenURL(grafanaUrl.String())
+				forward, err := newDashboardForward(os.Stderr, kubeAPI, options)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to open Grafana URL %s in the default browser: %s", grafanaUrl, err)
+					fmt.Fprintf(os.Stderr, "Failed to forward to the dashboard: %s\n", err)
 					os.Exit(1)
 				}
-			case showURL:
-				// no-op, we already printed the URLs
+
+				linkerdURL, grafanaURL = forward.linkerdURL(), forward.grafanaURL()
+				run = func() error { return forward.run(os.Stderr) }
 			}
 
+			// ensure we can connect to the public API before serving the dashboard
+			var retryDeadline time.Time
+			if options.wait {
+				retryDeadline = time.Now().Add(defaultWaitTimeout)
+			}
+			validatedPublicAPIClient(retryDeadline)
+
+			showDashboard(os.Stdout, os.Stderr, options.dashboardShow, linkerdURL, grafanaURL)
+
 			// blocks until killed
-			err = kubernetesProxy.Run()
+			err := run()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error running proxy: %s", err)
+				fmt.Fprintf(os.Stderr, "Error serving dashboard: %s\n", err)
 				os.Exit(1)
 			}
 
@@ -108,10 +145,205 @@ func newCmdDashboard() *cobra.Command {
 	}
 
 	cmd.Args = cobra.NoArgs
-	// This is identical to what `kubectl proxy --help` reports, `--port 0` indicates a random port.
-	cmd.PersistentFlags().IntVarP(&options.dashboardProxyPort, "port", "p", options.dashboardProxyPort, "The port on which to run the proxy (when set to 0, a random port will be used)")
+	// As with `kubectl proxy --help`, `--port 0` indicates a random port.
+	cmd.PersistentFlags().IntVarP(&options.dashboardProxyPort, "port", "p", options.dashboardProxyPort, "The local port on which to serve the dashboard (when set to 0, a random port will be used)")
+	cmd.PersistentFlags().StringVar(&options.dashboardAddress, "address", options.dashboardAddress, "The address on which to serve the dashboard; with --api-proxy, anyone who can reach a non-loopback address can use the Kubernetes API with your credentials")
 	cmd.PersistentFlags().StringVar(&options.dashboardShow, "show", options.dashboardShow, "Open a dashboard in a browser or show URLs in the CLI (one of: linkerd, grafana, url)")
+	cmd.PersistentFlags().BoolVar(&options.urlOnly, "url", options.urlOnly, "Only print the dashboard URLs, without opening a browser (same as --show url)")
+	cmd.PersistentFlags().BoolVar(&options.apiProxy, "api-proxy", options.apiProxy, "Serve the dashboard through the Kubernetes API server's service proxy, for clusters that do not allow port-forwarding")
 	cmd.PersistentFlags().BoolVar(&options.wait, "wait", false, "Wait for dashboard to become available if it's not available when the command is run")
 
 	return cmd
 }
+
+// showDashboard prints the dashboard URLs to w, and, unless show is showURL,
+// opens the selected dashboard in the default browser. If the browser cannot
+// be opened, it explains on stderr that the printed URL can be used instead,
+// since the dashboard is served either way.
+func showDashboard(w, stderr io.Writer, show, linkerdURL, grafanaURL string) {
+	fmt.Fprintf(w, "Linkerd dashboard available at:\n%s\n", linkerdURL)
+	fmt.Fprintf(w, "Grafana dashboard available at:\n%s\n", grafanaURL)
+
+	name, target := "Linkerd", linkerdURL
+	switch show {
+	case showGrafana:
+		name, target = "Grafana", grafanaURL
+	case showURL:
+		return
+	}
+
+	fmt.Fprintf(w, "Opening %s dashboard in the default browser\n", name)
+	if err := openURL(target); err != nil {
+		fmt.Fprintf(stderr, "Failed to open %s URL %s in the default browser: %s\n", name, target, err)
+		fmt.Fprintf(stderr, "Open the URL above in a browser to use the dashboard, or pass --url to skip opening one\n")
+	}
+}
+
+// newDashboardProxy starts listening for dashboard requests, warning on w if
+// the proxy can be reached from other hosts. If the port is already in use,
+// the returned error suggests the --port flag.
+func newDashboardProxy(w io.Writer, options *dashboardOptions) (*k8s.KubernetesProxy, error) {
+	if !k8s.IsLoopbackAddress(options.dashboardAddress) {
+		fmt.Fprintf(w, "Warning: the dashboard will be reachable by anyone who can connect to %s, and lets them use the Kubernetes API with your credentials\n", options.dashboardAddress)
+	}
+
+	kubernetesProxy, err := k8s.NewProxy(kubeconfigPath, kubeContext, options.dashboardAddress, options.dashboardProxyPort)
+	if inUse, ok := err.(*k8s.AddressInUseError); ok {
+		return nil, fmt.Errorf("%s; pass --port to use another port, or --port 0 to use any free port", inUse)
+	}
+	return kubernetesProxy, err
+}
+
+// dashboardForward serves the dashboard locally, forwarding requests to the
+// web and Grafana pods over port-forwards, which, unlike the API server's
+// service proxy, pass websockets and relative asset paths through unchanged.
+type dashboardForward struct {
+	listener    net.Listener
+	web         *k8s.PortForward
+	grafana     *k8s.PortForward
+	grafanaPath string
+}
+
+// newDashboardForward starts listening for dashboard requests, warning on w if
+// the dashboard can be reached from other hosts. It fails with the status of
+// the web pods unless one of them is ready.
+func newDashboardForward(w io.Writer, kubeAPI *k8s.KubernetesAPI, options *dashboardOptions) (*dashboardForward, error) {
+	if !k8s.IsLoopbackAddress(options.dashboardAddress) {
+		fmt.Fprintf(w, "Warning: the dashboard will be reachable by anyone who can connect to %s\n", options.dashboardAddress)
+	}
+
+	webSelector := fmt.Sprintf("%s=web", k8s.ControllerComponentLabel)
+	if _, err := kubeAPI.SelectReadyPod(context.Background(), controlPlaneNamespace, webSelector); err != nil {
+		return nil, err
+	}
+
+	listener, err := k8s.Listen(options.dashboardAddress, options.dashboardProxyPort)
+	if err != nil {
+		if inUse, ok := err.(*k8s.AddressInUseError); ok {
+			return nil, fmt.Errorf("%s; pass --port to use another port, or --port 0 to use any free port", inUse)
+		}
+		return nil, err
+	}
+
+	web, err := k8s.NewPortForward(kubeAPI, controlPlaneNamespace, webSelector, 0, webPort)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	grafanaSelector := fmt.Sprintf("%s=grafana", k8s.ControllerComponentLabel)
+	grafana, err := k8s.NewPortForward(kubeAPI, controlPlaneNamespace, grafanaSelector, 0, grafanaPort)
+	if err != nil {
+		listener.Close()
+		web.Stop()
+		return nil, err
+	}
+
+	return &dashboardForward{
+		listener: listener,
+		web:      web,
+		grafana:  grafana,
+		// Grafana's root_url is the path of its service proxy, so the links
+		// it generates only work under that path.
+		grafanaPath: fmt.Sprintf("/api/v1/namespaces/%s/services/grafana:http/proxy/", controlPlaneNamespace),
+	}, nil
+}
+
+func (d *dashboardForward) linkerdURL() string {
+	return k8s.ListenerURL(d.listener) + "/"
+}
+
+func (d *dashboardForward) grafanaURL() string {
+	return k8s.ListenerURL(d.listener) + d.grafanaPath
+}
+
+// run serves the dashboard until forwarding to the web pod fails. Failures to
+// forward to Grafana are reported on stderr, without stopping the dashboard.
+func (d *dashboardForward) run(stderr io.Writer) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		if err := d.grafana.Run(ctx); err != nil {
+			fmt.Fprintf(stderr, "Grafana dashboard is unavailable: %s\n", err)
+		}
+	}()
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- d.web.Run(ctx)
+	}()
+	go func() {
+		handler := newDashboardHandler(
+			fmt.Sprintf("127.0.0.1:%d", d.web.LocalPort()),
+			fmt.Sprintf("127.0.0.1:%d", d.grafana.LocalPort()),
+			d.grafanaPath,
+		)
+		errs <- http.Serve(d.listener, handler)
+	}()
+	return <-errs
+}
+
+// newDashboardHandler routes requests under grafanaPath, with that prefix
+// stripped, to grafanaAddr, and all others to the web server at webAddr,
+// tunneling websockets to it. Served from "/", the web app cannot rewrite the
+// path of its links to Grafana dashboards, so those are redirected under
+// grafanaPath.
+func newDashboardHandler(webAddr, grafanaAddr, grafanaPath string) http.Handler {
+	web := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: webAddr})
+	grafanaPrefix := strings.TrimSuffix(grafanaPath, "/")
+	grafana := http.StripPrefix(grafanaPrefix, httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: grafanaAddr}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.HasPrefix(req.URL.Path, grafanaPath):
+			grafana.ServeHTTP(w, req)
+		case strings.HasPrefix(req.URL.Path, "/dashboard/"):
+			http.Redirect(w, req, grafanaPrefix+req.URL.RequestURI(), http.StatusFound)
+		case strings.EqualFold(req.Header.Get("Upgrade"), "websocket"):
+			tunnelWebsocket(w, req, webAddr)
+		default:
+			web.ServeHTTP(w, req)
+		}
+	})
+}
+
+// tunnelWebsocket passes a websocket upgrade request to addr and then copies
+// the connection both ways, since httputil.ReverseProxy drops the Upgrade
+// header.
+func tunnelWebsocket(w http.ResponseWriter, req *http.Request, addr string) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websockets are not supported", http.StatusInternalServerError)
+		return
+	}
+
+	backend, err := net.Dial("tcp", addr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer backend.Close()
+
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer client.Close()
+
+	if err := req.Write(backend); err != nil {
+		return
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(backend, buffered)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, backend)
+		done <- struct{}{}
+	}()
+	<-done
+}
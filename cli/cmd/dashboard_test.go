@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestNewDashboardProxy(t *testing.T) {
+	defer func(path string) { kubeconfigPath = path }(kubeconfigPath)
+	kubeconfigPath = "testdata/config.test"
+
+	t.Run("Serves the dashboard on a free port for port 0", func(t *testing.T) {
+		options := newDashboardOptions()
+		options.dashboardProxyPort = 0
+
+		var stderr bytes.Buffer
+		proxy, err := newDashboardProxy(&stderr, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		url, err := proxy.ServiceProxyURLFor("linkerd", "web", "http", "", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := fmt.Sprintf("http://127.0.0.1:%d/api/v1/namespaces/linkerd/services/web:http/proxy/", proxy.Port())
+		if url.String() != expected {
+			t.Fatalf("Expected dashboard URL [%s], got [%s]", expected, url)
+		}
+		if stderr.Len() != 0 {
+			t.Fatalf("Expected no warning, got [%s]", stderr.String())
+		}
+	})
+
+	t.Run("Suggests --port if the port is in use", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer listener.Close()
+
+		options := newDashboardOptions()
+		options.dashboardProxyPort = listener.Addr().(*net.TCPAddr).Port
+
+		_, err = newDashboardProxy(&bytes.Buffer{}, options)
+		if err == nil || !strings.Contains(err.Error(), "is already in use") || !strings.HasSuffix(err.Error(), "pass --port to use another port, or --port 0 to use any free port") {
+			t.Fatalf("Expected an error suggesting --port, got [%v]", err)
+		}
+	})
+
+	t.Run("Warns about non-loopback addresses", func(t *testing.T) {
+		options := newDashboardOptions()
+		options.dashboardProxyPort = 0
+		options.dashboardAddress = "0.0.0.0"
+
+		var stderr bytes.Buffer
+		if _, err := newDashboardProxy(&stderr, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(stderr.String(), "Warning: the dashboard will be reachable by anyone who can connect to 0.0.0.0") {
+			t.Fatalf("Expected a warning, got [%s]", stderr.String())
+		}
+	})
+}
+
+func TestShowDashboard(t *testing.T) {
+	defer func(open func(string) error) { openURL = open }(openURL)
+
+	linkerdURL := "http://127.0.0.1:50750/api/v1/namespaces/linkerd/services/web:http/proxy/"
+	grafanaURL := "http://127.0.0.1:50750/api/v1/namespaces/linkerd/services/grafana:http/proxy/"
+	printedURLs := "Linkerd dashboard available at:\n" + linkerdURL + "\nGrafana dashboard available at:\n" + grafanaURL + "\n"
+
+	t.Run("Only prints the URLs in url mode", func(t *testing.T) {
+		openURL = func(url string) error {
+			t.Fatalf("Expected no browser to be opened, but opened [%s]", url)
+			return nil
+		}
+
+		var stdout, stderr bytes.Buffer
+		showDashboard(&stdout, &stderr, showURL, linkerdURL, grafanaURL)
+		if stdout.String() != printedURLs {
+			t.Fatalf("Expected output [%s], got [%s]", printedURLs, stdout.String())
+		}
+		if stderr.Len() != 0 {
+			t.Fatalf("Expected no errors, got [%s]", stderr.String())
+		}
+	})
+
+	t.Run("Opens the selected dashboard", func(t *testing.T) {
+		var opened []string
+		openURL = func(url string) error {
+			opened = append(opened, url)
+			return nil
+		}
+
+		var stdout, stderr bytes.Buffer
+		showDashboard(&stdout, &stderr, showGrafana, linkerdURL, grafanaURL)
+		if len(opened) != 1 || opened[0] != grafanaURL {
+			t.Fatalf("Expected [%s] to be opened, got %v", grafanaURL, opened)
+		}
+	})
+
+	t.Run("Falls back to the printed URL if the browser cannot be opened", func(t *testing.T) {
+		openURL = func(string) error {
+			return fmt.Errorf("exec: \"xdg-open\": executable file not found in $PATH")
+		}
+
+		var stdout, stderr bytes.Buffer
+		showDashboard(&stdout, &stderr, showLinkerd, linkerdURL, grafanaURL)
+		if !strings.HasPrefix(stdout.String(), printedURLs) {
+			t.Fatalf("Expected the URLs to be printed, got [%s]", stdout.String())
+		}
+		if !strings.Contains(stderr.String(), "Open the URL above in a browser") {
+			t.Fatalf("Expected a hint to use the printed URL, got [%s]", stderr.String())
+		}
+	})
+}
+
+// webPodServer is a fake API server listing a single web pod, ready or not.
+func webPodServer(ready bool) *httptest.Server {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "linkerd-web-1", Namespace: "linkerd"},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "web", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			},
+		},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(v1.PodList{Items: []v1.Pod{pod}})
+	}))
+}
+
+func TestNewDashboardForward(t *testing.T) {
+	t.Run("Serves the dashboard from the root of the local port", func(t *testing.T) {
+		server := webPodServer(true)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		options := newDashboardOptions()
+		options.dashboardProxyPort = 0
+
+		var stderr bytes.Buffer
+		forward, err := newDashboardForward(&stderr, kubeAPI, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer forward.listener.Close()
+		defer forward.web.Stop()
+		defer forward.grafana.Stop()
+
+		port := forward.listener.Addr().(*net.TCPAddr).Port
+		expected := fmt.Sprintf("http://127.0.0.1:%d/", port)
+		if forward.linkerdURL() != expected {
+			t.Fatalf("Expected dashboard URL [%s], got [%s]", expected, forward.linkerdURL())
+		}
+		expected = fmt.Sprintf("http://127.0.0.1:%d/api/v1/namespaces/linkerd/services/grafana:http/proxy/", port)
+		if forward.grafanaURL() != expected {
+			t.Fatalf("Expected Grafana URL [%s], got [%s]", expected, forward.grafanaURL())
+		}
+		if stderr.Len() != 0 {
+			t.Fatalf("Expected no warning, got [%s]", stderr.String())
+		}
+	})
+
+	t.Run("Fails with the status of the web pod when it is not ready", func(t *testing.T) {
+		server := webPodServer(false)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		_, err := newDashboardForward(&bytes.Buffer{}, kubeAPI, newDashboardOptions())
+		expected := "no ready pods in namespace [linkerd] match [linkerd.io/control-plane-component=web]:\n\tpod [linkerd-web-1] is not ready (container [web] is waiting: CrashLoopBackOff)"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestNewDashboardHandler(t *testing.T) {
+	backend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Upgrade") != "websocket" {
+				fmt.Fprintf(w, "%s %s", name, r.URL.RequestURI())
+				return
+			}
+
+			conn, buffered, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n"))
+			line, _ := buffered.ReadString('\n')
+			conn.Write([]byte(name + " " + line))
+		}))
+	}
+	web, grafana := backend("web"), backend("grafana")
+	defer web.Close()
+	defer grafana.Close()
+
+	grafanaPath := "/api/v1/namespaces/linkerd/services/grafana:http/proxy/"
+	server := httptest.NewServer(newDashboardHandler(web.Listener.Addr().String(), grafana.Listener.Addr().String(), grafanaPath))
+	defer server.Close()
+
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{"/servicemesh", "web /servicemesh"},
+		{"/api/tps-reports?resource_type=deployment", "web /api/tps-reports?resource_type=deployment"},
+		{grafanaPath, "grafana /"},
+		{grafanaPath + "public/build/app.js", "grafana /public/build/app.js"},
+		{"/dashboard/db/linkerd-deployment?var-namespace=emojivoto", "grafana /dashboard/db/linkerd-deployment?var-namespace=emojivoto"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			rsp, err := http.Get(server.URL + tc.path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer rsp.Body.Close()
+			body, err := ioutil.ReadAll(rsp.Body)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(body) != tc.expected {
+				t.Fatalf("Expected response [%s], got [%s]", tc.expected, body)
+			}
+		})
+	}
+
+	t.Run("Tunnels websockets to the web pod", func(t *testing.T) {
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer conn.Close()
+
+		req, err := http.NewRequest("GET", server.URL+"/api/tap", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		if err := req.Write(conn); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		reader := bufio.NewReader(conn)
+		rsp, err := http.ReadResponse(reader, req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if rsp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("Expected status %d, got %d", http.StatusSwitchingProtocols, rsp.StatusCode)
+		}
+
+		io.WriteString(conn, "hello\n")
+		line, err := reader.ReadString('\n')
+		if err != nil || line != "web hello\n" {
+			t.Fatalf("Expected [web hello], got [%s] and [%v]", line, err)
+		}
+	})
+}
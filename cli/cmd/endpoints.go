@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	pb "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	"github.com/linkerd/linkerd2/controller/destination"
+	"github.com/linkerd/linkerd2/pkg/addr"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+)
+
+// destinationPort is the port the destination container of the controller
+// pods is served on, as set in the install template. It only listens on
+// localhost, which port-forwards connect to.
+const destinationPort = 8089
+
+type endpointsOptions struct {
+	watch bool
+}
+
+func newEndpointsOptions() *endpointsOptions {
+	return &endpointsOptions{
+		watch: false,
+	}
+}
+
+func newCmdEndpoints() *cobra.Command {
+	options := newEndpointsOptions()
+
+	cmd := &cobra.Command{
+		Use:     "endpoints [flags] (AUTHORITY)",
+		Aliases: []string{"ep"},
+		Short:   "Display the endpoints of an authority, as resolved by the destination service",
+		Long: `Display the endpoints of an authority, as resolved by the destination service.
+
+The AUTHORITY argument is the name and port the proxies are asked to route
+requests to, such as "books.default.svc.cluster.local:8080"; the port is 80
+if omitted. The destination service of the control plane is queried for it,
+through a port-forward to a controller pod, as a proxy would, and the
+endpoints it resolves the authority to are displayed, with the name and
+namespace of their pod, their IP and port, and their weight.
+
+Authorities that are not those of a service are reported as such, and
+services without endpoints, for instance without ready pods, as having none.
+With --watch, the endpoints are displayed again as they are added and removed,
+until interrupted.`,
+		Example: `  # Get the endpoints of the books service in the default namespace
+  linkerd endpoints books.default.svc.cluster.local:8080
+
+  # Watch the endpoints of the web service in the emojivoto namespace
+  linkerd endpoints web-svc.emojivoto.svc.cluster.local --watch`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeAPIOptions())
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := newSignalContext()
+			defer cancel()
+
+			controllerSelector := fmt.Sprintf("%s=controller", k8s.ControllerComponentLabel)
+			forward, err := k8s.NewPortForward(kubeAPI, controlPlaneNamespace, controllerSelector, 0, destinationPort)
+			if err != nil {
+				return err
+			}
+			forwardErr := make(chan error, 1)
+			go func() {
+				forwardErr <- forward.Run(ctx)
+				cancel()
+			}()
+
+			client, conn, err := destination.NewClient(fmt.Sprintf("127.0.0.1:%d", forward.LocalPort()))
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			err = runEndpoints(ctx, client, args[0], options, os.Stdout)
+			// errors of the forward cancel the query, and are the ones to report
+			select {
+			case ferr := <-forwardErr:
+				if ferr != nil {
+					return ferr
+				}
+			default:
+			}
+			return err
+		},
+	}
+
+	cmd.PersistentFlags().BoolVarP(&options.watch, "watch", "w", options.watch, "Keep displaying the endpoints as they change, until interrupted")
+
+	return cmd
+}
+
+// endpoint is an endpoint of an authority, as resolved by the destination
+// service.
+type endpoint struct {
+	pod       string
+	namespace string
+	ip        string
+	port      uint32
+	weight    uint32
+}
+
+// runEndpoints writes the endpoints client resolves authority to to w. Unless
+// options.watch is set, it returns once the first update has been received,
+// with the endpoints known then.
+func runEndpoints(ctx context.Context, client pb.DestinationClient, authority string, options *endpointsOptions, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.Get(ctx, &pb.GetDestination{Scheme: "k8s", Path: authority})
+	if err != nil {
+		return err
+	}
+
+	endpoints := make(map[string]*endpoint)
+	for {
+		update, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err == io.EOF {
+				return fmt.Errorf("the destination service ended the stream of [%s]", authority)
+			}
+			return err
+		}
+
+		switch u := update.GetUpdate().(type) {
+		case *pb.Update_Add:
+			for _, a := range u.Add.GetAddrs() {
+				// the namespace of the service is that of its pods
+				pod, namespace := a.GetMetricLabels()["pod"], u.Add.GetMetricLabels()["namespace"]
+				if pod == "" {
+					pod = "-"
+				}
+				endpoints[addr.ProxyAddressToString(a.GetAddr())] = &endpoint{
+					pod:       pod,
+					namespace: namespace,
+					ip:        addr.ProxyIPToString(a.GetAddr().GetIp()),
+					port:      a.GetAddr().GetPort(),
+					weight:    a.GetWeight(),
+				}
+			}
+			fmt.Fprint(w, renderEndpoints(endpoints))
+		case *pb.Update_Remove:
+			for _, a := range u.Remove.GetAddrs() {
+				delete(endpoints, addr.ProxyAddressToString(a))
+			}
+			if len(endpoints) == 0 {
+				fmt.Fprintf(w, "No endpoints found for [%s]: all of them were removed\n", authority)
+			} else {
+				fmt.Fprint(w, renderEndpoints(endpoints))
+			}
+		case *pb.Update_NoEndpoints:
+			endpoints = make(map[string]*endpoint)
+			if !u.NoEndpoints.GetExists() {
+				if !options.watch {
+					return fmt.Errorf("no endpoints found for [%s]: it is not the authority of a service in the cluster", authority)
+				}
+				fmt.Fprintf(w, "No endpoints found for [%s]: it is not the authority of a service in the cluster\n", authority)
+			} else {
+				fmt.Fprintf(w, "No endpoints found for [%s]: the service exists, but has no endpoints\n", authority)
+			}
+		}
+
+		if !options.watch {
+			return nil
+		}
+		// separate the endpoints of each update
+		fmt.Fprintln(w)
+	}
+}
+
+// renderEndpoints returns the table of endpoints, sorted by namespace, pod,
+// IP and port.
+func renderEndpoints(endpoints map[string]*endpoint) string {
+	sorted := make([]*endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		sorted = append(sorted, e)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		if a.pod != b.pod {
+			return a.pod < b.pod
+		}
+		if a.ip != b.ip {
+			return a.ip < b.ip
+		}
+		return a.port < b.port
+	})
+
+	var buffer bytes.Buffer
+	w := tabwriter.NewWriter(&buffer, 0, 0, padding, ' ', tabwriter.AlignRight)
+
+	podHeader := "POD"
+	maxPodLength := len(podHeader)
+	maxNamespaceLength := len(namespaceHeader)
+	for _, e := range sorted {
+		if len(e.pod) > maxPodLength {
+			maxPodLength = len(e.pod)
+		}
+		if len(e.namespace) > maxNamespaceLength {
+			maxNamespaceLength = len(e.namespace)
+		}
+	}
+
+	headers := []string{
+		podHeader + strings.Repeat(" ", maxPodLength-len(podHeader)),
+		namespaceHeader + strings.Repeat(" ", maxNamespaceLength-len(namespaceHeader)),
+		"IP",
+		"PORT",
+		"WEIGHT",
+	}
+	// trailing \t is required to format last column
+	fmt.Fprintln(w, strings.Join(headers, "\t")+"\t")
+
+	for _, e := range sorted {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t\n",
+			e.pod+strings.Repeat(" ", maxPodLength-len(e.pod)),
+			e.namespace+strings.Repeat(" ", maxNamespaceLength-len(e.namespace)),
+			e.ip,
+			e.port,
+			e.weight,
+		)
+	}
+	w.Flush()
+
+	// strip left padding on the first column
+	out := string(buffer.Bytes()[padding:])
+	out = strings.Replace(out, "\n"+strings.Repeat(" ", padding), "\n", -1)
+
+	return out
+}
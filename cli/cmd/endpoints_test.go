@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	pb "github.com/linkerd/linkerd2-proxy-api/go/destination"
+	netPb "github.com/linkerd/linkerd2-proxy-api/go/net"
+	"github.com/linkerd/linkerd2/controller/destination"
+	"github.com/linkerd/linkerd2/pkg/addr"
+	"google.golang.org/grpc"
+)
+
+// fakeDestinationServer sends the updates of the authorities it is asked
+// for, and then keeps the stream open until the client closes it.
+type fakeDestinationServer struct {
+	updates map[string][]*pb.Update
+}
+
+func (s *fakeDestinationServer) Get(dest *pb.GetDestination, stream pb.Destination_GetServer) error {
+	for _, update := range s.updates[dest.Path] {
+		if err := stream.Send(update); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func newFakeDestinationClient(t *testing.T, updates map[string][]*pb.Update) (pb.DestinationClient, func()) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterDestinationServer(server, &fakeDestinationServer{updates: updates})
+	go server.Serve(lis)
+
+	client, conn, err := destination.NewClient(lis.Addr().String())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return client, func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func addUpdate(addrs ...*pb.WeightedAddr) *pb.Update {
+	return &pb.Update{Update: &pb.Update_Add{Add: &pb.WeightedAddrSet{
+		Addrs:        addrs,
+		MetricLabels: map[string]string{"namespace": "default", "service": "books"},
+	}}}
+}
+
+func removeUpdate(addrs ...*netPb.TcpAddress) *pb.Update {
+	return &pb.Update{Update: &pb.Update_Remove{Remove: &pb.AddrSet{Addrs: addrs}}}
+}
+
+func tcpAddress(a4 uint8, port uint32) *netPb.TcpAddress {
+	return &netPb.TcpAddress{Ip: addr.ProxyIPV4(10, 1, 0, a4), Port: port}
+}
+
+func weightedAddr(pod string, a4 uint8, port uint32) *pb.WeightedAddr {
+	return &pb.WeightedAddr{
+		Addr:         tcpAddress(a4, port),
+		Weight:       1,
+		MetricLabels: map[string]string{"pod": pod, "deployment": "books"},
+	}
+}
+
+func TestRunEndpoints(t *testing.T) {
+	updates := map[string][]*pb.Update{
+		"books.default.svc.cluster.local:8080": {
+			addUpdate(weightedAddr("books-7d8f9c5b6d-4jzlw", 12, 8080), weightedAddr("books-5f7c6b8dd8-x2kqd", 7, 8080)),
+			addUpdate(weightedAddr("books-64f5d8c9b7-pq2vt", 21, 8080)),
+			removeUpdate(tcpAddress(12, 8080)),
+			removeUpdate(tcpAddress(7, 8080), tcpAddress(21, 8080)),
+		},
+		"authors.default.svc.cluster.local:8080": {
+			{Update: &pb.Update_NoEndpoints{NoEndpoints: &pb.NoEndpoints{Exists: true}}},
+		},
+		"unknown.default.svc.cluster.local:8080": {
+			{Update: &pb.Update_NoEndpoints{NoEndpoints: &pb.NoEndpoints{Exists: false}}},
+		},
+	}
+
+	t.Run("Returns the endpoints resolved when the authority is first queried", func(t *testing.T) {
+		client, stop := newFakeDestinationClient(t, updates)
+		defer stop()
+
+		var out bytes.Buffer
+		if err := runEndpoints(context.Background(), client, "books.default.svc.cluster.local:8080", newEndpointsOptions(), &out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedOutput := `POD                      NAMESPACE          IP   PORT   WEIGHT
+books-5f7c6b8dd8-x2kqd   default      10.1.0.7   8080        1
+books-7d8f9c5b6d-4jzlw   default     10.1.0.12   8080        1
+`
+		if out.String() != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, out.String())
+		}
+	})
+
+	t.Run("Keeps displaying the endpoints as they are added and removed with --watch", func(t *testing.T) {
+		client, stop := newFakeDestinationClient(t, updates)
+		defer stop()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		// the command is interrupted once all the updates have been displayed
+		w := &cancelingWriter{cancel: cancel, after: "all of them were removed"}
+
+		options := newEndpointsOptions()
+		options.watch = true
+		if err := runEndpoints(ctx, client, "books.default.svc.cluster.local:8080", options, w); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedOutput := `POD                      NAMESPACE          IP   PORT   WEIGHT
+books-5f7c6b8dd8-x2kqd   default      10.1.0.7   8080        1
+books-7d8f9c5b6d-4jzlw   default     10.1.0.12   8080        1
+
+POD                      NAMESPACE          IP   PORT   WEIGHT
+books-5f7c6b8dd8-x2kqd   default      10.1.0.7   8080        1
+books-64f5d8c9b7-pq2vt   default     10.1.0.21   8080        1
+books-7d8f9c5b6d-4jzlw   default     10.1.0.12   8080        1
+
+POD                      NAMESPACE          IP   PORT   WEIGHT
+books-5f7c6b8dd8-x2kqd   default      10.1.0.7   8080        1
+books-64f5d8c9b7-pq2vt   default     10.1.0.21   8080        1
+
+No endpoints found for [books.default.svc.cluster.local:8080]: all of them were removed
+
+`
+		if w.String() != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, w.String())
+		}
+	})
+
+	t.Run("Reports services without endpoints", func(t *testing.T) {
+		client, stop := newFakeDestinationClient(t, updates)
+		defer stop()
+
+		var out bytes.Buffer
+		if err := runEndpoints(context.Background(), client, "authors.default.svc.cluster.local:8080", newEndpointsOptions(), &out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedOutput := "No endpoints found for [authors.default.svc.cluster.local:8080]: the service exists, but has no endpoints\n"
+		if out.String() != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, out.String())
+		}
+	})
+
+	t.Run("Returns an error for authorities that are not those of a service", func(t *testing.T) {
+		client, stop := newFakeDestinationClient(t, updates)
+		defer stop()
+
+		err := runEndpoints(context.Background(), client, "unknown.default.svc.cluster.local:8080", newEndpointsOptions(), &bytes.Buffer{})
+		expected := "no endpoints found for [unknown.default.svc.cluster.local:8080]: it is not the authority of a service in the cluster"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+// cancelingWriter calls cancel once what has been written to it contains
+// after.
+type cancelingWriter struct {
+	bytes.Buffer
+	cancel func()
+	after  string
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	if bytes.Contains(w.Bytes(), []byte(w.after)) {
+		w.cancel()
+	}
+	return n, err
+}
@@ -1,25 +1,36 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
-	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
 	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
 )
 
 type getOptions struct {
-	namespace     string
-	allNamespaces bool
+	namespace      string
+	allNamespaces  bool
+	output         string
+	showTerminated bool
 }
 
 func newGetOptions() *getOptions {
 	return &getOptions{
-		namespace:     "default",
-		allNamespaces: false,
+		namespace:      "default",
+		allNamespaces:  false,
+		output:         tableOutput,
+		showTerminated: false,
 	}
 }
 
@@ -31,12 +42,31 @@ func newCmdGet() *cobra.Command {
 		Short: "Display one or many mesh resources",
 		Long: `Display one or many mesh resources.
 
-Only pod resources (aka pods, po) are supported.`,
+Only pod resources (aka pods, po) are supported.
+
+The pods are listed with the Kubernetes API, sorted by namespace and name,
+with whether they are meshed, the version of their proxy, their status, and
+their age. Meshed pods whose proxy is not ready have a status of
+"Running (proxy not ready)". Pods in the Succeeded or Failed phases are
+hidden, unless --show-terminated is set.
+
+With -o json, the pods are displayed as an array of objects, one per pod,
+with these fields:
+
+  * name, namespace: the name and namespace of the pod
+  * meshed: whether the pod has a proxy
+  * proxyVersion: the version of the proxy, or null if it is unknown or the pod is not meshed
+  * proxyReady: whether the proxy is ready, or null for pods that are not meshed
+  * status: the status of the pod, as in the table
+  * createdAt: the time the pod was created, in RFC 3339`,
 		Example: `  # get all pods
   linkerd get pods
 
   # get pods from namespace linkerd
-  linkerd get pods --namespace linkerd`,
+  linkerd get pods --namespace linkerd
+
+  # get the pods of all namespaces, including those that have completed, as JSON
+  linkerd get pods -A --show-terminated -o json`,
 		Args:      cobra.ExactArgs(1),
 		ValidArgs: []string{k8s.Pod},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -55,44 +85,238 @@ Only pod resources (aka pods, po) are supported.`,
 				return fmt.Errorf("invalid resource type %s, valid types: %s", friendlyName, k8s.Pod)
 			}
 
-			podNames, err := getPods(validatedPublicAPIClient(false), options)
+			if options.allNamespaces && cmd.Flags().Changed("namespace") {
+				return fmt.Errorf("--all-namespaces and --namespace flags are mutually exclusive")
+			}
+			if options.output != tableOutput && options.output != jsonOutput {
+				return fmt.Errorf("output format \"%s\" not recognized, must be one of: %s, %s", options.output, tableOutput, jsonOutput)
+			}
+
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeAPIOptions())
 			if err != nil {
 				return err
 			}
 
-			if len(podNames) == 0 {
+			ctx, cancel := newSignalContext()
+			defer cancel()
+
+			pods, err := getPods(ctx, kubeAPI, options)
+			if err != nil {
+				return err
+			}
+
+			if len(pods) == 0 && options.output == tableOutput {
 				fmt.Fprintln(os.Stderr, "No resources found.")
 				os.Exit(0)
 			}
 
-			for _, podName := range podNames {
-				fmt.Println(podName)
+			output, err := renderPods(pods, options, time.Now())
+			if err != nil {
+				return err
 			}
 
-			return nil
+			_, err = fmt.Print(output)
+
+			return err
 		},
 	}
 
 	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of pods")
-	cmd.PersistentFlags().BoolVar(&options.allNamespaces, "all-namespaces", options.allNamespaces, "If present, returns pods across all namespaces, ignoring the \"--namespace\" flag")
+	cmd.PersistentFlags().BoolVarP(&options.allNamespaces, "all-namespaces", "A", options.allNamespaces, "If present, returns pods across all namespaces; incompatible with \"--namespace\"")
+	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, json")
+	cmd.PersistentFlags().BoolVar(&options.showTerminated, "show-terminated", options.showTerminated, "If present, also returns the pods in the Succeeded or Failed phases")
 	return cmd
 }
 
-func getPods(apiClient pb.ApiClient, options *getOptions) ([]string, error) {
-	req := &pb.ListPodsRequest{}
-	if !options.allNamespaces {
-		req.Namespace = options.namespace
+// getPods returns the pods of the namespace of options, or of all namespaces,
+// without those in terminal phases unless options.showTerminated is set.
+func getPods(ctx context.Context, kubeAPI *k8s.KubernetesAPI, options *getOptions) ([]v1.Pod, error) {
+	namespace := options.namespace
+	if options.allNamespaces {
+		namespace = ""
 	}
 
-	resp, err := apiClient.ListPods(context.Background(), req)
+	pods, err := kubeAPI.GetPodsFor(ctx, namespace, "")
 	if err != nil {
 		return nil, err
 	}
+	if options.showTerminated {
+		return pods, nil
+	}
+
+	running := make([]v1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed {
+			running = append(running, pod)
+		}
+	}
+	return running, nil
+}
+
+// podRow is a pod, as displayed by get.
+type podRow struct {
+	name         string
+	namespace    string
+	meshed       bool
+	proxyVersion string
+	proxyReady   bool
+	status       string
+	createdAt    time.Time
+}
+
+func newPodRow(pod *v1.Pod) *podRow {
+	row := &podRow{
+		name:      pod.Name,
+		namespace: pod.Namespace,
+		status:    string(pod.Status.Phase),
+		createdAt: pod.CreationTimestamp.Time,
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if container.Name != k8s.ProxyContainerName {
+			continue
+		}
+		row.meshed = true
+		// images are tagged with the version of the proxy, which pods
+		// injected by older CLIs do not have an annotation for
+		if i := strings.LastIndex(container.Image, ":"); i >= 0 && !strings.Contains(container.Image[i:], "/") {
+			row.proxyVersion = container.Image[i+1:]
+		}
+	}
+	if version := pod.Annotations[k8s.ProxyVersionAnnotation]; version != "" {
+		row.proxyVersion = version
+	}
+	if pod.Labels[k8s.ControllerNSLabel] != "" {
+		row.meshed = true
+	}
 
-	names := make([]string, 0)
-	for _, pod := range resp.GetPods() {
-		names = append(names, pod.Name)
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == k8s.ProxyContainerName {
+			row.proxyReady = status.Ready
+		}
 	}
 
-	return names, nil
+	switch {
+	case pod.DeletionTimestamp != nil:
+		row.status = "Terminating"
+	case row.meshed && !row.proxyReady && pod.Status.Phase == v1.PodRunning:
+		row.status = "Running (proxy not ready)"
+	}
+
+	return row
+}
+
+// sortPodRows returns the rows of pods, sorted by namespace, then name.
+func sortPodRows(pods []v1.Pod) []*podRow {
+	rows := make([]*podRow, 0, len(pods))
+	for i := range pods {
+		rows = append(rows, newPodRow(&pods[i]))
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].namespace != rows[j].namespace {
+			return rows[i].namespace < rows[j].namespace
+		}
+		return rows[i].name < rows[j].name
+	})
+	return rows
+}
+
+// renderPods returns the table or JSON of pods, as of now, which the ages of
+// the pods are relative to.
+func renderPods(pods []v1.Pod, options *getOptions, now time.Time) (string, error) {
+	rows := sortPodRows(pods)
+	if options.output == jsonOutput {
+		return renderPodsJSON(rows)
+	}
+
+	var buffer bytes.Buffer
+	w := tabwriter.NewWriter(&buffer, 0, 0, padding, ' ', tabwriter.AlignRight)
+
+	nameHeader := "NAME"
+	maxNameLength := len(nameHeader)
+	maxNamespaceLength := len(namespaceHeader)
+	for _, r := range rows {
+		if len(r.name) > maxNameLength {
+			maxNameLength = len(r.name)
+		}
+		if len(r.namespace) > maxNamespaceLength {
+			maxNamespaceLength = len(r.namespace)
+		}
+	}
+
+	headers := []string{
+		nameHeader + strings.Repeat(" ", maxNameLength-len(nameHeader)),
+		namespaceHeader + strings.Repeat(" ", maxNamespaceLength-len(namespaceHeader)),
+		"MESHED",
+		"PROXY_VERSION",
+		"STATUS",
+		"AGE",
+	}
+	// trailing \t is required to format last column
+	fmt.Fprintln(w, strings.Join(headers, "\t")+"\t")
+
+	for _, r := range rows {
+		meshed, proxyVersion := "no", "-"
+		if r.meshed {
+			meshed = "yes"
+			if r.proxyVersion != "" {
+				proxyVersion = r.proxyVersion
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			r.name+strings.Repeat(" ", maxNameLength-len(r.name)),
+			r.namespace+strings.Repeat(" ", maxNamespaceLength-len(r.namespace)),
+			meshed,
+			proxyVersion,
+			r.status,
+			duration.ShortHumanDuration(now.Sub(r.createdAt)),
+		)
+	}
+	w.Flush()
+
+	// strip left padding on the first column
+	out := string(buffer.Bytes()[padding:])
+	out = strings.Replace(out, "\n"+strings.Repeat(" ", padding), "\n", -1)
+
+	return out, nil
+}
+
+// jsonPod is a pod of the JSON output of get. Its fields are documented in the
+// help of the command, and, as those of stat, must not be renamed.
+type jsonPod struct {
+	Name         string    `json:"name"`
+	Namespace    string    `json:"namespace"`
+	Meshed       bool      `json:"meshed"`
+	ProxyVersion *string   `json:"proxyVersion"`
+	ProxyReady   *bool     `json:"proxyReady"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func renderPodsJSON(rows []*podRow) (string, error) {
+	pods := []*jsonPod{}
+	for _, r := range rows {
+		pod := &jsonPod{
+			Name:      r.name,
+			Namespace: r.namespace,
+			Meshed:    r.meshed,
+			Status:    r.status,
+			CreatedAt: r.createdAt.UTC(),
+		}
+		if r.meshed {
+			proxyReady := r.proxyReady
+			pod.ProxyReady = &proxyReady
+		}
+		if r.meshed && r.proxyVersion != "" {
+			proxyVersion := r.proxyVersion
+			pod.ProxyVersion = &proxyVersion
+		}
+		pods = append(pods, pod)
+	}
+
+	out, err := json.MarshalIndent(pods, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
 }
@@ -1,70 +1,156 @@
 package cmd
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
-	"github.com/linkerd/linkerd2/controller/api/public"
-	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
 )
 
+// getPodsList is a list of meshed and unmeshed pods, in the linkerd and
+// emojivoto namespaces, in all phases.
+const getPodsList = `{"items":[
+{
+  "metadata": {"name": "web-5f7c6b8dd8-x2kqd", "namespace": "emojivoto", "creationTimestamp": "2018-10-14T10:00:00Z",
+    "labels": {"linkerd.io/control-plane-ns": "linkerd"}, "annotations": {"linkerd.io/proxy-version": "v18.9.1"}},
+  "spec": {"containers": [{"name": "web", "image": "buoyantio/emojivoto-web:v6"}, {"name": "linkerd-proxy", "image": "gcr.io/linkerd-io/proxy:v18.9.1"}]},
+  "status": {"phase": "Running", "containerStatuses": [{"name": "web", "ready": true}, {"name": "linkerd-proxy", "ready": true}]}
+},
+{
+  "metadata": {"name": "emoji-7d8f9c5b6d-4jzlw", "namespace": "emojivoto", "creationTimestamp": "2018-10-14T11:59:30Z"},
+  "spec": {"containers": [{"name": "emoji", "image": "buoyantio/emojivoto-emoji-svc:v6"}, {"name": "linkerd-proxy", "image": "localhost:5000/linkerd-io/proxy:v18.8.4"}]},
+  "status": {"phase": "Running", "containerStatuses": [{"name": "emoji", "ready": true}, {"name": "linkerd-proxy", "ready": false}]}
+},
+{
+  "metadata": {"name": "vote-bot-6d7677bb68-8wl5x", "namespace": "emojivoto", "creationTimestamp": "2018-10-12T12:00:00Z"},
+  "spec": {"containers": [{"name": "vote-bot", "image": "buoyantio/emojivoto-web:v6"}]},
+  "status": {"phase": "Running", "containerStatuses": [{"name": "vote-bot", "ready": true}]}
+},
+{
+  "metadata": {"name": "migrate-9k2lp", "namespace": "emojivoto", "creationTimestamp": "2018-10-13T12:00:00Z",
+    "labels": {"linkerd.io/control-plane-ns": "linkerd"}},
+  "spec": {"containers": [{"name": "migrate", "image": "buoyantio/migrate"}, {"name": "linkerd-proxy", "image": "localhost:5000/linkerd-io/proxy"}]},
+  "status": {"phase": "Succeeded"}
+},
+{
+  "metadata": {"name": "controller-6f78cbd47-bclfd", "namespace": "linkerd", "creationTimestamp": "2018-10-14T11:00:00Z",
+    "deletionTimestamp": "2018-10-14T11:59:00Z", "labels": {"linkerd.io/control-plane-ns": "linkerd"},
+    "annotations": {"linkerd.io/proxy-version": "v18.9.1"}},
+  "spec": {"containers": [{"name": "public-api", "image": "gcr.io/linkerd-io/controller:v18.9.1"}, {"name": "linkerd-proxy", "image": "gcr.io/linkerd-io/proxy:v18.9.1"}]},
+  "status": {"phase": "Running", "containerStatuses": [{"name": "public-api", "ready": true}, {"name": "linkerd-proxy", "ready": true}]}
+},
+{
+  "metadata": {"name": "crashing-job-2xk8d", "namespace": "emojivoto", "creationTimestamp": "2018-10-14T12:00:00Z"},
+  "spec": {"containers": [{"name": "job", "image": "buoyantio/job"}]},
+  "status": {"phase": "Failed"}
+},
+{
+  "metadata": {"name": "voting-64f5d8c9b7-pq2vt", "namespace": "emojivoto", "creationTimestamp": "2018-10-14T11:58:00Z"},
+  "spec": {"containers": [{"name": "voting", "image": "buoyantio/emojivoto-voting-svc:v6"}]},
+  "status": {"phase": "Pending"}
+}
+]}`
+
+// getPodsNow is the time the ages of the pods of getPodsList are relative to.
+var getPodsNow = time.Date(2018, 10, 14, 12, 0, 0, 0, time.UTC)
+
+func newGetPodsServer(t *testing.T) (*httptest.Server, *[]string) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(getPodsList))
+	}))
+	return server, &paths
+}
+
 func TestGetPods(t *testing.T) {
-	t.Run("Returns names of existing pods if everything went ok", func(t *testing.T) {
-		mockClient := &public.MockApiClient{}
+	t.Run("Lists the pods of the namespace, without those that have completed", func(t *testing.T) {
+		server, paths := newGetPodsServer(t)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
 
-		pods := []*pb.Pod{
-			{Name: "pod-a"},
-			{Name: "pod-b"},
-			{Name: "pod-c"},
+		options := newGetOptions()
+		options.namespace = "emojivoto"
+		pods, err := getPods(context.Background(), kubeAPI, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		expectedPodNames := []string{
-			"pod-a",
-			"pod-b",
-			"pod-c",
+		if len(*paths) != 1 || (*paths)[0] != "/api/v1/namespaces/emojivoto/pods" {
+			t.Fatalf("Expected the pods of the emojivoto namespace to be listed, got requests to %v", *paths)
+		}
+		if len(pods) != 5 {
+			t.Fatalf("Expected 5 pods, got %d", len(pods))
 		}
-		response := &pb.ListPodsResponse{
-			Pods: pods,
+		for _, pod := range pods {
+			if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+				t.Fatalf("Expected no pods in terminal phases, got %s in phase %s", pod.Name, pod.Status.Phase)
+			}
 		}
+	})
+
+	t.Run("Lists the pods of all namespaces, including those that have completed", func(t *testing.T) {
+		server, paths := newGetPodsServer(t)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
 
-		mockClient.ListPodsResponseToReturn = response
-		actualPodNames, err := getPods(mockClient, newGetOptions())
+		options := newGetOptions()
+		options.allNamespaces = true
+		options.showTerminated = true
+		pods, err := getPods(context.Background(), kubeAPI, options)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
 
-		for i, actualName := range actualPodNames {
-			expectedName := expectedPodNames[i]
-			if expectedName != actualName {
-				t.Fatalf("Expected %dth element on %v to be [%s], but was [%s]", i, actualPodNames, expectedName, actualName)
-			}
+		if len(*paths) != 1 || (*paths)[0] != "/api/v1/pods" {
+			t.Fatalf("Expected the pods of all namespaces to be listed, got requests to %v", *paths)
+		}
+		if len(pods) != 7 {
+			t.Fatalf("Expected 7 pods, got %d", len(pods))
 		}
 	})
 
-	t.Run("Returns empty list if no pods found", func(t *testing.T) {
-		mockClient := &public.MockApiClient{}
+	t.Run("Returns the errors of the Kubernetes API", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","reason":"Forbidden","message":"forbidden"}`))
+		}))
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
 
-		mockClient.ListPodsResponseToReturn = &pb.ListPodsResponse{
-			Pods: []*pb.Pod{},
+		if _, err := getPods(context.Background(), kubeAPI, newGetOptions()); err == nil {
+			t.Fatalf("Expected an error, got none")
 		}
+	})
+}
+
+func TestRenderPods(t *testing.T) {
+	var list v1.PodList
+	if err := json.Unmarshal([]byte(getPodsList), &list); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
-		actualPodNames, err := getPods(mockClient, newGetOptions())
+	t.Run("Renders meshed and unmeshed pods as a table sorted by namespace and name", func(t *testing.T) {
+		output, err := renderPods(list.Items, newGetOptions(), getPodsNow)
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
-
-		if len(actualPodNames) != 0 {
-			t.Fatalf("Expecting no pod names, got %v", actualPodNames)
-		}
+		diffCompare(t, output, readOptionalTestFile(t, "get_pods.golden"))
 	})
 
-	t.Run("Returns error if cant find pods in API", func(t *testing.T) {
-		mockClient := &public.MockApiClient{}
-		mockClient.ErrorToReturn = errors.New("expected")
-
-		_, err := getPods(mockClient, newGetOptions())
-		if err == nil {
-			t.Fatalf("Expecting error, got noting")
+	t.Run("Renders meshed and unmeshed pods as JSON", func(t *testing.T) {
+		options := newGetOptions()
+		options.output = jsonOutput
+		output, err := renderPods(list.Items, options, getPodsNow)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
 		}
+		diffCompare(t, output, readOptionalTestFile(t, "get_pods_json.golden"))
 	})
 }
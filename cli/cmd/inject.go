@@ -3,67 +3,107 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/pkg/inject"
 	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/ports"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	appsV1 "k8s.io/api/apps/v1"
 	batchV1 "k8s.io/api/batch/v1"
+	batchV1beta1 "k8s.io/api/batch/v1beta1"
 	"k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
 	k8sMeta "k8s.io/apimachinery/pkg/api/meta"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	yamlDecoder "k8s.io/apimachinery/pkg/util/yaml"
 )
 
 const (
-	// LocalhostDNSNameOverride allows override of the controlPlaneDNS. This
-	// must be in absolute form for the proxy to special-case it.
-	LocalhostDNSNameOverride = "localhost."
 	// ControlPlanePodName default control plane pod name.
 	ControlPlanePodName = "controller"
-	// The name of the variable used to pass the pod's namespace.
-	PodNamespaceEnvVarName = "LINKERD2_PROXY_POD_NAMESPACE"
+
+	// defaultInboundPort and defaultOutboundPort are the ports the proxy
+	// listens on, unless others are given to inject.
+	defaultInboundPort  = 4143
+	defaultOutboundPort = 4140
+
+	// installDefaultsTimeout bounds how long inject waits for the cluster when
+	// reading the defaults recorded by `linkerd install`.
+	installDefaultsTimeout = 5 * time.Second
 )
 
+// installDefaultFlags are the flags of `linkerd install` whose recorded values
+// are the defaults of the same flags of `linkerd inject`, grouped by the
+// resource they are the request and limit of.
+var installDefaultFlags = [][]string{
+	{"proxy-cpu-request", "proxy-cpu-limit"},
+	{"proxy-memory-request", "proxy-memory-limit"},
+}
+
 type injectOptions struct {
-	inboundPort         uint
-	outboundPort        uint
-	ignoreInboundPorts  []uint
-	ignoreOutboundPorts []uint
+	inboundPort       uint
+	outboundPort      uint
+	skipInboundPorts  []string
+	skipOutboundPorts []string
+	ignoreCluster     bool
+	recursive         bool
+	outputDir         string
+	force             bool
 	*proxyConfigOptions
 }
 
 func newInjectOptions() *injectOptions {
 	return &injectOptions{
-		inboundPort:         4143,
-		outboundPort:        4140,
-		ignoreInboundPorts:  nil,
-		ignoreOutboundPorts: nil,
-		proxyConfigOptions:  newProxyConfigOptions(),
+		inboundPort:        defaultInboundPort,
+		outboundPort:       defaultOutboundPort,
+		skipInboundPorts:   nil,
+		skipOutboundPorts:  nil,
+		recursive:          false,
+		outputDir:          "",
+		force:              false,
+		proxyConfigOptions: newProxyConfigOptions(),
+	}
+}
+
+func (options *injectOptions) validate() error {
+	if _, err := ports.ParseRanges(options.skipInboundPorts); err != nil {
+		return fmt.Errorf("invalid --skip-inbound-ports: %v", err)
+	}
+	if _, err := ports.ParseRanges(options.skipOutboundPorts); err != nil {
+		return fmt.Errorf("invalid --skip-outbound-ports: %v", err)
 	}
+	return options.proxyConfigOptions.validate()
 }
 
 func newCmdInject() *cobra.Command {
 	options := newInjectOptions()
 
 	cmd := &cobra.Command{
-		Use:   "inject [flags] CONFIG-FILE",
+		Use:   "inject [flags] CONFIG-FILE...",
 		Short: "Add the Linkerd proxy to a Kubernetes config",
 		Long: `Add the Linkerd proxy to a Kubernetes config.
 
 You can use a config file from stdin by using the '-' argument
 with 'linkerd inject'. e.g. curl http://url.to/yml | linkerd inject -
-Also works with a folder containing resource files and other
-sub-folder. e.g. linkerd inject <folder> | kubectl apply -f -
+Also works with several files, and with folders, whose .yaml, .yml and
+.json files are injected, including those of their sub-folders with
+--recursive. e.g. linkerd inject --recursive <folder> | kubectl apply -f -
+The injected configs are written to stdout in the order they are read, or
+to the same paths in --output-dir. Files without Kubernetes resources are
+passed through unchanged.
 	`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
@@ -71,14 +111,27 @@ sub-folder. e.g. linkerd inject <folder> | kubectl apply -f -
 				return fmt.Errorf("please specify a kubernetes resource file")
 			}
 
+			if !options.ignoreCluster {
+				if installOptions := fetchInstallOptions(); installOptions != nil {
+					if err := setInstallDefaults(cmd.PersistentFlags(), installOptions); err != nil {
+						return err
+					}
+				}
+			}
+
 			if err := options.validate(); err != nil {
 				return err
 			}
 
-			in, err := read(args[0])
+			in, err := read(args, options.recursive)
 			if err != nil {
 				return err
 			}
+			if options.outputDir != "" {
+				if err := checkOutputPaths(in); err != nil {
+					return err
+				}
+			}
 
 			exitCode := runInjectCmd(in, os.Stderr, os.Stdout, options)
 			os.Exit(exitCode)
@@ -89,256 +142,313 @@ sub-folder. e.g. linkerd inject <folder> | kubectl apply -f -
 	addProxyConfigFlags(cmd, options.proxyConfigOptions)
 	cmd.PersistentFlags().UintVar(&options.inboundPort, "inbound-port", options.inboundPort, "Proxy port to use for inbound traffic")
 	cmd.PersistentFlags().UintVar(&options.outboundPort, "outbound-port", options.outboundPort, "Proxy port to use for outbound traffic")
-	cmd.PersistentFlags().UintSliceVar(&options.ignoreInboundPorts, "skip-inbound-ports", options.ignoreInboundPorts, "Ports that should skip the proxy and send directly to the application")
-	cmd.PersistentFlags().UintSliceVar(&options.ignoreOutboundPorts, "skip-outbound-ports", options.ignoreOutboundPorts, "Outbound ports that should skip the proxy")
+	cmd.PersistentFlags().StringSliceVar(&options.skipInboundPorts, "skip-inbound-ports", options.skipInboundPorts, "Ports and ranges of ports that should skip the proxy and send directly to the application (e.g. 25,4222-4223); defaults to the "+k8s.ProxySkipInboundPortsAnnotation+" annotation of the pod template")
+	cmd.PersistentFlags().StringSliceVar(&options.skipOutboundPorts, "skip-outbound-ports", options.skipOutboundPorts, "Outbound ports and ranges of ports that should skip the proxy (e.g. 25,4222-4223); defaults to the "+k8s.ProxySkipOutboundPortsAnnotation+" annotation of the pod template")
+	cmd.PersistentFlags().BoolVar(&options.ignoreCluster, "ignore-cluster", options.ignoreCluster, "Do not read the proxy defaults set by \"linkerd install\" from the cluster")
+	cmd.PersistentFlags().BoolVarP(&options.recursive, "recursive", "R", options.recursive, "Also inject the files in the sub-folders of folders")
+	cmd.PersistentFlags().BoolVar(&options.force, "force", options.force, "Inject the workloads that are already injected again, replacing their proxy, so that the flags given apply to them")
+	cmd.PersistentFlags().StringVar(&options.outputDir, "output-dir", options.outputDir, "Write each injected file to its path relative to the folder it was found in, or to its name, in this directory, instead of writing all of them to stdout")
 
 	return cmd
 }
 
-// Read all the resource files found in path into a slice of readers.
-// path can be either a file, directory or stdin.
-func read(path string) ([]io.Reader, error) {
-	var (
-		in  []io.Reader
-		err error
-	)
-	if path == "-" {
-		in = append(in, os.Stdin)
-	} else {
-		in, err = walk(path)
-		if err != nil {
-			return nil, err
+// fetchInstallOptions returns the options recorded by `linkerd install` in the
+// control plane namespace, or nil if the cluster cannot be reached or they
+// were not recorded, in which case inject falls back to its own defaults.
+func fetchInstallOptions() *k8s.InstallOptions {
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeAPIOptions())
+	if err != nil {
+		log.Debugf("Not using install defaults: %v", err)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), installDefaultsTimeout)
+	defer cancel()
+	installOptions, err := kubeAPI.GetInstallOptions(ctx, controlPlaneNamespace)
+	if err != nil {
+		log.Debugf("Not using install defaults: %v", err)
+		return nil
+	}
+	return installOptions
+}
+
+// setInstallDefaults sets the installDefaultFlags to the values they were
+// installed with, so that the flags of each invocation take precedence over
+// the defaults of the control plane. The defaults of a resource are only used
+// if neither its request nor its limit was set on the command line, so that a
+// limit given alone is also the request, rather than being mixed with the
+// installed request.
+func setInstallDefaults(flags *pflag.FlagSet, installOptions *k8s.InstallOptions) error {
+	for _, names := range installDefaultFlags {
+		if anyChanged(flags, names) {
+			continue
+		}
+		for _, name := range names {
+			if value, ok := installOptions.Flag(name); ok {
+				if err := flags.Set(name, value); err != nil {
+					return fmt.Errorf("invalid install default for --%s: %v", name, err)
+				}
+			}
 		}
 	}
+	return nil
+}
 
-	return in, nil
+func anyChanged(flags *pflag.FlagSet, names []string) bool {
+	for _, name := range names {
+		if flags.Changed(name) {
+			return true
+		}
+	}
+	return false
 }
 
-// Returns the integer representation of os.Exit code; 0 on success and 1 on failure.
-func runInjectCmd(inputs []io.Reader, errWriter, outWriter io.Writer, options *injectOptions) int {
-	postInjectBuf := &bytes.Buffer{}
+// injectFile is a config file to inject.
+type injectFile struct {
+	// name is the path of the file, as given or found in a folder, or "stdin".
+	name string
+	// relPath is the path of the file relative to the folder it was found in,
+	// or its base name if it was given, and is where it is written to in
+	// --output-dir. It is empty for stdin.
+	relPath string
+	in      io.Reader
+}
 
-	for _, input := range inputs {
-		err := InjectYAML(input, postInjectBuf, options)
-		if err != nil {
-			fmt.Fprintf(errWriter, "Error injecting linkerd proxy: %v\n", err)
-			return 1
+// Read all the resource files found in paths into a slice of injectFiles.
+// Each path can be either a file, directory or stdin.
+func read(paths []string, recursive bool) ([]injectFile, error) {
+	var in []injectFile
+	for _, path := range paths {
+		if path == "-" {
+			in = append(in, injectFile{name: "stdin", in: os.Stdin})
+			continue
 		}
-		_, err = io.Copy(outWriter, postInjectBuf)
+		files, err := walk(path, recursive)
 		if err != nil {
-			fmt.Fprintf(errWriter, "Error printing YAML: %v\n", err)
-			return 1
+			return nil, err
 		}
+		in = append(in, files...)
 	}
-	return 0
+	return in, nil
 }
 
-/* Given a ObjectMeta, update ObjectMeta in place with the new labels and
- * annotations.
- */
-func injectObjectMeta(t *metaV1.ObjectMeta, k8sLabels map[string]string, options *injectOptions) {
-	if t.Annotations == nil {
-		t.Annotations = make(map[string]string)
+// checkOutputPaths checks that each of files can be written to its own path
+// in --output-dir.
+func checkOutputPaths(files []injectFile) error {
+	names := map[string]string{}
+	for _, file := range files {
+		if file.relPath == "" {
+			return fmt.Errorf("--output-dir cannot be used to write configs read from %s", file.name)
+		}
+		if name, ok := names[file.relPath]; ok {
+			return fmt.Errorf("%s and %s would both be written to [%s] in --output-dir", name, file.name, file.relPath)
+		}
+		names[file.relPath] = file.name
 	}
-	t.Annotations[k8s.CreatedByAnnotation] = k8s.CreatedByAnnotationValue()
-	t.Annotations[k8s.ProxyVersionAnnotation] = options.linkerdVersion
+	return nil
+}
 
-	if t.Labels == nil {
-		t.Labels = make(map[string]string)
-	}
-	t.Labels[k8s.ControllerNSLabel] = controlPlaneNamespace
-	for k, v := range k8sLabels {
-		t.Labels[k] = v
-	}
+// injectReport writes a line to w for each resource and file that command,
+// inject or uninject, passes through without changing it, saying why, and
+// counts them to summarize what was changed.
+type injectReport struct {
+	w         io.Writer
+	command   string
+	changed   int
+	skipped   int
+	files     int
+	untouched int
 }
 
-/* Given a PodSpec, update the PodSpec in place with the sidecar
- * and init-container injected. If the pod is unsuitable for having them
- * injected, return false.
- */
-func injectPodSpec(t *v1.PodSpec, identity k8s.TLSIdentity, controlPlaneDNSNameOverride string, options *injectOptions) bool {
-	// Pods with `hostNetwork=true` share a network namespace with the host. The
-	// init-container would destroy the iptables configuration on the host, so
-	// skip the injection in this case.
-	if t.HostNetwork {
-		return false
-	}
+func newInjectReport(w io.Writer, command string) *injectReport {
+	return &injectReport{w: w, command: command}
+}
+
+func (r *injectReport) skip(kind, name, reason string) {
+	fmt.Fprintf(r.w, "%s \"%s\" skipped: %s\n", kind, name, reason)
+	r.skipped++
+}
 
-	f := false
-	inboundSkipPorts := append(options.ignoreInboundPorts, options.proxyControlPort, options.proxyMetricsPort)
-	inboundSkipPortsStr := make([]string, len(inboundSkipPorts))
-	for i, p := range inboundSkipPorts {
-		inboundSkipPortsStr[i] = strconv.Itoa(int(p))
+// change counts a resource that was changed, writing what was changed, if
+// that is not empty.
+func (r *injectReport) change(kind, name, description string) {
+	if description != "" {
+		fmt.Fprintf(r.w, "%s \"%s\" %s\n", kind, name, description)
 	}
+	r.changed++
+}
+
+func (r *injectReport) resources() int {
+	return r.changed + r.skipped
+}
 
-	outboundSkipPortsStr := make([]string, len(options.ignoreOutboundPorts))
-	for i, p := range options.ignoreOutboundPorts {
-		outboundSkipPortsStr[i] = strconv.Itoa(int(p))
+func (r *injectReport) summarize() {
+	fmt.Fprintf(r.w, "Summary: %d of %d resources %sed, from %s", r.changed, r.resources(), r.command, pluralize(r.files, "file"))
+	if r.untouched > 0 {
+		fmt.Fprintf(r.w, " (%d without Kubernetes resources)", r.untouched)
 	}
+	fmt.Fprintln(r.w)
+}
 
-	initArgs := []string{
-		"--incoming-proxy-port", fmt.Sprintf("%d", options.inboundPort),
-		"--outgoing-proxy-port", fmt.Sprintf("%d", options.outboundPort),
-		"--proxy-uid", fmt.Sprintf("%d", options.proxyUID),
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
 	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// Returns the integer representation of os.Exit code; 0 on success and 1 on failure.
+func runInjectCmd(inputs []injectFile, errWriter, outWriter io.Writer, options *injectOptions) int {
+	report := newInjectReport(errWriter, "inject")
+	return transformFiles(inputs, outWriter, report, injector{options}, options.outputDir, "injecting linkerd proxy into")
+}
+
+// transformFiles transforms each of inputs with rt, writing them to outWriter,
+// or to their paths in outputDir if it is set, and the report of what was
+// transformed to report. It returns the os.Exit code; 0 on success and 1 on
+// failure, whose error describes action on the file that failed.
+func transformFiles(inputs []injectFile, outWriter io.Writer, report *injectReport, rt resourceTransformer, outputDir, action string) int {
+	for _, input := range inputs {
+		out, err := transformFileYAML(input, report, rt, outputDir)
+		if err != nil {
+			fmt.Fprintf(report.w, "Error %s %s: %v\n", action, input.name, err)
+			return 1
+		}
 
-	if len(inboundSkipPortsStr) > 0 {
-		initArgs = append(initArgs, "--inbound-ports-to-ignore")
-		initArgs = append(initArgs, strings.Join(inboundSkipPortsStr, ","))
+		if outputDir != "" {
+			err = writeOutputFile(filepath.Join(outputDir, input.relPath), out)
+		} else {
+			if len(inputs) > 1 && !bytes.HasSuffix(out, []byte("---\n")) {
+				// Separate the JSON of a file from the documents of the
+				// next file written to stdout.
+				out = append(out, []byte("---\n")...)
+			}
+			_, err = outWriter.Write(out)
+		}
+		if err != nil {
+			fmt.Fprintf(report.w, "Error printing YAML: %v\n", err)
+			return 1
+		}
 	}
 
-	if len(outboundSkipPortsStr) > 0 {
-		initArgs = append(initArgs, "--outbound-ports-to-ignore")
-		initArgs = append(initArgs, strings.Join(outboundSkipPortsStr, ","))
+	report.summarize()
+	return 0
+}
+
+// transformFileYAML returns the YAML of file transformed by rt. A file without
+// any Kubernetes resource, such as a values file found along with the resource
+// files of a folder, is returned unchanged, and reported.
+func transformFileYAML(file injectFile, report *injectReport, rt resourceTransformer, outputDir string) ([]byte, error) {
+	in, err := ioutil.ReadAll(file.in)
+	if err != nil {
+		return nil, err
 	}
+	report.files++
 
-	initContainer := v1.Container{
-		Name:                     "linkerd-init",
-		Image:                    options.taggedProxyInitImage(),
-		ImagePullPolicy:          v1.PullPolicy(options.imagePullPolicy),
-		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
-		Args: initArgs,
-		SecurityContext: &v1.SecurityContext{
-			Capabilities: &v1.Capabilities{
-				Add: []v1.Capability{v1.Capability("NET_ADMIN")},
-			},
-			Privileged: &f,
-		},
+	resources := report.resources()
+	out := &bytes.Buffer{}
+	if err := transformYAML(bytes.NewReader(in), out, report, rt); err != nil {
+		return nil, err
 	}
-	controlPlaneDNS := fmt.Sprintf("proxy-api.%s.svc.cluster.local", controlPlaneNamespace)
-	if controlPlaneDNSNameOverride != "" {
-		controlPlaneDNS = controlPlaneDNSNameOverride
+	if report.resources() > resources || len(bytes.TrimSpace(in)) == 0 {
+		return out.Bytes(), nil
 	}
 
-	metricsPort := intstr.IntOrString{
-		IntVal: int32(options.proxyMetricsPort),
+	fmt.Fprintf(report.w, "%s passed through unchanged: it has no Kubernetes resources\n", file.name)
+	report.untouched++
+	if outputDir != "" {
+		return in, nil
 	}
-	proxyProbe := v1.Probe{
-		Handler: v1.Handler{
-			HTTPGet: &v1.HTTPGetAction{
-				Path: "/metrics",
-				Port: metricsPort,
-			},
-		},
-		InitialDelaySeconds: 10,
+	// Separate it from the documents of the next file written to stdout.
+	if !bytes.HasSuffix(in, []byte("\n")) {
+		in = append(in, '\n')
 	}
+	return append(in, []byte("---\n")...), nil
+}
 
-	sidecar := v1.Container{
-		Name:                     "linkerd-proxy",
-		Image:                    options.taggedProxyImage(),
-		ImagePullPolicy:          v1.PullPolicy(options.imagePullPolicy),
-		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
-		SecurityContext: &v1.SecurityContext{
-			RunAsUser: &options.proxyUID,
-		},
-		Ports: []v1.ContainerPort{
-			{
-				Name:          "linkerd-proxy",
-				ContainerPort: int32(options.inboundPort),
-			},
-			{
-				Name:          "linkerd-metrics",
-				ContainerPort: int32(options.proxyMetricsPort),
-			},
-		},
-		Env: []v1.EnvVar{
-			{Name: "LINKERD2_PROXY_LOG", Value: options.proxyLogLevel},
-			{Name: "LINKERD2_PROXY_BIND_TIMEOUT", Value: options.proxyBindTimeout},
-			{
-				Name:  "LINKERD2_PROXY_CONTROL_URL",
-				Value: fmt.Sprintf("tcp://%s:%d", controlPlaneDNS, options.proxyAPIPort),
-			},
-			{Name: "LINKERD2_PROXY_CONTROL_LISTENER", Value: fmt.Sprintf("tcp://0.0.0.0:%d", options.proxyControlPort)},
-			{Name: "LINKERD2_PROXY_METRICS_LISTENER", Value: fmt.Sprintf("tcp://0.0.0.0:%d", options.proxyMetricsPort)},
-			{Name: "LINKERD2_PROXY_PRIVATE_LISTENER", Value: fmt.Sprintf("tcp://127.0.0.1:%d", options.outboundPort)},
-			{Name: "LINKERD2_PROXY_PUBLIC_LISTENER", Value: fmt.Sprintf("tcp://0.0.0.0:%d", options.inboundPort)},
-			{
-				Name:      PodNamespaceEnvVarName,
-				ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.namespace"}},
-			},
-		},
-		ReadinessProbe: &proxyProbe,
-		LivenessProbe:  &proxyProbe,
-	}
-
-	// Special case if the caller specifies that
-	// LINKERD2_PROXY_OUTBOUND_ROUTER_CAPACITY be set on the pod.
-	// We key off of any container image in the pod. Ideally we would instead key
-	// off of something at the top-level of the PodSpec, but there is nothing
-	// easily identifiable at that level.
-	// This is currently only used by the Prometheus pod in the control-plane.
-	for _, container := range t.Containers {
-		if capacity, ok := options.proxyOutboundCapacity[container.Image]; ok {
-			sidecar.Env = append(sidecar.Env,
-				v1.EnvVar{
-					Name:  "LINKERD2_PROXY_OUTBOUND_ROUTER_CAPACITY",
-					Value: fmt.Sprintf("%d", capacity),
-				},
-			)
-			break
-		}
+func writeOutputFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
 	}
+	return ioutil.WriteFile(path, content, 0644)
+}
 
-	if options.enableTLS() {
-		yes := true
+// InjectYAML takes an input stream of YAML, outputting injected YAML to out,
+// and reporting each resource that is passed through without being injected.
+func InjectYAML(in io.Reader, out io.Writer, report *injectReport, options *injectOptions) error {
+	return transformYAML(in, out, report, injector{options})
+}
 
-		configMapVolume := v1.Volume{
-			Name: "linkerd-trust-anchors",
-			VolumeSource: v1.VolumeSource{
-				ConfigMap: &v1.ConfigMapVolumeSource{
-					LocalObjectReference: v1.LocalObjectReference{Name: k8s.TLSTrustAnchorConfigMapName},
-					Optional:             &yes,
-				},
-			},
-		}
-		secretVolume := v1.Volume{
-			Name: "linkerd-secrets",
-			VolumeSource: v1.VolumeSource{
-				Secret: &v1.SecretVolumeSource{
-					SecretName: identity.ToSecretName(),
-					Optional:   &yes,
-				},
-			},
-		}
+// resourceTransformer changes the pod templates of the workloads read by
+// transformYAML, for inject and uninject.
+type resourceTransformer interface {
+	// transform changes the pod template of w in place, returning whether it
+	// did, and reporting the workload either way.
+	transform(w *workload, report *injectReport) (bool, error)
+}
 
-		base := "/var/linkerd-io"
-		configMapBase := base + "/trust-anchors"
-		secretBase := base + "/identity"
-		tlsEnvVars := []v1.EnvVar{
-			{Name: "LINKERD2_PROXY_TLS_TRUST_ANCHORS", Value: configMapBase + "/" + k8s.TLSTrustAnchorFileName},
-			{Name: "LINKERD2_PROXY_TLS_CERT", Value: secretBase + "/" + k8s.TLSCertFileName},
-			{Name: "LINKERD2_PROXY_TLS_PRIVATE_KEY", Value: secretBase + "/" + k8s.TLSPrivateKeyFileName},
-			{
-				Name:  "LINKERD2_PROXY_TLS_POD_IDENTITY",
-				Value: identity.ToDNSName(),
-			},
-			{Name: "LINKERD2_PROXY_CONTROLLER_NAMESPACE", Value: controlPlaneNamespace},
-			{Name: "LINKERD2_PROXY_TLS_CONTROLLER_IDENTITY", Value: identity.ToControllerIdentity().ToDNSName()},
-		}
+// workload is a resource with a pod template, of one of the kinds inject
+// supports, parsed as the type of its kind.
+type workload struct {
+	kind      string
+	name      string
+	namespace string
+	obj       interface{}
 
-		sidecar.Env = append(sidecar.Env, tlsEnvVars...)
-		sidecar.VolumeMounts = []v1.VolumeMount{
-			{Name: configMapVolume.Name, MountPath: configMapBase, ReadOnly: true},
-			{Name: secretVolume.Name, MountPath: secretBase, ReadOnly: true},
-		}
+	podSpec    *v1.PodSpec
+	objectMeta *metaV1.ObjectMeta
 
-		t.Volumes = append(t.Volumes, configMapVolume, secretVolume)
-	}
+	// label identifies the workload on the pods of its pod template, e.g.
+	// linkerd.io/proxy-deployment, and is empty for pods.
+	label string
+}
 
-	t.Containers = append(t.Containers, sidecar)
-	t.InitContainers = append(t.InitContainers, initContainer)
+// documentFormat is the serialization of an input document, which the
+// document transformed by transformYAML keeps.
+type documentFormat int
+
+const (
+	yamlFormat documentFormat = iota
+	jsonFormat
+)
 
-	return true
+// formatOf returns the format of document: JSON if it is a JSON object or
+// array, and YAML otherwise.
+func formatOf(document []byte) documentFormat {
+	trimmed := bytes.TrimSpace(document)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return jsonFormat
+	}
+	return yamlFormat
 }
 
-// InjectYAML takes an input stream of YAML, outputting injected YAML to out.
-func InjectYAML(in io.Reader, out io.Writer, options *injectOptions) error {
-	reader := yamlDecoder.NewYAMLReader(bufio.NewReaderSize(in, 4096))
+func (f documentFormat) marshal(obj interface{}) ([]byte, error) {
+	if f == jsonFormat {
+		return json.MarshalIndent(obj, "", "  ")
+	}
+	return yaml.Marshal(obj)
+}
+
+// transformYAML takes an input stream of YAML, outputting the YAML transformed
+// by rt to out. Each document is output in the format it was read in, so JSON
+// documents of the stream stay JSON, and an input that is entirely JSON, such
+// as a single object or an array of them, is output as JSON only, without
+// document separators, by transformJSON.
+func transformYAML(in io.Reader, out io.Writer, report *injectReport, rt resourceTransformer) error {
+	input, err := ioutil.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	if values, ok := splitJSON(input); ok {
+		return transformJSON(values, out, report, rt)
+	}
+
+	reader := yamlDecoder.NewYAMLReader(bufio.NewReaderSize(bytes.NewReader(input), 4096))
 
 	// Iterate over all YAML objects in the input
 	for {
 		// Read a single YAML object
-		bytes, err := reader.Read()
+		document, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
@@ -346,19 +456,74 @@ func InjectYAML(in io.Reader, out io.Writer, options *injectOptions) error {
 			return err
 		}
 
-		result, err := injectResource(bytes, options)
+		format := formatOf(document)
+		result, err := transformResource(document, format, report, rt)
 		if err != nil {
 			return err
 		}
 
 		out.Write(result)
+		if format == jsonFormat && !bytes.HasSuffix(result, []byte("\n")) {
+			out.Write([]byte("\n"))
+		}
 		out.Write([]byte("---\n"))
 	}
 
 	return nil
 }
 
-func injectList(b []byte, options *injectOptions) ([]byte, error) {
+// splitJSON returns the JSON values input consists of, if it is a stream of
+// JSON values only, and false otherwise.
+func splitJSON(input []byte) ([]json.RawMessage, bool) {
+	if formatOf(input) != jsonFormat {
+		return nil, false
+	}
+	values := []json.RawMessage{}
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	for {
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err == io.EOF {
+			return values, true
+		} else if err != nil {
+			return nil, false
+		}
+		values = append(values, value)
+	}
+}
+
+// transformJSON outputs the JSON values of an input that is entirely JSON
+// transformed by rt to out, each on its own line. The resources of a JSON
+// array are transformed one by one, and output as an array.
+func transformJSON(values []json.RawMessage, out io.Writer, report *injectReport, rt resourceTransformer) error {
+	for _, value := range values {
+		var result []byte
+		var elements []json.RawMessage
+		if json.Unmarshal(value, &elements) == nil {
+			for i, element := range elements {
+				transformed, err := transformResource(element, jsonFormat, report, rt)
+				if err != nil {
+					return err
+				}
+				elements[i] = transformed
+			}
+			var err error
+			if result, err = jsonFormat.marshal(elements); err != nil {
+				return err
+			}
+		} else {
+			var err error
+			if result, err = transformResource(value, jsonFormat, report, rt); err != nil {
+				return err
+			}
+		}
+
+		out.Write(result)
+		out.Write([]byte("\n"))
+	}
+	return nil
+}
+
+func transformList(b []byte, format documentFormat, report *injectReport, rt resourceTransformer) ([]byte, error) {
 	var sourceList v1.List
 	if err := yaml.Unmarshal(b, &sourceList); err != nil {
 		return nil, err
@@ -367,27 +532,76 @@ func injectList(b []byte, options *injectOptions) ([]byte, error) {
 	items := []runtime.RawExtension{}
 
 	for _, item := range sourceList.Items {
-		result, err := injectResource(item.Raw, options)
+		// The kubernetes internal representation of the items is JSON, which
+		// RawExtensions hold, whatever the format of the list.
+		transformed, err := transformResource(item.Raw, jsonFormat, report, rt)
 		if err != nil {
 			return nil, err
 		}
 
-		// At this point, we have yaml. The kubernetes internal representation is
-		// json. Because we're building a list from RawExtensions, the yaml needs
-		// to be converted to json.
-		injected, err := yaml.YAMLToJSON(result)
-		if err != nil {
+		items = append(items, runtime.RawExtension{Raw: transformed})
+	}
+
+	sourceList.Items = items
+	return format.marshal(sourceList)
+}
+
+// transformResource returns the document transformed by rt, serialized in
+// format, or the document itself if it is not changed.
+func transformResource(bytes []byte, format documentFormat, report *injectReport, rt resourceTransformer) ([]byte, error) {
+	// Unmarshal the object enough to read the Kind field
+	var meta metaV1.TypeMeta
+	if err := yaml.Unmarshal(bytes, &meta); err != nil {
+		// Documents that are not objects, such as lists of values, are not
+		// resources either, as long as they are YAML.
+		var document interface{}
+		if yaml.Unmarshal(bytes, &document) != nil {
 			return nil, err
 		}
+		return bytes, nil
+	}
 
-		items = append(items, runtime.RawExtension{Raw: injected})
+	switch meta.Kind {
+	case "List":
+		// Lists are a little different than the other types. There's no immediate
+		// pod template. Because of this, we do a recursive call for each element
+		// in the list (instead of just marshaling the transformed pod template).
+		return transformList(bytes, format, report, rt)
+
+	case "":
+		// Documents without a kind, such as those only holding comments, are
+		// not resources.
+		return bytes, nil
 	}
 
-	sourceList.Items = items
-	return yaml.Marshal(sourceList)
+	w, err := parseWorkload(meta.Kind, bytes)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		var resource struct {
+			metaV1.ObjectMeta `json:"metadata"`
+		}
+		if err := yaml.Unmarshal(bytes, &resource); err != nil {
+			return nil, err
+		}
+		report.skip(meta.Kind, resource.Name, fmt.Sprintf("kind %s is not supported by linkerd %s", meta.Kind, report.command))
+		return bytes, nil
+	}
+
+	// If we don't change anything in the pod template then output the
+	// original serialization of the original object. Otherwise, output the
+	// serialization of the modified object.
+	changed, err := rt.transform(w, report)
+	if err != nil || !changed {
+		return bytes, err
+	}
+	return format.marshal(w.obj)
 }
 
-func injectResource(bytes []byte, options *injectOptions) ([]byte, error) {
+// parseWorkload parses bytes as the type of kind, returning nil if kind has no
+// pod template inject supports.
+func parseWorkload(kind string, bytes []byte) (*workload, error) {
 	// The Kuberentes API is versioned and each version has an API modeled
 	// with its own distinct Go types. If we tell `yaml.Unmarshal()` which
 	// version we support then it will provide a representation of that
@@ -395,51 +609,18 @@ func injectResource(bytes []byte, options *injectOptions) ([]byte, error) {
 	// to supply one object (of one type), so first we have to determine
 	// what kind of object `bytes` represents so we can pass an object of
 	// the correct type to `yaml.Unmarshal()`.
-	// ---------------------------------------
-	// Note: bytes is expected to be YAML and will only modify it when a
-	// supported type is found. Otherwise, it is returned unmodified.
-
-	// Unmarshal the object enough to read the Kind field
-	var meta metaV1.TypeMeta
-	if err := yaml.Unmarshal(bytes, &meta); err != nil {
-		return nil, err
-	}
-
-	// obj and podTemplateSpec will reference zero or one the following
-	// objects, depending on the type.
-	var obj interface{}
-	var podSpec *v1.PodSpec
-	var objectMeta *metaV1.ObjectMeta
-	var DNSNameOverride string
-	k8sLabels := map[string]string{}
-
-	// When injecting the linkerd proxy into a linkerd controller pod. The linkerd proxy's
-	// LINKERD2_PROXY_CONTROL_URL variable must be set to localhost for the following reasons:
-	//	1. According to https://github.com/kubernetes/minikube/issues/1568, minikube has an issue
-	//     where pods are unable to connect to themselves through their associated service IP.
-	//     Setting the LINKERD2_PROXY_CONTROL_URL to localhost allows the proxy to bypass kube DNS
-	//     name resolution as a workaround to this issue.
-	//  2. We avoid the TLS overhead in encrypting and decrypting intra-pod traffic i.e. traffic
-	//     between containers in the same pod.
-	//  3. Using a Service IP instead of localhost would mean intra-pod traffic would be load-balanced
-	//     across all controller pod replicas. This is undesirable as we would want all traffic between
-	//	   containers to be self contained.
-	//  4. We skip recording telemetry for intra-pod traffic within the control plane.
-	switch meta.Kind {
+	w := &workload{kind: kind}
+	switch kind {
 	case "Deployment":
 		var deployment v1beta1.Deployment
 		if err := yaml.Unmarshal(bytes, &deployment); err != nil {
 			return nil, err
 		}
 
-		if deployment.Name == ControlPlanePodName && deployment.Namespace == controlPlaneNamespace {
-			DNSNameOverride = LocalhostDNSNameOverride
-		}
-
-		obj = &deployment
-		k8sLabels[k8s.ProxyDeploymentLabel] = deployment.Name
-		podSpec = &deployment.Spec.Template.Spec
-		objectMeta = &deployment.Spec.Template.ObjectMeta
+		w.obj = &deployment
+		w.label = k8s.ProxyDeploymentLabel
+		w.podSpec = &deployment.Spec.Template.Spec
+		w.objectMeta = &deployment.Spec.Template.ObjectMeta
 
 	case "ReplicationController":
 		var rc v1.ReplicationController
@@ -447,10 +628,10 @@ func injectResource(bytes []byte, options *injectOptions) ([]byte, error) {
 			return nil, err
 		}
 
-		obj = &rc
-		k8sLabels[k8s.ProxyReplicationControllerLabel] = rc.Name
-		podSpec = &rc.Spec.Template.Spec
-		objectMeta = &rc.Spec.Template.ObjectMeta
+		w.obj = &rc
+		w.label = k8s.ProxyReplicationControllerLabel
+		w.podSpec = &rc.Spec.Template.Spec
+		w.objectMeta = &rc.Spec.Template.ObjectMeta
 
 	case "ReplicaSet":
 		var rs v1beta1.ReplicaSet
@@ -458,10 +639,10 @@ func injectResource(bytes []byte, options *injectOptions) ([]byte, error) {
 			return nil, err
 		}
 
-		obj = &rs
-		k8sLabels[k8s.ProxyReplicaSetLabel] = rs.Name
-		podSpec = &rs.Spec.Template.Spec
-		objectMeta = &rs.Spec.Template.ObjectMeta
+		w.obj = &rs
+		w.label = k8s.ProxyReplicaSetLabel
+		w.podSpec = &rs.Spec.Template.Spec
+		w.objectMeta = &rs.Spec.Template.ObjectMeta
 
 	case "Job":
 		var job batchV1.Job
@@ -469,10 +650,21 @@ func injectResource(bytes []byte, options *injectOptions) ([]byte, error) {
 			return nil, err
 		}
 
-		obj = &job
-		k8sLabels[k8s.ProxyJobLabel] = job.Name
-		podSpec = &job.Spec.Template.Spec
-		objectMeta = &job.Spec.Template.ObjectMeta
+		w.obj = &job
+		w.label = k8s.ProxyJobLabel
+		w.podSpec = &job.Spec.Template.Spec
+		w.objectMeta = &job.Spec.Template.ObjectMeta
+
+	case "CronJob":
+		var cronJob batchV1beta1.CronJob
+		if err := yaml.Unmarshal(bytes, &cronJob); err != nil {
+			return nil, err
+		}
+
+		w.obj = &cronJob
+		w.label = k8s.ProxyCronJobLabel
+		w.podSpec = &cronJob.Spec.JobTemplate.Spec.Template.Spec
+		w.objectMeta = &cronJob.Spec.JobTemplate.Spec.Template.ObjectMeta
 
 	case "DaemonSet":
 		var ds v1beta1.DaemonSet
@@ -480,10 +672,10 @@ func injectResource(bytes []byte, options *injectOptions) ([]byte, error) {
 			return nil, err
 		}
 
-		obj = &ds
-		k8sLabels[k8s.ProxyDaemonSetLabel] = ds.Name
-		podSpec = &ds.Spec.Template.Spec
-		objectMeta = &ds.Spec.Template.ObjectMeta
+		w.obj = &ds
+		w.label = k8s.ProxyDaemonSetLabel
+		w.podSpec = &ds.Spec.Template.Spec
+		w.objectMeta = &ds.Spec.Template.ObjectMeta
 
 	case "StatefulSet":
 		var statefulset appsV1.StatefulSet
@@ -491,10 +683,10 @@ func injectResource(bytes []byte, options *injectOptions) ([]byte, error) {
 			return nil, err
 		}
 
-		obj = &statefulset
-		k8sLabels[k8s.ProxyStatefulSetLabel] = statefulset.Name
-		podSpec = &statefulset.Spec.Template.Spec
-		objectMeta = &statefulset.Spec.Template.ObjectMeta
+		w.obj = &statefulset
+		w.label = k8s.ProxyStatefulSetLabel
+		w.podSpec = &statefulset.Spec.Template.Spec
+		w.objectMeta = &statefulset.Spec.Template.ObjectMeta
 
 	case "Pod":
 		var pod v1.Pod
@@ -502,79 +694,137 @@ func injectResource(bytes []byte, options *injectOptions) ([]byte, error) {
 			return nil, err
 		}
 
-		obj = &pod
-		podSpec = &pod.Spec
-		objectMeta = &pod.ObjectMeta
+		w.obj = &pod
+		w.podSpec = &pod.Spec
+		w.objectMeta = &pod.ObjectMeta
 
-	case "List":
-		// Lists are a little different than the other types. There's no immediate
-		// pod template. Because of this, we do a recursive call for each element
-		// in the list (instead of just marshaling the injected pod template).
-		return injectList(bytes, options)
+	default:
+		return nil, nil
+	}
 
+	metaAccessor, err := k8sMeta.Accessor(w.obj)
+	if err != nil {
+		return nil, err
 	}
+	w.name = metaAccessor.GetName()
+	w.namespace = metaAccessor.GetNamespace()
+	return w, nil
+}
 
-	// If we don't inject anything into the pod template then output the
-	// original serialization of the original object. Otherwise, output the
-	// serialization of the modified object.
-	output := bytes
-	if podSpec != nil {
-		metaAccessor, err := k8sMeta.Accessor(obj)
-		if err != nil {
-			return nil, err
-		}
+// injector injects the proxy into the pod templates of workloads.
+type injector struct {
+	options *injectOptions
+}
 
-		// The namespace isn't necessarily in the input so it has to be substituted
-		// at runtime. The proxy recognizes the "$NAME" syntax for this variable
-		// but not necessarily other variables.
-		identity := k8s.TLSIdentity{
-			Name:                metaAccessor.GetName(),
-			Kind:                strings.ToLower(meta.Kind),
-			Namespace:           "$" + PodNamespaceEnvVarName,
-			ControllerNamespace: controlPlaneNamespace,
-		}
+func (i injector) transform(w *workload, report *injectReport) (bool, error) {
+	options := i.options
+	config := options.proxyConfig(options.inboundPort, options.outboundPort)
 
-		if injectPodSpec(podSpec, identity, DNSNameOverride, options) {
-			injectObjectMeta(objectMeta, k8sLabels, options)
-			var err error
-			output, err = yaml.Marshal(obj)
-			if err != nil {
-				return nil, err
-			}
+	// When injecting the linkerd proxy into a linkerd controller pod. The linkerd proxy's
+	// LINKERD2_PROXY_CONTROL_URL variable must be set to localhost for the following reasons:
+	//	1. According to https://github.com/kubernetes/minikube/issues/1568, minikube has an issue
+	//     where pods are unable to connect to themselves through their associated service IP.
+	//     Setting the LINKERD2_PROXY_CONTROL_URL to localhost allows the proxy to bypass kube DNS
+	//     name resolution as a workaround to this issue.
+	//  2. We avoid the TLS overhead in encrypting and decrypting intra-pod traffic i.e. traffic
+	//     between containers in the same pod.
+	//  3. Using a Service IP instead of localhost would mean intra-pod traffic would be load-balanced
+	//     across all controller pod replicas. This is undesirable as we would want all traffic between
+	//	   containers to be self contained.
+	//  4. We skip recording telemetry for intra-pod traffic within the control plane.
+	var DNSNameOverride string
+	if w.kind == "Deployment" && w.name == ControlPlanePodName && w.namespace == controlPlaneNamespace {
+		DNSNameOverride = inject.LocalhostDNSNameOverride
+	}
+
+	k8sLabels := map[string]string{}
+	if w.label != "" {
+		k8sLabels[w.label] = w.name
+	}
+
+	// The namespace isn't necessarily in the input so it has to be substituted
+	// at runtime. The proxy recognizes the "$NAME" syntax for this variable
+	// but not necessarily other variables.
+	identity := k8s.TLSIdentity{
+		Name:                w.name,
+		Kind:                strings.ToLower(w.kind),
+		Namespace:           "$" + inject.PodNamespaceEnvVarName,
+		ControllerNamespace: controlPlaneNamespace,
+	}
+
+	inboundSkipPorts, err := inject.SkippedPorts(options.skipInboundPorts, w.objectMeta, k8s.ProxySkipInboundPortsAnnotation)
+	if err != nil {
+		return false, fmt.Errorf("%s \"%s\": %v", w.kind, w.name, err)
+	}
+	outboundSkipPorts, err := inject.SkippedPorts(options.skipOutboundPorts, w.objectMeta, k8s.ProxySkipOutboundPortsAnnotation)
+	if err != nil {
+		return false, fmt.Errorf("%s \"%s\": %v", w.kind, w.name, err)
+	}
+
+	reason := ""
+	if inject.IsInjected(w.objectMeta, w.podSpec) {
+		if options.force {
+			inject.RemoveProxy(w.podSpec)
+		} else {
+			reason = "already injected; pass --force to inject it again"
 		}
 	}
+	if reason == "" {
+		reason = config.InjectPodSpec(w.podSpec, identity, DNSNameOverride, inboundSkipPorts, outboundSkipPorts)
+	}
+	if reason != "" {
+		report.skip(w.kind, w.name, reason)
+		return false, nil
+	}
 
-	return output, nil
+	config.InjectObjectMeta(w.objectMeta, k8sLabels)
+	inject.RecordSkippedPorts(w.objectMeta, k8s.ProxySkipInboundPortsAnnotation, inboundSkipPorts)
+	inject.RecordSkippedPorts(w.objectMeta, k8s.ProxySkipOutboundPortsAnnotation, outboundSkipPorts)
+	report.change(w.kind, w.name, "")
+	return true, nil
 }
 
-// walk walks the file tree rooted at path. path may be a file or a directory.
-// Creates a reader for each file found.
-func walk(path string) ([]io.Reader, error) {
+// walk returns the file at path, or the .yaml, .yml and .json files of the
+// folder at path, including those of its sub-folders if recursive is set.
+func walk(path string, recursive bool) ([]injectFile, error) {
 	stat, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
 
 	if !stat.IsDir() {
-		file, err := os.Open(path)
+		file, err := readFile(path, filepath.Base(path))
 		if err != nil {
 			return nil, err
 		}
 
-		return []io.Reader{file}, nil
+		return []injectFile{file}, nil
 	}
 
-	var in []io.Reader
-	werr := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+	var in []injectFile
+	werr := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
 		if info.IsDir() {
+			if filePath != path && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch filepath.Ext(filePath) {
+		case ".yaml", ".yml", ".json":
+		default:
 			return nil
 		}
 
-		file, err := os.Open(path)
+		relPath, err := filepath.Rel(path, filePath)
+		if err != nil {
+			return err
+		}
+		file, err := readFile(filePath, relPath)
 		if err != nil {
 			return err
 		}
@@ -589,3 +839,13 @@ func walk(path string) ([]io.Reader, error) {
 
 	return in, nil
 }
+
+// readFile reads the file at path, so that no file is kept open while the
+// others are injected.
+func readFile(path, relPath string) (injectFile, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return injectFile{}, err
+	}
+	return injectFile{name: path, relPath: relPath, in: bytes.NewReader(content)}, nil
+}
@@ -3,12 +3,20 @@ package cmd
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/api/core/v1"
+	yamlDecoder "k8s.io/apimachinery/pkg/util/yaml"
 )
 
 func TestInjectYAML(t *testing.T) {
@@ -19,20 +27,89 @@ func TestInjectYAML(t *testing.T) {
 	tlsOptions.linkerdVersion = "testinjectversion"
 	tlsOptions.tls = "optional"
 
+	registryOptions := newInjectOptions()
+	registryOptions.linkerdVersion = "testinjectversion"
+	registryOptions.dockerRegistry = "registry.example.com:5000/linkerd-io"
+
+	resourceOptions := newInjectOptions()
+	resourceOptions.linkerdVersion = "testinjectversion"
+	resourceOptions.proxyCPURequest = "100m"
+	resourceOptions.proxyMemoryRequest = "64Mi"
+	resourceOptions.proxyCPULimit = "1"
+	resourceOptions.proxyMemoryLimit = "256Mi"
+
+	limitsOptions := newInjectOptions()
+	limitsOptions.linkerdVersion = "testinjectversion"
+	limitsOptions.proxyCPULimit = "1"
+	limitsOptions.proxyMemoryLimit = "256Mi"
+
+	requestsOptions := newInjectOptions()
+	requestsOptions.linkerdVersion = "testinjectversion"
+	requestsOptions.proxyCPURequest = "100m"
+	requestsOptions.proxyMemoryRequest = "64Mi"
+
+	forceOptions := newInjectOptions()
+	forceOptions.linkerdVersion = "testinjectversion"
+	forceOptions.force = true
+
+	forceTLSOptions := newInjectOptions()
+	forceTLSOptions.linkerdVersion = "testinjectversion"
+	forceTLSOptions.tls = "optional"
+	forceTLSOptions.force = true
+
+	skipInboundOptions := newInjectOptions()
+	skipInboundOptions.linkerdVersion = "testinjectversion"
+	skipInboundOptions.skipInboundPorts = []string{"25", "3306"}
+
+	skipRangeOptions := newInjectOptions()
+	skipRangeOptions.linkerdVersion = "testinjectversion"
+	skipRangeOptions.skipOutboundPorts = []string{"4222-4223"}
+
+	skipPortsOptions := newInjectOptions()
+	skipPortsOptions.linkerdVersion = "testinjectversion"
+	skipPortsOptions.skipInboundPorts = []string{"25"}
+	skipPortsOptions.skipOutboundPorts = []string{"3306", "4222-4223"}
+
 	testCases := []struct {
 		inputFileName     string
 		goldenFileName    string
+		reportFileName    string
 		testInjectOptions *injectOptions
 	}{
-		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment.golden.yml", defaultOptions},
-		{"inject_emojivoto_list.input.yml", "inject_emojivoto_list.golden.yml", defaultOptions},
-		{"inject_emojivoto_deployment_hostNetwork_false.input.yml", "inject_emojivoto_deployment_hostNetwork_false.golden.yml", defaultOptions},
-		{"inject_emojivoto_deployment_hostNetwork_true.input.yml", "inject_emojivoto_deployment_hostNetwork_true.golden.yml", defaultOptions},
-		{"inject_emojivoto_deployment_controller_name.input.yml", "inject_emojivoto_deployment_controller_name.golden.yml", defaultOptions},
-		{"inject_emojivoto_statefulset.input.yml", "inject_emojivoto_statefulset.golden.yml", defaultOptions},
-		{"inject_emojivoto_pod.input.yml", "inject_emojivoto_pod.golden.yml", defaultOptions},
-		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment_tls.golden.yml", tlsOptions},
-		{"inject_emojivoto_pod.input.yml", "inject_emojivoto_pod_tls.golden.yml", tlsOptions},
+		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_list.input.yml", "inject_emojivoto_list.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_deployment_hostNetwork_false.input.yml", "inject_emojivoto_deployment_hostNetwork_false.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_deployment_hostNetwork_true.input.yml", "inject_emojivoto_deployment_hostNetwork_true.golden.yml", "inject_emojivoto_deployment_hostNetwork_true.report.golden", defaultOptions},
+		{"inject_emojivoto_deployment_controller_name.input.yml", "inject_emojivoto_deployment_controller_name.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_statefulset.input.yml", "inject_emojivoto_statefulset.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_replicationcontroller.input.yml", "inject_emojivoto_replicationcontroller.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_replicaset.input.yml", "inject_emojivoto_replicaset.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_daemonset.input.yml", "inject_emojivoto_daemonset.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_job.input.yml", "inject_emojivoto_job.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_cronjob.input.yml", "inject_emojivoto_cronjob.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_cronjob.input.yml", "inject_emojivoto_cronjob_tls.golden.yml", "", tlsOptions},
+		{"inject_emojivoto_mixed.input.yml", "inject_emojivoto_mixed.golden.yml", "inject_emojivoto_mixed.report.golden", defaultOptions},
+		{"inject_emojivoto_pod.input.yml", "inject_emojivoto_pod.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment_tls.golden.yml", "", tlsOptions},
+		{"inject_emojivoto_pod.input.yml", "inject_emojivoto_pod_tls.golden.yml", "", tlsOptions},
+		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment_registry.golden.yml", "", registryOptions},
+		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment_resources.golden.yml", "", resourceOptions},
+		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment_resources_limits.golden.yml", "", limitsOptions},
+		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment_resources_requests.golden.yml", "", requestsOptions},
+		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment_skip_inbound.golden.yml", "", skipInboundOptions},
+		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment_skip_range.golden.yml", "", skipRangeOptions},
+		{"inject_emojivoto_deployment.input.yml", "inject_emojivoto_deployment_skip_ports.golden.yml", "", skipPortsOptions},
+		{"inject_emojivoto_deployment_skip_annotations.input.yml", "inject_emojivoto_deployment_skip_annotations.golden.yml", "", defaultOptions},
+		{"inject_emojivoto_deployment_skip_annotations.input.yml", "inject_emojivoto_deployment_skip_ports.golden.yml", "", skipPortsOptions},
+		{"inject_emojivoto_deployment.golden.yml", "inject_emojivoto_deployment.golden.yml", "inject_emojivoto_deployment_injected.report.golden", defaultOptions},
+		{"inject_emojivoto_deployment_tls.golden.yml", "inject_emojivoto_deployment_tls.golden.yml", "inject_emojivoto_deployment_injected.report.golden", tlsOptions},
+		{"inject_emojivoto_deployment.golden.yml", "inject_emojivoto_deployment_tls.golden.yml", "", forceTLSOptions},
+		{"inject_emojivoto_deployment_tls.golden.yml", "inject_emojivoto_deployment.golden.yml", "", forceOptions},
+		{"inject_emojivoto_deployment_skip_ports.golden.yml", "inject_emojivoto_deployment_skip_ports.golden.yml", "", forceOptions},
+		{"inject_emojivoto_deployment_proxy_name.input.yml", "inject_emojivoto_deployment_proxy_name.golden.yml", "inject_emojivoto_deployment_proxy_name.report.golden", forceOptions},
+		{"inject_emojivoto_deployment.input.json", "inject_emojivoto_deployment.golden.json", "", defaultOptions},
+		{"inject_emojivoto_list.input.json", "inject_emojivoto_list.golden.json", "", defaultOptions},
+		{"inject_emojivoto_array.input.json", "inject_emojivoto_array.golden.json", "inject_emojivoto_array.report.golden", defaultOptions},
 	}
 
 	for i, tc := range testCases {
@@ -45,8 +122,9 @@ func TestInjectYAML(t *testing.T) {
 			read := bufio.NewReader(file)
 
 			output := new(bytes.Buffer)
+			report := new(bytes.Buffer)
 
-			err = InjectYAML(read, output, tc.testInjectOptions)
+			err = InjectYAML(read, output, newInjectReport(report, "inject"), tc.testInjectOptions)
 			if err != nil {
 				t.Errorf("Unexpected error injecting YAML: %v\n", err)
 			}
@@ -59,10 +137,254 @@ func TestInjectYAML(t *testing.T) {
 			}
 			expectedOutput := string(goldenFileBytes)
 			diffCompare(t, actualOutput, expectedOutput)
+			diffCompare(t, report.String(), readOptionalTestFile(t, tc.reportFileName))
 		})
 	}
 }
 
+func TestInjectKeepsFormat(t *testing.T) {
+	inject := func(t *testing.T, input string) string {
+		output := new(bytes.Buffer)
+		if err := InjectYAML(strings.NewReader(input), output, newInjectReport(ioutil.Discard, "inject"), newInjectOptions()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return output.String()
+	}
+	// decode returns the document of a JSON or YAML input, as decoded from
+	// JSON, so that the documents of both formats can be compared.
+	decode := func(t *testing.T, input []byte) interface{} {
+		j, err := yaml.YAMLToJSON(input)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var document interface{}
+		if err := json.Unmarshal(j, &document); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return document
+	}
+
+	t.Run("Outputs JSON inputs as JSON only, as YAML inputs are injected", func(t *testing.T) {
+		testCases := []struct {
+			jsonInput, yamlInput string
+		}{
+			{"inject_emojivoto_deployment.input.json", "inject_emojivoto_deployment.input.yml"},
+			{"inject_emojivoto_list.input.json", "inject_emojivoto_list.input.yml"},
+		}
+		for _, tc := range testCases {
+			output := inject(t, readOptionalTestFile(t, tc.jsonInput))
+			decoder := json.NewDecoder(strings.NewReader(output))
+			var document json.RawMessage
+			if err := decoder.Decode(&document); err != nil {
+				t.Fatalf("Expected JSON output for %s, got %v:\n%s", tc.jsonInput, err, output)
+			}
+			if decoder.More() {
+				t.Fatalf("Expected a single JSON document for %s, got:\n%s", tc.jsonInput, output)
+			}
+
+			yamlOutput := inject(t, readOptionalTestFile(t, tc.yamlInput))
+			yamlOutput = strings.TrimPrefix(strings.TrimSuffix(yamlOutput, "---\n"), "---\n")
+			if !reflect.DeepEqual(decode(t, document), decode(t, []byte(yamlOutput))) {
+				t.Fatalf("Expected %s to be injected as %s is, got:\n%s", tc.jsonInput, tc.yamlInput, output)
+			}
+		}
+	})
+
+	t.Run("Keeps the JSON documents of a YAML stream JSON", func(t *testing.T) {
+		pod := `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"vote-bot"},"spec":{"containers":[{"name":"vote-bot","image":"buoyantio/emojivoto-web:v3"}]}}`
+		service := "apiVersion: v1\nkind: Service\nmetadata:\n  name: web-svc\n"
+		output := inject(t, service+"---\n"+pod+"\n---\n"+service)
+
+		documents := strings.Split(output, "---\n")
+		if len(documents) != 4 || documents[0] != service || documents[2] != service || documents[3] != "" {
+			t.Fatalf("Expected the YAML documents to be kept, got:\n%s", output)
+		}
+		var injected v1.Pod
+		if err := json.Unmarshal([]byte(documents[1]), &injected); err != nil {
+			t.Fatalf("Expected the pod to stay JSON, got %v:\n%s", err, documents[1])
+		}
+		if len(injected.Spec.Containers) != 2 {
+			t.Fatalf("Expected the pod to be injected, got %+v", injected.Spec.Containers)
+		}
+	})
+
+	t.Run("Keeps YAML streams YAML", func(t *testing.T) {
+		output := inject(t, readOptionalTestFile(t, "inject_emojivoto_mixed.input.yml"))
+		reader := yamlDecoder.NewYAMLReader(bufio.NewReader(strings.NewReader(output)))
+		for documents := 0; ; documents++ {
+			document, err := reader.Read()
+			if err == io.EOF {
+				if documents != 6 {
+					t.Fatalf("Expected 6 documents, got %d", documents)
+				}
+				break
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if formatOf(document) != yamlFormat {
+				t.Fatalf("Expected YAML documents only, got:\n%s", document)
+			}
+		}
+	})
+}
+
+func TestSetInstallDefaults(t *testing.T) {
+	installOptions := &k8s.InstallOptions{Flags: []k8s.InstallFlag{
+		{Name: "proxy-cpu-request", Value: "100m"},
+		{Name: "proxy-memory-limit", Value: "256Mi"},
+		{Name: "proxy-log-level", Value: "debug"},
+	}}
+
+	t.Run("Uses the resources the control plane was installed with", func(t *testing.T) {
+		cmd := newCmdInject()
+		if err := setInstallDefaults(cmd.PersistentFlags(), installOptions); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := map[string]string{
+			"proxy-cpu-request":    "100m",
+			"proxy-memory-request": "",
+			"proxy-memory-limit":   "256Mi",
+			"proxy-log-level":      newProxyConfigOptions().proxyLogLevel,
+		}
+		for name, value := range expected {
+			if actual := cmd.PersistentFlags().Lookup(name).Value.String(); actual != value {
+				t.Fatalf("Expected --%s to be [%s], got [%s]", name, value, actual)
+			}
+		}
+	})
+
+	t.Run("Prefers the flags set on the command line", func(t *testing.T) {
+		cmd := newCmdInject()
+		if err := cmd.PersistentFlags().Parse([]string{"--proxy-cpu-request=250m"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := setInstallDefaults(cmd.PersistentFlags(), installOptions); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if actual := cmd.PersistentFlags().Lookup("proxy-cpu-request").Value.String(); actual != "250m" {
+			t.Fatalf("Expected --proxy-cpu-request to be [250m], got [%s]", actual)
+		}
+		if actual := cmd.PersistentFlags().Lookup("proxy-memory-limit").Value.String(); actual != "256Mi" {
+			t.Fatalf("Expected --proxy-memory-limit to be [256Mi], got [%s]", actual)
+		}
+	})
+
+	t.Run("Does not mix a limit set on the command line with the installed request", func(t *testing.T) {
+		cmd := newCmdInject()
+		if err := cmd.PersistentFlags().Parse([]string{"--proxy-cpu-limit=50m"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := setInstallDefaults(cmd.PersistentFlags(), installOptions); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if actual := cmd.PersistentFlags().Lookup("proxy-cpu-request").Value.String(); actual != "" {
+			t.Fatalf("Expected --proxy-cpu-request not to be set, got [%s]", actual)
+		}
+		if actual := cmd.PersistentFlags().Lookup("proxy-memory-limit").Value.String(); actual != "256Mi" {
+			t.Fatalf("Expected --proxy-memory-limit to be [256Mi], got [%s]", actual)
+		}
+	})
+}
+
+func TestValidateProxyResources(t *testing.T) {
+	testCases := []struct {
+		request     string
+		limit       string
+		expectedErr string
+	}{
+		{request: "100m", limit: "1"},
+		{request: "100m"},
+		{limit: "500m"},
+		{request: "a lot", expectedErr: "Invalid quantity 'a lot' for --proxy-cpu-request flag"},
+		{limit: "1.5.0", expectedErr: "Invalid quantity '1.5.0' for --proxy-cpu-limit flag"},
+		{request: "two", limit: "3", expectedErr: "Invalid quantity 'two' for --proxy-cpu-request flag"},
+		{request: "2", limit: "1500m", expectedErr: "--proxy-cpu-request [2] must not be greater than --proxy-cpu-limit [1500m]"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("request=%s limit=%s", tc.request, tc.limit), func(t *testing.T) {
+			options := newProxyConfigOptions()
+			options.proxyCPURequest = tc.request
+			options.proxyCPULimit = tc.limit
+
+			err := options.validate()
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Fatalf("Expected error [%s], got [%v]", tc.expectedErr, err)
+			}
+		})
+	}
+
+	t.Run("Validates memory quantities", func(t *testing.T) {
+		options := newProxyConfigOptions()
+		options.proxyMemoryRequest = "64MB!"
+		if err := options.validate(); err == nil || !strings.Contains(err.Error(), "--proxy-memory-request") {
+			t.Fatalf("Expected an error about --proxy-memory-request, got [%v]", err)
+		}
+	})
+}
+
+func TestValidateSkipPorts(t *testing.T) {
+	testCases := []struct {
+		inbound     []string
+		outbound    []string
+		expectedErr string
+	}{
+		{inbound: []string{"25", "4222-4223"}, outbound: []string{"3306"}},
+		{inbound: []string{"smtp"}, expectedErr: "invalid --skip-inbound-ports: [smtp] is not a port or a range of ports, e.g. 25 or 4222-4223"},
+		{outbound: []string{"4223-4222"}, expectedErr: "invalid --skip-outbound-ports: port range [4223-4222] ends before it starts"},
+		{outbound: []string{"0"}, expectedErr: "invalid --skip-outbound-ports: [0] is not a port or a range of ports, e.g. 25 or 4222-4223"},
+		{inbound: []string{"4222-4230", "4225"}, expectedErr: "invalid --skip-inbound-ports: port ranges [4222-4230] and [4225] overlap"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			options := newInjectOptions()
+			options.skipInboundPorts = tc.inbound
+			options.skipOutboundPorts = tc.outbound
+
+			err := options.validate()
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Fatalf("Expected error [%s], got [%v]", tc.expectedErr, err)
+			}
+		})
+	}
+
+	t.Run("Rejects invalid annotations", func(t *testing.T) {
+		input := `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  annotations:
+    linkerd.io/skip-outbound-ports: 25,http
+spec:
+  containers:
+  - name: web
+    image: buoyantio/emojivoto-web:v3
+`
+		err := InjectYAML(strings.NewReader(input), &bytes.Buffer{}, newInjectReport(&bytes.Buffer{}, "inject"), newInjectOptions())
+		expected := "Pod \"web\": invalid linkerd.io/skip-outbound-ports annotation: [http] is not a port or a range of ports, e.g. 25 or 4222-4223"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
 func TestRunInjectCmd(t *testing.T) {
 	testInjectOptions := newInjectOptions()
 	testInjectOptions.linkerdVersion = "testinjectversion"
@@ -79,6 +401,7 @@ func TestRunInjectCmd(t *testing.T) {
 		},
 		{
 			inputFileName:        "inject_gettest_deployment.good.input.yml",
+			stdErrGoldenFileName: "inject_gettest_deployment.good.report.golden",
 			stdOutGoldenFileName: "inject_gettest_deployment.good.golden.yml",
 			exitCode:             0,
 		},
@@ -89,12 +412,12 @@ func TestRunInjectCmd(t *testing.T) {
 			errBuffer := &bytes.Buffer{}
 			outBuffer := &bytes.Buffer{}
 
-			in, err := os.Open(fmt.Sprintf("testdata/%s", tc.inputFileName))
+			in, err := read([]string{fmt.Sprintf("testdata/%s", tc.inputFileName)}, false)
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
 
-			exitCode := runInjectCmd([]io.Reader{in}, errBuffer, outBuffer, testInjectOptions)
+			exitCode := runInjectCmd(in, errBuffer, outBuffer, testInjectOptions)
 			if exitCode != tc.exitCode {
 				t.Fatalf("Expected exit code to be %d but got: %d", tc.exitCode, exitCode)
 			}
@@ -130,13 +453,13 @@ func TestInjectFilePath(t *testing.T) {
 
 		for i, testCase := range testCases {
 			t.Run(fmt.Sprintf("%d %s", i, testCase.resource), func(t *testing.T) {
-				in, err := read(testCase.resourceFile)
+				in, err := read([]string{testCase.resourceFile}, false)
 				if err != nil {
 					t.Fatal("Unexpected error: ", err)
 				}
 
 				actual := &bytes.Buffer{}
-				if exitCode := runInjectCmd(in, actual, actual, options); exitCode != 0 {
+				if exitCode := runInjectCmd(in, &bytes.Buffer{}, actual, options); exitCode != 0 {
 					t.Fatal("Unexpected error. Exit code from runInjectCmd: ", exitCode)
 				}
 
@@ -149,13 +472,13 @@ func TestInjectFilePath(t *testing.T) {
 	})
 
 	t.Run("read from folder", func(t *testing.T) {
-		in, err := read(resourceFolder)
+		in, err := read([]string{resourceFolder}, true)
 		if err != nil {
 			t.Fatal("Unexpected error: ", err)
 		}
 
 		actual := &bytes.Buffer{}
-		if exitCode := runInjectCmd(in, actual, actual, options); exitCode != 0 {
+		if exitCode := runInjectCmd(in, &bytes.Buffer{}, actual, options); exitCode != 0 {
 			t.Fatal("Unexpected error. Exit code from runInjectCmd: ", exitCode)
 		}
 
@@ -181,8 +504,8 @@ func TestWalk(t *testing.T) {
 
 	var (
 		data  = []byte(readOptionalTestFile(t, "inject_gettest_deployment.bad.input.yml"))
-		file1 = filepath.Join(tmpFolderRoot, "root.txt")
-		file2 = filepath.Join(tmpFolderData, "data.txt")
+		file1 = filepath.Join(tmpFolderRoot, "root.yml")
+		file2 = filepath.Join(tmpFolderData, "data.yml")
 	)
 	if err := ioutil.WriteFile(file1, data, 0666); err != nil {
 		t.Fatal("Unexpected error: ", err)
@@ -191,17 +514,140 @@ func TestWalk(t *testing.T) {
 		t.Fatal("Unexpected error: ", err)
 	}
 
-	actual, err := walk(tmpFolderRoot)
+	actual, err := walk(tmpFolderRoot, true)
 	if err != nil {
 		t.Fatal("Unexpected error: ", err)
 	}
+	if len(actual) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(actual))
+	}
 
-	for _, r := range actual {
+	for _, f := range actual {
 		b := make([]byte, len(data))
-		r.Read(b)
+		f.in.Read(b)
 
 		if string(b) != string(data) {
 			t.Errorf("Content mismatch. Expected %q, but got %q", data, b)
 		}
 	}
 }
+
+func TestInjectFolder(t *testing.T) {
+	folder := filepath.Join("testdata", "inject-folder")
+	options := newInjectOptions()
+	options.linkerdVersion = "testinjectversion"
+
+	testCases := []struct {
+		recursive            bool
+		stdOutGoldenFileName string
+		stdErrGoldenFileName string
+	}{
+		{false, "inject_folder.golden.yml", "inject_folder.report.golden"},
+		{true, "inject_folder_recursive.golden.yml", "inject_folder_recursive.report.golden"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("recursive=%t", tc.recursive), func(t *testing.T) {
+			in, err := read([]string{folder}, tc.recursive)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			errBuffer := &bytes.Buffer{}
+			outBuffer := &bytes.Buffer{}
+			if exitCode := runInjectCmd(in, errBuffer, outBuffer, options); exitCode != 0 {
+				t.Fatalf("Unexpected exit code %d: %s", exitCode, errBuffer.String())
+			}
+
+			diffCompare(t, outBuffer.String(), readOptionalTestFile(t, tc.stdOutGoldenFileName))
+			diffCompare(t, errBuffer.String(), readOptionalTestFile(t, tc.stdErrGoldenFileName))
+		})
+	}
+
+	t.Run("Reads the files given in order", func(t *testing.T) {
+		in, err := read([]string{filepath.Join(folder, "db", "redis.json"), folder}, false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var names []string
+		for _, file := range in {
+			names = append(names, file.name)
+		}
+		expected := []string{
+			filepath.Join(folder, "db", "redis.json"),
+			filepath.Join(folder, "web.yaml"),
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Expected files %v, got %v", expected, names)
+		}
+	})
+
+	t.Run("Writes the files to the same paths in --output-dir", func(t *testing.T) {
+		outputDir, err := ioutil.TempDir("", "linkerd-inject")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer os.RemoveAll(outputDir)
+
+		in, err := read([]string{folder}, true)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := checkOutputPaths(in); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		dirOptions := *options
+		dirOptions.outputDir = outputDir
+		errBuffer := &bytes.Buffer{}
+		outBuffer := &bytes.Buffer{}
+		if exitCode := runInjectCmd(in, errBuffer, outBuffer, &dirOptions); exitCode != 0 {
+			t.Fatalf("Unexpected exit code %d: %s", exitCode, errBuffer.String())
+		}
+		if outBuffer.Len() > 0 {
+			t.Fatalf("Expected nothing to be written to stdout, got:\n%s", outBuffer.String())
+		}
+		diffCompare(t, errBuffer.String(), readOptionalTestFile(t, "inject_folder_recursive.report.golden"))
+
+		for _, relPath := range []string{"web.yaml", "db/redis.json", "db/cache/memcached.yml"} {
+			file, err := read([]string{filepath.Join(folder, relPath)}, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expected := &bytes.Buffer{}
+			if exitCode := runInjectCmd(file, &bytes.Buffer{}, expected, options); exitCode != 0 {
+				t.Fatalf("Unexpected exit code %d injecting %s", exitCode, relPath)
+			}
+
+			actual, err := ioutil.ReadFile(filepath.Join(outputDir, relPath))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			diffCompare(t, string(actual), expected.String())
+		}
+
+		values, err := ioutil.ReadFile(filepath.Join(outputDir, "config", "values.yaml"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		diffCompare(t, string(values), readOptionalTestFile(t, filepath.Join("inject-folder", "config", "values.yaml")))
+
+		if _, err := os.Stat(filepath.Join(outputDir, "README.md")); !os.IsNotExist(err) {
+			t.Fatalf("Expected README.md not to be written, got %v", err)
+		}
+	})
+
+	t.Run("Refuses to write files to the same path in --output-dir", func(t *testing.T) {
+		in, err := read([]string{filepath.Join(folder, "web.yaml"), filepath.Join(folder, "db"), filepath.Join(folder, "web.yaml")}, false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		err = checkOutputPaths(in)
+		expected := fmt.Sprintf("%s and %s would both be written to [web.yaml] in --output-dir", filepath.Join(folder, "web.yaml"), filepath.Join(folder, "web.yaml"))
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
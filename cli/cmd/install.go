@@ -2,16 +2,27 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"text/template"
 
+	"github.com/ghodss/yaml"
 	"github.com/linkerd/linkerd2/cli/install"
+	"github.com/linkerd/linkerd2/controller/ca"
 	"github.com/linkerd/linkerd2/pkg/k8s"
 	uuid "github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type installConfig struct {
@@ -28,29 +39,99 @@ type installConfig struct {
 	CliVersion                  string
 	ControllerLogLevel          string
 	ControllerComponentLabel    string
+	ControllerNamespaceLabel    string
 	CreatedByAnnotation         string
 	ProxyAPIPort                uint
 	EnableTLS                   bool
 	TLSTrustAnchorConfigMapName string
+	EnableHA                    bool
+	SingleNamespace             bool
+	EnablePSP                   bool
+	NodeSelector                map[string]string
+	Tolerations                 []v1.Toleration
+	InstallConfigMapName        string
+	InstallOptions              string
+	ProxyAutoInject             bool
+	ProxyAutoInjectDefault      string
+	ProxyInjectorFailurePolicy  string
+	ProxyInjectorServiceName    string
+	ProxyInjectorTLSSecretName  string
+	ProxyInjectorWebhookConfig  string
+	ProxyConfigKey              string
+	ProxyConfig                 string
+	ProxyInjectorTLS            *proxyInjectorTLS
+}
+
+// proxyInjectorTLS is the certificate served by the proxy injector webhook,
+// its private key and the CA bundle the API server verifies it with, all
+// base64-encoded PEM, as they are written to its Secret and webhook
+// configuration.
+type proxyInjectorTLS struct {
+	Cert     string
+	Key      string
+	CABundle string
 }
 
 type installOptions struct {
-	controllerReplicas uint
-	webReplicas        uint
-	prometheusReplicas uint
-	controllerLogLevel string
+	controllerReplicas         uint
+	webReplicas                uint
+	prometheusReplicas         uint
+	controllerLogLevel         string
+	highAvailability           bool
+	singleNamespace            bool
+	enablePSP                  bool
+	nodeSelector               []string
+	tolerations                []string
+	proxyAutoInject            bool
+	proxyAutoInjectDefault     string
+	proxyInjectorFailurePolicy string
+	outputDir                  string
+	force                      bool
 	*proxyConfigOptions
+
+	// recordedFlags are the flags set on the command line, which are recorded
+	// in the install ConfigMap.
+	recordedFlags []k8s.InstallFlag
 }
 
-const prometheusProxyOutboundCapacity = 10000
+const (
+	prometheusProxyOutboundCapacity = 10000
+
+	// prometheusImage and prometheusVersion are the Prometheus image pulled
+	// from Docker Hub, unless another --registry is given.
+	prometheusImage   = "prom/prometheus"
+	prometheusVersion = "v2.3.1"
+
+	defaultReplicas = 1
+
+	// defaultHAReplicas is the number of replicas of the controller and web
+	// components with --ha, unless set explicitly.
+	defaultHAReplicas = 3
+
+	// The failure policies of the proxy injector webhook: pods are created
+	// without the proxy when it cannot be reached, or not at all.
+	ignoreFailurePolicy = "Ignore"
+	failFailurePolicy   = "Fail"
+)
 
 func newInstallOptions() *installOptions {
 	return &installOptions{
-		controllerReplicas: 1,
-		webReplicas:        1,
-		prometheusReplicas: 1,
-		controllerLogLevel: "info",
-		proxyConfigOptions: newProxyConfigOptions(),
+		controllerReplicas:         defaultReplicas,
+		webReplicas:                defaultReplicas,
+		prometheusReplicas:         defaultReplicas,
+		controllerLogLevel:         "info",
+		highAvailability:           false,
+		singleNamespace:            false,
+		enablePSP:                  false,
+		nodeSelector:               []string{},
+		tolerations:                []string{},
+		proxyAutoInject:            false,
+		proxyAutoInjectDefault:     k8s.ProxyInjectEnabled,
+		proxyInjectorFailurePolicy: ignoreFailurePolicy,
+		outputDir:                  "",
+		force:                      false,
+		proxyConfigOptions:         newProxyConfigOptions(),
+		recordedFlags:              []k8s.InstallFlag{},
 	}
 }
 
@@ -62,49 +143,270 @@ func newCmdInstall() *cobra.Command {
 		Short: "Output Kubernetes configs to install Linkerd",
 		Long:  "Output Kubernetes configs to install Linkerd.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			options.recordedFlags = recordFlags(cmd.PersistentFlags())
 			config, err := validateAndBuildConfig(options)
 			if err != nil {
 				return err
 			}
 
+			if options.outputDir != "" {
+				return renderToDir(*config, options)
+			}
 			return render(*config, os.Stdout, options)
 		},
 	}
 
+	addInstallFlags(cmd, options)
+	cmd.PersistentFlags().StringVar(&options.outputDir, "output-dir", options.outputDir, "Write each resource to <kind>-<name>.yaml in this directory, instead of writing all of them to stdout")
+	cmd.PersistentFlags().BoolVar(&options.force, "force", options.force, "Overwrite the files of a non-empty --output-dir")
+
+	return cmd
+}
+
+// addInstallFlags adds the flags describing the control plane, shared by
+// install and upgrade, to cmd.
+func addInstallFlags(cmd *cobra.Command, options *installOptions) {
 	addProxyConfigFlags(cmd, options.proxyConfigOptions)
 	cmd.PersistentFlags().UintVar(&options.controllerReplicas, "controller-replicas", options.controllerReplicas, "Replicas of the controller to deploy")
 	cmd.PersistentFlags().UintVar(&options.webReplicas, "web-replicas", options.webReplicas, "Replicas of the web server to deploy")
 	cmd.PersistentFlags().UintVar(&options.prometheusReplicas, "prometheus-replicas", options.prometheusReplicas, "Replicas of prometheus to deploy")
 	cmd.PersistentFlags().StringVar(&options.controllerLogLevel, "controller-log-level", options.controllerLogLevel, "Log level for the controller and web components")
-
-	return cmd
+	cmd.PersistentFlags().BoolVar(&options.highAvailability, "ha", options.highAvailability, fmt.Sprintf("Run %d replicas of the controller and web components on separate nodes, with production resource requests and disruption budgets", defaultHAReplicas))
+	cmd.PersistentFlags().BoolVar(&options.singleNamespace, "single-namespace", options.singleNamespace, "Only grant the control plane access to its own namespace, with Roles instead of ClusterRoles; the namespace must already exist, and only workloads in it can be added to the mesh")
+	cmd.PersistentFlags().BoolVar(&options.enablePSP, "enable-psp", options.enablePSP, "Create a PodSecurityPolicy allowing the control plane pods, and RBAC granting them its use, for clusters that enforce PodSecurityPolicies")
+	cmd.PersistentFlags().StringSliceVar(&options.nodeSelector, "control-plane-node-selector", options.nodeSelector, "Only schedule the control plane on nodes with this label, as key=value (can be repeated)")
+	cmd.PersistentFlags().StringSliceVar(&options.tolerations, "control-plane-toleration", options.tolerations, "Allow the control plane to be scheduled on nodes with this taint, as key[=value][:effect] (can be repeated)")
+	cmd.PersistentFlags().BoolVar(&options.proxyAutoInject, "proxy-auto-inject", options.proxyAutoInject, "Run the proxy injector, a webhook adding the proxy to the pods created in namespaces other than kube-system and the control plane's, as decided by the "+k8s.ProxyInjectAnnotation+" annotation of the pods, or else of their namespace, or else by --proxy-auto-inject-default")
+	cmd.PersistentFlags().StringVar(&options.proxyAutoInjectDefault, "proxy-auto-inject-default", options.proxyAutoInjectDefault, fmt.Sprintf("Whether the proxy injector injects the pods that neither they nor their namespace are annotated with %s: %q injects them, so that pods opt out, and %q does not, so that pods opt in", k8s.ProxyInjectAnnotation, k8s.ProxyInjectEnabled, k8s.ProxyInjectDisabled))
+	cmd.PersistentFlags().StringVar(&options.proxyInjectorFailurePolicy, "proxy-injector-failure-policy", options.proxyInjectorFailurePolicy, fmt.Sprintf("What the API server does with the pods it creates when the proxy injector cannot be reached: %q creates them without the proxy, %q rejects them", ignoreFailurePolicy, failFailurePolicy))
 }
 
 func validateAndBuildConfig(options *installOptions) (*installConfig, error) {
 	if err := validate(options); err != nil {
 		return nil, err
 	}
+
+	nodeSelector, err := parseNodeSelector(options.nodeSelector)
+	if err != nil {
+		return nil, err
+	}
+	tolerations, err := parseTolerations(options.tolerations)
+	if err != nil {
+		return nil, err
+	}
+
+	controllerReplicas, webReplicas := options.controllerReplicas, options.webReplicas
+	if options.highAvailability {
+		if !options.recorded("controller-replicas") {
+			controllerReplicas = defaultHAReplicas
+		}
+		if !options.recorded("web-replicas") {
+			webReplicas = defaultHAReplicas
+		}
+	}
+
+	installOptions, err := json.Marshal(k8s.InstallOptions{Flags: options.recordedFlags})
+	if err != nil {
+		return nil, err
+	}
+
+	prometheus := fmt.Sprintf("%s:%s", prometheusImage, prometheusVersion)
+	if options.dockerRegistry != defaultDockerRegistry {
+		prometheus = options.registryImage("prometheus", prometheusVersion)
+	}
+
+	var proxyConfig []byte
+	var injectorTLS *proxyInjectorTLS
+	if options.proxyAutoInject {
+		// The proxy injector injects the workloads of the cluster with the
+		// proxy configured by the flags given, as `linkerd inject` would.
+		proxyConfig, err = json.Marshal(options.proxyConfig(defaultInboundPort, defaultOutboundPort))
+		if err != nil {
+			return nil, err
+		}
+		injectorTLS, err = newProxyInjectorTLS(controlPlaneNamespace)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &installConfig{
 		Namespace:                   controlPlaneNamespace,
-		ControllerImage:             fmt.Sprintf("%s/controller:%s", options.dockerRegistry, options.linkerdVersion),
-		WebImage:                    fmt.Sprintf("%s/web:%s", options.dockerRegistry, options.linkerdVersion),
-		PrometheusImage:             "prom/prometheus:v2.3.1",
-		GrafanaImage:                fmt.Sprintf("%s/grafana:%s", options.dockerRegistry, options.linkerdVersion),
-		ControllerReplicas:          options.controllerReplicas,
-		WebReplicas:                 options.webReplicas,
+		ControllerImage:             options.registryImage("controller", options.linkerdVersion),
+		WebImage:                    options.registryImage("web", options.linkerdVersion),
+		PrometheusImage:             prometheus,
+		GrafanaImage:                options.registryImage("grafana", options.linkerdVersion),
+		ControllerReplicas:          controllerReplicas,
+		WebReplicas:                 webReplicas,
 		PrometheusReplicas:          options.prometheusReplicas,
 		ImagePullPolicy:             options.imagePullPolicy,
 		UUID:                        uuid.NewV4().String(),
 		CliVersion:                  k8s.CreatedByAnnotationValue(),
 		ControllerLogLevel:          options.controllerLogLevel,
 		ControllerComponentLabel:    k8s.ControllerComponentLabel,
+		ControllerNamespaceLabel:    k8s.ControllerNSLabel,
 		CreatedByAnnotation:         k8s.CreatedByAnnotation,
 		ProxyAPIPort:                options.proxyAPIPort,
 		EnableTLS:                   options.enableTLS(),
 		TLSTrustAnchorConfigMapName: k8s.TLSTrustAnchorConfigMapName,
+		EnableHA:                    options.highAvailability,
+		SingleNamespace:             options.singleNamespace,
+		EnablePSP:                   options.enablePSP,
+		NodeSelector:                nodeSelector,
+		Tolerations:                 tolerations,
+		InstallConfigMapName:        k8s.InstallConfigMapName,
+		InstallOptions:              string(installOptions),
+		ProxyAutoInject:             options.proxyAutoInject,
+		ProxyAutoInjectDefault:      options.proxyAutoInjectDefault,
+		ProxyInjectorFailurePolicy:  options.proxyInjectorFailurePolicy,
+		ProxyInjectorServiceName:    k8s.ProxyInjectorServiceName,
+		ProxyInjectorTLSSecretName:  k8s.ProxyInjectorTLSSecretName,
+		ProxyInjectorWebhookConfig:  k8s.ProxyInjectorWebhookConfigName(controlPlaneNamespace),
+		ProxyConfigKey:              k8s.ProxyConfigKey,
+		ProxyConfig:                 string(proxyConfig),
+		ProxyInjectorTLS:            injectorTLS,
+	}, nil
+}
+
+// newProxyInjectorTLS issues the certificate of the proxy injector webhook, for
+// the DNS name of its Service in controlPlaneNamespace, from a CA of its own:
+// the API server is the only client of the webhook, and it trusts that CA
+// only for the webhook.
+func newProxyInjectorTLS(controlPlaneNamespace string) (*proxyInjectorTLS, error) {
+	injectorCA, err := ca.NewCA()
+	if err != nil {
+		return nil, err
+	}
+	dnsName := fmt.Sprintf("%s.%s.svc", k8s.ProxyInjectorServiceName, controlPlaneNamespace)
+	cert, err := injectorCA.IssueEndEntityCertificate(dnsName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxyInjectorTLS{
+		Cert:     base64PEM("CERTIFICATE", cert.Certificate),
+		Key:      base64PEM("PRIVATE KEY", cert.PrivateKey),
+		CABundle: base64.StdEncoding.EncodeToString([]byte(injectorCA.TrustAnchorPEM())),
+	}, nil
+}
+
+// proxyInjectorTLSFromSecret returns the certificate, private key and CA
+// bundle stored in the Secret of an installed proxy injector, so that
+// upgrading it keeps them.
+func proxyInjectorTLSFromSecret(secret *v1.Secret) (*proxyInjectorTLS, error) {
+	data := map[string]string{}
+	for _, key := range []string{v1.TLSCertKey, v1.TLSPrivateKeyKey, k8s.ProxyInjectorCABundleKey} {
+		value, ok := secret.Data[key]
+		if !ok {
+			return nil, fmt.Errorf("secret [%s/%s] has no [%s] key", secret.Namespace, secret.Name, key)
+		}
+		data[key] = base64.StdEncoding.EncodeToString(value)
+	}
+	return &proxyInjectorTLS{
+		Cert:     data[v1.TLSCertKey],
+		Key:      data[v1.TLSPrivateKeyKey],
+		CABundle: data[k8s.ProxyInjectorCABundleKey],
 	}, nil
 }
 
+func base64PEM(blockType string, der []byte) string {
+	return base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}))
+}
+
+// recorded returns whether the flag name was set on the command line, or, on
+// upgrade, when the control plane was installed.
+func (options *installOptions) recorded(name string) bool {
+	for _, flag := range options.recordedFlags {
+		if flag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unrecordedFlags are the flags of `linkerd install` and `linkerd upgrade`
+// that only choose where the configs are written to, or whether they are
+// written at all, rather than the control plane they describe.
+var unrecordedFlags = map[string]bool{
+	"output-dir": true,
+	"force":      true,
+}
+
+// recordFlags returns the flags of flags that were set on the command line, in
+// lexicographical order, so that the rendered configs are deterministic.
+func recordFlags(flags *pflag.FlagSet) []k8s.InstallFlag {
+	recorded := []k8s.InstallFlag{}
+	flags.Visit(func(flag *pflag.Flag) {
+		if unrecordedFlags[flag.Name] {
+			return
+		}
+		value := flag.Value.String()
+		if flag.Value.Type() == "stringSlice" {
+			// Slices are printed in brackets, but set as comma-separated
+			// values.
+			value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+		}
+		recorded = append(recorded, k8s.InstallFlag{Name: flag.Name, Value: value})
+	})
+	return recorded
+}
+
+// parseNodeSelector parses the key=value labels of
+// --control-plane-node-selector, validating them as label selectors.
+func parseNodeSelector(selectors []string) (map[string]string, error) {
+	nodeSelector := map[string]string{}
+	for _, selector := range selectors {
+		parts := strings.SplitN(selector, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("--control-plane-node-selector must be of the form key=value, was [%s]", selector)
+		}
+		errs := append(validation.IsQualifiedName(parts[0]), validation.IsValidLabelValue(parts[1])...)
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("invalid --control-plane-node-selector [%s]: %s", selector, strings.Join(errs, "; "))
+		}
+		nodeSelector[parts[0]] = parts[1]
+	}
+	return nodeSelector, nil
+}
+
+// parseTolerations parses the key[=value][:effect] taints of
+// --control-plane-toleration, like those of `kubectl taint`. A toleration
+// without a value tolerates any value of the key, and one without an effect
+// tolerates all of them.
+func parseTolerations(taints []string) ([]v1.Toleration, error) {
+	tolerations := []v1.Toleration{}
+	for _, taint := range taints {
+		toleration := v1.Toleration{Operator: v1.TolerationOpExists}
+
+		keyValue := taint
+		if i := strings.LastIndex(taint, ":"); i >= 0 {
+			keyValue = taint[:i]
+			toleration.Effect = v1.TaintEffect(taint[i+1:])
+			switch toleration.Effect {
+			case v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+			default:
+				return nil, fmt.Errorf("invalid --control-plane-toleration [%s]: effect must be one of: %s, %s, %s",
+					taint, v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute)
+			}
+		}
+
+		parts := strings.SplitN(keyValue, "=", 2)
+		toleration.Key = parts[0]
+		errs := validation.IsQualifiedName(toleration.Key)
+		if len(parts) == 2 {
+			toleration.Operator = v1.TolerationOpEqual
+			toleration.Value = parts[1]
+			errs = append(errs, validation.IsValidLabelValue(toleration.Value)...)
+		}
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("invalid --control-plane-toleration [%s]: %s", taint, strings.Join(errs, "; "))
+		}
+
+		tolerations = append(tolerations, toleration)
+	}
+	return tolerations, nil
+}
+
 func render(config installConfig, w io.Writer, options *installOptions) error {
 	template, err := template.New("linkerd").Parse(install.Template)
 	if err != nil {
@@ -125,18 +427,143 @@ func render(config installConfig, w io.Writer, options *installOptions) error {
 			return err
 		}
 	}
+	if config.ProxyAutoInject {
+		proxyInjectorTemplate, err := template.New("linkerd").Parse(install.ProxyInjectorTemplate)
+		if err != nil {
+			return err
+		}
+		err = proxyInjectorTemplate.Execute(buf, config)
+		if err != nil {
+			return err
+		}
+	}
 	injectOptions := newInjectOptions()
 	injectOptions.proxyConfigOptions = options.proxyConfigOptions
 
 	// Special case for linkerd-proxy running in the Prometheus pod.
 	injectOptions.proxyOutboundCapacity[config.PrometheusImage] = prometheusProxyOutboundCapacity
 
-	return InjectYAML(buf, w, injectOptions)
+	return InjectYAML(buf, w, newInjectReport(ioutil.Discard, "inject"), injectOptions)
+}
+
+// manifestFile is a resource of the install output, and the name of the file
+// it is written to with --output-dir.
+type manifestFile struct {
+	name    string
+	content []byte
+}
+
+// renderToDir renders the install output to a file per resource in
+// options.outputDir, creating it if needed. Files of a non-empty directory are
+// only overwritten with options.force, and other files are left untouched.
+func renderToDir(config installConfig, options *installOptions) error {
+	if err := os.MkdirAll(options.outputDir, 0755); err != nil {
+		return err
+	}
+	existing, err := ioutil.ReadDir(options.outputDir)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && !options.force {
+		return fmt.Errorf("--output-dir [%s] is not empty; pass --force to overwrite its files", options.outputDir)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := render(config, buf, options); err != nil {
+		return err
+	}
+	files, err := splitManifests(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := ioutil.WriteFile(filepath.Join(options.outputDir, file.name), file.content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitManifests splits a stream of YAML documents into a file per resource,
+// named after its kind and name. The comments preceding a document separator
+// are kept with the document that follows it, so that each file starts with
+// the comment describing its resource, and the files concatenated in order
+// are the stream. Blank lines are kept with the previous document, as are
+// documents without a kind, such as the empty one after the last separator.
+func splitManifests(stream []byte) ([]manifestFile, error) {
+	pieces := [][]byte{}
+	start, pieceStart := 0, 0
+	for offset := 0; offset < len(stream); {
+		end := bytes.IndexByte(stream[offset:], '\n') + 1
+		if end == 0 {
+			end = len(stream) - offset
+		}
+		line := stream[offset : offset+end]
+
+		trimmed := bytes.TrimSpace(line)
+		switch {
+		case string(trimmed) == "---":
+			if start > pieceStart {
+				pieces = append(pieces, stream[pieceStart:start])
+				pieceStart = start
+			}
+			start = offset + end
+		case len(trimmed) == 0 || trimmed[0] != '#':
+			start = offset + end
+		}
+		offset += end
+	}
+	pieces = append(pieces, stream[pieceStart:])
+
+	files := []manifestFile{}
+	names := map[string]bool{}
+	var prefix []byte
+	for _, piece := range pieces {
+		var resource struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal(piece, &resource); err != nil {
+			return nil, err
+		}
+
+		if resource.Kind == "" {
+			if len(files) == 0 {
+				prefix = append(append([]byte{}, prefix...), piece...)
+			} else {
+				last := &files[len(files)-1]
+				last.content = append(last.content, piece...)
+			}
+			continue
+		}
+
+		name := fmt.Sprintf("%s-%s.yaml", strings.ToLower(resource.Kind), resource.Metadata.Name)
+		if names[name] {
+			return nil, fmt.Errorf("several resources would be written to [%s]", name)
+		}
+		names[name] = true
+		content := append(append([]byte{}, prefix...), piece...)
+		files = append(files, manifestFile{name: name, content: content})
+		prefix = nil
+	}
+	return files, nil
 }
 
 func validate(options *installOptions) error {
 	if _, err := log.ParseLevel(options.controllerLogLevel); err != nil {
 		return fmt.Errorf("--controller-log-level must be one of: panic, fatal, error, warn, info, debug")
 	}
+	if options.proxyAutoInjectDefault != k8s.ProxyInjectEnabled && options.proxyAutoInjectDefault != k8s.ProxyInjectDisabled {
+		return fmt.Errorf("--proxy-auto-inject-default must be one of: %s, %s", k8s.ProxyInjectEnabled, k8s.ProxyInjectDisabled)
+	}
+	if options.proxyInjectorFailurePolicy != ignoreFailurePolicy && options.proxyInjectorFailurePolicy != failFailurePolicy {
+		return fmt.Errorf("--proxy-injector-failure-policy must be one of: %s, %s", ignoreFailurePolicy, failFailurePolicy)
+	}
+	if options.proxyAutoInject && options.singleNamespace {
+		return fmt.Errorf("--proxy-auto-inject cannot be used with --single-namespace, as the proxy injector reads and injects the pods of all namespaces")
+	}
 	return options.validate()
 }
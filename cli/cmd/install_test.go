@@ -2,9 +2,23 @@ package cmd
 
 import (
 	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/pkg/inject"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
 )
 
 func TestRender(t *testing.T) {
@@ -18,6 +32,85 @@ func TestRender(t *testing.T) {
 	}
 	defaultConfig.UUID = "deaab91a-f4ab-448a-b7d1-c832a2fa0a60"
 
+	// A high availability configuration, as rendered for `linkerd install --ha`.
+	haOptions := newInstallOptions()
+	haOptions.highAvailability = true
+	haOptions.recordedFlags = []k8s.InstallFlag{{Name: "ha", Value: "true"}}
+	haConfig, err := validateAndBuildConfig(haOptions)
+	if err != nil {
+		t.Fatalf("Unexpected error from validateAndBuildConfig(): %v", err)
+	}
+	haConfig.UUID = "deaab91a-f4ab-448a-b7d1-c832a2fa0a60"
+
+	// Configurations pulling images from a mirror, with the default version
+	// and with another one.
+	registryOptions := newInstallOptions()
+	registryOptions.dockerRegistry = "registry.example.com:5000/linkerd-io"
+	registryConfig, err := validateAndBuildConfig(registryOptions)
+	if err != nil {
+		t.Fatalf("Unexpected error from validateAndBuildConfig(): %v", err)
+	}
+	registryConfig.UUID = "deaab91a-f4ab-448a-b7d1-c832a2fa0a60"
+
+	registryVersionOptions := newInstallOptions()
+	registryVersionOptions.dockerRegistry = "registry.example.com:5000/linkerd-io"
+	registryVersionOptions.linkerdVersion = "edge-18.9.1"
+	registryVersionConfig, err := validateAndBuildConfig(registryVersionOptions)
+	if err != nil {
+		t.Fatalf("Unexpected error from validateAndBuildConfig(): %v", err)
+	}
+	registryVersionConfig.UUID = "deaab91a-f4ab-448a-b7d1-c832a2fa0a60"
+
+	// A configuration restricted to the control plane namespace, as rendered
+	// for `linkerd install --single-namespace --tls optional`.
+	singleNamespaceOptions := newInstallOptions()
+	singleNamespaceOptions.singleNamespace = true
+	singleNamespaceOptions.tls = optionalTLS
+	singleNamespaceOptions.recordedFlags = []k8s.InstallFlag{{Name: "single-namespace", Value: "true"}, {Name: "tls", Value: optionalTLS}}
+	singleNamespaceConfig, err := validateAndBuildConfig(singleNamespaceOptions)
+	if err != nil {
+		t.Fatalf("Unexpected error from validateAndBuildConfig(): %v", err)
+	}
+	singleNamespaceConfig.UUID = "deaab91a-f4ab-448a-b7d1-c832a2fa0a60"
+
+	// A configuration pinning the control plane to tainted infrastructure
+	// nodes, with TLS so that the CA is pinned too.
+	nodeSelectorOptions := newInstallOptions()
+	nodeSelectorOptions.nodeSelector = []string{"node-role.kubernetes.io/infra=true", "beta.kubernetes.io/arch=amd64"}
+	nodeSelectorOptions.tolerations = []string{"dedicated=infra:NoSchedule", "node-role.kubernetes.io/infra"}
+	nodeSelectorOptions.tls = optionalTLS
+	nodeSelectorConfig, err := validateAndBuildConfig(nodeSelectorOptions)
+	if err != nil {
+		t.Fatalf("Unexpected error from validateAndBuildConfig(): %v", err)
+	}
+	nodeSelectorConfig.UUID = "deaab91a-f4ab-448a-b7d1-c832a2fa0a60"
+
+	// A configuration for clusters enforcing PodSecurityPolicies, as rendered
+	// for `linkerd install --enable-psp --tls optional`, so that the CA is
+	// allowed to use the policy too.
+	pspOptions := newInstallOptions()
+	pspOptions.enablePSP = true
+	pspOptions.tls = optionalTLS
+	pspOptions.recordedFlags = []k8s.InstallFlag{{Name: "enable-psp", Value: "true"}, {Name: "tls", Value: optionalTLS}}
+	pspConfig, err := validateAndBuildConfig(pspOptions)
+	if err != nil {
+		t.Fatalf("Unexpected error from validateAndBuildConfig(): %v", err)
+	}
+	pspConfig.UUID = "deaab91a-f4ab-448a-b7d1-c832a2fa0a60"
+
+	// A configuration running the proxy injector, as rendered for `linkerd
+	// install --proxy-auto-inject`, with its random certificate overridden
+	// with fixed values too.
+	autoInjectOptions := newInstallOptions()
+	autoInjectOptions.proxyAutoInject = true
+	autoInjectOptions.recordedFlags = []k8s.InstallFlag{{Name: "proxy-auto-inject", Value: "true"}}
+	autoInjectConfig, err := validateAndBuildConfig(autoInjectOptions)
+	if err != nil {
+		t.Fatalf("Unexpected error from validateAndBuildConfig(): %v", err)
+	}
+	autoInjectConfig.UUID = "deaab91a-f4ab-448a-b7d1-c832a2fa0a60"
+	autoInjectConfig.ProxyInjectorTLS = &proxyInjectorTLS{Cert: "Q2VydA==", Key: "S2V5", CABundle: "Q0FCdW5kbGU="}
+
 	// A configuration that shows that all config setting strings are honored
 	// by `render()`.
 	metaConfig := installConfig{
@@ -34,19 +127,31 @@ func TestRender(t *testing.T) {
 		CliVersion:                  "CliVersion",
 		ControllerLogLevel:          "ControllerLogLevel",
 		ControllerComponentLabel:    "ControllerComponentLabel",
+		ControllerNamespaceLabel:    "ControllerNamespaceLabel",
 		CreatedByAnnotation:         "CreatedByAnnotation",
 		ProxyAPIPort:                123,
 		EnableTLS:                   true,
 		TLSTrustAnchorConfigMapName: "TLSTrustAnchorConfigMapName",
+		EnableHA:                    true,
+		InstallConfigMapName:        "InstallConfigMapName",
+		InstallOptions:              "InstallOptions",
 	}
 
 	testCases := []struct {
 		config                installConfig
+		options               *installOptions
 		controlPlaneNamespace string
 		goldenFileName        string
 	}{
-		{*defaultConfig, defaultControlPlaneNamespace, "testdata/install_default.golden"},
-		{metaConfig, metaConfig.Namespace, "testdata/install_output.golden"},
+		{*defaultConfig, defaultOptions, defaultControlPlaneNamespace, "testdata/install_default.golden"},
+		{metaConfig, defaultOptions, metaConfig.Namespace, "testdata/install_output.golden"},
+		{*haConfig, defaultOptions, defaultControlPlaneNamespace, "testdata/install_ha.golden"},
+		{*registryConfig, registryOptions, defaultControlPlaneNamespace, "testdata/install_custom_registry.golden"},
+		{*registryVersionConfig, registryVersionOptions, defaultControlPlaneNamespace, "testdata/install_custom_registry_version.golden"},
+		{*singleNamespaceConfig, singleNamespaceOptions, defaultControlPlaneNamespace, "testdata/install_single_namespace.golden"},
+		{*nodeSelectorConfig, nodeSelectorOptions, defaultControlPlaneNamespace, "testdata/install_node_selector.golden"},
+		{*pspConfig, pspOptions, defaultControlPlaneNamespace, "testdata/install_psp.golden"},
+		{*autoInjectConfig, autoInjectOptions, defaultControlPlaneNamespace, "testdata/install_proxy_auto_inject.golden"},
 	}
 
 	for i, tc := range testCases {
@@ -54,7 +159,7 @@ func TestRender(t *testing.T) {
 			controlPlaneNamespace = tc.controlPlaneNamespace
 
 			var buf bytes.Buffer
-			err := render(tc.config, &buf, defaultOptions)
+			err := render(tc.config, &buf, tc.options)
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
@@ -69,3 +174,401 @@ func TestRender(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateAndBuildConfig(t *testing.T) {
+	t.Run("Runs 3 replicas with --ha", func(t *testing.T) {
+		options := newInstallOptions()
+		options.highAvailability = true
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !config.EnableHA || config.ControllerReplicas != 3 || config.WebReplicas != 3 || config.PrometheusReplicas != 1 {
+			t.Fatalf("Unexpected high availability config: %+v", config)
+		}
+	})
+
+	t.Run("Honors replicas set along with --ha", func(t *testing.T) {
+		options := newInstallOptions()
+		options.highAvailability = true
+		options.controllerReplicas = 5
+		options.recordedFlags = []k8s.InstallFlag{{Name: "controller-replicas", Value: "5"}, {Name: "ha", Value: "true"}}
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.ControllerReplicas != 5 || config.WebReplicas != 3 {
+			t.Fatalf("Expected 5 controller and 3 web replicas, got %d and %d", config.ControllerReplicas, config.WebReplicas)
+		}
+	})
+
+	t.Run("Honors a single replica set along with --ha", func(t *testing.T) {
+		options := newInstallOptions()
+		cmd := &cobra.Command{}
+		addInstallFlags(cmd, options)
+		if err := cmd.PersistentFlags().Parse([]string{"--ha", "--controller-replicas=1"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		options.recordedFlags = recordFlags(cmd.PersistentFlags())
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.ControllerReplicas != 1 || config.WebReplicas != 3 {
+			t.Fatalf("Expected 1 controller and 3 web replicas, got %d and %d", config.ControllerReplicas, config.WebReplicas)
+		}
+	})
+
+	t.Run("Pulls all images from the registry", func(t *testing.T) {
+		options := newInstallOptions()
+		options.dockerRegistry = "registry.example.com:5000/linkerd-io"
+		options.linkerdVersion = "edge-18.9.1"
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		images := []string{config.ControllerImage, config.WebImage, config.GrafanaImage, config.PrometheusImage, options.taggedProxyImage(), options.taggedProxyInitImage()}
+		expected := []string{
+			"registry.example.com:5000/linkerd-io/controller:edge-18.9.1",
+			"registry.example.com:5000/linkerd-io/web:edge-18.9.1",
+			"registry.example.com:5000/linkerd-io/grafana:edge-18.9.1",
+			"registry.example.com:5000/linkerd-io/prometheus:v2.3.1",
+			"registry.example.com:5000/linkerd-io/proxy:edge-18.9.1",
+			"registry.example.com:5000/linkerd-io/proxy-init:edge-18.9.1",
+		}
+		for i := range expected {
+			if images[i] != expected[i] {
+				t.Fatalf("Expected image [%s], got [%s]", expected[i], images[i])
+			}
+		}
+	})
+
+	t.Run("Rejects registries with a tag or digest", func(t *testing.T) {
+		testCases := []struct {
+			registry    string
+			expectedErr string
+		}{
+			{"gcr.io/linkerd-io:stable-2.0.0", "--registry must not include a tag, was [gcr.io/linkerd-io:stable-2.0.0]; use --linkerd-version to choose the version of the images"},
+			{"gcr.io/linkerd-io@sha256:0f5d1c8b3d", "--registry must not include a digest, was [gcr.io/linkerd-io@sha256:0f5d1c8b3d]"},
+			{"gcr.io/linkerd-io/", "gcr.io/linkerd-io/ is not a valid Docker registry"},
+			{"", " is not a valid Docker registry"},
+		}
+		for _, tc := range testCases {
+			options := newInstallOptions()
+			options.dockerRegistry = tc.registry
+			_, err := validateAndBuildConfig(options)
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Fatalf("Expected error [%s] for registry [%s], got [%v]", tc.expectedErr, tc.registry, err)
+			}
+		}
+	})
+
+	t.Run("Accepts registries on a port", func(t *testing.T) {
+		for _, registry := range []string{"localhost:5000", "registry.example.com:5000/mirrors/linkerd-io"} {
+			options := newInstallOptions()
+			options.dockerRegistry = registry
+			if _, err := validateAndBuildConfig(options); err != nil {
+				t.Fatalf("Unexpected error for registry [%s]: %v", registry, err)
+			}
+		}
+	})
+
+	t.Run("Records the flags set on the command line", func(t *testing.T) {
+		cmd := newCmdInstall()
+		if err := cmd.PersistentFlags().Parse([]string{"--web-replicas=2", "--ha"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		options := newInstallOptions()
+		options.recordedFlags = recordFlags(cmd.PersistentFlags())
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := `{"flags":[{"name":"ha","value":"true"},{"name":"web-replicas","value":"2"}]}`
+		if config.InstallOptions != expected {
+			t.Fatalf("Expected install options [%s], got [%s]", expected, config.InstallOptions)
+		}
+	})
+
+	t.Run("Does not record where the configs are written to", func(t *testing.T) {
+		cmd := newCmdInstall()
+		if err := cmd.PersistentFlags().Parse([]string{"--output-dir=manifests", "--force", "--ha"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []k8s.InstallFlag{{Name: "ha", Value: "true"}}
+		if recorded := recordFlags(cmd.PersistentFlags()); !reflect.DeepEqual(recorded, expected) {
+			t.Fatalf("Expected recorded flags %+v, got %+v", expected, recorded)
+		}
+	})
+
+	t.Run("Records slices as comma-separated values", func(t *testing.T) {
+		cmd := newCmdInstall()
+		args := []string{"--control-plane-node-selector=beta.kubernetes.io/arch=amd64", "--control-plane-node-selector=disk=ssd"}
+		if err := cmd.PersistentFlags().Parse(args); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []k8s.InstallFlag{{Name: "control-plane-node-selector", Value: "beta.kubernetes.io/arch=amd64,disk=ssd"}}
+		if recorded := recordFlags(cmd.PersistentFlags()); !reflect.DeepEqual(recorded, expected) {
+			t.Fatalf("Expected recorded flags %+v, got %+v", expected, recorded)
+		}
+	})
+
+	t.Run("Rejects invalid proxy resources", func(t *testing.T) {
+		options := newInstallOptions()
+		options.proxyMemoryLimit = "256M1"
+		_, err := validateAndBuildConfig(options)
+		expected := "Invalid quantity '256M1' for --proxy-memory-limit flag"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Rejects invalid node selectors and tolerations", func(t *testing.T) {
+		testCases := []struct {
+			nodeSelector []string
+			tolerations  []string
+			expectedErr  string
+		}{
+			{nodeSelector: []string{"infra"}, expectedErr: "--control-plane-node-selector must be of the form key=value, was [infra]"},
+			{nodeSelector: []string{"-infra=true"}, expectedErr: "invalid --control-plane-node-selector [-infra=true]: name part must consist of alphanumeric characters"},
+			{nodeSelector: []string{"infra=not valid"}, expectedErr: "invalid --control-plane-node-selector [infra=not valid]: a valid label must be"},
+			{tolerations: []string{"dedicated=infra:NoWay"}, expectedErr: "invalid --control-plane-toleration [dedicated=infra:NoWay]: effect must be one of: NoSchedule, PreferNoSchedule, NoExecute"},
+			{tolerations: []string{"=infra"}, expectedErr: "invalid --control-plane-toleration [=infra]: name part must be non-empty"},
+		}
+		for _, tc := range testCases {
+			options := newInstallOptions()
+			options.nodeSelector = tc.nodeSelector
+			options.tolerations = tc.tolerations
+			_, err := validateAndBuildConfig(options)
+			if err == nil || !strings.HasPrefix(err.Error(), tc.expectedErr) {
+				t.Fatalf("Expected error starting with [%s], got [%v]", tc.expectedErr, err)
+			}
+		}
+	})
+
+	t.Run("Schedules every control plane deployment on the selected nodes", func(t *testing.T) {
+		options := newInstallOptions()
+		options.nodeSelector = []string{"beta.kubernetes.io/arch=amd64"}
+		options.tolerations = []string{"dedicated=infra:NoSchedule", "node-role.kubernetes.io/infra"}
+		options.tls = optionalTLS
+		options.proxyAutoInject = true
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := render(*config, &buf, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedTolerations := []v1.Toleration{
+			{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "infra", Effect: v1.TaintEffectNoSchedule},
+			{Key: "node-role.kubernetes.io/infra", Operator: v1.TolerationOpExists},
+		}
+		var deployments []string
+		for _, doc := range strings.Split(buf.String(), "\n---\n") {
+			var deployment v1beta1.Deployment
+			if err := yaml.Unmarshal([]byte(doc), &deployment); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if deployment.Kind != "Deployment" {
+				continue
+			}
+			deployments = append(deployments, deployment.Name)
+
+			spec := deployment.Spec.Template.Spec
+			if !reflect.DeepEqual(spec.NodeSelector, map[string]string{"beta.kubernetes.io/arch": "amd64"}) {
+				t.Fatalf("Unexpected node selector of deployment [%s]: %v", deployment.Name, spec.NodeSelector)
+			}
+			if !reflect.DeepEqual(spec.Tolerations, expectedTolerations) {
+				t.Fatalf("Unexpected tolerations of deployment [%s]: %+v", deployment.Name, spec.Tolerations)
+			}
+		}
+
+		expected := []string{"controller", "web", "prometheus", "grafana", "ca", "proxy-injector"}
+		if !reflect.DeepEqual(deployments, expected) {
+			t.Fatalf("Expected deployments %v, got %v", expected, deployments)
+		}
+	})
+
+	t.Run("Issues the proxy injector a certificate for its service", func(t *testing.T) {
+		options := newInstallOptions()
+		options.proxyAutoInject = true
+		config, err := validateAndBuildConfig(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		decode := func(value string) []byte {
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			return decoded
+		}
+		secret := &v1.Secret{Data: map[string][]byte{
+			v1.TLSCertKey:       decode(config.ProxyInjectorTLS.Cert),
+			v1.TLSPrivateKeyKey: decode(config.ProxyInjectorTLS.Key),
+		}}
+		tlsSecret, err := k8s.ParseTLSSecret(secret)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(decode(config.ProxyInjectorTLS.CABundle)) {
+			t.Fatalf("Expected the CA bundle to hold a certificate")
+		}
+		dnsName := fmt.Sprintf("linkerd-proxy-injector.%s.svc", controlPlaneNamespace)
+		if _, err := tlsSecret.Certificate.Verify(x509.VerifyOptions{DNSName: dnsName, Roots: roots}); err != nil {
+			t.Fatalf("Expected the certificate to be valid for [%s]: %v", dnsName, err)
+		}
+
+		var proxyConfig inject.Config
+		if err := json.Unmarshal([]byte(config.ProxyConfig), &proxyConfig); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if proxyConfig.ProxyImage != options.taggedProxyImage() || proxyConfig.InboundPort != defaultInboundPort {
+			t.Fatalf("Unexpected proxy configuration: %+v", proxyConfig)
+		}
+	})
+
+	t.Run("Rejects invalid proxy injector options", func(t *testing.T) {
+		options := newInstallOptions()
+		options.proxyInjectorFailurePolicy = "Retry"
+		_, err := validateAndBuildConfig(options)
+		expected := "--proxy-injector-failure-policy must be one of: Ignore, Fail"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+
+		options = newInstallOptions()
+		options.proxyAutoInjectDefault = "opt-in"
+		_, err = validateAndBuildConfig(options)
+		expected = "--proxy-auto-inject-default must be one of: enabled, disabled"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+
+		options = newInstallOptions()
+		options.proxyAutoInject = true
+		options.singleNamespace = true
+		_, err = validateAndBuildConfig(options)
+		if err == nil || !strings.HasPrefix(err.Error(), "--proxy-auto-inject cannot be used with --single-namespace") {
+			t.Fatalf("Expected an error for --single-namespace, got [%v]", err)
+		}
+	})
+}
+
+func TestRenderToDir(t *testing.T) {
+	options := newInstallOptions()
+	config, err := validateAndBuildConfig(options)
+	if err != nil {
+		t.Fatalf("Unexpected error from validateAndBuildConfig(): %v", err)
+	}
+	config.UUID = "deaab91a-f4ab-448a-b7d1-c832a2fa0a60"
+
+	tmpDir, err := ioutil.TempDir("", "linkerd-install")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	t.Run("Writes a file per resource that concatenate to the stdout output", func(t *testing.T) {
+		options.outputDir = filepath.Join(tmpDir, "manifests", "linkerd")
+		if err := renderToDir(*config, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedFiles := []string{
+			"namespace-linkerd.yaml",
+			"configmap-linkerd-config.yaml",
+			"serviceaccount-linkerd-controller.yaml",
+			"clusterrole-linkerd-linkerd-controller.yaml",
+			"clusterrolebinding-linkerd-linkerd-controller.yaml",
+			"customresourcedefinition-serviceprofiles.linkerd.io.yaml",
+			"serviceaccount-linkerd-prometheus.yaml",
+			"clusterrole-linkerd-linkerd-prometheus.yaml",
+			"clusterrolebinding-linkerd-linkerd-prometheus.yaml",
+			"service-api.yaml",
+			"service-proxy-api.yaml",
+			"deployment-controller.yaml",
+			"service-web.yaml",
+			"deployment-web.yaml",
+			"service-prometheus.yaml",
+			"deployment-prometheus.yaml",
+			"configmap-prometheus-config.yaml",
+			"service-grafana.yaml",
+			"deployment-grafana.yaml",
+			"configmap-grafana-config.yaml",
+		}
+		written, err := ioutil.ReadDir(options.outputDir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(written) != len(expectedFiles) {
+			t.Fatalf("Expected %d files, got %d", len(expectedFiles), len(written))
+		}
+
+		var concatenated bytes.Buffer
+		for _, name := range expectedFiles {
+			content, err := ioutil.ReadFile(filepath.Join(options.outputDir, name))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			concatenated.Write(content)
+		}
+
+		var stdout bytes.Buffer
+		if err := render(*config, &stdout, options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		diffCompare(t, concatenated.String(), stdout.String())
+	})
+
+	t.Run("Refuses to overwrite a non-empty directory", func(t *testing.T) {
+		options.outputDir = filepath.Join(tmpDir, "manifests", "linkerd")
+		err := renderToDir(*config, options)
+		expected := fmt.Sprintf("--output-dir [%s] is not empty; pass --force to overwrite its files", options.outputDir)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+
+		options.force = true
+		if err := renderToDir(*config, options); err != nil {
+			t.Fatalf("Unexpected error with --force: %v", err)
+		}
+	})
+}
+
+func TestSplitManifests(t *testing.T) {
+	t.Run("Keeps the comment of each resource in its file", func(t *testing.T) {
+		stream := "### A ###\nkind: ConfigMap\nmetadata:\n  name: a\n\n### B ###\n---\nkind: Secret\nmetadata:\n  name: b\n---\n"
+		files, err := splitManifests([]byte(stream))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []manifestFile{
+			{name: "configmap-a.yaml", content: []byte("### A ###\nkind: ConfigMap\nmetadata:\n  name: a\n\n")},
+			{name: "secret-b.yaml", content: []byte("### B ###\n---\nkind: Secret\nmetadata:\n  name: b\n---\n")},
+		}
+		if !reflect.DeepEqual(files, expected) {
+			t.Fatalf("Expected files %q, got %q", expected, files)
+		}
+	})
+
+	t.Run("Rejects resources that would be written to the same file", func(t *testing.T) {
+		stream := "kind: ConfigMap\nmetadata:\n  name: a\n---\nkind: ConfigMap\nmetadata:\n  name: a\n"
+		_, err := splitManifests([]byte(stream))
+		expected := "several resources would be written to [configmap-a.yaml]"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+)
+
+// logsComponents are the values of the component label of the control plane
+// pods, as set by `linkerd install`.
+var logsComponents = []string{"controller", "web", "prometheus", "grafana", "ca", "proxy-injector"}
+
+// logsProxyContainer is the shorthand of --container for the proxy container
+// of the control plane pods.
+const logsProxyContainer = "proxy"
+
+// logsReattachInterval is how often, with --follow, a container whose log
+// stream has ended is checked for having restarted.
+var logsReattachInterval = 2 * time.Second
+
+// logsColors are the ANSI colors of the prefixes of the log lines, one per
+// stream, in turn.
+var logsColors = []string{"31", "32", "33", "34", "35", "36"}
+
+type logsOptions struct {
+	component string
+	container string
+	follow    bool
+	since     time.Duration
+	tail      int64
+}
+
+func newLogsOptions() *logsOptions {
+	return &logsOptions{
+		component: "",
+		container: "",
+		follow:    false,
+		since:     0,
+		tail:      0,
+	}
+}
+
+func newCmdLogs() *cobra.Command {
+	options := newLogsOptions()
+
+	cmd := &cobra.Command{
+		Use:   "logs [flags]",
+		Short: "Tail logs from the containers of the control plane",
+		Long: `Tail logs from the containers of the control plane.
+
+The logs of all the containers of the control plane pods in --linkerd-namespace
+are displayed as they are streamed, interleaved, each line prefixed with the
+pod and container it was written by. --component and --container restrict
+them to the pods of one component, and to one container of each pod; the
+proxy container can be named "proxy".
+
+With --follow, the logs of a container that restarts are displayed again once
+it is running, from the start of its new logs. Pods created after the command
+has started are not followed.`,
+		Example: `  # Tail the logs of all the containers of the control plane
+  linkerd logs
+
+  # Follow the logs of the public-api container of the controller pods
+  linkerd logs --component controller --container public-api --follow
+
+  # Display the last 10 lines that the proxies of the control plane wrote in the last hour
+  linkerd logs --container proxy --since 1h --tail 10`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.validate(); err != nil {
+				return err
+			}
+
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeAPIOptions())
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := newSignalContext()
+			defer cancel()
+
+			return runLogs(ctx, kubeAPI, os.Stdout, os.Stderr, options, isTerminal(os.Stdout))
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.component, "component", "c", options.component, fmt.Sprintf("Control plane component whose pods to display the logs of. One of: %s", strings.Join(logsComponents, ", ")))
+	cmd.PersistentFlags().StringVar(&options.container, "container", options.container, "Container of the pods to display the logs of, such as \"public-api\", or \"proxy\" for the proxy container")
+	cmd.PersistentFlags().BoolVarP(&options.follow, "follow", "f", options.follow, "Keep streaming the logs as they are written, until interrupted")
+	cmd.PersistentFlags().DurationVar(&options.since, "since", options.since, "Only display the logs written in this duration before now, such as \"10s\" or \"1h\"; all logs by default")
+	cmd.PersistentFlags().Int64Var(&options.tail, "tail", options.tail, "Only display the most recent lines of the logs of each container; all lines by default")
+
+	return cmd
+}
+
+func (o *logsOptions) validate() error {
+	if o.component != "" {
+		valid := false
+		for _, component := range logsComponents {
+			valid = valid || component == o.component
+		}
+		if !valid {
+			return fmt.Errorf("component \"%s\" not recognized, must be one of: %s", o.component, strings.Join(logsComponents, ", "))
+		}
+	}
+	if o.since < 0 {
+		return fmt.Errorf("--since must not be negative, got %s", o.since)
+	}
+	if o.tail < 0 {
+		return fmt.Errorf("--tail must not be negative, got %d", o.tail)
+	}
+	return nil
+}
+
+// podSelector returns the label selector of the control plane pods whose logs
+// are displayed.
+func (o *logsOptions) podSelector() string {
+	if o.component == "" {
+		return k8s.ControllerComponentLabel
+	}
+	return fmt.Sprintf("%s=%s", k8s.ControllerComponentLabel, o.component)
+}
+
+// containerName returns the name of the container whose logs are displayed,
+// or an empty string for all containers.
+func (o *logsOptions) containerName() string {
+	if o.container == logsProxyContainer {
+		return k8s.ProxyContainerName
+	}
+	return o.container
+}
+
+// logOptions returns the options of the first log stream of each container.
+// Seconds are rounded up, so that --since includes all the logs requested.
+func (o *logsOptions) logOptions() k8s.LogOptions {
+	sinceSeconds := int64(o.since / time.Second)
+	if o.since%time.Second != 0 {
+		sinceSeconds++
+	}
+	return k8s.LogOptions{
+		Follow:       o.follow,
+		TailLines:    o.tail,
+		SinceSeconds: sinceSeconds,
+	}
+}
+
+// logStream is a container of a pod whose logs are displayed.
+type logStream struct {
+	pod       string
+	container string
+	prefix    string
+}
+
+// logStreams returns the streams of the containers of pods, as selected by
+// options, each prefixed with "pod/container", colored if colorize is set.
+func logStreams(pods []v1.Pod, options *logsOptions, colorize bool) []*logStream {
+	container := options.containerName()
+	var streams []*logStream
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			if container != "" && c.Name != container {
+				continue
+			}
+			prefix := pod.Name + "/" + c.Name
+			if colorize {
+				color := logsColors[len(streams)%len(logsColors)]
+				prefix = "\033[" + color + "m" + prefix + "\033[0m"
+			}
+			streams = append(streams, &logStream{pod: pod.Name, container: c.Name, prefix: prefix})
+		}
+	}
+	return streams
+}
+
+// runLogs writes the logs of the control plane pods selected by options to w,
+// streamed concurrently, until they end, or, with --follow, until ctx is
+// cancelled. The errors of single streams are reported on stderr, so that they
+// do not stop the others.
+func runLogs(ctx context.Context, kubeAPI *k8s.KubernetesAPI, w, stderr io.Writer, options *logsOptions, colorize bool) error {
+	pods, err := kubeAPI.GetPodsFor(ctx, controlPlaneNamespace, options.podSelector())
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no control plane pods found in namespace \"%s\" for selector \"%s\"", controlPlaneNamespace, options.podSelector())
+	}
+
+	streams := logStreams(pods, options, colorize)
+	if len(streams) == 0 {
+		return fmt.Errorf("no container named \"%s\" found in the control plane pods", options.containerName())
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, stream := range streams {
+		wg.Add(1)
+		go func(stream *logStream) {
+			defer wg.Done()
+			// the streams of an interrupted command end with the errors of
+			// their cancelled requests
+			if err := streamLogs(ctx, kubeAPI, stream, &mu, w, options); err != nil && ctx.Err() == nil {
+				mu.Lock()
+				fmt.Fprintf(stderr, "Error streaming the logs of %s/%s: %s\n", stream.pod, stream.container, err)
+				mu.Unlock()
+			}
+		}(stream)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// streamLogs writes the lines of the logs of stream to w, holding mu for each
+// line, so that the lines of concurrent streams are not mixed up. With
+// --follow, once the stream ends, it is reattached when the container has
+// restarted, or, if the API server ended the stream of the same container,
+// from about when it ended.
+func streamLogs(ctx context.Context, kubeAPI *k8s.KubernetesAPI, stream *logStream, mu *sync.Mutex, w io.Writer, options *logsOptions) error {
+	logOptions := options.logOptions()
+
+	// the container is known to have restarted once its ID has changed
+	var containerID string
+	if options.follow {
+		pod, err := kubeAPI.GetPod(ctx, controlPlaneNamespace, stream.pod)
+		if err != nil {
+			return err
+		}
+		if status := logContainerStatus(pod, stream.container); status != nil {
+			containerID = status.ContainerID
+		}
+	}
+
+	for {
+		logs, err := kubeAPI.StreamPodLogs(ctx, controlPlaneNamespace, stream.pod, stream.container, logOptions)
+		if err != nil {
+			return err
+		}
+
+		scanner := bufio.NewScanner(logs)
+		for scanner.Scan() {
+			mu.Lock()
+			fmt.Fprintf(w, "%s %s\n", stream.prefix, scanner.Text())
+			mu.Unlock()
+		}
+		logs.Close()
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		if !options.follow {
+			return nil
+		}
+		ended := time.Now()
+
+		status, err := waitForLogContainer(ctx, kubeAPI, stream)
+		if err != nil || status == nil {
+			return err
+		}
+
+		logOptions = k8s.LogOptions{Follow: true}
+		if status.ContainerID == containerID {
+			logOptions.SinceSeconds = int64(time.Since(ended)/time.Second) + 1
+		}
+		containerID = status.ContainerID
+	}
+}
+
+// waitForLogContainer returns the status of the container of stream once it
+// is running, or nil if ctx is cancelled first.
+func waitForLogContainer(ctx context.Context, kubeAPI *k8s.KubernetesAPI, stream *logStream) (*v1.ContainerStatus, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(logsReattachInterval):
+		}
+
+		pod, err := kubeAPI.GetPod(ctx, controlPlaneNamespace, stream.pod)
+		if err != nil {
+			return nil, err
+		}
+		if status := logContainerStatus(pod, stream.container); status != nil && status.State.Running != nil {
+			return status, nil
+		}
+	}
+}
+
+func logContainerStatus(pod *v1.Pod, container string) *v1.ContainerStatus {
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == container {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
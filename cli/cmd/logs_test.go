@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// logsServer is a fake API server for a control plane in the linkerd
+// namespace, with a controller pod and a web pod. The logs of their
+// containers are the lines returned by logs, and the IDs of their containers
+// those returned by containerID, each called with the number of times the
+// pod has been requested before.
+type logsServer struct {
+	*httptest.Server
+	t           *testing.T
+	logs        func(pod, container string, query url.Values) string
+	containerID func(requests int) string
+
+	mu           sync.Mutex
+	selectors    []string
+	podRequests  map[string]int
+	logsRequests []url.Values
+}
+
+func newLogsServer(t *testing.T, logs func(pod, container string, query url.Values) string, containerID func(requests int) string) *logsServer {
+	s := &logsServer{t: t, logs: logs, containerID: containerID, podRequests: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func logsPod(name string, containers ...string) v1.Pod {
+	pod := v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "linkerd"}}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: container})
+	}
+	return pod
+}
+
+func (s *logsServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pods := map[string]v1.Pod{
+		"controller-6f78cbd47-bclfd": logsPod("controller-6f78cbd47-bclfd", "public-api", "linkerd-proxy"),
+		"web-5f7c6b8dd8-x2kqd":       logsPod("web-5f7c6b8dd8-x2kqd", "web", "linkerd-proxy"),
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/linkerd/pods"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "":
+		s.selectors = append(s.selectors, r.URL.Query().Get("labelSelector"))
+		list := v1.PodList{Items: []v1.Pod{pods["controller-6f78cbd47-bclfd"], pods["web-5f7c6b8dd8-x2kqd"]}}
+		writeJSON(s.t, w, list)
+	case len(parts) == 2 && pods[parts[1]].Name != "":
+		pod := pods[parts[1]]
+		for _, container := range pod.Spec.Containers {
+			pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{
+				Name:        container.Name,
+				ContainerID: s.containerID(s.podRequests[pod.Name]),
+				State:       v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+			})
+		}
+		s.podRequests[pod.Name]++
+		writeJSON(s.t, w, pod)
+	case len(parts) == 3 && parts[2] == "log" && pods[parts[1]].Name != "":
+		query := r.URL.Query()
+		s.logsRequests = append(s.logsRequests, query)
+		w.Write([]byte(s.logs(parts[1], query.Get("container"), query)))
+	default:
+		s.t.Errorf("Unexpected request to [%s]", r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, obj interface{}) {
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestRunLogs(t *testing.T) {
+	defaultControlPlaneNamespace := controlPlaneNamespace
+	controlPlaneNamespace = "linkerd"
+	defer func() { controlPlaneNamespace = defaultControlPlaneNamespace }()
+
+	twoLines := func(pod, container string, query url.Values) string {
+		return fmt.Sprintf("%s started\n%s ready\n", container, container)
+	}
+	sameContainer := func(int) string { return "docker://1" }
+
+	t.Run("Prefixes the lines of the logs of all the containers of the control plane", func(t *testing.T) {
+		server := newLogsServer(t, twoLines, sameContainer)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		var out, stderr bytes.Buffer
+		if err := runLogs(context.Background(), kubeAPI, &out, &stderr, newLogsOptions(), false); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		// the streams are interleaved, but the lines of each are in order
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		expected := []string{
+			"controller-6f78cbd47-bclfd/linkerd-proxy linkerd-proxy started",
+			"controller-6f78cbd47-bclfd/linkerd-proxy linkerd-proxy ready",
+			"controller-6f78cbd47-bclfd/public-api public-api started",
+			"controller-6f78cbd47-bclfd/public-api public-api ready",
+			"web-5f7c6b8dd8-x2kqd/linkerd-proxy linkerd-proxy started",
+			"web-5f7c6b8dd8-x2kqd/linkerd-proxy linkerd-proxy ready",
+			"web-5f7c6b8dd8-x2kqd/web web started",
+			"web-5f7c6b8dd8-x2kqd/web web ready",
+		}
+		sort.SliceStable(lines, func(i, j int) bool {
+			return strings.Fields(lines[i])[0] < strings.Fields(lines[j])[0]
+		})
+		if !reflect.DeepEqual(lines, expected) {
+			t.Fatalf("Expected lines:\n%s\nGot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+		}
+		if stderr.Len() != 0 {
+			t.Fatalf("Unexpected errors: %s", stderr.String())
+		}
+		if !reflect.DeepEqual(server.selectors, []string{"linkerd.io/control-plane-component"}) {
+			t.Fatalf("Expected the pods of all components to be listed, got selectors %v", server.selectors)
+		}
+	})
+
+	t.Run("Discovers the pods of a component by their label", func(t *testing.T) {
+		server := newLogsServer(t, twoLines, sameContainer)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		options := newLogsOptions()
+		options.component = "controller"
+		options.container = "proxy"
+		if err := runLogs(context.Background(), kubeAPI, &bytes.Buffer{}, &bytes.Buffer{}, options, false); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(server.selectors, []string{"linkerd.io/control-plane-component=controller"}) {
+			t.Fatalf("Expected the pods of the controller to be listed, got selectors %v", server.selectors)
+		}
+	})
+
+	t.Run("Translates the flags into the parameters of the log queries", func(t *testing.T) {
+		server := newLogsServer(t, twoLines, sameContainer)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		options := newLogsOptions()
+		options.container = "public-api"
+		options.since = 90*time.Second + 500*time.Millisecond
+		options.tail = 10
+		if err := runLogs(context.Background(), kubeAPI, &bytes.Buffer{}, &bytes.Buffer{}, options, false); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []url.Values{
+			{"container": {"public-api"}, "sinceSeconds": {"91"}, "tailLines": {"10"}},
+		}
+		if !reflect.DeepEqual(server.logsRequests, expected) {
+			t.Fatalf("Expected log queries %v, got %v", expected, server.logsRequests)
+		}
+	})
+
+	t.Run("Reattaches to the logs of containers that restart with --follow", func(t *testing.T) {
+		defaultReattachInterval := logsReattachInterval
+		logsReattachInterval = time.Millisecond
+		defer func() { logsReattachInterval = defaultReattachInterval }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// the container restarts once its logs have been streamed, and the
+		// command is interrupted once the logs of the new one have been
+		restarted := func(requests int) string {
+			switch requests {
+			case 0:
+				return "docker://1"
+			case 1:
+				return "docker://2"
+			}
+			cancel()
+			return "docker://2"
+		}
+		logs := func(pod, container string, query url.Values) string {
+			if query.Get("tailLines") != "" {
+				return "before restart\n"
+			}
+			return "after restart\n"
+		}
+		server := newLogsServer(t, logs, restarted)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		options := newLogsOptions()
+		options.component = "web"
+		options.container = "web"
+		options.follow = true
+		options.tail = 5
+		var out, stderr bytes.Buffer
+		if err := runLogs(ctx, kubeAPI, &out, &stderr, options, false); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedOutput := "web-5f7c6b8dd8-x2kqd/web before restart\nweb-5f7c6b8dd8-x2kqd/web after restart\n"
+		if out.String() != expectedOutput {
+			t.Fatalf("Expected output:\n%s\nGot:\n%s", expectedOutput, out.String())
+		}
+		if stderr.Len() != 0 {
+			t.Fatalf("Unexpected errors: %s", stderr.String())
+		}
+
+		// the logs of the new container are streamed from their start
+		expectedQueries := []url.Values{
+			{"container": {"web"}, "follow": {"true"}, "tailLines": {"5"}},
+			{"container": {"web"}, "follow": {"true"}},
+		}
+		if !reflect.DeepEqual(server.logsRequests[:2], expectedQueries) {
+			t.Fatalf("Expected log queries %v, got %v", expectedQueries, server.logsRequests)
+		}
+	})
+
+	t.Run("Returns an error when no container matches", func(t *testing.T) {
+		server := newLogsServer(t, twoLines, sameContainer)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		options := newLogsOptions()
+		options.container = "tap"
+		err := runLogs(context.Background(), kubeAPI, &bytes.Buffer{}, &bytes.Buffer{}, options, false)
+		expected := "no container named \"tap\" found in the control plane pods"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestLogStreams(t *testing.T) {
+	pods := []v1.Pod{logsPod("controller-6f78cbd47-bclfd", "public-api", "linkerd-proxy")}
+
+	streams := logStreams(pods, newLogsOptions(), true)
+	expected := []string{
+		"\033[31mcontroller-6f78cbd47-bclfd/public-api\033[0m",
+		"\033[32mcontroller-6f78cbd47-bclfd/linkerd-proxy\033[0m",
+	}
+	var prefixes []string
+	for _, stream := range streams {
+		prefixes = append(prefixes, stream.prefix)
+	}
+	if !reflect.DeepEqual(prefixes, expected) {
+		t.Fatalf("Expected colored prefixes %q, got %q", expected, prefixes)
+	}
+}
+
+func TestLogsOptions(t *testing.T) {
+	testCases := []struct {
+		component string
+		since     time.Duration
+		tail      int64
+		expected  string
+	}{
+		{"", 0, 0, ""},
+		{"prometheus", time.Minute, 100, ""},
+		{"tap", 0, 0, "component \"tap\" not recognized, must be one of: controller, web, prometheus, grafana, ca, proxy-injector"},
+		{"", -time.Second, 0, "--since must not be negative, got -1s"},
+		{"", 0, -1, "--tail must not be negative, got -1"},
+	}
+
+	for _, tc := range testCases {
+		options := newLogsOptions()
+		options.component = tc.component
+		options.since = tc.since
+		options.tail = tc.tail
+
+		err := options.validate()
+		if tc.expected == "" && err != nil {
+			t.Fatalf("Unexpected error for %+v: %s", tc, err)
+		}
+		if tc.expected != "" && (err == nil || err.Error() != tc.expected) {
+			t.Fatalf("Expected error [%s] for %+v, got [%v]", tc.expected, tc, err)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/linkerd/linkerd2/controller/api/util"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+)
+
+// proxyMetricsPort is the name of the port of the proxy container that its
+// admin server, and its metrics, are served on.
+const proxyMetricsPort = "linkerd-metrics"
+
+// obfuscatedHashLength is the number of hexadecimal digits of the hashes that
+// replace the values of obfuscated labels.
+const obfuscatedHashLength = 12
+
+// obfuscatedLabel matches the labels of metrics whose values are replaced by
+// --obfuscate, as they name the hosts and paths of the requests.
+var obfuscatedLabel = regexp.MustCompile(`([{,]\s*(?:authority|path)=)"((?:[^"\\]|\\.)*)"`)
+
+type metricsOptions struct {
+	namespace string
+	obfuscate bool
+}
+
+func newMetricsOptions() *metricsOptions {
+	return &metricsOptions{
+		namespace: "default",
+		obfuscate: false,
+	}
+}
+
+func newCmdMetrics() *cobra.Command {
+	options := newMetricsOptions()
+
+	cmd := &cobra.Command{
+		Use:   "metrics [flags] (POD | RESOURCE)",
+		Short: "Fetch the raw metrics of proxies",
+		Long: `Fetch the raw metrics of proxies.
+
+The metrics that the proxies of the given pods serve to Prometheus are fetched
+through the API server's pod proxy, and displayed as they are, each pod's
+preceded by a "# POD: name" line. The RESOURCE argument is either a pod, or a
+deployment whose pods' metrics are displayed, sorted by name: (POD |
+po/NAME | deploy/NAME). Pods without a proxy are reported on stderr, and
+skipped.
+
+With --obfuscate, the values of the authority and path labels are replaced by
+hashes of them, so that the metrics can be shared without naming the hosts and
+paths of the requests; the same values have the same hash.`,
+		Example: `  # Get the metrics of the proxy of the web-5f86686c4d-qh5lm pod
+  linkerd metrics web-5f86686c4d-qh5lm
+
+  # Get the metrics of the proxies of the web deployment in the emojivoto namespace, to share them
+  linkerd metrics deploy/web -n emojivoto --obfuscate`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeAPIOptions())
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := newSignalContext()
+			defer cancel()
+
+			pods, err := getMetricsPods(ctx, kubeAPI, options.namespace, args)
+			if err != nil {
+				return err
+			}
+
+			return writeProxyMetrics(ctx, kubeAPI, pods, options, os.Stdout, os.Stderr)
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the specified resource")
+	cmd.PersistentFlags().BoolVar(&options.obfuscate, "obfuscate", options.obfuscate, "Replace the values of the authority and path labels by hashes of them")
+
+	return cmd
+}
+
+// getMetricsPods returns the pods of the resource named by args in namespace:
+// either a pod, given by its name alone, or a pod or a deployment, given by
+// its type and name. The pods of a deployment are sorted by name.
+func getMetricsPods(ctx context.Context, kubeAPI *k8s.KubernetesAPI, namespace string, args []string) ([]v1.Pod, error) {
+	target := pb.Resource{Namespace: namespace, Type: k8s.Pod, Name: args[0]}
+	if len(args) == 2 || strings.Contains(args[0], "/") {
+		var err error
+		target, err = util.BuildResource(namespace, args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if target.Name == "" {
+		return nil, fmt.Errorf("no name given for the %s, must be one of: POD, po/NAME, deploy/NAME", target.Type)
+	}
+
+	switch target.Type {
+	case k8s.Pod:
+		pod, err := kubeAPI.GetPod(ctx, target.Namespace, target.Name)
+		if err != nil {
+			return nil, err
+		}
+		return []v1.Pod{*pod}, nil
+	case k8s.Deployment:
+		pods, err := kubeAPI.GetPodsForDeployment(ctx, target.Namespace, target.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(pods) == 0 {
+			return nil, fmt.Errorf("no pods found for deployment [%s] in namespace [%s]", target.Name, target.Namespace)
+		}
+		sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+		return pods, nil
+	}
+	return nil, fmt.Errorf("unsupported resource type [%s], must be one of: %s, %s", target.Type, k8s.Pod, k8s.Deployment)
+}
+
+// writeProxyMetrics writes the metrics of the proxies of pods to w, in order.
+// Pods without a proxy are skipped, and reported on stderr, as are the pods
+// whose metrics cannot be fetched, which fails the command once the others
+// have been written.
+func writeProxyMetrics(ctx context.Context, kubeAPI *k8s.KubernetesAPI, pods []v1.Pod, options *metricsOptions, w, stderr io.Writer) error {
+	var fetched, failed int
+	for _, pod := range pods {
+		if !hasProxyContainer(&pod) {
+			fmt.Fprintf(stderr, "Skipping pod [%s]: it has no proxy\n", pod.Name)
+			continue
+		}
+
+		metrics, err := kubeAPI.GetPodProxyResponse(ctx, pod.Namespace, pod.Name, proxyMetricsPort, "/metrics")
+		if err != nil {
+			fmt.Fprintf(stderr, "Error fetching the metrics of pod [%s]: %s\n", pod.Name, err)
+			failed++
+			continue
+		}
+		if options.obfuscate {
+			metrics = obfuscateMetrics(metrics)
+		}
+
+		fmt.Fprintf(w, "# POD: %s\n", pod.Name)
+		w.Write(metrics)
+		if len(metrics) > 0 && metrics[len(metrics)-1] != '\n' {
+			fmt.Fprintln(w)
+		}
+		fetched++
+	}
+
+	switch {
+	case failed > 0:
+		return fmt.Errorf("failed to fetch the metrics of %d of %d pods with a proxy", failed, failed+fetched)
+	case fetched == 0:
+		return fmt.Errorf("none of the pods has a proxy")
+	}
+	return nil
+}
+
+func hasProxyContainer(pod *v1.Pod) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == k8s.ProxyContainerName {
+			return true
+		}
+	}
+	return false
+}
+
+// obfuscateMetrics returns metrics, in the Prometheus text format, with the
+// values of the authority and path labels replaced by the first digits of
+// their SHA-256, so that distinct series remain distinct. Comments are left
+// as they are.
+func obfuscateMetrics(metrics []byte) []byte {
+	lines := strings.SplitAfter(string(metrics), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines[i] = obfuscatedLabel.ReplaceAllStringFunc(line, func(label string) string {
+			match := obfuscatedLabel.FindStringSubmatch(label)
+			hash := fmt.Sprintf("%x", sha256.Sum256([]byte(match[2])))
+			return fmt.Sprintf("%s\"%s\"", match[1], hash[:obfuscatedHashLength])
+		})
+	}
+	return []byte(strings.Join(lines, ""))
+}
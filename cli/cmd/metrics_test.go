@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func metricsPod(name string, proxied bool) v1.Pod {
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "emojivoto"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web"}}},
+	}
+	if proxied {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{
+			Name:  k8s.ProxyContainerName,
+			Ports: []v1.ContainerPort{{Name: proxyMetricsPort, ContainerPort: 4191}},
+		})
+	}
+	return pod
+}
+
+// newMetricsServer returns a fake API server for the emojivoto namespace, with
+// a web deployment of three pods, listed out of order, one of which has no
+// proxy, and a vote-bot pod, without a proxy. The metrics of each proxy are
+// those of metrics, by pod.
+func newMetricsServer(t *testing.T, metrics map[string]string) *httptest.Server {
+	pods := map[string]v1.Pod{
+		"web-5f86686c4d-qh5lm":      metricsPod("web-5f86686c4d-qh5lm", true),
+		"web-5f86686c4d-24v9v":      metricsPod("web-5f86686c4d-24v9v", true),
+		"web-5f86686c4d-x8xcd":      metricsPod("web-5f86686c4d-x8xcd", false),
+		"vote-bot-7466ffc7f7-vmrt5": metricsPod("vote-bot-7466ffc7f7-vmrt5", false),
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path := r.URL.Path; {
+		case path == "/apis/apps/v1/namespaces/emojivoto/deployments/web":
+			writeJSON(t, w, appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "emojivoto"},
+				Spec: appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "web-svc"},
+				}},
+			})
+		case path == "/api/v1/namespaces/emojivoto/pods":
+			if selector := r.URL.Query().Get("labelSelector"); selector != "app=web-svc" {
+				t.Errorf("Unexpected selector [%s]", selector)
+			}
+			writeJSON(t, w, v1.PodList{Items: []v1.Pod{
+				pods["web-5f86686c4d-qh5lm"],
+				pods["web-5f86686c4d-x8xcd"],
+				pods["web-5f86686c4d-24v9v"],
+			}})
+		case strings.HasSuffix(path, ":4191/proxy/metrics"):
+			name := strings.TrimSuffix(strings.TrimPrefix(path, "/api/v1/namespaces/emojivoto/pods/"), ":4191/proxy/metrics")
+			body, ok := metrics[name]
+			if !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte(body))
+		case pods[strings.TrimPrefix(path, "/api/v1/namespaces/emojivoto/pods/")].Name != "":
+			writeJSON(t, w, pods[strings.TrimPrefix(path, "/api/v1/namespaces/emojivoto/pods/")])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGetMetricsPods(t *testing.T) {
+	server := newMetricsServer(t, nil)
+	defer server.Close()
+	kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+	testCases := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"web-5f86686c4d-qh5lm"}, []string{"web-5f86686c4d-qh5lm"}},
+		{[]string{"po/vote-bot-7466ffc7f7-vmrt5"}, []string{"vote-bot-7466ffc7f7-vmrt5"}},
+		{[]string{"pod", "web-5f86686c4d-24v9v"}, []string{"web-5f86686c4d-24v9v"}},
+		{[]string{"deploy/web"}, []string{"web-5f86686c4d-24v9v", "web-5f86686c4d-qh5lm", "web-5f86686c4d-x8xcd"}},
+		{[]string{"deployments", "web"}, []string{"web-5f86686c4d-24v9v", "web-5f86686c4d-qh5lm", "web-5f86686c4d-x8xcd"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			pods, err := getMetricsPods(context.Background(), kubeAPI, "emojivoto", tc.args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var names []string
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tc.expected, ",") {
+				t.Fatalf("Expected pods %v, got %v", tc.expected, names)
+			}
+		})
+	}
+
+	errorCases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"svc/web-svc"}, "unsupported resource type [service], must be one of: pod, deployment"},
+		{[]string{"deploy/"}, "no name given for the deployment, must be one of: POD, po/NAME, deploy/NAME"},
+		{[]string{"deploy/voting"}, "404 Not Found"},
+	}
+
+	for _, tc := range errorCases {
+		tc := tc // pin
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			_, err := getMetricsPods(context.Background(), kubeAPI, "emojivoto", tc.args)
+			if err == nil || !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("Expected error [%s], got [%v]", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestWriteProxyMetrics(t *testing.T) {
+	metrics := map[string]string{
+		"web-5f86686c4d-24v9v": "# HELP request_total Total count of HTTP requests.\n# TYPE request_total counter\n" +
+			"request_total{authority=\"web-svc.emojivoto.svc.cluster.local\",direction=\"inbound\",path=\"/api/list\"} 12\n" +
+			"request_total{direction=\"outbound\",authority=\"emoji-svc.emojivoto:8080\"} 7\n",
+		// without a trailing newline
+		"web-5f86686c4d-qh5lm": "request_total{authority=\"web-svc.emojivoto.svc.cluster.local\",direction=\"inbound\",path=\"/api/list\"} 3",
+	}
+	server := newMetricsServer(t, metrics)
+	defer server.Close()
+	kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+	pods := []v1.Pod{
+		metricsPod("web-5f86686c4d-24v9v", true),
+		metricsPod("web-5f86686c4d-qh5lm", true),
+		metricsPod("web-5f86686c4d-x8xcd", false),
+	}
+
+	t.Run("Writes the metrics of the proxies in order, skipping pods without a proxy", func(t *testing.T) {
+		var out, stderr bytes.Buffer
+		if err := writeProxyMetrics(context.Background(), kubeAPI, pods, newMetricsOptions(), &out, &stderr); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedOutput := "# POD: web-5f86686c4d-24v9v\n" + metrics["web-5f86686c4d-24v9v"] +
+			"# POD: web-5f86686c4d-qh5lm\n" + metrics["web-5f86686c4d-qh5lm"] + "\n"
+		if out.String() != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, out.String())
+		}
+		expectedStderr := "Skipping pod [web-5f86686c4d-x8xcd]: it has no proxy\n"
+		if stderr.String() != expectedStderr {
+			t.Fatalf("Expected stderr [%s], got [%s]", expectedStderr, stderr.String())
+		}
+	})
+
+	t.Run("Obfuscates the authority and path labels with --obfuscate", func(t *testing.T) {
+		options := newMetricsOptions()
+		options.obfuscate = true
+
+		var out bytes.Buffer
+		if err := writeProxyMetrics(context.Background(), kubeAPI, pods, options, &out, &bytes.Buffer{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedOutput := `# POD: web-5f86686c4d-24v9v
+# HELP request_total Total count of HTTP requests.
+# TYPE request_total counter
+request_total{authority="aa55f7199370",direction="inbound",path="b9d08a731b69"} 12
+request_total{direction="outbound",authority="81d2bb8233b3"} 7
+# POD: web-5f86686c4d-qh5lm
+request_total{authority="aa55f7199370",direction="inbound",path="b9d08a731b69"} 3
+`
+		if out.String() != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, out.String())
+		}
+	})
+
+	t.Run("Reports the pods whose metrics cannot be fetched, and fails", func(t *testing.T) {
+		unreachable := append(pods, metricsPod("web-5f86686c4d-9zr2m", true))
+
+		var out, stderr bytes.Buffer
+		err := writeProxyMetrics(context.Background(), kubeAPI, unreachable, newMetricsOptions(), &out, &stderr)
+		expected := "failed to fetch the metrics of 1 of 3 pods with a proxy"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+		if !strings.Contains(out.String(), "# POD: web-5f86686c4d-qh5lm\n") {
+			t.Fatalf("Expected the metrics of the other pods, got: \n%s", out.String())
+		}
+		if !strings.HasPrefix(stderr.String(), "Skipping pod [web-5f86686c4d-x8xcd]: it has no proxy\nError fetching the metrics of pod [web-5f86686c4d-9zr2m]: ") {
+			t.Fatalf("Unexpected stderr [%s]", stderr.String())
+		}
+	})
+
+	t.Run("Returns an error if none of the pods has a proxy", func(t *testing.T) {
+		err := writeProxyMetrics(context.Background(), kubeAPI, pods[2:], newMetricsOptions(), &bytes.Buffer{}, &bytes.Buffer{})
+		expected := "none of the pods has a proxy"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/api/util"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/profiles"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// profileFetchTimeout is the timeout of the requests for the OpenAPI
+	// documents given by URL.
+	profileFetchTimeout = 30 * time.Second
+
+	// profileTapMaxRps is the maximum requests per second tapped by --tap.
+	profileTapMaxRps = 100.0
+)
+
+type profileOptions struct {
+	namespace string
+	template  bool
+	openAPI   string
+	validate  string
+
+	tap           string
+	tapDuration   time.Duration
+	tapRouteLimit int
+}
+
+func newProfileOptions() *profileOptions {
+	return &profileOptions{
+		namespace: "default",
+		template:  false,
+		openAPI:   "",
+		validate:  "",
+
+		tap:           "",
+		tapDuration:   5 * time.Second,
+		tapRouteLimit: 20,
+	}
+}
+
+func (options *profileOptions) validateArgs(args []string) error {
+	modes := []string{}
+	if options.template {
+		modes = append(modes, "--template")
+	}
+	if options.openAPI != "" {
+		modes = append(modes, "--open-api")
+	}
+	if options.tap != "" {
+		modes = append(modes, "--tap")
+	}
+	if options.validate != "" {
+		modes = append(modes, "--validate")
+	}
+	if options.tapDuration <= 0 {
+		return fmt.Errorf("--tap-duration must be positive, got %s", options.tapDuration)
+	}
+	if options.tapRouteLimit <= 0 {
+		return fmt.Errorf("--tap-route-limit must be positive, got %d", options.tapRouteLimit)
+	}
+
+	switch {
+	case len(modes) == 0:
+		return errors.New("one of --template, --open-api, --tap or --validate is required")
+	case len(modes) > 1:
+		return fmt.Errorf("%s cannot be used together", strings.Join(modes, " and "))
+	case options.validate != "":
+		if len(args) != 0 {
+			return errors.New("--validate takes no service, but the file of a ServiceProfile")
+		}
+	default:
+		if len(args) != 1 {
+			return fmt.Errorf("a service is required with %s", modes[0])
+		}
+	}
+	return nil
+}
+
+func newCmdProfile() *cobra.Command {
+	options := newProfileOptions()
+
+	cmd := &cobra.Command{
+		Use:   "profile [flags] (--template | --open-api FILE | --tap RESOURCE) (SERVICE) | --validate FILE",
+		Short: "Output or validate service profiles",
+		Long: `Output or validate service profiles.
+
+  A ServiceProfile describes the routes of a service, by which "linkerd routes"
+  reports the requests to it. Each route has a name, unique within the
+  profile, and a condition on the path and method of the requests that belong
+  to it.
+
+  With --template, a ServiceProfile whose routes are examples to be edited is
+  output for SERVICE. With --open-api, the ServiceProfile of SERVICE is
+  generated from its Swagger 2.0 or OpenAPI 3 document, in FILE, at the URL
+  FILE, or in stdin if FILE is "-": each operation is a route named after its
+  method and path template, such as "GET /books/{id}". The parts of the
+  document that are not supported are skipped, with a warning. With --tap, the
+  ServiceProfile of SERVICE is generated from the requests to RESOURCE, which
+  are tapped for --tap-duration: requests of the same method whose paths only
+  differ by numbers, UUIDs, dates or hexadecimal hashes have the same route,
+  such as "GET /books/{id}", and the --tap-route-limit routes of the most
+  requests are output. With --validate, the ServiceProfile in FILE, or in stdin
+  if FILE is "-", is validated the way the control plane validates the
+  profiles it loads.`,
+		Example: `  # Output a ServiceProfile template for the books service in the default namespace.
+  linkerd profile --template books > books-profile.yml
+
+  # Generate the ServiceProfile of the books service from its OpenAPI document, and apply it.
+  linkerd profile --open-api books.swagger books | kubectl apply -f -
+
+  # Generate the ServiceProfile of the books service from 30 seconds of requests to the books deployment.
+  linkerd profile --tap deploy/books --tap-duration 30s books
+
+  # Validate a ServiceProfile before applying it.
+  linkerd profile --validate books-profile.yml && kubectl apply -f books-profile.yml`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.validateArgs(args); err != nil {
+				return err
+			}
+
+			switch {
+			case options.template:
+				return profiles.RenderTemplate(options.namespace, args[0], os.Stdout)
+			case options.openAPI != "":
+				return renderOpenAPIProfile(options.openAPI, options.namespace, args[0], os.Stdin, os.Stdout, os.Stderr)
+			case options.tap != "":
+				return renderTapProfile(validatedPublicAPIClient(time.Time{}), options, args[0], os.Stdout, os.Stderr)
+			default:
+				return validateProfile(options.validate, os.Stdin, os.Stdout)
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the service")
+	cmd.PersistentFlags().BoolVar(&options.template, "template", options.template, "Output a ServiceProfile template")
+	cmd.PersistentFlags().StringVar(&options.openAPI, "open-api", options.openAPI, "Output a ServiceProfile based on the Swagger 2.0 or OpenAPI 3 document in this file or URL, or in stdin if \"-\"")
+	cmd.PersistentFlags().StringVar(&options.tap, "tap", options.tap, "Output a ServiceProfile based on the requests to this resource, tapped for --tap-duration")
+	cmd.PersistentFlags().DurationVar(&options.tapDuration, "tap-duration", options.tapDuration, "Duration of the tap of --tap")
+	cmd.PersistentFlags().IntVar(&options.tapRouteLimit, "tap-route-limit", options.tapRouteLimit, "Maximum number of routes of the ServiceProfile of --tap, those of the most requests")
+	cmd.PersistentFlags().StringVar(&options.validate, "validate", options.validate, "Validate the ServiceProfile in this file, or in stdin if \"-\"")
+
+	return cmd
+}
+
+// renderOpenAPIProfile writes the ServiceProfile of the service named service
+// in namespace, generated from the OpenAPI document at path, to w, and the
+// warnings about the parts of the document it skipped to stderr.
+func renderOpenAPIProfile(path, namespace, service string, stdin io.Reader, w, stderr io.Writer) error {
+	data, err := readProfileInput(path, stdin)
+	if err != nil {
+		return err
+	}
+
+	profile, warnings, err := profiles.ProfileFromOpenAPI(data, namespace, service)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(stderr, "Warning: %s\n", warning)
+	}
+	return profiles.RenderProfile(profile, w)
+}
+
+// renderTapProfile writes the ServiceProfile of the service named service,
+// generated from the requests to the resource options.tap, tapped for
+// options.tapDuration, to w. The events received once the duration has
+// elapsed are ignored.
+func renderTapProfile(client pb.ApiClient, options *profileOptions, service string, w, stderr io.Writer) error {
+	req, err := util.BuildTapByResourceRequest(util.TapRequestParams{
+		Resource:  options.tap,
+		Namespace: options.namespace,
+		MaxRps:    profileTapMaxRps,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), options.tapDuration)
+	defer cancel()
+
+	rsp, err := client.TapByResource(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	requests := []profiles.Request{}
+	for {
+		event, err := rsp.Recv()
+		if ctx.Err() != nil {
+			break
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// the requests to the resource are those its proxies receive
+		init := event.GetHttp().GetRequestInit()
+		if init == nil || event.GetProxyDirection() != pb.TapEvent_INBOUND {
+			continue
+		}
+		requests = append(requests, profiles.Request{Method: methodName(init.GetMethod()), Path: init.GetPath()})
+	}
+
+	profile, warnings := profiles.ProfileFromRequests(requests, options.namespace, service, options.tapRouteLimit)
+	for _, warning := range warnings {
+		fmt.Fprintf(stderr, "Warning: %s\n", warning)
+	}
+	return profiles.RenderProfile(profile, w)
+}
+
+// readProfileInput returns the content of the file at path, of the URL path,
+// or of stdin if path is "-".
+func readProfileInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return ioutil.ReadAll(stdin)
+	}
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		return ioutil.ReadFile(path)
+	}
+
+	client := http.Client{Timeout: profileFetchTimeout}
+	rsp, err := client.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch [%s]: %s", path, rsp.Status)
+	}
+	return ioutil.ReadAll(rsp.Body)
+}
+
+// validateProfile validates the ServiceProfile read by readProfileInput from
+// path, and reports it to w if it is valid.
+func validateProfile(path string, stdin io.Reader, w io.Writer) error {
+	data, err := readProfileInput(path, stdin)
+	if err != nil {
+		return err
+	}
+
+	if err := profiles.Validate(data); err != nil {
+		return fmt.Errorf("invalid ServiceProfile in [%s]: %s", path, err)
+	}
+	_, err = fmt.Fprintf(w, "ServiceProfile in [%s] is valid\n", path)
+	return err
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/api/public"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/profiles"
+)
+
+func TestProfileOptions(t *testing.T) {
+	testCases := []struct {
+		template bool
+		openAPI  string
+		validate string
+		args     []string
+		expected string
+	}{
+		{true, "", "", []string{"books"}, ""},
+		{false, "books.swagger", "", []string{"books"}, ""},
+		{false, "", "profile.yml", nil, ""},
+		{false, "", "", nil, "one of --template, --open-api, --tap or --validate is required"},
+		{true, "", "profile.yml", []string{"books"}, "--template and --validate cannot be used together"},
+		{true, "books.swagger", "", []string{"books"}, "--template and --open-api cannot be used together"},
+		{true, "", "", nil, "a service is required with --template"},
+		{false, "books.swagger", "", nil, "a service is required with --open-api"},
+		{false, "", "profile.yml", []string{"books"}, "--validate takes no service, but the file of a ServiceProfile"},
+	}
+
+	tapCases := []struct {
+		duration   time.Duration
+		routeLimit int
+		expected   string
+	}{
+		{10 * time.Second, 10, ""},
+		{0, 10, "--tap-duration must be positive, got 0s"},
+		{10 * time.Second, -1, "--tap-route-limit must be positive, got -1"},
+	}
+	for _, tc := range tapCases {
+		options := newProfileOptions()
+		options.tap = "deploy/books"
+		options.tapDuration = tc.duration
+		options.tapRouteLimit = tc.routeLimit
+
+		err := options.validateArgs([]string{"books"})
+		if tc.expected == "" && err != nil {
+			t.Fatalf("Unexpected error for %+v: %s", tc, err)
+		}
+		if tc.expected != "" && (err == nil || err.Error() != tc.expected) {
+			t.Fatalf("Expected error [%s] for %+v, got [%v]", tc.expected, tc, err)
+		}
+	}
+
+	for _, tc := range testCases {
+		options := newProfileOptions()
+		options.template = tc.template
+		options.openAPI = tc.openAPI
+		options.validate = tc.validate
+
+		err := options.validateArgs(tc.args)
+		if tc.expected == "" && err != nil {
+			t.Fatalf("Unexpected error for %+v: %s", tc, err)
+		}
+		if tc.expected != "" && (err == nil || err.Error() != tc.expected) {
+			t.Fatalf("Expected error [%s] for %+v, got [%v]", tc.expected, tc, err)
+		}
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	var buf bytes.Buffer
+	if err := profiles.RenderTemplate("default", "books", &buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	template := buf.String()
+
+	t.Run("Validates the ServiceProfile of stdin", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := validateProfile("-", strings.NewReader(template), &out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if out.String() != "ServiceProfile in [-] is valid\n" {
+			t.Fatalf("Unexpected output: %s", out.String())
+		}
+	})
+
+	t.Run("Reports the invalid route of a file", func(t *testing.T) {
+		tmpDir, err := ioutil.TempDir("", "linkerd-profile")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		path := filepath.Join(tmpDir, "books.yml")
+		invalid := strings.Replace(template, "name: 'POST /authors'", "name: '/authors/{id}'", 1)
+		if err := ioutil.WriteFile(path, []byte(invalid), 0644); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "invalid ServiceProfile in [" + path + "]: spec.routes[1]: duplicate route name [/authors/{id}], already the name of spec.routes[0]"
+		err = validateProfile(path, nil, ioutil.Discard)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestRenderOpenAPIProfile(t *testing.T) {
+	spec := `swagger: "2.0"
+basePath: /api
+paths:
+  /books/{id}:
+    get:
+      responses:
+        200:
+          description: A book
+  /authors:
+    $ref: authors.yaml
+`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/books.swagger" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(spec))
+	}))
+	defer server.Close()
+
+	expectedProfile := `apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.library.svc.cluster.local
+  namespace: library
+spec:
+  routes:
+  - condition:
+      method: GET
+      pathRegex: ^/api/books/[^/]+$
+    name: GET /books/{id}
+`
+	expectedWarnings := "Warning: skipped path [/authors]: path items with $ref are not supported\n"
+
+	t.Run("Generates a ServiceProfile from the document of a URL", func(t *testing.T) {
+		var out, stderr bytes.Buffer
+		if err := renderOpenAPIProfile(server.URL+"/books.swagger", "library", "books", nil, &out, &stderr); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if out.String() != expectedProfile {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedProfile, out.String())
+		}
+		if stderr.String() != expectedWarnings {
+			t.Fatalf("Wrong warnings:\n expected: \n%s\n, got: \n%s", expectedWarnings, stderr.String())
+		}
+	})
+
+	t.Run("Generates a ServiceProfile from the document of stdin", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := renderOpenAPIProfile("-", "library", "books", strings.NewReader(spec), &out, ioutil.Discard); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if out.String() != expectedProfile {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedProfile, out.String())
+		}
+	})
+
+	t.Run("Returns an error for URLs that cannot be fetched", func(t *testing.T) {
+		url := server.URL + "/authors.swagger"
+		expected := "failed to fetch [" + url + "]: 404 Not Found"
+		err := renderOpenAPIProfile(url, "library", "books", nil, ioutil.Discard, ioutil.Discard)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestRenderTapProfile(t *testing.T) {
+	// inboundEvents returns the events of a request to the books pod, as
+	// received by its proxy.
+	inboundEvents := func(stream uint64, method pb.HttpMethod_Registered, path string) []pb.TapEvent {
+		events := topEvents("web", "books", stream, method, path, 200, time.Millisecond)
+		for i := range events {
+			events[i].ProxyDirection = pb.TapEvent_INBOUND
+		}
+		return events
+	}
+
+	options := newProfileOptions()
+	options.tap = "deploy/books"
+	options.tapRouteLimit = 3
+
+	t.Run("Generates a ServiceProfile from the requests tapped", func(t *testing.T) {
+		var events []pb.TapEvent
+		events = append(events, inboundEvents(1, pb.HttpMethod_GET, "/books/1")...)
+		events = append(events, inboundEvents(3, pb.HttpMethod_GET, "/books/2?format=json")...)
+		events = append(events, inboundEvents(5, pb.HttpMethod_GET, "/books/3")...)
+		events = append(events, inboundEvents(7, pb.HttpMethod_POST, "/books")...)
+		events = append(events, inboundEvents(9, pb.HttpMethod_POST, "/books")...)
+		events = append(events, inboundEvents(11, pb.HttpMethod_GET, "/authors/7c9e6679-7425-40de-944b-e07fc1f90ae7")...)
+		events = append(events, inboundEvents(13, pb.HttpMethod_DELETE, "/books/4")...)
+		// the requests the books pod sends are not those of its routes
+		events = append(events, topEvents("books", "authors", 15, pb.HttpMethod_GET, "/authors/1", 200, time.Millisecond)...)
+
+		mockClient := &public.MockApiClient{
+			Api_TapByResourceClientToReturn: &public.MockApi_TapByResourceClient{TapEventsToReturn: events},
+		}
+
+		var out, stderr bytes.Buffer
+		if err := renderTapProfile(mockClient, options, "books", &out, &stderr); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		diffCompare(t, out.String(), readOptionalTestFile(t, "profile_tap.golden"))
+		if stderr.Len() != 0 {
+			t.Fatalf("Unexpected warnings: %s", stderr.String())
+		}
+	})
+
+	t.Run("Generates a skeleton ServiceProfile with a warning without requests", func(t *testing.T) {
+		mockClient := &public.MockApiClient{
+			Api_TapByResourceClientToReturn: &public.MockApi_TapByResourceClient{},
+		}
+
+		var out, stderr bytes.Buffer
+		if err := renderTapProfile(mockClient, options, "books", &out, &stderr); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.HasSuffix(out.String(), "spec:\n  routes: []\n") {
+			t.Fatalf("Expected a ServiceProfile without routes, got:\n%s", out.String())
+		}
+		expected := "Warning: no requests observed; the ServiceProfile of [books] has no routes\n"
+		if stderr.String() != expected {
+			t.Fatalf("Expected warning [%s], got [%s]", expected, stderr.String())
+		}
+	})
+
+	t.Run("Returns the errors of the tap", func(t *testing.T) {
+		mockClient := &public.MockApiClient{
+			Api_TapByResourceClientToReturn: &public.MockApi_TapByResourceClient{
+				ErrorsToReturn: []error{errors.New("tap failed")},
+			},
+		}
+
+		err := renderTapProfile(mockClient, options, "books", ioutil.Discard, ioutil.Discard)
+		if err == nil || err.Error() != "tap failed" {
+			t.Fatalf("Expected error [tap failed], got [%v]", err)
+		}
+	})
+}
@@ -1,32 +1,56 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"regexp"
 	"strings"
 	"time"
 
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/inject"
+	"github.com/linkerd/linkerd2/pkg/k8s"
 	"github.com/linkerd/linkerd2/pkg/version"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
-const defaultNamespace = "linkerd"
+const (
+	defaultNamespace = "linkerd"
+
+	// defaultWaitTimeout is how long failing checks are retried for when
+	// --wait is passed without a duration.
+	defaultWaitTimeout = 5 * time.Minute
+)
 
 var controlPlaneNamespace string
 var apiAddr string // An empty value means "use the Kubernetes configuration"
+var kubeAPIAddr string
+var kubeCAFile string
+var kubeInsecure bool
 var kubeconfigPath string
-var verbose bool
+var kubeContext string
+var impersonate string
+var impersonateGroups []string
+var kubeToken string
+var kubeTokenFile string
+var apiTimeout time.Duration
+var verbose int
 
 var (
 	// These regexs are not as strict as they could be, but are a quick and dirty
 	// sanity check against illegal characters.
-	alphaNumDash         = regexp.MustCompile("^[a-zA-Z0-9-]+$")
-	alphaNumDashDot      = regexp.MustCompile("^[\\.a-zA-Z0-9-]+$")
-	alphaNumDashDotSlash = regexp.MustCompile("^[\\./a-zA-Z0-9-]+$")
+	alphaNumDash    = regexp.MustCompile("^[a-zA-Z0-9-]+$")
+	alphaNumDashDot = regexp.MustCompile("^[\\.a-zA-Z0-9-]+$")
+
+	// registryFormat matches a registry host, with an optional port, followed
+	// by any number of repository path components.
+	registryFormat = regexp.MustCompile("^[a-zA-Z0-9][a-zA-Z0-9.-]*(:[0-9]+)?(/[a-z0-9._-]+)*$")
 )
 
 var RootCmd = &cobra.Command{
@@ -35,7 +59,7 @@ var RootCmd = &cobra.Command{
 	Long:  `linkerd manages the Linkerd service mesh.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// enable / disable logging
-		if verbose {
+		if verbose > 0 {
 			log.SetLevel(log.DebugLevel)
 		} else {
 			log.SetLevel(log.PanicLevel)
@@ -45,6 +69,17 @@ var RootCmd = &cobra.Command{
 			return fmt.Errorf("%s is not a valid namespace", controlPlaneNamespace)
 		}
 
+		if kubeToken != "" && kubeTokenFile != "" {
+			return fmt.Errorf("--token and --token-file are mutually exclusive")
+		}
+
+		if kubeInsecure {
+			if kubeCAFile != "" {
+				return fmt.Errorf("--certificate-authority and --insecure-skip-tls-verify are mutually exclusive")
+			}
+			fmt.Fprintln(os.Stderr, "WARNING: --insecure-skip-tls-verify is set, the Kubernetes API server's certificate will not be verified. This makes your connection insecure.")
+		}
+
 		return nil
 	},
 }
@@ -52,26 +87,45 @@ var RootCmd = &cobra.Command{
 func init() {
 	RootCmd.PersistentFlags().StringVarP(&controlPlaneNamespace, "linkerd-namespace", "l", defaultNamespace, "Namespace in which Linkerd is installed")
 	RootCmd.PersistentFlags().StringVar(&kubeconfigPath, "kubeconfig", "", "Path to the kubeconfig file to use for CLI requests")
+	RootCmd.PersistentFlags().StringVar(&kubeContext, "context", "", "Name of the kubeconfig context to use")
+	RootCmd.PersistentFlags().StringVar(&kubeAPIAddr, "kube-api-addr", "", "Override the Kubernetes API server address from the kubeconfig (e.g. https://10.0.0.1:6443)")
+	RootCmd.PersistentFlags().StringVar(&kubeCAFile, "certificate-authority", "", "Path to a CA bundle used to verify the Kubernetes API server's certificate")
+	RootCmd.PersistentFlags().BoolVar(&kubeInsecure, "insecure-skip-tls-verify", false, "Do not verify the Kubernetes API server's certificate (insecure)")
+	RootCmd.PersistentFlags().StringVar(&impersonate, "as", "", "Username to impersonate for Kubernetes operations")
+	RootCmd.PersistentFlags().StringArrayVar(&impersonateGroups, "as-group", []string{}, "Group to impersonate for Kubernetes operations, this flag can be repeated to specify multiple groups")
+	RootCmd.PersistentFlags().StringVar(&kubeToken, "token", "", "Bearer token for authentication to the Kubernetes API server")
+	RootCmd.PersistentFlags().StringVar(&kubeTokenFile, "token-file", "", "Path to a file containing a bearer token for authentication to the Kubernetes API server")
 	RootCmd.PersistentFlags().StringVar(&apiAddr, "api-addr", "", "Override kubeconfig and communicate directly with the control plane at host:port (mostly for testing)")
-	RootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Turn on debug logging")
+	RootCmd.PersistentFlags().DurationVar(&apiTimeout, "api-timeout", k8s.DefaultRequestTimeout, "Timeout for each request to the Kubernetes API")
+	RootCmd.PersistentFlags().CountVar(&verbose, "verbose", "Turn on debug logging, including the method, URL, status and duration of each Kubernetes API request; pass --verbose=2, or --verbose twice, to also log request and response headers and bodies, with credentials redacted")
 
 	RootCmd.AddCommand(newCmdCheck())
+	RootCmd.AddCommand(newCmdComplete())
 	RootCmd.AddCommand(newCmdCompletion())
 	RootCmd.AddCommand(newCmdDashboard())
+	RootCmd.AddCommand(newCmdEndpoints())
 	RootCmd.AddCommand(newCmdGet())
 	RootCmd.AddCommand(newCmdInject())
 	RootCmd.AddCommand(newCmdInstall())
+	RootCmd.AddCommand(newCmdLogs())
+	RootCmd.AddCommand(newCmdMetrics())
+	RootCmd.AddCommand(newCmdProfile())
+	RootCmd.AddCommand(newCmdRoutes())
 	RootCmd.AddCommand(newCmdStat())
 	RootCmd.AddCommand(newCmdTap())
 	RootCmd.AddCommand(newCmdTop())
+	RootCmd.AddCommand(newCmdUninject())
+	RootCmd.AddCommand(newCmdUninstall())
+	RootCmd.AddCommand(newCmdUpgrade())
 	RootCmd.AddCommand(newCmdVersion())
 }
 
 // validatedPublicAPIClient builds a new public API client and executes status
-// checks to determine if the client can successfully connect to the API. If the
-// checks fail, then CLI will print an error and exit. If the shouldRetry param
-// is specified, then the CLI will print a message to stderr and retry.
-func validatedPublicAPIClient(shouldRetry bool) pb.ApiClient {
+// checks to determine if the client can successfully connect to the API. If a
+// fatal check fails, then CLI will print an error and exit; warnings are
+// ignored. If retryDeadline is not zero, then the CLI will print a message to
+// stderr and retry until then.
+func validatedPublicAPIClient(retryDeadline time.Time) pb.ApiClient {
 	checks := []healthcheck.Checks{
 		healthcheck.KubernetesAPIChecks,
 		healthcheck.LinkerdAPIChecks,
@@ -80,8 +134,9 @@ func validatedPublicAPIClient(shouldRetry bool) pb.ApiClient {
 	hc := healthcheck.NewHealthChecker(checks, &healthcheck.HealthCheckOptions{
 		ControlPlaneNamespace: controlPlaneNamespace,
 		KubeConfig:            kubeconfigPath,
+		KubeAPIOptions:        kubeAPIOptions(),
 		APIAddr:               apiAddr,
-		ShouldRetry:           shouldRetry,
+		RetryDeadline:         retryDeadline,
 	})
 
 	exitOnError := func(result *healthcheck.CheckResult) {
@@ -90,15 +145,8 @@ func validatedPublicAPIClient(shouldRetry bool) pb.ApiClient {
 			return
 		}
 
-		if result.Err != nil {
-			var msg string
-			switch result.Category {
-			case healthcheck.KubernetesAPICategory:
-				msg = "Cannot connect to Kubernetes"
-			case healthcheck.LinkerdAPICategory:
-				msg = "Cannot connect to Linkerd"
-			}
-			fmt.Fprintf(os.Stderr, "%s: %s\n", msg, result.Err)
+		if msg := publicAPICheckFailure(result); msg != "" {
+			fmt.Fprintln(os.Stderr, msg)
 
 			checkCmd := "linkerd check"
 			if controlPlaneNamespace != defaultNamespace {
@@ -110,10 +158,102 @@ func validatedPublicAPIClient(shouldRetry bool) pb.ApiClient {
 		}
 	}
 
-	hc.RunChecks(exitOnError)
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	hc.RunChecks(ctx, exitOnError)
 	return hc.PublicAPIClient()
 }
 
+// publicAPICheckFailure returns the error reported by validatedPublicAPIClient
+// if result prevents using the public API, or an empty string otherwise.
+// Warnings, and failures of checks that are not fatal, do not prevent it.
+func publicAPICheckFailure(result *healthcheck.CheckResult) string {
+	if result.Err == nil || result.Warning || !result.Fatal {
+		return ""
+	}
+
+	var msg string
+	switch category := result.Category; {
+	case category == healthcheck.KubernetesAPICategory:
+		msg = "Cannot connect to Kubernetes"
+	case category == healthcheck.KubernetesVersionCategory:
+		msg = "Unsupported Kubernetes version"
+	case category == healthcheck.LinkerdAPICategory:
+		msg = "Cannot connect to Linkerd"
+	case strings.HasPrefix(string(category), string(healthcheck.LinkerdAPICategory)+"["):
+		msg = "Linkerd control plane is unhealthy"
+	default:
+		msg = "Cannot validate the Linkerd install"
+	}
+	return fmt.Sprintf("%s: %s", msg, result.Err)
+}
+
+// namespacesWithPodAccess returns the namespaces in which you are allowed to
+// list pods, for the commands that read all namespaces on your behalf, or nil
+// if you are allowed to in all of them. The namespaces you are not allowed to
+// list pods in are reported on stderr, so that they can be skipped.
+func namespacesWithPodAccess() ([]string, error) {
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeAPIOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	allowed, denied, err := kubeAPI.CanIInNamespaces(ctx, "list", "", "pods")
+	if err != nil {
+		return nil, err
+	}
+	if allowed != nil && len(allowed) == 0 {
+		return nil, fmt.Errorf("you are not allowed to list pods in any namespace")
+	}
+	if len(denied) > 0 {
+		fmt.Fprintf(os.Stderr, "Skipping the namespaces you are not allowed to list pods in: %s\n", strings.Join(denied, ", "))
+	}
+	return allowed, nil
+}
+
+// kubeAPIOptions returns the options used to construct a KubernetesAPI, as
+// configured by the global CLI flags.
+func kubeAPIOptions() *k8s.APIOptions {
+	return &k8s.APIOptions{
+		KubeContext:       kubeContext,
+		APIServerAddr:     kubeAPIAddr,
+		CAFile:            kubeCAFile,
+		Insecure:          kubeInsecure,
+		Impersonate:       impersonate,
+		ImpersonateGroups: impersonateGroups,
+		Token:             kubeToken,
+		TokenFile:         kubeTokenFile,
+		Timeout:           apiTimeout,
+		LogBodies:         verbose > 1,
+	}
+}
+
+// newSignalContext returns a context that is cancelled when the CLI receives
+// an interrupt signal, so that requests to an unresponsive API server do not
+// leave the process waiting. Once the first interrupt has been handled, the
+// default signal behavior is restored.
+func newSignalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	go func() {
+		defer signal.Stop(signals)
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
 type proxyConfigOptions struct {
 	linkerdVersion        string
 	proxyImage            string
@@ -127,6 +267,10 @@ type proxyConfigOptions struct {
 	proxyControlPort      uint
 	proxyMetricsPort      uint
 	proxyOutboundCapacity map[string]uint
+	proxyCPURequest       string
+	proxyMemoryRequest    string
+	proxyCPULimit         string
+	proxyMemoryLimit      string
 	tls                   string
 }
 
@@ -157,8 +301,8 @@ func (options *proxyConfigOptions) validate() error {
 	if !alphaNumDashDot.MatchString(options.linkerdVersion) {
 		return fmt.Errorf("%s is not a valid version", options.linkerdVersion)
 	}
-	if !alphaNumDashDotSlash.MatchString(options.dockerRegistry) {
-		return fmt.Errorf("%s is not a valid Docker registry", options.dockerRegistry)
+	if err := validateRegistry(options.dockerRegistry); err != nil {
+		return err
 	}
 	if options.imagePullPolicy != "Always" && options.imagePullPolicy != "IfNotPresent" && options.imagePullPolicy != "Never" {
 		return fmt.Errorf("--image-pull-policy must be one of: Always, IfNotPresent, Never")
@@ -169,9 +313,103 @@ func (options *proxyConfigOptions) validate() error {
 	if options.tls != "" && options.tls != optionalTLS {
 		return fmt.Errorf("--tls must be blank or set to \"%s\"", optionalTLS)
 	}
+	if err := validateResources("cpu", options.proxyCPURequest, options.proxyCPULimit); err != nil {
+		return err
+	}
+	return validateResources("memory", options.proxyMemoryRequest, options.proxyMemoryLimit)
+}
+
+// validateResources checks that the request and limit of the proxy for the
+// given resource, either of which may be empty, are quantities, and that the
+// request does not exceed the limit.
+func validateResources(name, request, limit string) error {
+	requestQuantity, err := parseQuantity(name, "request", request)
+	if err != nil {
+		return err
+	}
+	limitQuantity, err := parseQuantity(name, "limit", limit)
+	if err != nil {
+		return err
+	}
+	if requestQuantity != nil && limitQuantity != nil && requestQuantity.Cmp(*limitQuantity) > 0 {
+		return fmt.Errorf("--proxy-%s-request [%s] must not be greater than --proxy-%s-limit [%s]", name, request, name, limit)
+	}
 	return nil
 }
 
+// parseQuantity parses the value of the --proxy-<name>-<kind> flag, returning
+// nil if it is empty.
+func parseQuantity(name, kind, value string) (*resource.Quantity, error) {
+	if value == "" {
+		return nil, nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid quantity '%s' for --proxy-%s-%s flag", value, name, kind)
+	}
+	return &quantity, nil
+}
+
+// proxyResources returns the resource requirements of the proxy container,
+// leaving out the requests and limits that were not set. As Kubernetes does,
+// a request that is not set defaults to the limit, if that is set, and a limit
+// that is not set is left out, so that the proxy can use more than it
+// requests.
+func (options *proxyConfigOptions) proxyResources() v1.ResourceRequirements {
+	cpuRequest := options.proxyCPURequest
+	if cpuRequest == "" {
+		cpuRequest = options.proxyCPULimit
+	}
+	memoryRequest := options.proxyMemoryRequest
+	if memoryRequest == "" {
+		memoryRequest = options.proxyMemoryLimit
+	}
+
+	resources := v1.ResourceRequirements{}
+	for _, r := range []struct {
+		list  *v1.ResourceList
+		name  v1.ResourceName
+		value string
+	}{
+		{&resources.Requests, v1.ResourceCPU, cpuRequest},
+		{&resources.Requests, v1.ResourceMemory, memoryRequest},
+		{&resources.Limits, v1.ResourceCPU, options.proxyCPULimit},
+		{&resources.Limits, v1.ResourceMemory, options.proxyMemoryLimit},
+	} {
+		if r.value == "" {
+			continue
+		}
+		if *r.list == nil {
+			*r.list = v1.ResourceList{}
+		}
+		(*r.list)[r.name] = resource.MustParse(r.value)
+	}
+	return resources
+}
+
+// validateRegistry checks that registry is a registry host and path images can
+// be pulled from, e.g. registry.example.com:5000/linkerd, without the tag or
+// digest of an image, which are set by --linkerd-version.
+func validateRegistry(registry string) error {
+	if strings.Contains(registry, "@") {
+		return fmt.Errorf("--registry must not include a digest, was [%s]", registry)
+	}
+	components := strings.Split(registry, "/")
+	if last := components[len(components)-1]; len(components) > 1 && strings.Contains(last, ":") {
+		return fmt.Errorf("--registry must not include a tag, was [%s]; use --linkerd-version to choose the version of the images", registry)
+	}
+	if !registryFormat.MatchString(registry) {
+		return fmt.Errorf("%s is not a valid Docker registry", registry)
+	}
+	return nil
+}
+
+// registryImage returns the image with the given name and tag in the
+// configured registry.
+func (options *proxyConfigOptions) registryImage(name, tag string) string {
+	return fmt.Sprintf("%s/%s:%s", options.dockerRegistry, name, tag)
+}
+
 func (options *proxyConfigOptions) enableTLS() bool {
 	return options.tls == optionalTLS
 }
@@ -186,11 +424,35 @@ func (options *proxyConfigOptions) taggedProxyInitImage() string {
 	return fmt.Sprintf("%s:%s", image, options.linkerdVersion)
 }
 
+// proxyConfig returns the configuration of the proxy injected with these
+// options, listening on the given inbound and outbound ports.
+func (options *proxyConfigOptions) proxyConfig(inboundPort, outboundPort uint) *inject.Config {
+	return &inject.Config{
+		ControlPlaneNamespace: controlPlaneNamespace,
+		Version:               options.linkerdVersion,
+		CreatedBy:             k8s.CreatedByAnnotationValue(),
+		ProxyImage:            options.taggedProxyImage(),
+		InitImage:             options.taggedProxyInitImage(),
+		ImagePullPolicy:       options.imagePullPolicy,
+		ProxyUID:              options.proxyUID,
+		LogLevel:              options.proxyLogLevel,
+		BindTimeout:           options.proxyBindTimeout,
+		APIPort:               options.proxyAPIPort,
+		ControlPort:           options.proxyControlPort,
+		MetricsPort:           options.proxyMetricsPort,
+		InboundPort:           inboundPort,
+		OutboundPort:          outboundPort,
+		Resources:             options.proxyResources(),
+		EnableTLS:             options.enableTLS(),
+		OutboundCapacity:      options.proxyOutboundCapacity,
+	}
+}
+
 func addProxyConfigFlags(cmd *cobra.Command, options *proxyConfigOptions) {
 	cmd.PersistentFlags().StringVarP(&options.linkerdVersion, "linkerd-version", "v", options.linkerdVersion, "Tag to be used for Linkerd images")
 	cmd.PersistentFlags().StringVar(&options.initImage, "init-image", options.initImage, "Linkerd init container image name")
 	cmd.PersistentFlags().StringVar(&options.proxyImage, "proxy-image", options.proxyImage, "Linkerd proxy container image name")
-	cmd.PersistentFlags().StringVar(&options.dockerRegistry, "registry", options.dockerRegistry, "Docker registry to pull images from")
+	cmd.PersistentFlags().StringVar(&options.dockerRegistry, "registry", options.dockerRegistry, "Docker registry to pull the control plane and proxy images from, e.g. registry.example.com:5000/linkerd-io; image names and tags are kept")
 	cmd.PersistentFlags().StringVar(&options.imagePullPolicy, "image-pull-policy", options.imagePullPolicy, "Docker image pull policy")
 	cmd.PersistentFlags().Int64Var(&options.proxyUID, "proxy-uid", options.proxyUID, "Run the proxy under this user ID")
 	cmd.PersistentFlags().StringVar(&options.proxyLogLevel, "proxy-log-level", options.proxyLogLevel, "Log level for the proxy")
@@ -198,5 +460,9 @@ func addProxyConfigFlags(cmd *cobra.Command, options *proxyConfigOptions) {
 	cmd.PersistentFlags().UintVar(&options.proxyAPIPort, "api-port", options.proxyAPIPort, "Port where the Linkerd controller is running")
 	cmd.PersistentFlags().UintVar(&options.proxyControlPort, "control-port", options.proxyControlPort, "Proxy port to use for control")
 	cmd.PersistentFlags().UintVar(&options.proxyMetricsPort, "metrics-port", options.proxyMetricsPort, "Proxy port to serve metrics on")
+	cmd.PersistentFlags().StringVar(&options.proxyCPURequest, "proxy-cpu-request", options.proxyCPURequest, "Amount of CPU units that the proxy sidecar requests, e.g. 100m")
+	cmd.PersistentFlags().StringVar(&options.proxyMemoryRequest, "proxy-memory-request", options.proxyMemoryRequest, "Amount of memory that the proxy sidecar requests, e.g. 64Mi")
+	cmd.PersistentFlags().StringVar(&options.proxyCPULimit, "proxy-cpu-limit", options.proxyCPULimit, "Maximum amount of CPU units that the proxy sidecar can use; also the request, if --proxy-cpu-request is not set")
+	cmd.PersistentFlags().StringVar(&options.proxyMemoryLimit, "proxy-memory-limit", options.proxyMemoryLimit, "Maximum amount of memory that the proxy sidecar can use; also the request, if --proxy-memory-request is not set")
 	cmd.PersistentFlags().StringVar(&options.tls, "tls", options.tls, "Enable TLS; valid settings: \"optional\"")
 }
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/healthcheck"
+)
+
+func TestPublicAPICheckFailure(t *testing.T) {
+	err := errors.New("connection refused")
+
+	testCases := []struct {
+		name     string
+		result   *healthcheck.CheckResult
+		expected string
+	}{
+		{
+			name:   "passing check",
+			result: &healthcheck.CheckResult{Category: healthcheck.LinkerdAPICategory, Fatal: true},
+		},
+		{
+			name:   "warning",
+			result: &healthcheck.CheckResult{Category: healthcheck.LinkerdAPICategory, Fatal: true, Warning: true, Err: err},
+		},
+		{
+			name:   "non-fatal failure",
+			result: &healthcheck.CheckResult{Category: healthcheck.KubernetesAPICategory, Err: err},
+		},
+		{
+			name:     "Kubernetes API failure",
+			result:   &healthcheck.CheckResult{Category: healthcheck.KubernetesAPICategory, Fatal: true, Err: err},
+			expected: "Cannot connect to Kubernetes: connection refused",
+		},
+		{
+			name:     "Kubernetes version failure",
+			result:   &healthcheck.CheckResult{Category: healthcheck.KubernetesVersionCategory, Fatal: true, Err: err},
+			expected: "Unsupported Kubernetes version: connection refused",
+		},
+		{
+			name:     "Linkerd API failure",
+			result:   &healthcheck.CheckResult{Category: healthcheck.LinkerdAPICategory, Fatal: true, Err: err},
+			expected: "Cannot connect to Linkerd: connection refused",
+		},
+		{
+			name:     "control plane subsystem failure",
+			result:   &healthcheck.CheckResult{Category: "linkerd-api[prometheus]", Fatal: true, Err: err},
+			expected: "Linkerd control plane is unhealthy: connection refused",
+		},
+		{
+			name:     "other category",
+			result:   &healthcheck.CheckResult{Category: healthcheck.LinkerdControlPlaneCategory, Fatal: true, Err: err},
+			expected: "Cannot validate the Linkerd install: connection refused",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.name, func(t *testing.T) {
+			if msg := publicAPICheckFailure(tc.result); msg != tc.expected {
+				t.Fatalf("Expected [%s], got [%s]", tc.expected, msg)
+			}
+		})
+	}
+}
+
+func TestVerboseLevels(t *testing.T) {
+	defer func() { verbose = 0 }()
+
+	testCases := []struct {
+		args      []string
+		verbose   int
+		logBodies bool
+	}{
+		{[]string{}, 0, false},
+		{[]string{"--verbose"}, 1, false},
+		{[]string{"--verbose", "--verbose"}, 2, true},
+		{[]string{"--verbose=2"}, 2, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			verbose = 0
+			if err := RootCmd.PersistentFlags().Parse(tc.args); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if verbose != tc.verbose {
+				t.Fatalf("Expected verbose level %d, got %d", tc.verbose, verbose)
+			}
+			if logBodies := kubeAPIOptions().LogBodies; logBodies != tc.logBodies {
+				t.Fatalf("Expected LogBodies to be %t, got %t", tc.logBodies, logBodies)
+			}
+		})
+	}
+}
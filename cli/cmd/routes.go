@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/linkerd/linkerd2/controller/api/util"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/spf13/cobra"
+)
+
+type routesOptions struct {
+	namespace   string
+	timeWindow  string
+	toNamespace string
+	toResource  string
+	output      string
+}
+
+func newRoutesOptions() *routesOptions {
+	return &routesOptions{
+		namespace:   "default",
+		timeWindow:  "1m",
+		toNamespace: "",
+		toResource:  "",
+		output:      tableOutput,
+	}
+}
+
+func newCmdRoutes() *cobra.Command {
+	options := newRoutesOptions()
+
+	cmd := &cobra.Command{
+		Use:   "routes [flags] (RESOURCE)",
+		Short: "Display route stats about a service",
+		Long: `Display route stats about a service.
+
+  The stats of the requests to a service are displayed by the routes of its
+  ServiceProfile, one row per route, followed by a [DEFAULT] row for the
+  requests that matched none of them. Services without a ServiceProfile have
+  no routes; create one with "linkerd profile".
+
+  The RESOURCE argument specifies the service whose inbound requests are
+  displayed: (svc NAME | svc/NAME). With --to, it specifies the resource whose
+  outbound requests to the service given by --to are displayed instead:
+  (TYPE [NAME] | TYPE/NAME).
+
+With -o json, the stats are displayed as an array of objects, one per route,
+with these fields:
+
+  * route: the name of the route, or [DEFAULT]
+  * successRate: the fraction of requests that succeeded, between 0 and 1
+  * rps: the number of requests per second
+  * latencyMsP50, latencyMsP95, latencyMsP99: the percentiles of the latency of requests, in milliseconds
+
+The fields about requests are null for routes that received no traffic.`,
+		Example: `  # Get the routes of the webapp service in the test namespace.
+  linkerd routes svc/webapp -n test
+
+  # Get the routes of the books service requested by the web deployment.
+  linkerd routes deploy/web --to svc/books`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req, err := buildTopRoutesRequest(args, options)
+			if err != nil {
+				return fmt.Errorf("error creating metrics request while making routes request: %v", err)
+			}
+
+			output, err := requestRouteStatsFromAPI(validatedPublicAPIClient(time.Time{}), req, options)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Print(output)
+
+			return err
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&options.namespace, "namespace", "n", options.namespace, "Namespace of the specified resource")
+	cmd.PersistentFlags().StringVarP(&options.timeWindow, "time-window", "t", options.timeWindow, "Stat window (for example: \"10s\", \"1m\", \"10m\", \"1h\")")
+	cmd.PersistentFlags().StringVar(&options.toResource, "to", options.toResource, "If present, displays the outbound requests of the resource to this service")
+	cmd.PersistentFlags().StringVar(&options.toNamespace, "to-namespace", options.toNamespace, "Sets the namespace used to lookup the \"--to\" resource; by default the current \"--namespace\" is used")
+	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, json")
+
+	return cmd
+}
+
+func buildTopRoutesRequest(resource []string, options *routesOptions) (*pb.TopRoutesRequest, error) {
+	if options.output != tableOutput && options.output != jsonOutput {
+		return nil, fmt.Errorf("output format \"%s\" not recognized, must be one of: %s, %s", options.output, tableOutput, jsonOutput)
+	}
+
+	target, err := util.BuildResource(options.namespace, resource...)
+	if err != nil {
+		return nil, err
+	}
+
+	var toRes pb.Resource
+	if options.toResource != "" {
+		toRes, err = util.BuildResource(options.toNamespace, options.toResource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return util.BuildTopRoutesRequest(util.TopRoutesRequestParams{
+		TimeWindow:   options.timeWindow,
+		Namespace:    options.namespace,
+		ResourceType: target.Type,
+		ResourceName: target.Name,
+		ToNamespace:  toRes.Namespace,
+		ToType:       toRes.Type,
+		ToName:       toRes.Name,
+	})
+}
+
+func requestRouteStatsFromAPI(client pb.ApiClient, req *pb.TopRoutesRequest, options *routesOptions) (string, error) {
+	resp, err := client.TopRoutes(context.Background(), req)
+	if err != nil {
+		return "", fmt.Errorf("TopRoutes API error: %v", err)
+	}
+	// the error of a service without a ServiceProfile is meant for users
+	if e := resp.GetError(); e != nil {
+		return "", errors.New(e.Error)
+	}
+
+	if options.output == jsonOutput {
+		return renderRouteStatsJSON(resp)
+	}
+	return renderRouteStats(resp), nil
+}
+
+// routeRowStats returns the stats of a route as those of a row of stat, whose
+// rates are computed the same way.
+func routeRowStats(r *pb.RouteTable_Row) pb.StatTable_PodGroup_Row {
+	return pb.StatTable_PodGroup_Row{Stats: r.Stats, TimeWindow: r.TimeWindow}
+}
+
+func renderRouteStats(resp *pb.TopRoutesResponse) string {
+	var buffer bytes.Buffer
+	w := tabwriter.NewWriter(&buffer, 0, 0, padding, ' ', tabwriter.AlignRight)
+
+	routeHeader := "ROUTE"
+	maxRouteLength := len(routeHeader)
+	for _, r := range resp.GetOk().GetRows() {
+		if len(r.Route) > maxRouteLength {
+			maxRouteLength = len(r.Route)
+		}
+	}
+
+	headers := []string{
+		routeHeader + strings.Repeat(" ", maxRouteLength-len(routeHeader)),
+		"SUCCESS",
+		"RPS",
+		"LATENCY_P50",
+		"LATENCY_P95",
+		"LATENCY_P99",
+	}
+	// trailing \t is required to format last column
+	fmt.Fprintln(w, strings.Join(headers, "\t")+"\t")
+
+	for _, r := range resp.GetOk().GetRows() {
+		route := r.Route + strings.Repeat(" ", maxRouteLength-len(r.Route))
+		if r.Stats == nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t-\t\n", route)
+			continue
+		}
+		stats := routeRowStats(r)
+		fmt.Fprintf(w, "%s\t%.2f%%\t%.1frps\t%dms\t%dms\t%dms\t\n",
+			route,
+			getSuccessRate(stats)*100,
+			getRequestRate(stats),
+			r.Stats.LatencyMsP50,
+			r.Stats.LatencyMsP95,
+			r.Stats.LatencyMsP99,
+		)
+	}
+	w.Flush()
+
+	// strip left padding on the first column
+	out := string(buffer.Bytes()[padding:])
+	out = strings.Replace(out, "\n"+strings.Repeat(" ", padding), "\n", -1)
+
+	return out
+}
+
+// jsonRouteStats is a row of the JSON output of routes. Its fields are
+// documented in the help of the command, and, as those of stat, must not be
+// renamed.
+type jsonRouteStats struct {
+	Route        string   `json:"route"`
+	SuccessRate  *float64 `json:"successRate"`
+	RequestRate  *float64 `json:"rps"`
+	LatencyMsP50 *uint64  `json:"latencyMsP50"`
+	LatencyMsP95 *uint64  `json:"latencyMsP95"`
+	LatencyMsP99 *uint64  `json:"latencyMsP99"`
+}
+
+// renderRouteStatsJSON returns the rows of resp as a JSON array, in the order
+// they are returned in.
+func renderRouteStatsJSON(resp *pb.TopRoutesResponse) (string, error) {
+	rows := []*jsonRouteStats{}
+	for _, r := range resp.GetOk().GetRows() {
+		jsonRow := &jsonRouteStats{Route: r.Route}
+		if r.Stats != nil {
+			stats := routeRowStats(r)
+			successRate, requestRate := getSuccessRate(stats), getRequestRate(stats)
+			jsonRow.SuccessRate = &successRate
+			jsonRow.RequestRate = &requestRate
+			jsonRow.LatencyMsP50 = &r.Stats.LatencyMsP50
+			jsonRow.LatencyMsP95 = &r.Stats.LatencyMsP95
+			jsonRow.LatencyMsP99 = &r.Stats.LatencyMsP99
+		}
+		rows = append(rows, jsonRow)
+	}
+
+	out, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/api/public"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+func routesResponse() *pb.TopRoutesResponse {
+	return &pb.TopRoutesResponse{
+		Response: &pb.TopRoutesResponse_Ok{
+			Ok: &pb.RouteTable{
+				Rows: []*pb.RouteTable_Row{
+					{Route: "GET /books/{id}", TimeWindow: "1m"},
+					{
+						Route:      "POST /books",
+						TimeWindow: "1m",
+						Stats: &pb.BasicStats{
+							SuccessCount: 90,
+							FailureCount: 30,
+							LatencyMsP50: 12,
+							LatencyMsP95: 45,
+							LatencyMsP99: 87,
+						},
+					},
+					{
+						Route:      "[DEFAULT]",
+						TimeWindow: "1m",
+						Stats:      &pb.BasicStats{SuccessCount: 60, LatencyMsP50: 5, LatencyMsP95: 9, LatencyMsP99: 10},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	t.Run("Returns the stats of a service by route", func(t *testing.T) {
+		mockClient := &public.MockApiClient{TopRoutesResponseToReturn: routesResponse()}
+
+		expectedOutput := `ROUTE             SUCCESS      RPS   LATENCY_P50   LATENCY_P95   LATENCY_P99
+GET /books/{id}         -        -             -             -             -
+POST /books        75.00%   2.0rps          12ms          45ms          87ms
+[DEFAULT]         100.00%   1.0rps           5ms           9ms          10ms
+`
+
+		options := newRoutesOptions()
+		req, err := buildTopRoutesRequest([]string{"svc/books"}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output, err := requestRouteStatsFromAPI(mockClient, req, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if output != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, output)
+		}
+	})
+
+	t.Run("Returns the stats of a service by route in JSON", func(t *testing.T) {
+		mockClient := &public.MockApiClient{TopRoutesResponseToReturn: routesResponse()}
+
+		expectedOutput := `[
+  {
+    "route": "GET /books/{id}",
+    "successRate": null,
+    "rps": null,
+    "latencyMsP50": null,
+    "latencyMsP95": null,
+    "latencyMsP99": null
+  },
+  {
+    "route": "POST /books",
+    "successRate": 0.75,
+    "rps": 2,
+    "latencyMsP50": 12,
+    "latencyMsP95": 45,
+    "latencyMsP99": 87
+  },
+  {
+    "route": "[DEFAULT]",
+    "successRate": 1,
+    "rps": 1,
+    "latencyMsP50": 5,
+    "latencyMsP95": 9,
+    "latencyMsP99": 10
+  }
+]
+`
+
+		options := newRoutesOptions()
+		options.output = jsonOutput
+		req, err := buildTopRoutesRequest([]string{"svc/books"}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output, err := requestRouteStatsFromAPI(mockClient, req, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if output != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, output)
+		}
+	})
+
+	t.Run("Returns the error of the response, such as that of a service without a ServiceProfile", func(t *testing.T) {
+		expected := "no ServiceProfile found for service [authors] in namespace [default]; create one with `linkerd profile` to see its routes"
+		mockClient := &public.MockApiClient{
+			TopRoutesResponseToReturn: &pb.TopRoutesResponse{
+				Response: &pb.TopRoutesResponse_Error{
+					Error: &pb.ResourceError{Error: expected},
+				},
+			},
+		}
+
+		options := newRoutesOptions()
+		req, err := buildTopRoutesRequest([]string{"svc/authors"}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, err = requestRouteStatsFromAPI(mockClient, req, options)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got %v", expected, err)
+		}
+	})
+
+	t.Run("Builds requests to the service given by --to", func(t *testing.T) {
+		options := newRoutesOptions()
+		options.toResource = "svc/books"
+		options.toNamespace = "books"
+		req, err := buildTopRoutesRequest([]string{"deploy/web"}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if res := req.GetSelector().GetResource(); res.Type != k8s.Deployment || res.Name != "web" || res.Namespace != "default" {
+			t.Fatalf("Expected the selector to be deploy/web in default, got %v", res)
+		}
+		if to := req.GetToResource(); to.Type != k8s.Service || to.Name != "books" || to.Namespace != "books" {
+			t.Fatalf("Expected the destination to be svc/books in books, got %v", to)
+		}
+	})
+
+	t.Run("Rejects invalid output formats", func(t *testing.T) {
+		options := newRoutesOptions()
+		options.output = "wide"
+		expected := "output format \"wide\" not recognized, must be one of: table, json"
+
+		_, err := buildTopRoutesRequest([]string{"svc/books"}, options)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got %v", expected, err)
+		}
+	})
+}
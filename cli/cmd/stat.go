@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
@@ -10,6 +11,7 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/linkerd/linkerd2/controller/api/util"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
@@ -25,6 +27,7 @@ type statOptions struct {
 	fromNamespace string
 	fromResource  string
 	allNamespaces bool
+	output        string
 }
 
 func newStatOptions() *statOptions {
@@ -36,6 +39,7 @@ func newStatOptions() *statOptions {
 		fromNamespace: "",
 		fromResource:  "",
 		allNamespaces: false,
+		output:        tableOutput,
 	}
 }
 
@@ -70,7 +74,22 @@ Valid resource types include:
   * all (all resource types, not supported in --from or --to)
 
 This command will hide resources that have completed, such as pods that are in the Succeeded or Failed phases.
-If no resource name is specified, displays stats about all resources of the specified RESOURCETYPE`,
+With --to or --from, only the traffic to or from the given resource is displayed, and the NAME header names that resource.
+If no resource name is specified, displays stats about all resources of the specified RESOURCETYPE.
+With --all-namespaces, the resources of all the namespaces you are allowed to list pods in are displayed, sorted by namespace and name, in a NAMESPACE column; the namespaces you are not allowed to list pods in are named on stderr.
+
+With -o wide, the TCP connections open, and the bytes read and written per second, are also displayed.
+
+With -o json, the stats are displayed as an array of objects, one per resource, with these fields:
+
+  * namespace, kind, name: the resource
+  * meshedPods, runningPods: the number of pods of the resource that are meshed, and running (null for authorities)
+  * successRate: the fraction of requests that succeeded, between 0 and 1
+  * rps: the number of requests per second
+  * latencyMsP50, latencyMsP95, latencyMsP99: the percentiles of the latency of requests, in milliseconds
+  * tlsRate: the fraction of requests sent over TLS, between 0 and 1
+
+The fields about requests are null for resources that received no traffic.`,
 		Example: `  # Get all deployments in the test namespace.
   linkerd stat deployments -n test
 
@@ -80,6 +99,9 @@ If no resource name is specified, displays stats about all resources of the spec
   # Get all namespaces.
   linkerd stat namespaces
 
+  # Get all deployments in all namespaces.
+  linkerd stat deploy -A
+
   # Get all inbound stats to the web deployment.
   linkerd stat deploy/web
 
@@ -100,12 +122,31 @@ If no resource name is specified, displays stats about all resources of the spec
 		Args:      cobra.RangeArgs(1, 2),
 		ValidArgs: util.ValidTargets,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.allNamespaces && cmd.Flags().Changed("namespace") {
+				return fmt.Errorf("--all-namespaces and --namespace flags are mutually exclusive")
+			}
+
 			req, err := buildStatSummaryRequest(args, options)
 			if err != nil {
 				return fmt.Errorf("error creating metrics request while making stats request: %v", err)
 			}
 
-			output, err := requestStatsFromAPI(validatedPublicAPIClient(false), req, options)
+			// namespaces are not themselves in a namespace
+			var namespaces []string
+			if options.allNamespaces && req.Selector.Resource.Type != k8s.Namespace {
+				namespaces, err = namespacesWithPodAccess()
+				if err != nil {
+					return err
+				}
+			}
+
+			client := validatedPublicAPIClient(time.Time{})
+			var output string
+			if namespaces == nil {
+				output, err = requestStatsFromAPI(client, req, options)
+			} else {
+				output, err = requestStatsInNamespacesFromAPI(client, req, options, namespaces)
+			}
 			if err != nil {
 				return err
 			}
@@ -122,21 +163,60 @@ If no resource name is specified, displays stats about all resources of the spec
 	cmd.PersistentFlags().StringVar(&options.toNamespace, "to-namespace", options.toNamespace, "Sets the namespace used to lookup the \"--to\" resource; by default the current \"--namespace\" is used")
 	cmd.PersistentFlags().StringVar(&options.fromResource, "from", options.fromResource, "If present, restricts outbound stats from the specified resource name")
 	cmd.PersistentFlags().StringVar(&options.fromNamespace, "from-namespace", options.fromNamespace, "Sets the namespace used from lookup the \"--from\" resource; by default the current \"--namespace\" is used")
-	cmd.PersistentFlags().BoolVar(&options.allNamespaces, "all-namespaces", options.allNamespaces, "If present, returns stats across all namespaces, ignoring the \"--namespace\" flag")
+	cmd.PersistentFlags().BoolVarP(&options.allNamespaces, "all-namespaces", "A", options.allNamespaces, "If present, returns stats across all namespaces you are allowed to list pods in; incompatible with \"--namespace\"")
+	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, wide, json")
 
 	return cmd
 }
 
 func requestStatsFromAPI(client pb.ApiClient, req *pb.StatSummaryRequest, options *statOptions) (string, error) {
+	resp, err := getStatSummary(client, req)
+	if err != nil {
+		return "", err
+	}
+
+	return renderStatsResponse(resp, req.Selector.Resource.Type, options)
+}
+
+// requestStatsInNamespacesFromAPI requests the stats of req in each of
+// namespaces, for --all-namespaces when you are not allowed to read all of
+// them, and renders them together.
+func requestStatsInNamespacesFromAPI(client pb.ApiClient, req *pb.StatSummaryRequest, options *statOptions, namespaces []string) (string, error) {
+	merged := &pb.StatSummaryResponse{
+		Response: &pb.StatSummaryResponse_Ok_{
+			Ok: &pb.StatSummaryResponse_Ok{},
+		},
+	}
+	for _, namespace := range namespaces {
+		namespaceReq := proto.Clone(req).(*pb.StatSummaryRequest)
+		namespaceReq.Selector.Resource.Namespace = namespace
+
+		resp, err := getStatSummary(client, namespaceReq)
+		if err != nil {
+			return "", err
+		}
+		merged.GetOk().StatTables = append(merged.GetOk().StatTables, resp.GetOk().StatTables...)
+	}
+
+	return renderStatsResponse(merged, req.Selector.Resource.Type, options)
+}
+
+func getStatSummary(client pb.ApiClient, req *pb.StatSummaryRequest) (*pb.StatSummaryResponse, error) {
 	resp, err := client.StatSummary(context.Background(), req)
 	if err != nil {
-		return "", fmt.Errorf("StatSummary API error: %v", err)
+		return nil, fmt.Errorf("StatSummary API error: %v", err)
 	}
 	if e := resp.GetError(); e != nil {
-		return "", fmt.Errorf("StatSummary API response error: %v", e.Error)
+		return nil, fmt.Errorf("StatSummary API response error: %v", e.Error)
 	}
+	return resp, nil
+}
 
-	return renderStats(resp, req.Selector.Resource.Type, options), nil
+func renderStatsResponse(resp *pb.StatSummaryResponse, resourceType string, options *statOptions) (string, error) {
+	if options.output == jsonOutput {
+		return renderStatsJSON(resp)
+	}
+	return renderStats(resp, resourceType, options), nil
 }
 
 func renderStats(resp *pb.StatSummaryResponse, resourceType string, options *statOptions) string {
@@ -152,7 +232,12 @@ func renderStats(resp *pb.StatSummaryResponse, resourceType string, options *sta
 	return out
 }
 
-const padding = 3
+const (
+	padding = 3
+
+	// wideOutput also displays the TCP stats in the table.
+	wideOutput = "wide"
+)
 
 type rowStats struct {
 	requestRate float64
@@ -163,9 +248,16 @@ type rowStats struct {
 	latencyP99  uint64
 }
 
+type tcpRowStats struct {
+	openConnections uint64
+	readRate        float64
+	writeRate       float64
+}
+
 type row struct {
 	meshed string
 	*rowStats
+	*tcpRowStats
 }
 
 var (
@@ -173,8 +265,27 @@ var (
 	namespaceHeader = "NAMESPACE"
 )
 
+// nameColumnHeader returns the header of the NAME column, which names the
+// resource given with --to or --from, if any, so that the direction of the
+// traffic is explicit.
+func (o *statOptions) nameColumnHeader() string {
+	direction, resource, namespace := "", "", ""
+	switch {
+	case o.toResource != "":
+		direction, resource, namespace = "TO", o.toResource, o.toNamespace
+	case o.fromResource != "":
+		direction, resource, namespace = "FROM", o.fromResource, o.fromNamespace
+	default:
+		return nameHeader
+	}
+	if namespace != "" {
+		resource += " IN " + namespace
+	}
+	return fmt.Sprintf("%s (%s %s)", nameHeader, direction, resource)
+}
+
 func writeStatsToBuffer(resp *pb.StatSummaryResponse, reqResourceType string, w *tabwriter.Writer, options *statOptions) {
-	maxNameLength := len(nameHeader)
+	maxNameLength := len(options.nameColumnHeader())
 	maxNamespaceLength := len(namespaceHeader)
 	statTables := make(map[string]map[string]*row)
 
@@ -222,6 +333,14 @@ func writeStatsToBuffer(resp *pb.StatSummaryResponse, reqResourceType string, w
 					latencyP99:  r.Stats.LatencyMsP99,
 				}
 			}
+
+			if r.TcpStats != nil {
+				statTables[resourceKey][key].tcpRowStats = &tcpRowStats{
+					openConnections: r.TcpStats.OpenConnections,
+					readRate:        getByteRate(r.TcpStats.ReadBytesTotal, r.TimeWindow),
+					writeRate:       getByteRate(r.TcpStats.WriteBytesTotal, r.TimeWindow),
+				}
+			}
 		}
 	}
 
@@ -250,23 +369,28 @@ func writeStatsToBuffer(resp *pb.StatSummaryResponse, reqResourceType string, w
 }
 
 func printStatTable(stats map[string]*row, resourceType string, w *tabwriter.Writer, maxNameLength int, maxNamespaceLength int, options *statOptions) {
+	nameColumnHeader := options.nameColumnHeader()
 	headers := make([]string, 0)
 	if options.allNamespaces {
 		headers = append(headers,
 			namespaceHeader+strings.Repeat(" ", maxNamespaceLength-len(namespaceHeader)))
 	}
 	headers = append(headers, []string{
-		nameHeader + strings.Repeat(" ", maxNameLength-len(nameHeader)),
+		nameColumnHeader + strings.Repeat(" ", maxNameLength-len(nameColumnHeader)),
 		"MESHED",
 		"SUCCESS",
 		"RPS",
 		"LATENCY_P50",
 		"LATENCY_P95",
 		"LATENCY_P99",
-		"TLS\t", // trailing \t is required to format last column
+		"TLS",
 	}...)
+	if options.output == wideOutput {
+		headers = append(headers, "TCP_CONN", "READ_BYTES/SEC", "WRITE_BYTES/SEC")
+	}
 
-	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	// trailing \t is required to format last column
+	fmt.Fprintln(w, strings.Join(headers, "\t")+"\t")
 
 	namePrefix := getNamePrefix(resourceType)
 
@@ -276,8 +400,8 @@ func printStatTable(stats map[string]*row, resourceType string, w *tabwriter.Wri
 		namespace := parts[0]
 		name := namePrefix + parts[1]
 		values := make([]interface{}, 0)
-		templateString := "%s\t%s\t%.2f%%\t%.1frps\t%dms\t%dms\t%dms\t%.f%%\t\n"
-		templateStringEmpty := "%s\t%s\t-\t-\t-\t-\t-\t-\t\n"
+		templateString := "%s\t%s\t%.2f%%\t%.1frps\t%dms\t%dms\t%dms\t%.f%%\t"
+		templateStringEmpty := "%s\t%s\t-\t-\t-\t-\t-\t-\t"
 
 		if options.allNamespaces {
 			values = append(values,
@@ -304,7 +428,91 @@ func printStatTable(stats map[string]*row, resourceType string, w *tabwriter.Wri
 		} else {
 			fmt.Fprintf(w, templateStringEmpty, values...)
 		}
+
+		if options.output == wideOutput {
+			if stats[key].tcpRowStats != nil {
+				fmt.Fprintf(w, "%d\t%.1fB/s\t%.1fB/s\t",
+					stats[key].openConnections,
+					stats[key].readRate,
+					stats[key].writeRate,
+				)
+			} else {
+				fmt.Fprint(w, "-\t-\t-\t")
+			}
+		}
+		fmt.Fprint(w, "\n")
+	}
+}
+
+// jsonStats is a row of the JSON output of stat. Its fields are documented in
+// the help of the command, and are relied upon by scripts: they must not be
+// renamed.
+type jsonStats struct {
+	Namespace    string   `json:"namespace"`
+	Kind         string   `json:"kind"`
+	Name         string   `json:"name"`
+	MeshedPods   *uint64  `json:"meshedPods"`
+	RunningPods  *uint64  `json:"runningPods"`
+	SuccessRate  *float64 `json:"successRate"`
+	RequestRate  *float64 `json:"rps"`
+	LatencyMsP50 *uint64  `json:"latencyMsP50"`
+	LatencyMsP95 *uint64  `json:"latencyMsP95"`
+	LatencyMsP99 *uint64  `json:"latencyMsP99"`
+	TLSRate      *float64 `json:"tlsRate"`
+}
+
+// renderStatsJSON returns the rows of resp as a JSON array, sorted by kind, in
+// the order of the tables of `stat all`, namespace and name.
+func renderStatsJSON(resp *pb.StatSummaryResponse) (string, error) {
+	rows := []*jsonStats{}
+	for _, statTable := range resp.GetOk().StatTables {
+		for _, r := range statTable.GetPodGroup().Rows {
+			jsonRow := &jsonStats{
+				Namespace: r.Resource.Namespace,
+				Kind:      r.Resource.Type,
+				Name:      r.Resource.Name,
+			}
+			if r.Resource.Type != k8s.Authority {
+				meshedPods, runningPods := r.MeshedPodCount, r.RunningPodCount
+				jsonRow.MeshedPods = &meshedPods
+				jsonRow.RunningPods = &runningPods
+			}
+			if r.Stats != nil {
+				successRate, requestRate, tlsRate := getSuccessRate(*r), getRequestRate(*r), getPercentTls(*r)
+				jsonRow.SuccessRate = &successRate
+				jsonRow.RequestRate = &requestRate
+				jsonRow.LatencyMsP50 = &r.Stats.LatencyMsP50
+				jsonRow.LatencyMsP95 = &r.Stats.LatencyMsP95
+				jsonRow.LatencyMsP99 = &r.Stats.LatencyMsP99
+				jsonRow.TLSRate = &tlsRate
+			}
+			rows = append(rows, jsonRow)
+		}
 	}
+
+	kindOrder := func(kind string) int {
+		for i, resourceType := range k8s.StatAllResourceTypes {
+			if resourceType == kind {
+				return i
+			}
+		}
+		return len(k8s.StatAllResourceTypes)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Kind != rows[j].Kind {
+			return kindOrder(rows[i].Kind) < kindOrder(rows[j].Kind)
+		}
+		if rows[i].Namespace != rows[j].Namespace {
+			return rows[i].Namespace < rows[j].Namespace
+		}
+		return rows[i].Name < rows[j].Name
+	})
+
+	out, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
 }
 
 func getNamePrefix(resourceType string) string {
@@ -353,6 +561,7 @@ func buildStatSummaryRequest(resource []string, options *statOptions) (*pb.StatS
 		FromType:      fromRes.Type,
 		FromNamespace: options.fromNamespace,
 		AllNamespaces: options.allNamespaces,
+		TcpStats:      options.output == wideOutput,
 	}
 
 	return util.BuildStatSummaryRequest(requestParams)
@@ -369,6 +578,17 @@ func getRequestRate(r pb.StatTable_PodGroup_Row) float64 {
 	return float64(success+failure) / windowLength.Seconds()
 }
 
+// getByteRate returns the bytes per second of the bytes transferred during
+// timeWindow.
+func getByteRate(bytes uint64, timeWindow string) float64 {
+	windowLength, err := time.ParseDuration(timeWindow)
+	if err != nil {
+		log.Error(err.Error())
+		return 0.0
+	}
+	return float64(bytes) / windowLength.Seconds()
+}
+
 func getSuccessRate(r pb.StatTable_PodGroup_Row) float64 {
 	success := r.Stats.SuccessCount
 	failure := r.Stats.FailureCount
@@ -387,12 +607,21 @@ func getPercentTls(r pb.StatTable_PodGroup_Row) float64 {
 	return float64(r.Stats.TlsRequestCount) / float64(reqTotal)
 }
 
+// sortStatsKeys returns the "namespace/name" keys of stats, sorted by
+// namespace, then name.
 func sortStatsKeys(stats map[string]*row) []string {
 	var sortedKeys []string
 	for key := range stats {
 		sortedKeys = append(sortedKeys, key)
 	}
-	sort.Strings(sortedKeys)
+	sort.Slice(sortedKeys, func(i, j int) bool {
+		iParts := strings.SplitN(sortedKeys[i], "/", 2)
+		jParts := strings.SplitN(sortedKeys[j], "/", 2)
+		if iParts[0] != jParts[0] {
+			return iParts[0] < jParts[0]
+		}
+		return iParts[1] < jParts[1]
+	})
 	return sortedKeys
 }
 
@@ -404,6 +633,10 @@ func (o *statOptions) validate(resourceType string) error {
 		return err
 	}
 
+	if o.output != tableOutput && o.output != wideOutput && o.output != jsonOutput {
+		return fmt.Errorf("output format \"%s\" not recognized, must be one of: %s, %s, %s", o.output, tableOutput, wideOutput, jsonOutput)
+	}
+
 	if resourceType == k8s.Namespace {
 		err := o.validateNamespaceFlags()
 		if err != nil {
@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"context"
+	"io/ioutil"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/linkerd/linkerd2/controller/api/public"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/k8s"
+	"google.golang.org/grpc"
 )
 
 func TestStat(t *testing.T) {
@@ -42,6 +48,253 @@ emoji      1/2   100.00%   2.0rps         123ms         123ms         123ms   10
 		}
 	})
 
+	t.Run("Returns namespaces without traffic with dashes, sorted by name", func(t *testing.T) {
+		mockClient := &public.MockApiClient{}
+
+		response := public.GenStatSummaryResponse("emojivoto", k8s.Namespace, "", &public.PodCounts{
+			MeshedPods:  5,
+			RunningPods: 12,
+		})
+		rows := response.GetOk().StatTables[0].GetPodGroup().Rows
+		response.GetOk().StatTables[0].GetPodGroup().Rows = append(rows, &pb.StatTable_PodGroup_Row{
+			Resource:        &pb.Resource{Type: k8s.Namespace, Name: "books"},
+			TimeWindow:      "1m",
+			RunningPodCount: 3,
+		})
+
+		mockClient.StatSummaryResponseToReturn = &response
+
+		expectedOutput := `NAME        MESHED   SUCCESS      RPS   LATENCY_P50   LATENCY_P95   LATENCY_P99    TLS
+books          0/3         -        -             -             -             -      -
+emojivoto     5/12   100.00%   2.0rps         123ms         123ms         123ms   100%
+`
+
+		options := newStatOptions()
+		req, err := buildStatSummaryRequest([]string{"ns"}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output, err := requestStatsFromAPI(mockClient, req, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if output != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, output)
+		}
+	})
+
+	t.Run("Names the --to or --from resource in the header", func(t *testing.T) {
+		mockClient := &public.MockApiClient{}
+		response := public.GenStatSummaryResponse("web", k8s.Deployment, "emojivoto", &public.PodCounts{
+			MeshedPods:  1,
+			RunningPods: 1,
+		})
+		mockClient.StatSummaryResponseToReturn = &response
+
+		testCases := []struct {
+			toResource     string
+			fromResource   string
+			toNamespace    string
+			expectedHeader string
+		}{
+			{"deploy/books", "", "", "NAME (TO deploy/books)   MESHED"},
+			{"ns/prod", "", "", "NAME (TO ns/prod)   MESHED"},
+			{"deploy/books", "", "prod", "NAME (TO deploy/books IN prod)   MESHED"},
+			{"", "deploy/books", "", "NAME (FROM deploy/books)   MESHED"},
+		}
+
+		for _, tc := range testCases {
+			options := newStatOptions()
+			options.namespace = "emojivoto"
+			options.toResource = tc.toResource
+			options.fromResource = tc.fromResource
+			options.toNamespace = tc.toNamespace
+			req, err := buildStatSummaryRequest([]string{"deploy"}, options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			output, err := requestStatsFromAPI(mockClient, req, options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !strings.HasPrefix(output, tc.expectedHeader) {
+				t.Fatalf("Expected the header to start with [%s], got:\n%s", tc.expectedHeader, output)
+			}
+		}
+	})
+
+	t.Run("Returns the stats in JSON or in a wide table", func(t *testing.T) {
+		testCases := []struct {
+			output           string
+			goldenFileName   string
+			expectedTcpStats bool
+		}{
+			{jsonOutput, "stat_all_output.golden.json", false},
+			{wideOutput, "stat_all_wide_output.golden", true},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.output, func(t *testing.T) {
+				mockClient := &public.MockApiClient{}
+				response := statAllResponse()
+				mockClient.StatSummaryResponseToReturn = &response
+
+				options := newStatOptions()
+				options.output = tc.output
+				req, err := buildStatSummaryRequest([]string{"all"}, options)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if req.TcpStats != tc.expectedTcpStats {
+					t.Fatalf("Expected the TCP stats to be requested: %t, got: %t", tc.expectedTcpStats, req.TcpStats)
+				}
+
+				output, err := requestStatsFromAPI(mockClient, req, options)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				diffCompare(t, output, readOptionalTestFile(t, tc.goldenFileName))
+			})
+		}
+	})
+
+	t.Run("Rejects unknown output formats", func(t *testing.T) {
+		options := newStatOptions()
+		options.output = "yaml"
+		expectedError := "output format \"yaml\" not recognized, must be one of: table, wide, json"
+
+		_, err := buildStatSummaryRequest([]string{"deploy"}, options)
+		if err == nil || err.Error() != expectedError {
+			t.Fatalf("Expected error [%s] instead got [%s]", expectedError, err)
+		}
+	})
+
+	t.Run("Aligns the columns of authorities of any length", func(t *testing.T) {
+		mockClient := &public.MockApiClient{}
+		response := public.GenStatSummaryResponse("web-svc.prod.svc.cluster.local:8080", k8s.Authority, "prod", nil)
+		short := public.GenStatSummaryResponse("books", k8s.Authority, "prod", nil)
+		rows := response.GetOk().StatTables[0].GetPodGroup()
+		rows.Rows = append(rows.Rows, short.GetOk().StatTables[0].GetPodGroup().Rows...)
+		mockClient.StatSummaryResponseToReturn = &response
+
+		expectedOutput := `NAME                                  MESHED   SUCCESS      RPS   LATENCY_P50   LATENCY_P95   LATENCY_P99    TLS
+books                                      -   100.00%   2.0rps         123ms         123ms         123ms   100%
+web-svc.prod.svc.cluster.local:8080        -   100.00%   2.0rps         123ms         123ms         123ms   100%
+`
+
+		options := newStatOptions()
+		options.namespace = "prod"
+		req, err := buildStatSummaryRequest([]string{"authority"}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output, err := requestStatsFromAPI(mockClient, req, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if output != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, output)
+		}
+	})
+
+	t.Run("Returns the stats of all namespaces, sorted by namespace and name", func(t *testing.T) {
+		mockClient := &public.MockApiClient{}
+		response := public.GenStatSummaryResponse("web", k8s.Deployment, "emojivoto", &public.PodCounts{
+			MeshedPods:  1,
+			RunningPods: 1,
+		})
+		rows := response.GetOk().StatTables[0].GetPodGroup()
+		rows.Rows = append(rows.Rows,
+			&pb.StatTable_PodGroup_Row{
+				Resource:        &pb.Resource{Namespace: "books", Type: k8s.Deployment, Name: "webapp"},
+				TimeWindow:      "1m",
+				RunningPodCount: 3,
+			},
+			&pb.StatTable_PodGroup_Row{
+				Resource:        &pb.Resource{Namespace: "books", Type: k8s.Deployment, Name: "authors"},
+				TimeWindow:      "1m",
+				RunningPodCount: 1,
+			},
+		)
+		mockClient.StatSummaryResponseToReturn = &response
+
+		expectedOutput := `NAMESPACE   NAME      MESHED   SUCCESS      RPS   LATENCY_P50   LATENCY_P95   LATENCY_P99    TLS
+books       authors      0/1         -        -             -             -             -      -
+books       webapp       0/3         -        -             -             -             -      -
+emojivoto   web          1/1   100.00%   2.0rps         123ms         123ms         123ms   100%
+`
+
+		options := newStatOptions()
+		options.allNamespaces = true
+		req, err := buildStatSummaryRequest([]string{"deploy"}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if req.Selector.Resource.Namespace != "" {
+			t.Fatalf("Expected no namespace filter, got [%s]", req.Selector.Resource.Namespace)
+		}
+
+		output, err := requestStatsFromAPI(mockClient, req, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if output != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, output)
+		}
+	})
+
+	t.Run("Requests the stats of each namespace you are allowed to list pods in", func(t *testing.T) {
+		mockClient := &namespacedStatClient{}
+
+		options := newStatOptions()
+		options.allNamespaces = true
+		req, err := buildStatSummaryRequest([]string{"deploy"}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		output, err := requestStatsInNamespacesFromAPI(mockClient, req, options, []string{"emojivoto", "books"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedNamespaces := []string{"emojivoto", "books"}
+		if !reflect.DeepEqual(mockClient.namespaces, expectedNamespaces) {
+			t.Fatalf("Expected the stats of %v to be requested, got %v", expectedNamespaces, mockClient.namespaces)
+		}
+		if req.Selector.Resource.Namespace != "" {
+			t.Fatalf("Expected the request not to be changed, got namespace [%s]", req.Selector.Resource.Namespace)
+		}
+
+		expectedOutput := `NAMESPACE   NAME   MESHED   SUCCESS      RPS   LATENCY_P50   LATENCY_P95   LATENCY_P99    TLS
+books       web       1/1   100.00%   2.0rps         123ms         123ms         123ms   100%
+emojivoto   web       1/1   100.00%   2.0rps         123ms         123ms         123ms   100%
+`
+		if output != expectedOutput {
+			t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", expectedOutput, output)
+		}
+	})
+
+	t.Run("Rejects --all-namespaces with --namespace", func(t *testing.T) {
+		cmd := newCmdStat()
+		cmd.SetArgs([]string{"deploy", "-A", "-n", "emojivoto"})
+		cmd.SetOutput(ioutil.Discard)
+		expectedError := "--all-namespaces and --namespace flags are mutually exclusive"
+
+		err := cmd.Execute()
+		if err == nil || err.Error() != expectedError {
+			t.Fatalf("Expected error [%s] instead got [%s]", expectedError, err)
+		}
+	})
+
 	t.Run("Returns an error for named resource queries with the --all-namespaces flag", func(t *testing.T) {
 		options := newStatOptions()
 		options.allNamespaces = true
@@ -104,3 +357,46 @@ emoji      1/2   100.00%   2.0rps         123ms         123ms         123ms   10
 		}
 	})
 }
+
+// namespacedStatClient returns the stats of a deployment in the namespace of
+// each request, and records the namespaces requested.
+type namespacedStatClient struct {
+	public.MockApiClient
+	namespaces []string
+}
+
+func (c *namespacedStatClient) StatSummary(ctx context.Context, in *pb.StatSummaryRequest, opts ...grpc.CallOption) (*pb.StatSummaryResponse, error) {
+	namespace := in.Selector.Resource.Namespace
+	c.namespaces = append(c.namespaces, namespace)
+	response := public.GenStatSummaryResponse("web", k8s.Deployment, namespace, &public.PodCounts{
+		MeshedPods:  1,
+		RunningPods: 1,
+	})
+	return &response, nil
+}
+
+// statAllResponse returns the stats of a deployment with traffic, of a
+// deployment without, and of an authority.
+func statAllResponse() pb.StatSummaryResponse {
+	deployments := public.GenStatSummaryResponse("web", k8s.Deployment, "emojivoto", &public.PodCounts{
+		MeshedPods:  2,
+		RunningPods: 3,
+	})
+	deploymentRows := deployments.GetOk().StatTables[0].GetPodGroup()
+	deploymentRows.Rows[0].Stats.FailureCount = 41
+	deploymentRows.Rows[0].TcpStats = &pb.TcpStats{
+		OpenConnections: 4,
+		ReadBytesTotal:  6000,
+		WriteBytesTotal: 120,
+	}
+	deploymentRows.Rows = append(deploymentRows.Rows, &pb.StatTable_PodGroup_Row{
+		Resource:        &pb.Resource{Namespace: "emojivoto", Type: k8s.Deployment, Name: "voting"},
+		TimeWindow:      "1m",
+		RunningPodCount: 1,
+	})
+
+	authorities := public.GenStatSummaryResponse("web-svc.emojivoto.svc.cluster.local", k8s.Authority, "emojivoto", nil)
+
+	deployments.GetOk().StatTables = append(authorities.GetOk().StatTables, deployments.GetOk().StatTables...)
+	return deployments
+}
@@ -2,41 +2,56 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/duration"
 	"github.com/linkerd/linkerd2/controller/api/util"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/addr"
+	"github.com/linkerd/linkerd2/pkg/k8s"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
 )
 
+// defaultNamespaceMaxRps is the default --max-rps when tapping a namespace,
+// whose many pods would otherwise stream more than can be read.
+const defaultNamespaceMaxRps = 20.0
+
 type tapOptions struct {
-	namespace   string
-	toResource  string
-	toNamespace string
-	maxRps      float32
-	scheme      string
-	method      string
-	authority   string
-	path        string
-	output      string
+	namespace     string
+	toResource    string
+	toNamespace   string
+	maxRps        float32
+	scheme        string
+	method        string
+	authority     string
+	path          string
+	status        string
+	output        string
+	allNamespaces bool
 }
 
 func newTapOptions() *tapOptions {
 	return &tapOptions{
-		namespace:   "default",
-		toResource:  "",
-		toNamespace: "",
-		maxRps:      100.0,
-		scheme:      "",
-		method:      "",
-		authority:   "",
-		path:        "",
-		output:      "",
+		namespace:     "default",
+		toResource:    "",
+		toNamespace:   "",
+		maxRps:        100.0,
+		scheme:        "",
+		method:        "",
+		authority:     "",
+		path:          "",
+		status:        "",
+		output:        "",
+		allNamespaces: false,
 	}
 }
 
@@ -44,7 +59,7 @@ func newCmdTap() *cobra.Command {
 	options := newTapOptions()
 
 	cmd := &cobra.Command{
-		Use:   "tap [flags] (RESOURCE)",
+		Use:   "tap [flags] [RESOURCE]",
 		Short: "Listen to a traffic stream",
 		Long: `Listen to a traffic stream.
 
@@ -57,13 +72,45 @@ func newCmdTap() *cobra.Command {
   * deploy my-deploy
   * ns/my-ns
 
+  Without a RESOURCE, the namespace given by --namespace is tapped.
+
   Valid resource types include:
 
   * deployments
   * namespaces
   * pods
   * replicationcontrollers
-  * services (only supported as a "--to" resource)`,
+  * services (only supported as a "--to" resource)
+
+  The requests displayed must match all of the --to, --scheme, --method,
+  --authority, --path and --status flags given; they are matched by the tap
+  service, so that only the requests displayed are streamed. With --status, a
+  request is displayed once its response has the status given.
+
+  With -o json, each event is written as soon as it is received, as a line of
+  JSON with these fields:
+
+  * schemaVersion: the version of this schema, currently 1
+  * kind: request, response or end
+  * id: the base and stream of the ID of the request
+  * proxyDirection: inbound or outbound
+  * source, destination: the address of the peer, and the metadata of its pod
+  * tls: the TLS status of the connection, as in the default output
+  * method, scheme, authority, path: the request, for request events
+  * status, latencyMs: the status of the response, and the time since the
+    request, for response events
+  * grpcStatus or resetErrorCode, latencyMs, durationMs, responseBytes: how the
+    response ended, the time since the request and since the response, and its
+    size, for end events
+
+  When a namespace is tapped, all of its meshed pods are, including those
+  created while it is tapped, and the source and destination of each request
+  are labeled with the workload they belong to. The --max-rps of a namespace is
+  shared between its pods, and is 20 unless given.
+
+  With --all-namespaces, the resources of the given type are tapped in all the
+  namespaces you are allowed to list pods in, and the output is wide, so that
+  the namespace of the source and destination of each request is displayed.`,
 		Example: `  # tap the web deployment in the default namespace
   linkerd tap deploy/web
 
@@ -71,13 +118,38 @@ func newCmdTap() *cobra.Command {
   linkerd tap pod/web-dlbvj
 
   # tap the test namespace, filter by request to prod namespace
-  linkerd tap ns/test --to ns/prod`,
-		Args:      cobra.RangeArgs(1, 2),
+  linkerd tap ns/test --to ns/prod
+
+  # tap all the workloads of the test namespace
+  linkerd tap --namespace test
+
+  # tap the POSTs to /api/checkout of the web deployment that fail with a 5xx
+  linkerd tap deploy/web --method POST --path /api/checkout --status 5xx
+
+  # tap all deployments in all namespaces
+  linkerd tap deploy --all-namespaces`,
+		Args:      cobra.RangeArgs(0, 2),
 		ValidArgs: util.ValidTargets,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace := options.namespace
+			if options.allNamespaces {
+				if cmd.Flags().Changed("namespace") {
+					return fmt.Errorf("--all-namespaces and --namespace flags are mutually exclusive")
+				}
+				if len(args) == 0 {
+					return fmt.Errorf("a resource is required with --all-namespaces")
+				}
+				namespace = ""
+			}
+
+			resource := strings.Join(args, "/")
+			if len(args) == 0 {
+				resource = k8s.Namespace + "/" + namespace
+			}
+
 			requestParams := util.TapRequestParams{
-				Resource:    strings.Join(args, "/"),
-				Namespace:   options.namespace,
+				Resource:    resource,
+				Namespace:   namespace,
 				ToResource:  options.toResource,
 				ToNamespace: options.toNamespace,
 				MaxRps:      options.maxRps,
@@ -85,25 +157,45 @@ func newCmdTap() *cobra.Command {
 				Method:      options.method,
 				Authority:   options.authority,
 				Path:        options.path,
+				Status:      options.status,
 			}
 
 			req, err := util.BuildTapByResourceRequest(requestParams)
 			if err != nil {
 				return err
 			}
+			if options.allNamespaces && req.Target.Resource.Name != "" && req.Target.Resource.Type != k8s.Namespace {
+				return fmt.Errorf("a resource cannot be tapped by name across all namespaces")
+			}
+			if req.Target.Resource.Type == k8s.Namespace && !cmd.Flags().Changed("max-rps") {
+				req.MaxRps = defaultNamespaceMaxRps
+			}
 
-			wide := false
-			switch options.output {
-			// TODO: support more output formats?
-			case "":
-				// default output format.
-			case "wide":
-				wide = true
+			output := options.output
+			switch output {
+			case "", wideOutput, jsonOutput:
 			default:
-				return fmt.Errorf("output format \"%s\" not recognized", options.output)
+				return fmt.Errorf("output format \"%s\" not recognized", output)
+			}
+			// the namespaces of the resources are only displayed in wide output
+			if options.allNamespaces && output == "" {
+				output = wideOutput
 			}
 
-			return requestTapByResourceFromAPI(os.Stdout, validatedPublicAPIClient(false), req, wide)
+			// namespaces are not themselves in a namespace
+			var namespaces []string
+			if options.allNamespaces && req.Target.Resource.Type != k8s.Namespace {
+				namespaces, err = namespacesWithPodAccess()
+				if err != nil {
+					return err
+				}
+			}
+
+			client := validatedPublicAPIClient(time.Time{})
+			if namespaces == nil {
+				return requestTapByResourceFromAPI(os.Stdout, client, req, output)
+			}
+			return requestTapInNamespacesFromAPI(os.Stdout, client, req, output, namespaces)
 		},
 	}
 
@@ -114,7 +206,7 @@ func newCmdTap() *cobra.Command {
 	cmd.PersistentFlags().StringVar(&options.toNamespace, "to-namespace", options.toNamespace,
 		"Sets the namespace used to lookup the \"--to\" resource; by default the current \"--namespace\" is used")
 	cmd.PersistentFlags().Float32Var(&options.maxRps, "max-rps", options.maxRps,
-		"Maximum requests per second to tap.")
+		fmt.Sprintf("Maximum requests per second to tap; %g by default when tapping a namespace.", defaultNamespaceMaxRps))
 	cmd.PersistentFlags().StringVar(&options.scheme, "scheme", options.scheme,
 		"Display requests with this scheme")
 	cmd.PersistentFlags().StringVar(&options.method, "method", options.method,
@@ -123,25 +215,104 @@ func newCmdTap() *cobra.Command {
 		"Display requests with this :authority")
 	cmd.PersistentFlags().StringVar(&options.path, "path", options.path,
 		"Display requests with paths that start with this prefix")
+	cmd.PersistentFlags().StringVar(&options.status, "status", options.status,
+		"Display requests whose response has this HTTP status code, like 503, or class of codes, like 5xx")
 	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output,
-		"Output format. One of: wide")
+		"Output format. One of: wide, json")
+	cmd.PersistentFlags().BoolVarP(&options.allNamespaces, "all-namespaces", "A", options.allNamespaces,
+		"If present, taps the resources across all namespaces you are allowed to list pods in; incompatible with \"--namespace\"")
 
 	return cmd
 }
 
-func requestTapByResourceFromAPI(w io.Writer, client pb.ApiClient, req *pb.TapByResourceRequest, wide bool) error {
-	var resource string
-	if wide {
-		resource = req.Target.Resource.GetType()
-	}
+func requestTapByResourceFromAPI(w io.Writer, client pb.ApiClient, req *pb.TapByResourceRequest, output string) error {
+	resource := tapResourceLabel(req, output)
 
 	rsp, err := client.TapByResource(context.Background(), req)
 	if err != nil {
 		return err
 	}
+	if output == jsonOutput {
+		return renderTapJSON(w, rsp)
+	}
 	return renderTap(w, rsp, resource)
 }
 
+// requestTapInNamespacesFromAPI taps the target of req in each of namespaces,
+// for --all-namespaces when you are not allowed to read all of them, and
+// renders the events of all the streams as they arrive. The maximum rate of
+// req is shared between the namespaces.
+func requestTapInNamespacesFromAPI(w io.Writer, client pb.ApiClient, req *pb.TapByResourceRequest, output string, namespaces []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan *pb.TapEvent)
+	errs := make(chan error, len(namespaces))
+	for _, namespace := range namespaces {
+		namespaceReq := proto.Clone(req).(*pb.TapByResourceRequest)
+		namespaceReq.Target.Resource.Namespace = namespace
+		namespaceReq.MaxRps = req.MaxRps / float32(len(namespaces))
+
+		rsp, err := client.TapByResource(ctx, namespaceReq)
+		if err != nil {
+			return err
+		}
+		go func() {
+			for {
+				event, err := rsp.Recv()
+				if err != nil {
+					errs <- err
+					return
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	resource := tapResourceLabel(req, output)
+	tableWriter := tabwriter.NewWriter(w, 0, 0, 0, ' ', tabwriter.AlignRight)
+	for open := len(namespaces); open > 0; {
+		select {
+		case event := <-events:
+			var err error
+			if output == jsonOutput {
+				err = writeTapEventJSON(w, event)
+			} else {
+				_, err = fmt.Fprintln(tableWriter, util.RenderTapEvent(event, resource))
+			}
+			if err != nil {
+				return err
+			}
+		case err := <-errs:
+			open--
+			if err != io.EOF {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+	tableWriter.Flush()
+
+	return nil
+}
+
+// tapResourceLabel returns the resource the peers of the events of req are
+// labeled with by RenderTapEvent: the workloads they belong to, if a namespace
+// is tapped, so that its many pods can be told apart, and resources of the
+// type of the target in wide output.
+func tapResourceLabel(req *pb.TapByResourceRequest, output string) string {
+	if req.Target.Resource.GetType() == k8s.Namespace {
+		return util.WorkloadResource
+	}
+	if output == wideOutput {
+		return req.Target.Resource.GetType()
+	}
+	return ""
+}
+
 func renderTap(w io.Writer, tapClient pb.Api_TapByResourceClient, resource string) error {
 	tableWriter := tabwriter.NewWriter(w, 0, 0, 0, ' ', tabwriter.AlignRight)
 	err := writeTapEventsToBuffer(tapClient, tableWriter, resource)
@@ -172,3 +343,166 @@ func writeTapEventsToBuffer(tapClient pb.Api_TapByResourceClient, w *tabwriter.W
 
 	return nil
 }
+
+// tapJSONSchemaVersion is the version of the schema of the events rendered by
+// -o json, to be incremented when fields are changed or removed.
+const tapJSONSchemaVersion = 1
+
+// tapEventJSON is an event rendered by -o json. The fields about requests are
+// only set for request events, and those about responses for response and end
+// events.
+type tapEventJSON struct {
+	SchemaVersion  int         `json:"schemaVersion"`
+	Kind           string      `json:"kind"`
+	ID             tapIDJSON   `json:"id"`
+	ProxyDirection string      `json:"proxyDirection"`
+	Source         tapPeerJSON `json:"source"`
+	Destination    tapPeerJSON `json:"destination"`
+	TLS            string      `json:"tls,omitempty"`
+
+	Method    string `json:"method,omitempty"`
+	Scheme    string `json:"scheme,omitempty"`
+	Authority string `json:"authority,omitempty"`
+	Path      string `json:"path,omitempty"`
+
+	Status         uint32   `json:"status,omitempty"`
+	GrpcStatus     string   `json:"grpcStatus,omitempty"`
+	ResetErrorCode *uint32  `json:"resetErrorCode,omitempty"`
+	LatencyMs      *float64 `json:"latencyMs,omitempty"`
+	DurationMs     *float64 `json:"durationMs,omitempty"`
+	ResponseBytes  *uint64  `json:"responseBytes,omitempty"`
+}
+
+type tapIDJSON struct {
+	Base   uint32 `json:"base"`
+	Stream uint64 `json:"stream"`
+}
+
+// tapPeerJSON is the source or destination of an event, with the labels of
+// its pod, like its pod, deployment and namespace, if it is known.
+type tapPeerJSON struct {
+	Address  string            `json:"address"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// flusher is a writer that buffers its output until it is flushed.
+type flusher interface {
+	Flush() error
+}
+
+func renderTapJSON(w io.Writer, tapClient pb.Api_TapByResourceClient) error {
+	for {
+		log.Debug("Waiting for data...")
+		event, err := tapClient.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			break
+		}
+		if err := writeTapEventJSON(w, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTapEventJSON writes event to w as a line of JSON, flushing w if it is
+// buffered, so that each event can be read as soon as it is received.
+func writeTapEventJSON(w io.Writer, event *pb.TapEvent) error {
+	line, err := json.Marshal(tapEventToJSON(event))
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if f, ok := w.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func tapEventToJSON(event *pb.TapEvent) tapEventJSON {
+	ev := tapEventJSON{
+		SchemaVersion:  tapJSONSchemaVersion,
+		ProxyDirection: strings.ToLower(event.GetProxyDirection().String()),
+		Source:         tapPeerToJSON(event.GetSource(), event.GetSourceMeta()),
+		Destination:    tapPeerToJSON(event.GetDestination(), event.GetDestinationMeta()),
+	}
+	switch event.GetProxyDirection() {
+	case pb.TapEvent_INBOUND:
+		ev.TLS = event.GetSourceMeta().GetLabels()["tls"]
+	case pb.TapEvent_OUTBOUND:
+		ev.TLS = event.GetDestinationMeta().GetLabels()["tls"]
+	}
+
+	milliseconds := func(d *duration.Duration) *float64 {
+		if d == nil {
+			return nil
+		}
+		ms := float64(d.GetSeconds())*1000 + float64(d.GetNanos())/1e6
+		return &ms
+	}
+	id := func(id *pb.TapEvent_Http_StreamId) tapIDJSON {
+		return tapIDJSON{Base: id.GetBase(), Stream: id.GetStream()}
+	}
+
+	switch e := event.GetHttp().GetEvent().(type) {
+	case *pb.TapEvent_Http_RequestInit_:
+		ev.Kind = "request"
+		ev.ID = id(e.RequestInit.GetId())
+		switch method := e.RequestInit.GetMethod().GetType().(type) {
+		case *pb.HttpMethod_Registered_:
+			ev.Method = method.Registered.String()
+		case *pb.HttpMethod_Unregistered:
+			ev.Method = method.Unregistered
+		}
+		switch scheme := e.RequestInit.GetScheme().GetType().(type) {
+		case *pb.Scheme_Registered_:
+			ev.Scheme = strings.ToLower(scheme.Registered.String())
+		case *pb.Scheme_Unregistered:
+			ev.Scheme = scheme.Unregistered
+		}
+		ev.Authority = e.RequestInit.GetAuthority()
+		ev.Path = e.RequestInit.GetPath()
+
+	case *pb.TapEvent_Http_ResponseInit_:
+		ev.Kind = "response"
+		ev.ID = id(e.ResponseInit.GetId())
+		ev.Status = e.ResponseInit.GetHttpStatus()
+		ev.LatencyMs = milliseconds(e.ResponseInit.GetSinceRequestInit())
+
+	case *pb.TapEvent_Http_ResponseEnd_:
+		ev.Kind = "end"
+		ev.ID = id(e.ResponseEnd.GetId())
+		switch eos := e.ResponseEnd.GetEos().GetEnd().(type) {
+		case *pb.Eos_GrpcStatusCode:
+			ev.GrpcStatus = codes.Code(eos.GrpcStatusCode).String()
+		case *pb.Eos_ResetErrorCode:
+			code := eos.ResetErrorCode
+			ev.ResetErrorCode = &code
+		}
+		ev.LatencyMs = milliseconds(e.ResponseEnd.GetSinceRequestInit())
+		ev.DurationMs = milliseconds(e.ResponseEnd.GetSinceResponseInit())
+		responseBytes := e.ResponseEnd.GetResponseBytes()
+		ev.ResponseBytes = &responseBytes
+	}
+	return ev
+}
+
+func tapPeerToJSON(address *pb.TcpAddress, meta *pb.TapEvent_EndpointMeta) tapPeerJSON {
+	peer := tapPeerJSON{Address: addr.PublicAddressToString(address)}
+	for key, value := range meta.GetLabels() {
+		if key == "tls" {
+			continue
+		}
+		if peer.Metadata == nil {
+			peer.Metadata = map[string]string{}
+		}
+		peer.Metadata[key] = value
+	}
+	return peer
+}
@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/golang/protobuf/ptypes/duration"
@@ -13,10 +20,11 @@ import (
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/addr"
 	"github.com/linkerd/linkerd2/pkg/k8s"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 )
 
-func busyTest(t *testing.T, wide bool) {
+func busyTest(t *testing.T, format string) {
 	resourceType := k8s.Pod
 	targetName := "pod-666"
 	params := util.TapRequestParams{
@@ -77,13 +85,13 @@ func busyTest(t *testing.T, wide bool) {
 	}
 
 	writer := bytes.NewBufferString("")
-	err = requestTapByResourceFromAPI(writer, mockApiClient, req, wide)
+	err = requestTapByResourceFromAPI(writer, mockApiClient, req, format)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
 	var goldenFilePath string
-	if wide {
+	if format == wideOutput {
 		goldenFilePath = "testdata/tap_busy_output_wide.golden"
 	} else {
 		goldenFilePath = "testdata/tap_busy_output.golden"
@@ -102,11 +110,11 @@ func busyTest(t *testing.T, wide bool) {
 
 func TestRequestTapByResourceFromAPI(t *testing.T) {
 	t.Run("Should render busy response if everything went well", func(t *testing.T) {
-		busyTest(t, false)
+		busyTest(t, "")
 	})
 
 	t.Run("Should render wide busy response if everything went well", func(t *testing.T) {
-		busyTest(t, true)
+		busyTest(t, wideOutput)
 	})
 
 	t.Run("Should render empty response if no events returned", func(t *testing.T) {
@@ -131,7 +139,7 @@ func TestRequestTapByResourceFromAPI(t *testing.T) {
 		}
 
 		writer := bytes.NewBufferString("")
-		err = requestTapByResourceFromAPI(writer, mockApiClient, req, false)
+		err = requestTapByResourceFromAPI(writer, mockApiClient, req, "")
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -147,6 +155,159 @@ func TestRequestTapByResourceFromAPI(t *testing.T) {
 		}
 	})
 
+	t.Run("Should render events as lines of JSON", func(t *testing.T) {
+		req, err := util.BuildTapByResourceRequest(util.TapRequestParams{Resource: "deploy/web"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		mockApiClient := &public.MockApiClient{}
+		mockApiClient.Api_TapByResourceClientToReturn = &public.MockApi_TapByResourceClient{
+			TapEventsToReturn: jsonTapEvents(),
+		}
+
+		writer := bytes.NewBufferString("")
+		err = requestTapByResourceFromAPI(writer, mockApiClient, req, jsonOutput)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		diffCompare(t, writer.String(), readOptionalTestFile(t, "tap_events_output.golden.json"))
+	})
+
+	t.Run("Should write each JSON event as soon as it is received", func(t *testing.T) {
+		req, err := util.BuildTapByResourceRequest(util.TapRequestParams{Resource: "deploy/web"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		stream := &channelTapClient{events: make(chan *pb.TapEvent)}
+		mockApiClient := &public.MockApiClient{Api_TapByResourceClientToReturn: stream}
+
+		reader, writer := io.Pipe()
+		done := make(chan error)
+		go func() {
+			done <- requestTapByResourceFromAPI(writer, mockApiClient, req, jsonOutput)
+			writer.Close()
+		}()
+
+		lines := bufio.NewReader(reader)
+		for i, event := range jsonTapEvents() {
+			event := event
+			stream.events <- &event
+			line, err := lines.ReadString('\n')
+			if err != nil {
+				t.Fatalf("Unexpected error reading event %d: %v", i, err)
+			}
+			var decoded tapEventJSON
+			if err := json.Unmarshal([]byte(line), &decoded); err != nil || decoded.SchemaVersion != tapJSONSchemaVersion {
+				t.Fatalf("Expected event %d to be a line of JSON, got [%s]: %v", i, line, err)
+			}
+		}
+		close(stream.events)
+
+		if err := <-done; err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Should tap each namespace you are allowed to list pods in", func(t *testing.T) {
+		params := util.TapRequestParams{
+			Resource: k8s.Deployment,
+			MaxRps:   10,
+		}
+
+		req, err := util.BuildTapByResourceRequest(params)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		mockApiClient := &namespacedTapClient{}
+		writer := bytes.NewBufferString("")
+		err = requestTapInNamespacesFromAPI(writer, mockApiClient, req, wideOutput, []string{"books", "emojivoto"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedRequests := []string{"books@5", "emojivoto@5"}
+		if !reflect.DeepEqual(mockApiClient.requests, expectedRequests) {
+			t.Fatalf("Expected the namespaces and rates %v to be tapped, got %v", expectedRequests, mockApiClient.requests)
+		}
+
+		output := writer.String()
+		for _, namespace := range []string{"books", "emojivoto"} {
+			expected := fmt.Sprintf("dst_res=deploy/web dst_ns=%s\n", namespace)
+			if !strings.Contains(output, expected) {
+				t.Fatalf("Expected the events of namespace %s to be rendered with [%s], got:\n%s", namespace, expected, output)
+			}
+		}
+	})
+
+	t.Run("Should label the events of a namespace with their workloads", func(t *testing.T) {
+		req, err := util.BuildTapByResourceRequest(util.TapRequestParams{
+			Resource:  k8s.Namespace + "/emojivoto",
+			Namespace: "emojivoto",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		requestInit := &pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_RequestInit_{
+				RequestInit: &pb.TapEvent_Http_RequestInit{
+					Id: &pb.TapEvent_Http_StreamId{Base: 1},
+				},
+			},
+		}
+		var events []pb.TapEvent
+		for _, labels := range []map[string]string{
+			{k8s.Pod: "web-57b7f9db85-297dw", k8s.ReplicaSet: "web-57b7f9db85", k8s.Deployment: "web", k8s.Namespace: "emojivoto"},
+			{k8s.Pod: "logs-x7rkq", k8s.DaemonSet: "logs", k8s.Namespace: "emojivoto"},
+			{k8s.Pod: "debug", k8s.Namespace: "emojivoto"},
+		} {
+			events = append(events, createEvent(requestInit, labels))
+		}
+
+		for _, output := range []string{"", wideOutput} {
+			mockApiClient := &public.MockApiClient{}
+			mockApiClient.Api_TapByResourceClientToReturn = &public.MockApi_TapByResourceClient{
+				TapEventsToReturn: events,
+			}
+			writer := bytes.NewBufferString("")
+			err = requestTapByResourceFromAPI(writer, mockApiClient, req, output)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(writer.String(), "\n"), "\n")
+			expected := []string{
+				"dst_res=deploy/web dst_ns=emojivoto",
+				"dst_res=ds/logs dst_ns=emojivoto",
+				"dst_res=po/debug dst_ns=emojivoto",
+			}
+			if len(lines) != len(expected) {
+				t.Fatalf("Expected %d events, got:\n%s", len(expected), writer.String())
+			}
+			for i, exp := range expected {
+				if !strings.HasSuffix(lines[i], exp) {
+					t.Fatalf("Expected event %d to be labeled with [%s] in output [%s], got [%s]", i, exp, output, lines[i])
+				}
+			}
+		}
+	})
+
+	t.Run("Should reject --all-namespaces with --namespace", func(t *testing.T) {
+		cmd := newCmdTap()
+		cmd.SetArgs([]string{"deploy", "-A", "-n", "emojivoto"})
+		cmd.SetOutput(ioutil.Discard)
+		expectedError := "--all-namespaces and --namespace flags are mutually exclusive"
+
+		err := cmd.Execute()
+		if err == nil || err.Error() != expectedError {
+			t.Fatalf("Expected error [%s] instead got [%s]", expectedError, err)
+		}
+	})
+
 	t.Run("Should return error if stream returned error", func(t *testing.T) {
 		t.SkipNow()
 		resourceType := k8s.Pod
@@ -170,7 +331,7 @@ func TestRequestTapByResourceFromAPI(t *testing.T) {
 		}
 
 		writer := bytes.NewBufferString("")
-		err = requestTapByResourceFromAPI(writer, mockApiClient, req, false)
+		err = requestTapByResourceFromAPI(writer, mockApiClient, req, "")
 		if err == nil {
 			t.Fatalf("Expecting error, got nothing but output [%s]", writer.String())
 		}
@@ -367,3 +528,98 @@ func createEvent(event_http *pb.TapEvent_Http, dstMeta map[string]string) pb.Tap
 	}
 	return event
 }
+
+// namespacedTapClient returns a stream with an event from a deployment in the
+// namespace of each request, and records the namespace and rate requested.
+type namespacedTapClient struct {
+	public.MockApiClient
+	requests []string
+}
+
+func (c *namespacedTapClient) TapByResource(ctx context.Context, in *pb.TapByResourceRequest, opts ...grpc.CallOption) (pb.Api_TapByResourceClient, error) {
+	namespace := in.Target.Resource.Namespace
+	c.requests = append(c.requests, fmt.Sprintf("%s@%g", namespace, in.MaxRps))
+	event := createEvent(
+		&pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_RequestInit_{
+				RequestInit: &pb.TapEvent_Http_RequestInit{
+					Id: &pb.TapEvent_Http_StreamId{Base: 1},
+				},
+			},
+		},
+		map[string]string{k8s.Deployment: "web", k8s.Namespace: namespace},
+	)
+	return &public.MockApi_TapByResourceClient{TapEventsToReturn: []pb.TapEvent{event}}, nil
+}
+
+// channelTapClient is a stream of the events sent on its channel, which ends
+// once it is closed.
+type channelTapClient struct {
+	events chan *pb.TapEvent
+	grpc.ClientStream
+}
+
+func (c *channelTapClient) Recv() (*pb.TapEvent, error) {
+	event, ok := <-c.events
+	if !ok {
+		return nil, io.EOF
+	}
+	return event, nil
+}
+
+// jsonTapEvents returns the events of a request, its response, and of the end
+// of responses with a gRPC status and with a reset.
+func jsonTapEvents() []pb.TapEvent {
+	id := &pb.TapEvent_Http_StreamId{Base: 2, Stream: 7}
+	inbound := func(httpEvent *pb.TapEvent_Http) pb.TapEvent {
+		event := createEvent(httpEvent, map[string]string{"pod": "web-57b7f9db85-297dw", "deployment": "web", "namespace": "emojivoto"})
+		event.ProxyDirection = pb.TapEvent_INBOUND
+		event.SourceMeta = &pb.TapEvent_EndpointMeta{
+			Labels: map[string]string{"pod": "vote-bot-5d9c7f8b9f-lqmwr", "deployment": "vote-bot", "namespace": "emojivoto", "tls": "true"},
+		}
+		return event
+	}
+
+	return []pb.TapEvent{
+		inbound(&pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_RequestInit_{
+				RequestInit: &pb.TapEvent_Http_RequestInit{
+					Id:        id,
+					Method:    &pb.HttpMethod{Type: &pb.HttpMethod_Registered_{Registered: pb.HttpMethod_POST}},
+					Scheme:    &pb.Scheme{Type: &pb.Scheme_Registered_{Registered: pb.Scheme_HTTP}},
+					Authority: "web-svc.emojivoto:80",
+					Path:      "/api/vote",
+				},
+			},
+		}),
+		inbound(&pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_ResponseInit_{
+				ResponseInit: &pb.TapEvent_Http_ResponseInit{
+					Id:               id,
+					SinceRequestInit: &duration.Duration{Nanos: 1500000},
+					HttpStatus:       http.StatusOK,
+				},
+			},
+		}),
+		inbound(&pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_ResponseEnd_{
+				ResponseEnd: &pb.TapEvent_Http_ResponseEnd{
+					Id:                id,
+					Eos:               &pb.Eos{End: &pb.Eos_GrpcStatusCode{GrpcStatusCode: uint32(codes.Unavailable)}},
+					SinceRequestInit:  &duration.Duration{Nanos: 2250000},
+					SinceResponseInit: &duration.Duration{Nanos: 750000},
+					ResponseBytes:     42,
+				},
+			},
+		}),
+		inbound(&pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_ResponseEnd_{
+				ResponseEnd: &pb.TapEvent_Http_ResponseEnd{
+					Id:               &pb.TapEvent_Http_StreamId{Base: 2, Stream: 8},
+					Eos:              &pb.Eos{End: &pb.Eos_ResetErrorCode{ResetErrorCode: 8}},
+					SinceRequestInit: &duration.Duration{Seconds: 1},
+				},
+			},
+		}),
+	}
+}
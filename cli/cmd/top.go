@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"container/list"
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"sort"
 	"strconv"
@@ -14,6 +16,7 @@ import (
 	"github.com/linkerd/linkerd2/controller/api/util"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/pkg/addr"
+	"github.com/linkerd/linkerd2/pkg/k8s"
 	runewidth "github.com/mattn/go-runewidth"
 	termbox "github.com/nsf/termbox-go"
 	log "github.com/sirupsen/logrus"
@@ -29,6 +32,7 @@ type topOptions struct {
 	method      string
 	authority   string
 	path        string
+	hideSources bool
 }
 
 type topRequest struct {
@@ -48,27 +52,52 @@ func (id topRequestID) String() string {
 	return fmt.Sprintf("%s->%s(%d)", id.src, id.dst, id.stream)
 }
 
-type tableRow struct {
-	by          string
+// topRowKey is what the requests of a row of the table have in common. The
+// source is empty with --hide-sources.
+type topRowKey struct {
 	source      string
 	destination string
-	count       int
-	best        time.Duration
-	worst       time.Duration
-	last        time.Duration
-	successes   int
-	failures    int
+	method      string
+	path        string
+}
+
+type tableRow struct {
+	topRowKey
+	count     int
+	successes int
+
+	// latencies holds the latencies of the last maxLatencySamples requests,
+	// from which the percentiles are computed; next is where the next one is
+	// recorded once it is full.
+	latencies []time.Duration
+	next      int
 }
 
-const headerHeight = 3
+const (
+	headerHeight = 3
+
+	// refreshInterval is how often the table is redrawn.
+	refreshInterval = time.Second
+
+	// maxTopRows bounds the rows of the table; once it is full, the row
+	// updated the least recently is evicted to make room for a new one.
+	maxTopRows = 1000
+
+	// maxOutstandingRequests bounds the requests whose response has not been
+	// seen yet; once it is full, the oldest is forgotten, as the stream may
+	// never send the end of some responses.
+	maxOutstandingRequests = 10000
+
+	maxLatencySamples = 1000
+)
 
 var (
-	columnNames  = []string{"Source", "Destination", "Path", "Count", "Best", "Worst", "Last", "Success Rate"}
-	columnWidths = []int{23, 23, 55, 6, 6, 6, 6, 3}
+	columnNames  = []string{"Source", "Destination", "Method", "Path", "Count", "Success", "P50", "P95", "P99"}
+	columnWidths = []int{23, 23, 7, 45, 6, 8, 7, 7, 7}
 )
 
-func newTopOptions() *tapOptions {
-	return &tapOptions{
+func newTopOptions() *topOptions {
+	return &topOptions{
 		namespace:   "default",
 		toResource:  "",
 		toNamespace: "",
@@ -77,6 +106,7 @@ func newTopOptions() *tapOptions {
 		method:      "",
 		authority:   "",
 		path:        "",
+		hideSources: false,
 	}
 }
 
@@ -103,12 +133,21 @@ func newCmdTop() *cobra.Command {
   * namespaces
   * pods
   * replicationcontrollers
-  * services (only supported as a "--to" resource)`,
+  * services (only supported as a "--to" resource)
+
+  The requests tapped are aggregated by source, destination, method and path,
+  and the table of their counts, success rates and latency percentiles is
+  refreshed every second, with the most frequent requests first. The
+  percentiles are those of the last 1000 requests of each row, and the rows
+  least recently seen are dropped once there are 1000 of them.`,
 		Example: `  # display traffic for the web deployment in the default namespace
   linkerd top deploy/web
 
   # display traffic for the web-dlbvj pod in the default namespace
-  linkerd top pod/web-dlbvj`,
+  linkerd top pod/web-dlbvj
+
+  # display traffic for the web deployment, aggregated across all sources
+  linkerd top deploy/web --hide-sources`,
 		Args:      cobra.RangeArgs(1, 2),
 		ValidArgs: util.ValidTargets,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -129,7 +168,7 @@ func newCmdTop() *cobra.Command {
 				return err
 			}
 
-			return getTrafficByResourceFromAPI(os.Stdout, validatedPublicAPIClient(false), req)
+			return getTrafficByResourceFromAPI(os.Stdout, validatedPublicAPIClient(time.Time{}), req, options)
 		},
 	}
 
@@ -149,12 +188,21 @@ func newCmdTop() *cobra.Command {
 		"Display requests with this :authority")
 	cmd.PersistentFlags().StringVar(&options.path, "path", options.path,
 		"Display requests with paths that start with this prefix")
+	cmd.PersistentFlags().BoolVar(&options.hideSources, "hide-sources", options.hideSources,
+		"Hide the source column, aggregating requests from all sources")
 
 	return cmd
 }
 
-func getTrafficByResourceFromAPI(w io.Writer, client pb.ApiClient, req *pb.TapByResourceRequest) error {
-	rsp, err := client.TapByResource(context.Background(), req)
+// getTrafficByResourceFromAPI renders the table of the requests tapped by req
+// until the stream ends, q or Ctrl-C is pressed, or the process is
+// interrupted, and restores the terminal before returning. The error ending
+// the stream, if any, is written to w once the terminal is restored.
+func getTrafficByResourceFromAPI(w io.Writer, client pb.ApiClient, req *pb.TapByResourceRequest, options *topOptions) error {
+	ctx, cancel := newSignalContext()
+	defer cancel()
+
+	rsp, err := client.TapByResource(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -163,175 +211,290 @@ func getTrafficByResourceFromAPI(w io.Writer, client pb.ApiClient, req *pb.TapBy
 	if err != nil {
 		return err
 	}
-	defer termbox.Close()
 
 	requestCh := make(chan topRequest, 100)
-	done := make(chan struct{})
+	streamErr := make(chan error, 1)
 
-	go recvEvents(rsp, requestCh, done)
-	go pollInput(done)
+	go recvEvents(ctx, rsp, requestCh, streamErr, cancel)
+	go pollInput(cancel)
 
-	renderTable(requestCh, done)
+	renderTable(ctx, requestCh, newTopTable(maxTopRows, options.hideSources))
+	termbox.Close()
 
+	select {
+	case err := <-streamErr:
+		if err == io.EOF {
+			fmt.Fprintln(w, "Tap stream terminated")
+		} else {
+			fmt.Fprintln(w, err.Error())
+		}
+	default:
+	}
 	return nil
 }
 
-func recvEvents(tapClient pb.Api_TapByResourceClient, requestCh chan<- topRequest, done chan<- struct{}) {
-	outstandingRequests := make(map[topRequestID]topRequest)
+// recvEvents sends the requests completed by the events of the stream to
+// requestCh, until ctx is done or the stream ends, in which case the error
+// ending it is sent to streamErr and ctx is canceled.
+func recvEvents(ctx context.Context, tapClient pb.Api_TapByResourceClient, requestCh chan<- topRequest, streamErr chan<- error, cancel context.CancelFunc) {
+	tracker := newTopRequestTracker(maxOutstandingRequests)
 	for {
 		event, err := tapClient.Recv()
-		if err == io.EOF {
-			fmt.Println("Tap stream terminated")
-			close(done)
+		if err != nil {
+			if ctx.Err() == nil {
+				streamErr <- err
+			}
+			cancel()
 			return
 		}
-		if err != nil {
-			fmt.Println(err.Error())
-			close(done)
+		req, ok := tracker.track(event)
+		if !ok {
+			continue
+		}
+		select {
+		case requestCh <- req:
+		case <-ctx.Done():
 			return
 		}
-		id := topRequestID{
-			src: addr.PublicAddressToString(event.GetSource()),
-			dst: addr.PublicAddressToString(event.GetDestination()),
+	}
+}
+
+// topRequestTracker matches the events of the tap stream with the requests
+// they are about.
+type topRequestTracker struct {
+	maxOutstanding int
+
+	// outstanding holds the elements of order by request ID; order holds the
+	// requests whose response has not ended, the oldest first.
+	outstanding map[topRequestID]*list.Element
+	order       *list.List
+}
+
+type outstandingRequest struct {
+	id  topRequestID
+	req topRequest
+}
+
+func newTopRequestTracker(maxOutstanding int) *topRequestTracker {
+	return &topRequestTracker{
+		maxOutstanding: maxOutstanding,
+		outstanding:    make(map[topRequestID]*list.Element),
+		order:          list.New(),
+	}
+}
+
+// track records event, and returns the request it completes, if it is the end
+// of a response. The events of responses to requests that were not seen, as
+// when top starts while they are in flight, are ignored.
+func (t *topRequestTracker) track(event *pb.TapEvent) (topRequest, bool) {
+	id := topRequestID{
+		src: addr.PublicAddressToString(event.GetSource()),
+		dst: addr.PublicAddressToString(event.GetDestination()),
+	}
+	switch ev := event.GetHttp().GetEvent().(type) {
+	case *pb.TapEvent_Http_RequestInit_:
+		id.stream = ev.RequestInit.GetId().GetStream()
+		if elem, ok := t.outstanding[id]; ok {
+			t.order.Remove(elem)
+		}
+		t.outstanding[id] = t.order.PushBack(&outstandingRequest{
+			id:  id,
+			req: topRequest{event: event, reqInit: ev.RequestInit},
+		})
+		if t.order.Len() > t.maxOutstanding {
+			oldest := t.order.Remove(t.order.Front()).(*outstandingRequest)
+			delete(t.outstanding, oldest.id)
 		}
-		switch ev := event.GetHttp().GetEvent().(type) {
-		case *pb.TapEvent_Http_RequestInit_:
-			id.stream = ev.RequestInit.GetId().Stream
-			outstandingRequests[id] = topRequest{
-				event:   event,
-				reqInit: ev.RequestInit,
-			}
 
-		case *pb.TapEvent_Http_ResponseInit_:
-			id.stream = ev.ResponseInit.GetId().Stream
-			if req, ok := outstandingRequests[id]; ok {
-				req.rspInit = ev.ResponseInit
-				outstandingRequests[id] = req
-			} else {
-				log.Warnf("Got ResponseInit for unknown stream: %s", id)
-			}
+	case *pb.TapEvent_Http_ResponseInit_:
+		id.stream = ev.ResponseInit.GetId().GetStream()
+		if elem, ok := t.outstanding[id]; ok {
+			elem.Value.(*outstandingRequest).req.rspInit = ev.ResponseInit
+		} else {
+			log.Debugf("Got ResponseInit for unknown stream: %s", id)
+		}
 
-		case *pb.TapEvent_Http_ResponseEnd_:
-			id.stream = ev.ResponseEnd.GetId().Stream
-			if req, ok := outstandingRequests[id]; ok {
-				req.rspEnd = ev.ResponseEnd
-				requestCh <- req
-			} else {
-				log.Warnf("Got ResponseEnd for unknown stream: %s", id)
-			}
+	case *pb.TapEvent_Http_ResponseEnd_:
+		id.stream = ev.ResponseEnd.GetId().GetStream()
+		if elem, ok := t.outstanding[id]; ok {
+			t.order.Remove(elem)
+			delete(t.outstanding, id)
+			req := elem.Value.(*outstandingRequest).req
+			req.rspEnd = ev.ResponseEnd
+			return req, true
 		}
+		log.Debugf("Got ResponseEnd for unknown stream: %s", id)
 	}
+	return topRequest{}, false
 }
 
-func pollInput(done chan<- struct{}) {
+func pollInput(cancel context.CancelFunc) {
 	for {
 		switch ev := termbox.PollEvent(); ev.Type {
 		case termbox.EventKey:
 			if ev.Ch == 'q' || ev.Key == termbox.KeyCtrlC {
-				close(done)
+				cancel()
 				return
 			}
 		}
 	}
 }
 
-func renderTable(requestCh <-chan topRequest, done <-chan struct{}) {
-	ticker := time.NewTicker(100 * time.Millisecond)
-	var table []tableRow
+func renderTable(ctx context.Context, requestCh <-chan topRequest, table *topTable) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
 
 	for {
 		select {
-		case <-done:
+		case <-ctx.Done():
 			return
 		case req := <-requestCh:
-			tableInsert(&table, req)
+			table.insert(req)
 		case <-ticker.C:
 			termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
-			renderHeaders()
-			renderTableBody(&table)
+			renderHeaders(table.hideSources)
+			renderTableBody(table)
 			termbox.Flush()
 		}
 	}
 }
 
-func tableInsert(table *[]tableRow, req topRequest) {
-	by := req.reqInit.GetPath()
-	source := stripPort(addr.PublicAddressToString(req.event.GetSource()))
-	if pod := req.event.SourceMeta.Labels["pod"]; pod != "" {
-		source = pod
-	}
-	destination := stripPort(addr.PublicAddressToString(req.event.GetDestination()))
-	if pod := req.event.DestinationMeta.Labels["pod"]; pod != "" {
-		destination = pod
+// topTable aggregates the requests tapped by the key of their row.
+type topTable struct {
+	hideSources bool
+	maxRows     int
+
+	// rows holds the elements of recent by key; recent holds the rows, the
+	// most recently updated first.
+	rows   map[topRowKey]*list.Element
+	recent *list.List
+}
+
+func newTopTable(maxRows int, hideSources bool) *topTable {
+	return &topTable{
+		hideSources: hideSources,
+		maxRows:     maxRows,
+		rows:        make(map[topRowKey]*list.Element),
+		recent:      list.New(),
 	}
+}
 
+// insert adds req to the stats of its row, adding the row if it is the first
+// request of its kind, and evicting the row updated the least recently if the
+// table is full.
+func (t *topTable) insert(req topRequest) {
 	latency, err := ptypes.Duration(req.rspEnd.GetSinceRequestInit())
 	if err != nil {
-		log.Errorf("error parsing duration %v: %s", req.rspEnd.GetSinceRequestInit(), err)
+		log.Debugf("error parsing duration %v: %s", req.rspEnd.GetSinceRequestInit(), err)
 		return
 	}
-	success := req.rspInit.GetHttpStatus() < 500
-	if success {
-		switch eos := req.rspEnd.GetEos().GetEnd().(type) {
-		case *pb.Eos_GrpcStatusCode:
-			success = eos.GrpcStatusCode == 0
-
-		case *pb.Eos_ResetErrorCode:
-			success = false
-		}
+
+	key := topRowKey{
+		destination: peerName(req.event.GetDestination(), req.event.GetDestinationMeta()),
+		method:      methodName(req.reqInit.GetMethod()),
+		path:        req.reqInit.GetPath(),
+	}
+	if !t.hideSources {
+		key.source = peerName(req.event.GetSource(), req.event.GetSourceMeta())
 	}
 
-	found := false
-	for i, row := range *table {
-		if row.by == by && row.source == source && row.destination == destination {
-			(*table)[i].count++
-			if latency.Nanoseconds() < row.best.Nanoseconds() {
-				(*table)[i].best = latency
-			}
-			if latency.Nanoseconds() > row.worst.Nanoseconds() {
-				(*table)[i].worst = latency
-			}
-			(*table)[i].last = latency
-			if success {
-				(*table)[i].successes++
-			} else {
-				(*table)[i].failures++
-			}
-			found = true
+	var row *tableRow
+	if elem, ok := t.rows[key]; ok {
+		t.recent.MoveToFront(elem)
+		row = elem.Value.(*tableRow)
+	} else {
+		row = &tableRow{topRowKey: key}
+		t.rows[key] = t.recent.PushFront(row)
+		if t.recent.Len() > t.maxRows {
+			evicted := t.recent.Remove(t.recent.Back()).(*tableRow)
+			delete(t.rows, evicted.topRowKey)
 		}
 	}
 
-	if !found {
-		successes := 0
-		failures := 0
-		if success {
-			successes++
-		} else {
-			failures++
-		}
-		row := tableRow{
-			by:          by,
-			source:      source,
-			destination: destination,
-			count:       1,
-			best:        latency,
-			worst:       latency,
-			last:        latency,
-			successes:   successes,
-			failures:    failures,
-		}
-		*table = append(*table, row)
+	row.count++
+	if isSuccess(req) {
+		row.successes++
+	}
+	if len(row.latencies) < maxLatencySamples {
+		row.latencies = append(row.latencies, latency)
+	} else {
+		row.latencies[row.next] = latency
+		row.next = (row.next + 1) % maxLatencySamples
 	}
 }
 
+// sortedRows returns the rows of the table, the most requested first.
+func (t *topTable) sortedRows() []*tableRow {
+	rows := make([]*tableRow, 0, len(t.rows))
+	for elem := t.recent.Front(); elem != nil; elem = elem.Next() {
+		rows = append(rows, elem.Value.(*tableRow))
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].count > rows[j].count
+	})
+	return rows
+}
+
+// successRate returns the fraction of the requests of the row that succeeded.
+func (r *tableRow) successRate() float64 {
+	return float64(r.successes) / float64(r.count)
+}
+
+// latencyPercentile returns the latency under which percentile percent of the
+// sampled requests of the row completed, by the nearest-rank method.
+func (r *tableRow) latencyPercentile(percentile float64) time.Duration {
+	sorted := make([]time.Duration, len(r.latencies))
+	copy(sorted, r.latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// isSuccess returns whether req succeeded: its response has neither a 5xx
+// status, nor a gRPC status other than OK, nor was it reset.
+func isSuccess(req topRequest) bool {
+	if req.rspInit.GetHttpStatus() >= 500 {
+		return false
+	}
+	switch eos := req.rspEnd.GetEos().GetEnd().(type) {
+	case *pb.Eos_GrpcStatusCode:
+		return eos.GrpcStatusCode == 0
+	case *pb.Eos_ResetErrorCode:
+		return false
+	}
+	return true
+}
+
+// peerName returns the pod of a peer of a request, or its IP if it has no pod.
+func peerName(address *pb.TcpAddress, meta *pb.TapEvent_EndpointMeta) string {
+	if pod := meta.GetLabels()[k8s.Pod]; pod != "" {
+		return pod
+	}
+	return stripPort(addr.PublicAddressToString(address))
+}
+
+func methodName(method *pb.HttpMethod) string {
+	if unregistered := method.GetUnregistered(); unregistered != "" {
+		return unregistered
+	}
+	return method.GetRegistered().String()
+}
+
 func stripPort(address string) string {
 	return strings.Split(address, ":")[0]
 }
 
-func renderHeaders() {
+func renderHeaders(hideSources bool) {
 	tbprint(0, 0, "(press q to quit)")
 	x := 0
 	for i, header := range columnNames {
+		if i == 0 && hideSources {
+			continue
+		}
 		width := columnWidths[i]
 		padded := fmt.Sprintf("%-"+strconv.Itoa(width)+"s ", header)
 		tbprintBold(x, 2, padded)
@@ -339,28 +502,27 @@ func renderHeaders() {
 	}
 }
 
-func renderTableBody(table *[]tableRow) {
-	sort.SliceStable(*table, func(i, j int) bool {
-		return (*table)[i].count > (*table)[j].count
-	})
-	for i, row := range *table {
+func renderTableBody(table *topTable) {
+	for i, row := range table.sortedRows() {
+		cells := []string{
+			row.source,
+			row.destination,
+			row.method,
+			row.path,
+			strconv.Itoa(row.count),
+			fmt.Sprintf("%.2f%%", 100*row.successRate()),
+			formatDuration(row.latencyPercentile(50)),
+			formatDuration(row.latencyPercentile(95)),
+			formatDuration(row.latencyPercentile(99)),
+		}
 		x := 0
-		tbprint(x, i+headerHeight, row.source)
-		x += columnWidths[0] + 1
-		tbprint(x, i+headerHeight, row.destination)
-		x += columnWidths[1] + 1
-		tbprint(x, i+headerHeight, row.by)
-		x += columnWidths[2] + 1
-		tbprint(x, i+headerHeight, strconv.Itoa(row.count))
-		x += columnWidths[3] + 1
-		tbprint(x, i+headerHeight, formatDuration(row.best))
-		x += columnWidths[4] + 1
-		tbprint(x, i+headerHeight, formatDuration(row.worst))
-		x += columnWidths[5] + 1
-		tbprint(x, i+headerHeight, formatDuration(row.last))
-		x += columnWidths[6] + 1
-		successRate := fmt.Sprintf("%.2f%%", 100.0*float32(row.successes)/float32(row.successes+row.failures))
-		tbprint(x, i+headerHeight, successRate)
+		for j, cell := range cells {
+			if j == 0 && table.hideSources {
+				continue
+			}
+			tbprint(x, i+headerHeight, cell)
+			x += columnWidths[j] + 1
+		}
 	}
 }
 
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/linkerd/linkerd2/controller/api/public"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/addr"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+// topEvents returns the events of a request from the src pod to the dst pod,
+// on the given stream, and of its response, with the given status and latency.
+func topEvents(src, dst string, stream uint64, method pb.HttpMethod_Registered, path string, status uint32, latency time.Duration) []pb.TapEvent {
+	id := &pb.TapEvent_Http_StreamId{Base: 1, Stream: stream}
+	return []pb.TapEvent{
+		topEvent(src, dst, &pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_RequestInit_{
+				RequestInit: &pb.TapEvent_Http_RequestInit{
+					Id:     id,
+					Method: &pb.HttpMethod{Type: &pb.HttpMethod_Registered_{Registered: method}},
+					Path:   path,
+				},
+			},
+		}),
+		topEvent(src, dst, &pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_ResponseInit_{
+				ResponseInit: &pb.TapEvent_Http_ResponseInit{Id: id, HttpStatus: status},
+			},
+		}),
+		topEvent(src, dst, &pb.TapEvent_Http{
+			Event: &pb.TapEvent_Http_ResponseEnd_{
+				ResponseEnd: &pb.TapEvent_Http_ResponseEnd{Id: id, SinceRequestInit: ptypes.DurationProto(latency)},
+			},
+		}),
+	}
+}
+
+func topEvent(src, dst string, http *pb.TapEvent_Http) pb.TapEvent {
+	return pb.TapEvent{
+		Source:          &pb.TcpAddress{Ip: addr.PublicIPV4(10, 0, 0, uint8(len(src))), Port: 5555},
+		SourceMeta:      &pb.TapEvent_EndpointMeta{Labels: map[string]string{k8s.Pod: src}},
+		Destination:     &pb.TcpAddress{Ip: addr.PublicIPV4(10, 0, 1, uint8(len(dst))), Port: 8080},
+		DestinationMeta: &pb.TapEvent_EndpointMeta{Labels: map[string]string{k8s.Pod: dst}},
+		Event:           &pb.TapEvent_Http_{Http: http},
+	}
+}
+
+// aggregateTopEvents streams events through recvEvents into a table.
+func aggregateTopEvents(t *testing.T, table *topTable, events []pb.TapEvent) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := &public.MockApi_TapByResourceClient{TapEventsToReturn: events}
+	requestCh := make(chan topRequest, len(events))
+	streamErr := make(chan error, 1)
+	recvEvents(ctx, stream, requestCh, streamErr, cancel)
+	close(requestCh)
+
+	if err := <-streamErr; err != io.EOF {
+		t.Fatalf("Expected the stream to end with EOF, got %v", err)
+	}
+	for req := range requestCh {
+		table.insert(req)
+	}
+}
+
+func TestTopTable(t *testing.T) {
+	t.Run("Aggregates requests by source, destination, method and path, the most requested first", func(t *testing.T) {
+		var events []pb.TapEvent
+		events = append(events, topEvents("web", "books", 1, pb.HttpMethod_POST, "/books", 201, time.Millisecond)...)
+		events = append(events, topEvents("web", "books", 3, pb.HttpMethod_GET, "/books", 200, time.Millisecond)...)
+		events = append(events, topEvents("web", "books", 5, pb.HttpMethod_GET, "/books", 503, time.Millisecond)...)
+		events = append(events, topEvents("traffic", "books", 1, pb.HttpMethod_GET, "/books", 200, time.Millisecond)...)
+
+		table := newTopTable(maxTopRows, false)
+		aggregateTopEvents(t, table, events)
+
+		rows := table.sortedRows()
+		expected := []struct {
+			key         topRowKey
+			count       int
+			successRate float64
+		}{
+			{topRowKey{"web", "books", "GET", "/books"}, 2, 0.5},
+			{topRowKey{"traffic", "books", "GET", "/books"}, 1, 1},
+			{topRowKey{"web", "books", "POST", "/books"}, 1, 1},
+		}
+		if len(rows) != len(expected) {
+			t.Fatalf("Expected %d rows, got %d", len(expected), len(rows))
+		}
+		for i, exp := range expected {
+			if rows[i].topRowKey != exp.key || rows[i].count != exp.count || rows[i].successRate() != exp.successRate {
+				t.Fatalf("Expected row %d to be %+v, got %+v with success rate %f", i, exp, rows[i].topRowKey, rows[i].successRate())
+			}
+		}
+	})
+
+	t.Run("Ignores the responses to requests that were not seen", func(t *testing.T) {
+		orphan := topEvents("web", "books", 7, pb.HttpMethod_GET, "/authors", 200, time.Millisecond)
+		events := append(orphan[1:], topEvents("web", "books", 9, pb.HttpMethod_GET, "/books", 200, time.Millisecond)...)
+		// the end of a response that already ended
+		events = append(events, events[len(events)-1])
+
+		table := newTopTable(maxTopRows, false)
+		aggregateTopEvents(t, table, events)
+
+		rows := table.sortedRows()
+		if len(rows) != 1 || rows[0].path != "/books" || rows[0].count != 1 {
+			t.Fatalf("Expected only the request with a request event to be counted, got %+v", rows)
+		}
+	})
+
+	t.Run("Aggregates requests from all sources with --hide-sources", func(t *testing.T) {
+		var events []pb.TapEvent
+		events = append(events, topEvents("web", "books", 1, pb.HttpMethod_GET, "/books", 200, time.Millisecond)...)
+		events = append(events, topEvents("traffic", "books", 1, pb.HttpMethod_GET, "/books", 200, time.Millisecond)...)
+
+		table := newTopTable(maxTopRows, true)
+		aggregateTopEvents(t, table, events)
+
+		rows := table.sortedRows()
+		if len(rows) != 1 || rows[0].source != "" || rows[0].count != 2 {
+			t.Fatalf("Expected a single row without source, got %+v", rows)
+		}
+	})
+
+	t.Run("Computes latency percentiles from the responses", func(t *testing.T) {
+		var events []pb.TapEvent
+		for i := 1; i <= 100; i++ {
+			events = append(events, topEvents("web", "books", uint64(i), pb.HttpMethod_GET, "/books", 200, time.Duration(101-i)*time.Millisecond)...)
+		}
+
+		table := newTopTable(maxTopRows, false)
+		aggregateTopEvents(t, table, events)
+
+		row := table.sortedRows()[0]
+		for percentile, expected := range map[float64]time.Duration{
+			50: 50 * time.Millisecond,
+			95: 95 * time.Millisecond,
+			99: 99 * time.Millisecond,
+		} {
+			if latency := row.latencyPercentile(percentile); latency != expected {
+				t.Fatalf("Expected p%.0f to be %s, got %s", percentile, expected, latency)
+			}
+		}
+	})
+
+	t.Run("Evicts the row updated the least recently once the table is full", func(t *testing.T) {
+		var events []pb.TapEvent
+		events = append(events, topEvents("web", "books", 1, pb.HttpMethod_GET, "/books", 200, time.Millisecond)...)
+		events = append(events, topEvents("web", "books", 3, pb.HttpMethod_GET, "/authors", 200, time.Millisecond)...)
+		events = append(events, topEvents("web", "books", 5, pb.HttpMethod_GET, "/books", 200, time.Millisecond)...)
+		events = append(events, topEvents("web", "books", 7, pb.HttpMethod_GET, "/search", 200, time.Millisecond)...)
+
+		table := newTopTable(2, false)
+		aggregateTopEvents(t, table, events)
+
+		rows := table.sortedRows()
+		if len(rows) != 2 || rows[0].path != "/books" || rows[1].path != "/search" {
+			t.Fatalf("Expected /authors to be evicted, got %+v", rows)
+		}
+	})
+}
+
+func TestTopRequestTracker(t *testing.T) {
+	t.Run("Forgets the oldest requests without responses once too many are outstanding", func(t *testing.T) {
+		first := topEvents("web", "books", 1, pb.HttpMethod_GET, "/books", 200, time.Millisecond)
+		second := topEvents("web", "books", 3, pb.HttpMethod_GET, "/books", 200, time.Millisecond)
+		third := topEvents("web", "books", 5, pb.HttpMethod_GET, "/books", 200, time.Millisecond)
+
+		tracker := newTopRequestTracker(2)
+		for _, event := range []pb.TapEvent{first[0], second[0], third[0]} {
+			event := event
+			tracker.track(&event)
+		}
+		if len(tracker.outstanding) != 2 || tracker.order.Len() != 2 {
+			t.Fatalf("Expected 2 outstanding requests, got %d", len(tracker.outstanding))
+		}
+
+		if _, ok := tracker.track(&first[2]); ok {
+			t.Fatalf("Expected the oldest request to be forgotten")
+		}
+		if _, ok := tracker.track(&third[2]); !ok {
+			t.Fatalf("Expected the latest request to be completed")
+		}
+		if len(tracker.outstanding) != 1 {
+			t.Fatalf("Expected completed requests not to be tracked, got %d outstanding", len(tracker.outstanding))
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/inject"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+)
+
+// uninjectLabels are the labels inject sets on pod templates.
+var uninjectLabels = []string{
+	k8s.ControllerNSLabel,
+	k8s.ProxyDeploymentLabel,
+	k8s.ProxyReplicationControllerLabel,
+	k8s.ProxyReplicaSetLabel,
+	k8s.ProxyJobLabel,
+	k8s.ProxyCronJobLabel,
+	k8s.ProxyDaemonSetLabel,
+	k8s.ProxyStatefulSetLabel,
+}
+
+// uninjectAnnotations are the annotations inject sets on pod templates.
+var uninjectAnnotations = []string{
+	k8s.CreatedByAnnotation,
+	k8s.ProxyVersionAnnotation,
+	k8s.ProxySkipInboundPortsAnnotation,
+	k8s.ProxySkipOutboundPortsAnnotation,
+}
+
+type uninjectOptions struct {
+	recursive bool
+	outputDir string
+}
+
+func newUninjectOptions() *uninjectOptions {
+	return &uninjectOptions{
+		recursive: false,
+		outputDir: "",
+	}
+}
+
+func newCmdUninject() *cobra.Command {
+	options := newUninjectOptions()
+
+	cmd := &cobra.Command{
+		Use:   "uninject [flags] CONFIG-FILE...",
+		Short: "Remove the Linkerd proxy from a Kubernetes config",
+		Long: `Remove the Linkerd proxy from a Kubernetes config.
+
+The uninject command removes the proxy and init containers added by
+"linkerd inject" from the pod templates of the configs, along with the
+labels and annotations it set, and reports what was removed from each of
+them. It reads the configs as inject does: from stdin with the '-' argument,
+and from several files and folders.`,
+		Example: `  # Remove the proxy from a deployment that is running
+  kubectl get deploy/web -o yaml | linkerd uninject - | kubectl apply -f -
+
+  # Remove the proxy from the configs of a folder and its sub-folders
+  linkerd uninject --recursive <folder> | kubectl apply -f -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("please specify a kubernetes resource file")
+			}
+
+			in, err := read(args, options.recursive)
+			if err != nil {
+				return err
+			}
+			if options.outputDir != "" {
+				if err := checkOutputPaths(in); err != nil {
+					return err
+				}
+			}
+
+			exitCode := runUninjectCmd(in, os.Stderr, os.Stdout, options)
+			os.Exit(exitCode)
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().BoolVarP(&options.recursive, "recursive", "R", options.recursive, "Also uninject the files in the sub-folders of folders")
+	cmd.PersistentFlags().StringVar(&options.outputDir, "output-dir", options.outputDir, "Write each uninjected file to its path relative to the folder it was found in, or to its name, in this directory, instead of writing all of them to stdout")
+
+	return cmd
+}
+
+// Returns the integer representation of os.Exit code; 0 on success and 1 on failure.
+func runUninjectCmd(inputs []injectFile, errWriter, outWriter io.Writer, options *uninjectOptions) int {
+	report := newInjectReport(errWriter, "uninject")
+	return transformFiles(inputs, outWriter, report, uninjector{}, options.outputDir, "uninjecting linkerd proxy from")
+}
+
+// uninjector removes the proxy from the pod templates of workloads.
+type uninjector struct{}
+
+func (uninjector) transform(w *workload, report *injectReport) (bool, error) {
+	if !inject.IsInjected(w.objectMeta, w.podSpec) {
+		report.skip(w.kind, w.name, "not injected")
+		return false, nil
+	}
+
+	removed := inject.RemoveProxy(w.podSpec)
+	removed = append(removed, removeKeys(w.objectMeta.Labels, "label", uninjectLabels)...)
+	removed = append(removed, removeKeys(w.objectMeta.Annotations, "annotation", uninjectAnnotations)...)
+	report.change(w.kind, w.name, "uninjected: removed "+strings.Join(removed, ", "))
+	return true, nil
+}
+
+// removeKeys removes the given keys from m, returning those it removed, each
+// prefixed with what they are. Keys that merely share a prefix with them are
+// left, as they are not set by inject.
+func removeKeys(m map[string]string, what string, keys []string) []string {
+	removed := []string{}
+	for _, key := range keys {
+		if _, ok := m[key]; ok {
+			delete(m, key)
+			removed = append(removed, what+" "+key)
+		}
+	}
+	return removed
+}
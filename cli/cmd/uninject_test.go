@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestUninjectYAML(t *testing.T) {
+	testCases := []struct {
+		inputFileName  string
+		goldenFileName string
+		reportFileName string
+	}{
+		{"inject_emojivoto_deployment.golden.yml", "uninject_emojivoto_deployment.golden.yml", "uninject_emojivoto_deployment.report.golden"},
+		{"inject_emojivoto_deployment_tls.golden.yml", "uninject_emojivoto_deployment.golden.yml", "uninject_emojivoto_deployment_tls.report.golden"},
+		{"inject_emojivoto_list.golden.yml", "uninject_emojivoto_list.golden.yml", "uninject_emojivoto_list.report.golden"},
+		{"inject_emojivoto_replicationcontroller.golden.yml", "uninject_emojivoto_replicationcontroller.golden.yml", "uninject_emojivoto_replicationcontroller.report.golden"},
+		{"inject_emojivoto_replicaset.golden.yml", "uninject_emojivoto_replicaset.golden.yml", "uninject_emojivoto_replicaset.report.golden"},
+		{"inject_emojivoto_statefulset.golden.yml", "uninject_emojivoto_statefulset.golden.yml", "uninject_emojivoto_statefulset.report.golden"},
+		{"inject_emojivoto_daemonset.golden.yml", "uninject_emojivoto_daemonset.golden.yml", "uninject_emojivoto_daemonset.report.golden"},
+		{"inject_emojivoto_job.golden.yml", "uninject_emojivoto_job.golden.yml", "uninject_emojivoto_job.report.golden"},
+		{"inject_emojivoto_cronjob_tls.golden.yml", "uninject_emojivoto_cronjob.golden.yml", "uninject_emojivoto_cronjob.report.golden"},
+		{"inject_emojivoto_pod_tls.golden.yml", "uninject_emojivoto_pod.golden.yml", "uninject_emojivoto_pod.report.golden"},
+		{"inject_emojivoto_mixed.golden.yml", "uninject_emojivoto_mixed.golden.yml", "uninject_emojivoto_mixed.report.golden"},
+		{"inject_emojivoto_deployment.input.yml", "uninject_emojivoto_deployment_not_injected.golden.yml", "uninject_emojivoto_deployment_not_injected.report.golden"},
+		{"uninject_emojivoto_deployment_prefixes.input.yml", "uninject_emojivoto_deployment_prefixes.golden.yml", "uninject_emojivoto_deployment_prefixes.report.golden"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d: %s", i, tc.inputFileName), func(t *testing.T) {
+			file, err := os.Open("testdata/" + tc.inputFileName)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer file.Close()
+
+			output := new(bytes.Buffer)
+			report := new(bytes.Buffer)
+			if err := transformYAML(file, output, newInjectReport(report, "uninject"), uninjector{}); err != nil {
+				t.Fatalf("Unexpected error uninjecting YAML: %v", err)
+			}
+
+			goldenFileBytes, err := ioutil.ReadFile("testdata/" + tc.goldenFileName)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			diffCompare(t, output.String(), string(goldenFileBytes))
+			diffCompare(t, report.String(), readOptionalTestFile(t, tc.reportFileName))
+		})
+	}
+
+	t.Run("Uninjected workloads are injected back to the same configs", func(t *testing.T) {
+		options := newInjectOptions()
+		options.linkerdVersion = "testinjectversion"
+
+		input, err := ioutil.ReadFile("testdata/uninject_emojivoto_deployment.golden.yml")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		output := new(bytes.Buffer)
+		if err := InjectYAML(bytes.NewReader(input), output, newInjectReport(ioutil.Discard, "inject"), options); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		goldenFileBytes, err := ioutil.ReadFile("testdata/inject_emojivoto_deployment.golden.yml")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		diffCompare(t, output.String(), string(goldenFileBytes))
+	})
+}
+
+func TestRunUninjectCmd(t *testing.T) {
+	in, err := read([]string{"testdata/inject_emojivoto_deployment.golden.yml", "testdata/inject_emojivoto_deployment.input.yml"}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	errBuffer := &bytes.Buffer{}
+	if exitCode := runUninjectCmd(in, errBuffer, ioutil.Discard, newUninjectOptions()); exitCode != 0 {
+		t.Fatalf("Unexpected exit code %d: %s", exitCode, errBuffer.String())
+	}
+
+	expected := `Deployment "web" uninjected: removed container linkerd-proxy, init container linkerd-init, label linkerd.io/control-plane-ns, label linkerd.io/proxy-deployment, annotation linkerd.io/created-by, annotation linkerd.io/proxy-version
+Deployment "web" skipped: not injected
+Summary: 1 of 2 resources uninjected, from 2 files
+`
+	diffCompare(t, errBuffer.String(), expected)
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"k8s.io/api/core/v1"
+)
+
+// uninstallResourceTypes are the types of the resources created by `linkerd
+// install`, in the order they are deleted: the webhooks first, so that no
+// more pods are injected, then the workloads, so that they do not fail as
+// their configuration and permissions are removed, and the namespace last, as
+// deleting it deletes whatever is left in it.
+var uninstallResourceTypes = []k8s.ResourceType{
+	{Kind: "MutatingWebhookConfiguration", Resource: "mutatingwebhookconfigurations", Group: "admissionregistration.k8s.io", Version: "v1beta1"},
+	{Kind: "Deployment", Resource: "deployments", Group: "extensions", Version: "v1beta1", Namespaced: true},
+	{Kind: "PodDisruptionBudget", Resource: "poddisruptionbudgets", Group: "policy", Version: "v1beta1", Namespaced: true},
+	{Kind: "Service", Resource: "services", Version: "v1", Namespaced: true},
+	{Kind: "ConfigMap", Resource: "configmaps", Version: "v1", Namespaced: true},
+	{Kind: "Secret", Resource: "secrets", Version: "v1", Namespaced: true},
+	{Kind: "RoleBinding", Resource: "rolebindings", Group: "rbac.authorization.k8s.io", Version: "v1", Namespaced: true},
+	{Kind: "Role", Resource: "roles", Group: "rbac.authorization.k8s.io", Version: "v1", Namespaced: true},
+	{Kind: "ServiceAccount", Resource: "serviceaccounts", Version: "v1", Namespaced: true},
+	{Kind: "ClusterRoleBinding", Resource: "clusterrolebindings", Group: "rbac.authorization.k8s.io", Version: "v1"},
+	{Kind: "ClusterRole", Resource: "clusterroles", Group: "rbac.authorization.k8s.io", Version: "v1"},
+	{Kind: "PodSecurityPolicy", Resource: "podsecuritypolicies", Group: "policy", Version: "v1beta1"},
+	{Kind: "CustomResourceDefinition", Resource: "customresourcedefinitions", Group: "apiextensions.k8s.io", Version: "v1beta1"},
+	{Kind: "Namespace", Resource: "namespaces", Version: "v1"},
+}
+
+type uninstallOptions struct {
+	yes   bool
+	force bool
+	wait  time.Duration
+}
+
+func newUninstallOptions() *uninstallOptions {
+	return &uninstallOptions{
+		yes:   false,
+		force: false,
+		wait:  defaultWaitTimeout,
+	}
+}
+
+func newCmdUninstall() *cobra.Command {
+	options := newUninstallOptions()
+
+	cmd := &cobra.Command{
+		Use:   "uninstall [flags]",
+		Short: "Output Kubernetes resources to uninstall Linkerd",
+		Long: `Output Kubernetes resources to uninstall Linkerd.
+
+The uninstall command finds the resources created by "linkerd install" for
+the control plane in --linkerd-namespace, and outputs them in the order they
+should be deleted in, to be piped to "kubectl delete -f -". With --yes, it
+deletes them itself, and waits for the control plane namespace to be deleted.`,
+		Example: `  # Output the resources of the control plane, to review them or delete them with kubectl
+  linkerd uninstall | kubectl delete -f -
+
+  # Delete the control plane in the "test" namespace
+  linkerd uninstall --yes --linkerd-namespace test`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeAPIOptions())
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := newSignalContext()
+			defer cancel()
+
+			return runUninstall(ctx, kubeAPI, os.Stdout, options)
+		},
+	}
+
+	cmd.PersistentFlags().BoolVar(&options.yes, "yes", options.yes, "Delete the resources, instead of writing them to stdout")
+	cmd.PersistentFlags().BoolVar(&options.force, "force", options.force, "Uninstall even if workloads are still meshed with the control plane; their proxies will stop working")
+	cmd.PersistentFlags().DurationVar(&options.wait, "wait", options.wait, "How long to wait for the control plane namespace to be deleted, with --yes")
+
+	return cmd
+}
+
+func runUninstall(ctx context.Context, kubeAPI *k8s.KubernetesAPI, w io.Writer, options *uninstallOptions) error {
+	if !options.force {
+		namespaces, err := meshedNamespaces(ctx, kubeAPI, controlPlaneNamespace)
+		if err != nil {
+			return err
+		}
+		if len(namespaces) > 0 {
+			return fmt.Errorf("workloads in the following namespaces are still meshed with the control plane in \"%s\": %s; uninject them first, or pass --force", controlPlaneNamespace, strings.Join(namespaces, ", "))
+		}
+	}
+
+	resources, errs := discoverResources(ctx, kubeAPI, controlPlaneNamespace)
+	if !options.yes {
+		if err := renderResources(resources, w); err != nil {
+			return err
+		}
+		return uninstallError("find", errs)
+	}
+
+	errs = append(errs, deleteResources(ctx, kubeAPI, resources, w)...)
+	for _, resource := range resources {
+		if resource.Kind != "Namespace" {
+			continue
+		}
+		fmt.Fprintf(w, "Waiting for namespace %s to be deleted...\n", resource.Name)
+		if err := kubeAPI.WaitForNamespaceDeletion(ctx, resource.Name, options.wait); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return uninstallError("delete", errs)
+}
+
+// meshedNamespaces returns the namespaces of the pods meshed with the control
+// plane in controlPlaneNamespace, other than those of the control plane.
+func meshedNamespaces(ctx context.Context, kubeAPI *k8s.KubernetesAPI, controlPlaneNamespace string) ([]string, error) {
+	selector := fmt.Sprintf("%s=%s,!%s", k8s.ControllerNSLabel, controlPlaneNamespace, k8s.ControllerComponentLabel)
+	found := map[string]bool{}
+	err := kubeAPI.VisitPods(ctx, "", selector, func(pods []v1.Pod) error {
+		for _, pod := range pods {
+			found[pod.Namespace] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]string, 0, len(found))
+	for namespace := range found {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// discoverResources returns the resources labelled as belonging to the
+// control plane in controlPlaneNamespace, in the order of
+// uninstallResourceTypes. Namespaced resources are only looked for in
+// controlPlaneNamespace. Types the cluster does not serve are skipped, and the
+// errors listing the others are returned along with the resources that could
+// be found.
+func discoverResources(ctx context.Context, kubeAPI *k8s.KubernetesAPI, controlPlaneNamespace string) ([]k8s.Resource, []error) {
+	selector := fmt.Sprintf("%s=%s", k8s.ControllerNSLabel, controlPlaneNamespace)
+	resources := []k8s.Resource{}
+	errs := []error{}
+	for _, resourceType := range uninstallResourceTypes {
+		found, err := kubeAPI.ListResources(ctx, resourceType, controlPlaneNamespace, selector)
+		if k8s.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not list %s resources: %v", resourceType.Kind, err))
+			continue
+		}
+		resources = append(resources, found...)
+	}
+	return resources, errs
+}
+
+// renderResources writes a YAML document identifying each of resources, so
+// that they can be deleted with kubectl.
+func renderResources(resources []k8s.Resource, w io.Writer) error {
+	for _, resource := range resources {
+		apiVersion := resource.Version
+		if resource.Group != "" {
+			apiVersion = resource.Group + "/" + resource.Version
+		}
+
+		metadata := map[string]string{"name": resource.Name}
+		if resource.Namespace != "" {
+			metadata["namespace"] = resource.Namespace
+		}
+
+		out, err := yaml.Marshal(map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       resource.Kind,
+			"metadata":   metadata,
+		})
+		if err != nil {
+			return err
+		}
+		w.Write(out)
+		w.Write([]byte("---\n"))
+	}
+	return nil
+}
+
+// deleteResources deletes resources in order, returning the errors deleting
+// those that could not be. Resources that no longer exist are skipped.
+func deleteResources(ctx context.Context, kubeAPI *k8s.KubernetesAPI, resources []k8s.Resource, w io.Writer) []error {
+	errs := []error{}
+	for _, resource := range resources {
+		err := kubeAPI.DeleteResource(ctx, resource)
+		switch {
+		case err == nil:
+			fmt.Fprintf(w, "%s deleted\n", resource)
+		case k8s.IsNotFound(err):
+		case k8s.IsForbidden(err):
+			errs = append(errs, fmt.Errorf("not allowed to delete %s: %v", resource, err))
+		default:
+			errs = append(errs, fmt.Errorf("could not delete %s: %v", resource, err))
+		}
+	}
+	return errs
+}
+
+func uninstallError(action string, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	descriptions := make([]string, len(errs))
+	for i, err := range errs {
+		descriptions[i] = err.Error()
+	}
+	return fmt.Errorf("could not %s all of the control plane resources:\n\t%s", action, strings.Join(descriptions, "\n\t"))
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/client-go/rest"
+)
+
+// uninstallServer is a fake API server for a control plane in the linkerd
+// namespace, listing the given objects by collection path. Other collections
+// are not served. Deleting an object in forbidden is not allowed, and the
+// paths of the other deleted objects are recorded in deleted.
+type uninstallServer struct {
+	*httptest.Server
+	t         *testing.T
+	pods      string
+	objects   map[string]string
+	forbidden map[string]bool
+
+	mu      sync.Mutex
+	deleted []string
+}
+
+func newUninstallServer(t *testing.T, pods string, objects map[string]string, forbidden map[string]bool) *uninstallServer {
+	s := &uninstallServer{t: t, pods: pods, objects: objects, forbidden: forbidden}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *uninstallServer) serve(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == "DELETE":
+		if s.forbidden[r.URL.Path] {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","reason":"Forbidden","message":"forbidden"}`))
+			return
+		}
+		s.mu.Lock()
+		s.deleted = append(s.deleted, r.URL.Path)
+		s.mu.Unlock()
+		w.Write([]byte(`{}`))
+	case r.URL.Path == "/api/v1/pods":
+		if selector := r.URL.Query().Get("labelSelector"); selector != "linkerd.io/control-plane-ns=linkerd,!linkerd.io/control-plane-component" {
+			s.t.Errorf("Unexpected pod selector [%s]", selector)
+		}
+		w.Write([]byte(s.pods))
+	case s.objects[r.URL.Path] != "":
+		if selector := r.URL.Query().Get("labelSelector"); selector != "linkerd.io/control-plane-ns=linkerd" {
+			s.t.Errorf("Unexpected selector [%s] listing [%s]", selector, r.URL.Path)
+		}
+		w.Write([]byte(s.objects[r.URL.Path]))
+	default:
+		// Namespaces, once deleted, and collections that are not served.
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func listBody(names ...string) string {
+	var items []string
+	for _, name := range names {
+		items = append(items, `{"metadata":{"name":"`+name+`"}}`)
+	}
+	return `{"items":[` + strings.Join(items, ",") + `]}`
+}
+
+func namespacedListBody(names ...string) string {
+	var items []string
+	for _, name := range names {
+		items = append(items, `{"metadata":{"name":"`+name+`","namespace":"linkerd"}}`)
+	}
+	return `{"items":[` + strings.Join(items, ",") + `]}`
+}
+
+// installedObjects are the objects of a control plane installed in the linkerd
+// namespace with --tls optional, along with the Secret of the proxy injector,
+// by collection path.
+var installedObjects = map[string]string{
+	"/apis/admissionregistration.k8s.io/v1beta1/mutatingwebhookconfigurations": listBody(),
+	"/apis/extensions/v1beta1/namespaces/linkerd/deployments":                  namespacedListBody("ca", "controller", "web"),
+	"/apis/policy/v1beta1/namespaces/linkerd/poddisruptionbudgets":             listBody(),
+	"/api/v1/namespaces/linkerd/services":                                      namespacedListBody("api", "web"),
+	"/api/v1/namespaces/linkerd/configmaps":                                    namespacedListBody("linkerd-config"),
+	"/api/v1/namespaces/linkerd/secrets":                                       namespacedListBody("linkerd-proxy-injector-tls"),
+	"/apis/rbac.authorization.k8s.io/v1/namespaces/linkerd/rolebindings":       listBody(),
+	"/apis/rbac.authorization.k8s.io/v1/namespaces/linkerd/roles":              listBody(),
+	"/api/v1/namespaces/linkerd/serviceaccounts":                               namespacedListBody("linkerd-ca", "linkerd-controller"),
+	"/apis/rbac.authorization.k8s.io/v1/clusterrolebindings":                   listBody("linkerd-linkerd-ca", "linkerd-linkerd-controller"),
+	"/apis/rbac.authorization.k8s.io/v1/clusterroles":                          listBody("linkerd-linkerd-ca", "linkerd-linkerd-controller"),
+	"/apis/apiextensions.k8s.io/v1beta1/customresourcedefinitions":             listBody(),
+	"/api/v1/namespaces": listBody("linkerd"),
+}
+
+func TestRunUninstall(t *testing.T) {
+	defaultControlPlaneNamespace := controlPlaneNamespace
+	controlPlaneNamespace = "linkerd"
+	defer func() { controlPlaneNamespace = defaultControlPlaneNamespace }()
+
+	t.Run("Outputs the resources in the order they are deleted", func(t *testing.T) {
+		server := newUninstallServer(t, listBody(), installedObjects, nil)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		var out bytes.Buffer
+		if err := runUninstall(context.Background(), kubeAPI, &out, newUninstallOptions()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: ca
+  namespace: linkerd
+---
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: controller
+  namespace: linkerd
+---
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+  namespace: linkerd
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: linkerd
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: linkerd
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: linkerd-config
+  namespace: linkerd
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: linkerd-proxy-injector-tls
+  namespace: linkerd
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: linkerd-ca
+  namespace: linkerd
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: linkerd-controller
+  namespace: linkerd
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: linkerd-linkerd-ca
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: linkerd-linkerd-controller
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: linkerd-linkerd-ca
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: linkerd-linkerd-controller
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: linkerd
+---
+`
+		diffCompare(t, out.String(), expected)
+		if len(server.deleted) > 0 {
+			t.Fatalf("Expected nothing to be deleted without --yes, got %v", server.deleted)
+		}
+	})
+
+	t.Run("Refuses to uninstall while workloads are meshed", func(t *testing.T) {
+		pods := `{"items":[{"metadata":{"name":"web-1","namespace":"emojivoto"}},{"metadata":{"name":"books-1","namespace":"booksapp"}},{"metadata":{"name":"web-2","namespace":"emojivoto"}}]}`
+		server := newUninstallServer(t, pods, installedObjects, nil)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		options := newUninstallOptions()
+		options.yes = true
+		err := runUninstall(context.Background(), kubeAPI, &bytes.Buffer{}, options)
+		expected := "workloads in the following namespaces are still meshed with the control plane in \"linkerd\": booksapp, emojivoto; uninject them first, or pass --force"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+		if len(server.deleted) > 0 {
+			t.Fatalf("Expected nothing to be deleted, got %v", server.deleted)
+		}
+
+		options.force = true
+		if err := runUninstall(context.Background(), kubeAPI, &bytes.Buffer{}, options); err != nil {
+			t.Fatalf("Unexpected error with --force: %v", err)
+		}
+		if len(server.deleted) == 0 {
+			t.Fatalf("Expected the control plane to be deleted with --force")
+		}
+	})
+
+	t.Run("Deletes the resources in order, and reports those it is not allowed to", func(t *testing.T) {
+		forbidden := map[string]bool{
+			"/apis/rbac.authorization.k8s.io/v1/clusterroles/linkerd-linkerd-ca": true,
+		}
+		server := newUninstallServer(t, listBody(), installedObjects, forbidden)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		options := newUninstallOptions()
+		options.yes = true
+		var out bytes.Buffer
+		err := runUninstall(context.Background(), kubeAPI, &out, options)
+		if err == nil || !strings.Contains(err.Error(), "not allowed to delete ClusterRole/linkerd-linkerd-ca") {
+			t.Fatalf("Expected an error about ClusterRole/linkerd-linkerd-ca, got [%v]", err)
+		}
+
+		expected := []string{
+			"/apis/extensions/v1beta1/namespaces/linkerd/deployments/ca",
+			"/apis/extensions/v1beta1/namespaces/linkerd/deployments/controller",
+			"/apis/extensions/v1beta1/namespaces/linkerd/deployments/web",
+			"/api/v1/namespaces/linkerd/services/api",
+			"/api/v1/namespaces/linkerd/services/web",
+			"/api/v1/namespaces/linkerd/configmaps/linkerd-config",
+			"/api/v1/namespaces/linkerd/secrets/linkerd-proxy-injector-tls",
+			"/api/v1/namespaces/linkerd/serviceaccounts/linkerd-ca",
+			"/api/v1/namespaces/linkerd/serviceaccounts/linkerd-controller",
+			"/apis/rbac.authorization.k8s.io/v1/clusterrolebindings/linkerd-linkerd-ca",
+			"/apis/rbac.authorization.k8s.io/v1/clusterrolebindings/linkerd-linkerd-controller",
+			"/apis/rbac.authorization.k8s.io/v1/clusterroles/linkerd-linkerd-controller",
+			"/api/v1/namespaces/linkerd",
+		}
+		if !reflect.DeepEqual(server.deleted, expected) {
+			t.Fatalf("Expected deletions %v, got %v", expected, server.deleted)
+		}
+		if !strings.Contains(out.String(), "Namespace/linkerd deleted\nWaiting for namespace linkerd to be deleted...\n") {
+			t.Fatalf("Expected the namespace deletion to be waited for, got:\n%s", out.String())
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// upgradeResetFlags are the install flags that are not carried over from the
+// installed control plane: upgrading moves it to the images of this version of
+// the CLI, unless another --linkerd-version is given again.
+var upgradeResetFlags = map[string]bool{
+	"linkerd-version": true,
+}
+
+// proxyInjectorSecretFile is the file splitManifests writes the Secret of the
+// proxy injector to.
+var proxyInjectorSecretFile = fmt.Sprintf("secret-%s.yaml", k8s.ProxyInjectorTLSSecretName)
+
+type upgradeOptions struct {
+	// force upgrades the control plane even if the options it was installed
+	// with cannot be read, using only the flags given.
+	force bool
+	*installOptions
+}
+
+func newUpgradeOptions() *upgradeOptions {
+	return &upgradeOptions{
+		force:          false,
+		installOptions: newInstallOptions(),
+	}
+}
+
+func newCmdUpgrade() *cobra.Command {
+	options := newUpgradeOptions()
+
+	cmd := &cobra.Command{
+		Use:   "upgrade [flags]",
+		Short: "Output Kubernetes configs to upgrade an existing Linkerd control plane",
+		Long: `Output Kubernetes configs to upgrade an existing Linkerd control plane.
+
+The upgrade command reads the flags the control plane in --linkerd-namespace
+was installed with from its linkerd-config ConfigMap, and renders the configs
+of this version of the CLI with them, so that upgrading does not undo any of
+them. Flags given to upgrade override the installed ones, and are recorded
+for the next upgrade. With --proxy-auto-inject, the Secret of the proxy
+injector is rendered again with the certificate it already serves, so that it
+keeps serving it, or with a new one if the Secret is missing.`,
+		Example: `  # Upgrade the control plane, keeping the options it was installed with
+  linkerd upgrade | kubectl apply -f -
+
+  # Upgrade the control plane, and run more replicas of the controller
+  linkerd upgrade --controller-replicas 5 | kubectl apply -f -`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeAPIOptions())
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := newSignalContext()
+			defer cancel()
+
+			config, err := upgradeConfig(ctx, kubeAPI, cmd.PersistentFlags(), os.Stderr, options)
+			if err != nil {
+				return err
+			}
+			return renderUpgrade(*config, os.Stdout, options.installOptions)
+		},
+	}
+
+	addInstallFlags(cmd, options.installOptions)
+	cmd.PersistentFlags().BoolVar(&options.force, "force", options.force, "Upgrade even if the options the control plane was installed with cannot be read, using only the flags given")
+
+	return cmd
+}
+
+// upgradeConfig builds the config of the upgraded control plane, from the
+// flags it was installed with, overridden by those set in flags. If the
+// installed flags cannot be read, e.g. because the control plane was installed
+// by a version of the CLI that did not record them, it returns an error,
+// unless options.force is set.
+func upgradeConfig(ctx context.Context, kubeAPI *k8s.KubernetesAPI, flags *pflag.FlagSet, stderr io.Writer, options *upgradeOptions) (*installConfig, error) {
+	installed, err := kubeAPI.GetInstallOptions(ctx, controlPlaneNamespace)
+	if err != nil {
+		if !options.force {
+			return nil, fmt.Errorf("could not read the options of the control plane in \"%s\": %v; pass --force to upgrade it with the flags given only", controlPlaneNamespace, err)
+		}
+		fmt.Fprintf(stderr, "Upgrading with the flags given only, as the options of the control plane in \"%s\" could not be read: %v\n", controlPlaneNamespace, err)
+		installed = &k8s.InstallOptions{}
+	}
+
+	if err := setInstalledFlags(flags, installed, stderr); err != nil {
+		return nil, err
+	}
+	options.recordedFlags = recordFlags(flags)
+	config, err := validateAndBuildConfig(options.installOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ProxyAutoInject {
+		// The webhook configuration must keep the CA bundle of the certificate
+		// the proxy injector already serves, unless it is being added.
+		secret, err := kubeAPI.GetSecret(ctx, controlPlaneNamespace, k8s.ProxyInjectorTLSSecretName)
+		switch {
+		case err == nil:
+			if config.ProxyInjectorTLS, err = proxyInjectorTLSFromSecret(secret); err != nil {
+				return nil, err
+			}
+		case !k8s.IsNotFound(err):
+			return nil, fmt.Errorf("could not read the certificate of the proxy injector: %v", err)
+		}
+	}
+	return config, nil
+}
+
+// setInstalledFlags sets the flags that were not set on the command line to
+// the values the control plane was installed with. Installed flags that this
+// version of the CLI no longer has are reported to stderr and skipped.
+func setInstalledFlags(flags *pflag.FlagSet, installed *k8s.InstallOptions, stderr io.Writer) error {
+	for _, flag := range installed.Flags {
+		if upgradeResetFlags[flag.Name] || flags.Changed(flag.Name) {
+			continue
+		}
+		if flags.Lookup(flag.Name) == nil {
+			fmt.Fprintf(stderr, "Ignoring --%s=%s, which the control plane was installed with, as it is no longer supported\n", flag.Name, flag.Value)
+			continue
+		}
+		if err := flags.Set(flag.Name, flag.Value); err != nil {
+			return fmt.Errorf("invalid installed value for --%s: %v", flag.Name, err)
+		}
+	}
+	return nil
+}
+
+// renderUpgrade renders the configs of the upgraded control plane like
+// render. The Secret of the proxy injector is rendered again with the
+// certificate upgradeConfig read from the installed one, if any. The template
+// renders no other Secret; any added to it later is left out, so that applying
+// the configs never replaces certificates and keys the control plane already
+// uses.
+func renderUpgrade(config installConfig, w io.Writer, options *installOptions) error {
+	buf := &bytes.Buffer{}
+	if err := render(config, buf, options); err != nil {
+		return err
+	}
+	files, err := splitManifests(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if strings.HasPrefix(file.name, "secret-") && file.name != proxyInjectorSecretFile {
+			continue
+		}
+		if _, err := w.Write(file.content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// fakeAPIServer is a fake API server serving objects as JSON, by the path of
+// the request, and NotFound for any other path.
+func fakeAPIServer(t *testing.T, objects map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		obj, ok := objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","reason":"NotFound","code":404}`))
+			return
+		}
+		body, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		w.Write(body)
+	}))
+}
+
+// installConfigMap returns the objects of a control plane in the linkerd
+// namespace installed with the given options, or with none recorded if they
+// are empty, for fakeAPIServer.
+func installConfigMap(installOptions string) map[string]interface{} {
+	if installOptions == "" {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"/api/v1/namespaces/linkerd/configmaps/linkerd-config": v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: k8s.InstallConfigMapName, Namespace: "linkerd"},
+			Data:       map[string]string{k8s.InstallOptionsKey: installOptions},
+		},
+	}
+}
+
+// installConfigServer is a fake API server for a control plane in the linkerd
+// namespace installed with the given options, or with none recorded if they
+// are empty.
+func installConfigServer(t *testing.T, installOptions string) *httptest.Server {
+	return fakeAPIServer(t, installConfigMap(installOptions))
+}
+
+// parseUpgradeFlags returns the flags and options of `linkerd upgrade`, with
+// args parsed. They describe the control plane as those of `linkerd install`
+// do.
+func parseUpgradeFlags(t *testing.T, args ...string) (*pflag.FlagSet, *upgradeOptions) {
+	options := newUpgradeOptions()
+	cmd := &cobra.Command{}
+	addInstallFlags(cmd, options.installOptions)
+	if err := cmd.PersistentFlags().Parse(args); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return cmd.PersistentFlags(), options
+}
+
+func TestUpgradeConfig(t *testing.T) {
+	defaultControlPlaneNamespace := controlPlaneNamespace
+	controlPlaneNamespace = "linkerd"
+	defer func() { controlPlaneNamespace = defaultControlPlaneNamespace }()
+
+	t.Run("Renders the configs the control plane was installed with", func(t *testing.T) {
+		installFlags, installOptions := parseUpgradeFlags(t,
+			"--ha",
+			"--controller-replicas=2",
+			"--registry=registry.example.com:5000/linkerd-io",
+			"--tls=optional",
+			"--control-plane-node-selector=beta.kubernetes.io/arch=amd64,disk=ssd",
+			"--proxy-memory-limit=256Mi",
+		)
+		installOptions.recordedFlags = recordFlags(installFlags)
+		installConfig, err := validateAndBuildConfig(installOptions.installOptions)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		installConfig.UUID = "deaab91a-f4ab-448a-b7d1-c832a2fa0a60"
+
+		server := installConfigServer(t, installConfig.InstallOptions)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		flags, options := parseUpgradeFlags(t)
+		var stderr bytes.Buffer
+		config, err := upgradeConfig(context.Background(), kubeAPI, flags, &stderr, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		config.UUID = installConfig.UUID
+		if config.InstallOptions != installConfig.InstallOptions {
+			t.Fatalf("Expected install options [%s] to be recorded again, got [%s]", installConfig.InstallOptions, config.InstallOptions)
+		}
+		if stderr.Len() > 0 {
+			t.Fatalf("Unexpected output to stderr: %s", stderr.String())
+		}
+
+		var installed, upgraded bytes.Buffer
+		if err := render(*installConfig, &installed, installOptions.installOptions); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := renderUpgrade(*config, &upgraded, options.installOptions); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		goldenFileBytes, err := ioutil.ReadFile("testdata/upgrade_round_trip.golden")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		diffCompare(t, upgraded.String(), string(goldenFileBytes))
+		diffCompare(t, upgraded.String(), installed.String())
+	})
+
+	t.Run("Lets flags override the installed ones", func(t *testing.T) {
+		server := installConfigServer(t, `{"flags":[{"name":"controller-replicas","value":"2"},{"name":"ha","value":"true"}]}`)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		flags, options := parseUpgradeFlags(t, "--controller-replicas=5")
+		config, err := upgradeConfig(context.Background(), kubeAPI, flags, &bytes.Buffer{}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if config.ControllerReplicas != 5 || config.WebReplicas != defaultHAReplicas || !config.EnableHA {
+			t.Fatalf("Expected 5 controller and %d web replicas with HA, got %d, %d and %t", defaultHAReplicas, config.ControllerReplicas, config.WebReplicas, config.EnableHA)
+		}
+		expected := `{"flags":[{"name":"controller-replicas","value":"5"},{"name":"ha","value":"true"}]}`
+		if config.InstallOptions != expected {
+			t.Fatalf("Expected install options [%s], got [%s]", expected, config.InstallOptions)
+		}
+	})
+
+	t.Run("Moves to the images of this version", func(t *testing.T) {
+		server := installConfigServer(t, `{"flags":[{"name":"linkerd-version","value":"edge-18.9.1"}]}`)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		flags, options := parseUpgradeFlags(t)
+		config, err := upgradeConfig(context.Background(), kubeAPI, flags, &bytes.Buffer{}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.HasSuffix(config.ControllerImage, ":edge-18.9.1") {
+			t.Fatalf("Expected the installed version not to be kept, got [%s]", config.ControllerImage)
+		}
+		if config.InstallOptions != `{"flags":[]}` {
+			t.Fatalf("Expected no install options, got [%s]", config.InstallOptions)
+		}
+	})
+
+	t.Run("Skips installed flags that are no longer supported", func(t *testing.T) {
+		server := installConfigServer(t, `{"flags":[{"name":"ha","value":"true"},{"name":"legacy","value":"true"}]}`)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		flags, options := parseUpgradeFlags(t)
+		var stderr bytes.Buffer
+		config, err := upgradeConfig(context.Background(), kubeAPI, flags, &stderr, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !config.EnableHA {
+			t.Fatalf("Expected --ha to be kept")
+		}
+		expected := "Ignoring --legacy=true, which the control plane was installed with, as it is no longer supported\n"
+		if stderr.String() != expected {
+			t.Fatalf("Expected [%s] on stderr, got [%s]", expected, stderr.String())
+		}
+	})
+
+	t.Run("Refuses to upgrade without the installed options, unless forced", func(t *testing.T) {
+		server := installConfigServer(t, "")
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		flags, options := parseUpgradeFlags(t, "--ha")
+		_, err := upgradeConfig(context.Background(), kubeAPI, flags, &bytes.Buffer{}, options)
+		if err == nil || !strings.HasPrefix(err.Error(), "could not read the options of the control plane in \"linkerd\"") {
+			t.Fatalf("Expected an error reading the options, got [%v]", err)
+		}
+
+		options.force = true
+		var stderr bytes.Buffer
+		config, err := upgradeConfig(context.Background(), kubeAPI, flags, &stderr, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !config.EnableHA {
+			t.Fatalf("Expected the flags given to be used")
+		}
+		if !strings.HasPrefix(stderr.String(), "Upgrading with the flags given only") {
+			t.Fatalf("Expected a warning on stderr, got [%s]", stderr.String())
+		}
+	})
+
+	t.Run("Keeps the certificate of the proxy injector", func(t *testing.T) {
+		secretPath := "/api/v1/namespaces/linkerd/secrets/" + k8s.ProxyInjectorTLSSecretName
+		objects := installConfigMap(`{"flags":[{"name":"proxy-auto-inject","value":"true"}]}`)
+		objects[secretPath] = v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: k8s.ProxyInjectorTLSSecretName, Namespace: "linkerd"},
+			Data: map[string][]byte{
+				v1.TLSCertKey:                []byte("cert"),
+				v1.TLSPrivateKeyKey:          []byte("key"),
+				k8s.ProxyInjectorCABundleKey: []byte("ca"),
+			},
+		}
+		server := fakeAPIServer(t, objects)
+		defer server.Close()
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		flags, options := parseUpgradeFlags(t)
+		config, err := upgradeConfig(context.Background(), kubeAPI, flags, &bytes.Buffer{}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := proxyInjectorTLS{Cert: "Y2VydA==", Key: "a2V5", CABundle: "Y2E="}
+		if !config.ProxyAutoInject || *config.ProxyInjectorTLS != expected {
+			t.Fatalf("Expected the installed certificate %+v, got %+v", expected, config.ProxyInjectorTLS)
+		}
+
+		var upgraded bytes.Buffer
+		if err := renderUpgrade(*config, &upgraded, options.installOptions); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, expected := range []string{"name: " + k8s.ProxyInjectorTLSSecretName, "tls.crt: Y2VydA==", "caBundle: Y2E="} {
+			if !strings.Contains(upgraded.String(), expected) {
+				t.Fatalf("Expected [%s] in the upgraded configs, got:\n%s", expected, upgraded.String())
+			}
+		}
+
+		// Adding the proxy injector in an upgrade issues it a certificate.
+		delete(objects, secretPath)
+		flags, options = parseUpgradeFlags(t)
+		config, err = upgradeConfig(context.Background(), kubeAPI, flags, &bytes.Buffer{}, options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if *config.ProxyInjectorTLS == expected {
+			t.Fatalf("Expected a new certificate, got the installed one")
+		}
+	})
+}
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/linkerd/linkerd2/controller/api/public"
@@ -17,12 +19,14 @@ const DefaultVersionString = "unavailable"
 type versionOptions struct {
 	shortVersion      bool
 	onlyClientVersion bool
+	output            string
 }
 
 func newVersionOptions() *versionOptions {
 	return &versionOptions{
 		shortVersion:      false,
 		onlyClientVersion: false,
+		output:            tableOutput,
 	}
 }
 
@@ -32,53 +36,141 @@ func newCmdVersion() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print the client and server version information",
-		Run: func(cmd *cobra.Command, args []string) {
-			clientVersion := version.Version
-			if options.shortVersion {
-				fmt.Println(clientVersion)
-			} else {
-				fmt.Printf("Client version: %s\n", clientVersion)
-			}
+		Long: `Print the client and server version information.
 
-			client, err := newVersionClient()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error connecting to server: %s\n", err)
-				os.Exit(1)
-			}
+The server version is that of the control plane in --linkerd-namespace, queried
+through the Kubernetes API for at most --api-timeout; if it cannot be reached,
+the server version is printed as "unavailable", and the error reported on
+stderr. --client skips the query. With --output json, the versions are
+printed as a JSON object, whose serverError is set to the error instead.`,
+		Example: `  # Print the version of the CLI only, as a bare string
+  linkerd version --client --short
 
-			if !options.onlyClientVersion {
-				serverVersion := getServerVersion(client)
-				if options.shortVersion {
-					fmt.Println(serverVersion)
-				} else {
-					fmt.Printf("Server version: %s\n", serverVersion)
-				}
+  # Print the versions for use by scripts
+  linkerd version -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if options.output != tableOutput && options.output != jsonOutput {
+				return fmt.Errorf("output format \"%s\" not recognized, must be one of: %s, %s", options.output, tableOutput, jsonOutput)
 			}
+			if options.shortVersion && options.output == jsonOutput {
+				return fmt.Errorf("--short and --output %s are mutually exclusive", jsonOutput)
+			}
+
+			ctx, cancel := newSignalContext()
+			defer cancel()
+
+			return runVersion(ctx, os.Stdout, os.Stderr, options, newVersionClient)
 		},
 	}
 
-	cmd.Args = cobra.NoArgs
 	cmd.PersistentFlags().BoolVar(&options.shortVersion, "short", options.shortVersion, "Print the version number(s) only, with no additional output")
-	cmd.PersistentFlags().BoolVar(&options.onlyClientVersion, "client", options.onlyClientVersion, "Print the client version only")
+	cmd.PersistentFlags().BoolVar(&options.onlyClientVersion, "client", options.onlyClientVersion, "Print the client version only, without querying the control plane")
+	cmd.PersistentFlags().StringVarP(&options.output, "output", "o", options.output, "Output format. One of: table, json")
 
 	return cmd
 }
 
-func getServerVersion(client pb.ApiClient) string {
-	resp, err := client.Version(context.Background(), &pb.Empty{})
+// jsonVersion is the output of version with --output json. ServerVersion and
+// ServerError are omitted with --client.
+type jsonVersion struct {
+	ClientVersion string `json:"clientVersion"`
+	ServerVersion string `json:"serverVersion,omitempty"`
+	ServerError   string `json:"serverError,omitempty"`
+}
+
+// runVersion writes the client version to w, followed, unless
+// options.onlyClientVersion is set, by the version of the server that
+// newClient connects to. The server being unreachable does not fail the
+// command: its version is "unavailable", and the error is reported on stderr,
+// or, with --output json, in serverError.
+func runVersion(ctx context.Context, w, stderr io.Writer, options *versionOptions, newClient func() (pb.ApiClient, error)) error {
+	clientVersion := version.Version
+
+	var serverVersion string
+	var serverErr error
+	if !options.onlyClientVersion {
+		serverVersion, serverErr = getServerVersion(ctx, newClient)
+	}
+
+	if options.output == jsonOutput {
+		out := jsonVersion{ClientVersion: clientVersion, ServerVersion: serverVersion}
+		if serverErr != nil {
+			out.ServerError = serverErr.Error()
+		}
+		b, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+		return nil
+	}
+
+	if options.shortVersion {
+		fmt.Fprintln(w, clientVersion)
+	} else {
+		fmt.Fprintf(w, "Client version: %s\n", clientVersion)
+	}
+	if options.onlyClientVersion {
+		return nil
+	}
+
+	if serverErr != nil {
+		serverVersion = DefaultVersionString
+		fmt.Fprintf(stderr, "Error getting the server version: %s\n", serverErr)
+	}
+	if options.shortVersion {
+		fmt.Fprintln(w, serverVersion)
+	} else {
+		fmt.Fprintf(w, "Server version: %s\n", serverVersion)
+	}
+
+	if serverErr == nil {
+		printVersionSkew(stderr, version.GetSkew(clientVersion, serverVersion))
+	}
+	return nil
+}
+
+// getServerVersion returns the release version of the control plane, queried
+// with the client returned by newClient, for at most --api-timeout.
+func getServerVersion(ctx context.Context, newClient func() (pb.ApiClient, error)) (string, error) {
+	client, err := newClient()
 	if err != nil {
-		return DefaultVersionString
+		return "", fmt.Errorf("error connecting to server: %s", err)
 	}
 
-	return resp.GetReleaseVersion()
+	timeout := apiTimeout
+	if timeout <= 0 {
+		timeout = k8s.DefaultRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := client.Version(ctx, &pb.Empty{})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetReleaseVersion(), nil
+}
+
+// printVersionSkew reports incompatible or differing CLI and control plane
+// versions to w.
+func printVersionSkew(w io.Writer, skew *version.Skew) {
+	if err := skew.Err(); err != nil {
+		fmt.Fprintf(w, "Error: %s\n", err)
+	} else if warning := skew.Warning(); warning != "" {
+		fmt.Fprintf(w, "Warning: %s\n", warning)
+	}
 }
 
-// This client does not do any validation
+// newVersionClient returns a client of the public API of the control plane in
+// --linkerd-namespace, through the API server's service proxy, or of the one
+// served at --api-addr. This client does not do any validation.
 func newVersionClient() (pb.ApiClient, error) {
 	if apiAddr != "" {
 		return public.NewInternalClient(controlPlaneNamespace, apiAddr)
 	}
-	kubeAPI, err := k8s.NewAPI(kubeconfigPath)
+	kubeAPI, err := k8s.NewAPI(kubeconfigPath, kubeAPIOptions())
 	if err != nil {
 		return nil, err
 	}
@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/linkerd/linkerd2/controller/api/public"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/version"
+	"k8s.io/client-go/rest"
 )
 
 func TestGetServerVersion(t *testing.T) {
@@ -16,7 +24,10 @@ func TestGetServerVersion(t *testing.T) {
 			ReleaseVersion: expectedServerVersion,
 		}
 
-		version := getServerVersion(mockClient)
+		version, err := getServerVersion(context.Background(), mockVersionClient(mockClient))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
 
 		if version != expectedServerVersion {
 			t.Fatalf("Expected server version to be [%s], was [%s]",
@@ -24,16 +35,190 @@ func TestGetServerVersion(t *testing.T) {
 		}
 	})
 
-	t.Run("Returns unavailable when cannot get server version", func(t *testing.T) {
-		expectedServerVersion := "unavailable"
+	t.Run("Returns an error when cannot get server version", func(t *testing.T) {
 		mockClient := &public.MockApiClient{}
 		mockClient.ErrorToReturn = errors.New("expected")
 
-		version := getServerVersion(mockClient)
+		_, err := getServerVersion(context.Background(), mockVersionClient(mockClient))
+		if err == nil || err.Error() != "expected" {
+			t.Fatalf("Expected error [expected], got [%v]", err)
+		}
+	})
 
-		if version != expectedServerVersion {
-			t.Fatalf("Expected server version to be [%s], was [%s]",
-				expectedServerVersion, version)
+	t.Run("Queries the server through the API server's service proxy for at most --api-timeout", func(t *testing.T) {
+		defaultAPITimeout := apiTimeout
+		apiTimeout = 50 * time.Millisecond
+		defer func() { apiTimeout = defaultAPITimeout }()
+
+		paths := make(chan string, 1)
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths <- r.URL.Path
+			<-done
+		}))
+		defer server.Close()
+		defer close(done)
+
+		kubeAPI := &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+		newClient := func() (pb.ApiClient, error) { return public.NewExternalClient("linkerd-test", kubeAPI) }
+
+		start := time.Now()
+		_, err := getServerVersion(context.Background(), newClient)
+		if err == nil {
+			t.Fatalf("Expected the request to time out")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("Expected the request to time out after --api-timeout, took %s", elapsed)
+		}
+		expectedPath := "/api/v1/namespaces/linkerd-test/services/http:api:http/proxy/api/v1/Version"
+		if path := <-paths; path != expectedPath {
+			t.Fatalf("Expected request to [%s], got [%s]", expectedPath, path)
 		}
 	})
 }
+
+func mockVersionClient(client pb.ApiClient) func() (pb.ApiClient, error) {
+	return func() (pb.ApiClient, error) { return client, nil }
+}
+
+func TestRunVersion(t *testing.T) {
+	defaultVersion := version.Version
+	version.Version = "stable-2.1.0"
+	defer func() { version.Version = defaultVersion }()
+
+	reachable := &public.MockApiClient{VersionInfoToReturn: &pb.VersionInfo{ReleaseVersion: "stable-2.2.0"}}
+	unreachable := &public.MockApiClient{ErrorToReturn: errors.New("connection refused")}
+	noClient := func() (pb.ApiClient, error) { return nil, errors.New("no kubeconfig found") }
+
+	testCases := []struct {
+		name           string
+		short          bool
+		client         bool
+		output         string
+		newClient      func() (pb.ApiClient, error)
+		expectedOutput string
+		expectedStderr string
+	}{
+		{
+			name:           "Prints both versions",
+			output:         tableOutput,
+			newClient:      mockVersionClient(reachable),
+			expectedOutput: "Client version: stable-2.1.0\nServer version: stable-2.2.0\n",
+			expectedStderr: "Warning: cli is running version stable-2.1.0 but the control plane is running version stable-2.2.0\n",
+		},
+		{
+			name:           "Prints both versions only with --short",
+			short:          true,
+			output:         tableOutput,
+			newClient:      mockVersionClient(reachable),
+			expectedOutput: "stable-2.1.0\nstable-2.2.0\n",
+			expectedStderr: "Warning: cli is running version stable-2.1.0 but the control plane is running version stable-2.2.0\n",
+		},
+		{
+			name:           "Prints the client version with --client",
+			client:         true,
+			output:         tableOutput,
+			expectedOutput: "Client version: stable-2.1.0\n",
+		},
+		{
+			name:           "Prints the client version only with --client --short",
+			client:         true,
+			short:          true,
+			output:         tableOutput,
+			expectedOutput: "stable-2.1.0\n",
+		},
+		{
+			name:           "Prints both versions as JSON with -o json",
+			output:         jsonOutput,
+			newClient:      mockVersionClient(reachable),
+			expectedOutput: "{\n  \"clientVersion\": \"stable-2.1.0\",\n  \"serverVersion\": \"stable-2.2.0\"\n}\n",
+		},
+		{
+			name:           "Prints the client version as JSON with -o json --client",
+			client:         true,
+			output:         jsonOutput,
+			expectedOutput: "{\n  \"clientVersion\": \"stable-2.1.0\"\n}\n",
+		},
+		{
+			name:           "Prints the server version as unavailable when unreachable",
+			output:         tableOutput,
+			newClient:      mockVersionClient(unreachable),
+			expectedOutput: "Client version: stable-2.1.0\nServer version: unavailable\n",
+			expectedStderr: "Error getting the server version: connection refused\n",
+		},
+		{
+			name:           "Prints the server version as unavailable when unreachable with --short",
+			short:          true,
+			output:         tableOutput,
+			newClient:      noClient,
+			expectedOutput: "stable-2.1.0\nunavailable\n",
+			expectedStderr: "Error getting the server version: error connecting to server: no kubeconfig found\n",
+		},
+		{
+			name:           "Reports the server error in JSON when unreachable with -o json",
+			output:         jsonOutput,
+			newClient:      mockVersionClient(unreachable),
+			expectedOutput: "{\n  \"clientVersion\": \"stable-2.1.0\",\n  \"serverError\": \"connection refused\"\n}\n",
+		},
+		{
+			name:           "Reports the client error in JSON when unreachable with -o json",
+			output:         jsonOutput,
+			newClient:      noClient,
+			expectedOutput: "{\n  \"clientVersion\": \"stable-2.1.0\",\n  \"serverError\": \"error connecting to server: no kubeconfig found\"\n}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // pin
+		t.Run(tc.name, func(t *testing.T) {
+			options := newVersionOptions()
+			options.shortVersion = tc.short
+			options.onlyClientVersion = tc.client
+			options.output = tc.output
+
+			newClient := tc.newClient
+			if newClient == nil {
+				newClient = func() (pb.ApiClient, error) {
+					t.Fatalf("Unexpected query of the server version")
+					return nil, nil
+				}
+			}
+
+			var out, stderr bytes.Buffer
+			if err := runVersion(context.Background(), &out, &stderr, options, newClient); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if out.String() != tc.expectedOutput {
+				t.Fatalf("Wrong output:\n expected: \n%s\n, got: \n%s", tc.expectedOutput, out.String())
+			}
+			if stderr.String() != tc.expectedStderr {
+				t.Fatalf("Expected stderr [%s], got [%s]", tc.expectedStderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestPrintVersionSkew(t *testing.T) {
+	testCases := map[string]struct {
+		clientVersion string
+		serverVersion string
+		expected      string
+	}{
+		"same versions":       {"stable-2.0.0", "stable-2.0.0", ""},
+		"different minors":    {"stable-2.0.0", "stable-2.1.0", "Warning: cli is running version stable-2.0.0 but the control plane is running version stable-2.1.0\n"},
+		"different majors":    {"stable-2.0.0", "stable-3.0.0", "Error: cli is running version stable-2.0.0 but the control plane is running version stable-3.0.0, which is incompatible\n"},
+		"different channels":  {"edge-18.8.1", "stable-2.0.0", "Warning: cli is running version edge-18.8.1 but the control plane is running version stable-2.0.0, from a different release channel\n"},
+		"development version": {"dev-0e7a9b3e-jane", "stable-3.0.0", ""},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			output := bytes.NewBufferString("")
+			printVersionSkew(output, version.GetSkew(tc.clientVersion, tc.serverVersion))
+
+			if output.String() != tc.expected {
+				t.Fatalf("Expected output [%s], got [%s]", tc.expected, output.String())
+			}
+		})
+	}
+}
@@ -1,11 +1,35 @@
 package install
 
 // Template provides the base template for the `linkerd install` command.
-const Template = `### Namespace ###
+const Template = `{{- if not .SingleNamespace -}}
+### Namespace ###
 kind: Namespace
 apiVersion: v1
 metadata:
   name: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+
+{{ end -}}
+### Install Options ###
+---
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: {{.InstallConfigMapName}}
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+    {{.ControllerComponentLabel}}: controller
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+data:
+  install: |
+    {{.InstallOptions}}
+  {{- if .ProxyAutoInject }}
+  {{.ProxyConfigKey}}: |
+    {{.ProxyConfig}}
+  {{- end }}
 
 ### Service Account Controller ###
 ---
@@ -14,26 +38,77 @@ apiVersion: v1
 metadata:
   name: linkerd-controller
   namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
 
 ### Controller RBAC ###
 ---
+{{- if .SingleNamespace }}
+kind: Role
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-controller
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+rules:
+- apiGroups: ["extensions", "apps"]
+  resources: ["deployments", "replicasets"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["batch"]
+  resources: ["jobs"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: [""]
+  resources: ["pods", "endpoints", "services", "replicationcontrollers"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["linkerd.io"]
+  resources: ["serviceprofiles"]
+  verbs: ["list", "get", "watch"]
+
+---
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-controller
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: linkerd-controller
+subjects:
+- kind: ServiceAccount
+  name: linkerd-controller
+  namespace: {{.Namespace}}
+{{- else }}
 kind: ClusterRole
 apiVersion: rbac.authorization.k8s.io/v1beta1
 metadata:
   name: linkerd-{{.Namespace}}-controller
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
 rules:
 - apiGroups: ["extensions", "apps"]
   resources: ["deployments", "replicasets"]
   verbs: ["list", "get", "watch"]
+- apiGroups: ["batch"]
+  resources: ["jobs"]
+  verbs: ["list", "get", "watch"]
 - apiGroups: [""]
   resources: ["pods", "endpoints", "services", "namespaces", "replicationcontrollers"]
   verbs: ["list", "get", "watch"]
+- apiGroups: ["linkerd.io"]
+  resources: ["serviceprofiles"]
+  verbs: ["list", "get", "watch"]
 
 ---
 kind: ClusterRoleBinding
 apiVersion: rbac.authorization.k8s.io/v1beta1
 metadata:
   name: linkerd-{{.Namespace}}-controller
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
 roleRef:
   apiGroup: rbac.authorization.k8s.io
   kind: ClusterRole
@@ -42,6 +117,52 @@ subjects:
 - kind: ServiceAccount
   name: linkerd-controller
   namespace: {{.Namespace}}
+{{- end }}
+{{- if not .SingleNamespace }}
+
+### Service Profile CRD ###
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: serviceprofiles.linkerd.io
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+spec:
+  group: linkerd.io
+  version: v1alpha1
+  scope: Namespaced
+  names:
+    plural: serviceprofiles
+    singular: serviceprofile
+    kind: ServiceProfile
+    shortNames:
+    - sp
+  validation:
+    openAPIV3Schema:
+      properties:
+        spec:
+          required:
+          - routes
+          properties:
+            routes:
+              type: array
+              items:
+                type: object
+                required:
+                - name
+                - condition
+                properties:
+                  name:
+                    type: string
+                  condition:
+                    type: object
+                    properties:
+                      pathRegex:
+                        type: string
+                      method:
+                        type: string
+{{- end }}
 
 ### Service Account Prometheus ###
 ---
@@ -50,13 +171,47 @@ apiVersion: v1
 metadata:
   name: linkerd-prometheus
   namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
 
 ### Prometheus RBAC ###
 ---
+{{- if .SingleNamespace }}
+kind: Role
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-prometheus
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["get", "list", "watch"]
+
+---
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-prometheus
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: linkerd-prometheus
+subjects:
+- kind: ServiceAccount
+  name: linkerd-prometheus
+  namespace: {{.Namespace}}
+{{- else }}
 kind: ClusterRole
 apiVersion: rbac.authorization.k8s.io/v1beta1
 metadata:
   name: linkerd-{{.Namespace}}-prometheus
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
 rules:
 - apiGroups: [""]
   resources: ["pods"]
@@ -67,6 +222,8 @@ kind: ClusterRoleBinding
 apiVersion: rbac.authorization.k8s.io/v1beta1
 metadata:
   name: linkerd-{{.Namespace}}-prometheus
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
 roleRef:
   apiGroup: rbac.authorization.k8s.io
   kind: ClusterRole
@@ -75,6 +232,89 @@ subjects:
 - kind: ServiceAccount
   name: linkerd-prometheus
   namespace: {{.Namespace}}
+{{- end }}
+{{- if .EnablePSP }}
+
+### Pod Security Policy ###
+---
+kind: PodSecurityPolicy
+apiVersion: policy/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-control-plane
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+    {{.ControllerComponentLabel}}: controller
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  privileged: false
+  # proxy-init configures iptables to redirect traffic through the proxy.
+  allowedCapabilities:
+  - NET_ADMIN
+  allowPrivilegeEscalation: false
+  hostNetwork: false
+  hostIPC: false
+  hostPID: false
+  readOnlyRootFilesystem: false
+  runAsUser:
+    rule: RunAsAny
+  seLinux:
+    rule: RunAsAny
+  supplementalGroups:
+    rule: RunAsAny
+  fsGroup:
+    rule: RunAsAny
+  volumes:
+  - configMap
+  - secret
+
+---
+kind: Role
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-psp
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+rules:
+- apiGroups: ["policy", "extensions"]
+  resources: ["podsecuritypolicies"]
+  resourceNames: ["linkerd-{{.Namespace}}-control-plane"]
+  verbs: ["use"]
+
+---
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-psp
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: linkerd-psp
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: {{.Namespace}}
+- kind: ServiceAccount
+  name: linkerd-controller
+  namespace: {{.Namespace}}
+- kind: ServiceAccount
+  name: linkerd-prometheus
+  namespace: {{.Namespace}}
+{{- if .EnableTLS }}
+- kind: ServiceAccount
+  name: linkerd-ca
+  namespace: {{.Namespace}}
+{{- end }}
+{{- if .ProxyAutoInject }}
+- kind: ServiceAccount
+  name: linkerd-proxy-injector
+  namespace: {{.Namespace}}
+{{- end }}
+{{- end }}
 
 ### Controller ###
 ---
@@ -84,6 +324,7 @@ metadata:
   name: api
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: controller
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -103,6 +344,7 @@ metadata:
   name: proxy-api
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: controller
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -122,6 +364,7 @@ metadata:
   name: controller
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: controller
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -135,6 +378,34 @@ spec:
         {{.CreatedByAnnotation}}: {{.CliVersion}}
     spec:
       serviceAccount: linkerd-controller
+      {{- if .EnableHA }}
+      affinity:
+        podAntiAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+          - labelSelector:
+              matchLabels:
+                {{.ControllerComponentLabel}}: controller
+            topologyKey: kubernetes.io/hostname
+      {{- end }}
+      {{- with .NodeSelector }}
+      nodeSelector:
+        {{- range $key, $value := . }}
+        {{ $key }}: {{ printf "%q" $value }}
+        {{- end }}
+      {{- end }}
+      {{- with .Tolerations }}
+      tolerations:
+      {{- range . }}
+      - key: {{ .Key }}
+        operator: {{ .Operator }}
+        {{- if .Value }}
+        value: {{ printf "%q" .Value }}
+        {{- end }}
+        {{- if .Effect }}
+        effect: {{ .Effect }}
+        {{- end }}
+      {{- end }}
+      {{- end }}
       containers:
       - name: public-api
         ports:
@@ -149,6 +420,9 @@ spec:
         - "-prometheus-url=http://prometheus.{{.Namespace}}.svc.cluster.local:9090"
         - "-controller-namespace={{.Namespace}}"
         - "-log-level={{.ControllerLogLevel}}"
+        {{- if .SingleNamespace }}
+        - "-single-namespace"
+        {{- end }}
         livenessProbe:
           httpGet:
             path: /ping
@@ -159,6 +433,12 @@ spec:
             path: /ready
             port: 9995
           failureThreshold: 7
+        {{- if .EnableHA }}
+        resources:
+          requests:
+            cpu: 20m
+            memory: 50Mi
+        {{- end }}
       - name: destination
         ports:
         - name: grpc
@@ -171,6 +451,10 @@ spec:
         - "destination"
         - "-enable-tls={{.EnableTLS}}"
         - "-log-level={{.ControllerLogLevel}}"
+        {{- if .SingleNamespace }}
+        - "-controller-namespace={{.Namespace}}"
+        - "-single-namespace"
+        {{- end }}
         livenessProbe:
           httpGet:
             path: /ping
@@ -181,6 +465,12 @@ spec:
             path: /ready
             port: 9999
           failureThreshold: 7
+        {{- if .EnableHA }}
+        resources:
+          requests:
+            cpu: 20m
+            memory: 50Mi
+        {{- end }}
       - name: proxy-api
         ports:
         - name: grpc
@@ -203,6 +493,12 @@ spec:
             path: /ready
             port: 9996
           failureThreshold: 7
+        {{- if .EnableHA }}
+        resources:
+          requests:
+            cpu: 20m
+            memory: 50Mi
+        {{- end }}
       - name: tap
         ports:
         - name: grpc
@@ -215,6 +511,9 @@ spec:
         - "tap"
         - "-log-level={{.ControllerLogLevel}}"
         - "-controller-namespace={{.Namespace}}"
+        {{- if .SingleNamespace }}
+        - "-single-namespace"
+        {{- end }}
         livenessProbe:
           httpGet:
             path: /ping
@@ -225,6 +524,31 @@ spec:
             path: /ready
             port: 9998
           failureThreshold: 7
+        {{- if .EnableHA }}
+        resources:
+          requests:
+            cpu: 20m
+            memory: 50Mi
+        {{- end }}
+{{- if .EnableHA }}
+
+---
+kind: PodDisruptionBudget
+apiVersion: policy/v1beta1
+metadata:
+  name: controller
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+    {{.ControllerComponentLabel}}: controller
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  maxUnavailable: 1
+  selector:
+    matchLabels:
+      {{.ControllerComponentLabel}}: controller
+{{- end }}
 
 ### Web ###
 ---
@@ -234,6 +558,7 @@ metadata:
   name: web
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: web
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -256,6 +581,7 @@ metadata:
   name: web
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: web
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -268,6 +594,34 @@ spec:
       annotations:
         {{.CreatedByAnnotation}}: {{.CliVersion}}
     spec:
+      {{- if .EnableHA }}
+      affinity:
+        podAntiAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+          - labelSelector:
+              matchLabels:
+                {{.ControllerComponentLabel}}: web
+            topologyKey: kubernetes.io/hostname
+      {{- end }}
+      {{- with .NodeSelector }}
+      nodeSelector:
+        {{- range $key, $value := . }}
+        {{ $key }}: {{ printf "%q" $value }}
+        {{- end }}
+      {{- end }}
+      {{- with .Tolerations }}
+      tolerations:
+      {{- range . }}
+      - key: {{ .Key }}
+        operator: {{ .Operator }}
+        {{- if .Value }}
+        value: {{ printf "%q" .Value }}
+        {{- end }}
+        {{- if .Effect }}
+        effect: {{ .Effect }}
+        {{- end }}
+      {{- end }}
+      {{- end }}
       containers:
       - name: web
         ports:
@@ -294,6 +648,31 @@ spec:
             path: /ready
             port: 9994
           failureThreshold: 7
+        {{- if .EnableHA }}
+        resources:
+          requests:
+            cpu: 20m
+            memory: 50Mi
+        {{- end }}
+{{- if .EnableHA }}
+
+---
+kind: PodDisruptionBudget
+apiVersion: policy/v1beta1
+metadata:
+  name: web
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+    {{.ControllerComponentLabel}}: web
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  maxUnavailable: 1
+  selector:
+    matchLabels:
+      {{.ControllerComponentLabel}}: web
+{{- end }}
 
 ### Prometheus ###
 ---
@@ -303,6 +682,7 @@ metadata:
   name: prometheus
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: prometheus
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -322,6 +702,7 @@ metadata:
   name: prometheus
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: prometheus
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -339,6 +720,25 @@ spec:
       - name: prometheus-config
         configMap:
           name: prometheus-config
+      {{- with .NodeSelector }}
+      nodeSelector:
+        {{- range $key, $value := . }}
+        {{ $key }}: {{ printf "%q" $value }}
+        {{- end }}
+      {{- end }}
+      {{- with .Tolerations }}
+      tolerations:
+      {{- range . }}
+      - key: {{ .Key }}
+        operator: {{ .Operator }}
+        {{- if .Value }}
+        value: {{ printf "%q" .Value }}
+        {{- end }}
+        {{- if .Effect }}
+        effect: {{ .Effect }}
+        {{- end }}
+      {{- end }}
+      {{- end }}
       containers:
       - name: prometheus
         ports:
@@ -365,6 +765,12 @@ spec:
             port: 9090
           initialDelaySeconds: 30
           timeoutSeconds: 30
+        {{- if .EnableHA }}
+        resources:
+          requests:
+            cpu: 300m
+            memory: 300Mi
+        {{- end }}
 
 ---
 kind: ConfigMap
@@ -373,6 +779,7 @@ metadata:
   name: prometheus-config
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: prometheus
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -417,6 +824,10 @@ data:
     - job_name: 'linkerd-proxy'
       kubernetes_sd_configs:
       - role: pod
+        {{- if .SingleNamespace }}
+        namespaces:
+          names: ['{{.Namespace}}']
+        {{- end }}
       relabel_configs:
       - source_labels:
         - __meta_kubernetes_pod_container_name
@@ -457,6 +868,7 @@ metadata:
   name: grafana
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: grafana
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -476,6 +888,7 @@ metadata:
   name: grafana
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: grafana
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -499,6 +912,25 @@ spec:
             path: provisioning/datasources/datasources.yaml
           - key: dashboards.yaml
             path: provisioning/dashboards/dashboards.yaml
+      {{- with .NodeSelector }}
+      nodeSelector:
+        {{- range $key, $value := . }}
+        {{ $key }}: {{ printf "%q" $value }}
+        {{- end }}
+      {{- end }}
+      {{- with .Tolerations }}
+      tolerations:
+      {{- range . }}
+      - key: {{ .Key }}
+        operator: {{ .Operator }}
+        {{- if .Value }}
+        value: {{ printf "%q" .Value }}
+        {{- end }}
+        {{- if .Effect }}
+        effect: {{ .Effect }}
+        {{- end }}
+      {{- end }}
+      {{- end }}
       containers:
       - name: grafana
         ports:
@@ -522,6 +954,12 @@ spec:
           timeoutSeconds: 30
           failureThreshold: 10
           periodSeconds: 10
+        {{- if .EnableHA }}
+        resources:
+          requests:
+            cpu: 20m
+            memory: 50Mi
+        {{- end }}
 
 ---
 kind: ConfigMap
@@ -530,6 +968,7 @@ metadata:
   name: grafana-config
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: grafana
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -589,13 +1028,27 @@ apiVersion: v1
 metadata:
   name: linkerd-ca
   namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
 
 ### CA RBAC ###
 ---
+{{- if .SingleNamespace }}
+kind: Role
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-ca
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+{{- else }}
 kind: ClusterRole
 apiVersion: rbac.authorization.k8s.io/v1beta1
 metadata:
   name: linkerd-{{.Namespace}}-ca
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+{{- end }}
 rules:
 - apiGroups: [""]
   resources: ["configmaps"]
@@ -610,19 +1063,38 @@ rules:
 - apiGroups: ["extensions", "apps"]
   resources: ["replicasets"]
   verbs: ["list", "get", "watch"]
+- apiGroups: ["batch"]
+  resources: ["jobs"]
+  verbs: ["list", "get", "watch"]
 - apiGroups: [""]
   resources: ["secrets"]
   verbs: ["create", "update"]
 
 ---
+{{- if .SingleNamespace }}
+kind: RoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-ca
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: linkerd-ca
+{{- else }}
 kind: ClusterRoleBinding
 apiVersion: rbac.authorization.k8s.io/v1beta1
 metadata:
   name: linkerd-{{.Namespace}}-ca
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
 roleRef:
   apiGroup: rbac.authorization.k8s.io
   kind: ClusterRole
   name: linkerd-{{.Namespace}}-ca
+{{- end }}
 subjects:
 - kind: ServiceAccount
   name: linkerd-ca
@@ -636,6 +1108,7 @@ metadata:
   name: ca
   namespace: {{.Namespace}}
   labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
     {{.ControllerComponentLabel}}: ca
   annotations:
     {{.CreatedByAnnotation}}: {{.CliVersion}}
@@ -649,6 +1122,34 @@ spec:
         {{.CreatedByAnnotation}}: {{.CliVersion}}
     spec:
       serviceAccount: linkerd-ca
+      {{- if .EnableHA }}
+      affinity:
+        podAntiAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+          - labelSelector:
+              matchLabels:
+                {{.ControllerComponentLabel}}: ca
+            topologyKey: kubernetes.io/hostname
+      {{- end }}
+      {{- with .NodeSelector }}
+      nodeSelector:
+        {{- range $key, $value := . }}
+        {{ $key }}: {{ printf "%q" $value }}
+        {{- end }}
+      {{- end }}
+      {{- with .Tolerations }}
+      tolerations:
+      {{- range . }}
+      - key: {{ .Key }}
+        operator: {{ .Operator }}
+        {{- if .Value }}
+        value: {{ printf "%q" .Value }}
+        {{- end }}
+        {{- if .Effect }}
+        effect: {{ .Effect }}
+        {{- end }}
+      {{- end }}
+      {{- end }}
       containers:
       - name: ca
         ports:
@@ -660,6 +1161,9 @@ spec:
         - "ca"
         - "-controller-namespace={{.Namespace}}"
         - "-log-level={{.ControllerLogLevel}}"
+        {{- if .SingleNamespace }}
+        - "-single-namespace"
+        {{- end }}
         livenessProbe:
           httpGet:
             path: /ping
@@ -670,4 +1174,222 @@ spec:
             path: /ready
             port: 9997
           failureThreshold: 7
+        {{- if .EnableHA }}
+        resources:
+          requests:
+            cpu: 20m
+            memory: 50Mi
+        {{- end }}
+`
+
+// ProxyInjectorTemplate provides the template of the proxy injector webhook,
+// rendered by `linkerd install --proxy-auto-inject`.
+const ProxyInjectorTemplate = `
+### Service Account Proxy Injector ###
+---
+kind: ServiceAccount
+apiVersion: v1
+metadata:
+  name: linkerd-proxy-injector
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+
+### Proxy Injector RBAC ###
+---
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-proxy-injector
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+rules:
+- apiGroups: [""]
+  resources: ["namespaces"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["extensions", "apps"]
+  resources: ["replicasets"]
+  verbs: ["list", "get", "watch"]
+- apiGroups: ["batch"]
+  resources: ["jobs"]
+  verbs: ["list", "get", "watch"]
+
+---
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1beta1
+metadata:
+  name: linkerd-{{.Namespace}}-proxy-injector
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: linkerd-{{.Namespace}}-proxy-injector
+subjects:
+- kind: ServiceAccount
+  name: linkerd-proxy-injector
+  namespace: {{.Namespace}}
+
+### Proxy Injector ###
+---
+kind: Service
+apiVersion: v1
+metadata:
+  name: {{.ProxyInjectorServiceName}}
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+    {{.ControllerComponentLabel}}: proxy-injector
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  type: ClusterIP
+  selector:
+    {{.ControllerComponentLabel}}: proxy-injector
+  ports:
+  - name: webhook
+    port: 443
+    targetPort: 8443
+
+---
+kind: Deployment
+apiVersion: extensions/v1beta1
+metadata:
+  name: proxy-injector
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+    {{.ControllerComponentLabel}}: proxy-injector
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+spec:
+  replicas: {{.ControllerReplicas}}
+  template:
+    metadata:
+      labels:
+        {{.ControllerComponentLabel}}: proxy-injector
+      annotations:
+        {{.CreatedByAnnotation}}: {{.CliVersion}}
+        # The API server calls the webhook over TLS, without a proxy of its
+        # own.
+        linkerd.io/skip-inbound-ports: "8443"
+    spec:
+      serviceAccount: linkerd-proxy-injector
+      {{- if .EnableHA }}
+      affinity:
+        podAntiAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+          - labelSelector:
+              matchLabels:
+                {{.ControllerComponentLabel}}: proxy-injector
+            topologyKey: kubernetes.io/hostname
+      {{- end }}
+      {{- with .NodeSelector }}
+      nodeSelector:
+        {{- range $key, $value := . }}
+        {{ $key }}: {{ printf "%q" $value }}
+        {{- end }}
+      {{- end }}
+      {{- with .Tolerations }}
+      tolerations:
+      {{- range . }}
+      - key: {{ .Key }}
+        operator: {{ .Operator }}
+        {{- if .Value }}
+        value: {{ printf "%q" .Value }}
+        {{- end }}
+        {{- if .Effect }}
+        effect: {{ .Effect }}
+        {{- end }}
+      {{- end }}
+      {{- end }}
+      containers:
+      - name: proxy-injector
+        ports:
+        - name: webhook
+          containerPort: 8443
+        - name: admin-http
+          containerPort: 9993
+        image: {{.ControllerImage}}
+        imagePullPolicy: {{.ImagePullPolicy}}
+        args:
+        - "proxy-injector"
+        - "-controller-namespace={{.Namespace}}"
+        - "-log-level={{.ControllerLogLevel}}"
+        - "-default-inject={{.ProxyAutoInjectDefault}}"
+        volumeMounts:
+        - name: config
+          mountPath: /var/linkerd-io/config
+          readOnly: true
+        - name: tls
+          mountPath: /var/linkerd-io/tls
+          readOnly: true
+        livenessProbe:
+          httpGet:
+            path: /ping
+            port: 9993
+          initialDelaySeconds: 10
+        readinessProbe:
+          httpGet:
+            path: /ready
+            port: 9993
+          failureThreshold: 7
+        {{- if .EnableHA }}
+        resources:
+          requests:
+            cpu: 20m
+            memory: 50Mi
+        {{- end }}
+      volumes:
+      - name: config
+        configMap:
+          name: {{.InstallConfigMapName}}
+          items:
+          - key: {{.ProxyConfigKey}}
+            path: proxy.json
+      - name: tls
+        secret:
+          secretName: {{.ProxyInjectorTLSSecretName}}
+
+---
+kind: Secret
+apiVersion: v1
+metadata:
+  name: {{.ProxyInjectorTLSSecretName}}
+  namespace: {{.Namespace}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+    {{.ControllerComponentLabel}}: proxy-injector
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+type: kubernetes.io/tls
+data:
+  tls.crt: {{.ProxyInjectorTLS.Cert}}
+  tls.key: {{.ProxyInjectorTLS.Key}}
+  ca.crt: {{.ProxyInjectorTLS.CABundle}}
+
+---
+kind: MutatingWebhookConfiguration
+apiVersion: admissionregistration.k8s.io/v1beta1
+metadata:
+  name: {{.ProxyInjectorWebhookConfig}}
+  labels:
+    {{.ControllerNamespaceLabel}}: {{.Namespace}}
+    {{.ControllerComponentLabel}}: proxy-injector
+  annotations:
+    {{.CreatedByAnnotation}}: {{.CliVersion}}
+webhooks:
+- name: proxy-injector.linkerd.io
+  clientConfig:
+    service:
+      name: {{.ProxyInjectorServiceName}}
+      namespace: {{.Namespace}}
+      path: "/"
+    caBundle: {{.ProxyInjectorTLS.CABundle}}
+  rules:
+  - operations: ["CREATE"]
+    apiGroups: [""]
+    apiVersions: ["v1"]
+    resources: ["pods"]
+  failurePolicy: {{.ProxyInjectorFailurePolicy}}
 `
@@ -38,6 +38,12 @@ func (c *grpcOverHttpClient) StatSummary(ctx context.Context, req *pb.StatSummar
 	return &msg, err
 }
 
+func (c *grpcOverHttpClient) TopRoutes(ctx context.Context, req *pb.TopRoutesRequest, _ ...grpc.CallOption) (*pb.TopRoutesResponse, error) {
+	var msg pb.TopRoutesResponse
+	err := c.apiRequest(ctx, "TopRoutes", req, &msg)
+	return &msg, err
+}
+
 func (c *grpcOverHttpClient) Version(ctx context.Context, req *pb.Empty, _ ...grpc.CallOption) (*pb.VersionInfo, error) {
 	var msg pb.VersionInfo
 	err := c.apiRequest(ctx, "Version", req, &msg)
@@ -188,12 +194,12 @@ func NewInternalClient(controlPlaneNamespace string, kubeAPIHost string) (pb.Api
 }
 
 func NewExternalClient(controlPlaneNamespace string, kubeAPI *k8s.KubernetesAPI) (pb.ApiClient, error) {
-	apiURL, err := kubeAPI.UrlFor(controlPlaneNamespace, "/services/http:api:http/proxy/")
+	apiURL, err := kubeAPI.ServiceProxyUrlFor(controlPlaneNamespace, "api", "http", "http", "")
 	if err != nil {
 		return nil, err
 	}
 
-	httpClientToUse, err := kubeAPI.NewClient()
+	httpClientToUse, err := kubeAPI.Client()
 	if err != nil {
 		return nil, err
 	}
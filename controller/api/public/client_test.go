@@ -5,13 +5,17 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
 	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/client-go/rest"
 )
 
 type mockTransport struct {
@@ -59,6 +63,38 @@ func TestNewInternalClient(t *testing.T) {
 	})
 }
 
+func TestNewExternalClient(t *testing.T) {
+	t.Run("Reaches the API of a control plane in a custom namespace through the service proxy", func(t *testing.T) {
+		var requested []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = append(requested, r.URL.Path)
+			if r.URL.Path != "/api/v1/namespaces/custom-ns/services/http:api:http/proxy/api/v1/Version" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			io.Copy(w, bufferedReader(t, &pb.VersionInfo{ReleaseVersion: "edge-18.9.1"}))
+		}))
+		defer server.Close()
+
+		kubeAPI, err := k8s.NewAPIFromConfig(&rest.Config{Host: server.URL})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		client, err := NewExternalClient("custom-ns", kubeAPI)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		versionInfo, err := client.Version(context.Background(), &pb.Empty{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v, requested %v", err, requested)
+		}
+		if versionInfo.GetReleaseVersion() != "edge-18.9.1" {
+			t.Fatalf("Expected release version [edge-18.9.1], got [%s]", versionInfo.GetReleaseVersion())
+		}
+	})
+}
+
 func TestFromByteStreamToProtocolBuffers(t *testing.T) {
 	t.Run("Correctly marshalls an valid object", func(t *testing.T) {
 		versionInfo := pb.VersionInfo{
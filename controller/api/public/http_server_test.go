@@ -24,6 +24,11 @@ func (m *mockGrpcServer) StatSummary(ctx context.Context, req *pb.StatSummaryReq
 	return m.ResponseToReturn.(*pb.StatSummaryResponse), m.ErrorToReturn
 }
 
+func (m *mockGrpcServer) TopRoutes(ctx context.Context, req *pb.TopRoutesRequest) (*pb.TopRoutesResponse, error) {
+	m.LastRequestReceived = req
+	return m.ResponseToReturn.(*pb.TopRoutesResponse), m.ErrorToReturn
+}
+
 func (m *mockGrpcServer) Version(ctx context.Context, req *pb.Empty) (*pb.VersionInfo, error) {
 	m.LastRequestReceived = req
 	return m.ResponseToReturn.(*pb.VersionInfo), m.ErrorToReturn
@@ -109,6 +114,13 @@ func TestServer(t *testing.T) {
 			functionCall:     func() (proto.Message, error) { return client.StatSummary(context.TODO(), statSummaryReq) },
 		}
 
+		topRoutesReq := &pb.TopRoutesRequest{}
+		testTopRoutes := grpcCallTestCase{
+			expectedRequest:  topRoutesReq,
+			expectedResponse: &pb.TopRoutesResponse{},
+			functionCall:     func() (proto.Message, error) { return client.TopRoutes(context.TODO(), topRoutesReq) },
+		}
+
 		versionReq := &pb.Empty{}
 		testVersion := grpcCallTestCase{
 			expectedRequest: versionReq,
@@ -118,7 +130,7 @@ func TestServer(t *testing.T) {
 			functionCall: func() (proto.Message, error) { return client.Version(context.TODO(), versionReq) },
 		}
 
-		for _, testCase := range []grpcCallTestCase{testListPods, testStatSummary, testVersion} {
+		for _, testCase := range []grpcCallTestCase{testListPods, testStatSummary, testTopRoutes, testVersion} {
 			assertCallWasForwarded(t, mockGrpcServer, testCase.expectedRequest, testCase.expectedResponse, testCase.functionCall)
 		}
 	})
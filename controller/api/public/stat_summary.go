@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	proto "github.com/golang/protobuf/proto"
@@ -44,18 +45,22 @@ type rKey struct {
 const (
 	reqQuery             = "sum(increase(response_total%s[%s])) by (%s, classification, tls)"
 	latencyQuantileQuery = "histogram_quantile(%s, sum(irate(response_latency_ms_bucket%s[%s])) by (le, %s))"
-
-	promRequests   = promType("QUERY_REQUESTS")
-	promLatencyP50 = promType("0.5")
-	promLatencyP95 = promType("0.95")
-	promLatencyP99 = promType("0.99")
+	tcpConnectionsQuery  = "sum(tcp_open_connections%s) by (%s)"
+	tcpReadBytesQuery    = "sum(increase(tcp_read_bytes_total%s[%s])) by (%s)"
+	tcpWriteBytesQuery   = "sum(increase(tcp_write_bytes_total%s[%s])) by (%s)"
+
+	promRequests       = promType("QUERY_REQUESTS")
+	promLatencyP50     = promType("0.5")
+	promLatencyP95     = promType("0.95")
+	promLatencyP99     = promType("0.99")
+	promTCPConnections = promType("TCP_CONNECTIONS")
+	promTCPReadBytes   = promType("TCP_READ_BYTES")
+	promTCPWriteBytes  = promType("TCP_WRITE_BYTES")
 
 	namespaceLabel    = model.LabelName("namespace")
 	dstNamespaceLabel = model.LabelName("dst_namespace")
 )
 
-var promTypes = []promType{promRequests, promLatencyP50, promLatencyP95, promLatencyP99}
-
 type podStats struct {
 	inMesh uint64
 	total  uint64
@@ -181,7 +186,7 @@ func (s *grpcServer) k8sResourceQuery(ctx context.Context, req *pb.StatSummaryRe
 		return resourceResult{res: nil, err: err}
 	}
 
-	requestMetrics, err := s.getPrometheusMetrics(ctx, req, req.TimeWindow)
+	requestMetrics, tcpMetrics, err := s.getPrometheusMetrics(ctx, req, req.TimeWindow)
 	if err != nil {
 		return resourceResult{res: nil, err: err}
 	}
@@ -203,6 +208,7 @@ func (s *grpcServer) k8sResourceQuery(ctx context.Context, req *pb.StatSummaryRe
 			},
 			TimeWindow: req.TimeWindow,
 			Stats:      requestMetrics[key],
+			TcpStats:   tcpMetrics[key],
 		}
 
 		podStat := objInfo.podStats
@@ -226,13 +232,19 @@ func (s *grpcServer) k8sResourceQuery(ctx context.Context, req *pb.StatSummaryRe
 }
 
 func (s *grpcServer) nonK8sResourceQuery(ctx context.Context, req *pb.StatSummaryRequest) resourceResult {
-	requestMetrics, err := s.getPrometheusMetrics(ctx, req, req.TimeWindow)
+	requestMetrics, tcpMetrics, err := s.getPrometheusMetrics(ctx, req, req.TimeWindow)
 	if err != nil {
 		return resourceResult{res: nil, err: err}
 	}
 	rows := make([]*pb.StatTable_PodGroup_Row, 0)
 
-	for rkey, metrics := range requestMetrics {
+	for _, rkey := range getMetricKeys(requestMetrics) {
+		metrics := requestMetrics[rkey]
+		// series of authorities that received requests before the time window
+		// are returned with no requests during the window
+		if metrics.SuccessCount+metrics.FailureCount == 0 {
+			continue
+		}
 		rkey.Type = req.GetSelector().GetResource().GetType()
 
 		row := pb.StatTable_PodGroup_Row{
@@ -243,6 +255,7 @@ func (s *grpcServer) nonK8sResourceQuery(ctx context.Context, req *pb.StatSummar
 			},
 			TimeWindow: req.TimeWindow,
 			Stats:      metrics,
+			TcpStats:   tcpMetrics[rkey],
 		}
 		rows = append(rows, &row)
 	}
@@ -257,11 +270,22 @@ func (s *grpcServer) nonK8sResourceQuery(ctx context.Context, req *pb.StatSummar
 	return resourceResult{res: &rsp, err: nil}
 }
 
+// get the keys of the metrics, sorted by namespace and name
+func getMetricKeys(metricResults map[rKey]*pb.BasicStats) []rKey {
+	keys := make([]rKey, 0, len(metricResults))
+	for key := range metricResults {
+		keys = append(keys, key)
+	}
+	sortKeys(keys)
+	return keys
+}
+
 func isNonK8sResourceQuery(resourceType string) bool {
 	return resourceType == k8s.Authority
 }
 
-// get the list of objects for which we want to return results
+// get the list of objects for which we want to return results, sorted by
+// namespace and name so that rows are returned in a stable order
 func getResultKeys(
 	req *pb.StatSummaryRequest,
 	k8sObjects map[rKey]k8sStat,
@@ -281,9 +305,19 @@ func getResultKeys(
 			keys = append(keys, key)
 		}
 	}
+	sortKeys(keys)
 	return keys
 }
 
+func sortKeys(keys []rKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Name < keys[j].Name
+	})
+}
+
 // add filtering by resource type
 // note that metricToKey assumes the label ordering (namespace, name)
 func promGroupByLabelNames(resource *pb.Resource) model.LabelNames {
@@ -381,40 +415,61 @@ func buildRequestLabels(req *pb.StatSummaryRequest) (labels model.LabelSet, labe
 	return
 }
 
-func (s *grpcServer) getPrometheusMetrics(ctx context.Context, req *pb.StatSummaryRequest, timeWindow string) (map[rKey]*pb.BasicStats, error) {
+// query the TCP metrics of the connections accepted by the proxies, so that
+// each connection is counted once
+func promPeerLabel(peer string) model.LabelSet {
+	return model.LabelSet{
+		model.LabelName("peer"): model.LabelValue(peer),
+	}
+}
+
+func (s *grpcServer) getPrometheusMetrics(ctx context.Context, req *pb.StatSummaryRequest, timeWindow string) (map[rKey]*pb.BasicStats, map[rKey]*pb.TcpStats, error) {
 	reqLabels, groupBy := buildRequestLabels(req)
-	resultChan := make(chan promResult)
 
-	// kick off 4 asynchronous queries: 1 request volume + 3 latency
-	go func() {
-		// success/failure counts
-		requestsQuery := fmt.Sprintf(reqQuery, reqLabels, timeWindow, groupBy)
-		resultVector, err := s.queryProm(ctx, requestsQuery)
+	// 1 request volume + 3 latency queries, and 3 TCP queries if requested
+	queries := map[promType]string{
+		promRequests: fmt.Sprintf(reqQuery, reqLabels, timeWindow, groupBy),
+	}
+	for _, quantile := range []promType{promLatencyP50, promLatencyP95, promLatencyP99} {
+		queries[quantile] = fmt.Sprintf(latencyQuantileQuery, quantile, reqLabels, timeWindow, groupBy)
+	}
+	if req.TcpStats {
+		tcpLabels := reqLabels.Merge(promPeerLabel("src"))
+		queries[promTCPConnections] = fmt.Sprintf(tcpConnectionsQuery, tcpLabels, groupBy)
+		queries[promTCPReadBytes] = fmt.Sprintf(tcpReadBytesQuery, tcpLabels, timeWindow, groupBy)
+		queries[promTCPWriteBytes] = fmt.Sprintf(tcpWriteBytesQuery, tcpLabels, timeWindow, groupBy)
+	}
 
-		resultChan <- promResult{
-			prom: promRequests,
-			vec:  resultVector,
-			err:  err,
-		}
-	}()
+	results, err := s.runPromQueries(ctx, queries)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	for _, quantile := range []promType{promLatencyP50, promLatencyP95, promLatencyP99} {
-		go func(quantile promType) {
-			latencyQuery := fmt.Sprintf(latencyQuantileQuery, quantile, reqLabels, timeWindow, groupBy)
-			latencyResult, err := s.queryProm(ctx, latencyQuery)
+	basicStats, tcpStats := processPrometheusMetrics(req, results, groupBy)
+	return basicStats, tcpStats, nil
+}
+
+// runPromQueries runs the queries in parallel, returning their results, or the
+// error of one of those that failed.
+func (s *grpcServer) runPromQueries(ctx context.Context, queries map[promType]string) ([]promResult, error) {
+	// kick off the queries asynchronously
+	resultChan := make(chan promResult)
+	for prom, query := range queries {
+		go func(prom promType, query string) {
+			resultVector, err := s.queryProm(ctx, query)
 
 			resultChan <- promResult{
-				prom: quantile,
-				vec:  latencyResult,
+				prom: prom,
+				vec:  resultVector,
 				err:  err,
 			}
-		}(quantile)
+		}(prom, query)
 	}
 
 	// process results, receive one message per prometheus query type
 	var err error
 	results := []promResult{}
-	for i := 0; i < len(promTypes); i++ {
+	for i := 0; i < len(queries); i++ {
 		result := <-resultChan
 		if result.err != nil {
 			log.Errorf("queryProm failed with: %s", result.err)
@@ -426,46 +481,68 @@ func (s *grpcServer) getPrometheusMetrics(ctx context.Context, req *pb.StatSumma
 	if err != nil {
 		return nil, err
 	}
-
-	return processPrometheusMetrics(req, results, groupBy), nil
+	return results, nil
 }
 
-func processPrometheusMetrics(req *pb.StatSummaryRequest, results []promResult, groupBy model.LabelNames) map[rKey]*pb.BasicStats {
+func processPrometheusMetrics(req *pb.StatSummaryRequest, results []promResult, groupBy model.LabelNames) (map[rKey]*pb.BasicStats, map[rKey]*pb.TcpStats) {
 	basicStats := make(map[rKey]*pb.BasicStats)
+	tcpStats := make(map[rKey]*pb.TcpStats)
 
 	for _, result := range results {
 		for _, sample := range result.vec {
 			resource := metricToKey(req, sample.Metric, groupBy)
-
-			if basicStats[resource] == nil {
-				basicStats[resource] = &pb.BasicStats{}
-			}
-
 			value := extractSampleValue(sample)
 
 			switch result.prom {
-			case promRequests:
-				switch string(sample.Metric[model.LabelName("classification")]) {
-				case "success":
-					basicStats[resource].SuccessCount += value
-				case "failure":
-					basicStats[resource].FailureCount += value
+			case promTCPConnections, promTCPReadBytes, promTCPWriteBytes:
+				if tcpStats[resource] == nil {
+					tcpStats[resource] = &pb.TcpStats{}
 				}
-				switch string(sample.Metric[model.LabelName("tls")]) {
-				case "true":
-					basicStats[resource].TlsRequestCount += value
+
+				switch result.prom {
+				case promTCPConnections:
+					tcpStats[resource].OpenConnections = value
+				case promTCPReadBytes:
+					tcpStats[resource].ReadBytesTotal = value
+				case promTCPWriteBytes:
+					tcpStats[resource].WriteBytesTotal = value
 				}
-			case promLatencyP50:
-				basicStats[resource].LatencyMsP50 = value
-			case promLatencyP95:
-				basicStats[resource].LatencyMsP95 = value
-			case promLatencyP99:
-				basicStats[resource].LatencyMsP99 = value
+				continue
+			}
+
+			if basicStats[resource] == nil {
+				basicStats[resource] = &pb.BasicStats{}
 			}
+			addBasicStat(basicStats[resource], result.prom, sample)
 		}
 	}
 
-	return basicStats
+	return basicStats, tcpStats
+}
+
+// addBasicStat adds the sample of the request or latency query prom to stats.
+func addBasicStat(stats *pb.BasicStats, prom promType, sample *model.Sample) {
+	value := extractSampleValue(sample)
+
+	switch prom {
+	case promRequests:
+		switch string(sample.Metric[model.LabelName("classification")]) {
+		case "success":
+			stats.SuccessCount += value
+		case "failure":
+			stats.FailureCount += value
+		}
+		switch string(sample.Metric[model.LabelName("tls")]) {
+		case "true":
+			stats.TlsRequestCount += value
+		}
+	case promLatencyP50:
+		stats.LatencyMsP50 = value
+	case promLatencyP95:
+		stats.LatencyMsP95 = value
+	case promLatencyP99:
+		stats.LatencyMsP99 = value
+	}
 }
 
 func extractSampleValue(sample *model.Sample) uint64 {
@@ -227,6 +227,176 @@ status:
 		testStatSummary(t, expectations)
 	})
 
+	t.Run("Aggregates stats by namespace, including namespaces without traffic", func(t *testing.T) {
+		expectations := []statSumExpected{
+			statSumExpected{
+				err: nil,
+				k8sConfigs: []string{`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: emojivoto
+`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: books
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-meshed
+  namespace: emojivoto
+  labels:
+    linkerd.io/control-plane-ns: linkerd
+status:
+  phase: Running
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-not-meshed
+  namespace: emojivoto
+status:
+  phase: Running
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: books-not-meshed
+  namespace: books
+status:
+  phase: Running
+`,
+				},
+				mockPromResponse: prometheusMetric("emojivoto", "namespace", "emojivoto", "success", false),
+				expectedPrometheusQueries: []string{
+					`histogram_quantile(0.5, sum(irate(response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace))`,
+					`histogram_quantile(0.95, sum(irate(response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace))`,
+					`histogram_quantile(0.99, sum(irate(response_latency_ms_bucket{direction="inbound"}[1m])) by (le, namespace))`,
+					`sum(increase(response_total{direction="inbound"}[1m])) by (namespace, classification, tls)`,
+				},
+				req: pb.StatSummaryRequest{
+					Selector: &pb.ResourceSelection{
+						Resource: &pb.Resource{
+							Type: pkgK8s.Namespace,
+						},
+					},
+					TimeWindow: "1m",
+				},
+				expectedResponse: pb.StatSummaryResponse{
+					Response: &pb.StatSummaryResponse_Ok_{
+						Ok: &pb.StatSummaryResponse_Ok{
+							StatTables: []*pb.StatTable{
+								&pb.StatTable{
+									Table: &pb.StatTable_PodGroup_{
+										PodGroup: &pb.StatTable_PodGroup{
+											Rows: []*pb.StatTable_PodGroup_Row{
+												&pb.StatTable_PodGroup_Row{
+													Resource:        &pb.Resource{Type: pkgK8s.Namespace, Name: "books"},
+													TimeWindow:      "1m",
+													MeshedPodCount:  0,
+													RunningPodCount: 1,
+													ErrorsByPod:     map[string]*pb.PodErrors{},
+												},
+												&pb.StatTable_PodGroup_Row{
+													Resource:   &pb.Resource{Type: pkgK8s.Namespace, Name: "emojivoto"},
+													TimeWindow: "1m",
+													Stats: &pb.BasicStats{
+														SuccessCount:    123,
+														LatencyMsP50:    123,
+														LatencyMsP95:    123,
+														LatencyMsP99:    123,
+														TlsRequestCount: 123,
+													},
+													MeshedPodCount:  1,
+													RunningPodCount: 2,
+													ErrorsByPod:     map[string]*pb.PodErrors{},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		testStatSummary(t, expectations)
+	})
+
+	t.Run("Queries prometheus for TCP stats if requested", func(t *testing.T) {
+		expected := GenStatSummaryResponse("emoji", pkgK8s.Deployment, "emojivoto", &PodCounts{
+			MeshedPods:  1,
+			RunningPods: 1,
+			FailedPods:  0,
+		})
+		expected.GetOk().StatTables[0].GetPodGroup().Rows[0].TcpStats = &pb.TcpStats{
+			OpenConnections: 123,
+			ReadBytesTotal:  123,
+			WriteBytesTotal: 123,
+		}
+
+		expectations := []statSumExpected{
+			statSumExpected{
+				err: nil,
+				k8sConfigs: []string{`
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: emoji
+  namespace: emojivoto
+spec:
+  selector:
+    matchLabels:
+      app: emoji-svc
+  strategy: {}
+  template:
+    spec:
+      containers:
+      - image: buoyantio/emojivoto-emoji-svc:v3
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-meshed
+  namespace: emojivoto
+  labels:
+    app: emoji-svc
+    linkerd.io/control-plane-ns: linkerd
+status:
+  phase: Running
+`,
+				},
+				mockPromResponse: prometheusMetric("emoji", "deployment", "emojivoto", "success", false),
+				expectedPrometheusQueries: []string{
+					`histogram_quantile(0.5, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto"}[1m])) by (le, namespace, deployment))`,
+					`histogram_quantile(0.95, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto"}[1m])) by (le, namespace, deployment))`,
+					`histogram_quantile(0.99, sum(irate(response_latency_ms_bucket{direction="inbound", namespace="emojivoto"}[1m])) by (le, namespace, deployment))`,
+					`sum(increase(response_total{direction="inbound", namespace="emojivoto"}[1m])) by (namespace, deployment, classification, tls)`,
+					`sum(tcp_open_connections{direction="inbound", namespace="emojivoto", peer="src"}) by (namespace, deployment)`,
+					`sum(increase(tcp_read_bytes_total{direction="inbound", namespace="emojivoto", peer="src"}[1m])) by (namespace, deployment)`,
+					`sum(increase(tcp_write_bytes_total{direction="inbound", namespace="emojivoto", peer="src"}[1m])) by (namespace, deployment)`,
+				},
+				req: pb.StatSummaryRequest{
+					Selector: &pb.ResourceSelection{
+						Resource: &pb.Resource{
+							Namespace: "emojivoto",
+							Type:      pkgK8s.Deployment,
+						},
+					},
+					TimeWindow: "1m",
+					TcpStats:   true,
+				},
+				expectedResponse: expected,
+			},
+		}
+
+		testStatSummary(t, expectations)
+	})
+
 	t.Run("Queries prometheus for a specific resource if name is specified", func(t *testing.T) {
 		expectations := []statSumExpected{
 			statSumExpected{
@@ -558,6 +728,133 @@ status:
 		testStatSummary(t, expectations)
 	})
 
+	t.Run("Queries prometheus for outbound metrics to a namespace if --to is a namespace", func(t *testing.T) {
+		expectations := []statSumExpected{
+			statSumExpected{
+				err: nil,
+				k8sConfigs: []string{`
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: emoji
+  namespace: emojivoto
+spec:
+  selector:
+    matchLabels:
+      app: emoji-svc
+  strategy: {}
+  template:
+    spec:
+      containers:
+      - image: buoyantio/emojivoto-emoji-svc:v3
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-meshed
+  namespace: emojivoto
+  labels:
+    app: emoji-svc
+    linkerd.io/control-plane-ns: linkerd
+status:
+  phase: Running
+`,
+				},
+				mockPromResponse: prometheusMetric("emoji", "deployment", "emojivoto", "success", false),
+				req: pb.StatSummaryRequest{
+					Selector: &pb.ResourceSelection{
+						Resource: &pb.Resource{
+							Namespace: "emojivoto",
+							Type:      pkgK8s.Deployment,
+						},
+					},
+					TimeWindow: "1m",
+					Outbound: &pb.StatSummaryRequest_ToResource{
+						ToResource: &pb.Resource{
+							Name: "prod",
+							Type: pkgK8s.Namespace,
+						},
+					},
+				},
+				expectedPrometheusQueries: []string{
+					`histogram_quantile(0.5, sum(irate(response_latency_ms_bucket{direction="outbound", dst_namespace="prod", namespace="emojivoto"}[1m])) by (le, namespace, deployment))`,
+					`histogram_quantile(0.95, sum(irate(response_latency_ms_bucket{direction="outbound", dst_namespace="prod", namespace="emojivoto"}[1m])) by (le, namespace, deployment))`,
+					`histogram_quantile(0.99, sum(irate(response_latency_ms_bucket{direction="outbound", dst_namespace="prod", namespace="emojivoto"}[1m])) by (le, namespace, deployment))`,
+					`sum(increase(response_total{direction="outbound", dst_namespace="prod", namespace="emojivoto"}[1m])) by (namespace, deployment, classification, tls)`,
+				},
+				expectedResponse: GenStatSummaryResponse("emoji", pkgK8s.Deployment, "emojivoto", &PodCounts{
+					MeshedPods:  1,
+					RunningPods: 1,
+					FailedPods:  0,
+				}),
+			},
+		}
+
+		testStatSummary(t, expectations)
+	})
+
+	t.Run("Returns no rows if there is no traffic to the --to resource", func(t *testing.T) {
+		expectations := []statSumExpected{
+			statSumExpected{
+				err: nil,
+				k8sConfigs: []string{`
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: emoji
+  namespace: emojivoto
+spec:
+  selector:
+    matchLabels:
+      app: emoji-svc
+  strategy: {}
+  template:
+    spec:
+      containers:
+      - image: buoyantio/emojivoto-emoji-svc:v3
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-meshed
+  namespace: emojivoto
+  labels:
+    app: emoji-svc
+    linkerd.io/control-plane-ns: linkerd
+status:
+  phase: Running
+`,
+				},
+				mockPromResponse: model.Vector{},
+				req: pb.StatSummaryRequest{
+					Selector: &pb.ResourceSelection{
+						Resource: &pb.Resource{
+							Namespace: "emojivoto",
+							Type:      pkgK8s.Deployment,
+						},
+					},
+					TimeWindow: "1m",
+					Outbound: &pb.StatSummaryRequest_ToResource{
+						ToResource: &pb.Resource{
+							Name:      "unknown",
+							Namespace: "emojivoto",
+							Type:      pkgK8s.Deployment,
+						},
+					},
+				},
+				expectedPrometheusQueries: []string{
+					`histogram_quantile(0.5, sum(irate(response_latency_ms_bucket{direction="outbound", dst_deployment="unknown", dst_namespace="emojivoto", namespace="emojivoto"}[1m])) by (le, namespace, deployment))`,
+					`histogram_quantile(0.95, sum(irate(response_latency_ms_bucket{direction="outbound", dst_deployment="unknown", dst_namespace="emojivoto", namespace="emojivoto"}[1m])) by (le, namespace, deployment))`,
+					`histogram_quantile(0.99, sum(irate(response_latency_ms_bucket{direction="outbound", dst_deployment="unknown", dst_namespace="emojivoto", namespace="emojivoto"}[1m])) by (le, namespace, deployment))`,
+					`sum(increase(response_total{direction="outbound", dst_deployment="unknown", dst_namespace="emojivoto", namespace="emojivoto"}[1m])) by (namespace, deployment, classification, tls)`,
+				},
+				expectedResponse: genEmptyResponse(),
+			},
+		}
+
+		testStatSummary(t, expectations)
+	})
+
 	t.Run("Successfully queries for resource type 'all'", func(t *testing.T) {
 		expectations := []statSumExpected{
 			statSumExpected{
@@ -1153,4 +1450,67 @@ status:
 
 		testStatSummary(t, expectations)
 	})
+
+	t.Run("Queries prometheus for authority stats when --to resource is specified", func(t *testing.T) {
+		expectations := []statSumExpected{
+			statSumExpected{
+				err: nil,
+				mockPromResponse: model.Vector{
+					genPromSample("web-svc.prod.svc.cluster.local", "authority", "prod", "success", false),
+				},
+				req: pb.StatSummaryRequest{
+					Selector: &pb.ResourceSelection{
+						Resource: &pb.Resource{
+							Namespace: "prod",
+							Type:      pkgK8s.Authority,
+						},
+					},
+					TimeWindow: "1m",
+					Outbound: &pb.StatSummaryRequest_ToResource{
+						ToResource: &pb.Resource{
+							Name:      "web",
+							Namespace: "prod",
+							Type:      pkgK8s.Deployment,
+						},
+					},
+				},
+				expectedPrometheusQueries: []string{
+					`histogram_quantile(0.5, sum(irate(response_latency_ms_bucket{direction="outbound", dst_deployment="web", dst_namespace="prod", namespace="prod"}[1m])) by (le, namespace, authority))`,
+					`histogram_quantile(0.95, sum(irate(response_latency_ms_bucket{direction="outbound", dst_deployment="web", dst_namespace="prod", namespace="prod"}[1m])) by (le, namespace, authority))`,
+					`histogram_quantile(0.99, sum(irate(response_latency_ms_bucket{direction="outbound", dst_deployment="web", dst_namespace="prod", namespace="prod"}[1m])) by (le, namespace, authority))`,
+					`sum(increase(response_total{direction="outbound", dst_deployment="web", dst_namespace="prod", namespace="prod"}[1m])) by (namespace, authority, classification, tls)`,
+				},
+				expectedResponse: GenStatSummaryResponse("web-svc.prod.svc.cluster.local", pkgK8s.Authority, "prod", nil),
+			},
+		}
+
+		testStatSummary(t, expectations)
+	})
+
+	t.Run("Omits authorities without requests in the time window", func(t *testing.T) {
+		idle := genPromSample("books.prod.svc.cluster.local", "authority", "prod", "success", false)
+		idle.Value = 0
+
+		expectations := []statSumExpected{
+			statSumExpected{
+				err: nil,
+				mockPromResponse: model.Vector{
+					idle,
+					genPromSample("web-svc.prod.svc.cluster.local", "authority", "prod", "success", false),
+				},
+				req: pb.StatSummaryRequest{
+					Selector: &pb.ResourceSelection{
+						Resource: &pb.Resource{
+							Namespace: "prod",
+							Type:      pkgK8s.Authority,
+						},
+					},
+					TimeWindow: "1m",
+				},
+				expectedResponse: GenStatSummaryResponse("web-svc.prod.svc.cluster.local", pkgK8s.Authority, "prod", nil),
+			},
+		}
+
+		testStatSummary(t, expectations)
+	})
 }
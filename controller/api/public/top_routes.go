@@ -0,0 +1,158 @@
+package public
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/linkerd/linkerd2/controller/api/util"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/profiles"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	routeReqQuery             = "sum(increase(route_response_total%s[%s])) by (rt_route, classification, tls)"
+	routeLatencyQuantileQuery = "histogram_quantile(%s, sum(irate(route_response_latency_ms_bucket%s[%s])) by (le, rt_route))"
+
+	routeLabel = model.LabelName("rt_route")
+	dstLabel   = model.LabelName("dst")
+
+	// defaultRouteName is the name of the row of the requests that matched
+	// none of the routes of the ServiceProfile.
+	defaultRouteName = "[DEFAULT]"
+)
+
+func (s *grpcServer) TopRoutes(ctx context.Context, req *pb.TopRoutesRequest) (*pb.TopRoutesResponse, error) {
+	// check for well-formed request
+	if req.GetSelector().GetResource() == nil {
+		return topRoutesError(req, "TopRoutes request missing Selector Resource"), nil
+	}
+
+	service := req.GetSelector().GetResource()
+	if to := req.GetToResource(); to != nil {
+		if service.GetType() == k8s.Service {
+			return topRoutesError(req, "service only supported as a target on inbound queries, or as a destination on 'to' queries"), nil
+		}
+		service = to
+	}
+	if service.GetType() != k8s.Service || service.GetName() == "" {
+		return topRoutesError(req, "routes are only reported for requests to a named service, by the routes of its ServiceProfile"), nil
+	}
+
+	authority := k8s.ServiceProfileName(service.GetName(), service.GetNamespace())
+	profile, err := s.k8sAPI.GetServiceProfile(service.GetNamespace(), authority)
+	if err != nil {
+		return nil, util.GRPCError(err)
+	}
+	if profile == nil {
+		return topRoutesError(req, fmt.Sprintf(
+			"no ServiceProfile found for service [%s] in namespace [%s]; create one with `linkerd profile` to see its routes",
+			service.GetName(), service.GetNamespace())), nil
+	}
+	if err := profiles.ValidateServiceProfile(profile); err != nil {
+		return topRoutesError(req, fmt.Sprintf("invalid ServiceProfile [%s] in namespace [%s]: %s", authority, service.GetNamespace(), err)), nil
+	}
+
+	results, err := s.runPromQueries(ctx, buildRouteQueries(req, authority))
+	if err != nil {
+		return nil, util.GRPCError(err)
+	}
+	routeStats := processRouteMetrics(results)
+
+	routes := make([]string, 0, len(profile.Spec.Routes))
+	for _, route := range profile.Spec.Routes {
+		routes = append(routes, route.Name)
+	}
+	sort.Strings(routes)
+
+	rows := make([]*pb.RouteTable_Row, 0, len(routes)+1)
+	for _, route := range routes {
+		rows = append(rows, &pb.RouteTable_Row{
+			Route:      route,
+			TimeWindow: req.TimeWindow,
+			Stats:      routeStats[route],
+		})
+	}
+	// requests that match no route are not labeled with one
+	rows = append(rows, &pb.RouteTable_Row{
+		Route:      defaultRouteName,
+		TimeWindow: req.TimeWindow,
+		Stats:      routeStats[""],
+	})
+
+	return &pb.TopRoutesResponse{
+		Response: &pb.TopRoutesResponse_Ok{
+			Ok: &pb.RouteTable{Rows: rows},
+		},
+	}, nil
+}
+
+func topRoutesError(req *pb.TopRoutesRequest, message string) *pb.TopRoutesResponse {
+	return &pb.TopRoutesResponse{
+		Response: &pb.TopRoutesResponse_Error{
+			Error: &pb.ResourceError{
+				Resource: req.GetSelector().GetResource(),
+				Error:    message,
+			},
+		},
+	}
+}
+
+// buildRouteQueries returns the request volume and latency queries of the
+// route metrics of the requests of req to authority: the inbound requests of
+// the service selected, or the outbound requests of the resource selected to
+// the service of the to_resource.
+func buildRouteQueries(req *pb.TopRoutesRequest, authority string) map[promType]string {
+	var labels model.LabelSet
+	if req.GetToResource() != nil {
+		labels = promQueryLabels(req.Selector.Resource).Merge(promDirectionLabels("outbound"))
+	} else {
+		labels = model.LabelSet{namespaceLabel: model.LabelValue(req.Selector.Resource.Namespace)}
+		labels = labels.Merge(promDirectionLabels("inbound"))
+	}
+	selector := routeSelector(labels, authority)
+
+	queries := map[promType]string{
+		promRequests: fmt.Sprintf(routeReqQuery, selector, req.TimeWindow),
+	}
+	for _, quantile := range []promType{promLatencyP50, promLatencyP95, promLatencyP99} {
+		queries[quantile] = fmt.Sprintf(routeLatencyQuantileQuery, quantile, selector, req.TimeWindow)
+	}
+	return queries
+}
+
+// routeSelector returns the selector of the series with labels whose dst is
+// authority, with or without a port.
+func routeSelector(labels model.LabelSet, authority string) string {
+	matchers := make([]string, 0, len(labels)+1)
+	for name, value := range labels {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", name, value))
+	}
+	sort.Strings(matchers)
+
+	// regular expressions are quoted in PromQL strings, which escape backslashes
+	dst := strings.Replace(regexp.QuoteMeta(authority)+`(:\d+)?`, `\`, `\\`, -1)
+	matchers = append(matchers, fmt.Sprintf(`%s=~"%s"`, dstLabel, dst))
+
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+// processRouteMetrics returns the stats of the results by the route they are
+// labeled with.
+func processRouteMetrics(results []promResult) map[string]*pb.BasicStats {
+	routeStats := make(map[string]*pb.BasicStats)
+	for _, result := range results {
+		for _, sample := range result.vec {
+			route := string(sample.Metric[routeLabel])
+			if routeStats[route] == nil {
+				routeStats[route] = &pb.BasicStats{}
+			}
+			addBasicStat(routeStats[route], result.prom, sample)
+		}
+	}
+	return routeStats
+}
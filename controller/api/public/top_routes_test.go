@@ -0,0 +1,202 @@
+package public
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	tap "github.com/linkerd/linkerd2/controller/gen/controller/tap"
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+	"github.com/linkerd/linkerd2/controller/k8s"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/prometheus/common/model"
+)
+
+// booksProfile is the ServiceProfile of the books service in the default
+// namespace. Other services have no profile.
+const booksProfile = `
+apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.default.svc.cluster.local
+  namespace: default
+spec:
+  routes:
+  - name: POST /books
+    condition:
+      pathRegex: /books
+      method: POST
+  - name: GET /books/{id}
+    condition:
+      pathRegex: /books/[^/]*
+      method: GET`
+
+func newTopRoutesServer(t *testing.T, mockProm *MockProm, profiles ...string) *grpcServer {
+	k8sAPI, err := k8s.NewFakeAPI(profiles...)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+	k8sAPI.Sync(nil)
+	return newGrpcServer(mockProm, tap.NewTapClient(nil), k8sAPI, "linkerd", []string{})
+}
+
+func TestBuildRouteQueries(t *testing.T) {
+	t.Run("Queries the inbound requests of a service", func(t *testing.T) {
+		req := &pb.TopRoutesRequest{
+			Selector: &pb.ResourceSelection{
+				Resource: &pb.Resource{Namespace: "default", Type: pkgK8s.Service, Name: "books"},
+			},
+			TimeWindow: "1m",
+		}
+
+		selector := `{direction="inbound", namespace="default", dst=~"books\\.default\\.svc\\.cluster\\.local(:\\d+)?"}`
+		expected := map[promType]string{
+			promRequests:   `sum(increase(route_response_total` + selector + `[1m])) by (rt_route, classification, tls)`,
+			promLatencyP50: `histogram_quantile(0.5, sum(irate(route_response_latency_ms_bucket` + selector + `[1m])) by (le, rt_route))`,
+			promLatencyP95: `histogram_quantile(0.95, sum(irate(route_response_latency_ms_bucket` + selector + `[1m])) by (le, rt_route))`,
+			promLatencyP99: `histogram_quantile(0.99, sum(irate(route_response_latency_ms_bucket` + selector + `[1m])) by (le, rt_route))`,
+		}
+		queries := buildRouteQueries(req, "books.default.svc.cluster.local")
+		if !reflect.DeepEqual(queries, expected) {
+			t.Fatalf("Expected queries:\n%v\nGot:\n%v", expected, queries)
+		}
+	})
+
+	t.Run("Queries the outbound requests of a resource to a service", func(t *testing.T) {
+		req := &pb.TopRoutesRequest{
+			Selector: &pb.ResourceSelection{
+				Resource: &pb.Resource{Namespace: "default", Type: pkgK8s.Deployment, Name: "web"},
+			},
+			TimeWindow: "10s",
+			Outbound: &pb.TopRoutesRequest_ToResource{
+				ToResource: &pb.Resource{Namespace: "default", Type: pkgK8s.Service, Name: "books"},
+			},
+		}
+
+		expected := `sum(increase(route_response_total{deployment="web", direction="outbound", namespace="default", dst=~"books\\.default\\.svc\\.cluster\\.local(:\\d+)?"}[10s])) by (rt_route, classification, tls)`
+		if query := buildRouteQueries(req, "books.default.svc.cluster.local")[promRequests]; query != expected {
+			t.Fatalf("Expected query:\n%s\nGot:\n%s", expected, query)
+		}
+	})
+}
+
+func TestTopRoutes(t *testing.T) {
+	booksReq := pb.TopRoutesRequest{
+		Selector: &pb.ResourceSelection{
+			Resource: &pb.Resource{Namespace: "default", Type: pkgK8s.Service, Name: "books"},
+		},
+		TimeWindow: "1m",
+	}
+
+	t.Run("Returns a row per route of the ServiceProfile, and one for the requests matching none", func(t *testing.T) {
+		sample := func(route, classification string, value model.SampleValue) *model.Sample {
+			metric := model.Metric{"classification": model.LabelValue(classification)}
+			if route != "" {
+				metric[routeLabel] = model.LabelValue(route)
+			}
+			return &model.Sample{Metric: metric, Value: value}
+		}
+		mockProm := &MockProm{Res: model.Vector{
+			sample("POST /books", "success", 8),
+			sample("POST /books", "failure", 2),
+			sample("", "success", 5),
+		}}
+
+		rsp, err := newTopRoutesServer(t, mockProm, booksProfile).TopRoutes(context.TODO(), &booksReq)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(mockProm.QueriesExecuted) != 4 {
+			t.Fatalf("Expected 4 queries, got %v", mockProm.QueriesExecuted)
+		}
+
+		var routes []string
+		for _, row := range rsp.GetOk().GetRows() {
+			routes = append(routes, row.Route)
+		}
+		expectedRoutes := []string{"GET /books/{id}", "POST /books", "[DEFAULT]"}
+		if !reflect.DeepEqual(routes, expectedRoutes) {
+			t.Fatalf("Expected routes %v, got %v", expectedRoutes, routes)
+		}
+
+		rows := rsp.GetOk().GetRows()
+		if rows[0].Stats != nil {
+			t.Fatalf("Expected no stats for the route without requests, got %v", rows[0].Stats)
+		}
+		if stats := rows[1].Stats; stats.SuccessCount != 8 || stats.FailureCount != 2 {
+			t.Fatalf("Expected 8 successes and 2 failures on POST /books, got %v", stats)
+		}
+		if stats := rows[2].Stats; stats.SuccessCount != 5 || stats.FailureCount != 0 {
+			t.Fatalf("Expected 5 successes on [DEFAULT], got %v", stats)
+		}
+	})
+
+	t.Run("Returns an error pointing at linkerd profile for services without a ServiceProfile", func(t *testing.T) {
+		req := booksReq
+		req.Selector = &pb.ResourceSelection{
+			Resource: &pb.Resource{Namespace: "default", Type: pkgK8s.Service, Name: "authors"},
+		}
+
+		mockProm := &MockProm{Res: model.Vector{}}
+		rsp, err := newTopRoutesServer(t, mockProm, booksProfile).TopRoutes(context.TODO(), &req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		expected := "no ServiceProfile found for service [authors] in namespace [default]; create one with `linkerd profile` to see its routes"
+		if rsp.GetError().GetError() != expected {
+			t.Fatalf("Expected error [%s], got %v", expected, rsp)
+		}
+		if len(mockProm.QueriesExecuted) != 0 {
+			t.Fatalf("Expected no queries, got %v", mockProm.QueriesExecuted)
+		}
+	})
+
+	t.Run("Returns an error for invalid ServiceProfiles", func(t *testing.T) {
+		invalidProfile := booksProfile + `
+  - name: POST /books
+    condition:
+      method: PUT`
+
+		mockProm := &MockProm{Res: model.Vector{}}
+		rsp, err := newTopRoutesServer(t, mockProm, invalidProfile).TopRoutes(context.TODO(), &booksReq)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		expected := "invalid ServiceProfile [books.default.svc.cluster.local] in namespace [default]: spec.routes[2]: duplicate route name [POST /books], already the name of spec.routes[0]"
+		if rsp.GetError().GetError() != expected {
+			t.Fatalf("Expected error [%s], got %v", expected, rsp)
+		}
+		if len(mockProm.QueriesExecuted) != 0 {
+			t.Fatalf("Expected no queries, got %v", mockProm.QueriesExecuted)
+		}
+	})
+
+	t.Run("Returns an error for requests that are not to a service", func(t *testing.T) {
+		messages := map[string]pb.TopRoutesRequest{
+			"routes are only reported for requests to a named service, by the routes of its ServiceProfile": {
+				Selector: &pb.ResourceSelection{
+					Resource: &pb.Resource{Namespace: "default", Type: pkgK8s.Deployment, Name: "web"},
+				},
+			},
+			"service only supported as a target on inbound queries, or as a destination on 'to' queries": {
+				Selector: booksReq.Selector,
+				Outbound: &pb.TopRoutesRequest_ToResource{
+					ToResource: &pb.Resource{Namespace: "default", Type: pkgK8s.Service, Name: "authors"},
+				},
+			},
+		}
+
+		for expected, req := range messages {
+			req := req
+			rsp, err := newTopRoutesServer(t, &MockProm{}, booksProfile).TopRoutes(context.TODO(), &req)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if rsp.GetError().GetError() != expected {
+				t.Fatalf("Expected error [%s], got %v", expected, rsp)
+			}
+		}
+	})
+}
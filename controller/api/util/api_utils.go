@@ -3,6 +3,7 @@ package util
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -59,6 +60,17 @@ type StatSummaryRequestParams struct {
 	FromType      string
 	FromName      string
 	AllNamespaces bool
+	TcpStats      bool
+}
+
+type TopRoutesRequestParams struct {
+	TimeWindow   string
+	Namespace    string
+	ResourceType string
+	ResourceName string
+	ToNamespace  string
+	ToType       string
+	ToName       string
 }
 
 type TapRequestParams struct {
@@ -71,6 +83,7 @@ type TapRequestParams struct {
 	Method      string
 	Authority   string
 	Path        string
+	Status      string
 }
 
 // GRPCError generates a gRPC error code, as defined in
@@ -140,6 +153,7 @@ func BuildStatSummaryRequest(p StatSummaryRequestParams) (*pb.StatSummaryRequest
 			},
 		},
 		TimeWindow: window,
+		TcpStats:   p.TcpStats,
 	}
 
 	if p.ToName != "" || p.ToType != "" || p.ToNamespace != "" {
@@ -191,6 +205,32 @@ func BuildStatSummaryRequest(p StatSummaryRequestParams) (*pb.StatSummaryRequest
 	return statRequest, nil
 }
 
+// BuildTopRoutesRequest builds a TopRoutesRequest from params, which are
+// validated and defaulted as those of BuildStatSummaryRequest are.
+func BuildTopRoutesRequest(p TopRoutesRequestParams) (*pb.TopRoutesRequest, error) {
+	statRequest, err := BuildStatSummaryRequest(StatSummaryRequestParams{
+		TimeWindow:   p.TimeWindow,
+		Namespace:    p.Namespace,
+		ResourceType: p.ResourceType,
+		ResourceName: p.ResourceName,
+		ToNamespace:  p.ToNamespace,
+		ToType:       p.ToType,
+		ToName:       p.ToName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	routesRequest := &pb.TopRoutesRequest{
+		Selector:   statRequest.Selector,
+		TimeWindow: statRequest.TimeWindow,
+	}
+	if toResource := statRequest.GetToResource(); toResource != nil {
+		routesRequest.Outbound = &pb.TopRoutesRequest_ToResource{ToResource: toResource}
+	}
+	return routesRequest, nil
+}
+
 // An authority can only receive traffic, not send it, so it can't be a --from
 func validateFromResourceType(resourceType string) (string, error) {
 	name, err := k8s.CanonicalResourceNameFromFriendlyName(resourceType)
@@ -300,6 +340,15 @@ func BuildTapByResourceRequest(params TapRequestParams) (*pb.TapByResourceReques
 		})
 		matches = append(matches, &match)
 	}
+	if params.Status != "" {
+		if _, err := ParseHTTPStatusMatch(params.Status); err != nil {
+			return nil, err
+		}
+		match := buildMatchHTTP(&pb.TapByResourceRequest_Match_Http{
+			Match: &pb.TapByResourceRequest_Match_Http_Status{Status: params.Status},
+		})
+		matches = append(matches, &match)
+	}
 
 	return &pb.TapByResourceRequest{
 		Target: &pb.ResourceSelection{
@@ -316,6 +365,30 @@ func BuildTapByResourceRequest(params TapRequestParams) (*pb.TapByResourceReques
 	}, nil
 }
 
+// HTTPStatusMatch matches the HTTP status codes between min and max.
+type HTTPStatusMatch struct {
+	min, max uint32
+}
+
+// ParseHTTPStatusMatch parses the status of a tap match: a code, like "503",
+// or a class of codes, like "5xx".
+func ParseHTTPStatusMatch(s string) (HTTPStatusMatch, error) {
+	if len(s) == 3 && strings.HasSuffix(strings.ToLower(s), "xx") && s[0] >= '1' && s[0] <= '5' {
+		min := uint32(s[0]-'0') * 100
+		return HTTPStatusMatch{min: min, max: min + 99}, nil
+	}
+	code, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || code < 100 || code > 599 {
+		return HTTPStatusMatch{}, fmt.Errorf("invalid HTTP status [%s], must be a code between 100 and 599, or a class from 1xx to 5xx", s)
+	}
+	return HTTPStatusMatch{min: uint32(code), max: uint32(code)}, nil
+}
+
+// Matches returns whether status matches m.
+func (m HTTPStatusMatch) Matches(status uint32) bool {
+	return status >= m.min && status <= m.max
+}
+
 func buildMatchHTTP(match *pb.TapByResourceRequest_Match_Http) pb.TapByResourceRequest_Match {
 	return pb.TapByResourceRequest_Match{
 		Match: &pb.TapByResourceRequest_Match_Http_{
@@ -396,10 +469,36 @@ func (p *peer) formatResource(resourceKind string) string {
 	return s
 }
 
+// workloadKinds are the kinds of the workloads labeled by formatWorkload, in
+// the order they are looked for in the labels of a peer, so that the pods of
+// a deployment are labeled with it rather than with their replica set.
+var workloadKinds = []string{
+	k8s.Deployment,
+	k8s.DaemonSet,
+	k8s.StatefulSet,
+	k8s.ReplicationController,
+	k8s.ReplicaSet,
+}
+
+// formatWorkload returns a label describing the workload the peer belongs to,
+// falling back to its pod, as formatResource does.
+func (p *peer) formatWorkload() string {
+	for _, kind := range workloadKinds {
+		if _, exists := p.labels[kind]; exists {
+			return p.formatResource(kind)
+		}
+	}
+	return p.formatResource(k8s.Pod)
+}
+
 func (p *peer) tlsStatus() string {
 	return p.labels["tls"]
 }
 
+// WorkloadResource may be given to RenderTapEvent as the resource, to label
+// the peers of events with the workloads they belong to, whatever their kind.
+const WorkloadResource = "workload"
+
 func RenderTapEvent(event *pb.TapEvent, resource string) string {
 	dst := dst(event)
 	src := src(event)
@@ -425,7 +524,13 @@ func RenderTapEvent(event *pb.TapEvent, resource string) string {
 	)
 
 	resources := ""
-	if resource != "" {
+	if resource == WorkloadResource {
+		resources = fmt.Sprintf(
+			"%s%s",
+			src.formatWorkload(),
+			dst.formatWorkload(),
+		)
+	} else if resource != "" {
 		resources = fmt.Sprintf(
 			"%s%s",
 			src.formatResource(resource),
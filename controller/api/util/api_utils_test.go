@@ -197,3 +197,68 @@ func TestBuildResource(t *testing.T) {
 		}
 	})
 }
+
+func TestBuildTapByResourceRequest(t *testing.T) {
+	t.Run("Requires all the HTTP matches given", func(t *testing.T) {
+		req, err := BuildTapByResourceRequest(TapRequestParams{
+			Resource: "deploy/web",
+			Method:   "POST",
+			Path:     "/api/checkout",
+			Status:   "5xx",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		matches := req.GetMatch().GetAll().GetMatches()
+		if len(matches) != 3 {
+			t.Fatalf("Expected 3 matches, got %d: %+v", len(matches), matches)
+		}
+		if method := matches[0].GetHttp().GetMethod(); method != "POST" {
+			t.Fatalf("Expected a method match, got %+v", matches[0])
+		}
+		if path := matches[1].GetHttp().GetPath(); path != "/api/checkout" {
+			t.Fatalf("Expected a path match, got %+v", matches[1])
+		}
+		if status := matches[2].GetHttp().GetStatus(); status != "5xx" {
+			t.Fatalf("Expected a status match, got %+v", matches[2])
+		}
+	})
+
+	t.Run("Rejects invalid statuses", func(t *testing.T) {
+		for _, status := range []string{"6xx", "0xx", "5x", "600", "99", "ok"} {
+			if _, err := BuildTapByResourceRequest(TapRequestParams{Resource: "deploy/web", Status: status}); err == nil {
+				t.Fatalf("Expected status [%s] to be rejected", status)
+			}
+		}
+	})
+}
+
+func TestParseHTTPStatusMatch(t *testing.T) {
+	testCases := []struct {
+		status     string
+		matches    []uint32
+		mismatches []uint32
+	}{
+		{"503", []uint32{503}, []uint32{500, 502, 504}},
+		{"5xx", []uint32{500, 503, 599}, []uint32{499, 200, 600}},
+		{"2XX", []uint32{200, 204}, []uint32{301, 503}},
+	}
+
+	for _, tc := range testCases {
+		match, err := ParseHTTPStatusMatch(tc.status)
+		if err != nil {
+			t.Fatalf("Unexpected error parsing [%s]: %v", tc.status, err)
+		}
+		for _, status := range tc.matches {
+			if !match.Matches(status) {
+				t.Fatalf("Expected [%s] to match %d", tc.status, status)
+			}
+		}
+		for _, status := range tc.mismatches {
+			if match.Matches(status) {
+				t.Fatalf("Expected [%s] not to match %d", tc.status, status)
+			}
+		}
+	}
+}
@@ -17,17 +17,27 @@ func main() {
 	metricsAddr := flag.String("metrics-addr", ":9997", "address to serve scrapable metrics on")
 	controllerNamespace := flag.String("controller-namespace", "linkerd", "namespace in which Linkerd is installed")
 	kubeConfigPath := flag.String("kubeconfig", "", "path to kube config")
+	kubeAPIQPS := flag.Float64("kube-api-qps", k8s.DefaultQPS, "maximum queries per second to the Kubernetes API (0 uses the client-go default)")
+	kubeAPIBurst := flag.Int("kube-api-burst", k8s.DefaultBurst, "maximum burst of queries to the Kubernetes API (0 uses the client-go default)")
+	singleNamespace := flag.Bool("single-namespace", false, "only watch the controller namespace, for control planes installed without cluster-wide access")
 	flags.ConfigureAndParse()
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
-	k8sClient, err := k8s.NewClientSet(*kubeConfigPath)
+	k8sClient, err := k8s.NewClientSet("ca", *kubeConfigPath, float32(*kubeAPIQPS), *kubeAPIBurst)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	k8sAPI := k8s.NewAPI(
+	watchedNamespace := ""
+	if *singleNamespace {
+		watchedNamespace = *controllerNamespace
+	}
+	k8sAPI := k8s.NewNamespacedAPI(
 		k8sClient,
+		nil,
+		watchedNamespace,
+		k8s.Job,
 		k8s.Pod,
 		k8s.RS,
 	)
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	injector "github.com/linkerd/linkerd2/controller/proxy-injector"
+	"github.com/linkerd/linkerd2/pkg/admin"
+	"github.com/linkerd/linkerd2/pkg/flags"
+	"github.com/linkerd/linkerd2/pkg/inject"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/version"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", ":8443", "address to serve the webhook on")
+	metricsAddr := flag.String("metrics-addr", ":9993", "address to serve scrapable metrics on")
+	controllerNamespace := flag.String("controller-namespace", "linkerd", "namespace in which Linkerd is installed")
+	kubeConfigPath := flag.String("kubeconfig", "", "path to kube config")
+	kubeAPIQPS := flag.Float64("kube-api-qps", k8s.DefaultQPS, "maximum queries per second to the Kubernetes API (0 uses the client-go default)")
+	kubeAPIBurst := flag.Int("kube-api-burst", k8s.DefaultBurst, "maximum burst of queries to the Kubernetes API (0 uses the client-go default)")
+	proxyConfigPath := flag.String("proxy-config", "/var/linkerd-io/config/proxy.json", "path to the JSON configuration of the injected proxy, recorded by linkerd install")
+	tlsCertPath := flag.String("tls-cert", "/var/linkerd-io/tls/tls.crt", "path to the certificate the webhook serves")
+	tlsKeyPath := flag.String("tls-key", "/var/linkerd-io/tls/tls.key", "path to the private key of the certificate the webhook serves")
+	defaultInject := flag.String("default-inject", pkgK8s.ProxyInjectEnabled, fmt.Sprintf("whether pods not annotated with %s, nor in a namespace that is, are injected: %s or %s", pkgK8s.ProxyInjectAnnotation, pkgK8s.ProxyInjectEnabled, pkgK8s.ProxyInjectDisabled))
+	flags.ConfigureAndParse()
+
+	if *defaultInject != pkgK8s.ProxyInjectEnabled && *defaultInject != pkgK8s.ProxyInjectDisabled {
+		log.Fatalf("-default-inject must be %s or %s, not [%s]", pkgK8s.ProxyInjectEnabled, pkgK8s.ProxyInjectDisabled, *defaultInject)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	config, err := readProxyConfig(*proxyConfigPath)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	config.CreatedBy = fmt.Sprintf("linkerd/proxy-injector %s", version.Version)
+
+	k8sClient, err := k8s.NewClientSet("proxy-injector", *kubeConfigPath, float32(*kubeAPIQPS), *kubeAPIBurst)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	k8sAPI := k8s.NewAPI(
+		k8sClient,
+		nil,
+		k8s.Job,
+		k8s.NS,
+		k8s.RS,
+	)
+
+	ready := make(chan struct{})
+	go k8sAPI.Sync(ready)
+
+	server := &http.Server{
+		Addr:    *addr,
+		Handler: injector.NewWebhook(k8sAPI, config, *controllerNamespace, *defaultInject),
+	}
+	go func() {
+		<-ready
+		log.Infof("starting proxy injector webhook on %s", *addr)
+		if err := server.ListenAndServeTLS(*tlsCertPath, *tlsKeyPath); err != http.ErrServerClosed {
+			log.Fatal(err.Error())
+		}
+	}()
+
+	go admin.StartServer(*metricsAddr, ready)
+
+	<-stop
+
+	log.Info("shutting down")
+	server.Close()
+}
+
+// readProxyConfig reads the configuration of the proxy to inject, recorded by
+// `linkerd install` in the install ConfigMap.
+func readProxyConfig(path string) (*inject.Config, error) {
+	configJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the proxy configuration: %v", err)
+	}
+	var config inject.Config
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, fmt.Errorf("invalid proxy configuration in [%s]: %v", path, err)
+	}
+	return &config, nil
+}
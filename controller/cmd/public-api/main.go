@@ -20,11 +20,14 @@ import (
 func main() {
 	addr := flag.String("addr", ":8085", "address to serve on")
 	kubeConfigPath := flag.String("kubeconfig", "", "path to kube config")
+	kubeAPIQPS := flag.Float64("kube-api-qps", k8s.DefaultQPS, "maximum queries per second to the Kubernetes API (0 uses the client-go default)")
+	kubeAPIBurst := flag.Int("kube-api-burst", k8s.DefaultBurst, "maximum burst of queries to the Kubernetes API (0 uses the client-go default)")
 	prometheusUrl := flag.String("prometheus-url", "http://127.0.0.1:9090", "prometheus url")
 	metricsAddr := flag.String("metrics-addr", ":9995", "address to serve scrapable metrics on")
 	tapAddr := flag.String("tap-addr", "127.0.0.1:8088", "address of tap service")
 	controllerNamespace := flag.String("controller-namespace", "linkerd", "namespace in which Linkerd is installed")
 	ignoredNamespaces := flag.String("ignore-namespaces", "kube-system", "comma separated list of namespaces to not list pods from")
+	singleNamespace := flag.Bool("single-namespace", false, "only watch the controller namespace, for control planes installed without cluster-wide access")
 	flags.ConfigureAndParse()
 
 	stop := make(chan os.Signal, 1)
@@ -36,18 +39,27 @@ func main() {
 	}
 	defer tapConn.Close()
 
-	k8sClient, err := k8s.NewClientSet(*kubeConfigPath)
+	k8sClient, err := k8s.NewClientSet("public-api", *kubeConfigPath, float32(*kubeAPIQPS), *kubeAPIBurst)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
-	k8sAPI := k8s.NewAPI(
+	spClient, err := k8s.NewServiceProfileClient("public-api", *kubeConfigPath, float32(*kubeAPIQPS), *kubeAPIBurst)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	resources := []k8s.ApiResource{k8s.Deploy, k8s.NS, k8s.Pod, k8s.RC, k8s.RS, k8s.Svc}
+	watchedNamespace := ""
+	if *singleNamespace {
+		watchedNamespace = *controllerNamespace
+	} else {
+		// the ServiceProfile CRD is not installed in a single namespace
+		resources = append(resources, k8s.SP)
+	}
+	k8sAPI := k8s.NewNamespacedAPI(
 		k8sClient,
-		k8s.Deploy,
-		k8s.NS,
-		k8s.Pod,
-		k8s.RC,
-		k8s.RS,
-		k8s.Svc,
+		spClient,
+		watchedNamespace,
+		resources...,
 	)
 
 	prometheusClient, err := promApi.NewClient(promApi.Config{Address: *prometheusUrl})
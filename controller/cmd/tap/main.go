@@ -17,19 +17,28 @@ func main() {
 	addr := flag.String("addr", "127.0.0.1:8088", "address to serve on")
 	metricsAddr := flag.String("metrics-addr", ":9998", "address to serve scrapable metrics on")
 	kubeConfigPath := flag.String("kubeconfig", "", "path to kube config")
+	kubeAPIQPS := flag.Float64("kube-api-qps", k8s.DefaultQPS, "maximum queries per second to the Kubernetes API (0 uses the client-go default)")
+	kubeAPIBurst := flag.Int("kube-api-burst", k8s.DefaultBurst, "maximum burst of queries to the Kubernetes API (0 uses the client-go default)")
 	controllerNamespace := flag.String("controller-namespace", "linkerd", "namespace in which Linkerd is installed")
 	tapPort := flag.Uint("tap-port", 4190, "proxy tap port to connect to")
+	singleNamespace := flag.Bool("single-namespace", false, "only watch the controller namespace, for control planes installed without cluster-wide access")
 	flags.ConfigureAndParse()
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
-	clientSet, err := k8s.NewClientSet(*kubeConfigPath)
+	clientSet, err := k8s.NewClientSet("tap", *kubeConfigPath, float32(*kubeAPIQPS), *kubeAPIBurst)
 	if err != nil {
 		log.Fatalf("failed to create Kubernetes client: %s", err)
 	}
-	k8sAPI := k8s.NewAPI(
+	watchedNamespace := ""
+	if *singleNamespace {
+		watchedNamespace = *controllerNamespace
+	}
+	k8sAPI := k8s.NewNamespacedAPI(
 		clientSet,
+		nil,
+		watchedNamespace,
 		k8s.Deploy,
 		k8s.NS,
 		k8s.Pod,
@@ -0,0 +1,4 @@
+package serviceprofile
+
+// GroupName is the API group of the ServiceProfile custom resource.
+const GroupName = "linkerd.io"
@@ -0,0 +1,6 @@
+// +k8s:deepcopy-gen=package
+
+// Package v1alpha1 is the v1alpha1 version of the ServiceProfile custom
+// resource, which describes the routes of a service.
+// +groupName=linkerd.io
+package v1alpha1
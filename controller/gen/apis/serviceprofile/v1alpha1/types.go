@@ -0,0 +1,46 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceProfile describes the routes of a service, by which the requests to
+// it are reported. It is named after the fully-qualified name of the service.
+type ServiceProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ServiceProfileSpec `json:"spec"`
+}
+
+// ServiceProfileSpec is the spec of a ServiceProfile.
+type ServiceProfileSpec struct {
+	Routes []*RouteSpec `json:"routes"`
+}
+
+// RouteSpec is a route of a ServiceProfile. The metrics of the requests
+// matching its condition are labeled with its name.
+type RouteSpec struct {
+	Name      string        `json:"name"`
+	Condition *RequestMatch `json:"condition"`
+}
+
+// RequestMatch matches the requests whose path matches PathRegex and whose
+// method is Method. Empty fields match every request.
+type RequestMatch struct {
+	PathRegex string `json:"pathRegex,omitempty"`
+	Method    string `json:"method,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServiceProfileList is a list of ServiceProfiles.
+type ServiceProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ServiceProfile `json:"items"`
+}
@@ -30,7 +30,11 @@ It has these top-level messages:
 	StatSummaryRequest
 	StatSummaryResponse
 	BasicStats
+	TcpStats
 	StatTable
+	TopRoutesRequest
+	TopRoutesResponse
+	RouteTable
 */
 package public
 
@@ -954,6 +958,7 @@ type TapByResourceRequest_Match_Http struct {
 	//	*TapByResourceRequest_Match_Http_Method
 	//	*TapByResourceRequest_Match_Http_Authority
 	//	*TapByResourceRequest_Match_Http_Path
+	//	*TapByResourceRequest_Match_Http_Status
 	Match isTapByResourceRequest_Match_Http_Match `protobuf_oneof:"match"`
 }
 
@@ -978,11 +983,15 @@ type TapByResourceRequest_Match_Http_Authority struct {
 type TapByResourceRequest_Match_Http_Path struct {
 	Path string `protobuf:"bytes,4,opt,name=path,oneof"`
 }
+type TapByResourceRequest_Match_Http_Status struct {
+	Status string `protobuf:"bytes,5,opt,name=status,oneof"`
+}
 
 func (*TapByResourceRequest_Match_Http_Scheme) isTapByResourceRequest_Match_Http_Match()    {}
 func (*TapByResourceRequest_Match_Http_Method) isTapByResourceRequest_Match_Http_Match()    {}
 func (*TapByResourceRequest_Match_Http_Authority) isTapByResourceRequest_Match_Http_Match() {}
 func (*TapByResourceRequest_Match_Http_Path) isTapByResourceRequest_Match_Http_Match()      {}
+func (*TapByResourceRequest_Match_Http_Status) isTapByResourceRequest_Match_Http_Match()    {}
 
 func (m *TapByResourceRequest_Match_Http) GetMatch() isTapByResourceRequest_Match_Http_Match {
 	if m != nil {
@@ -1019,6 +1028,13 @@ func (m *TapByResourceRequest_Match_Http) GetPath() string {
 	return ""
 }
 
+func (m *TapByResourceRequest_Match_Http) GetStatus() string {
+	if x, ok := m.GetMatch().(*TapByResourceRequest_Match_Http_Status); ok {
+		return x.Status
+	}
+	return ""
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*TapByResourceRequest_Match_Http) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _TapByResourceRequest_Match_Http_OneofMarshaler, _TapByResourceRequest_Match_Http_OneofUnmarshaler, _TapByResourceRequest_Match_Http_OneofSizer, []interface{}{
@@ -1026,6 +1042,7 @@ func (*TapByResourceRequest_Match_Http) XXX_OneofFuncs() (func(msg proto.Message
 		(*TapByResourceRequest_Match_Http_Method)(nil),
 		(*TapByResourceRequest_Match_Http_Authority)(nil),
 		(*TapByResourceRequest_Match_Http_Path)(nil),
+		(*TapByResourceRequest_Match_Http_Status)(nil),
 	}
 }
 
@@ -1045,6 +1062,9 @@ func _TapByResourceRequest_Match_Http_OneofMarshaler(msg proto.Message, b *proto
 	case *TapByResourceRequest_Match_Http_Path:
 		b.EncodeVarint(4<<3 | proto.WireBytes)
 		b.EncodeStringBytes(x.Path)
+	case *TapByResourceRequest_Match_Http_Status:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Status)
 	case nil:
 	default:
 		return fmt.Errorf("TapByResourceRequest_Match_Http.Match has unexpected type %T", x)
@@ -1083,6 +1103,13 @@ func _TapByResourceRequest_Match_Http_OneofUnmarshaler(msg proto.Message, tag, w
 		x, err := b.DecodeStringBytes()
 		m.Match = &TapByResourceRequest_Match_Http_Path{x}
 		return true, err
+	case 5: // match.status
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Match = &TapByResourceRequest_Match_Http_Status{x}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -1108,6 +1135,10 @@ func _TapByResourceRequest_Match_Http_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(4<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(len(x.Path)))
 		n += len(x.Path)
+	case *TapByResourceRequest_Match_Http_Status:
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Status)))
+		n += len(x.Status)
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -2327,6 +2358,9 @@ type StatSummaryRequest struct {
 	//	*StatSummaryRequest_ToResource
 	//	*StatSummaryRequest_FromResource
 	Outbound isStatSummaryRequest_Outbound `protobuf_oneof:"outbound"`
+	// Also query the TCP stats of the resources, returned in the tcp_stats of
+	// the rows.
+	TcpStats bool `protobuf:"varint,6,opt,name=tcp_stats,json=tcpStats" json:"tcp_stats,omitempty"`
 }
 
 func (m *StatSummaryRequest) Reset()                    { *m = StatSummaryRequest{} }
@@ -2392,6 +2426,13 @@ func (m *StatSummaryRequest) GetFromResource() *Resource {
 	return nil
 }
 
+func (m *StatSummaryRequest) GetTcpStats() bool {
+	if m != nil {
+		return m.TcpStats
+	}
+	return false
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*StatSummaryRequest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _StatSummaryRequest_OneofMarshaler, _StatSummaryRequest_OneofUnmarshaler, _StatSummaryRequest_OneofSizer, []interface{}{
@@ -2676,6 +2717,40 @@ func (m *BasicStats) GetTlsRequestCount() uint64 {
 	return 0
 }
 
+type TcpStats struct {
+	// number of TCP connections open at the end of the time window
+	OpenConnections uint64 `protobuf:"varint,1,opt,name=open_connections,json=openConnections" json:"open_connections,omitempty"`
+	// number of bytes read and written during the time window
+	ReadBytesTotal  uint64 `protobuf:"varint,2,opt,name=read_bytes_total,json=readBytesTotal" json:"read_bytes_total,omitempty"`
+	WriteBytesTotal uint64 `protobuf:"varint,3,opt,name=write_bytes_total,json=writeBytesTotal" json:"write_bytes_total,omitempty"`
+}
+
+func (m *TcpStats) Reset()                    { *m = TcpStats{} }
+func (m *TcpStats) String() string            { return proto.CompactTextString(m) }
+func (*TcpStats) ProtoMessage()               {}
+func (*TcpStats) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *TcpStats) GetOpenConnections() uint64 {
+	if m != nil {
+		return m.OpenConnections
+	}
+	return 0
+}
+
+func (m *TcpStats) GetReadBytesTotal() uint64 {
+	if m != nil {
+		return m.ReadBytesTotal
+	}
+	return 0
+}
+
+func (m *TcpStats) GetWriteBytesTotal() uint64 {
+	if m != nil {
+		return m.WriteBytesTotal
+	}
+	return 0
+}
+
 type StatTable struct {
 	// Types that are valid to be assigned to Table:
 	//	*StatTable_PodGroup_
@@ -2685,7 +2760,7 @@ type StatTable struct {
 func (m *StatTable) Reset()                    { *m = StatTable{} }
 func (m *StatTable) String() string            { return proto.CompactTextString(m) }
 func (*StatTable) ProtoMessage()               {}
-func (*StatTable) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+func (*StatTable) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 type isStatTable_Table interface{ isStatTable_Table() }
 
@@ -2771,7 +2846,7 @@ type StatTable_PodGroup struct {
 func (m *StatTable_PodGroup) Reset()                    { *m = StatTable_PodGroup{} }
 func (m *StatTable_PodGroup) String() string            { return proto.CompactTextString(m) }
 func (*StatTable_PodGroup) ProtoMessage()               {}
-func (*StatTable_PodGroup) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22, 0} }
+func (*StatTable_PodGroup) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23, 0} }
 
 func (m *StatTable_PodGroup) GetRows() []*StatTable_PodGroup_Row {
 	if m != nil {
@@ -2792,12 +2867,14 @@ type StatTable_PodGroup_Row struct {
 	Stats          *BasicStats `protobuf:"bytes,5,opt,name=stats" json:"stats,omitempty"`
 	// Stores a set of errors for each pod name. If a pod has no errors, it may be omitted.
 	ErrorsByPod map[string]*PodErrors `protobuf:"bytes,7,rep,name=errors_by_pod,json=errorsByPod" json:"errors_by_pod,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Only set if the tcp_stats of the request is set.
+	TcpStats *TcpStats `protobuf:"bytes,8,opt,name=tcp_stats,json=tcpStats" json:"tcp_stats,omitempty"`
 }
 
 func (m *StatTable_PodGroup_Row) Reset()                    { *m = StatTable_PodGroup_Row{} }
 func (m *StatTable_PodGroup_Row) String() string            { return proto.CompactTextString(m) }
 func (*StatTable_PodGroup_Row) ProtoMessage()               {}
-func (*StatTable_PodGroup_Row) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22, 0, 0} }
+func (*StatTable_PodGroup_Row) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23, 0, 0} }
 
 func (m *StatTable_PodGroup_Row) GetResource() *Resource {
 	if m != nil {
@@ -2848,6 +2925,319 @@ func (m *StatTable_PodGroup_Row) GetErrorsByPod() map[string]*PodErrors {
 	return nil
 }
 
+func (m *StatTable_PodGroup_Row) GetTcpStats() *TcpStats {
+	if m != nil {
+		return m.TcpStats
+	}
+	return nil
+}
+
+type TopRoutesRequest struct {
+	// The resource whose requests are reported. Without a to_resource, it must
+	// be a service with a ServiceProfile, whose inbound requests are reported.
+	Selector   *ResourceSelection `protobuf:"bytes,1,opt,name=selector" json:"selector,omitempty"`
+	TimeWindow string             `protobuf:"bytes,2,opt,name=time_window,json=timeWindow" json:"time_window,omitempty"`
+	// Types that are valid to be assigned to Outbound:
+	//	*TopRoutesRequest_None
+	//	*TopRoutesRequest_ToResource
+	Outbound isTopRoutesRequest_Outbound `protobuf_oneof:"outbound"`
+}
+
+func (m *TopRoutesRequest) Reset()                    { *m = TopRoutesRequest{} }
+func (m *TopRoutesRequest) String() string            { return proto.CompactTextString(m) }
+func (*TopRoutesRequest) ProtoMessage()               {}
+func (*TopRoutesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+type isTopRoutesRequest_Outbound interface{ isTopRoutesRequest_Outbound() }
+
+type TopRoutesRequest_None struct {
+	None *Empty `protobuf:"bytes,3,opt,name=none,oneof"`
+}
+type TopRoutesRequest_ToResource struct {
+	ToResource *Resource `protobuf:"bytes,4,opt,name=to_resource,json=toResource,oneof"`
+}
+
+func (*TopRoutesRequest_None) isTopRoutesRequest_Outbound()       {}
+func (*TopRoutesRequest_ToResource) isTopRoutesRequest_Outbound() {}
+
+func (m *TopRoutesRequest) GetOutbound() isTopRoutesRequest_Outbound {
+	if m != nil {
+		return m.Outbound
+	}
+	return nil
+}
+
+func (m *TopRoutesRequest) GetSelector() *ResourceSelection {
+	if m != nil {
+		return m.Selector
+	}
+	return nil
+}
+
+func (m *TopRoutesRequest) GetTimeWindow() string {
+	if m != nil {
+		return m.TimeWindow
+	}
+	return ""
+}
+
+func (m *TopRoutesRequest) GetNone() *Empty {
+	if x, ok := m.GetOutbound().(*TopRoutesRequest_None); ok {
+		return x.None
+	}
+	return nil
+}
+
+func (m *TopRoutesRequest) GetToResource() *Resource {
+	if x, ok := m.GetOutbound().(*TopRoutesRequest_ToResource); ok {
+		return x.ToResource
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*TopRoutesRequest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _TopRoutesRequest_OneofMarshaler, _TopRoutesRequest_OneofUnmarshaler, _TopRoutesRequest_OneofSizer, []interface{}{
+		(*TopRoutesRequest_None)(nil),
+		(*TopRoutesRequest_ToResource)(nil),
+	}
+}
+
+func _TopRoutesRequest_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*TopRoutesRequest)
+	// outbound
+	switch x := m.Outbound.(type) {
+	case *TopRoutesRequest_None:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.None); err != nil {
+			return err
+		}
+	case *TopRoutesRequest_ToResource:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.ToResource); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("TopRoutesRequest.Outbound has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _TopRoutesRequest_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*TopRoutesRequest)
+	switch tag {
+	case 3: // outbound.none
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Empty)
+		err := b.DecodeMessage(msg)
+		m.Outbound = &TopRoutesRequest_None{msg}
+		return true, err
+	case 4: // outbound.to_resource
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Resource)
+		err := b.DecodeMessage(msg)
+		m.Outbound = &TopRoutesRequest_ToResource{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _TopRoutesRequest_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*TopRoutesRequest)
+	// outbound
+	switch x := m.Outbound.(type) {
+	case *TopRoutesRequest_None:
+		s := proto.Size(x.None)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TopRoutesRequest_ToResource:
+		s := proto.Size(x.ToResource)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type TopRoutesResponse struct {
+	// Types that are valid to be assigned to Response:
+	//	*TopRoutesResponse_Ok
+	//	*TopRoutesResponse_Error
+	Response isTopRoutesResponse_Response `protobuf_oneof:"response"`
+}
+
+func (m *TopRoutesResponse) Reset()                    { *m = TopRoutesResponse{} }
+func (m *TopRoutesResponse) String() string            { return proto.CompactTextString(m) }
+func (*TopRoutesResponse) ProtoMessage()               {}
+func (*TopRoutesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+type isTopRoutesResponse_Response interface{ isTopRoutesResponse_Response() }
+
+type TopRoutesResponse_Ok struct {
+	Ok *RouteTable `protobuf:"bytes,1,opt,name=ok,oneof"`
+}
+type TopRoutesResponse_Error struct {
+	Error *ResourceError `protobuf:"bytes,2,opt,name=error,oneof"`
+}
+
+func (*TopRoutesResponse_Ok) isTopRoutesResponse_Response()    {}
+func (*TopRoutesResponse_Error) isTopRoutesResponse_Response() {}
+
+func (m *TopRoutesResponse) GetResponse() isTopRoutesResponse_Response {
+	if m != nil {
+		return m.Response
+	}
+	return nil
+}
+
+func (m *TopRoutesResponse) GetOk() *RouteTable {
+	if x, ok := m.GetResponse().(*TopRoutesResponse_Ok); ok {
+		return x.Ok
+	}
+	return nil
+}
+
+func (m *TopRoutesResponse) GetError() *ResourceError {
+	if x, ok := m.GetResponse().(*TopRoutesResponse_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*TopRoutesResponse) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _TopRoutesResponse_OneofMarshaler, _TopRoutesResponse_OneofUnmarshaler, _TopRoutesResponse_OneofSizer, []interface{}{
+		(*TopRoutesResponse_Ok)(nil),
+		(*TopRoutesResponse_Error)(nil),
+	}
+}
+
+func _TopRoutesResponse_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*TopRoutesResponse)
+	// response
+	switch x := m.Response.(type) {
+	case *TopRoutesResponse_Ok:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Ok); err != nil {
+			return err
+		}
+	case *TopRoutesResponse_Error:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Error); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("TopRoutesResponse.Response has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _TopRoutesResponse_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*TopRoutesResponse)
+	switch tag {
+	case 1: // response.ok
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(RouteTable)
+		err := b.DecodeMessage(msg)
+		m.Response = &TopRoutesResponse_Ok{msg}
+		return true, err
+	case 2: // response.error
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ResourceError)
+		err := b.DecodeMessage(msg)
+		m.Response = &TopRoutesResponse_Error{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _TopRoutesResponse_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*TopRoutesResponse)
+	// response
+	switch x := m.Response.(type) {
+	case *TopRoutesResponse_Ok:
+		s := proto.Size(x.Ok)
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *TopRoutesResponse_Error:
+		s := proto.Size(x.Error)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type RouteTable struct {
+	// The rows of the routes of the ServiceProfile, followed by that of the
+	// requests that matched none of them.
+	Rows []*RouteTable_Row `protobuf:"bytes,1,rep,name=rows" json:"rows,omitempty"`
+}
+
+func (m *RouteTable) Reset()                    { *m = RouteTable{} }
+func (m *RouteTable) String() string            { return proto.CompactTextString(m) }
+func (*RouteTable) ProtoMessage()               {}
+func (*RouteTable) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *RouteTable) GetRows() []*RouteTable_Row {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+type RouteTable_Row struct {
+	Route      string      `protobuf:"bytes,1,opt,name=route" json:"route,omitempty"`
+	TimeWindow string      `protobuf:"bytes,2,opt,name=time_window,json=timeWindow" json:"time_window,omitempty"`
+	Stats      *BasicStats `protobuf:"bytes,3,opt,name=stats" json:"stats,omitempty"`
+}
+
+func (m *RouteTable_Row) Reset()                    { *m = RouteTable_Row{} }
+func (m *RouteTable_Row) String() string            { return proto.CompactTextString(m) }
+func (*RouteTable_Row) ProtoMessage()               {}
+func (*RouteTable_Row) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26, 0} }
+
+func (m *RouteTable_Row) GetRoute() string {
+	if m != nil {
+		return m.Route
+	}
+	return ""
+}
+
+func (m *RouteTable_Row) GetTimeWindow() string {
+	if m != nil {
+		return m.TimeWindow
+	}
+	return ""
+}
+
+func (m *RouteTable_Row) GetStats() *BasicStats {
+	if m != nil {
+		return m.Stats
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Empty)(nil), "linkerd2.public.Empty")
 	proto.RegisterType((*VersionInfo)(nil), "linkerd2.public.VersionInfo")
@@ -2883,9 +3273,14 @@ func init() {
 	proto.RegisterType((*StatSummaryResponse)(nil), "linkerd2.public.StatSummaryResponse")
 	proto.RegisterType((*StatSummaryResponse_Ok)(nil), "linkerd2.public.StatSummaryResponse.Ok")
 	proto.RegisterType((*BasicStats)(nil), "linkerd2.public.BasicStats")
+	proto.RegisterType((*TcpStats)(nil), "linkerd2.public.TcpStats")
 	proto.RegisterType((*StatTable)(nil), "linkerd2.public.StatTable")
 	proto.RegisterType((*StatTable_PodGroup)(nil), "linkerd2.public.StatTable.PodGroup")
 	proto.RegisterType((*StatTable_PodGroup_Row)(nil), "linkerd2.public.StatTable.PodGroup.Row")
+	proto.RegisterType((*TopRoutesRequest)(nil), "linkerd2.public.TopRoutesRequest")
+	proto.RegisterType((*TopRoutesResponse)(nil), "linkerd2.public.TopRoutesResponse")
+	proto.RegisterType((*RouteTable)(nil), "linkerd2.public.RouteTable")
+	proto.RegisterType((*RouteTable_Row)(nil), "linkerd2.public.RouteTable.Row")
 	proto.RegisterEnum("linkerd2.public.HttpMethod_Registered", HttpMethod_Registered_name, HttpMethod_Registered_value)
 	proto.RegisterEnum("linkerd2.public.Scheme_Registered", Scheme_Registered_name, Scheme_Registered_value)
 	proto.RegisterEnum("linkerd2.public.TapEvent_ProxyDirection", TapEvent_ProxyDirection_name, TapEvent_ProxyDirection_value)
@@ -2903,6 +3298,9 @@ const _ = grpc.SupportPackageIsVersion4
 
 type ApiClient interface {
 	StatSummary(ctx context.Context, in *StatSummaryRequest, opts ...grpc.CallOption) (*StatSummaryResponse, error)
+	// Reports the stats of the requests to a service by the routes of its
+	// ServiceProfile.
+	TopRoutes(ctx context.Context, in *TopRoutesRequest, opts ...grpc.CallOption) (*TopRoutesResponse, error)
 	ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsResponse, error)
 	// Superceded by `TapByResource`.
 	Tap(ctx context.Context, in *TapRequest, opts ...grpc.CallOption) (Api_TapClient, error)
@@ -2929,6 +3327,15 @@ func (c *apiClient) StatSummary(ctx context.Context, in *StatSummaryRequest, opt
 	return out, nil
 }
 
+func (c *apiClient) TopRoutes(ctx context.Context, in *TopRoutesRequest, opts ...grpc.CallOption) (*TopRoutesResponse, error) {
+	out := new(TopRoutesResponse)
+	err := grpc.Invoke(ctx, "/linkerd2.public.Api/TopRoutes", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *apiClient) ListPods(ctx context.Context, in *ListPodsRequest, opts ...grpc.CallOption) (*ListPodsResponse, error) {
 	out := new(ListPodsResponse)
 	err := grpc.Invoke(ctx, "/linkerd2.public.Api/ListPods", in, out, c.cc, opts...)
@@ -3024,6 +3431,9 @@ func (c *apiClient) SelfCheck(ctx context.Context, in *linkerd2_common_healthche
 
 type ApiServer interface {
 	StatSummary(context.Context, *StatSummaryRequest) (*StatSummaryResponse, error)
+	// Reports the stats of the requests to a service by the routes of its
+	// ServiceProfile.
+	TopRoutes(context.Context, *TopRoutesRequest) (*TopRoutesResponse, error)
 	ListPods(context.Context, *ListPodsRequest) (*ListPodsResponse, error)
 	// Superceded by `TapByResource`.
 	Tap(*TapRequest, Api_TapServer) error
@@ -3055,6 +3465,24 @@ func _Api_StatSummary_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Api_TopRoutes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopRoutesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ApiServer).TopRoutes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/linkerd2.public.Api/TopRoutes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ApiServer).TopRoutes(ctx, req.(*TopRoutesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Api_ListPods_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListPodsRequest)
 	if err := dec(in); err != nil {
@@ -3159,6 +3587,10 @@ var _Api_serviceDesc = grpc.ServiceDesc{
 			MethodName: "StatSummary",
 			Handler:    _Api_StatSummary_Handler,
 		},
+		{
+			MethodName: "TopRoutes",
+			Handler:    _Api_TopRoutes_Handler,
+		},
 		{
 			MethodName: "ListPods",
 			Handler:    _Api_ListPods_Handler,
@@ -3190,160 +3622,172 @@ var _Api_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("public.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 2477 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x39, 0x3b, 0x73, 0x1b, 0xc9,
-	0xd1, 0x78, 0x2c, 0x5e, 0x0d, 0x80, 0x84, 0x46, 0x3a, 0x7d, 0x10, 0xee, 0x4a, 0x47, 0xad, 0x74,
-	0x3a, 0x96, 0xee, 0x33, 0x48, 0x41, 0x27, 0x9d, 0x28, 0x9d, 0x1f, 0x04, 0x09, 0x0b, 0xb4, 0x29,
-	0x12, 0x1a, 0x40, 0xbe, 0x2a, 0xd5, 0x55, 0xa1, 0x16, 0xd8, 0x21, 0xb1, 0xe6, 0x62, 0x67, 0xb5,
-	0x3b, 0x10, 0x0f, 0xa9, 0x23, 0x07, 0x0e, 0xed, 0xd8, 0xb1, 0x9d, 0xb8, 0x9c, 0xf8, 0x47, 0xf8,
-	0x0f, 0x38, 0xb3, 0x33, 0xa7, 0x4e, 0x1c, 0xdb, 0xae, 0x79, 0x2d, 0x16, 0x04, 0xf8, 0x90, 0x9c,
-	0x38, 0xc2, 0x74, 0x4f, 0x77, 0x6f, 0x4f, 0xbf, 0x67, 0x00, 0x25, 0x7f, 0x32, 0x70, 0x9d, 0x61,
-	0xdd, 0x0f, 0x28, 0xa3, 0x68, 0xd5, 0x75, 0xbc, 0x13, 0x12, 0xd8, 0x8d, 0xba, 0x44, 0xd7, 0x6e,
-	0x1f, 0x53, 0x7a, 0xec, 0x92, 0x0d, 0xb1, 0x3d, 0x98, 0x1c, 0x6d, 0xd8, 0x93, 0xc0, 0x62, 0x0e,
-	0xf5, 0x24, 0x43, 0xad, 0x3a, 0xa4, 0xe3, 0x31, 0xf5, 0x36, 0x46, 0xc4, 0x72, 0xd9, 0x68, 0x38,
-	0x22, 0xc3, 0x13, 0xb9, 0x63, 0xe6, 0x20, 0xd3, 0x1a, 0xfb, 0x6c, 0x6a, 0xbe, 0x85, 0xe2, 0xcf,
-	0x48, 0x10, 0x3a, 0xd4, 0xdb, 0xf3, 0x8e, 0x28, 0xfa, 0x04, 0x0a, 0xc7, 0x54, 0x21, 0xaa, 0xc9,
-	0xb5, 0xe4, 0x7a, 0x01, 0xcf, 0x10, 0x7c, 0x77, 0x30, 0x71, 0x5c, 0x7b, 0xd7, 0x62, 0xa4, 0x9a,
-	0x92, 0xbb, 0x11, 0x02, 0xdd, 0x87, 0x95, 0x80, 0xb8, 0xc4, 0x0a, 0x89, 0x16, 0x90, 0x16, 0x24,
-	0x67, 0xb0, 0xe6, 0x06, 0xac, 0xee, 0x3b, 0x21, 0xeb, 0x50, 0x3b, 0xc4, 0xe4, 0xed, 0x84, 0x84,
-	0x8c, 0x0b, 0xf6, 0xac, 0x31, 0x09, 0x7d, 0x6b, 0x48, 0xf4, 0x67, 0x23, 0x84, 0xf9, 0x35, 0x54,
-	0x66, 0x0c, 0xa1, 0x4f, 0xbd, 0x90, 0xa0, 0x75, 0x30, 0x7c, 0x6a, 0x87, 0xd5, 0xe4, 0x5a, 0x7a,
-	0xbd, 0xd8, 0xb8, 0x51, 0x3f, 0x63, 0x9a, 0x7a, 0x87, 0xda, 0x58, 0x50, 0x98, 0xbf, 0x32, 0x20,
-	0xdd, 0xa1, 0x36, 0x42, 0x60, 0x70, 0x91, 0x4a, 0xbc, 0x58, 0xa3, 0x1b, 0x90, 0xf1, 0xa9, 0xbd,
-	0xd7, 0x51, 0x87, 0x91, 0x00, 0x5a, 0x03, 0xb0, 0x89, 0xef, 0xd2, 0xe9, 0x98, 0x78, 0x4c, 0x1e,
-	0xa2, 0x9d, 0xc0, 0x31, 0x1c, 0xba, 0x03, 0xc5, 0x80, 0xf8, 0xae, 0x33, 0xb4, 0xfa, 0x21, 0x61,
-	0x55, 0xd0, 0x24, 0x0a, 0xd9, 0x25, 0x0c, 0x7d, 0x05, 0x37, 0x15, 0xc4, 0x1d, 0xd2, 0x1f, 0x52,
-	0x8f, 0x05, 0xd4, 0x75, 0x49, 0x50, 0x2d, 0x2a, 0xea, 0x8f, 0x62, 0xfb, 0x3b, 0xd1, 0x36, 0xba,
-	0x0b, 0xa5, 0x90, 0x59, 0x8c, 0x1c, 0x4d, 0x5c, 0x21, 0xbc, 0xa4, 0xc8, 0x8b, 0x1a, 0xcb, 0xa5,
-	0x7f, 0x0a, 0x60, 0x5b, 0x64, 0x4c, 0x3d, 0x41, 0x52, 0x56, 0x24, 0x05, 0x89, 0xe3, 0x04, 0x08,
-	0xd2, 0x3f, 0xa7, 0x83, 0xea, 0x8a, 0xda, 0xe1, 0x00, 0xba, 0x09, 0x59, 0x2e, 0x63, 0x12, 0x56,
-	0x0d, 0x71, 0x5c, 0x05, 0x71, 0x2b, 0x58, 0xb6, 0x4d, 0xec, 0x6a, 0x66, 0x2d, 0xb9, 0x9e, 0xc7,
-	0x12, 0x40, 0x3b, 0xb0, 0x1a, 0x3a, 0xde, 0x90, 0xec, 0x5b, 0x21, 0xc3, 0xc4, 0xa7, 0x01, 0xab,
-	0x66, 0xd7, 0x92, 0xeb, 0xc5, 0xc6, 0xad, 0xba, 0x0c, 0xbb, 0xba, 0x0e, 0xbb, 0xfa, 0xae, 0x0a,
-	0x3b, 0x7c, 0x96, 0x03, 0x6d, 0xc2, 0xf5, 0xd9, 0xc9, 0x0f, 0x22, 0x17, 0xe7, 0xc4, 0xf7, 0x97,
-	0x6d, 0x21, 0x13, 0x4a, 0x0a, 0xdd, 0x71, 0x2d, 0x8f, 0x54, 0xf3, 0x42, 0xa7, 0x39, 0x1c, 0x7a,
-	0x08, 0xd9, 0x89, 0xcf, 0x9c, 0x31, 0xa9, 0x16, 0x2e, 0xd3, 0x48, 0x11, 0x36, 0x73, 0x90, 0xa1,
-	0xa7, 0x1e, 0x09, 0xcc, 0xdf, 0xa7, 0x00, 0x7a, 0x96, 0xaf, 0x23, 0x0f, 0x41, 0xda, 0xa7, 0xb6,
-	0x0c, 0x0a, 0x6e, 0x27, 0x9f, 0xda, 0x67, 0xfc, 0x9f, 0x5a, 0xe2, 0xff, 0x9b, 0x90, 0x1d, 0x5b,
-	0xdf, 0x61, 0x3f, 0x14, 0xd1, 0x91, 0xc2, 0x0a, 0xe2, 0x78, 0x46, 0x3b, 0xdc, 0x54, 0xdc, 0xc2,
-	0x65, 0xac, 0x20, 0x1e, 0x7b, 0x8c, 0xee, 0x75, 0x84, 0x81, 0x0b, 0x58, 0xac, 0x51, 0x0d, 0xf2,
-	0x47, 0x01, 0x1d, 0x77, 0xb4, 0x61, 0xcb, 0x38, 0x82, 0xb9, 0x1c, 0xbe, 0xde, 0xeb, 0x28, 0x4b,
-	0x29, 0x48, 0x78, 0x70, 0x38, 0x22, 0x63, 0x69, 0x16, 0xee, 0x41, 0x01, 0x09, 0x7d, 0x08, 0x1b,
-	0x51, 0x5b, 0x18, 0xa4, 0x80, 0x15, 0xc4, 0xf3, 0xca, 0x9a, 0xb0, 0x11, 0x0d, 0x1c, 0x36, 0x95,
-	0x51, 0x8a, 0x67, 0x08, 0xae, 0x95, 0x6f, 0xb1, 0x91, 0x0c, 0x48, 0x2c, 0xd6, 0xcf, 0x52, 0xd5,
-	0x64, 0x33, 0x0f, 0x59, 0x66, 0x05, 0xc7, 0x84, 0x99, 0x7f, 0xcf, 0xc0, 0x8d, 0x9e, 0xe5, 0x37,
-	0xa7, 0x98, 0x84, 0x74, 0x12, 0x0c, 0x89, 0x36, 0xdb, 0x33, 0x4d, 0x22, 0x2c, 0x57, 0x6c, 0x98,
-	0x0b, 0x09, 0xa8, 0x39, 0xba, 0xc4, 0x25, 0x43, 0xe9, 0x0a, 0xc9, 0x81, 0xb6, 0x21, 0x33, 0xb6,
-	0xd8, 0x70, 0x24, 0x2c, 0x5b, 0x6c, 0x7c, 0xb1, 0xc0, 0xba, 0xec, 0x8b, 0xf5, 0x97, 0x9c, 0x05,
-	0x4b, 0xce, 0xf3, 0xec, 0x5f, 0xfb, 0x93, 0x01, 0x19, 0x41, 0x88, 0x76, 0x20, 0x6d, 0xb9, 0xae,
-	0xd2, 0x6e, 0xe3, 0x3d, 0x3e, 0x51, 0xef, 0x92, 0xb7, 0x3c, 0x10, 0x2c, 0xd7, 0x15, 0x42, 0xbc,
-	0xa9, 0xd2, 0xf3, 0x83, 0x84, 0x78, 0x53, 0xf4, 0x43, 0x48, 0x7b, 0x54, 0x96, 0x91, 0xf7, 0x3b,
-	0x2c, 0x17, 0xe0, 0x51, 0x86, 0xda, 0x50, 0xb2, 0x49, 0xc8, 0x1c, 0x4f, 0x44, 0xb4, 0x4c, 0xde,
-	0x2b, 0x59, 0xbc, 0x9d, 0xc0, 0x73, 0x9c, 0xe8, 0xc7, 0x60, 0x8c, 0x18, 0xf3, 0x45, 0x18, 0x16,
-	0x1b, 0x9b, 0xef, 0x73, 0xa0, 0x36, 0x63, 0x7e, 0x3b, 0x81, 0x05, 0x7f, 0x6d, 0x1f, 0xd2, 0x5d,
-	0xf2, 0x16, 0xb5, 0x20, 0x27, 0xdc, 0x41, 0x74, 0x19, 0x7e, 0x2f, 0x57, 0x6a, 0xde, 0xda, 0x14,
-	0x0c, 0x2e, 0x1d, 0x55, 0xa3, 0xe0, 0xd6, 0xd9, 0xa8, 0xc3, 0xbb, 0x1a, 0x85, 0xb7, 0x4e, 0x46,
-	0x1d, 0xe0, 0xb7, 0xe3, 0x01, 0xae, 0x2b, 0x75, 0x2c, 0xc4, 0x6f, 0xa8, 0x10, 0x37, 0xd4, 0x96,
-	0x80, 0x78, 0x31, 0x10, 0x1f, 0x8f, 0x16, 0xe6, 0x3f, 0x93, 0x00, 0x5c, 0x89, 0x97, 0x52, 0x6c,
-	0x1b, 0x20, 0x20, 0xc7, 0x4e, 0xc8, 0x48, 0x40, 0x64, 0x71, 0x58, 0x69, 0xdc, 0x5f, 0x38, 0xdc,
-	0x8c, 0xa1, 0x8e, 0x23, 0x6a, 0xd9, 0x06, 0x34, 0x84, 0xee, 0x41, 0x69, 0xe2, 0xc5, 0x64, 0xe9,
-	0x03, 0xcc, 0x61, 0x4d, 0x0f, 0x60, 0x26, 0x01, 0xe5, 0x20, 0xfd, 0xa2, 0xd5, 0xab, 0x24, 0x50,
-	0x1e, 0x8c, 0xce, 0x61, 0xb7, 0x57, 0x49, 0x72, 0x54, 0xe7, 0x75, 0xaf, 0x92, 0x42, 0x00, 0xd9,
-	0xdd, 0xd6, 0x7e, 0xab, 0xd7, 0xaa, 0xa4, 0x51, 0x01, 0x32, 0x9d, 0xed, 0xde, 0x4e, 0xbb, 0x62,
-	0xa0, 0x22, 0xe4, 0x0e, 0x3b, 0xbd, 0xbd, 0xc3, 0x83, 0x6e, 0x25, 0xc3, 0x81, 0x9d, 0xc3, 0x83,
-	0x83, 0xd6, 0x4e, 0xaf, 0x92, 0xe5, 0x32, 0xda, 0xad, 0xed, 0xdd, 0x4a, 0x8e, 0x93, 0xf7, 0xf0,
-	0xf6, 0x4e, 0xab, 0x92, 0x6f, 0x66, 0xc1, 0x60, 0x53, 0x9f, 0x98, 0xbf, 0x4d, 0x42, 0xb6, 0x2b,
-	0x6d, 0xbc, 0xbb, 0xe4, 0xc8, 0x8b, 0x31, 0x26, 0x89, 0xff, 0xdb, 0xe3, 0xde, 0x99, 0x3b, 0x2e,
-	0xd7, 0xb0, 0xd7, 0xeb, 0x54, 0x12, 0x5c, 0x43, 0xbe, 0xea, 0x56, 0x92, 0x91, 0x86, 0x3d, 0x28,
-	0xec, 0x75, 0xb6, 0x6d, 0x3b, 0x20, 0x21, 0x6f, 0x54, 0x86, 0xe3, 0xbf, 0xfb, 0x52, 0x68, 0x97,
-	0xe3, 0xde, 0xe4, 0x10, 0xfa, 0x42, 0x60, 0x9f, 0xa8, 0x34, 0xfd, 0x68, 0x41, 0xe7, 0xbd, 0xce,
-	0xbb, 0x27, 0x8a, 0xf8, 0x49, 0xd3, 0x80, 0x94, 0xe3, 0x9b, 0x9b, 0x60, 0x70, 0x2c, 0xef, 0x7c,
-	0x47, 0x4e, 0x10, 0xca, 0x2a, 0x96, 0xc5, 0x12, 0xe0, 0x75, 0xd1, 0xb5, 0x42, 0x59, 0xf9, 0xb3,
-	0x58, 0xac, 0xcd, 0x7d, 0x80, 0xde, 0xd0, 0xd7, 0x8a, 0x3c, 0xe0, 0x52, 0x54, 0x71, 0xa9, 0x2d,
-	0xf9, 0xa0, 0xa2, 0xc3, 0x29, 0xc7, 0x17, 0x55, 0x96, 0xd7, 0xf8, 0x94, 0xa8, 0xf1, 0x62, 0x6d,
-	0xda, 0x90, 0x6e, 0x51, 0x2e, 0xa6, 0x72, 0x1c, 0xf8, 0xc3, 0xbe, 0xec, 0xc3, 0xfd, 0x21, 0xb5,
-	0x65, 0xec, 0x97, 0xdb, 0x09, 0xbc, 0xc2, 0x77, 0xba, 0x62, 0x63, 0x87, 0xda, 0x84, 0xd3, 0x06,
-	0x24, 0x24, 0xac, 0x4f, 0x82, 0x80, 0x06, 0x92, 0x36, 0xa5, 0x69, 0xc5, 0x4e, 0x8b, 0x6f, 0x70,
-	0xda, 0x66, 0x06, 0xd2, 0xc4, 0xb3, 0xcd, 0x7f, 0x97, 0x20, 0xdf, 0xb3, 0xfc, 0xd6, 0x3b, 0xde,
-	0xb2, 0x1e, 0x41, 0x56, 0x66, 0xa1, 0x52, 0xfb, 0xe3, 0xc5, 0x5c, 0x8d, 0xce, 0x87, 0x15, 0x29,
-	0x7a, 0x01, 0x45, 0xb9, 0xea, 0x8f, 0x09, 0xb3, 0x54, 0xdd, 0xb8, 0xbf, 0x2c, 0xcb, 0xc5, 0x47,
-	0xea, 0x2d, 0xcf, 0xf6, 0xa9, 0xe3, 0xb1, 0x97, 0x84, 0x59, 0x18, 0x24, 0x2b, 0x5f, 0xa3, 0xef,
-	0x43, 0x31, 0x56, 0x89, 0x94, 0xab, 0x2e, 0x54, 0x21, 0x4e, 0x8f, 0x5e, 0x41, 0x25, 0x06, 0x4a,
-	0x65, 0x8c, 0xf7, 0x52, 0x66, 0x35, 0xc6, 0x2f, 0x34, 0x7a, 0x05, 0xab, 0x7e, 0x40, 0xbf, 0x9b,
-	0xf6, 0x6d, 0x27, 0x90, 0xe5, 0x52, 0x74, 0xe1, 0x95, 0xc6, 0xfa, 0xf9, 0x12, 0x3b, 0x9c, 0x61,
-	0x57, 0xd3, 0xe3, 0x15, 0x7f, 0x0e, 0x46, 0x5f, 0xaa, 0xf2, 0x2a, 0x4b, 0xfd, 0xed, 0xf3, 0xe5,
-	0xcc, 0x15, 0xd3, 0xdf, 0x24, 0xa1, 0x14, 0x57, 0x15, 0xfd, 0x04, 0xb2, 0xae, 0x35, 0x20, 0xae,
-	0xae, 0xaa, 0x8d, 0xab, 0x1d, 0xb1, 0xbe, 0x2f, 0x98, 0x5a, 0x1e, 0x0b, 0xa6, 0x58, 0x49, 0xa8,
-	0x6d, 0x41, 0x31, 0x86, 0x46, 0x15, 0x48, 0x9f, 0x90, 0xa9, 0x1a, 0x81, 0xf9, 0x92, 0x67, 0xc0,
-	0x3b, 0xcb, 0x9d, 0xe8, 0x71, 0x5e, 0x02, 0xcf, 0x52, 0x4f, 0x93, 0xb5, 0x7f, 0xe5, 0x54, 0x5d,
-	0x3e, 0x84, 0x52, 0x20, 0x2b, 0x77, 0xdf, 0xf1, 0x1c, 0xdd, 0xf1, 0x1f, 0x5c, 0x7c, 0xbc, 0xba,
-	0x2a, 0xf6, 0x7b, 0x9e, 0xc3, 0xf8, 0xf0, 0x1a, 0xcc, 0x40, 0x84, 0xa1, 0x1c, 0xa8, 0x39, 0x5e,
-	0x4a, 0xbc, 0x60, 0x10, 0x98, 0x93, 0x28, 0x79, 0x94, 0xc8, 0x52, 0x10, 0x83, 0xa5, 0x92, 0x4a,
-	0x26, 0xf1, 0x6c, 0xe5, 0x83, 0x07, 0x57, 0x14, 0xd9, 0xf2, 0x6c, 0xa9, 0x64, 0x04, 0xd6, 0x9e,
-	0x40, 0xbe, 0xcb, 0x02, 0x62, 0x8d, 0xf7, 0xc4, 0xd5, 0x61, 0x60, 0x85, 0x2a, 0x37, 0xb1, 0x58,
-	0xcb, 0x61, 0x9a, 0xef, 0x0b, 0xed, 0x0d, 0xac, 0xa0, 0xda, 0x5f, 0x93, 0x50, 0x8c, 0x9d, 0x1d,
-	0x7d, 0x05, 0x29, 0xc7, 0x56, 0x36, 0xfb, 0xfc, 0x12, 0x75, 0xf4, 0x07, 0x71, 0xca, 0xb1, 0x79,
-	0xc2, 0xc6, 0x9a, 0xde, 0xb2, 0x6c, 0x99, 0xf5, 0x9f, 0xa8, 0x1f, 0x6e, 0x44, 0x3d, 0x54, 0x1a,
-	0xe0, 0xff, 0xce, 0xa9, 0xe0, 0x51, 0x6b, 0x9d, 0x9b, 0x10, 0x8d, 0xf3, 0x26, 0xc4, 0xcc, 0x6c,
-	0x42, 0xac, 0xfd, 0x31, 0x09, 0xa5, 0xb8, 0x2b, 0x3e, 0xfc, 0x84, 0x2f, 0x00, 0x89, 0xfb, 0x42,
-	0x7f, 0x2e, 0xbc, 0x52, 0x97, 0x8d, 0xf4, 0x15, 0xc1, 0x14, 0xb7, 0xf1, 0xa7, 0x50, 0xe4, 0xa9,
-	0xa4, 0xea, 0xa8, 0x38, 0x7a, 0x19, 0x03, 0x47, 0xc9, 0x02, 0x5a, 0xfb, 0x5d, 0x8a, 0x3b, 0x25,
-	0x72, 0xee, 0xff, 0x80, 0xca, 0x7b, 0x70, 0x5d, 0x0b, 0x8a, 0x67, 0x42, 0xfa, 0x32, 0x49, 0xd7,
-	0x94, 0xa4, 0x98, 0xfd, 0x3f, 0xe3, 0xf7, 0x6e, 0x25, 0x64, 0x30, 0x65, 0x44, 0x4e, 0x88, 0x06,
-	0x8e, 0x92, 0xac, 0xc9, 0x91, 0xe8, 0x3e, 0xa4, 0x09, 0x0d, 0x55, 0x0d, 0x5f, 0xbc, 0x30, 0xb7,
-	0x68, 0x88, 0x39, 0x01, 0x9f, 0x89, 0x08, 0x3f, 0xbd, 0xf9, 0x14, 0x56, 0xe6, 0x0b, 0x1e, 0x1f,
-	0x2c, 0x5e, 0x1f, 0xfc, 0xf4, 0xe0, 0xf0, 0x9b, 0x83, 0x4a, 0x82, 0x03, 0x7b, 0x07, 0xcd, 0xc3,
-	0xd7, 0x07, 0xbb, 0x95, 0x24, 0x2a, 0x41, 0xfe, 0xf0, 0x75, 0x4f, 0x42, 0xa9, 0x99, 0x88, 0x35,
-	0xc8, 0x6f, 0xfb, 0x8e, 0x68, 0x4c, 0xbc, 0xd2, 0x88, 0xd6, 0xa5, 0xaa, 0x8f, 0x04, 0xf8, 0x75,
-	0xac, 0xd0, 0xa1, 0xb6, 0x20, 0x09, 0xd1, 0x73, 0xc8, 0x0a, 0xb4, 0x2e, 0x7d, 0x77, 0x97, 0xdd,
-	0xeb, 0x25, 0x6d, 0xb4, 0xc2, 0x8a, 0xa5, 0xf6, 0xb7, 0x24, 0xe4, 0x35, 0x12, 0x61, 0x28, 0xf0,
-	0x2b, 0xa3, 0xe5, 0x78, 0x24, 0x50, 0x8e, 0x6e, 0x5c, 0x41, 0x58, 0x7d, 0x47, 0x33, 0x09, 0x90,
-	0x0f, 0x93, 0x91, 0x98, 0xda, 0x3b, 0x58, 0x99, 0xdf, 0x46, 0x55, 0xc8, 0x8d, 0x49, 0x18, 0x5a,
-	0xc7, 0xfa, 0x59, 0x41, 0x83, 0x3c, 0xaf, 0x66, 0xdf, 0x57, 0x4f, 0x25, 0x11, 0x82, 0xdb, 0xc2,
-	0x19, 0x73, 0x2e, 0xf9, 0x42, 0x22, 0x01, 0x5e, 0x52, 0x02, 0x62, 0x85, 0xd4, 0xd3, 0xf7, 0x73,
-	0x09, 0x09, 0x73, 0x0a, 0x63, 0x75, 0x20, 0xaf, 0x67, 0xe9, 0x8b, 0x9f, 0x4c, 0xc4, 0x85, 0x73,
-	0xea, 0xeb, 0xaa, 0x2e, 0xd6, 0xd1, 0x03, 0x48, 0x7a, 0xf6, 0x00, 0x62, 0xbe, 0x85, 0x6b, 0x0b,
-	0xd7, 0x06, 0xf4, 0x18, 0xf2, 0x01, 0x99, 0x1b, 0x16, 0x6e, 0x9d, 0x7b, 0xd9, 0xc0, 0x11, 0x29,
-	0x8f, 0x43, 0xd1, 0x75, 0xfa, 0xa1, 0x90, 0x44, 0xf5, 0xb9, 0xcb, 0x02, 0xdb, 0x55, 0x48, 0xf3,
-	0x5b, 0x28, 0x6b, 0x66, 0x69, 0xc4, 0x0f, 0xfc, 0x5c, 0x14, 0x4f, 0xa9, 0x78, 0x3c, 0xfd, 0x21,
-	0x05, 0x88, 0x27, 0x7d, 0x77, 0x32, 0x1e, 0x5b, 0xc1, 0x54, 0xdf, 0x57, 0x7f, 0x00, 0xf9, 0x48,
-	0xab, 0xab, 0xdf, 0x58, 0x23, 0x1e, 0x5e, 0x61, 0x98, 0x33, 0x26, 0xfd, 0x53, 0xc7, 0xb3, 0xe9,
-	0xa9, 0xfa, 0x24, 0x70, 0xd4, 0x37, 0x02, 0x83, 0xfe, 0x1f, 0x0c, 0x8f, 0x7a, 0xba, 0xec, 0xde,
-	0x5c, 0x4c, 0xaf, 0xb1, 0xcf, 0xa6, 0xbc, 0xe7, 0x73, 0x2a, 0xf4, 0x35, 0x14, 0x19, 0xed, 0x47,
-	0xa7, 0x36, 0x2e, 0x39, 0x35, 0x1f, 0xb2, 0x19, 0x8d, 0x5c, 0xff, 0x23, 0x28, 0x1f, 0x05, 0x74,
-	0x3c, 0xe3, 0xcf, 0x5c, 0xce, 0x5f, 0xe2, 0x1c, 0x1a, 0x6e, 0x02, 0xe4, 0xe9, 0x84, 0x0d, 0xe8,
-	0xc4, 0xb3, 0xcd, 0xbf, 0x24, 0xe1, 0xfa, 0x9c, 0xc5, 0xd4, 0x0b, 0xdb, 0x16, 0xa4, 0xe8, 0xc9,
-	0xb9, 0x35, 0x72, 0x09, 0x47, 0xfd, 0xf0, 0xa4, 0x9d, 0xc0, 0x29, 0x7a, 0x82, 0x9e, 0xc4, 0x5d,
-	0xb3, 0x6c, 0x12, 0x9a, 0x0b, 0x80, 0x76, 0x42, 0x39, 0xaf, 0xb6, 0x0d, 0xa9, 0xc3, 0x13, 0xf4,
-	0x1c, 0xc4, 0x53, 0x57, 0x9f, 0x59, 0x03, 0x37, 0xba, 0x5a, 0xd6, 0x96, 0x6a, 0xd0, 0xe3, 0x24,
-	0x18, 0x42, 0xbd, 0x0c, 0xf9, 0xc9, 0x74, 0xd9, 0x13, 0x97, 0xba, 0xa6, 0x15, 0x3a, 0x62, 0x8c,
-	0x0e, 0xd1, 0x5d, 0x28, 0x87, 0x93, 0xe1, 0x90, 0x84, 0x7c, 0xd2, 0x9e, 0x78, 0x72, 0x90, 0x31,
-	0x70, 0x49, 0x21, 0x77, 0x38, 0x8e, 0x13, 0x1d, 0x59, 0x8e, 0x3b, 0x09, 0x88, 0x22, 0x92, 0xdd,
-	0xbd, 0xa4, 0x90, 0x92, 0xe8, 0x1e, 0x8f, 0x74, 0x46, 0xbc, 0xe1, 0xb4, 0x3f, 0x0e, 0xfb, 0xfe,
-	0xe3, 0x4d, 0xe1, 0x76, 0x03, 0x97, 0x14, 0xf6, 0x65, 0xd8, 0x79, 0xbc, 0x79, 0x96, 0x6a, 0xeb,
-	0xb1, 0xaa, 0xcb, 0x31, 0xaa, 0xad, 0xc7, 0x0b, 0x54, 0x5b, 0xc2, 0x9b, 0xf3, 0x54, 0x5b, 0xe8,
-	0x01, 0x5c, 0x63, 0x6e, 0x18, 0x75, 0x1d, 0xa9, 0x5a, 0x56, 0x10, 0xae, 0x32, 0x57, 0xbf, 0xa3,
-	0x0a, 0xed, 0xcc, 0x7f, 0x18, 0x50, 0x88, 0x8c, 0x83, 0x9a, 0x50, 0xf0, 0xa9, 0xdd, 0x3f, 0x0e,
-	0xe8, 0x44, 0xdf, 0x58, 0xee, 0x9e, 0x6f, 0x4b, 0x5e, 0x08, 0x5f, 0x70, 0xd2, 0x76, 0x02, 0xe7,
-	0x7d, 0xb5, 0xae, 0xfd, 0xda, 0x10, 0x95, 0x55, 0x00, 0xe8, 0x39, 0x18, 0x01, 0x3d, 0xd5, 0x7e,
-	0xf9, 0xfc, 0x0a, 0xb2, 0xea, 0x98, 0x9e, 0x62, 0xc1, 0x54, 0xfb, 0x73, 0x1a, 0xd2, 0x98, 0x9e,
-	0x7e, 0x68, 0xce, 0x5f, 0x9a, 0x86, 0xeb, 0x50, 0x19, 0x93, 0x70, 0x44, 0xec, 0x3e, 0x3f, 0xb4,
-	0x34, 0x93, 0xf4, 0xcd, 0x8a, 0xc4, 0x77, 0xa8, 0x2d, 0x7d, 0xf8, 0x00, 0xae, 0x05, 0x13, 0xcf,
-	0x73, 0xbc, 0xe3, 0x18, 0xa9, 0x74, 0xd0, 0xaa, 0xda, 0x88, 0x68, 0xd7, 0xa1, 0xc2, 0xfd, 0x3f,
-	0x27, 0x55, 0x1a, 0x7f, 0x45, 0xe2, 0x23, 0xca, 0x87, 0x90, 0xe1, 0xc1, 0xa8, 0xdb, 0xec, 0xe2,
-	0xcc, 0x36, 0x8b, 0x47, 0x2c, 0x29, 0xd1, 0xb7, 0x50, 0x96, 0x0d, 0xac, 0x3f, 0x98, 0x72, 0xf9,
-	0xd5, 0x9c, 0x30, 0xec, 0xd3, 0x2b, 0x1a, 0xb6, 0x2e, 0x3b, 0x58, 0x73, 0xca, 0x5b, 0x98, 0x98,
-	0xfd, 0x8b, 0x64, 0x86, 0xa9, 0xbd, 0x81, 0xca, 0x59, 0x82, 0x25, 0xb7, 0x80, 0xcd, 0xf8, 0x2d,
-	0x60, 0x59, 0xb2, 0x45, 0x9d, 0x32, 0x76, 0x43, 0xe0, 0x7d, 0x49, 0xe4, 0x68, 0xe3, 0x17, 0x06,
-	0xa4, 0xb7, 0x7d, 0x07, 0xbd, 0x81, 0x62, 0xac, 0x2e, 0xa0, 0xbb, 0x17, 0x57, 0x0d, 0x11, 0xb2,
-	0xb5, 0x7b, 0x57, 0x29, 0x2d, 0x66, 0x02, 0xbd, 0x82, 0xbc, 0xfe, 0x13, 0x00, 0xad, 0x2d, 0xf0,
-	0x9c, 0xf9, 0x43, 0xa1, 0x76, 0xe7, 0x02, 0x8a, 0x48, 0xe4, 0x2e, 0xa4, 0x7b, 0x96, 0x8f, 0x3e,
-	0x5e, 0x36, 0x00, 0x6a, 0x41, 0xb7, 0xce, 0x9d, 0x0e, 0xcd, 0xf4, 0x2f, 0x53, 0xc9, 0xcd, 0x24,
-	0x7a, 0x0d, 0xe5, 0xb9, 0x57, 0x2e, 0xf4, 0xd9, 0x95, 0x5e, 0xc1, 0x2e, 0x92, 0x9c, 0xd8, 0x4c,
-	0xa2, 0x6d, 0xc8, 0xe9, 0xbf, 0x5d, 0xce, 0xe9, 0x26, 0xb5, 0x4f, 0x16, 0xf0, 0xb1, 0xbf, 0x72,
-	0xcc, 0x04, 0x72, 0xa1, 0xd0, 0x25, 0xee, 0xd1, 0xce, 0x88, 0x0c, 0x4f, 0xd0, 0xf7, 0x66, 0xc4,
-	0xf2, 0x5f, 0xa1, 0x7a, 0xfc, 0x5f, 0xa1, 0x88, 0x4e, 0x6b, 0x57, 0xbf, 0x2a, 0xb9, 0xb6, 0x66,
-	0xf3, 0xd1, 0x9b, 0x87, 0xc7, 0x0e, 0x1b, 0x4d, 0x06, 0x9c, 0x61, 0x43, 0x71, 0xeb, 0xdf, 0xc6,
-	0xc6, 0xec, 0xad, 0x7f, 0xe3, 0x98, 0x78, 0x1b, 0x52, 0xe1, 0x41, 0x56, 0x4c, 0xb8, 0x8f, 0xfe,
-	0x13, 0x00, 0x00, 0xff, 0xff, 0xff, 0x14, 0xb8, 0x67, 0xe9, 0x1a, 0x00, 0x00,
+	// 2670 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x19, 0x4d, 0x73, 0x23, 0x47,
+	0x55, 0x23, 0x8d, 0x64, 0xe9, 0x49, 0xb2, 0xb5, 0x9d, 0xcd, 0xa2, 0x4c, 0x52, 0x89, 0x77, 0x36,
+	0xd9, 0x98, 0x0d, 0x91, 0x1d, 0x6f, 0x76, 0x93, 0x4d, 0xc2, 0x87, 0x65, 0x8b, 0x95, 0x61, 0xd7,
+	0x56, 0x5a, 0x5a, 0x52, 0xb5, 0x95, 0x2a, 0xd5, 0x48, 0xd3, 0xb6, 0x07, 0x8f, 0xa6, 0x67, 0x67,
+	0x5a, 0xeb, 0xe8, 0x08, 0x07, 0x8a, 0x2a, 0xb8, 0x51, 0x9c, 0x39, 0x52, 0x70, 0x83, 0xdf, 0x91,
+	0x0b, 0x27, 0x38, 0xc1, 0x0f, 0xe0, 0xca, 0x19, 0xa8, 0xfe, 0x1a, 0x8d, 0x2c, 0xc9, 0xf6, 0x6e,
+	0x2e, 0x14, 0x27, 0xf5, 0x7b, 0xfd, 0xde, 0x9b, 0xf7, 0x5e, 0xbf, 0xaf, 0x6e, 0x41, 0x25, 0x1c,
+	0x0f, 0x7c, 0x6f, 0xd8, 0x08, 0x23, 0xca, 0x28, 0x5a, 0xf3, 0xbd, 0xe0, 0x94, 0x44, 0xee, 0x76,
+	0x43, 0xa2, 0xad, 0x37, 0x8f, 0x29, 0x3d, 0xf6, 0xc9, 0xa6, 0xd8, 0x1e, 0x8c, 0x8f, 0x36, 0xdd,
+	0x71, 0xe4, 0x30, 0x8f, 0x06, 0x92, 0xc1, 0xaa, 0x0f, 0xe9, 0x68, 0x44, 0x83, 0xcd, 0x13, 0xe2,
+	0xf8, 0xec, 0x64, 0x78, 0x42, 0x86, 0xa7, 0x72, 0xc7, 0x5e, 0x81, 0x7c, 0x6b, 0x14, 0xb2, 0x89,
+	0xfd, 0x0c, 0xca, 0x3f, 0x21, 0x51, 0xec, 0xd1, 0x60, 0x3f, 0x38, 0xa2, 0xe8, 0x0d, 0x28, 0x1d,
+	0x53, 0x85, 0xa8, 0x1b, 0xeb, 0xc6, 0x46, 0x09, 0x4f, 0x11, 0x7c, 0x77, 0x30, 0xf6, 0x7c, 0x77,
+	0xcf, 0x61, 0xa4, 0x9e, 0x95, 0xbb, 0x09, 0x02, 0xdd, 0x86, 0xd5, 0x88, 0xf8, 0xc4, 0x89, 0x89,
+	0x16, 0x90, 0x13, 0x24, 0xe7, 0xb0, 0xf6, 0x26, 0xac, 0x3d, 0xf2, 0x62, 0xd6, 0xa1, 0x6e, 0x8c,
+	0xc9, 0xb3, 0x31, 0x89, 0x19, 0x17, 0x1c, 0x38, 0x23, 0x12, 0x87, 0xce, 0x90, 0xe8, 0xcf, 0x26,
+	0x08, 0xfb, 0x33, 0xa8, 0x4d, 0x19, 0xe2, 0x90, 0x06, 0x31, 0x41, 0x1b, 0x60, 0x86, 0xd4, 0x8d,
+	0xeb, 0xc6, 0x7a, 0x6e, 0xa3, 0xbc, 0x7d, 0xbd, 0x71, 0xce, 0x35, 0x8d, 0x0e, 0x75, 0xb1, 0xa0,
+	0xb0, 0x7f, 0x6d, 0x42, 0xae, 0x43, 0x5d, 0x84, 0xc0, 0xe4, 0x22, 0x95, 0x78, 0xb1, 0x46, 0xd7,
+	0x21, 0x1f, 0x52, 0x77, 0xbf, 0xa3, 0x8c, 0x91, 0x00, 0x5a, 0x07, 0x70, 0x49, 0xe8, 0xd3, 0xc9,
+	0x88, 0x04, 0x4c, 0x1a, 0xd1, 0xce, 0xe0, 0x14, 0x0e, 0xdd, 0x84, 0x72, 0x44, 0x42, 0xdf, 0x1b,
+	0x3a, 0xfd, 0x98, 0xb0, 0x3a, 0x68, 0x12, 0x85, 0xec, 0x12, 0x86, 0x3e, 0x82, 0x1b, 0x0a, 0xe2,
+	0x07, 0xd2, 0x1f, 0xd2, 0x80, 0x45, 0xd4, 0xf7, 0x49, 0x54, 0x2f, 0x2b, 0xea, 0x57, 0x53, 0xfb,
+	0xbb, 0xc9, 0x36, 0xba, 0x05, 0x95, 0x98, 0x39, 0x8c, 0x1c, 0x8d, 0x7d, 0x21, 0xbc, 0xa2, 0xc8,
+	0xcb, 0x1a, 0xcb, 0xa5, 0xbf, 0x05, 0xe0, 0x3a, 0x64, 0x44, 0x03, 0x41, 0x52, 0x55, 0x24, 0x25,
+	0x89, 0xe3, 0x04, 0x08, 0x72, 0x3f, 0xa5, 0x83, 0xfa, 0xaa, 0xda, 0xe1, 0x00, 0xba, 0x01, 0x05,
+	0x2e, 0x63, 0x1c, 0xd7, 0x4d, 0x61, 0xae, 0x82, 0xb8, 0x17, 0x1c, 0xd7, 0x25, 0x6e, 0x3d, 0xbf,
+	0x6e, 0x6c, 0x14, 0xb1, 0x04, 0xd0, 0x2e, 0xac, 0xc5, 0x5e, 0x30, 0x24, 0x8f, 0x9c, 0x98, 0x61,
+	0x12, 0xd2, 0x88, 0xd5, 0x0b, 0xeb, 0xc6, 0x46, 0x79, 0xfb, 0xb5, 0x86, 0x0c, 0xbb, 0x86, 0x0e,
+	0xbb, 0xc6, 0x9e, 0x0a, 0x3b, 0x7c, 0x9e, 0x03, 0x6d, 0xc1, 0x2b, 0x53, 0xcb, 0x0f, 0x92, 0x23,
+	0x5e, 0x11, 0xdf, 0x5f, 0xb4, 0x85, 0x6c, 0xa8, 0x28, 0x74, 0xc7, 0x77, 0x02, 0x52, 0x2f, 0x0a,
+	0x9d, 0x66, 0x70, 0xe8, 0x03, 0x28, 0x8c, 0x43, 0xe6, 0x8d, 0x48, 0xbd, 0x74, 0x99, 0x46, 0x8a,
+	0xb0, 0xb9, 0x02, 0x79, 0x7a, 0x16, 0x90, 0xc8, 0xfe, 0x63, 0x16, 0xa0, 0xe7, 0x84, 0x3a, 0xf2,
+	0x10, 0xe4, 0x42, 0xea, 0xd6, 0x0d, 0xed, 0xa7, 0x90, 0xba, 0xe7, 0xce, 0x3f, 0xbb, 0xe0, 0xfc,
+	0x6f, 0x40, 0x61, 0xe4, 0x7c, 0x85, 0xc3, 0x58, 0x44, 0x47, 0x16, 0x2b, 0x88, 0xe3, 0x19, 0xed,
+	0x70, 0x57, 0x71, 0x0f, 0x57, 0xb1, 0x82, 0x78, 0xec, 0x31, 0xba, 0xdf, 0x11, 0x0e, 0x2e, 0x61,
+	0xb1, 0x46, 0x16, 0x14, 0x8f, 0x22, 0x3a, 0xea, 0x68, 0xc7, 0x56, 0x71, 0x02, 0x73, 0x39, 0x7c,
+	0xbd, 0xdf, 0x51, 0x9e, 0x52, 0x90, 0x38, 0xc1, 0xe1, 0x09, 0x19, 0x49, 0xb7, 0x94, 0xb0, 0x82,
+	0x84, 0x3e, 0x84, 0x9d, 0x50, 0x57, 0x38, 0xa4, 0x84, 0x15, 0xc4, 0xf3, 0xca, 0x19, 0xb3, 0x13,
+	0x1a, 0x79, 0x6c, 0x22, 0xa3, 0x14, 0x4f, 0x11, 0x5c, 0xab, 0xd0, 0x61, 0x27, 0x32, 0x20, 0xb1,
+	0x58, 0x7f, 0x92, 0xad, 0x1b, 0xcd, 0x22, 0x14, 0x98, 0x13, 0x1d, 0x13, 0x66, 0xff, 0xac, 0x00,
+	0xd7, 0x7b, 0x4e, 0xd8, 0x9c, 0x60, 0x12, 0xd3, 0x71, 0x34, 0x24, 0xda, 0x6d, 0x9f, 0x68, 0x12,
+	0xe1, 0xb9, 0xf2, 0xb6, 0x3d, 0x97, 0x80, 0x9a, 0xa3, 0x4b, 0x7c, 0x32, 0x94, 0x47, 0x21, 0x39,
+	0xd0, 0x0e, 0xe4, 0x47, 0x0e, 0x1b, 0x9e, 0x08, 0xcf, 0x96, 0xb7, 0xdf, 0x9b, 0x63, 0x5d, 0xf4,
+	0xc5, 0xc6, 0x63, 0xce, 0x82, 0x25, 0xe7, 0x32, 0xff, 0x5b, 0x7f, 0x31, 0x21, 0x2f, 0x08, 0xd1,
+	0x2e, 0xe4, 0x1c, 0xdf, 0x57, 0xda, 0x6d, 0xbe, 0xc0, 0x27, 0x1a, 0x5d, 0xf2, 0x8c, 0x07, 0x82,
+	0xe3, 0xfb, 0x42, 0x48, 0x30, 0xa9, 0x67, 0x5f, 0x5e, 0x48, 0x30, 0x41, 0xdf, 0x87, 0x5c, 0x40,
+	0x65, 0x19, 0x79, 0x31, 0x63, 0xb9, 0x80, 0x80, 0x32, 0xd4, 0x86, 0x8a, 0x4b, 0x62, 0xe6, 0x05,
+	0x22, 0xa2, 0x65, 0xf2, 0x5e, 0xc9, 0xe3, 0xed, 0x0c, 0x9e, 0xe1, 0x44, 0x3f, 0x04, 0xf3, 0x84,
+	0xb1, 0x50, 0x84, 0x61, 0x79, 0x7b, 0xeb, 0x45, 0x0c, 0x6a, 0x33, 0x16, 0xb6, 0x33, 0x58, 0xf0,
+	0x5b, 0x8f, 0x20, 0xd7, 0x25, 0xcf, 0x50, 0x0b, 0x56, 0xc4, 0x71, 0x10, 0x5d, 0x86, 0x5f, 0xe8,
+	0x28, 0x35, 0xaf, 0xf5, 0x1b, 0x03, 0x4c, 0x2e, 0x1e, 0xd5, 0x93, 0xe8, 0xd6, 0xe9, 0xa8, 0xe3,
+	0xbb, 0x9e, 0xc4, 0xb7, 0xce, 0x46, 0x1d, 0xe1, 0x6f, 0xa6, 0x23, 0x5c, 0x97, 0xea, 0x29, 0x0a,
+	0x5d, 0x57, 0x31, 0x6e, 0xaa, 0x2d, 0x01, 0x89, 0x2f, 0xc9, 0x4a, 0x98, 0x4f, 0xbe, 0x24, 0x60,
+	0x5e, 0x27, 0x84, 0x5e, 0xc9, 0xc2, 0xfe, 0x97, 0x01, 0xc0, 0xd5, 0x7b, 0x2c, 0x3f, 0xd8, 0x06,
+	0x88, 0xc8, 0xb1, 0x17, 0x33, 0x12, 0x11, 0x59, 0x37, 0x56, 0xb7, 0x6f, 0xcf, 0xd9, 0x3d, 0x65,
+	0x68, 0xe0, 0x84, 0x5a, 0x76, 0x08, 0x0d, 0xa1, 0xb7, 0xa1, 0x32, 0x0e, 0x52, 0xb2, 0xb4, 0x69,
+	0x33, 0x58, 0x3b, 0x00, 0x98, 0x4a, 0x40, 0x2b, 0x90, 0x7b, 0xd8, 0xea, 0xd5, 0x32, 0xa8, 0x08,
+	0x66, 0xe7, 0xb0, 0xdb, 0xab, 0x19, 0x1c, 0xd5, 0x79, 0xd2, 0xab, 0x65, 0x11, 0x40, 0x61, 0xaf,
+	0xf5, 0xa8, 0xd5, 0x6b, 0xd5, 0x72, 0xa8, 0x04, 0xf9, 0xce, 0x4e, 0x6f, 0xb7, 0x5d, 0x33, 0x51,
+	0x19, 0x56, 0x0e, 0x3b, 0xbd, 0xfd, 0xc3, 0x83, 0x6e, 0x2d, 0xcf, 0x81, 0xdd, 0xc3, 0x83, 0x83,
+	0xd6, 0x6e, 0xaf, 0x56, 0xe0, 0x32, 0xda, 0xad, 0x9d, 0xbd, 0xda, 0x0a, 0x27, 0xef, 0xe1, 0x9d,
+	0xdd, 0x56, 0xad, 0xd8, 0x2c, 0x80, 0xc9, 0x26, 0x21, 0xb1, 0x7f, 0x67, 0x40, 0xa1, 0x2b, 0xbd,
+	0xbf, 0xb7, 0xc0, 0xe4, 0xf9, 0xf0, 0x93, 0xc4, 0xdf, 0xd4, 0xdc, 0x9b, 0x33, 0xe6, 0x72, 0x0d,
+	0x7b, 0xbd, 0x4e, 0x2d, 0xc3, 0x35, 0xe4, 0xab, 0x6e, 0xcd, 0x48, 0x34, 0xec, 0x41, 0x69, 0xbf,
+	0xb3, 0xe3, 0xba, 0x11, 0x89, 0x79, 0x0f, 0x33, 0xbd, 0xf0, 0xf9, 0x87, 0x42, 0xbb, 0x15, 0x7e,
+	0xce, 0x1c, 0x42, 0xef, 0x09, 0xec, 0x7d, 0x95, 0xc1, 0xaf, 0xce, 0xe9, 0xbc, 0xdf, 0x79, 0x7e,
+	0x5f, 0x11, 0xdf, 0x6f, 0x9a, 0x90, 0xf5, 0x42, 0x7b, 0x0b, 0x4c, 0x8e, 0xe5, 0x4d, 0xf1, 0xc8,
+	0x8b, 0x62, 0x59, 0xe0, 0x0a, 0x58, 0x02, 0xbc, 0x64, 0xfa, 0x4e, 0x2c, 0x9b, 0x42, 0x01, 0x8b,
+	0xb5, 0xfd, 0x08, 0xa0, 0x37, 0x0c, 0xb5, 0x22, 0x77, 0xb8, 0x14, 0x55, 0x77, 0xac, 0x05, 0x1f,
+	0x54, 0x74, 0x38, 0xeb, 0x85, 0xa2, 0x00, 0xd3, 0x48, 0x4a, 0xab, 0x62, 0xb1, 0xb6, 0x5d, 0xc8,
+	0xb5, 0x28, 0x17, 0x53, 0x3b, 0x8e, 0xc2, 0x61, 0x5f, 0x86, 0x65, 0x7f, 0x48, 0x5d, 0x99, 0x15,
+	0xd5, 0x76, 0x06, 0xaf, 0xf2, 0x9d, 0xae, 0xd8, 0xd8, 0xa5, 0x2e, 0xe1, 0xb4, 0x11, 0x89, 0x09,
+	0xeb, 0x93, 0x28, 0xa2, 0x91, 0xa4, 0xcd, 0x6a, 0x5a, 0xb1, 0xd3, 0xe2, 0x1b, 0x9c, 0xb6, 0x99,
+	0x87, 0x1c, 0x09, 0x5c, 0xfb, 0x3f, 0x15, 0x28, 0xf6, 0x9c, 0xb0, 0xf5, 0x9c, 0x77, 0xb3, 0xbb,
+	0x50, 0x90, 0x09, 0xaa, 0xd4, 0x7e, 0x7d, 0x3e, 0x8d, 0x13, 0xfb, 0xb0, 0x22, 0x45, 0x0f, 0xa1,
+	0x2c, 0x57, 0xfd, 0x11, 0x61, 0x8e, 0x2a, 0x29, 0xb7, 0x17, 0x15, 0x00, 0xf1, 0x91, 0x46, 0x2b,
+	0x70, 0x43, 0xea, 0x05, 0xec, 0x31, 0x61, 0x0e, 0x06, 0xc9, 0xca, 0xd7, 0xe8, 0xbb, 0x50, 0x4e,
+	0x15, 0xa9, 0x7a, 0xf6, 0x72, 0x15, 0xd2, 0xf4, 0xe8, 0x73, 0xa8, 0xa5, 0x40, 0xa9, 0x8c, 0xf9,
+	0x42, 0xca, 0xac, 0xa5, 0xf8, 0x85, 0x46, 0x9f, 0xc3, 0x5a, 0x18, 0xd1, 0xaf, 0x26, 0x7d, 0xd7,
+	0x8b, 0x64, 0x25, 0x15, 0x0d, 0x7a, 0x75, 0x7b, 0x63, 0xb9, 0xc4, 0x0e, 0x67, 0xd8, 0xd3, 0xf4,
+	0x78, 0x35, 0x9c, 0x81, 0xd1, 0x87, 0xaa, 0xf2, 0xca, 0x2e, 0xf0, 0xe6, 0x72, 0x39, 0x33, 0x75,
+	0xf6, 0xb7, 0x06, 0x54, 0xd2, 0xaa, 0xa2, 0x1f, 0x41, 0xc1, 0x77, 0x06, 0xc4, 0xd7, 0x05, 0x77,
+	0xfb, 0x6a, 0x26, 0x36, 0x1e, 0x09, 0xa6, 0x56, 0xc0, 0xa2, 0x09, 0x56, 0x12, 0xac, 0x07, 0x50,
+	0x4e, 0xa1, 0x51, 0x0d, 0x72, 0xa7, 0x64, 0xa2, 0xa6, 0x63, 0xbe, 0xe4, 0x19, 0xf0, 0xdc, 0xf1,
+	0xc7, 0x7a, 0xd2, 0x97, 0xc0, 0x27, 0xd9, 0x8f, 0x0d, 0xeb, 0xdf, 0x2b, 0xaa, 0x62, 0x1f, 0x42,
+	0x25, 0x92, 0x45, 0xbd, 0xef, 0x05, 0x9e, 0x1e, 0x06, 0xee, 0x5c, 0x6c, 0x5e, 0x43, 0xf5, 0x81,
+	0xfd, 0xc0, 0x63, 0x7c, 0xae, 0x8d, 0xa6, 0x20, 0xc2, 0x50, 0x8d, 0xd4, 0x88, 0x2f, 0x25, 0x5e,
+	0x30, 0x23, 0xcc, 0x48, 0x94, 0x3c, 0x4a, 0x64, 0x25, 0x4a, 0xc1, 0x52, 0x49, 0x25, 0x93, 0x04,
+	0x6e, 0x3d, 0x77, 0x45, 0x25, 0x25, 0x4b, 0x2b, 0x70, 0xa5, 0x92, 0x09, 0x68, 0xdd, 0x87, 0x62,
+	0x97, 0x45, 0xc4, 0x19, 0xed, 0x8b, 0x5b, 0xc5, 0xc0, 0x89, 0x55, 0x6e, 0x62, 0xb1, 0x96, 0x73,
+	0x36, 0xdf, 0x17, 0xda, 0x9b, 0x58, 0x41, 0xd6, 0xdf, 0x0d, 0x28, 0xa7, 0x6c, 0x47, 0x1f, 0x41,
+	0xd6, 0x73, 0x95, 0xcf, 0xde, 0xbd, 0x44, 0x1d, 0xfd, 0x41, 0x9c, 0xf5, 0x5c, 0x9e, 0xb0, 0xa9,
+	0x76, 0xb8, 0x28, 0x5b, 0xa6, 0xfd, 0x27, 0xe9, 0x94, 0x9b, 0x49, 0x77, 0x95, 0x0e, 0xf8, 0xd6,
+	0x92, 0x0a, 0x9e, 0x34, 0xdd, 0x99, 0xe1, 0xd1, 0x5c, 0x36, 0x3c, 0xe6, 0xa7, 0xc3, 0xa3, 0xf5,
+	0x27, 0x03, 0x2a, 0xe9, 0xa3, 0x78, 0x79, 0x0b, 0x1f, 0x02, 0x12, 0x57, 0x89, 0xfe, 0x4c, 0x78,
+	0x65, 0x2f, 0x9b, 0xf6, 0x6b, 0x82, 0x29, 0xed, 0xe3, 0xb7, 0xa0, 0xcc, 0x53, 0x49, 0xd5, 0x51,
+	0x61, 0x7a, 0x15, 0x03, 0x47, 0xc9, 0x02, 0x6a, 0xfd, 0x21, 0x0b, 0x65, 0xad, 0x73, 0x2b, 0x70,
+	0xff, 0x07, 0x54, 0xde, 0x87, 0x57, 0xb4, 0xa0, 0x74, 0x26, 0xe4, 0x2e, 0x93, 0x74, 0x4d, 0x49,
+	0x4a, 0xf9, 0xff, 0x1d, 0x7e, 0x25, 0x57, 0x42, 0x06, 0x13, 0x46, 0xe4, 0xf0, 0x68, 0xe2, 0x24,
+	0xc9, 0x9a, 0x1c, 0x89, 0x6e, 0x43, 0x8e, 0xd0, 0x58, 0xd5, 0xf0, 0xf9, 0xbb, 0x74, 0x8b, 0xc6,
+	0x98, 0x13, 0xf0, 0x99, 0x88, 0x70, 0xeb, 0xed, 0x8f, 0x61, 0x75, 0xb6, 0xe0, 0xf1, 0xc1, 0xe2,
+	0xc9, 0xc1, 0x8f, 0x0f, 0x0e, 0xbf, 0x38, 0xa8, 0x65, 0x38, 0xb0, 0x7f, 0xd0, 0x3c, 0x7c, 0x72,
+	0xb0, 0x57, 0x33, 0x50, 0x05, 0x8a, 0x87, 0x4f, 0x7a, 0x12, 0xca, 0x4e, 0x45, 0xac, 0x43, 0x71,
+	0x27, 0xf4, 0x44, 0x63, 0xe2, 0x95, 0x46, 0xb4, 0x2e, 0x55, 0x7d, 0x24, 0xc0, 0x6f, 0x6a, 0xa5,
+	0x0e, 0x75, 0x05, 0x49, 0x8c, 0x3e, 0x85, 0x82, 0x40, 0xeb, 0xd2, 0x77, 0x6b, 0xd1, 0x95, 0x5f,
+	0xd2, 0x26, 0x2b, 0xac, 0x58, 0xac, 0x7f, 0x18, 0x50, 0xd4, 0x48, 0x84, 0xa1, 0xc4, 0x6f, 0x93,
+	0x8e, 0x17, 0x90, 0x48, 0x1d, 0xf4, 0xf6, 0x15, 0x84, 0x35, 0x76, 0x35, 0x93, 0x00, 0xf9, 0x98,
+	0x99, 0x88, 0xb1, 0x9e, 0xc3, 0xea, 0xec, 0x36, 0xaa, 0xc3, 0xca, 0x88, 0xc4, 0xb1, 0x73, 0xac,
+	0x5f, 0x1c, 0x34, 0xc8, 0xf3, 0x6a, 0xfa, 0x7d, 0xf5, 0x8a, 0x92, 0x20, 0xb8, 0x2f, 0xbc, 0x11,
+	0xe7, 0x92, 0x8f, 0x27, 0x12, 0xe0, 0x25, 0x25, 0x22, 0x4e, 0x4c, 0x03, 0x7d, 0x75, 0x97, 0x90,
+	0x70, 0xa7, 0x70, 0x56, 0x07, 0x8a, 0x7a, 0xcc, 0xbe, 0xf8, 0x35, 0x45, 0xdc, 0x45, 0x27, 0xa1,
+	0xae, 0xea, 0x62, 0x9d, 0xbc, 0x8d, 0xe4, 0xa6, 0x6f, 0x23, 0xf6, 0x33, 0xb8, 0x36, 0x77, 0xa3,
+	0x40, 0xf7, 0xa0, 0x18, 0x91, 0x99, 0x61, 0xe1, 0xb5, 0xa5, 0xf7, 0x10, 0x9c, 0x90, 0xf2, 0x38,
+	0x14, 0x5d, 0xa7, 0x1f, 0x0b, 0x49, 0x54, 0xdb, 0x5d, 0x15, 0xd8, 0xae, 0x42, 0xda, 0x5f, 0x42,
+	0x55, 0x33, 0x4b, 0x27, 0xbe, 0xe4, 0xe7, 0x92, 0x78, 0xca, 0xa6, 0xe3, 0xe9, 0xeb, 0x2c, 0x20,
+	0x9e, 0xf4, 0xdd, 0xf1, 0x68, 0xe4, 0x44, 0x13, 0x7d, 0x95, 0xfd, 0x1e, 0x14, 0x13, 0xad, 0xae,
+	0x7e, 0x99, 0x4d, 0x78, 0x78, 0x85, 0xe1, 0x2f, 0x0c, 0xfd, 0x33, 0x2f, 0x70, 0xe9, 0x99, 0xfa,
+	0x24, 0x70, 0xd4, 0x17, 0x02, 0x83, 0xbe, 0x03, 0x66, 0x40, 0x03, 0x5d, 0x76, 0x6f, 0xcc, 0xa7,
+	0x17, 0x7f, 0x88, 0xe3, 0x3d, 0x9f, 0x53, 0xa1, 0xcf, 0xa0, 0xcc, 0x68, 0x3f, 0xb1, 0xda, 0xbc,
+	0xc4, 0x6a, 0x3e, 0x64, 0x33, 0x9a, 0x1c, 0xfd, 0x0f, 0xa0, 0xca, 0x9f, 0x0a, 0xa6, 0xfc, 0xf9,
+	0xcb, 0xf9, 0x2b, 0x9c, 0x23, 0x91, 0xf0, 0x3a, 0x94, 0xd8, 0x50, 0xd6, 0xcb, 0x58, 0x8c, 0x3d,
+	0x45, 0x5c, 0x64, 0x43, 0x51, 0x2d, 0xe3, 0x26, 0x40, 0x91, 0x8e, 0xd9, 0x80, 0x8e, 0x03, 0xd7,
+	0xfe, 0xab, 0x01, 0xaf, 0xcc, 0xb8, 0x53, 0xbd, 0xcc, 0x3d, 0x80, 0x2c, 0x3d, 0x5d, 0x5a, 0x40,
+	0x17, 0x70, 0x34, 0x0e, 0x4f, 0xdb, 0x19, 0x9c, 0xa5, 0xa7, 0xe8, 0x7e, 0xfa, 0xdc, 0x16, 0x8d,
+	0x49, 0x33, 0xd1, 0xd1, 0xce, 0xa8, 0x93, 0xb5, 0x76, 0x20, 0x7b, 0x78, 0x8a, 0x3e, 0x05, 0xf1,
+	0x44, 0xd6, 0x67, 0xce, 0xc0, 0x4f, 0xae, 0xa4, 0xd6, 0x42, 0x0d, 0x7a, 0x9c, 0x04, 0x43, 0xac,
+	0x97, 0xc2, 0x32, 0x5d, 0x13, 0xc5, 0x8d, 0xaf, 0xe9, 0xc4, 0x9e, 0x98, 0xb1, 0x63, 0x74, 0x0b,
+	0xaa, 0xf1, 0x78, 0x38, 0x24, 0x31, 0x1f, 0xc3, 0xc7, 0x81, 0x9c, 0x72, 0x4c, 0x5c, 0x51, 0xc8,
+	0x5d, 0x8e, 0xe3, 0x44, 0x47, 0x8e, 0xe7, 0x8f, 0x23, 0xa2, 0x88, 0x64, 0xeb, 0xaf, 0x28, 0xa4,
+	0x24, 0x7a, 0x9b, 0xa7, 0x01, 0x23, 0xc1, 0x70, 0xd2, 0x1f, 0xc5, 0xfd, 0xf0, 0xde, 0x96, 0x88,
+	0x09, 0x13, 0x57, 0x14, 0xf6, 0x71, 0xdc, 0xb9, 0xb7, 0x75, 0x9e, 0xea, 0xc1, 0xbd, 0xba, 0x79,
+	0x9e, 0xea, 0xc1, 0xbd, 0x39, 0xaa, 0x07, 0xf5, 0xfc, 0x1c, 0xd5, 0x03, 0x74, 0x07, 0xae, 0x31,
+	0x3f, 0x4e, 0x5a, 0x92, 0x54, 0xad, 0x20, 0x08, 0xd7, 0x98, 0xaf, 0xdf, 0x5f, 0x85, 0x76, 0xf6,
+	0xaf, 0x0c, 0x28, 0xf6, 0xd4, 0x49, 0xa3, 0x6f, 0x43, 0x8d, 0x86, 0x44, 0xbc, 0x5b, 0x06, 0x32,
+	0xe4, 0x63, 0x65, 0xf7, 0x1a, 0xc7, 0xef, 0x4e, 0xd1, 0x68, 0x83, 0x5f, 0x3f, 0x1c, 0x57, 0x36,
+	0x98, 0x3e, 0xa3, 0xcc, 0xf1, 0x95, 0xf5, 0xab, 0x1c, 0x2f, 0x5a, 0x4c, 0x8f, 0x63, 0xb9, 0x36,
+	0x67, 0x91, 0xc7, 0xc8, 0x0c, 0xa9, 0x74, 0xc1, 0x9a, 0xd8, 0x98, 0xd2, 0xda, 0xbf, 0xcf, 0x43,
+	0x29, 0x39, 0x2a, 0xd4, 0x84, 0x52, 0x48, 0xdd, 0xfe, 0x71, 0x44, 0xc7, 0xfa, 0x72, 0x75, 0x6b,
+	0xf9, 0xc9, 0xf2, 0x9a, 0xfd, 0x90, 0x93, 0xb6, 0x33, 0xb8, 0x18, 0xaa, 0xb5, 0xf5, 0xb5, 0x29,
+	0x9a, 0x80, 0x00, 0xd0, 0xa7, 0x60, 0x46, 0xf4, 0x4c, 0x47, 0xc9, 0xbb, 0x57, 0x90, 0xd5, 0xc0,
+	0xf4, 0x0c, 0x0b, 0x26, 0xeb, 0xe7, 0x26, 0xe4, 0x30, 0x3d, 0x7b, 0xd9, 0xf2, 0x74, 0x69, 0xc5,
+	0xd8, 0x80, 0xda, 0x88, 0xc4, 0x27, 0xc4, 0xed, 0x73, 0xa3, 0xe5, 0xa1, 0x49, 0x37, 0xad, 0x4a,
+	0x7c, 0x87, 0xba, 0x32, 0xa2, 0xee, 0xc0, 0xb5, 0x68, 0x1c, 0x04, 0x5e, 0x70, 0x9c, 0x22, 0x95,
+	0xe1, 0xb2, 0xa6, 0x36, 0x12, 0xda, 0x0d, 0xa8, 0xf1, 0x68, 0x9c, 0x91, 0x2a, 0x43, 0x61, 0x55,
+	0xe2, 0x13, 0xca, 0x0f, 0x20, 0x2f, 0xf3, 0x3f, 0xbf, 0x64, 0xbc, 0x9c, 0x66, 0x07, 0x96, 0x94,
+	0xe8, 0x4b, 0xa8, 0xca, 0x5e, 0xdb, 0x1f, 0x4c, 0xb8, 0xfc, 0xfa, 0x8a, 0x70, 0xec, 0xc7, 0x57,
+	0x74, 0x6c, 0x43, 0x36, 0xdb, 0xe6, 0x84, 0x77, 0x5b, 0x71, 0x4d, 0x29, 0x93, 0x29, 0x06, 0xdd,
+	0x4f, 0x17, 0xa5, 0xe2, 0x12, 0x4f, 0xeb, 0xd8, 0x9d, 0xd6, 0x2b, 0xeb, 0x29, 0xd4, 0xce, 0x0b,
+	0x5e, 0x70, 0xd1, 0xd9, 0x4a, 0x5f, 0x74, 0x16, 0x95, 0x8c, 0x64, 0x18, 0x48, 0x5d, 0x82, 0x78,
+	0xeb, 0x15, 0x95, 0xc6, 0xfe, 0xa7, 0x01, 0xb5, 0x1e, 0x0d, 0x31, 0x1d, 0x33, 0x12, 0xff, 0x3f,
+	0x76, 0x95, 0x99, 0xb2, 0xff, 0x0b, 0x03, 0xae, 0xa5, 0xac, 0x55, 0x45, 0xff, 0xfd, 0x54, 0xd1,
+	0x9f, 0x0f, 0x17, 0x41, 0x2c, 0x0e, 0xfd, 0x9b, 0x15, 0xfa, 0x99, 0x2a, 0xfd, 0x67, 0x03, 0x60,
+	0x2a, 0x18, 0xdd, 0x9d, 0x49, 0xe8, 0xb7, 0x2e, 0xd0, 0x21, 0x95, 0xc8, 0x54, 0xe6, 0xf1, 0x75,
+	0xc8, 0x47, 0x7c, 0x5b, 0xcf, 0x9f, 0x02, 0xb8, 0xfc, 0x08, 0x92, 0x34, 0xc9, 0x5d, 0x35, 0x4d,
+	0xb6, 0xff, 0x66, 0x42, 0x6e, 0x27, 0xf4, 0xd0, 0x53, 0x28, 0xa7, 0x3a, 0x21, 0xba, 0x75, 0x71,
+	0x9f, 0x14, 0x21, 0x65, 0xbd, 0x7d, 0x95, 0x66, 0x6a, 0x67, 0x50, 0x0f, 0x4a, 0xc9, 0x01, 0xa1,
+	0x9b, 0xf3, 0x69, 0x72, 0x2e, 0x54, 0x2d, 0xfb, 0x22, 0x92, 0x44, 0xea, 0xe7, 0x50, 0xd4, 0x7f,
+	0xc2, 0xa1, 0xf5, 0x39, 0x8e, 0x73, 0x7f, 0xe8, 0x59, 0x37, 0x2f, 0xa0, 0x48, 0x44, 0xee, 0x41,
+	0xae, 0xe7, 0x84, 0xe8, 0xf5, 0x45, 0xb7, 0x2c, 0x2d, 0xe8, 0xb5, 0xa5, 0x57, 0x30, 0x3b, 0xf7,
+	0xcb, 0xac, 0xb1, 0x65, 0xa0, 0x27, 0x50, 0x9d, 0x79, 0x65, 0x46, 0xef, 0x5c, 0xe9, 0x15, 0xfa,
+	0x22, 0xc9, 0x99, 0x2d, 0x03, 0xed, 0xc0, 0x8a, 0xfe, 0xdb, 0x73, 0x49, 0x72, 0x59, 0x6f, 0xcc,
+	0xe1, 0x53, 0x7f, 0xa5, 0xda, 0x19, 0xe4, 0x43, 0xa9, 0x4b, 0xfc, 0xa3, 0x5d, 0xfe, 0xbf, 0x2b,
+	0x7a, 0x7f, 0x4a, 0x2c, 0xff, 0x95, 0x6d, 0xa4, 0xff, 0x95, 0x4d, 0xe8, 0xb4, 0x76, 0x8d, 0xab,
+	0x92, 0x6b, 0x6f, 0x36, 0xef, 0x3e, 0xfd, 0xe0, 0xd8, 0x63, 0x27, 0xe3, 0x01, 0x67, 0xd8, 0x54,
+	0xdc, 0xfa, 0x77, 0x7b, 0x73, 0xfa, 0x5f, 0xdb, 0xe6, 0x31, 0x09, 0x36, 0xa5, 0xc2, 0x83, 0x82,
+	0xb8, 0x46, 0xde, 0xfd, 0xef, 0x00, 0x34, 0x01, 0x55, 0x1b, 0x69, 0x1e, 0x00, 0x00,
 }
@@ -6,31 +6,41 @@ import (
 	"strings"
 	"time"
 
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
 	"github.com/linkerd/linkerd2/pkg/k8s"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	appsv1beta2 "k8s.io/api/apps/v1beta2"
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	appinformers "k8s.io/client-go/informers/apps/v1beta2"
+	batchinformers "k8s.io/client-go/informers/batch/v1"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 )
 
+// resyncPeriod is the period at which informers resync their caches.
+const resyncPeriod = 10 * time.Minute
+
 type ApiResource int
 
 const (
 	CM ApiResource = iota
 	Deploy
 	Endpoint
+	Job
 	NS
 	Pod
 	RC
 	RS
+	SP
 	Svc
 )
 
@@ -41,22 +51,39 @@ type API struct {
 	cm       coreinformers.ConfigMapInformer
 	deploy   appinformers.DeploymentInformer
 	endpoint coreinformers.EndpointsInformer
+	job      batchinformers.JobInformer
 	ns       coreinformers.NamespaceInformer
 	pod      coreinformers.PodInformer
 	rc       coreinformers.ReplicationControllerInformer
 	rs       appinformers.ReplicaSetInformer
+	sp       cache.SharedIndexInformer
 	svc      coreinformers.ServiceInformer
 
+	// namespace, if set, is the only namespace whose objects are watched.
+	namespace string
+
 	syncChecks      []cache.InformerSynced
 	sharedInformers informers.SharedInformerFactory
 }
 
-// NewAPI takes a Kubernetes client and returns an initialized API
-func NewAPI(k8sClient kubernetes.Interface, resources ...ApiResource) *API {
-	sharedInformers := informers.NewSharedInformerFactory(k8sClient, 10*time.Minute)
+// NewAPI takes a Kubernetes client and returns an initialized API. spClient
+// is the client of ServiceProfiles, which is only required by the SP
+// resource.
+func NewAPI(k8sClient kubernetes.Interface, spClient rest.Interface, resources ...ApiResource) *API {
+	return NewNamespacedAPI(k8sClient, spClient, "", resources...)
+}
+
+// NewNamespacedAPI is like NewAPI, but only watches the objects in namespace,
+// for control planes that are not allowed to list objects cluster-wide. An
+// empty namespace watches all of them. Since namespaces are cluster-scoped,
+// the NS informer is not started in a single namespace; namespace itself is
+// the only Namespace returned by GetObjects instead.
+func NewNamespacedAPI(k8sClient kubernetes.Interface, spClient rest.Interface, namespace string, resources ...ApiResource) *API {
+	sharedInformers := informers.NewFilteredSharedInformerFactory(k8sClient, resyncPeriod, namespace, nil)
 
 	api := &API{
 		Client:          k8sClient,
+		namespace:       namespace,
 		syncChecks:      make([]cache.InformerSynced, 0),
 		sharedInformers: sharedInformers,
 	}
@@ -72,7 +99,13 @@ func NewAPI(k8sClient kubernetes.Interface, resources ...ApiResource) *API {
 		case Endpoint:
 			api.endpoint = sharedInformers.Core().V1().Endpoints()
 			api.syncChecks = append(api.syncChecks, api.endpoint.Informer().HasSynced)
+		case Job:
+			api.job = sharedInformers.Batch().V1().Jobs()
+			api.syncChecks = append(api.syncChecks, api.job.Informer().HasSynced)
 		case NS:
+			if namespace != "" {
+				continue
+			}
 			api.ns = sharedInformers.Core().V1().Namespaces()
 			api.syncChecks = append(api.syncChecks, api.ns.Informer().HasSynced)
 		case Pod:
@@ -84,6 +117,8 @@ func NewAPI(k8sClient kubernetes.Interface, resources ...ApiResource) *API {
 		case RS:
 			api.rs = sharedInformers.Apps().V1beta2().ReplicaSets()
 			api.syncChecks = append(api.syncChecks, api.rs.Informer().HasSynced)
+		case SP:
+			api.watchServiceProfiles(cache.NewFilteredListWatchFromClient(spClient, "serviceprofiles", namespace, func(*metav1.ListOptions) {}))
 		case Svc:
 			api.svc = sharedInformers.Core().V1().Services()
 			api.syncChecks = append(api.syncChecks, api.svc.Informer().HasSynced)
@@ -93,11 +128,22 @@ func NewAPI(k8sClient kubernetes.Interface, resources ...ApiResource) *API {
 	return api
 }
 
+// watchServiceProfiles configures the SP informer, which is not provided by
+// the shared informers of the clientset, to list and watch ServiceProfiles
+// with lw.
+func (api *API) watchServiceProfiles(lw cache.ListerWatcher) {
+	api.sp = cache.NewSharedIndexInformer(lw, &sp.ServiceProfile{}, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	api.syncChecks = append(api.syncChecks, api.sp.HasSynced)
+}
+
 // Sync waits for all informers to be synced.
 // For servers, call this asynchronously.
 // For testing, call this synchronously.
 func (api *API) Sync(readyCh chan<- struct{}) {
 	api.sharedInformers.Start(nil)
+	if api.sp != nil {
+		go api.sp.Run(nil)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
@@ -134,6 +180,13 @@ func (api *API) RS() appinformers.ReplicaSetInformer {
 	return api.rs
 }
 
+func (api *API) Job() batchinformers.JobInformer {
+	if api.job == nil {
+		panic("Job informer not configured")
+	}
+	return api.job
+}
+
 func (api *API) Pod() coreinformers.PodInformer {
 	if api.pod == nil {
 		panic("Pod informer not configured")
@@ -155,6 +208,15 @@ func (api *API) Svc() coreinformers.ServiceInformer {
 	return api.svc
 }
 
+// SP returns the informer of ServiceProfiles, whose objects are
+// *sp.ServiceProfile.
+func (api *API) SP() cache.SharedIndexInformer {
+	if api.sp == nil {
+		panic("SP informer not configured")
+	}
+	return api.sp
+}
+
 func (api *API) Endpoint() coreinformers.EndpointsInformer {
 	if api.endpoint == nil {
 		panic("Endpoint informer not configured")
@@ -190,6 +252,20 @@ func (api *API) GetObjects(namespace, restype, name string) ([]runtime.Object, e
 	}
 }
 
+// GetServiceProfile returns the ServiceProfile named name in namespace, or nil
+// if there is none. Control planes installed in a single namespace have no
+// ServiceProfile CRD, and do not watch them.
+func (api *API) GetServiceProfile(namespace, name string) (*sp.ServiceProfile, error) {
+	if api.sp == nil {
+		return nil, status.Error(codes.Unimplemented, "ServiceProfiles are not watched by this control plane")
+	}
+	obj, exists, err := api.SP().GetIndexer().GetByKey(namespace + "/" + name)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return obj.(*sp.ServiceProfile), nil
+}
+
 // GetOwnerKindAndName returns the pod owner's kind and name, using owner
 // references from the Kubernetes API. The kind is represented as the Kubernetes
 // singular resource type (e.g. deployment, daemonset, job, etc.)
@@ -208,6 +284,18 @@ func (api *API) GetOwnerKindAndName(pod *apiv1.Pod) (string, string) {
 		return strings.ToLower(rsParent.Kind), rsParent.Name
 	}
 
+	// The Jobs of a CronJob are named after the time they are scheduled at, so
+	// their pods are identified by the CronJob, as they are injected, when the
+	// Job informer is configured.
+	if parent.Kind == "Job" && api.job != nil {
+		job, err := api.Job().Lister().Jobs(pod.Namespace).Get(parent.Name)
+		if err != nil || len(job.GetOwnerReferences()) != 1 {
+			return strings.ToLower(parent.Kind), parent.Name
+		}
+		jobParent := job.GetOwnerReferences()[0]
+		return strings.ToLower(jobParent.Kind), jobParent.Name
+	}
+
 	return strings.ToLower(parent.Kind), parent.Name
 }
 
@@ -274,6 +362,13 @@ func (api *API) GetPodsFor(obj runtime.Object, includeFailed bool) ([]*apiv1.Pod
 }
 
 func (api *API) getNamespaces(name string) ([]runtime.Object, error) {
+	if api.namespace != "" {
+		if name != "" && name != api.namespace {
+			return nil, errors.NewNotFound(apiv1.Resource("namespace"), name)
+		}
+		return []runtime.Object{&apiv1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: api.namespace}}}, nil
+	}
+
 	var err error
 	var namespaces []*apiv1.Namespace
 
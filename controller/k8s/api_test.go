@@ -11,6 +11,7 @@ import (
 	"google.golang.org/grpc/status"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func newAPI(resourceConfigs []string, extraConfigs ...string) (*API, []runtime.Object, error) {
@@ -273,6 +274,122 @@ status:
 	})
 }
 
+func TestNewNamespacedAPI(t *testing.T) {
+	objs := []runtime.Object{}
+	for _, config := range []string{`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: linkerd`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: emojivoto`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: controller
+  namespace: linkerd
+status:
+  phase: Running`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: emojivoto
+status:
+  phase: Running`,
+	} {
+		obj, err := toRuntimeObject(config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		objs = append(objs, obj)
+	}
+
+	api := NewNamespacedAPI(fake.NewSimpleClientset(objs...), nil, "linkerd", NS, Pod)
+	api.Sync(nil)
+
+	t.Run("Only returns objects in the namespace", func(t *testing.T) {
+		pods, err := api.GetObjects("", k8s.Pod, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pods) != 1 || pods[0].(*apiv1.Pod).Name != "controller" {
+			t.Fatalf("Expected only the controller pod, got %+v", pods)
+		}
+	})
+
+	t.Run("Returns the namespace without watching namespaces", func(t *testing.T) {
+		namespaces, err := api.GetObjects("", k8s.Namespace, "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(namespaces) != 1 || namespaces[0].(*apiv1.Namespace).Name != "linkerd" {
+			t.Fatalf("Expected only the linkerd namespace, got %+v", namespaces)
+		}
+
+		pods, err := api.GetPodsFor(namespaces[0], false)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pods) != 1 || pods[0].Name != "controller" {
+			t.Fatalf("Expected only the controller pod, got %+v", pods)
+		}
+	})
+
+	t.Run("Returns a not found error for other namespaces", func(t *testing.T) {
+		_, err := api.GetObjects("", k8s.Namespace, "emojivoto")
+		expected := "namespace \"emojivoto\" not found"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestGetServiceProfile(t *testing.T) {
+	api, err := NewFakeAPI(`
+apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.default.svc.cluster.local
+  namespace: default
+spec:
+  routes:
+  - name: GET /books
+    condition:
+      pathRegex: /books
+      method: GET`)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+	api.Sync(nil)
+
+	t.Run("Returns the ServiceProfiles watched by name", func(t *testing.T) {
+		profile, err := api.GetServiceProfile("default", "books.default.svc.cluster.local")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if profile == nil || len(profile.Spec.Routes) != 1 || profile.Spec.Routes[0].Condition.Method != "GET" {
+			t.Fatalf("Expected the profile of books, got %+v", profile)
+		}
+	})
+
+	t.Run("Returns nil for services without ServiceProfile", func(t *testing.T) {
+		profile, err := api.GetServiceProfile("library", "books.library.svc.cluster.local")
+		if err != nil || profile != nil {
+			t.Fatalf("Expected no profile and no error, got %+v and %v", profile, err)
+		}
+	})
+
+	t.Run("Returns an error when ServiceProfiles are not watched", func(t *testing.T) {
+		api := NewAPI(fake.NewSimpleClientset(), nil, Pod)
+		if _, err := api.GetServiceProfile("default", "books.default.svc.cluster.local"); status.Code(err) != codes.Unimplemented {
+			t.Fatalf("Expected an Unimplemented error, got %v", err)
+		}
+	})
+}
+
 func TestGetPodsFor(t *testing.T) {
 
 	type getPodsForExpected struct {
@@ -471,6 +588,31 @@ metadata:
     kind: Job
     name: slow-cooker`,
 		},
+		{
+			expectedOwnerKind: "cronjob",
+			expectedOwnerName: "report",
+			podConfig: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: report-1539475200-x7k2p
+  namespace: default
+  ownerReferences:
+  - apiVersion: batch/v1
+    kind: Job
+    name: report-1539475200`,
+			extraConfigs: []string{`
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: report-1539475200
+  namespace: default
+  ownerReferences:
+  - apiVersion: batch/v1beta1
+    kind: CronJob
+    name: report`,
+			},
+		},
 		{
 			expectedOwnerKind: "replicationcontroller",
 			expectedOwnerName: "web",
@@ -1,6 +1,10 @@
 package k8s
 
 import (
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -8,7 +12,49 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
-func NewClientSet(kubeConfig string) (*kubernetes.Clientset, error) {
+const (
+	// DefaultQPS and DefaultBurst are the client-side rate limits used by the
+	// controller components. They are higher than client-go's defaults, which
+	// throttle the controller when it issues many requests in quick succession.
+	DefaultQPS   = 100
+	DefaultBurst = 200
+)
+
+// NewClientSet returns a Kubernetes clientset for the cluster described by
+// kubeConfig, or for the cluster the process is running in if kubeConfig is
+// empty. Zero values for qps and burst keep client-go's defaults. Requests
+// identify the given control plane component in their User-Agent, and are
+// recorded in Prometheus metrics.
+func NewClientSet(component, kubeConfig string, qps float32, burst int) (*kubernetes.Clientset, error) {
+	config, err := newConfig(component, kubeConfig, qps, burst)
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewForConfig(config)
+}
+
+// NewServiceProfileClient is like NewClientSet, but returns a client of the
+// ServiceProfile custom resources, which the clientset has no client for.
+func NewServiceProfileClient(component, kubeConfig string, qps float32, burst int) (rest.Interface, error) {
+	config, err := newConfig(component, kubeConfig, qps, burst)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := runtime.NewScheme()
+	if err := sp.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+
+	config.GroupVersion = &sp.SchemeGroupVersion
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: serializer.NewCodecFactory(scheme)}
+
+	return rest.RESTClientFor(config)
+}
+
+func newConfig(component, kubeConfig string, qps float32, burst int) (*rest.Config, error) {
 	var config *rest.Config
 	var err error
 
@@ -24,5 +70,10 @@ func NewClientSet(kubeConfig string) (*kubernetes.Clientset, error) {
 		return nil, err
 	}
 
-	return kubernetes.NewForConfig(config)
+	config.QPS = qps
+	config.Burst = burst
+	config.UserAgent = k8s.ControllerUserAgent(component)
+	config.WrapTransport = k8s.InstrumentTransport
+
+	return config, nil
 }
@@ -1,37 +1,67 @@
 package k8s
 
 import (
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
 )
 
+// fakeCodecs decode the objects of the clientset, and ServiceProfiles.
+var fakeCodecs = func() serializer.CodecFactory {
+	fakeScheme := runtime.NewScheme()
+	scheme.AddToScheme(fakeScheme)
+	sp.AddToScheme(fakeScheme)
+	return serializer.NewCodecFactory(fakeScheme)
+}()
+
 func toRuntimeObject(config string) (runtime.Object, error) {
-	decode := scheme.Codecs.UniversalDeserializer().Decode
+	decode := fakeCodecs.UniversalDeserializer().Decode
 	obj, _, err := decode([]byte(config), nil, nil)
 	return obj, err
 }
 
 func NewFakeAPI(configs ...string) (*API, error) {
 	objs := []runtime.Object{}
+	profiles := []sp.ServiceProfile{}
 	for _, config := range configs {
 		obj, err := toRuntimeObject(config)
 		if err != nil {
 			return nil, err
 		}
+		// the fake clientset has no client of ServiceProfiles to serve them
+		if profile, ok := obj.(*sp.ServiceProfile); ok {
+			profiles = append(profiles, *profile)
+			continue
+		}
 		objs = append(objs, obj)
 	}
 
 	clientSet := fake.NewSimpleClientset(objs...)
-	return NewAPI(
+	api := NewAPI(
 		clientSet,
+		nil,
 		CM,
 		Deploy,
 		Endpoint,
+		Job,
 		NS,
 		Pod,
 		RC,
 		RS,
 		Svc,
-	), nil
+	)
+	api.watchServiceProfiles(&cache.ListWatch{
+		ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+			return &sp.ServiceProfileList{Items: profiles}, nil
+		},
+		WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+			return watch.NewFake(), nil
+		},
+	})
+	return api, nil
 }
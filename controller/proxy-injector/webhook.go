@@ -0,0 +1,286 @@
+package injector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/inject"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/ports"
+	log "github.com/sirupsen/logrus"
+	admissionV1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxRequestBytes bounds the size of the AdmissionReviews read by the
+// webhook; the API server limits objects to less than that.
+const maxRequestBytes = 3 << 20
+
+// ownerLabels are the labels identifying the owner of injected pods, by the
+// kind returned by GetOwnerKindAndName, as `linkerd inject` sets them on the
+// pod templates of the same workloads.
+var ownerLabels = map[string]string{
+	"deployment":            pkgK8s.ProxyDeploymentLabel,
+	"replicationcontroller": pkgK8s.ProxyReplicationControllerLabel,
+	"replicaset":            pkgK8s.ProxyReplicaSetLabel,
+	"job":                   pkgK8s.ProxyJobLabel,
+	"cronjob":               pkgK8s.ProxyCronJobLabel,
+	"daemonset":             pkgK8s.ProxyDaemonSetLabel,
+	"statefulset":           pkgK8s.ProxyStatefulSetLabel,
+}
+
+// Webhook is a mutating admission webhook that injects the proxy into the pods
+// created in the cluster, as `linkerd inject` would with config. Pods in the
+// kube-system and control plane namespaces are never injected; whether others
+// are is decided by injectionDecision.
+type Webhook struct {
+	k8sAPI              *k8s.API
+	config              *inject.Config
+	controllerNamespace string
+
+	// defaultInject is ProxyInjectEnabled if pods are injected unless they or
+	// their namespace opt out, and ProxyInjectDisabled if they are only
+	// injected when they opt in.
+	defaultInject string
+}
+
+// NewWebhook returns a Webhook injecting pods with config, by default if
+// defaultInject is ProxyInjectEnabled, and only those opting in if it is
+// ProxyInjectDisabled. The NS, RS and Job informers of k8sAPI must be
+// configured, to read the annotations of namespaces and the owners of pods.
+func NewWebhook(k8sAPI *k8s.API, config *inject.Config, controllerNamespace, defaultInject string) *Webhook {
+	return &Webhook{
+		k8sAPI:              k8sAPI,
+		config:              config,
+		controllerNamespace: controllerNamespace,
+		defaultInject:       defaultInject,
+	}
+}
+
+// patchOperation is an operation of the JSON Patch (RFC 6902) returned to the
+// API server to inject a pod.
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ServeHTTP answers the AdmissionReview POSTed by the API server with the
+// AdmissionResponse of Mutate.
+func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("method %s is not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, fmt.Sprintf("unsupported content type [%s]", r.Header.Get("Content-Type")), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read the request: %v", err), http.StatusBadRequest)
+		return
+	}
+	var review admissionV1beta1.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil {
+		http.Error(w, fmt.Sprintf("invalid AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "AdmissionReview has no request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = wh.Mutate(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	response, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+// Mutate returns the response to request: a patch injecting the pod it
+// creates, if it should be injected, and no patch otherwise. Requests for
+// other kinds of objects and operations are allowed unchanged. Pods whose
+// annotations are not valid are denied, with the reason why.
+func (wh *Webhook) Mutate(request *admissionV1beta1.AdmissionRequest) *admissionV1beta1.AdmissionResponse {
+	if request.Kind.Kind != "Pod" || request.Operation != admissionV1beta1.Create {
+		return &admissionV1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	var pod v1.Pod
+	if err := json.Unmarshal(request.Object.Raw, &pod); err != nil {
+		return deny(fmt.Errorf("invalid pod: %v", err))
+	}
+	// Pods are not always given a namespace until they are admitted.
+	namespace := request.Namespace
+	if namespace == "" {
+		namespace = pod.Namespace
+	}
+
+	patch, reason, err := wh.inject(&pod, namespace)
+	if err != nil {
+		log.Infof("denying pod %s in namespace %s: %v", podName(&pod), namespace, err)
+		return deny(err)
+	}
+	if patch == nil {
+		log.Infof("not injecting pod %s in namespace %s: %s", podName(&pod), namespace, reason)
+		return &admissionV1beta1.AdmissionResponse{Allowed: true}
+	}
+
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return deny(err)
+	}
+	log.Infof("injecting pod %s in namespace %s: %s", podName(&pod), namespace, reason)
+	patchType := admissionV1beta1.PatchTypeJSONPatch
+	return &admissionV1beta1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     patchBytes,
+		PatchType: &patchType,
+	}
+}
+
+// inject returns the patch injecting pod, or no patch if it should not be
+// injected, along with the reason for the decision.
+func (wh *Webhook) inject(pod *v1.Pod, namespace string) ([]patchOperation, string, error) {
+	if namespace == "kube-system" || namespace == wh.controllerNamespace {
+		return nil, fmt.Sprintf("pods in the %s namespace are not injected", namespace), nil
+	}
+	ns, err := wh.k8sAPI.NS().Lister().Get(namespace)
+	if err != nil {
+		// The namespace may not be in the cache yet, if it was just created;
+		// the pod is injected without the annotations of the namespace then.
+		log.Debugf("could not read namespace %s: %v", namespace, err)
+		ns = &v1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: namespace}}
+	}
+	injected, reason := injectionDecision(pod, ns, wh.defaultInject)
+	if !injected {
+		return nil, reason, nil
+	}
+	if inject.IsInjected(&pod.ObjectMeta, &pod.Spec) {
+		return nil, "already injected", nil
+	}
+
+	inboundSkipPorts, err := skippedPorts(pod, ns, pkgK8s.ProxySkipInboundPortsAnnotation)
+	if err != nil {
+		return nil, "", err
+	}
+	outboundSkipPorts, err := skippedPorts(pod, ns, pkgK8s.ProxySkipOutboundPortsAnnotation)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pod.Namespace = namespace
+	ownerKind, ownerName := wh.k8sAPI.GetOwnerKindAndName(pod)
+	identity := pkgK8s.TLSIdentity{
+		Name:                ownerName,
+		Kind:                ownerKind,
+		Namespace:           namespace,
+		ControllerNamespace: wh.controllerNamespace,
+	}
+
+	injectedPod := pod.DeepCopy()
+	if reason := wh.config.InjectPodSpec(&injectedPod.Spec, identity, "", inboundSkipPorts, outboundSkipPorts); reason != "" {
+		return nil, reason, nil
+	}
+	k8sLabels := map[string]string{}
+	if label, ok := ownerLabels[ownerKind]; ok {
+		k8sLabels[label] = ownerName
+	}
+	wh.config.InjectObjectMeta(&injectedPod.ObjectMeta, k8sLabels)
+	inject.RecordSkippedPorts(&injectedPod.ObjectMeta, pkgK8s.ProxySkipInboundPortsAnnotation, inboundSkipPorts)
+	inject.RecordSkippedPorts(&injectedPod.ObjectMeta, pkgK8s.ProxySkipOutboundPortsAnnotation, outboundSkipPorts)
+
+	return createPatch(pod, injectedPod), reason, nil
+}
+
+// injectionDecision returns whether pod should be injected, and why, from the
+// ProxyInjectAnnotation of the pod and of its namespace ns. The annotation of
+// the pod takes precedence over that of the namespace, which takes precedence
+// over defaultInject, the policy of the cluster. An annotation that is
+// missing or empty leaves the decision to the next level, as does one with an
+// unknown value, which is logged as a warning.
+func injectionDecision(pod *v1.Pod, ns *v1.Namespace, defaultInject string) (bool, string) {
+	for _, level := range []struct {
+		kind, name  string
+		annotations map[string]string
+	}{
+		{"pod", podName(pod), pod.Annotations},
+		{"namespace", ns.Name, ns.Annotations},
+	} {
+		switch value := level.annotations[pkgK8s.ProxyInjectAnnotation]; value {
+		case pkgK8s.ProxyInjectEnabled, pkgK8s.ProxyInjectDisabled:
+			return value == pkgK8s.ProxyInjectEnabled, fmt.Sprintf("%s annotation %s: %s", level.kind, pkgK8s.ProxyInjectAnnotation, value)
+		case "":
+		default:
+			log.Warnf("ignoring the %s annotation of %s %s, which must be %s or %s, not [%s]",
+				pkgK8s.ProxyInjectAnnotation, level.kind, level.name, pkgK8s.ProxyInjectEnabled, pkgK8s.ProxyInjectDisabled, value)
+		}
+	}
+	return defaultInject == pkgK8s.ProxyInjectEnabled, fmt.Sprintf("cluster default %s: %s", pkgK8s.ProxyInjectAnnotation, defaultInject)
+}
+
+// skippedPorts returns the ports listed in the annotation of the pod, or, if
+// it has none, in that of its namespace, so that a namespace can declare the
+// ports that skip the proxy of all of its pods.
+func skippedPorts(pod *v1.Pod, ns *v1.Namespace, annotation string) ([]ports.Range, error) {
+	if _, ok := pod.Annotations[annotation]; ok {
+		return inject.SkippedPorts(nil, &pod.ObjectMeta, annotation)
+	}
+	skipped, err := inject.SkippedPorts(nil, &ns.ObjectMeta, annotation)
+	if err != nil {
+		return nil, fmt.Errorf("namespace %s: %v", ns.Name, err)
+	}
+	return skipped, nil
+}
+
+// createPatch returns the operations replacing the fields of the pod that
+// inject changes with those of the injected pod. Adding a member that exists
+// replaces it, so each field is set with an add operation.
+func createPatch(pod, injected *v1.Pod) []patchOperation {
+	patch := []patchOperation{}
+	for _, field := range []struct {
+		path       string
+		old, value interface{}
+	}{
+		{"/metadata/labels", pod.Labels, injected.Labels},
+		{"/metadata/annotations", pod.Annotations, injected.Annotations},
+		{"/spec/initContainers", pod.Spec.InitContainers, injected.Spec.InitContainers},
+		{"/spec/containers", pod.Spec.Containers, injected.Spec.Containers},
+		{"/spec/volumes", pod.Spec.Volumes, injected.Spec.Volumes},
+	} {
+		if !reflect.DeepEqual(field.old, field.value) {
+			patch = append(patch, patchOperation{Op: "add", Path: field.path, Value: field.value})
+		}
+	}
+	return patch
+}
+
+func deny(err error) *admissionV1beta1.AdmissionResponse {
+	return &admissionV1beta1.AdmissionResponse{
+		Allowed: false,
+		Result:  &metaV1.Status{Message: err.Error()},
+	}
+}
+
+// podName returns the name of the pod, or the prefix of the name it will be
+// generated with.
+func podName(pod *v1.Pod) string {
+	if pod.Name == "" && pod.GenerateName != "" {
+		return pod.GenerateName + "*"
+	}
+	return pod.Name
+}
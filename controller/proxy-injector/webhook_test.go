@@ -0,0 +1,482 @@
+package injector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/inject"
+	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	admissionV1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const controllerNS = "linkerd"
+
+var fixtures = []string{`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: emojivoto`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: legacy
+  annotations:
+    linkerd.io/inject: disabled`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: mail
+  annotations:
+    linkerd.io/skip-outbound-ports: "25,587"`, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: books
+  annotations:
+    linkerd.io/inject: enabled`, `
+apiVersion: apps/v1beta2
+kind: ReplicaSet
+metadata:
+  name: web-5f86686c4d
+  namespace: emojivoto
+  ownerReferences:
+  - apiVersion: apps/v1beta2
+    kind: Deployment
+    name: web
+spec:
+  selector:
+    matchLabels:
+      app: web-svc`,
+}
+
+func testConfig() *inject.Config {
+	return &inject.Config{
+		ControlPlaneNamespace: controllerNS,
+		Version:               "testinjectversion",
+		CreatedBy:             "linkerd/proxy-injector testinjectversion",
+		ProxyImage:            "gcr.io/linkerd-io/proxy:testinjectversion",
+		InitImage:             "gcr.io/linkerd-io/proxy-init:testinjectversion",
+		ImagePullPolicy:       "IfNotPresent",
+		ProxyUID:              2102,
+		LogLevel:              "warn,linkerd2_proxy=info",
+		BindTimeout:           "10s",
+		APIPort:               8086,
+		ControlPort:           4190,
+		MetricsPort:           4191,
+		InboundPort:           4143,
+		OutboundPort:          4140,
+		EnableTLS:             true,
+	}
+}
+
+func newTestWebhook(t *testing.T) *Webhook {
+	return newTestWebhookWithDefault(t, pkgK8s.ProxyInjectEnabled)
+}
+
+func newTestWebhookWithDefault(t *testing.T, defaultInject string) *Webhook {
+	k8sAPI, err := k8s.NewFakeAPI(fixtures...)
+	if err != nil {
+		t.Fatalf("NewFakeAPI returned an error: %s", err)
+	}
+	k8sAPI.Sync(nil)
+	return NewWebhook(k8sAPI, testConfig(), controllerNS, defaultInject)
+}
+
+// webPod is a pod of the web Deployment, as created by its ReplicaSet.
+func webPod() *v1.Pod {
+	return &v1.Pod{
+		TypeMeta: metaV1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metaV1.ObjectMeta{
+			GenerateName: "web-5f86686c4d-",
+			Labels:       map[string]string{"app": "web-svc"},
+			OwnerReferences: []metaV1.OwnerReference{
+				{APIVersion: "apps/v1beta2", Kind: "ReplicaSet", Name: "web-5f86686c4d"},
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "web-svc", Image: "buoyantio/emojivoto-web:v3"}},
+		},
+	}
+}
+
+func podRequest(t *testing.T, namespace string, pod *v1.Pod) *admissionV1beta1.AdmissionRequest {
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	request := &admissionV1beta1.AdmissionRequest{
+		UID:       "0df28fbd-5f5f-11e8-bc74-36e6bb280816",
+		Kind:      metaV1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Resource:  metaV1.GroupVersionResource{Version: "v1", Resource: "pods"},
+		Namespace: namespace,
+		Operation: admissionV1beta1.Create,
+	}
+	request.Object.Raw = raw
+	return request
+}
+
+// decodePatch returns the values of the operations of the patch in response,
+// by path, decoded into the types of the fields they set.
+func decodePatch(t *testing.T, response *admissionV1beta1.AdmissionResponse) map[string]interface{} {
+	if !response.Allowed {
+		t.Fatalf("Expected the pod to be allowed, got %+v", response.Result)
+	}
+	if response.PatchType == nil || *response.PatchType != admissionV1beta1.PatchTypeJSONPatch {
+		t.Fatalf("Expected a JSONPatch, got %v", response.PatchType)
+	}
+
+	var operations []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(response.Patch, &operations); err != nil {
+		t.Fatalf("Invalid patch [%s]: %v", response.Patch, err)
+	}
+
+	values := map[string]interface{}{}
+	for _, operation := range operations {
+		if operation.Op != "add" {
+			t.Fatalf("Unexpected operation %s of %s", operation.Op, operation.Path)
+		}
+		var value interface{}
+		switch operation.Path {
+		case "/metadata/labels", "/metadata/annotations":
+			value = &map[string]string{}
+		case "/spec/containers", "/spec/initContainers":
+			value = &[]v1.Container{}
+		case "/spec/volumes":
+			value = &[]v1.Volume{}
+		default:
+			t.Fatalf("Unexpected patch of %s", operation.Path)
+		}
+		if err := json.Unmarshal(operation.Value, value); err != nil {
+			t.Fatalf("Invalid value of %s: %v", operation.Path, err)
+		}
+		values[operation.Path] = reflect.ValueOf(value).Elem().Interface()
+	}
+	return values
+}
+
+func containerNames(containers []v1.Container) []string {
+	names := []string{}
+	for _, container := range containers {
+		names = append(names, container.Name)
+	}
+	return names
+}
+
+func envValue(container v1.Container, name string) string {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return env.Value
+		}
+	}
+	return ""
+}
+
+func TestMutate(t *testing.T) {
+	t.Run("Injects the pods of a Deployment as inject does", func(t *testing.T) {
+		webhook := newTestWebhook(t)
+		response := webhook.Mutate(podRequest(t, "emojivoto", webPod()))
+		patch := decodePatch(t, response)
+
+		containers := patch["/spec/containers"].([]v1.Container)
+		if names := containerNames(containers); !reflect.DeepEqual(names, []string{"web-svc", pkgK8s.ProxyContainerName}) {
+			t.Fatalf("Expected the proxy to be added after the containers of the pod, got %v", names)
+		}
+		if names := containerNames(patch["/spec/initContainers"].([]v1.Container)); !reflect.DeepEqual(names, []string{pkgK8s.InitContainerName}) {
+			t.Fatalf("Expected the init container to be added, got %v", names)
+		}
+
+		identity := envValue(containers[1], "LINKERD2_PROXY_TLS_POD_IDENTITY")
+		if expected := "web.deployment.emojivoto.linkerd-managed.linkerd.svc.cluster.local"; identity != expected {
+			t.Fatalf("Expected the pod to have the identity of its Deployment [%s], got [%s]", expected, identity)
+		}
+		volumes := patch["/spec/volumes"].([]v1.Volume)
+		if len(volumes) != 2 || volumes[1].Secret.SecretName != "web-deployment-tls-linkerd-io" {
+			t.Fatalf("Expected the secret of the Deployment to be mounted, got %v", volumes)
+		}
+
+		expectedLabels := map[string]string{
+			"app":                       "web-svc",
+			pkgK8s.ControllerNSLabel:    controllerNS,
+			pkgK8s.ProxyDeploymentLabel: "web",
+		}
+		if labels := patch["/metadata/labels"]; !reflect.DeepEqual(labels, expectedLabels) {
+			t.Fatalf("Expected labels %v, got %v", expectedLabels, labels)
+		}
+		expectedAnnotations := map[string]string{
+			pkgK8s.CreatedByAnnotation:    "linkerd/proxy-injector testinjectversion",
+			pkgK8s.ProxyVersionAnnotation: "testinjectversion",
+		}
+		if annotations := patch["/metadata/annotations"]; !reflect.DeepEqual(annotations, expectedAnnotations) {
+			t.Fatalf("Expected annotations %v, got %v", expectedAnnotations, annotations)
+		}
+	})
+
+	t.Run("Identifies pods without an owner by their name", func(t *testing.T) {
+		pod := webPod()
+		pod.Name = "vote-bot"
+		pod.GenerateName = ""
+		pod.OwnerReferences = nil
+
+		patch := decodePatch(t, newTestWebhook(t).Mutate(podRequest(t, "emojivoto", pod)))
+		containers := patch["/spec/containers"].([]v1.Container)
+		identity := envValue(containers[1], "LINKERD2_PROXY_TLS_POD_IDENTITY")
+		if expected := "vote-bot.pod.emojivoto.linkerd-managed.linkerd.svc.cluster.local"; identity != expected {
+			t.Fatalf("Expected identity [%s], got [%s]", expected, identity)
+		}
+		if _, ok := patch["/metadata/labels"].(map[string]string)[pkgK8s.ProxyDeploymentLabel]; ok {
+			t.Fatalf("Expected no owner label, got %v", patch["/metadata/labels"])
+		}
+	})
+
+	t.Run("Skips the ports annotated on the pod, or else on its namespace", func(t *testing.T) {
+		webhook := newTestWebhook(t)
+
+		patch := decodePatch(t, webhook.Mutate(podRequest(t, "mail", webPod())))
+		initArgs := strings.Join(patch["/spec/initContainers"].([]v1.Container)[0].Args, " ")
+		if !strings.Contains(initArgs, "--outbound-ports-to-ignore 25,587") {
+			t.Fatalf("Expected the ports annotated on the namespace to skip the proxy, got [%s]", initArgs)
+		}
+		if annotation := patch["/metadata/annotations"].(map[string]string)[pkgK8s.ProxySkipOutboundPortsAnnotation]; annotation != "25,587" {
+			t.Fatalf("Expected the skipped ports to be recorded on the pod, got [%s]", annotation)
+		}
+
+		pod := webPod()
+		pod.Annotations = map[string]string{pkgK8s.ProxySkipOutboundPortsAnnotation: "3306"}
+		patch = decodePatch(t, webhook.Mutate(podRequest(t, "mail", pod)))
+		initArgs = strings.Join(patch["/spec/initContainers"].([]v1.Container)[0].Args, " ")
+		if !strings.Contains(initArgs, "--outbound-ports-to-ignore 3306") {
+			t.Fatalf("Expected the ports annotated on the pod to skip the proxy, got [%s]", initArgs)
+		}
+	})
+
+	t.Run("Allows the pods it does not inject unchanged", func(t *testing.T) {
+		disabled := webPod()
+		disabled.Annotations = map[string]string{pkgK8s.ProxyInjectAnnotation: pkgK8s.ProxyInjectDisabled}
+		injected := webPod()
+		injected.Annotations = map[string]string{pkgK8s.ProxyVersionAnnotation: "v18.8.4"}
+		hostNetwork := webPod()
+		hostNetwork.Spec.HostNetwork = true
+
+		testCases := []struct {
+			name      string
+			namespace string
+			pod       *v1.Pod
+		}{
+			{"in kube-system", "kube-system", webPod()},
+			{"in the control plane namespace", controllerNS, webPod()},
+			{"in a namespace with injection disabled", "legacy", webPod()},
+			{"with injection disabled", "emojivoto", disabled},
+			{"already injected", "emojivoto", injected},
+			{"on the host network", "emojivoto", hostNetwork},
+		}
+
+		webhook := newTestWebhook(t)
+		for _, tc := range testCases {
+			response := webhook.Mutate(podRequest(t, tc.namespace, tc.pod))
+			if !response.Allowed || response.Patch != nil || response.PatchType != nil {
+				t.Fatalf("Expected a pod %s to be allowed without a patch, got %+v", tc.name, response)
+			}
+		}
+	})
+
+	t.Run("Only injects the pods opting in when the cluster default is disabled", func(t *testing.T) {
+		webhook := newTestWebhookWithDefault(t, pkgK8s.ProxyInjectDisabled)
+
+		enabled := webPod()
+		enabled.Annotations = map[string]string{pkgK8s.ProxyInjectAnnotation: pkgK8s.ProxyInjectEnabled}
+		disabled := webPod()
+		disabled.Annotations = map[string]string{pkgK8s.ProxyInjectAnnotation: pkgK8s.ProxyInjectDisabled}
+
+		decodePatch(t, webhook.Mutate(podRequest(t, "books", webPod())))
+		decodePatch(t, webhook.Mutate(podRequest(t, "emojivoto", enabled)))
+		for _, request := range []*admissionV1beta1.AdmissionRequest{
+			podRequest(t, "emojivoto", webPod()),
+			podRequest(t, "books", disabled),
+			podRequest(t, "kube-system", enabled),
+		} {
+			response := webhook.Mutate(request)
+			if !response.Allowed || response.Patch != nil {
+				t.Fatalf("Expected the pod in %s to be allowed without a patch, got %+v", request.Namespace, response)
+			}
+		}
+	})
+
+	t.Run("Allows other objects and operations unchanged", func(t *testing.T) {
+		webhook := newTestWebhook(t)
+
+		update := podRequest(t, "emojivoto", webPod())
+		update.Operation = admissionV1beta1.Update
+		service := podRequest(t, "emojivoto", webPod())
+		service.Kind.Kind = "Service"
+
+		for _, request := range []*admissionV1beta1.AdmissionRequest{update, service} {
+			response := webhook.Mutate(request)
+			if !response.Allowed || response.Patch != nil {
+				t.Fatalf("Expected %s of a %s to be allowed without a patch, got %+v", request.Operation, request.Kind.Kind, response)
+			}
+		}
+	})
+
+	t.Run("Denies pods with invalid annotations", func(t *testing.T) {
+		pod := webPod()
+		pod.Annotations = map[string]string{pkgK8s.ProxySkipInboundPortsAnnotation: "smtp"}
+
+		response := newTestWebhook(t).Mutate(podRequest(t, "emojivoto", pod))
+		if response.Allowed || response.Result == nil || !strings.Contains(response.Result.Message, "invalid linkerd.io/skip-inbound-ports annotation") {
+			t.Fatalf("Expected the pod to be denied because of its annotation, got %+v", response)
+		}
+	})
+}
+
+func TestInjectionDecision(t *testing.T) {
+	// missing stands for an object without the annotation, as opposed to one
+	// with an empty value.
+	const missing = "<missing>"
+	objectMeta := func(name, value string) metaV1.ObjectMeta {
+		if value == missing {
+			return metaV1.ObjectMeta{Name: name}
+		}
+		return metaV1.ObjectMeta{Name: name, Annotations: map[string]string{pkgK8s.ProxyInjectAnnotation: value}}
+	}
+
+	// Each case gives the decision with the cluster default enabled, and with
+	// it disabled, along with the level that decided it.
+	testCases := []struct {
+		pod, namespace    string
+		whenEnabled       bool
+		whenDisabled      bool
+		expectedReasonFor string
+	}{
+		{missing, missing, true, false, "cluster default"},
+		{missing, "", true, false, "cluster default"},
+		{missing, "enabled", true, true, "namespace"},
+		{missing, "disabled", false, false, "namespace"},
+		{missing, "on", true, false, "cluster default"},
+		{"", missing, true, false, "cluster default"},
+		{"", "", true, false, "cluster default"},
+		{"", "enabled", true, true, "namespace"},
+		{"", "disabled", false, false, "namespace"},
+		{"", "on", true, false, "cluster default"},
+		{"enabled", missing, true, true, "pod"},
+		{"enabled", "", true, true, "pod"},
+		{"enabled", "enabled", true, true, "pod"},
+		{"enabled", "disabled", true, true, "pod"},
+		{"enabled", "on", true, true, "pod"},
+		{"disabled", missing, false, false, "pod"},
+		{"disabled", "", false, false, "pod"},
+		{"disabled", "enabled", false, false, "pod"},
+		{"disabled", "disabled", false, false, "pod"},
+		{"disabled", "on", false, false, "pod"},
+		{"Enabled", missing, true, false, "cluster default"},
+		{"Enabled", "", true, false, "cluster default"},
+		{"Enabled", "enabled", true, true, "namespace"},
+		{"Enabled", "disabled", false, false, "namespace"},
+		{"Enabled", "on", true, false, "cluster default"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("pod %s, namespace %s", tc.pod, tc.namespace), func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: objectMeta("web", tc.pod)}
+			ns := &v1.Namespace{ObjectMeta: objectMeta("emojivoto", tc.namespace)}
+
+			for defaultInject, expected := range map[string]bool{
+				pkgK8s.ProxyInjectEnabled:  tc.whenEnabled,
+				pkgK8s.ProxyInjectDisabled: tc.whenDisabled,
+			} {
+				injected, reason := injectionDecision(pod, ns, defaultInject)
+				if injected != expected {
+					t.Fatalf("Expected %t with the cluster default %s, got %t (%s)", expected, defaultInject, injected, reason)
+				}
+				if !strings.HasPrefix(reason, tc.expectedReasonFor+" ") {
+					t.Fatalf("Expected the decision of the %s with the cluster default %s, got [%s]", tc.expectedReasonFor, defaultInject, reason)
+				}
+			}
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	server := httptest.NewServer(newTestWebhook(t))
+	defer server.Close()
+
+	t.Run("Answers AdmissionReviews with the response to their request", func(t *testing.T) {
+		body, err := json.Marshal(admissionV1beta1.AdmissionReview{
+			TypeMeta: metaV1.TypeMeta{Kind: "AdmissionReview", APIVersion: "admission.k8s.io/v1beta1"},
+			Request:  podRequest(t, "emojivoto", webPod()),
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		rsp, err := http.Post(server.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer rsp.Body.Close()
+		if rsp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", rsp.StatusCode)
+		}
+
+		var review admissionV1beta1.AdmissionReview
+		if err := json.NewDecoder(rsp.Body).Decode(&review); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if review.Kind != "AdmissionReview" || review.APIVersion != "admission.k8s.io/v1beta1" {
+			t.Fatalf("Expected an AdmissionReview, got %v", review.TypeMeta)
+		}
+		if review.Request != nil || review.Response == nil {
+			t.Fatalf("Expected only a response, got %+v", review)
+		}
+		if review.Response.UID != "0df28fbd-5f5f-11e8-bc74-36e6bb280816" {
+			t.Fatalf("Expected the UID of the request, got [%s]", review.Response.UID)
+		}
+		decodePatch(t, review.Response)
+	})
+
+	t.Run("Rejects requests that are not AdmissionReviews", func(t *testing.T) {
+		testCases := []struct {
+			method      string
+			contentType string
+			body        string
+			status      int
+		}{
+			{"GET", "", "", http.StatusMethodNotAllowed},
+			{"POST", "text/plain", `{}`, http.StatusUnsupportedMediaType},
+			{"POST", "application/json", `{"request":`, http.StatusBadRequest},
+			{"POST", "application/json", `{"kind":"AdmissionReview"}`, http.StatusBadRequest},
+		}
+
+		for _, tc := range testCases {
+			t.Run(fmt.Sprintf("%s %s %s", tc.method, tc.contentType, tc.body), func(t *testing.T) {
+				req, err := http.NewRequest(tc.method, server.URL, strings.NewReader(tc.body))
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if tc.contentType != "" {
+					req.Header.Set("Content-Type", tc.contentType)
+				}
+				rsp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				rsp.Body.Close()
+				if rsp.StatusCode != tc.status {
+					t.Fatalf("Expected status %d, got %d", tc.status, rsp.StatusCode)
+				}
+			})
+		}
+	})
+}
@@ -1,11 +1,13 @@
 package tap
 
 import (
+	"container/list"
 	"context"
 	"fmt"
 	"io"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
 	netpb "github.com/linkerd/linkerd2-proxy-api/go/net"
@@ -28,6 +30,10 @@ import (
 const podIPIndex = "ip"
 const defaultMaxRps = 100.0
 
+// maxHeldRequests bounds the requests held by a responseStatusFilter until
+// their response is seen; once it is full, the oldest is dropped.
+const maxHeldRequests = 10000
+
 type (
 	server struct {
 		tapPort             uint
@@ -38,6 +44,10 @@ type (
 
 var (
 	tapInterval = 1 * time.Second
+
+	// targetRefreshInterval is how often the pods of a target are listed, to
+	// tap those created since the tap started, and stop tapping those deleted.
+	targetRefreshInterval = 10 * time.Second
 )
 
 func (s *server) Tap(req *public.TapRequest, stream pb.Tap_TapServer) error {
@@ -55,16 +65,77 @@ func (s *server) TapByResource(req *public.TapByResourceRequest, stream pb.Tap_T
 		req.MaxRps = defaultMaxRps
 	}
 
-	objects, err := s.k8sAPI.GetObjects(req.Target.Resource.Namespace, req.Target.Resource.Type, req.Target.Resource.Name)
+	pods, err := s.meshedPods(req.Target.Resource)
+	if err != nil {
+		return apiUtil.GRPCError(err)
+	}
+
+	if len(pods) == 0 {
+		return status.Errorf(codes.NotFound, "no pods found for ResourceSelection: %+v", *req.Target)
+	}
+
+	log.Infof("Tapping %d pods for target: %+v", len(pods), *req.Target.Resource)
+
+	match, statusMatches, err := makeByResourceMatch(req.Match)
 	if err != nil {
 		return apiUtil.GRPCError(err)
 	}
+	filter := newResponseStatusFilter(statusMatches, maxHeldRequests)
+
+	ctx := stream.Context()
+	events := make(chan *public.TapEvent)
+	taps := newPodTaps(req.MaxRps)
+	startTap := func(ctx context.Context, podIP string, done func()) {
+		go func() {
+			s.tapProxy(ctx, taps.rpsPerPod, match, podIP, events)
+			done()
+		}()
+	}
+	taps.update(ctx, pods, startTap)
+
+	// the pods of the target are listed again periodically, so that those
+	// created and deleted while it is tapped join and leave the stream
+	refresh := time.NewTicker(targetRefreshInterval)
+	defer refresh.Stop()
+
+	// read events from the taps and send them back
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-refresh.C:
+			pods, err := s.meshedPods(req.Target.Resource)
+			if err != nil {
+				log.Errorf("could not list the pods of target %+v: %s", *req.Target.Resource, err)
+				continue
+			}
+			taps.update(ctx, pods, startTap)
+
+		case event := <-events:
+			for _, event := range filter.filter(event) {
+				err := stream.Send(event)
+				if err != nil {
+					return apiUtil.GRPCError(err)
+				}
+			}
+		}
+	}
+}
+
+// meshedPods returns the meshed pods of resource, expanding namespaces and
+// workloads into their pods.
+func (s *server) meshedPods(resource *public.Resource) ([]*apiv1.Pod, error) {
+	objects, err := s.k8sAPI.GetObjects(resource.Namespace, resource.Type, resource.Name)
+	if err != nil {
+		return nil, err
+	}
 
 	pods := []*apiv1.Pod{}
 	for _, object := range objects {
 		podsFor, err := s.k8sAPI.GetPodsFor(object, false)
 		if err != nil {
-			return apiUtil.GRPCError(err)
+			return nil, err
 		}
 
 		for _, pod := range podsFor {
@@ -73,44 +144,89 @@ func (s *server) TapByResource(req *public.TapByResourceRequest, stream pb.Tap_T
 			}
 		}
 	}
+	return pods, nil
+}
 
-	if len(pods) == 0 {
-		return status.Errorf(codes.NotFound, "no pods found for ResourceSelection: %+v", *req.Target)
-	}
+// podTaps tracks the taps of the pods of a target, by the IP of the pod, and
+// divides the rate of the target evenly between them.
+type podTaps struct {
+	maxRps float32
 
-	log.Infof("Tapping %d pods for target: %+v", len(pods), *req.Target.Resource)
+	sync.Mutex
+	perPod float32
+	taps   map[string]context.CancelFunc
+}
 
-	events := make(chan *public.TapEvent)
+func newPodTaps(maxRps float32) *podTaps {
+	return &podTaps{
+		maxRps: maxRps,
+		taps:   make(map[string]context.CancelFunc),
+	}
+}
 
-	go func() { // Stop sending back events if the request is cancelled
-		<-stream.Context().Done()
-		close(events)
-	}()
+// update starts the taps of the pods that are not tapped yet with startTap,
+// and stops those of the pods that are gone, by canceling the context given
+// to startTap. Pods without an IP yet are tapped once they have one. startTap
+// calls done once the tap ends, so that a tap that failed, e.g. because the
+// proxy of the pod wasn't ready yet, is started again by the next update.
+func (t *podTaps) update(ctx context.Context, pods []*apiv1.Pod, startTap func(ctx context.Context, ip string, done func())) {
+	t.Lock()
+	defer t.Unlock()
 
-	// divide the rps evenly between all pods to tap
-	rpsPerPod := req.MaxRps / float32(len(pods))
-	if rpsPerPod < 1 {
-		rpsPerPod = 1
+	current := make(map[string]struct{})
+	for _, pod := range pods {
+		if pod.Status.PodIP != "" {
+			current[pod.Status.PodIP] = struct{}{}
+		}
 	}
 
-	match, err := makeByResourceMatch(req.Match)
-	if err != nil {
-		return apiUtil.GRPCError(err)
+	for ip, cancel := range t.taps {
+		if _, ok := current[ip]; !ok {
+			log.Infof("Stopping tap of %s", ip)
+			cancel()
+			delete(t.taps, ip)
+		}
 	}
 
-	for _, pod := range pods {
-		// initiate a tap on the pod
-		go s.tapProxy(stream.Context(), rpsPerPod, match, pod.Status.PodIP, events)
+	// divide the rps evenly between all pods to tap
+	t.perPod = t.maxRps
+	if len(current) > 0 {
+		t.perPod = t.maxRps / float32(len(current))
+	}
+	if t.perPod < 1 {
+		t.perPod = 1
 	}
 
-	// read events from the taps and send them back
-	for event := range events {
-		err := stream.Send(event)
-		if err != nil {
-			return apiUtil.GRPCError(err)
+	for ip := range current {
+		ip := ip
+		if _, ok := t.taps[ip]; !ok {
+			tapCtx, cancel := context.WithCancel(ctx)
+			t.taps[ip] = cancel
+			startTap(tapCtx, ip, func() { t.done(tapCtx, ip) })
 		}
 	}
-	return nil
+}
+
+// done forgets the tap of ip, given its context, once it has ended. Taps that
+// were stopped by update are already forgotten, and their IP may be tapped
+// again since.
+func (t *podTaps) done(ctx context.Context, ip string) {
+	t.Lock()
+	defer t.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+	log.Infof("Tap of %s ended, it will be established again on the next refresh", ip)
+	t.taps[ip]()
+	delete(t.taps, ip)
+}
+
+// rpsPerPod returns the rate at which each pod is tapped.
+func (t *podTaps) rpsPerPod() float32 {
+	t.Lock()
+	defer t.Unlock()
+	return t.perPod
 }
 
 // TODO: validate scheme
@@ -147,14 +263,17 @@ func parseMethod(method string) *proxy.HttpMethod {
 	}
 }
 
-func makeByResourceMatch(match *public.TapByResourceRequest_Match) (*proxy.ObserveRequest_Match, error) {
+// makeByResourceMatch returns the match of the events the proxies report, and
+// the statuses the responses must match, which the proxies cannot match on.
+func makeByResourceMatch(match *public.TapByResourceRequest_Match) (*proxy.ObserveRequest_Match, []apiUtil.HTTPStatusMatch, error) {
 	// TODO: for now assume it's always a single, flat `All` match list
 	seq := match.GetAll()
 	if seq == nil {
-		return nil, status.Errorf(codes.Unimplemented, "unexpected match specified: %+v", match)
+		return nil, nil, status.Errorf(codes.Unimplemented, "unexpected match specified: %+v", match)
 	}
 
 	matches := []*proxy.ObserveRequest_Match{}
+	statusMatches := []apiUtil.HTTPStatusMatch{}
 
 	for _, reqMatch := range seq.Matches {
 		switch typed := reqMatch.Match.(type) {
@@ -172,6 +291,14 @@ func makeByResourceMatch(match *public.TapByResourceRequest_Match) (*proxy.Obser
 			}
 
 		case *public.TapByResourceRequest_Match_Http_:
+			if httpStatus := typed.Http.GetStatus(); httpStatus != "" {
+				statusMatch, err := apiUtil.ParseHTTPStatusMatch(httpStatus)
+				if err != nil {
+					return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+				}
+				statusMatches = append(statusMatches, statusMatch)
+				continue
+			}
 
 			httpMatch := proxy.ObserveRequest_Match_Http{}
 
@@ -209,7 +336,7 @@ func makeByResourceMatch(match *public.TapByResourceRequest_Match) (*proxy.Obser
 					},
 				}
 			default:
-				return nil, status.Errorf(codes.Unimplemented, "unknown HTTP match type: %v", httpTyped)
+				return nil, nil, status.Errorf(codes.Unimplemented, "unknown HTTP match type: %v", httpTyped)
 			}
 
 			matches = append(matches, &proxy.ObserveRequest_Match{
@@ -219,7 +346,7 @@ func makeByResourceMatch(match *public.TapByResourceRequest_Match) (*proxy.Obser
 			})
 
 		default:
-			return nil, status.Errorf(codes.Unimplemented, "unknown match type: %v", typed)
+			return nil, nil, status.Errorf(codes.Unimplemented, "unknown match type: %v", typed)
 		}
 	}
 
@@ -229,7 +356,112 @@ func makeByResourceMatch(match *public.TapByResourceRequest_Match) (*proxy.Obser
 				Matches: matches,
 			},
 		},
-	}, nil
+	}, statusMatches, nil
+}
+
+// responseStatusFilter passes on the events of the requests whose response
+// status matches all of its matches. The event of each request is held until
+// that of the start of its response, and both are passed on if it matches,
+// followed by the event of the end of the response.
+type responseStatusFilter struct {
+	matches  []apiUtil.HTTPStatusMatch
+	maxHeld  int
+	streams  map[tapStreamKey]*list.Element
+	requests *list.List
+}
+
+// tapStreamKey identifies the request of an event, as reported by a proxy.
+type tapStreamKey struct {
+	source, destination string
+	direction           public.TapEvent_ProxyDirection
+	base                uint32
+	stream              uint64
+}
+
+// heldRequest is the event of a request, held until its response is seen;
+// matched is set once the status of the response matched, until it ends.
+type heldRequest struct {
+	key     tapStreamKey
+	event   *public.TapEvent
+	matched bool
+}
+
+// newResponseStatusFilter returns a filter holding at most maxHeld requests,
+// or nil, which passes on all events, if there are no matches.
+func newResponseStatusFilter(matches []apiUtil.HTTPStatusMatch, maxHeld int) *responseStatusFilter {
+	if len(matches) == 0 {
+		return nil
+	}
+	return &responseStatusFilter{
+		matches:  matches,
+		maxHeld:  maxHeld,
+		streams:  make(map[tapStreamKey]*list.Element),
+		requests: list.New(),
+	}
+}
+
+// filter returns the events to pass on upon receiving event.
+func (f *responseStatusFilter) filter(event *public.TapEvent) []*public.TapEvent {
+	if f == nil {
+		return []*public.TapEvent{event}
+	}
+
+	key := tapStreamKey{
+		source:      addr.PublicAddressToString(event.GetSource()),
+		destination: addr.PublicAddressToString(event.GetDestination()),
+		direction:   event.GetProxyDirection(),
+	}
+	switch ev := event.GetHttp().GetEvent().(type) {
+	case *public.TapEvent_Http_RequestInit_:
+		key.base, key.stream = ev.RequestInit.GetId().GetBase(), ev.RequestInit.GetId().GetStream()
+		f.forget(key)
+		f.streams[key] = f.requests.PushBack(&heldRequest{key: key, event: event})
+		if f.requests.Len() > f.maxHeld {
+			f.forget(f.requests.Front().Value.(*heldRequest).key)
+		}
+
+	case *public.TapEvent_Http_ResponseInit_:
+		key.base, key.stream = ev.ResponseInit.GetId().GetBase(), ev.ResponseInit.GetId().GetStream()
+		elem, ok := f.streams[key]
+		if !ok {
+			return nil
+		}
+		held := elem.Value.(*heldRequest)
+		if held.matched || !f.matchesStatus(ev.ResponseInit.GetHttpStatus()) {
+			f.forget(key)
+			return nil
+		}
+		held.matched = true
+		return []*public.TapEvent{held.event, event}
+
+	case *public.TapEvent_Http_ResponseEnd_:
+		key.base, key.stream = ev.ResponseEnd.GetId().GetBase(), ev.ResponseEnd.GetId().GetStream()
+		elem, ok := f.streams[key]
+		if !ok {
+			return nil
+		}
+		f.forget(key)
+		if elem.Value.(*heldRequest).matched {
+			return []*public.TapEvent{event}
+		}
+	}
+	return nil
+}
+
+func (f *responseStatusFilter) matchesStatus(httpStatus uint32) bool {
+	for _, match := range f.matches {
+		if !match.Matches(httpStatus) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *responseStatusFilter) forget(key tapStreamKey) {
+	if elem, ok := f.streams[key]; ok {
+		f.requests.Remove(elem)
+		delete(f.streams, key)
+	}
 }
 
 // TODO: factor out with `promLabels` in public-api
@@ -251,8 +483,9 @@ func destinationLabels(resource *public.Resource) map[string]string {
 // To limit the rps to maxRps, this method calls Observe on the pod with a limit
 // of maxRps * 1s at most once per 1s window.  If this limit is reached in
 // less than 1s, we sleep until the end of the window before calling Observe
-// again.
-func (s *server) tapProxy(ctx context.Context, maxRps float32, match *proxy.ObserveRequest_Match, addr string, events chan *public.TapEvent) {
+// again. maxRps is read at the start of each window, as it changes when pods
+// join or leave the tap.
+func (s *server) tapProxy(ctx context.Context, maxRps func() float32, match *proxy.ObserveRequest_Match, addr string, events chan<- *public.TapEvent) {
 	tapAddr := fmt.Sprintf("%s:%d", addr, s.tapPort)
 	log.Infof("Establishing tap on %s", tapAddr)
 	conn, err := grpc.DialContext(ctx, tapAddr, grpc.WithInsecure())
@@ -260,19 +493,21 @@ func (s *server) tapProxy(ctx context.Context, maxRps float32, match *proxy.Obse
 		log.Error(err)
 		return
 	}
+	defer conn.Close()
 	client := proxy.NewTapClient(conn)
 
-	req := &proxy.ObserveRequest{
-		Limit: uint32(maxRps * float32(tapInterval.Seconds())),
-		Match: match,
-	}
-
 	for { // Request loop
 		windowStart := time.Now()
 		windowEnd := windowStart.Add(tapInterval)
+		req := &proxy.ObserveRequest{
+			Limit: uint32(maxRps() * float32(tapInterval.Seconds())),
+			Match: match,
+		}
 		rsp, err := client.Observe(ctx, req)
 		if err != nil {
-			log.Error(err)
+			if ctx.Err() == nil {
+				log.Error(err)
+			}
 			return
 		}
 		for { // Stream loop
@@ -281,13 +516,21 @@ func (s *server) tapProxy(ctx context.Context, maxRps float32, match *proxy.Obse
 				break
 			}
 			if err != nil {
-				log.Error(err)
+				if ctx.Err() == nil {
+					log.Error(err)
+				}
+				return
+			}
+			select {
+			case events <- s.translateEvent(event):
+			case <-ctx.Done():
 				return
 			}
-			events <- s.translateEvent(event)
 		}
-		if time.Now().Before(windowEnd) {
-			time.Sleep(time.Until(windowEnd))
+		select {
+		case <-time.After(time.Until(windowEnd)):
+		case <-ctx.Done():
+			return
 		}
 	}
 }
@@ -2,12 +2,17 @@ package tap
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
+	proxy "github.com/linkerd/linkerd2-proxy-api/go/tap"
+	apiUtil "github.com/linkerd/linkerd2/controller/api/util"
 	public "github.com/linkerd/linkerd2/controller/gen/public"
 	"github.com/linkerd/linkerd2/controller/k8s"
+	"github.com/linkerd/linkerd2/pkg/addr"
 	pkgK8s "github.com/linkerd/linkerd2/pkg/k8s"
+	apiv1 "k8s.io/api/core/v1"
 )
 
 type tapExpected struct {
@@ -146,6 +151,44 @@ status:
 					},
 				},
 			},
+			tapExpected{
+				msg: "rpc error: code = InvalidArgument desc = invalid HTTP status [6xx], must be a code between 100 and 599, or a class from 1xx to 5xx",
+				k8sRes: []string{`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emojivoto-meshed
+  namespace: emojivoto
+  labels:
+    app: emoji-svc
+    linkerd.io/control-plane-ns: controller-ns
+  annotations:
+    linkerd.io/proxy-version: testinjectversion
+status:
+  phase: Running
+`,
+				},
+				req: public.TapByResourceRequest{
+					Target: &public.ResourceSelection{
+						Resource: &public.Resource{
+							Namespace: "emojivoto",
+							Type:      pkgK8s.Pod,
+							Name:      "emojivoto-meshed",
+						},
+					},
+					Match: &public.TapByResourceRequest_Match{
+						Match: &public.TapByResourceRequest_Match_All{
+							All: &public.TapByResourceRequest_Match_Seq{
+								Matches: []*public.TapByResourceRequest_Match{
+									httpMatch(&public.TapByResourceRequest_Match_Http{
+										Match: &public.TapByResourceRequest_Match_Http_Status{Status: "6xx"},
+									}),
+								},
+							},
+						},
+					},
+				},
+			},
 			tapExpected{
 				// indicates we will accept EOF, in addition to the deadline exceeded message
 				eofOk: true,
@@ -222,3 +265,338 @@ status:
 		}
 	})
 }
+
+func TestMeshedPods(t *testing.T) {
+	t.Run("Expands a namespace into its meshed pods", func(t *testing.T) {
+		k8sAPI, err := k8s.NewFakeAPI(`
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: emojivoto
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-meshed
+  namespace: emojivoto
+  labels:
+    linkerd.io/control-plane-ns: controller-ns
+status:
+  phase: Running
+  podIP: 10.0.0.1
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: voting-meshed
+  namespace: emojivoto
+  labels:
+    linkerd.io/control-plane-ns: controller-ns
+status:
+  phase: Running
+  podIP: 10.0.0.2
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: emoji-not-meshed
+  namespace: emojivoto
+status:
+  phase: Running
+  podIP: 10.0.0.3
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: books-meshed
+  namespace: books
+  labels:
+    linkerd.io/control-plane-ns: controller-ns
+status:
+  phase: Running
+  podIP: 10.0.0.4
+`)
+		if err != nil {
+			t.Fatalf("NewFakeAPI returned an error: %s", err)
+		}
+		k8sAPI.Sync(nil)
+
+		s := &server{k8sAPI: k8sAPI, controllerNamespace: "controller-ns"}
+		pods, err := s.meshedPods(&public.Resource{Type: pkgK8s.Namespace, Name: "emojivoto"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		names := make(map[string]bool)
+		for _, pod := range pods {
+			names[pod.Name] = true
+		}
+		expected := map[string]bool{"web-meshed": true, "voting-meshed": true}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Expected pods %v, got %v", expected, names)
+		}
+	})
+}
+
+func TestPodTaps(t *testing.T) {
+	pod := func(ip string) *apiv1.Pod {
+		return &apiv1.Pod{Status: apiv1.PodStatus{PodIP: ip}}
+	}
+
+	t.Run("Starts the taps of new pods and stops those of pods that are gone", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		started := make(map[string]context.Context)
+		start := func(ctx context.Context, ip string, done func()) {
+			if _, ok := started[ip]; ok {
+				t.Fatalf("Expected %s to be tapped once", ip)
+			}
+			started[ip] = ctx
+		}
+
+		taps := newPodTaps(100)
+		taps.update(ctx, []*apiv1.Pod{pod("10.0.0.1"), pod("10.0.0.2"), pod("")}, start)
+		if len(started) != 2 {
+			t.Fatalf("Expected the 2 pods with an IP to be tapped, got %v", started)
+		}
+		if rps := taps.rpsPerPod(); rps != 50 {
+			t.Fatalf("Expected each pod to be tapped at 50 rps, got %g", rps)
+		}
+
+		taps.update(ctx, []*apiv1.Pod{pod("10.0.0.2"), pod("10.0.0.3"), pod("10.0.0.4")}, start)
+		if started["10.0.0.1"].Err() == nil {
+			t.Fatalf("Expected the tap of the deleted pod to be stopped")
+		}
+		for _, ip := range []string{"10.0.0.2", "10.0.0.3", "10.0.0.4"} {
+			if tapCtx, ok := started[ip]; !ok || tapCtx.Err() != nil {
+				t.Fatalf("Expected %s to be tapped", ip)
+			}
+		}
+		if rps := taps.rpsPerPod(); rps != float32(100)/3 {
+			t.Fatalf("Expected the rate to be shared between the 3 pods, got %g", rps)
+		}
+	})
+
+	t.Run("Starts the taps that failed again on the next update", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		started := make(map[string]int)
+		done := make(map[string]func())
+		start := func(ctx context.Context, ip string, tapDone func()) {
+			started[ip]++
+			done[ip] = tapDone
+		}
+
+		pods := []*apiv1.Pod{pod("10.0.0.1"), pod("10.0.0.2")}
+		taps := newPodTaps(100)
+		taps.update(ctx, pods, start)
+
+		// the tap of 10.0.0.1 fails, e.g. as its proxy isn't ready yet
+		done["10.0.0.1"]()
+
+		taps.update(ctx, pods, start)
+		expected := map[string]int{"10.0.0.1": 2, "10.0.0.2": 1}
+		if !reflect.DeepEqual(started, expected) {
+			t.Fatalf("Expected taps %v, got %v", expected, started)
+		}
+	})
+
+	t.Run("Does not forget the new tap of a pod whose previous tap was stopped", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var done []func()
+		started := 0
+		start := func(ctx context.Context, ip string, tapDone func()) {
+			started++
+			done = append(done, tapDone)
+		}
+
+		taps := newPodTaps(100)
+		taps.update(ctx, []*apiv1.Pod{pod("10.0.0.1")}, start)
+		taps.update(ctx, []*apiv1.Pod{}, start)
+		taps.update(ctx, []*apiv1.Pod{pod("10.0.0.1")}, start)
+
+		// the stopped tap ends after the new one started
+		done[0]()
+
+		taps.update(ctx, []*apiv1.Pod{pod("10.0.0.1")}, start)
+		if started != 2 {
+			t.Fatalf("Expected 10.0.0.1 to be tapped twice, got %d", started)
+		}
+	})
+
+	t.Run("Taps each pod at 1 rps at least", func(t *testing.T) {
+		taps := newPodTaps(1)
+		taps.update(context.Background(), []*apiv1.Pod{pod("10.0.0.1"), pod("10.0.0.2")}, func(context.Context, string, func()) {})
+		if rps := taps.rpsPerPod(); rps != 1 {
+			t.Fatalf("Expected each pod to be tapped at 1 rps, got %g", rps)
+		}
+	})
+
+	t.Run("Keeps a finite rate without pods", func(t *testing.T) {
+		taps := newPodTaps(100)
+		taps.update(context.Background(), []*apiv1.Pod{pod("")}, func(context.Context, string, func()) {})
+		if rps := taps.rpsPerPod(); rps != 100 {
+			t.Fatalf("Expected a rate of 100 rps, got %g", rps)
+		}
+	})
+}
+
+func TestMakeByResourceMatch(t *testing.T) {
+	t.Run("Requires all the matches, leaving the statuses to the tap service", func(t *testing.T) {
+		match := &public.TapByResourceRequest_Match{
+			Match: &public.TapByResourceRequest_Match_All{
+				All: &public.TapByResourceRequest_Match_Seq{
+					Matches: []*public.TapByResourceRequest_Match{
+						httpMatch(&public.TapByResourceRequest_Match_Http{
+							Match: &public.TapByResourceRequest_Match_Http_Method{Method: "post"},
+						}),
+						httpMatch(&public.TapByResourceRequest_Match_Http{
+							Match: &public.TapByResourceRequest_Match_Http_Status{Status: "5xx"},
+						}),
+						httpMatch(&public.TapByResourceRequest_Match_Http{
+							Match: &public.TapByResourceRequest_Match_Http_Path{Path: "/api/checkout"},
+						}),
+					},
+				},
+			},
+		}
+
+		proxyMatch, statusMatches, err := makeByResourceMatch(match)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := &proxy.ObserveRequest_Match{
+			Match: &proxy.ObserveRequest_Match_All{
+				All: &proxy.ObserveRequest_Match_Seq{
+					Matches: []*proxy.ObserveRequest_Match{
+						{
+							Match: &proxy.ObserveRequest_Match_Http_{
+								Http: &proxy.ObserveRequest_Match_Http{
+									Match: &proxy.ObserveRequest_Match_Http_Method{
+										Method: &proxy.HttpMethod{
+											Type: &proxy.HttpMethod_Registered_{Registered: proxy.HttpMethod_POST},
+										},
+									},
+								},
+							},
+						},
+						{
+							Match: &proxy.ObserveRequest_Match_Http_{
+								Http: &proxy.ObserveRequest_Match_Http{
+									Match: &proxy.ObserveRequest_Match_Http_Path{
+										Path: &proxy.ObserveRequest_Match_Http_StringMatch{
+											Match: &proxy.ObserveRequest_Match_Http_StringMatch_Prefix{Prefix: "/api/checkout"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		if !reflect.DeepEqual(proxyMatch, expected) {
+			t.Fatalf("Expected the proxy match to be %v, got %v", expected, proxyMatch)
+		}
+		if len(statusMatches) != 1 || !statusMatches[0].Matches(503) || statusMatches[0].Matches(200) {
+			t.Fatalf("Expected a match of the 5xx statuses, got %v", statusMatches)
+		}
+	})
+}
+
+func TestResponseStatusFilter(t *testing.T) {
+	matches := []apiUtil.HTTPStatusMatch{}
+	for _, s := range []string{"5xx", "503"} {
+		match, err := apiUtil.ParseHTTPStatusMatch(s)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		matches = append(matches, match)
+	}
+
+	t.Run("Passes on all events without matches", func(t *testing.T) {
+		events := statusFilterEvents(1, 200)
+		filter := newResponseStatusFilter(nil, maxHeldRequests)
+		for i := range events {
+			if filtered := filter.filter(events[i]); len(filtered) != 1 || filtered[0] != events[i] {
+				t.Fatalf("Expected event %d to be passed on, got %v", i, filtered)
+			}
+		}
+	})
+
+	t.Run("Passes on the request once its response matches all the statuses", func(t *testing.T) {
+		filter := newResponseStatusFilter(matches, maxHeldRequests)
+		matching := statusFilterEvents(1, 503)
+		other := statusFilterEvents(2, 500)
+
+		var filtered []*public.TapEvent
+		for _, event := range []*public.TapEvent{matching[0], other[0], other[1], matching[1], other[2], matching[2]} {
+			filtered = append(filtered, filter.filter(event)...)
+		}
+
+		if !reflect.DeepEqual(filtered, matching) {
+			t.Fatalf("Expected only the events of the request with a 503 response, got %v", filtered)
+		}
+		if len(filter.streams) != 0 || filter.requests.Len() != 0 {
+			t.Fatalf("Expected no requests to be held once their responses ended, got %d", len(filter.streams))
+		}
+	})
+
+	t.Run("Drops the responses to requests that were not seen", func(t *testing.T) {
+		filter := newResponseStatusFilter(matches, maxHeldRequests)
+		orphan := statusFilterEvents(3, 503)
+		for _, event := range orphan[1:] {
+			if filtered := filter.filter(event); len(filtered) != 0 {
+				t.Fatalf("Expected the orphan response event to be dropped, got %v", filtered)
+			}
+		}
+	})
+
+	t.Run("Drops the oldest requests once too many are held", func(t *testing.T) {
+		filter := newResponseStatusFilter(matches, 1)
+		first := statusFilterEvents(4, 503)
+		second := statusFilterEvents(5, 503)
+		filter.filter(first[0])
+		filter.filter(second[0])
+
+		if filtered := filter.filter(first[1]); len(filtered) != 0 {
+			t.Fatalf("Expected the oldest request to be dropped, got %v", filtered)
+		}
+		if filtered := filter.filter(second[1]); len(filtered) != 2 {
+			t.Fatalf("Expected the latest request and its response to be passed on, got %v", filtered)
+		}
+	})
+}
+
+func httpMatch(match *public.TapByResourceRequest_Match_Http) *public.TapByResourceRequest_Match {
+	return &public.TapByResourceRequest_Match{
+		Match: &public.TapByResourceRequest_Match_Http_{Http: match},
+	}
+}
+
+// statusFilterEvents returns the request, response init and response end
+// events of a request on the given stream, answered with httpStatus.
+func statusFilterEvents(stream uint64, httpStatus uint32) []*public.TapEvent {
+	id := &public.TapEvent_Http_StreamId{Base: 1, Stream: stream}
+	httpEvents := []*public.TapEvent_Http{
+		{Event: &public.TapEvent_Http_RequestInit_{RequestInit: &public.TapEvent_Http_RequestInit{Id: id}}},
+		{Event: &public.TapEvent_Http_ResponseInit_{ResponseInit: &public.TapEvent_Http_ResponseInit{Id: id, HttpStatus: httpStatus}}},
+		{Event: &public.TapEvent_Http_ResponseEnd_{ResponseEnd: &public.TapEvent_Http_ResponseEnd{Id: id}}},
+	}
+
+	events := []*public.TapEvent{}
+	for _, httpEvent := range httpEvents {
+		events = append(events, &public.TapEvent{
+			ProxyDirection: public.TapEvent_OUTBOUND,
+			Source:         &public.TcpAddress{Ip: addr.PublicIPV4(10, 0, 0, 1), Port: 5555},
+			Destination:    &public.TcpAddress{Ip: addr.PublicIPV4(10, 0, 0, 2), Port: 8080},
+			Event:          &public.TapEvent_Http_{Http: httpEvent},
+		})
+	}
+	return events
+}
@@ -0,0 +1,114 @@
+package healthcheck
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+// CategoryID identifies a category of checks. It prefixes the description of
+// each check in the output of `linkerd check`, and is the name passed to its
+// --category flag, so the values below must not change.
+type CategoryID string
+
+const (
+	KubernetesAPICategory               CategoryID = k8s.KubernetesAPICategory
+	KubernetesVersionCategory           CategoryID = k8s.KubernetesVersionCategory
+	KubernetesClockCategory             CategoryID = k8s.KubernetesClockCategory
+	KubernetesDistributionCategory      CategoryID = "kubernetes-distribution"
+	KubernetesNodesCategory             CategoryID = "kubernetes-nodes"
+	LinkerdPreInstallCategory           CategoryID = "linkerd-ns"
+	LinkerdPreInstallCapabilityCategory CategoryID = "pre-kubernetes-capability"
+	LinkerdDataPlaneCategory            CategoryID = "linkerd-data-plane"
+	LinkerdAPICategory                  CategoryID = "linkerd-api"
+	LinkerdControlPlaneCategory         CategoryID = "linkerd-control-plane"
+	LinkerdVersionCategory              CategoryID = "linkerd-version"
+	LinkerdProxyInjectorCategory        CategoryID = "linkerd-proxy-injector"
+)
+
+// categoryPrerequisites lists, for each category, the categories whose checks
+// set up the clients or state its own checks use, and which therefore run
+// whenever it is selected.
+var categoryPrerequisites = map[CategoryID][]CategoryID{
+	KubernetesVersionCategory:           {KubernetesAPICategory},
+	KubernetesClockCategory:             {KubernetesAPICategory},
+	KubernetesDistributionCategory:      {KubernetesAPICategory},
+	KubernetesNodesCategory:             {KubernetesAPICategory},
+	LinkerdPreInstallCategory:           {KubernetesAPICategory},
+	LinkerdPreInstallCapabilityCategory: {KubernetesAPICategory},
+	LinkerdDataPlaneCategory:            {KubernetesAPICategory},
+	LinkerdAPICategory:                  {KubernetesAPICategory},
+	LinkerdControlPlaneCategory:         {KubernetesAPICategory},
+	LinkerdVersionCategory:              {LinkerdAPICategory},
+	LinkerdProxyInjectorCategory:        {KubernetesAPICategory},
+}
+
+// Categories returns the categories of the configured checks, in the order
+// they run.
+func (hc *HealthChecker) Categories() []CategoryID {
+	categories := []CategoryID{}
+	seen := make(map[CategoryID]bool)
+	for _, c := range hc.checkers {
+		if !seen[c.category] {
+			seen[c.category] = true
+			categories = append(categories, c.category)
+		}
+	}
+	return categories
+}
+
+// SelectCategories restricts RunChecks to the checks of the named
+// categories, and of the categories they depend on. The results of the
+// prerequisite checks are only reported if they fail. It returns an error if
+// any of names is not one of Categories.
+func (hc *HealthChecker) SelectCategories(names []string) error {
+	configured := make(map[CategoryID]bool)
+	valid := make([]string, 0)
+	for _, category := range hc.Categories() {
+		configured[category] = true
+		valid = append(valid, string(category))
+	}
+
+	selected := make(map[CategoryID]bool)
+	for _, name := range names {
+		category := CategoryID(name)
+		if !configured[category] {
+			return fmt.Errorf("unknown check category \"%s\", must be one of: %s", name, strings.Join(valid, ", "))
+		}
+		selected[category] = true
+	}
+
+	hc.selected = selected
+	hc.prerequisites = make(map[CategoryID]bool)
+	for category := range selected {
+		hc.addPrerequisites(category, configured)
+	}
+	return nil
+}
+
+func (hc *HealthChecker) addPrerequisites(category CategoryID, configured map[CategoryID]bool) {
+	for _, prerequisite := range categoryPrerequisites[category] {
+		if !configured[prerequisite] || hc.selected[prerequisite] || hc.prerequisites[prerequisite] {
+			continue
+		}
+		hc.prerequisites[prerequisite] = true
+		hc.addPrerequisites(prerequisite, configured)
+	}
+}
+
+// observerFor returns the observer of the results of the checks of category,
+// or nil if they are not selected.
+func (hc *HealthChecker) observerFor(category CategoryID, observer checkObserver) checkObserver {
+	switch {
+	case hc.selected == nil || hc.selected[category]:
+		return observer
+	case hc.prerequisites[category]:
+		return func(result *CheckResult) {
+			if result.Err != nil || result.Progress != "" {
+				observer(result)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestSelectCategories(t *testing.T) {
+	newHealthChecker := func(kubeErr error) *HealthChecker {
+		hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{})
+		hc.Add(KubernetesAPICategory, "can query the Kubernetes API", func() error { return kubeErr })
+		hc.Add(KubernetesVersionCategory, "is running the minimum Kubernetes API version", func() error { return nil })
+		hc.Add(LinkerdAPICategory, "can query the control plane API", func() error { return nil })
+		hc.Add(LinkerdVersionCategory, "control plane is up-to-date", func() error { return nil })
+		return hc
+	}
+
+	run := func(hc *HealthChecker) []string {
+		observed := []string{}
+		hc.RunChecks(context.Background(), func(result *CheckResult) {
+			res := fmt.Sprintf("%s %s", result.Category, result.Description)
+			if result.Err != nil {
+				res += fmt.Sprintf(": %s", result.Err)
+			}
+			observed = append(observed, res)
+		})
+		return observed
+	}
+
+	t.Run("Lists the configured categories", func(t *testing.T) {
+		expected := []CategoryID{KubernetesAPICategory, KubernetesVersionCategory, LinkerdAPICategory, LinkerdVersionCategory}
+		if categories := newHealthChecker(nil).Categories(); !reflect.DeepEqual(categories, expected) {
+			t.Fatalf("Expected categories %v, got %v", expected, categories)
+		}
+	})
+
+	t.Run("Runs all of the checks by default", func(t *testing.T) {
+		if observed := run(newHealthChecker(nil)); len(observed) != 4 {
+			t.Fatalf("Expected 4 results, got %q", observed)
+		}
+	})
+
+	t.Run("Only reports the selected categories", func(t *testing.T) {
+		hc := newHealthChecker(nil)
+		if err := hc.SelectCategories([]string{"kubernetes-version"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []string{"kubernetes-version is running the minimum Kubernetes API version"}
+		if observed := run(hc); !reflect.DeepEqual(observed, expected) {
+			t.Fatalf("Expected results %q, got %q", expected, observed)
+		}
+	})
+
+	t.Run("Reports failed prerequisites", func(t *testing.T) {
+		hc := newHealthChecker(fmt.Errorf("connection refused"))
+		if err := hc.SelectCategories([]string{"kubernetes-version"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []string{
+			"kubernetes-api can query the Kubernetes API: connection refused",
+			"kubernetes-version is running the minimum Kubernetes API version",
+		}
+		if observed := run(hc); !reflect.DeepEqual(observed, expected) {
+			t.Fatalf("Expected results %q, got %q", expected, observed)
+		}
+	})
+
+	t.Run("Runs prerequisites of prerequisites", func(t *testing.T) {
+		hc := newHealthChecker(nil)
+		if err := hc.SelectCategories([]string{"linkerd-version"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := map[CategoryID]bool{KubernetesAPICategory: true, LinkerdAPICategory: true}
+		if !reflect.DeepEqual(hc.prerequisites, expected) {
+			t.Fatalf("Expected prerequisites %v, got %v", expected, hc.prerequisites)
+		}
+	})
+
+	t.Run("Rejects unknown categories", func(t *testing.T) {
+		err := newHealthChecker(nil).SelectCategories([]string{"kubernetes-version", "linkerd-ns"})
+		expected := "unknown check category \"linkerd-ns\", must be one of: kubernetes-api, kubernetes-version, linkerd-api, linkerd-version"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestPublicAPICategories(t *testing.T) {
+	// The checks that the commands using the public API run before they
+	// query it must not require more access than those commands do.
+	hc := NewHealthChecker([]Checks{KubernetesAPIChecks, LinkerdAPIChecks}, &HealthCheckOptions{})
+	expected := []CategoryID{KubernetesAPICategory, LinkerdAPICategory}
+	if categories := hc.Categories(); !reflect.DeepEqual(categories, expected) {
+		t.Fatalf("Expected categories %v, got %v", expected, categories)
+	}
+}
@@ -2,8 +2,9 @@ package healthcheck
 
 import (
 	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
@@ -25,15 +26,19 @@ const (
 	// ShouldCheckKubeVersion option is false.
 	KubernetesAPIChecks Checks = iota
 
-	// LinkerdPreInstallChecks adds a check to validate that the control plane
-	// namespace does not already exist. This check only runs as part of the set
-	// of pre-install checks.
+	// LinkerdPreInstallChecks adds checks to validate that the control plane
+	// namespace does not already contain a previous install, warning if the
+	// namespace exists at all, and that the caller is allowed to create each
+	// kind of resource in the install manifest. These checks only run as part
+	// of the set of pre-install checks.
 	// This check is dependent on the output of KubernetesAPIChecks, so those
 	// checks must be added first.
 	LinkerdPreInstallChecks
 
-	// LinkerdDataPlaneChecks adds a data plane check to validate that the proxy
-	// containers are in the ready state.
+	// LinkerdDataPlaneChecks adds data plane checks to validate that there are
+	// meshed pods, that their proxy containers are ready and answer readiness
+	// requests, that the destination service is available to them, and to
+	// warn about proxies that are not running the expected version.
 	// This check is dependent on the output of KubernetesAPIChecks, so those
 	// checks must be added first.
 	LinkerdDataPlaneChecks
@@ -51,45 +56,189 @@ const (
 	// is false.
 	LinkerdVersionChecks
 
-	KubernetesAPICategory     = "kubernetes-api"
-	LinkerdPreInstallCategory = "linkerd-ns"
-	LinkerdDataPlaneCategory  = "linkerd-data-plane"
-	LinkerdAPICategory        = "linkerd-api"
-	LinkerdVersionCategory    = "linkerd-version"
+	// KubernetesNodeChecks adds a check that warns about nodes the data plane
+	// cannot run on, because of their operating system, architecture or
+	// kernel version.
+	// This check is dependent on the output of KubernetesAPIChecks, so those
+	// checks must be added first.
+	KubernetesNodeChecks
+
+	// LinkerdControlPlaneChecks adds checks diagnosing the control plane
+	// beyond what LinkerdAPIChecks needs to reach the public API, such as the
+	// validity of its RBAC bindings, the status of the pods of each of its
+	// components, the rollout status of its deployments, the readiness of
+	// Prometheus and whether a high-availability install has enough nodes.
+	// Unlike LinkerdAPIChecks, they require cluster-wide read access, and only
+	// run as part of `linkerd check`.
+	// These checks are dependent on the output of KubernetesAPIChecks, so
+	// those checks must be added first.
+	LinkerdControlPlaneChecks
+
+	// KubernetesClockChecks adds a check that warns if the local clock is out
+	// of sync with the clock of the Kubernetes API server, using the result of
+	// KubernetesAPI.SelfCheck. It only runs as part of `linkerd check`, so
+	// that a skewed clock does not get in the way of the other commands.
+	// This check is dependent on the output of KubernetesAPIChecks, so those
+	// checks must be added first.
+	KubernetesClockChecks
+
+	// KubernetesDistributionChecks adds a check that reports whether the
+	// cluster runs OpenShift, by listing the API groups it serves. It only
+	// runs as part of `linkerd check`, to spare the other commands the
+	// request.
+	// This check is dependent on the output of KubernetesAPIChecks, so those
+	// checks must be added first.
+	KubernetesDistributionChecks
+
+	// LinkerdProxyInjectorChecks adds checks validating the webhook
+	// configuration, certificate and endpoints of the proxy injector of
+	// control planes installed with --proxy-auto-inject. They require read
+	// access to Secrets and to MutatingWebhookConfigurations, and only run as
+	// part of `linkerd check`.
+	// These checks are dependent on the output of KubernetesAPIChecks, so
+	// those checks must be added first.
+	LinkerdProxyInjectorChecks
 )
 
 var (
-	maxRetries  = 10
-	retryWindow = 5 * time.Second
+	// retryBaseDelay is the delay before the first retry of a failed
+	// retryable check, doubled for each following retry, up to retryWindow.
+	retryBaseDelay = time.Second
+	retryWindow    = 5 * time.Second
+
+	// controlPlaneDeployments are the deployments whose rollout status is
+	// reported by the linkerd-control-plane checks. The optional ca deployment is
+	// covered by the pod checks only.
+	controlPlaneDeployments = []string{"controller", "grafana", "prometheus", "web"}
+
+	// installCapabilities are the resources created by `linkerd install`, each
+	// of which is checked by the pre-install checks. Namespaced resources are
+	// checked in the control plane namespace.
+	installCapabilities = []installCapability{
+		{kind: "Namespaces", resource: "namespaces"},
+		{kind: "ClusterRoles", group: "rbac.authorization.k8s.io", resource: "clusterroles"},
+		{kind: "ClusterRoleBindings", group: "rbac.authorization.k8s.io", resource: "clusterrolebindings"},
+		{kind: "CustomResourceDefinitions", group: "apiextensions.k8s.io", resource: "customresourcedefinitions"},
+		{kind: "ServiceAccounts", resource: "serviceaccounts", namespaced: true},
+		{kind: "Deployments", group: "extensions", resource: "deployments", namespaced: true},
+		{kind: "Services", resource: "services", namespaced: true},
+		{kind: "ConfigMaps", resource: "configmaps", namespaced: true},
+	}
+
+	// singleNamespaceInstallCapabilities are the resources created by
+	// `linkerd install --single-namespace`, all of them in the control plane
+	// namespace.
+	singleNamespaceInstallCapabilities = []installCapability{
+		{kind: "Roles", group: "rbac.authorization.k8s.io", resource: "roles", namespaced: true},
+		{kind: "RoleBindings", group: "rbac.authorization.k8s.io", resource: "rolebindings", namespaced: true},
+		{kind: "ServiceAccounts", resource: "serviceaccounts", namespaced: true},
+		{kind: "Deployments", group: "extensions", resource: "deployments", namespaced: true},
+		{kind: "Services", resource: "services", namespaced: true},
+		{kind: "ConfigMaps", resource: "configmaps", namespaced: true},
+	}
+
+	// pspInstallCapabilities are the additional resources created by
+	// `linkerd install --enable-psp`.
+	pspInstallCapabilities = []installCapability{
+		{kind: "PodSecurityPolicies", group: "policy", resource: "podsecuritypolicies"},
+		{kind: "Roles", group: "rbac.authorization.k8s.io", resource: "roles", namespaced: true},
+		{kind: "RoleBindings", group: "rbac.authorization.k8s.io", resource: "rolebindings", namespaced: true},
+	}
+
+	// minHighAvailabilityNodes is the number of schedulable nodes needed to
+	// run each of the replicas of a `linkerd install --ha` control plane on a
+	// separate node.
+	minHighAvailabilityNodes = 3
+
+	// maxReportedProxies bounds the number of pods listed by the data plane
+	// version check for each kind of problem it finds.
+	maxReportedProxies = 10
+
+	// maxPodsWithWarnings bounds the number of pods whose events are fetched
+	// when the pod checks fail.
+	maxPodsWithWarnings = 3
 )
 
+type installCapability struct {
+	kind       string
+	group      string
+	resource   string
+	namespaced bool
+}
+
 type checker struct {
-	category    string
+	category    CategoryID
 	description string
 	fatal       bool
-	retry       bool
-	check       func() error
-	checkRPC    func() (*healthcheckPb.SelfCheckResponse, error)
+
+	// retry is set for checks that can fail transiently, such as those
+	// waiting for pods to become ready, which are retried until the
+	// RetryDeadline, if any.
+	retry bool
+
+	warning  bool
+	check    func(context.Context) error
+	checkRPC func(context.Context) (*healthcheckPb.SelfCheckResponse, error)
+
+	// checkProgress, if set, is used instead of check by long-running checks,
+	// which describe their progress by calling progress, e.g. after each page
+	// of pods they go through.
+	checkProgress func(ctx context.Context, progress func(string)) error
+
+	// checkResults, if set, runs several checks at once, such as those of
+	// KubernetesAPI.SelfCheck. The checker fails if any of the results that
+	// isn't a warning has an error.
+	checkResults func(context.Context) []*CheckResult
+
+	// describe, if set, replaces description once check has succeeded, so
+	// that the result can include what the check found.
+	describe func() string
 }
 
 type CheckResult struct {
-	Category    string
+	Category    CategoryID
 	Description string
-	Retry       bool
-	Err         error
+	HintAnchor  string
+
+	// Retryable is set if the check can fail transiently, and Retry if it
+	// failed and is about to be retried. Attempt counts the attempts made so
+	// far, starting at 1.
+	Retryable bool
+	Retry     bool
+	Attempt   int
+
+	// Progress, if not empty, describes the progress of a long-running check
+	// that hasn't completed yet. Its final result is reported separately.
+	Progress string
+
+	// Fatal is set for the results of checks whose failure stops the checks
+	// that follow from running.
+	Fatal bool
+
+	Warning bool
+	Err     error
 }
 
 type checkObserver func(*CheckResult)
 
 type HealthCheckOptions struct {
-	ControlPlaneNamespace        string
-	DataPlaneNamespace           string
-	KubeConfig                   string
-	APIAddr                      string
-	VersionOverride              string
-	ShouldRetry                  bool
+	ControlPlaneNamespace string
+	DataPlaneNamespace    string
+	KubeConfig            string
+	KubeAPIOptions        *k8s.APIOptions
+	APIAddr               string
+	VersionOverride       string
+	// RetryDeadline is the time until which failing retryable checks are
+	// retried. The zero value disables retries.
+	RetryDeadline                time.Time
 	ShouldCheckKubeVersion       bool
 	ShouldCheckControllerVersion bool
+	// SingleNamespace selects the pre-install checks of
+	// `linkerd install --single-namespace`.
+	SingleNamespace bool
+	// EnablePSP selects the pre-install checks of
+	// `linkerd install --enable-psp`.
+	EnablePSP bool
 }
 
 type HealthChecker struct {
@@ -98,10 +247,18 @@ type HealthChecker struct {
 
 	// these fields are set in the process of running checks
 	kubeAPI       *k8s.KubernetesAPI
-	httpClient    *http.Client
 	kubeVersion   *k8sVersion.Info
+	kubeResults   []k8s.CheckResult
+	dataPlanePods []v1.Pod
+	openShift     bool
 	apiClient     pb.ApiClient
 	latestVersion string
+	versionSkew   *version.Skew
+
+	// selected and prerequisites are set by SelectCategories. If selected is
+	// nil, all of the checks run.
+	selected      map[CategoryID]bool
+	prerequisites map[CategoryID]bool
 }
 
 func NewHealthChecker(checks []Checks, options *HealthCheckOptions) *HealthChecker {
@@ -122,6 +279,16 @@ func NewHealthChecker(checks []Checks, options *HealthCheckOptions) *HealthCheck
 			hc.addLinkerdAPIChecks()
 		case LinkerdVersionChecks:
 			hc.addLinkerdVersionChecks()
+		case KubernetesNodeChecks:
+			hc.addKubernetesNodeChecks()
+		case LinkerdControlPlaneChecks:
+			hc.addLinkerdControlPlaneChecks()
+		case KubernetesClockChecks:
+			hc.addKubernetesClockChecks()
+		case KubernetesDistributionChecks:
+			hc.addKubernetesDistributionChecks()
+		case LinkerdProxyInjectorChecks:
+			hc.addLinkerdProxyInjectorChecks()
 		}
 	}
 
@@ -133,77 +300,303 @@ func (hc *HealthChecker) addKubernetesAPIChecks() {
 		category:    KubernetesAPICategory,
 		description: "can initialize the client",
 		fatal:       true,
-		check: func() (err error) {
-			hc.kubeAPI, err = k8s.NewAPI(hc.KubeConfig)
+		check: func(context.Context) (err error) {
+			hc.kubeAPI, err = k8s.NewAPI(hc.KubeConfig, hc.KubeAPIOptions)
 			return
 		},
 	})
 
 	hc.checkers = append(hc.checkers, &checker{
-		category:    KubernetesAPICategory,
-		description: "can query the Kubernetes API",
-		fatal:       true,
-		check: func() (err error) {
-			hc.httpClient, err = hc.kubeAPI.NewClient()
-			if err != nil {
-				return
+		category: KubernetesAPICategory,
+		fatal:    true,
+		checkResults: func(ctx context.Context) []*CheckResult {
+			hc.kubeResults = hc.kubeAPI.SelfCheck(ctx)
+			if hc.kubeResults[0].Err == nil {
+				// SelfCheck populated the cache, so this doesn't query the
+				// cluster again.
+				hc.kubeVersion, _ = hc.kubeAPI.GetVersionInfo(ctx)
 			}
-			hc.kubeVersion, err = hc.kubeAPI.GetVersionInfo(hc.httpClient)
-			return
+			return hc.kubeCheckResults(KubernetesAPICategory)
 		},
 	})
 
 	if hc.ShouldCheckKubeVersion {
 		hc.checkers = append(hc.checkers, &checker{
-			category:    KubernetesAPICategory,
-			description: "is running the minimum Kubernetes API version",
-			fatal:       false,
-			check: func() error {
-				return hc.kubeAPI.CheckVersion(hc.kubeVersion)
+			category: KubernetesVersionCategory,
+			fatal:    false,
+			checkResults: func(context.Context) []*CheckResult {
+				return hc.kubeCheckResults(KubernetesVersionCategory)
+			},
+		})
+
+		hc.checkers = append(hc.checkers, &checker{
+			category:    KubernetesVersionCategory,
+			description: "is running a tested Kubernetes API version",
+			warning:     true,
+			check: func(context.Context) error {
+				result, err := k8s.CheckVersion(hc.kubeVersion, k8s.MinAPIVersion, k8s.MaxTestedAPIVersion)
+				if err != nil {
+					return err
+				}
+				if result.Warning != "" {
+					return errors.New(result.Warning)
+				}
+				return nil
 			},
 		})
 	}
 }
 
-func (hc *HealthChecker) addLinkerdPreInstallChecks() {
+// kubeCheckResults returns the results of KubernetesAPI.SelfCheck in category.
+func (hc *HealthChecker) kubeCheckResults(category CategoryID) []*CheckResult {
+	results := make([]*CheckResult, 0)
+	for _, result := range hc.kubeResults {
+		if CategoryID(result.Category) != category {
+			continue
+		}
+		results = append(results, &CheckResult{
+			Category:    category,
+			Description: result.Description,
+			HintAnchor:  result.HintAnchor,
+			Retryable:   result.Retryable,
+			Warning:     result.Warning,
+			Err:         result.Err,
+		})
+	}
+	return results
+}
+
+func (hc *HealthChecker) addKubernetesClockChecks() {
 	hc.checkers = append(hc.checkers, &checker{
-		category:    LinkerdPreInstallCategory,
-		description: "control plane namespace does not already exist",
+		category: KubernetesClockCategory,
+		fatal:    false,
+		checkResults: func(context.Context) []*CheckResult {
+			return hc.kubeCheckResults(KubernetesClockCategory)
+		},
+	})
+}
+
+func (hc *HealthChecker) addKubernetesDistributionChecks() {
+	hc.checkers = append(hc.checkers, &checker{
+		category:    KubernetesDistributionCategory,
+		description: "can determine the Kubernetes distribution",
 		fatal:       false,
-		check: func() error {
-			exists, err := hc.kubeAPI.NamespaceExists(hc.httpClient, hc.ControlPlaneNamespace)
+		check: func(ctx context.Context) (err error) {
+			hc.openShift, err = hc.kubeAPI.IsOpenShift(ctx)
+			return
+		},
+		describe: func() string {
+			if hc.openShift {
+				return "is running on OpenShift"
+			}
+			return "is running on Kubernetes"
+		},
+	})
+}
+
+func (hc *HealthChecker) addKubernetesNodeChecks() {
+	hc.checkers = append(hc.checkers, &checker{
+		category:    KubernetesNodesCategory,
+		description: "nodes are compatible with the data plane",
+		warning:     true,
+		check: func(ctx context.Context) error {
+			nodes, err := hc.kubeAPI.ListNodes(ctx)
 			if err != nil {
 				return err
 			}
-			if exists {
-				return fmt.Errorf("The \"%s\" namespace already exists", hc.ControlPlaneNamespace)
-			}
-			return nil
+			return validateNodes(k8s.CheckNodeCompatibility(nodes))
 		},
 	})
 }
 
+func (hc *HealthChecker) addLinkerdPreInstallChecks() {
+	if hc.SingleNamespace {
+		hc.checkers = append(hc.checkers, &checker{
+			category:    LinkerdPreInstallCategory,
+			description: "control plane namespace exists",
+			fatal:       true,
+			check:       hc.checkNamespacePresent,
+		})
+	} else {
+		hc.checkers = append(hc.checkers, &checker{
+			category:    LinkerdPreInstallCategory,
+			description: "control plane namespace does not already exist",
+			warning:     true,
+			check:       hc.checkNamespaceAbsent,
+		})
+	}
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdPreInstallCategory,
+		description: "control plane is not already installed",
+		fatal:       false,
+		check:       hc.checkNoPreviousInstall,
+	})
+
+	if !hc.EnablePSP {
+		hc.checkers = append(hc.checkers, &checker{
+			category:    LinkerdPreInstallCategory,
+			description: "cluster does not enforce PodSecurityPolicies",
+			warning:     true,
+			check:       hc.checkPodSecurityPolicies,
+		})
+	}
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:     LinkerdPreInstallCapabilityCategory,
+		fatal:        false,
+		checkResults: hc.checkInstallCapabilities,
+	})
+}
+
+// checkNamespaceAbsent is the pre-install counterpart of checkNamespace: it
+// returns an error if the control plane namespace already exists. Installing
+// into an existing namespace works, so this is only a warning.
+func (hc *HealthChecker) checkNamespaceAbsent(ctx context.Context) error {
+	exists, err := hc.kubeAPI.NamespaceExists(ctx, hc.ControlPlaneNamespace)
+	if err != nil {
+		return namespaceError(hc.ControlPlaneNamespace, err)
+	}
+	if exists {
+		return fmt.Errorf("The \"%s\" namespace already exists; the install will reuse it", hc.ControlPlaneNamespace)
+	}
+	return nil
+}
+
+// checkNamespacePresent is the pre-install check of --single-namespace
+// installs, which do not create the control plane namespace.
+func (hc *HealthChecker) checkNamespacePresent(ctx context.Context) error {
+	exists, err := hc.kubeAPI.NamespaceExists(ctx, hc.ControlPlaneNamespace)
+	if err != nil {
+		return namespaceError(hc.ControlPlaneNamespace, err)
+	}
+	if !exists {
+		return fmt.Errorf("The \"%s\" namespace does not exist; --single-namespace installs require it to be created first", hc.ControlPlaneNamespace)
+	}
+	return nil
+}
+
+// checkNoPreviousInstall returns an error if the control plane namespace
+// contains control plane pods. Listing the pods of a namespace that doesn't
+// exist returns none.
+func (hc *HealthChecker) checkNoPreviousInstall(ctx context.Context) error {
+	pods, err := hc.kubeAPI.GetPodsFor(ctx, hc.ControlPlaneNamespace, k8s.ControllerComponentLabel)
+	if err != nil {
+		return err
+	}
+	if len(pods) > 0 {
+		return fmt.Errorf("The \"%s\" namespace already contains a control plane, e.g. pod \"%s\"; uninstall it, or pass another --linkerd-namespace", hc.ControlPlaneNamespace, pods[0].Name)
+	}
+	return nil
+}
+
+// checkPodSecurityPolicies returns an error if the cluster enforces
+// PodSecurityPolicies, which reject the control plane pods, and proxy-init in
+// particular, unless a policy allows them.
+func (hc *HealthChecker) checkPodSecurityPolicies(ctx context.Context) error {
+	enforced, err := hc.kubeAPI.PodSecurityPoliciesEnforced(ctx)
+	if err != nil {
+		return err
+	}
+	if enforced {
+		return fmt.Errorf("The cluster enforces PodSecurityPolicies, which may reject the control plane pods; install with --enable-psp to create a policy allowing them")
+	}
+	return nil
+}
+
+// checkInstallCapabilities returns a result for each of installCapabilities,
+// or singleNamespaceInstallCapabilities, and of pspInstallCapabilities with
+// EnablePSP, explaining why the caller is not allowed to create the resource,
+// if it isn't.
+func (hc *HealthChecker) checkInstallCapabilities(ctx context.Context) []*CheckResult {
+	capabilities := installCapabilities
+	if hc.SingleNamespace {
+		capabilities = singleNamespaceInstallCapabilities
+	}
+	if hc.EnablePSP {
+		capabilities = withCapabilities(capabilities, pspInstallCapabilities)
+	}
+
+	checks := make([]k8s.ResourceCheck, len(capabilities))
+	for i, capability := range capabilities {
+		checks[i] = k8s.ResourceCheck{Verb: "create", Group: capability.group, Resource: capability.resource}
+		if capability.namespaced {
+			checks[i].Namespace = hc.ControlPlaneNamespace
+		}
+	}
+
+	reviews, err := hc.kubeAPI.CanIAll(ctx, checks)
+	if err != nil {
+		return []*CheckResult{{
+			Category:    LinkerdPreInstallCapabilityCategory,
+			Description: "can check permissions",
+			Err:         err,
+		}}
+	}
+
+	results := make([]*CheckResult, len(reviews))
+	for i, review := range reviews {
+		results[i] = &CheckResult{
+			Category:    LinkerdPreInstallCapabilityCategory,
+			Description: fmt.Sprintf("can create %s", capabilities[i].kind),
+		}
+		if !review.Allowed {
+			results[i].Err = fmt.Errorf("Your account is not allowed to %s", review.ResourceCheck)
+			if review.Reason != "" {
+				results[i].Err = fmt.Errorf("Your account is not allowed to %s: %s", review.ResourceCheck, review.Reason)
+			}
+		}
+	}
+	return results
+}
+
+// withCapabilities returns a copy of capabilities, followed by those of extra
+// that it does not already include.
+func withCapabilities(capabilities, extra []installCapability) []installCapability {
+	combined := append([]installCapability{}, capabilities...)
+	for _, capability := range extra {
+		included := false
+		for _, c := range capabilities {
+			if c == capability {
+				included = true
+				break
+			}
+		}
+		if !included {
+			combined = append(combined, capability)
+		}
+	}
+	return combined
+}
+
 func (hc *HealthChecker) addLinkerdAPIChecks() {
 	hc.checkers = append(hc.checkers, &checker{
 		category:    LinkerdAPICategory,
 		description: "control plane namespace exists",
 		fatal:       true,
-		check: func() error {
-			return hc.checkNamespace(hc.ControlPlaneNamespace)
+		check: func(ctx context.Context) error {
+			return hc.checkNamespace(ctx, hc.ControlPlaneNamespace)
 		},
 	})
 
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdAPICategory,
+		description: "control plane namespace contains Linkerd",
+		fatal:       true,
+		check:       hc.checkControlPlaneInstalled,
+	})
+
 	hc.checkers = append(hc.checkers, &checker{
 		category:    LinkerdAPICategory,
 		description: "control plane pods are ready",
-		retry:       hc.ShouldRetry,
+		retry:       true,
 		fatal:       true,
-		check: func() error {
-			pods, err := hc.kubeAPI.GetPodsByNamespace(hc.httpClient, hc.ControlPlaneNamespace)
+		check: func(ctx context.Context) error {
+			pods, err := hc.kubeAPI.GetPodsByNamespace(ctx, hc.ControlPlaneNamespace)
 			if err != nil {
 				return err
 			}
-			return validateControlPlanePods(pods)
+			return hc.withPodWarnings(ctx, pods, validateControlPlanePods(pods))
 		},
 	})
 
@@ -211,7 +604,7 @@ func (hc *HealthChecker) addLinkerdAPIChecks() {
 		category:    LinkerdAPICategory,
 		description: "can initialize the client",
 		fatal:       true,
-		check: func() (err error) {
+		check: func(context.Context) (err error) {
 			if hc.APIAddr != "" {
 				hc.apiClient, err = public.NewInternalClient(hc.ControlPlaneNamespace, hc.APIAddr)
 			} else {
@@ -225,43 +618,298 @@ func (hc *HealthChecker) addLinkerdAPIChecks() {
 		category:    LinkerdAPICategory,
 		description: "can query the control plane API",
 		fatal:       true,
-		checkRPC: func() (*healthcheckPb.SelfCheckResponse, error) {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		checkRPC: func(ctx context.Context) (*healthcheckPb.SelfCheckResponse, error) {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			defer cancel()
 			return hc.apiClient.SelfCheck(ctx, &healthcheckPb.SelfCheckRequest{})
 		},
 	})
 }
 
+func (hc *HealthChecker) addLinkerdControlPlaneChecks() {
+	hc.checkers = append(hc.checkers, &checker{
+		category:     LinkerdControlPlaneCategory,
+		fatal:        false,
+		checkResults: hc.checkControlPlaneBindings,
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:     LinkerdControlPlaneCategory,
+		fatal:        false,
+		checkResults: hc.checkControlPlaneComponents,
+	})
+
+	for _, name := range controlPlaneDeployments {
+		name := name
+		hc.checkers = append(hc.checkers, &checker{
+			category:    LinkerdControlPlaneCategory,
+			description: fmt.Sprintf("%s deployment is healthy", name),
+			retry:       true,
+			fatal:       false,
+			check: func(ctx context.Context) error {
+				return hc.checkDeployment(ctx, name)
+			},
+		})
+	}
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdControlPlaneCategory,
+		description: "prometheus is ready",
+		retry:       true,
+		fatal:       false,
+		check: func(ctx context.Context) error {
+			_, err := hc.kubeAPI.GetServiceProxyResponse(ctx, hc.ControlPlaneNamespace, "prometheus", "admin-http", "", "/-/ready")
+			if err != nil {
+				return fmt.Errorf("prometheus is not ready: %s", err)
+			}
+			return nil
+		},
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:     LinkerdControlPlaneCategory,
+		fatal:        false,
+		checkResults: hc.checkHighAvailability,
+	})
+}
+
+func (hc *HealthChecker) addLinkerdProxyInjectorChecks() {
+	hc.checkers = append(hc.checkers, &checker{
+		category:     LinkerdProxyInjectorCategory,
+		fatal:        false,
+		checkResults: hc.checkProxyInjector,
+	})
+}
+
 func (hc *HealthChecker) addLinkerdDataPlaneChecks() {
 	if hc.DataPlaneNamespace != "" {
 		hc.checkers = append(hc.checkers, &checker{
 			category:    LinkerdDataPlaneCategory,
 			description: "data plane namespace exists",
 			fatal:       true,
-			check: func() error {
-				return hc.checkNamespace(hc.DataPlaneNamespace)
+			check: func(ctx context.Context) error {
+				return hc.checkNamespace(ctx, hc.DataPlaneNamespace)
 			},
 		})
 	}
 
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdDataPlaneCategory,
+		description: "data plane has meshed pods",
+		warning:     true,
+		check: func(ctx context.Context) error {
+			pods, err := hc.kubeAPI.GetPodsByControllerNamespace(
+				ctx,
+				hc.ControlPlaneNamespace,
+				hc.DataPlaneNamespace,
+			)
+			if err != nil {
+				return err
+			}
+			return validateMeshedPods(pods, hc.DataPlaneNamespace)
+		},
+	})
+
 	hc.checkers = append(hc.checkers, &checker{
 		category:    LinkerdDataPlaneCategory,
 		description: "data plane proxies are ready",
-		retry:       hc.ShouldRetry,
+		retry:       true,
 		fatal:       true,
-		check: func() error {
-			pods, err := hc.kubeAPI.GetPodsByControllerNamespace(
-				hc.httpClient,
+		check: func(ctx context.Context) (err error) {
+			hc.dataPlanePods, err = hc.kubeAPI.GetPodsByControllerNamespace(
+				ctx,
 				hc.ControlPlaneNamespace,
 				hc.DataPlaneNamespace,
 			)
 			if err != nil {
 				return err
 			}
-			return validateDataPlanePods(pods, hc.DataPlaneNamespace)
+			return hc.withPodWarnings(ctx, hc.dataPlanePods, validateDataPlanePods(hc.dataPlanePods))
 		},
 	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdDataPlaneCategory,
+		description: "data plane proxies respond to readiness requests",
+		retry:       true,
+		fatal:       false,
+		check: func(ctx context.Context) error {
+			return hc.checkProxyReadiness(ctx, hc.dataPlanePods)
+		},
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:    LinkerdDataPlaneCategory,
+		description: "control plane destination service is available",
+		retry:       true,
+		fatal:       false,
+		check:       hc.checkDestinationService,
+	})
+
+	hc.checkers = append(hc.checkers, &checker{
+		category:      LinkerdDataPlaneCategory,
+		description:   "data plane proxies are up-to-date",
+		warning:       true,
+		checkProgress: hc.checkDataPlaneVersions,
+	})
+}
+
+// checkProxyReadiness GETs the readiness endpoint of the proxy of each of
+// pods through the pod proxy, and fails unless all of them return 200.
+func (hc *HealthChecker) checkProxyReadiness(ctx context.Context, pods []v1.Pod) error {
+	var failed []string
+	for _, pod := range pods {
+		port, path := proxyReadinessEndpoint(pod)
+		if _, err := hc.kubeAPI.GetPodProxyResponse(ctx, pod.Namespace, pod.Name, port, path); err != nil {
+			failed = append(failed, fmt.Sprintf("%s/%s: %s", pod.Namespace, pod.Name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d proxies did not respond to readiness requests:\n%s",
+			len(failed), len(pods), listProxies(failed))
+	}
+	return nil
+}
+
+// proxyReadinessEndpoint returns the port and path of the proxy container's
+// HTTP readiness probe, defaulting to /ready on the linkerd-metrics port.
+func proxyReadinessEndpoint(pod v1.Pod) (string, string) {
+	port, path := "linkerd-metrics", "/ready"
+	for _, container := range pod.Spec.Containers {
+		if container.Name != "linkerd-proxy" || container.ReadinessProbe == nil || container.ReadinessProbe.HTTPGet == nil {
+			continue
+		}
+		probe := container.ReadinessProbe.HTTPGet
+		if probe.Port.String() != "" && probe.Port.String() != "0" {
+			port = probe.Port.String()
+		}
+		if probe.Path != "" {
+			path = probe.Path
+		}
+	}
+	return port, path
+}
+
+// checkDestinationService fails unless the proxy-api service, which the
+// proxies query for the destinations of their requests, has a ready endpoint.
+func (hc *HealthChecker) checkDestinationService(ctx context.Context) error {
+	addresses, err := hc.kubeAPI.GetServiceEndpointAddresses(ctx, hc.ControlPlaneNamespace, "proxy-api")
+	if err != nil {
+		return err
+	}
+	for _, address := range addresses {
+		if address.Ready {
+			return nil
+		}
+	}
+	return fmt.Errorf("The \"proxy-api\" service in the \"%s\" namespace has no ready endpoints, so proxies cannot discover destinations", hc.ControlPlaneNamespace)
+}
+
+// checkDataPlaneVersions goes through the meshed pods, page by page, and
+// fails if any of their proxies is not running the expected version, i.e. the
+// VersionOverride, if set, or the version of the CLI, or is not ready.
+func (hc *HealthChecker) checkDataPlaneVersions(ctx context.Context, progress func(string)) error {
+	expected := version.Version
+	if hc.VersionOverride != "" {
+		expected = hc.VersionOverride
+	}
+
+	proxies := &proxyVersions{expected: expected}
+	selector := fmt.Sprintf("%s=%s", k8s.ControllerNSLabel, hc.ControlPlaneNamespace)
+	err := hc.kubeAPI.VisitPods(ctx, hc.DataPlaneNamespace, selector, func(pods []v1.Pod) error {
+		for _, pod := range pods {
+			proxies.add(pod)
+		}
+		progress(fmt.Sprintf("checked %d pods", proxies.pods))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return proxies.err()
+}
+
+// proxyVersions collects the meshed pods whose proxy is stale or not ready.
+type proxyVersions struct {
+	expected string
+	pods     int
+	proxies  int
+	stale    []string
+	notReady []string
+}
+
+func (p *proxyVersions) add(pod v1.Pod) {
+	p.pods++
+	if isTerminatedPod(pod) {
+		return
+	}
+
+	var proxy *v1.Container
+	for i, container := range pod.Spec.Containers {
+		if container.Name == "linkerd-proxy" {
+			proxy = &pod.Spec.Containers[i]
+		}
+	}
+	if proxy == nil {
+		return
+	}
+	p.proxies++
+
+	name := pod.Namespace + "/" + pod.Name
+	if v := proxyVersion(pod, proxy.Image); v != "" && v != p.expected {
+		p.stale = append(p.stale, fmt.Sprintf("%s: %s", name, v))
+	}
+	for _, container := range pod.Status.ContainerStatuses {
+		if container.Name == "linkerd-proxy" && !container.Ready {
+			p.notReady = append(p.notReady, fmt.Sprintf("%s: %s", name, containerNotReadyReason(container)))
+		}
+	}
+}
+
+func (p *proxyVersions) err() error {
+	var problems []string
+	if len(p.stale) > 0 {
+		problems = append(problems, fmt.Sprintf("%d of %d proxies are not running version %s:\n%s",
+			len(p.stale), p.proxies, p.expected, listProxies(p.stale)))
+	}
+	if len(p.notReady) > 0 {
+		problems = append(problems, fmt.Sprintf("%d of %d proxies are not ready:\n%s",
+			len(p.notReady), p.proxies, listProxies(p.notReady)))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(problems, "\n"))
+}
+
+// listProxies formats the first maxReportedProxies of proxies, one per line.
+func listProxies(proxies []string) string {
+	lines := make([]string, 0, maxReportedProxies+1)
+	for i, proxy := range proxies {
+		if i == maxReportedProxies {
+			lines = append(lines, fmt.Sprintf("\t... and %d more", len(proxies)-maxReportedProxies))
+			break
+		}
+		lines = append(lines, "\t"+proxy)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// proxyVersion returns the version of the proxy of pod, from the tag of its
+// image, or, if the image is referenced by digest, from the annotation added
+// by `linkerd inject`. It returns an empty string if neither is available.
+func proxyVersion(pod v1.Pod, image string) string {
+	name := image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if strings.Contains(name, "@") {
+		return pod.Annotations[k8s.ProxyVersionAnnotation]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return pod.Annotations[k8s.ProxyVersionAnnotation]
 }
 
 func (hc *HealthChecker) addLinkerdVersionChecks() {
@@ -269,7 +917,7 @@ func (hc *HealthChecker) addLinkerdVersionChecks() {
 		category:    LinkerdVersionCategory,
 		description: "can determine the latest version",
 		fatal:       true,
-		check: func() (err error) {
+		check: func(context.Context) (err error) {
 			if hc.VersionOverride != "" {
 				hc.latestVersion = hc.VersionOverride
 			} else {
@@ -283,7 +931,7 @@ func (hc *HealthChecker) addLinkerdVersionChecks() {
 		category:    LinkerdVersionCategory,
 		description: "cli is up-to-date",
 		fatal:       false,
-		check: func() error {
+		check: func(context.Context) error {
 			return version.CheckClientVersion(hc.latestVersion)
 		},
 	})
@@ -293,34 +941,73 @@ func (hc *HealthChecker) addLinkerdVersionChecks() {
 			category:    LinkerdVersionCategory,
 			description: "control plane is up-to-date",
 			fatal:       false,
-			check: func() error {
+			check: func(context.Context) error {
 				return version.CheckServerVersion(hc.apiClient, hc.latestVersion)
 			},
 		})
+
+		hc.checkers = append(hc.checkers, &checker{
+			category:    LinkerdVersionCategory,
+			description: "cli and control plane versions are compatible",
+			fatal:       false,
+			check: func(ctx context.Context) (err error) {
+				hc.versionSkew, err = version.GetServerSkew(ctx, hc.apiClient)
+				if err != nil {
+					return
+				}
+				return hc.versionSkew.Err()
+			},
+		})
+
+		hc.checkers = append(hc.checkers, &checker{
+			category:    LinkerdVersionCategory,
+			description: "cli and control plane are on the same minor version",
+			warning:     true,
+			check: func(context.Context) error {
+				if hc.versionSkew == nil {
+					return nil
+				}
+				if warning := hc.versionSkew.Warning(); warning != "" {
+					return errors.New(warning)
+				}
+				return nil
+			},
+		})
 	}
 }
 
 // Add adds an arbitrary checker. This should only be used for testing. For
 // production code, pass in the desired set of checks when calling
 // NewHeathChecker.
-func (hc *HealthChecker) Add(category, description string, check func() error) {
+func (hc *HealthChecker) Add(category CategoryID, description string, check func() error) {
 	hc.checkers = append(hc.checkers, &checker{
 		category:    category,
 		description: description,
-		check:       check,
+		check: func(context.Context) error {
+			return check()
+		},
 	})
 }
 
 // RunChecks runs all configured checkers, and passes the results of each
 // check to the observer. If a check fails and is marked as fatal, then all
 // remaining checks are skipped. If at least one check fails, RunChecks returns
-// false; if all checks passed, RunChecks returns true.
-func (hc *HealthChecker) RunChecks(observer checkObserver) bool {
+// false; if all checks passed, RunChecks returns true. Checks that only warn
+// about a problem are reported with Warning set, and don't cause RunChecks to
+// return false. If SelectCategories was called, only the selected checks run.
+// Requests made by the checks are bound to ctx, and cancelling it aborts any
+// in-flight checks.
+func (hc *HealthChecker) RunChecks(ctx context.Context, observer checkObserver) bool {
 	success := true
 
 	for _, checker := range hc.checkers {
-		if checker.check != nil {
-			if !hc.runCheck(checker, observer) {
+		observer := hc.observerFor(checker.category, observer)
+		if observer == nil {
+			continue
+		}
+
+		if checker.check != nil || checker.checkProgress != nil {
+			if !hc.runCheck(ctx, checker, observer) {
 				success = false
 				if checker.fatal {
 					break
@@ -329,7 +1016,16 @@ func (hc *HealthChecker) RunChecks(observer checkObserver) bool {
 		}
 
 		if checker.checkRPC != nil {
-			if !hc.runCheckRPC(checker, observer) {
+			if !hc.runCheckRPC(ctx, checker, observer) {
+				success = false
+				if checker.fatal {
+					break
+				}
+			}
+		}
+
+		if checker.checkResults != nil {
+			if !hc.runCheckResults(ctx, checker, observer) {
 				success = false
 				if checker.fatal {
 					break
@@ -341,38 +1037,142 @@ func (hc *HealthChecker) RunChecks(observer checkObserver) bool {
 	return success
 }
 
-func (hc *HealthChecker) runCheck(c *checker, observer checkObserver) bool {
-	var retries int
-	if c.retry {
-		retries = maxRetries
-	}
-
-	for {
-		err := c.check()
+func (hc *HealthChecker) runCheck(ctx context.Context, c *checker, observer checkObserver) bool {
+	for attempt := 1; ; attempt++ {
+		err := hc.callCheck(ctx, c, attempt, observer)
+		description := c.description
+		if err == nil && c.describe != nil {
+			description = c.describe()
+		}
 		checkResult := &CheckResult{
 			Category:    c.category,
-			Description: c.description,
+			Description: description,
+			Retryable:   c.retry,
+			Attempt:     attempt,
+			Fatal:       c.fatal,
+			Warning:     c.warning,
 			Err:         err,
 		}
 
-		if err != nil && retries > 0 {
-			retries--
+		if err != nil && c.retry && hc.canRetry() {
 			checkResult.Retry = true
 			observer(checkResult)
-			time.Sleep(retryWindow)
+			if err := hc.waitToRetry(ctx, attempt); err != nil {
+				observer(&CheckResult{
+					Category:    c.category,
+					Description: c.description,
+					Retryable:   c.retry,
+					Attempt:     attempt,
+					Fatal:       c.fatal,
+					Err:         err,
+				})
+				return false
+			}
 			continue
 		}
 
 		observer(checkResult)
-		return err == nil
+		return err == nil || c.warning
+	}
+}
+
+// callCheck runs the check of c, passing the progress reported by
+// checkProgress checks to the observer.
+func (hc *HealthChecker) callCheck(ctx context.Context, c *checker, attempt int, observer checkObserver) error {
+	if c.checkProgress == nil {
+		return c.check(ctx)
 	}
+	return c.checkProgress(ctx, func(progress string) {
+		observer(&CheckResult{
+			Category:    c.category,
+			Description: c.description,
+			Retryable:   c.retry,
+			Attempt:     attempt,
+			Warning:     c.warning,
+			Progress:    progress,
+		})
+	})
 }
 
-func (hc *HealthChecker) runCheckRPC(c *checker, observer checkObserver) bool {
-	checkRsp, err := c.checkRPC()
+// canRetry reports whether the RetryDeadline has not passed yet.
+func (hc *HealthChecker) canRetry() bool {
+	return hc.HealthCheckOptions != nil && time.Now().Before(hc.RetryDeadline)
+}
+
+// waitToRetry waits before the retry following attempt, backing off
+// exponentially, but not past the RetryDeadline.
+func (hc *HealthChecker) waitToRetry(ctx context.Context, attempt int) error {
+	delay := retryWindow
+	if attempt < 32 && retryBaseDelay<<uint(attempt-1) < retryWindow {
+		delay = retryBaseDelay << uint(attempt-1)
+	}
+	if remaining := time.Until(hc.RetryDeadline); remaining < delay {
+		delay = remaining
+	}
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runCheckResults runs a checker with several results. If any of the failed
+// results is retryable, only those are reported, and all of the checks are
+// retried.
+func (hc *HealthChecker) runCheckResults(ctx context.Context, c *checker, observer checkObserver) bool {
+	for attempt := 1; ; attempt++ {
+		results := c.checkResults(ctx)
+
+		var retryable []*CheckResult
+		for _, result := range results {
+			result.Attempt = attempt
+			result.Fatal = c.fatal && !result.Warning
+			if result.Err != nil && result.Retryable {
+				retryable = append(retryable, result)
+			}
+		}
+
+		if len(retryable) > 0 && hc.canRetry() {
+			for _, result := range retryable {
+				result.Retry = true
+				observer(result)
+			}
+			if err := hc.waitToRetry(ctx, attempt); err != nil {
+				for _, result := range retryable {
+					observer(&CheckResult{
+						Category:    result.Category,
+						Description: result.Description,
+						HintAnchor:  result.HintAnchor,
+						Retryable:   true,
+						Attempt:     attempt,
+						Fatal:       result.Fatal,
+						Err:         err,
+					})
+				}
+				return false
+			}
+			continue
+		}
+
+		success := true
+		for _, result := range results {
+			observer(result)
+			if result.Err != nil && !result.Warning {
+				success = false
+			}
+		}
+		return success
+	}
+}
+
+func (hc *HealthChecker) runCheckRPC(ctx context.Context, c *checker, observer checkObserver) bool {
+	checkRsp, err := c.checkRPC(ctx)
 	observer(&CheckResult{
 		Category:    c.category,
 		Description: c.description,
+		Fatal:       c.fatal,
 		Err:         err,
 	})
 	if err != nil {
@@ -385,8 +1185,9 @@ func (hc *HealthChecker) runCheckRPC(c *checker, observer checkObserver) bool {
 			err = fmt.Errorf(check.FriendlyMessageToUser)
 		}
 		observer(&CheckResult{
-			Category:    fmt.Sprintf("%s[%s]", c.category, check.SubsystemName),
+			Category:    CategoryID(fmt.Sprintf("%s[%s]", c.category, check.SubsystemName)),
 			Description: check.CheckDescription,
+			Fatal:       c.fatal,
 			Err:         err,
 		})
 		if err != nil {
@@ -404,10 +1205,10 @@ func (hc *HealthChecker) PublicAPIClient() pb.ApiClient {
 	return hc.apiClient
 }
 
-func (hc *HealthChecker) checkNamespace(namespace string) error {
-	exists, err := hc.kubeAPI.NamespaceExists(hc.httpClient, namespace)
+func (hc *HealthChecker) checkNamespace(ctx context.Context, namespace string) error {
+	exists, err := hc.kubeAPI.NamespaceExists(ctx, namespace)
 	if err != nil {
-		return err
+		return namespaceError(namespace, err)
 	}
 	if !exists {
 		return fmt.Errorf("The \"%s\" namespace does not exist", namespace)
@@ -415,6 +1216,409 @@ func (hc *HealthChecker) checkNamespace(namespace string) error {
 	return nil
 }
 
+// checkControlPlaneInstalled looks for the labelled deployments of the control
+// plane, so that a namespace that merely exists, e.g. when --linkerd-namespace
+// is mistyped as "default", is not mistaken for the one Linkerd was installed
+// in.
+func (hc *HealthChecker) checkControlPlaneInstalled(ctx context.Context) error {
+	deployments, err := hc.kubeAPI.ListDeployments(ctx, hc.ControlPlaneNamespace, k8s.ControllerComponentLabel)
+	if err != nil {
+		return err
+	}
+	if len(deployments) == 0 {
+		return fmt.Errorf("The \"%s\" namespace does not contain a Linkerd control plane; pass the namespace Linkerd was installed in with --linkerd-namespace", hc.ControlPlaneNamespace)
+	}
+	return nil
+}
+
+func (hc *HealthChecker) checkDeployment(ctx context.Context, name string) error {
+	status, err := hc.kubeAPI.GetDeploymentStatus(ctx, hc.ControlPlaneNamespace, name)
+	if k8s.IsNotFound(err) {
+		return fmt.Errorf("The \"%s\" deployment does not exist in the \"%s\" namespace", name, hc.ControlPlaneNamespace)
+	}
+	if err != nil {
+		return err
+	}
+	if !status.Healthy() {
+		return fmt.Errorf("The \"%s\" deployment is not healthy: %s", name, status.Reason)
+	}
+	return nil
+}
+
+// namespaceError adds guidance to errors caused by the caller not being
+// authorized to get a namespace.
+func namespaceError(namespace string, err error) error {
+	if k8s.IsForbidden(err) {
+		return fmt.Errorf("Your account cannot get the \"%s\" namespace; grant it permission to get namespaces, or pass a --linkerd-namespace it can read: %s", namespace, err)
+	}
+	return err
+}
+
+// withPodWarnings appends the most recent Warning events of the pods that are
+// not ready to err, since they usually explain why, e.g. FailedScheduling or
+// FailedMount. Only the first maxPodsWithWarnings such pods are included.
+func (hc *HealthChecker) withPodWarnings(ctx context.Context, pods []v1.Pod, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var warnings []string
+	for _, pod := range pods {
+		if len(warnings) == maxPodsWithWarnings {
+			break
+		}
+		if pod.Status.Phase == v1.PodRunning && k8s.IsPodReady(pod) {
+			continue
+		}
+
+		events, eventsErr := hc.kubeAPI.GetWarningEventsFor(ctx, pod.Namespace, "Pod", pod.Name)
+		if eventsErr != nil || len(events) == 0 {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("Recent warnings for the \"%s\" pod in the \"%s\" namespace:\n%s",
+			pod.Name, pod.Namespace, k8s.FormatEvents(events)))
+	}
+
+	if len(warnings) == 0 {
+		return err
+	}
+	return fmt.Errorf("%s\n%s", err, strings.Join(warnings, "\n"))
+}
+
+// checkControlPlaneComponents returns a result for each of the
+// controlPlaneDeployments, describing the readiness and restarts of the
+// containers of its pods, and, if any of them is not ready, why.
+func (hc *HealthChecker) checkControlPlaneComponents(ctx context.Context) []*CheckResult {
+	pods, err := hc.kubeAPI.GetPodsFor(ctx, hc.ControlPlaneNamespace, k8s.ControllerComponentLabel)
+	if err != nil {
+		return []*CheckResult{{
+			Category:    LinkerdControlPlaneCategory,
+			Description: "can list control plane pods",
+			Retryable:   true,
+			Err:         err,
+		}}
+	}
+
+	byComponent := make(map[string][]v1.Pod)
+	for _, pod := range pods {
+		if isTerminatedPod(pod) {
+			continue
+		}
+		component := pod.Labels[k8s.ControllerComponentLabel]
+		byComponent[component] = append(byComponent[component], pod)
+	}
+
+	results := make([]*CheckResult, len(controlPlaneDeployments))
+	for i, name := range controlPlaneDeployments {
+		status := newComponentStatus(byComponent[name])
+		results[i] = &CheckResult{
+			Category:    LinkerdControlPlaneCategory,
+			Description: status.describe(name),
+			Retryable:   true,
+			Err:         status.err(name),
+		}
+		if results[i].Err != nil && status.notReady != nil {
+			results[i].Err = hc.withLatestWarning(ctx, *status.notReady, results[i].Err)
+		}
+	}
+	return results
+}
+
+// isTerminatedPod reports whether pod has run to completion or was evicted,
+// in which case it is replaced by another pod rather than becoming ready.
+func isTerminatedPod(pod v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
+
+// componentStatus summarizes the containers of the pods of a control plane
+// component.
+type componentStatus struct {
+	pods            int
+	containers      int
+	readyContainers int
+	restarts        int32
+
+	// notReady is the first pod that is not ready, if any, and reason
+	// explains why.
+	notReady *v1.Pod
+	reason   string
+}
+
+func newComponentStatus(pods []v1.Pod) *componentStatus {
+	status := &componentStatus{pods: len(pods)}
+	for i, pod := range pods {
+		for _, container := range pod.Status.ContainerStatuses {
+			status.containers++
+			status.restarts += container.RestartCount
+			if container.Ready {
+				status.readyContainers++
+				continue
+			}
+			if status.notReady == nil {
+				status.notReady = &pods[i]
+				status.reason = containerNotReadyReason(container)
+			}
+		}
+
+		if status.notReady == nil && (pod.Status.Phase != v1.PodRunning || !k8s.IsPodReady(pod)) {
+			status.notReady = &pods[i]
+			status.reason = fmt.Sprintf("is %s", pod.Status.Phase)
+		}
+	}
+	return status
+}
+
+func (s *componentStatus) describe(name string) string {
+	return fmt.Sprintf("%s: %d/%d containers ready, %d restarts", name, s.readyContainers, s.containers, s.restarts)
+}
+
+func (s *componentStatus) err(name string) error {
+	if s.pods == 0 {
+		return fmt.Errorf("No running pods for \"%s\"", name)
+	}
+	if s.notReady != nil {
+		return fmt.Errorf("The \"%s\" pod %s", s.notReady.Name, s.reason)
+	}
+	return nil
+}
+
+// containerNotReadyReason explains why container is not ready, using the
+// reason and message of its waiting or terminated state, e.g.
+// CrashLoopBackOff or ImagePullBackOff.
+func containerNotReadyReason(container v1.ContainerStatus) string {
+	state := container.State
+	switch {
+	case state.Waiting != nil && state.Waiting.Message != "":
+		return fmt.Sprintf("has a waiting \"%s\" container: %s: %s", container.Name, state.Waiting.Reason, state.Waiting.Message)
+	case state.Waiting != nil:
+		return fmt.Sprintf("has a waiting \"%s\" container: %s", container.Name, state.Waiting.Reason)
+	case state.Terminated != nil:
+		return fmt.Sprintf("has a terminated \"%s\" container: %s (exit code %d)", container.Name, state.Terminated.Reason, state.Terminated.ExitCode)
+	}
+	return fmt.Sprintf("has a \"%s\" container that is not ready", container.Name)
+}
+
+// withLatestWarning appends the most recent Warning event of pod to err, if
+// there is one.
+func (hc *HealthChecker) withLatestWarning(ctx context.Context, pod v1.Pod, err error) error {
+	events, eventsErr := hc.kubeAPI.GetWarningEventsFor(ctx, pod.Namespace, "Pod", pod.Name)
+	if eventsErr != nil || len(events) == 0 {
+		return err
+	}
+	return fmt.Errorf("%s\nMost recent warning for the \"%s\" pod:\n%s", err, pod.Name, k8s.FormatEvents(events[:1]))
+}
+
+// checkControlPlaneBindings validates the bindings of the controller and
+// Prometheus service accounts: RoleBindings if the control plane was installed
+// with --single-namespace, and ClusterRoleBindings otherwise.
+func (hc *HealthChecker) checkControlPlaneBindings(ctx context.Context) []*CheckResult {
+	result := &CheckResult{
+		Category:    LinkerdControlPlaneCategory,
+		Description: "control plane ClusterRoleBindings are valid",
+	}
+
+	options, err := hc.kubeAPI.GetInstallOptions(ctx, hc.ControlPlaneNamespace)
+	if err != nil && !k8s.IsNotFound(err) {
+		result.Err = err
+		return []*CheckResult{result}
+	}
+	singleNamespace := false
+	if err == nil {
+		value, _ := options.Flag("single-namespace")
+		singleNamespace = value == "true"
+	}
+	if singleNamespace {
+		result.Description = "control plane RoleBindings are valid"
+	}
+
+	for _, component := range []string{"controller", "prometheus"} {
+		serviceAccount := "linkerd-" + component
+		if singleNamespace {
+			result.Err = hc.kubeAPI.ValidateRoleBinding(ctx, hc.ControlPlaneNamespace, serviceAccount, serviceAccount)
+		} else {
+			name := fmt.Sprintf("linkerd-%s-%s", hc.ControlPlaneNamespace, component)
+			result.Err = hc.kubeAPI.ValidateClusterRoleBinding(ctx, name, hc.ControlPlaneNamespace, serviceAccount)
+		}
+		if result.Err != nil {
+			break
+		}
+	}
+	return []*CheckResult{result}
+}
+
+// checkHighAvailability warns if the control plane was installed with --ha,
+// but its replicas cannot all be scheduled on separate nodes. It returns no
+// results for other installs, including those predating the install
+// ConfigMap.
+func (hc *HealthChecker) checkHighAvailability(ctx context.Context) []*CheckResult {
+	result := &CheckResult{
+		Category:    LinkerdControlPlaneCategory,
+		Description: "cluster has enough nodes for high availability",
+		Warning:     true,
+	}
+
+	options, err := hc.kubeAPI.GetInstallOptions(ctx, hc.ControlPlaneNamespace)
+	if k8s.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		result.Err = err
+		return []*CheckResult{result}
+	}
+	if ha, _ := options.Flag("ha"); ha != "true" {
+		return nil
+	}
+
+	nodes, err := hc.kubeAPI.ListNodes(ctx)
+	if err != nil {
+		result.Err = err
+		return []*CheckResult{result}
+	}
+	result.Err = validateHighAvailabilityNodes(nodes)
+	return []*CheckResult{result}
+}
+
+// checkProxyInjector checks the proxy injector of control planes installed
+// with --proxy-auto-inject: that the API server is configured to call it, that
+// the certificate it serves is valid and trusted by that configuration, and
+// that its Service has endpoints for the API server to call. It returns no
+// results for other installs.
+func (hc *HealthChecker) checkProxyInjector(ctx context.Context) []*CheckResult {
+	options, err := hc.kubeAPI.GetInstallOptions(ctx, hc.ControlPlaneNamespace)
+	if k8s.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return []*CheckResult{{
+			Category:    LinkerdProxyInjectorCategory,
+			Description: "can read the install options",
+			Err:         err,
+		}}
+	}
+	if autoInject, _ := options.Flag("proxy-auto-inject"); autoInject != "true" {
+		return nil
+	}
+
+	webhookResult := &CheckResult{
+		Category:    LinkerdProxyInjectorCategory,
+		Description: "proxy injector webhook is configured",
+	}
+	certificateResult := &CheckResult{
+		Category:    LinkerdProxyInjectorCategory,
+		Description: "proxy injector certificate is valid",
+	}
+	endpointsResult := &CheckResult{
+		Category:    LinkerdProxyInjectorCategory,
+		Description: "proxy injector service has endpoints",
+		Retryable:   true,
+	}
+	results := []*CheckResult{webhookResult, certificateResult, endpointsResult}
+
+	var caBundle []byte
+	caBundle, webhookResult.Err = hc.proxyInjectorCABundle(ctx)
+	if webhookResult.Err != nil {
+		certificateResult.Err = errors.New("cannot verify the certificate without the webhook configuration")
+	} else {
+		certificateResult.Err = hc.checkProxyInjectorCertificate(ctx, caBundle)
+	}
+
+	addresses, err := hc.kubeAPI.GetServiceEndpointAddresses(ctx, hc.ControlPlaneNamespace, k8s.ProxyInjectorServiceName)
+	if err != nil {
+		endpointsResult.Err = err
+		return results
+	}
+	ready := 0
+	for _, address := range addresses {
+		if address.Ready {
+			ready++
+		}
+	}
+	if ready == 0 {
+		endpointsResult.Err = fmt.Errorf("The \"%s\" service has no ready endpoints, so pods are created without the proxy or rejected, depending on the failure policy of the webhook", k8s.ProxyInjectorServiceName)
+	}
+	return results
+}
+
+// proxyInjectorCABundle returns the CA bundle the API server verifies the
+// proxy injector with, after checking that its webhook configuration calls
+// the proxy injector Service of the control plane.
+func (hc *HealthChecker) proxyInjectorCABundle(ctx context.Context) ([]byte, error) {
+	name := k8s.ProxyInjectorWebhookConfigName(hc.ControlPlaneNamespace)
+	config, err := hc.kubeAPI.GetMutatingWebhookConfiguration(ctx, name)
+	if k8s.IsNotFound(err) {
+		return nil, fmt.Errorf("The \"%s\" MutatingWebhookConfiguration does not exist, so pods are not injected", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Webhooks) != 1 {
+		return nil, fmt.Errorf("The \"%s\" MutatingWebhookConfiguration has %d webhooks, expected 1", name, len(config.Webhooks))
+	}
+	clientConfig := config.Webhooks[0].ClientConfig
+	if service := clientConfig.Service; service == nil || service.Namespace != hc.ControlPlaneNamespace || service.Name != k8s.ProxyInjectorServiceName {
+		return nil, fmt.Errorf("The \"%s\" MutatingWebhookConfiguration does not call the \"%s/%s\" service", name, hc.ControlPlaneNamespace, k8s.ProxyInjectorServiceName)
+	}
+	if len(clientConfig.CABundle) == 0 {
+		return nil, fmt.Errorf("The \"%s\" MutatingWebhookConfiguration has no caBundle", name)
+	}
+	return clientConfig.CABundle, nil
+}
+
+// checkProxyInjectorCertificate returns an error if the certificate of the
+// proxy injector has expired, or is not trusted by caBundle for the DNS name
+// of its Service.
+func (hc *HealthChecker) checkProxyInjectorCertificate(ctx context.Context, caBundle []byte) error {
+	secret, err := hc.kubeAPI.GetTLSSecret(ctx, hc.ControlPlaneNamespace, k8s.ProxyInjectorTLSSecretName)
+	if err != nil {
+		return err
+	}
+	if err := secret.CheckValidity(time.Now()); err != nil {
+		return err
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caBundle) {
+		return errors.New("The caBundle of the proxy injector webhook holds no PEM certificates")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range secret.Chain {
+		intermediates.AddCert(cert)
+	}
+	dnsName := fmt.Sprintf("%s.%s.svc", k8s.ProxyInjectorServiceName, hc.ControlPlaneNamespace)
+	if _, err := secret.Certificate.Verify(x509.VerifyOptions{DNSName: dnsName, Roots: roots, Intermediates: intermediates}); err != nil {
+		return fmt.Errorf("The certificate of the proxy injector is not trusted by its webhook configuration for %s: %v", dnsName, err)
+	}
+	return nil
+}
+
+// validateHighAvailabilityNodes returns an error if fewer than
+// minHighAvailabilityNodes of nodes are schedulable.
+func validateHighAvailabilityNodes(nodes []v1.Node) error {
+	schedulable := 0
+	for _, node := range nodes {
+		if k8s.IsNodeSchedulable(node) {
+			schedulable++
+		}
+	}
+	if schedulable < minHighAvailabilityNodes {
+		return fmt.Errorf("The control plane was installed with --ha, which runs %d replicas of each component on separate nodes, but only %d of %d nodes are schedulable", minHighAvailabilityNodes, schedulable, len(nodes))
+	}
+	return nil
+}
+
+func validateNodes(nodes []k8s.NodeCompatibility) error {
+	var problems []string
+	for _, node := range nodes {
+		if !node.Compatible() {
+			problems = append(problems, fmt.Sprintf("\t%s: %s", node.Name, strings.Join(node.Problems, ", ")))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("Linkerd proxies cannot run on %d of %d nodes:\n%s", len(problems), len(nodes), strings.Join(problems, "\n"))
+}
+
 func validateControlPlanePods(pods []v1.Pod) error {
 	statuses := make(map[string][]v1.ContainerStatus)
 
@@ -449,7 +1653,7 @@ func validateControlPlanePods(pods []v1.Pod) error {
 	return nil
 }
 
-func validateDataPlanePods(pods []v1.Pod, targetNamespace string) error {
+func validateMeshedPods(pods []v1.Pod, targetNamespace string) error {
 	if len(pods) == 0 {
 		msg := "No \"linkerd-proxy\" containers found"
 		if targetNamespace != "" {
@@ -457,7 +1661,10 @@ func validateDataPlanePods(pods []v1.Pod, targetNamespace string) error {
 		}
 		return fmt.Errorf(msg)
 	}
+	return nil
+}
 
+func validateDataPlanePods(pods []v1.Pod) error {
 	for _, pod := range pods {
 		if pod.Status.Phase != v1.PodRunning {
 			return fmt.Errorf("The \"%s\" pod in the \"%s\" namespace is not running",
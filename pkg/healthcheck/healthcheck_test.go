@@ -2,15 +2,26 @@ package healthcheck
 
 import (
 	"context"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/linkerd/linkerd2/controller/api/public"
+	"github.com/linkerd/linkerd2/controller/ca"
 	healthcheckPb "github.com/linkerd/linkerd2/controller/gen/common/healthcheck"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	admissionregistrationV1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	"k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
 )
 
 func TestHealthChecker(t *testing.T) {
@@ -19,7 +30,7 @@ func TestHealthChecker(t *testing.T) {
 	passingCheck1 := &checker{
 		category:    "cat1",
 		description: "desc1",
-		check: func() error {
+		check: func(context.Context) error {
 			return nil
 		},
 	}
@@ -27,7 +38,7 @@ func TestHealthChecker(t *testing.T) {
 	passingCheck2 := &checker{
 		category:    "cat2",
 		description: "desc2",
-		check: func() error {
+		check: func(context.Context) error {
 			return nil
 		},
 	}
@@ -35,7 +46,7 @@ func TestHealthChecker(t *testing.T) {
 	failingCheck := &checker{
 		category:    "cat3",
 		description: "desc3",
-		check: func() error {
+		check: func(context.Context) error {
 			return fmt.Errorf("error")
 		},
 	}
@@ -55,7 +66,7 @@ func TestHealthChecker(t *testing.T) {
 	passingRPCCheck := &checker{
 		category:    "cat4",
 		description: "desc4",
-		checkRPC: func() (*healthcheckPb.SelfCheckResponse, error) {
+		checkRPC: func(context.Context) (*healthcheckPb.SelfCheckResponse, error) {
 			return passingRPCClient.SelfCheck(context.Background(),
 				&healthcheckPb.SelfCheckRequest{})
 		},
@@ -77,7 +88,7 @@ func TestHealthChecker(t *testing.T) {
 	failingRPCCheck := &checker{
 		category:    "cat5",
 		description: "desc5",
-		checkRPC: func() (*healthcheckPb.SelfCheckResponse, error) {
+		checkRPC: func(context.Context) (*healthcheckPb.SelfCheckResponse, error) {
 			return failingRPCClient.SelfCheck(context.Background(),
 				&healthcheckPb.SelfCheckRequest{})
 		},
@@ -87,7 +98,7 @@ func TestHealthChecker(t *testing.T) {
 		category:    "cat6",
 		description: "desc6",
 		fatal:       true,
-		check: func() error {
+		check: func(context.Context) error {
 			return fmt.Errorf("fatal")
 		},
 	}
@@ -122,7 +133,7 @@ func TestHealthChecker(t *testing.T) {
 			"cat5[rpc2] rpc desc2: rpc error",
 		}
 
-		hc.RunChecks(observer)
+		hc.RunChecks(context.Background(), observer)
 
 		if !reflect.DeepEqual(observedResults, expectedResults) {
 			t.Fatalf("Expected results %v, but got %v", expectedResults, observedResults)
@@ -138,7 +149,7 @@ func TestHealthChecker(t *testing.T) {
 			},
 		}
 
-		success := hc.RunChecks(nullObserver)
+		success := hc.RunChecks(context.Background(), nullObserver)
 
 		if !success {
 			t.Fatalf("Expecting checks to be successful, but got [%t]", success)
@@ -154,7 +165,7 @@ func TestHealthChecker(t *testing.T) {
 			},
 		}
 
-		success := hc.RunChecks(nullObserver)
+		success := hc.RunChecks(context.Background(), nullObserver)
 
 		if success {
 			t.Fatalf("Expecting checks to not be successful, but got [%t]", success)
@@ -170,13 +181,40 @@ func TestHealthChecker(t *testing.T) {
 			},
 		}
 
-		success := hc.RunChecks(nullObserver)
+		success := hc.RunChecks(context.Background(), nullObserver)
 
 		if success {
 			t.Fatalf("Expecting checks to not be successful, but got [%t]", success)
 		}
 	})
 
+	t.Run("Marks the results of fatal checks as fatal", func(t *testing.T) {
+		hc := HealthChecker{
+			checkers: []*checker{
+				passingCheck1,
+				failingCheck,
+				fatalCheck,
+			},
+		}
+
+		observedResults := make([]string, 0)
+		observer := func(result *CheckResult) {
+			observedResults = append(observedResults, fmt.Sprintf("%s %s fatal=%t", result.Category, result.Description, result.Fatal))
+		}
+
+		expectedResults := []string{
+			"cat1 desc1 fatal=false",
+			"cat3 desc3 fatal=false",
+			"cat6 desc6 fatal=true",
+		}
+
+		hc.RunChecks(context.Background(), observer)
+
+		if !reflect.DeepEqual(observedResults, expectedResults) {
+			t.Fatalf("Expected results %v, but got %v", expectedResults, observedResults)
+		}
+	})
+
 	t.Run("Does not run remaining check if fatal check fails", func(t *testing.T) {
 		hc := HealthChecker{
 			checkers: []*checker{
@@ -200,24 +238,146 @@ func TestHealthChecker(t *testing.T) {
 			"cat6 desc6: fatal",
 		}
 
-		hc.RunChecks(observer)
+		hc.RunChecks(context.Background(), observer)
+
+		if !reflect.DeepEqual(observedResults, expectedResults) {
+			t.Fatalf("Expected results %v, but got %v", expectedResults, observedResults)
+		}
+	})
+
+	t.Run("Is successful if a warning check fails", func(t *testing.T) {
+		warningCheck := &checker{
+			category:    "cat8",
+			description: "desc8",
+			warning:     true,
+			check: func(context.Context) error {
+				return fmt.Errorf("warning")
+			},
+		}
+
+		hc := HealthChecker{
+			checkers: []*checker{
+				passingCheck1,
+				warningCheck,
+				passingCheck2,
+			},
+		}
+
+		observedResults := make([]string, 0)
+		observer := func(result *CheckResult) {
+			res := fmt.Sprintf("%s %s warning=%t", result.Category, result.Description, result.Warning)
+			if result.Err != nil {
+				res += fmt.Sprintf(": %s", result.Err)
+			}
+			observedResults = append(observedResults, res)
+		}
+
+		expectedResults := []string{
+			"cat1 desc1 warning=false",
+			"cat8 desc8 warning=true: warning",
+			"cat2 desc2 warning=false",
+		}
 
+		success := hc.RunChecks(context.Background(), observer)
+
+		if !success {
+			t.Fatalf("Expecting checks to be successful, but got [%t]", success)
+		}
 		if !reflect.DeepEqual(observedResults, expectedResults) {
 			t.Fatalf("Expected results %v, but got %v", expectedResults, observedResults)
 		}
 	})
 
-	t.Run("Retries checks if retry is specified", func(t *testing.T) {
-		retryWindow = 0
-		returnError := true
+	t.Run("Notifies observer of each result of a multi-result check", func(t *testing.T) {
+		multiCheck := func(fatal bool, results ...*CheckResult) *checker {
+			return &checker{
+				category: "cat9",
+				fatal:    fatal,
+				checkResults: func(context.Context) []*CheckResult {
+					return results
+				},
+			}
+		}
+
+		testCases := []struct {
+			name            string
+			checker         *checker
+			expectedSuccess bool
+			expectedResults []string
+		}{
+			{
+				name: "passing and warning results",
+				checker: multiCheck(true,
+					&CheckResult{Category: "cat9", Description: "desc9a"},
+					&CheckResult{Category: "cat9", Description: "desc9b", HintAnchor: "hint", Warning: true, Err: fmt.Errorf("warning")},
+				),
+				expectedSuccess: true,
+				expectedResults: []string{"cat1 desc1", "cat9 desc9a", "cat9 desc9b hint=hint: warning", "cat2 desc2"},
+			},
+			{
+				name: "failing non-fatal result",
+				checker: multiCheck(false,
+					&CheckResult{Category: "cat9", Description: "desc9a", Err: fmt.Errorf("error")},
+					&CheckResult{Category: "cat9", Description: "desc9b"},
+				),
+				expectedSuccess: false,
+				expectedResults: []string{"cat1 desc1", "cat9 desc9a: error", "cat9 desc9b", "cat2 desc2"},
+			},
+			{
+				name: "failing fatal result",
+				checker: multiCheck(true,
+					&CheckResult{Category: "cat9", Description: "desc9a", HintAnchor: "hint", Err: fmt.Errorf("fatal")},
+				),
+				expectedSuccess: false,
+				expectedResults: []string{"cat1 desc1", "cat9 desc9a hint=hint: fatal"},
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				hc := HealthChecker{
+					checkers: []*checker{
+						passingCheck1,
+						tc.checker,
+						passingCheck2,
+					},
+				}
+
+				observedResults := make([]string, 0)
+				observer := func(result *CheckResult) {
+					res := fmt.Sprintf("%s %s", result.Category, result.Description)
+					if result.HintAnchor != "" {
+						res += fmt.Sprintf(" hint=%s", result.HintAnchor)
+					}
+					if result.Err != nil {
+						res += fmt.Sprintf(": %s", result.Err)
+					}
+					observedResults = append(observedResults, res)
+				}
+
+				success := hc.RunChecks(context.Background(), observer)
+
+				if success != tc.expectedSuccess {
+					t.Fatalf("Expecting checks to be successful [%t], but got [%t]", tc.expectedSuccess, success)
+				}
+				if !reflect.DeepEqual(observedResults, tc.expectedResults) {
+					t.Fatalf("Expected results %v, but got %v", tc.expectedResults, observedResults)
+				}
+			})
+		}
+	})
+
+	t.Run("Retries checks until they pass", func(t *testing.T) {
+		retryBaseDelay = 0
+		attempts := 0
 
 		retryCheck := &checker{
 			category:    "cat7",
 			description: "desc7",
 			retry:       true,
-			check: func() error {
-				if returnError {
-					returnError = false
+			check: func(context.Context) error {
+				attempts++
+				if attempts < 3 {
 					return fmt.Errorf("retry")
 				}
 				return nil
@@ -229,11 +389,12 @@ func TestHealthChecker(t *testing.T) {
 				passingCheck1,
 				retryCheck,
 			},
+			HealthCheckOptions: &HealthCheckOptions{RetryDeadline: time.Now().Add(time.Minute)},
 		}
 
 		observedResults := make([]string, 0)
 		observer := func(result *CheckResult) {
-			res := fmt.Sprintf("%s %s retry=%t", result.Category, result.Description, result.Retry)
+			res := fmt.Sprintf("%s %s retry=%t attempt=%d", result.Category, result.Description, result.Retry, result.Attempt)
 			if result.Err != nil {
 				res += fmt.Sprintf(": %s", result.Err)
 			}
@@ -241,17 +402,143 @@ func TestHealthChecker(t *testing.T) {
 		}
 
 		expectedResults := []string{
-			"cat1 desc1 retry=false",
-			"cat7 desc7 retry=true: retry",
-			"cat7 desc7 retry=false",
+			"cat1 desc1 retry=false attempt=1",
+			"cat7 desc7 retry=true attempt=1: retry",
+			"cat7 desc7 retry=true attempt=2: retry",
+			"cat7 desc7 retry=false attempt=3",
+		}
+
+		success := hc.RunChecks(context.Background(), observer)
+
+		if !success {
+			t.Fatalf("Expecting checks to be successful, but got [%t]", success)
+		}
+		if !reflect.DeepEqual(observedResults, expectedResults) {
+			t.Fatalf("Expected results %v, but got %v", expectedResults, observedResults)
+		}
+	})
+
+	t.Run("Does not retry checks that are not retryable", func(t *testing.T) {
+		attempts := 0
+		permanentCheck := &checker{
+			category:    "cat10",
+			description: "desc10",
+			fatal:       true,
+			check: func(context.Context) error {
+				attempts++
+				return fmt.Errorf("fatal")
+			},
+		}
+
+		hc := HealthChecker{
+			checkers: []*checker{
+				permanentCheck,
+				passingCheck1,
+			},
+			HealthCheckOptions: &HealthCheckOptions{RetryDeadline: time.Now().Add(time.Minute)},
+		}
+
+		observedResults := make([]string, 0)
+		observer := func(result *CheckResult) {
+			observedResults = append(observedResults, fmt.Sprintf("%s %s retry=%t: %s", result.Category, result.Description, result.Retry, result.Err))
+		}
+
+		success := hc.RunChecks(context.Background(), observer)
+
+		expectedResults := []string{"cat10 desc10 retry=false: fatal"}
+		if success || attempts != 1 {
+			t.Fatalf("Expecting a single failed attempt, but got [%d] attempts and success [%t]", attempts, success)
+		}
+		if !reflect.DeepEqual(observedResults, expectedResults) {
+			t.Fatalf("Expected results %v, but got %v", expectedResults, observedResults)
+		}
+	})
+
+	t.Run("Stops retrying at the deadline", func(t *testing.T) {
+		retryBaseDelay = 10 * time.Millisecond
+		retryWindow = 10 * time.Millisecond
+
+		retryCheck := &checker{
+			category:    "cat7",
+			description: "desc7",
+			retry:       true,
+			check: func(context.Context) error {
+				return fmt.Errorf("retry")
+			},
+		}
+
+		hc := HealthChecker{
+			checkers:           []*checker{retryCheck},
+			HealthCheckOptions: &HealthCheckOptions{RetryDeadline: time.Now().Add(100 * time.Millisecond)},
+		}
+
+		var last *CheckResult
+		success := hc.RunChecks(context.Background(), func(result *CheckResult) { last = result })
+
+		if success || last.Retry || last.Attempt < 2 {
+			t.Fatalf("Expected the check to fail after several attempts, got [%+v]", last)
+		}
+	})
+
+	t.Run("Retries multi-result checks with retryable failures", func(t *testing.T) {
+		retryBaseDelay = 0
+		attempts := 0
+
+		multiCheck := &checker{
+			category: "cat9",
+			checkResults: func(context.Context) []*CheckResult {
+				attempts++
+				if attempts < 2 {
+					return []*CheckResult{{Category: "cat9", Description: "desc9a", Retryable: true, Err: fmt.Errorf("retry")}}
+				}
+				return []*CheckResult{
+					{Category: "cat9", Description: "desc9a", Retryable: true},
+					{Category: "cat9", Description: "desc9b"},
+				}
+			},
+		}
+
+		hc := HealthChecker{
+			checkers:           []*checker{multiCheck},
+			HealthCheckOptions: &HealthCheckOptions{RetryDeadline: time.Now().Add(time.Minute)},
 		}
 
-		hc.RunChecks(observer)
+		observedResults := make([]string, 0)
+		observer := func(result *CheckResult) {
+			observedResults = append(observedResults, fmt.Sprintf("%s %s retry=%t attempt=%d", result.Category, result.Description, result.Retry, result.Attempt))
+		}
 
+		expectedResults := []string{
+			"cat9 desc9a retry=true attempt=1",
+			"cat9 desc9a retry=false attempt=2",
+			"cat9 desc9b retry=false attempt=2",
+		}
+
+		if !hc.RunChecks(context.Background(), observer) {
+			t.Fatalf("Expecting checks to be successful")
+		}
 		if !reflect.DeepEqual(observedResults, expectedResults) {
 			t.Fatalf("Expected results %v, but got %v", expectedResults, observedResults)
 		}
 	})
+
+	t.Run("Does not retry checks without a deadline", func(t *testing.T) {
+		attempts := 0
+		retryCheck := &checker{
+			category:    "cat7",
+			description: "desc7",
+			retry:       true,
+			check: func(context.Context) error {
+				attempts++
+				return fmt.Errorf("retry")
+			},
+		}
+
+		hc := HealthChecker{checkers: []*checker{retryCheck}}
+		if hc.RunChecks(context.Background(), nullObserver) || attempts != 1 {
+			t.Fatalf("Expected a single failed attempt, got [%d]", attempts)
+		}
+	})
 }
 
 func TestValidateControlPlanePods(t *testing.T) {
@@ -335,16 +622,6 @@ func TestValidateDataPlanePods(t *testing.T) {
 		}
 	}
 
-	t.Run("Returns an error if no inject pods were found", func(t *testing.T) {
-		err := validateDataPlanePods([]v1.Pod{}, "emojivoto")
-		if err == nil {
-			t.Fatal("Expected error, got nothing")
-		}
-		if err.Error() != "No \"linkerd-proxy\" containers found in the \"emojivoto\" namespace" {
-			t.Fatalf("Unexpected error message: %s", err.Error())
-		}
-	})
-
 	t.Run("Returns an error if not all pods are running", func(t *testing.T) {
 		pods := []v1.Pod{
 			pod("emoji-d9c7866bb-7v74n", v1.PodRunning, true),
@@ -353,7 +630,7 @@ func TestValidateDataPlanePods(t *testing.T) {
 			pod("web-6cfbccc48-5g8px", v1.PodRunning, true),
 		}
 
-		err := validateDataPlanePods(pods, "emojivoto")
+		err := validateDataPlanePods(pods)
 		if err == nil {
 			t.Fatal("Expected error, got nothing")
 		}
@@ -370,7 +647,7 @@ func TestValidateDataPlanePods(t *testing.T) {
 			pod("web-6cfbccc48-5g8px", v1.PodRunning, true),
 		}
 
-		err := validateDataPlanePods(pods, "emojivoto")
+		err := validateDataPlanePods(pods)
 		if err == nil {
 			t.Fatal("Expected error, got nothing")
 		}
@@ -387,9 +664,1136 @@ func TestValidateDataPlanePods(t *testing.T) {
 			pod("web-6cfbccc48-5g8px", v1.PodRunning, true),
 		}
 
-		err := validateDataPlanePods(pods, "emojivoto")
+		err := validateDataPlanePods(pods)
 		if err != nil {
 			t.Fatalf("Unexpected error: %s", err)
 		}
 	})
 }
+
+func TestValidateMeshedPods(t *testing.T) {
+	t.Run("Returns an error if no inject pods were found", func(t *testing.T) {
+		err := validateMeshedPods([]v1.Pod{}, "emojivoto")
+		if err == nil {
+			t.Fatal("Expected error, got nothing")
+		}
+		if err.Error() != "No \"linkerd-proxy\" containers found in the \"emojivoto\" namespace" {
+			t.Fatalf("Unexpected error message: %s", err.Error())
+		}
+	})
+
+	t.Run("Returns nil if there are inject pods", func(t *testing.T) {
+		pods := []v1.Pod{{ObjectMeta: meta.ObjectMeta{Name: "emoji-d9c7866bb-7v74n", Namespace: "emojivoto"}}}
+		if err := validateMeshedPods(pods, "emojivoto"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestNamespaceError(t *testing.T) {
+	t.Run("Adds guidance to forbidden errors", func(t *testing.T) {
+		err := namespaceError("linkerd", &k8s.APIError{StatusCode: http.StatusForbidden, Status: "403 Forbidden"})
+
+		expected := "Your account cannot get the \"linkerd\" namespace; grant it permission to get namespaces, or pass a --linkerd-namespace it can read: Unexpected Kubernetes API response: 403 Forbidden"
+		if err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%s]", expected, err)
+		}
+	})
+
+	t.Run("Returns other errors unchanged", func(t *testing.T) {
+		original := &k8s.APIError{StatusCode: http.StatusInternalServerError, Status: "500 Internal Server Error"}
+		if err := namespaceError("linkerd", original); err != original {
+			t.Fatalf("Expected error [%s], got [%s]", original, err)
+		}
+	})
+}
+
+func TestCheckDeployment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/apps/v1/namespaces/linkerd/deployments/controller":
+			w.Write([]byte(`{"metadata":{"name":"controller"},"spec":{"replicas":1},"status":{"replicas":1,"updatedReplicas":1,"readyReplicas":1}}`))
+		case "/apis/apps/v1/namespaces/linkerd/deployments/web":
+			w.Write([]byte(`{"metadata":{"name":"web"},"spec":{"replicas":2},"status":{"replicas":2,"updatedReplicas":2,"readyReplicas":1}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+	hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	testCases := []struct {
+		name string
+		err  string
+	}{
+		{name: "controller"},
+		{name: "web", err: "The \"web\" deployment is not healthy: 1 of 2 replicas are ready"},
+		{name: "grafana", err: "The \"grafana\" deployment does not exist in the \"linkerd\" namespace"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := hc.checkDeployment(context.Background(), tc.name)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("Expected error [%s], got [%v]", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestCheckControlPlaneInstalled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("labelSelector") != k8s.ControllerComponentLabel {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/apis/apps/v1/namespaces/custom-ns/deployments":
+			w.Write([]byte(`{"items":[{"metadata":{"name":"controller","labels":{"linkerd.io/control-plane-component":"controller"}}}]}`))
+		case "/apis/apps/v1/namespaces/default/deployments":
+			w.Write([]byte(`{"items":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		namespace string
+		err       string
+	}{
+		{namespace: "custom-ns"},
+		{namespace: "default", err: "The \"default\" namespace does not contain a Linkerd control plane; pass the namespace Linkerd was installed in with --linkerd-namespace"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.namespace, func(t *testing.T) {
+			hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: tc.namespace})
+			hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+			err := hc.checkControlPlaneInstalled(context.Background())
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("Expected error [%s], got [%v]", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestWithPodWarnings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fieldSelector") == "involvedObject.kind=Pod,involvedObject.name=web-1" {
+			w.Write([]byte(`{"items":[{"reason":"FailedScheduling","message":"0/3 nodes are available: 3 Insufficient cpu.","type":"Warning","lastTimestamp":"2018-09-01T10:05:00Z"}]}`))
+			return
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+	hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	pods := []v1.Pod{
+		{
+			ObjectMeta: meta.ObjectMeta{Name: "web-1", Namespace: "linkerd"},
+			Status:     v1.PodStatus{Phase: v1.PodPending},
+		},
+		{
+			ObjectMeta: meta.ObjectMeta{Name: "controller-1", Namespace: "linkerd"},
+			Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+			},
+		},
+	}
+
+	t.Run("Appends warnings of pods that are not ready", func(t *testing.T) {
+		err := hc.withPodWarnings(context.Background(), pods, fmt.Errorf("No running pods for \"web\""))
+
+		expected := "No running pods for \"web\"\nRecent warnings for the \"web-1\" pod in the \"linkerd\" namespace:\n\tFailedScheduling: 0/3 nodes are available: 3 Insufficient cpu."
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Returns nil when there is no error", func(t *testing.T) {
+		if err := hc.withPodWarnings(context.Background(), pods, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestValidateNodes(t *testing.T) {
+	t.Run("Returns nil when all nodes are compatible", func(t *testing.T) {
+		if err := validateNodes([]k8s.NodeCompatibility{{Name: "node-1"}}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Lists incompatible nodes", func(t *testing.T) {
+		nodes := []k8s.NodeCompatibility{
+			{Name: "node-1"},
+			{Name: "win-1", Problems: []string{"unsupported operating system [windows]"}},
+			{Name: "arm-1", Problems: []string{"unsupported architecture [arm64]"}},
+		}
+
+		expected := "Linkerd proxies cannot run on 2 of 3 nodes:\n\twin-1: unsupported operating system [windows]\n\tarm-1: unsupported architecture [arm64]"
+		if err := validateNodes(nodes); err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+// accessReviewServer answers SelfSubjectAccessReviews, allowing any action for
+// a cluster-admin and only get, list and watch for a read-only user.
+func accessReviewServer(t *testing.T, clusterAdmin bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var review authorizationv1.SelfSubjectAccessReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			t.Fatalf("Unexpected error decoding review: %v", err)
+		}
+
+		switch review.Spec.ResourceAttributes.Verb {
+		case "get", "list", "watch":
+			review.Status.Allowed = true
+		default:
+			review.Status.Allowed = clusterAdmin
+		}
+		if !review.Status.Allowed {
+			review.Status.Reason = "RBAC: clusterrole \"view\" does not allow " + review.Spec.ResourceAttributes.Verb
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+	}))
+}
+
+func TestCheckInstallCapabilities(t *testing.T) {
+	kinds := []string{"Namespaces", "ClusterRoles", "ClusterRoleBindings", "CustomResourceDefinitions", "ServiceAccounts", "Deployments", "Services", "ConfigMaps"}
+
+	t.Run("Passes for a cluster-admin", func(t *testing.T) {
+		server := accessReviewServer(t, true)
+		defer server.Close()
+
+		hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+		hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		results := hc.checkInstallCapabilities(context.Background())
+		if len(results) != len(kinds) {
+			t.Fatalf("Expected %d results, got %d", len(kinds), len(results))
+		}
+		for i, result := range results {
+			expected := "can create " + kinds[i]
+			if result.Description != expected {
+				t.Fatalf("Expected result [%s], got [%s]", expected, result.Description)
+			}
+			if result.Category != LinkerdPreInstallCapabilityCategory || result.Err != nil {
+				t.Fatalf("Expected [%s] to pass, got [%v]", result.Description, result.Err)
+			}
+		}
+	})
+
+	t.Run("Fails each capability for a read-only user", func(t *testing.T) {
+		server := accessReviewServer(t, false)
+		defer server.Close()
+
+		hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+		hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		results := hc.checkInstallCapabilities(context.Background())
+		if len(results) != len(kinds) {
+			t.Fatalf("Expected %d results, got %d", len(kinds), len(results))
+		}
+		for _, result := range results {
+			if result.Err == nil {
+				t.Fatalf("Expected [%s] to fail", result.Description)
+			}
+		}
+
+		expectedErrs := map[int]string{
+			1: "Your account is not allowed to create clusterroles.rbac.authorization.k8s.io: RBAC: clusterrole \"view\" does not allow create",
+			5: "Your account is not allowed to create deployments.extensions in namespace linkerd: RBAC: clusterrole \"view\" does not allow create",
+		}
+		for i, expected := range expectedErrs {
+			if results[i].Err.Error() != expected {
+				t.Fatalf("Expected error [%s], got [%s]", expected, results[i].Err)
+			}
+		}
+	})
+
+	t.Run("Only checks namespaced resources for --single-namespace installs", func(t *testing.T) {
+		// A user who is an admin of the linkerd namespace only.
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var review authorizationv1.SelfSubjectAccessReview
+			if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+				t.Fatalf("Unexpected error decoding review: %v", err)
+			}
+			review.Status.Allowed = review.Spec.ResourceAttributes.Namespace == "linkerd"
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(review)
+		}))
+		defer server.Close()
+
+		hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd", SingleNamespace: true})
+		hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		singleNamespaceKinds := []string{"Roles", "RoleBindings", "ServiceAccounts", "Deployments", "Services", "ConfigMaps"}
+		results := hc.checkInstallCapabilities(context.Background())
+		if len(results) != len(singleNamespaceKinds) {
+			t.Fatalf("Expected %d results, got %d", len(singleNamespaceKinds), len(results))
+		}
+		for i, result := range results {
+			expected := "can create " + singleNamespaceKinds[i]
+			if result.Description != expected {
+				t.Fatalf("Expected result [%s], got [%s]", expected, result.Description)
+			}
+			if result.Err != nil {
+				t.Fatalf("Expected [%s] to pass, got [%v]", result.Description, result.Err)
+			}
+		}
+	})
+
+	t.Run("Checks the PodSecurityPolicy and its RBAC with --enable-psp", func(t *testing.T) {
+		server := accessReviewServer(t, true)
+		defer server.Close()
+
+		testCases := []struct {
+			singleNamespace bool
+			expectedKinds   []string
+		}{
+			{false, append(kinds, "PodSecurityPolicies", "Roles", "RoleBindings")},
+			{true, []string{"Roles", "RoleBindings", "ServiceAccounts", "Deployments", "Services", "ConfigMaps", "PodSecurityPolicies"}},
+		}
+		for _, tc := range testCases {
+			hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd", SingleNamespace: tc.singleNamespace, EnablePSP: true})
+			hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+			results := hc.checkInstallCapabilities(context.Background())
+			if len(results) != len(tc.expectedKinds) {
+				t.Fatalf("Expected %d results, got %d", len(tc.expectedKinds), len(results))
+			}
+			for i, result := range results {
+				expected := "can create " + tc.expectedKinds[i]
+				if result.Description != expected {
+					t.Fatalf("Expected result [%s], got [%s]", expected, result.Description)
+				}
+			}
+		}
+	})
+
+	t.Run("Reports a single failure when permissions cannot be checked", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+		hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		results := hc.checkInstallCapabilities(context.Background())
+		if len(results) != 1 || results[0].Err == nil {
+			t.Fatalf("Expected a single failed result, got %+v", results)
+		}
+	})
+}
+
+func TestCheckPodSecurityPolicies(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policies    string
+		expectedErr string
+	}{
+		{
+			name:     "passes without policies",
+			policies: `{"items":[]}`,
+		},
+		{
+			name:        "warns when policies are enforced",
+			policies:    `{"items":[{"metadata":{"name":"restricted"}}]}`,
+			expectedErr: "The cluster enforces PodSecurityPolicies, which may reject the control plane pods; install with --enable-psp to create a policy allowing them",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/apis/policy/v1beta1":
+					w.Write([]byte(`{"resources":[{"name":"podsecuritypolicies"}]}`))
+				case "/apis/policy/v1beta1/podsecuritypolicies":
+					w.Write([]byte(tc.policies))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+			hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+			err := hc.checkPodSecurityPolicies(context.Background())
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Fatalf("Expected error [%s], got [%v]", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckNoPreviousInstall(t *testing.T) {
+	testCases := []struct {
+		name                string
+		namespace           string
+		pods                string
+		expectedAbsentErr   string
+		expectedPreviousErr string
+	}{
+		{
+			name: "namespace does not exist",
+			pods: `{"items":[]}`,
+		},
+		{
+			name:              "namespace is empty",
+			namespace:         `{"metadata":{"name":"linkerd"}}`,
+			pods:              `{"items":[]}`,
+			expectedAbsentErr: "The \"linkerd\" namespace already exists; the install will reuse it",
+		},
+		{
+			name:                "namespace contains a previous install",
+			namespace:           `{"metadata":{"name":"linkerd"}}`,
+			pods:                `{"items":[{"metadata":{"name":"controller-5b8f6d8c9-xwm2q","labels":{"linkerd.io/control-plane-component":"controller"}}}]}`,
+			expectedAbsentErr:   "The \"linkerd\" namespace already exists; the install will reuse it",
+			expectedPreviousErr: "The \"linkerd\" namespace already contains a control plane, e.g. pod \"controller-5b8f6d8c9-xwm2q\"; uninstall it, or pass another --linkerd-namespace",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/api/v1/namespaces/linkerd" && tc.namespace != "":
+					w.Write([]byte(tc.namespace))
+				case r.URL.Path == "/api/v1/namespaces/linkerd/pods" && tc.pods != "":
+					if selector := r.URL.Query().Get("labelSelector"); selector != k8s.ControllerComponentLabel {
+						t.Errorf("Unexpected label selector [%s]", selector)
+					}
+					w.Write([]byte(tc.pods))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+			hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+			checks := []struct {
+				check       func(context.Context) error
+				expectedErr string
+			}{
+				{hc.checkNamespaceAbsent, tc.expectedAbsentErr},
+				{hc.checkNoPreviousInstall, tc.expectedPreviousErr},
+			}
+			for _, c := range checks {
+				err := c.check(context.Background())
+				if c.expectedErr == "" {
+					if err != nil {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+					continue
+				}
+				if err == nil || err.Error() != c.expectedErr {
+					t.Fatalf("Expected error [%s], got [%v]", c.expectedErr, err)
+				}
+			}
+		})
+	}
+
+	t.Run("Reports an existing namespace as a warning", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/namespaces/linkerd":
+				w.Write([]byte(`{"metadata":{"name":"linkerd"}}`))
+			case "/api/v1/namespaces/linkerd/pods":
+				w.Write([]byte(`{"items":[]}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		hc := NewHealthChecker([]Checks{LinkerdPreInstallChecks}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+		hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+		// Skip the capability checks, which this server doesn't answer.
+		hc.checkers = hc.checkers[:2]
+
+		var results []*CheckResult
+		success := hc.RunChecks(context.Background(), func(result *CheckResult) {
+			results = append(results, result)
+		})
+		if !success {
+			t.Fatalf("Expected the pre-install checks to pass, got %+v", results)
+		}
+		if len(results) != 2 || !results[0].Warning || results[0].Err == nil || results[1].Err != nil {
+			t.Fatalf("Unexpected results %+v", results)
+		}
+	})
+}
+
+func TestCheckControlPlaneBindings(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      string
+		description string
+	}{
+		{
+			name:        "installs predating the install ConfigMap use ClusterRoleBindings",
+			description: "control plane ClusterRoleBindings are valid",
+		},
+		{
+			name:        "--single-namespace installs use RoleBindings",
+			config:      `{"data":{"install":"{\"flags\":[{\"name\":\"single-namespace\",\"value\":\"true\"}]}"}}`,
+			description: "control plane RoleBindings are valid",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rbac := "/apis/rbac.authorization.k8s.io/v1/"
+				switch {
+				case r.URL.Path == "/api/v1/namespaces/linkerd/configmaps/linkerd-config" && tc.config != "":
+					w.Write([]byte(tc.config))
+				case strings.HasPrefix(r.URL.Path, rbac+"clusterrolebindings/linkerd-linkerd-"):
+					component := strings.TrimPrefix(r.URL.Path, rbac+"clusterrolebindings/linkerd-linkerd-")
+					fmt.Fprintf(w, `{"metadata":{"name":"linkerd-linkerd-%s"},"roleRef":{"kind":"ClusterRole","name":"linkerd-linkerd-%s"},"subjects":[{"kind":"ServiceAccount","name":"linkerd-%s","namespace":"linkerd"}]}`, component, component, component)
+				case strings.HasPrefix(r.URL.Path, rbac+"namespaces/linkerd/rolebindings/linkerd-"):
+					component := strings.TrimPrefix(r.URL.Path, rbac+"namespaces/linkerd/rolebindings/linkerd-")
+					fmt.Fprintf(w, `{"metadata":{"name":"linkerd-%s"},"roleRef":{"kind":"Role","name":"linkerd-%s"},"subjects":[{"kind":"ServiceAccount","name":"linkerd-%s","namespace":"linkerd"}]}`, component, component, component)
+				case strings.HasPrefix(r.URL.Path, rbac+"clusterroles/linkerd-linkerd-"), strings.HasPrefix(r.URL.Path, rbac+"namespaces/linkerd/roles/linkerd-"):
+					w.Write([]byte(`{}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+			hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+			results := hc.checkControlPlaneBindings(context.Background())
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 result, got %+v", results)
+			}
+			if results[0].Description != tc.description {
+				t.Fatalf("Expected description [%s], got [%s]", tc.description, results[0].Description)
+			}
+			if results[0].Err != nil {
+				t.Fatalf("Unexpected error: %v", results[0].Err)
+			}
+		})
+	}
+}
+
+func TestCheckHighAvailability(t *testing.T) {
+	node := func(name string, unschedulable bool) string {
+		return fmt.Sprintf(`{"metadata":{"name":"%s"},"spec":{"unschedulable":%t}}`, name, unschedulable)
+	}
+	haConfig := `{"data":{"install":"{\"flags\":[{\"name\":\"ha\",\"value\":\"true\"}]}"}}`
+
+	testCases := []struct {
+		name        string
+		config      string
+		nodes       []string
+		results     int
+		expectedErr string
+	}{
+		{
+			name:  "installs predating the install ConfigMap are skipped",
+			nodes: []string{node("node-1", false)},
+		},
+		{
+			name:   "installs without --ha are skipped",
+			config: `{"data":{"install":"{\"flags\":[{\"name\":\"web-replicas\",\"value\":\"2\"}]}"}}`,
+			nodes:  []string{node("node-1", false)},
+		},
+		{
+			name:    "--ha installs with enough nodes pass",
+			config:  haConfig,
+			nodes:   []string{node("node-1", false), node("node-2", false), node("node-3", false)},
+			results: 1,
+		},
+		{
+			name:        "--ha installs with too few schedulable nodes warn",
+			config:      haConfig,
+			nodes:       []string{node("node-1", false), node("node-2", true), node("node-3", false)},
+			results:     1,
+			expectedErr: "The control plane was installed with --ha, which runs 3 replicas of each component on separate nodes, but only 2 of 3 nodes are schedulable",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/api/v1/namespaces/linkerd/configmaps/linkerd-config" && tc.config != "":
+					w.Write([]byte(tc.config))
+				case r.URL.Path == "/api/v1/nodes":
+					w.Write([]byte(`{"items":[` + strings.Join(tc.nodes, ",") + `]}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+			hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+			results := hc.checkHighAvailability(context.Background())
+			if len(results) != tc.results {
+				t.Fatalf("Expected %d results, got %+v", tc.results, results)
+			}
+			if tc.results == 0 {
+				return
+			}
+			if !results[0].Warning {
+				t.Fatalf("Expected a warning, got %+v", results[0])
+			}
+			if tc.expectedErr == "" {
+				if results[0].Err != nil {
+					t.Fatalf("Unexpected error: %v", results[0].Err)
+				}
+				return
+			}
+			if results[0].Err == nil || results[0].Err.Error() != tc.expectedErr {
+				t.Fatalf("Expected error [%s], got [%v]", tc.expectedErr, results[0].Err)
+			}
+		})
+	}
+}
+
+func TestCheckProxyInjector(t *testing.T) {
+	issue := func(dnsName string) (caPEM []byte, secret v1.Secret) {
+		injectorCA, err := ca.NewCA()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		cert, err := injectorCA.IssueEndEntityCertificate(dnsName)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		secret = v1.Secret{Data: map[string][]byte{
+			v1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate}),
+			v1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: cert.PrivateKey}),
+		}}
+		return []byte(injectorCA.TrustAnchorPEM()), secret
+	}
+	caPEM, secret := issue("linkerd-proxy-injector.linkerd.svc")
+	otherCAPEM, otherSecret := issue("linkerd-proxy-injector.other.svc")
+
+	webhookConfig := func(namespace string, caBundle []byte) *admissionregistrationV1beta1.MutatingWebhookConfiguration {
+		return &admissionregistrationV1beta1.MutatingWebhookConfiguration{
+			Webhooks: []admissionregistrationV1beta1.Webhook{{
+				Name: "proxy-injector.linkerd.io",
+				ClientConfig: admissionregistrationV1beta1.WebhookClientConfig{
+					Service:  &admissionregistrationV1beta1.ServiceReference{Namespace: namespace, Name: "linkerd-proxy-injector"},
+					CABundle: caBundle,
+				},
+			}},
+		}
+	}
+	autoInjectConfig := `{"data":{"install":"{\"flags\":[{\"name\":\"proxy-auto-inject\",\"value\":\"true\"}]}"}}`
+	readyEndpoints := `{"subsets":[{"addresses":[{"ip":"10.1.0.5"}]}]}`
+
+	testCases := []struct {
+		name         string
+		config       string
+		webhook      *admissionregistrationV1beta1.MutatingWebhookConfiguration
+		secret       v1.Secret
+		endpoints    string
+		expectedErrs []string
+	}{
+		{
+			name:   "installs without --proxy-auto-inject are skipped",
+			config: `{"data":{"install":"{\"flags\":[{\"name\":\"ha\",\"value\":\"true\"}]}"}}`,
+		},
+		{
+			name:         "healthy proxy injectors pass",
+			config:       autoInjectConfig,
+			webhook:      webhookConfig("linkerd", caPEM),
+			secret:       secret,
+			endpoints:    readyEndpoints,
+			expectedErrs: []string{"", "", ""},
+		},
+		{
+			name:         "missing webhook configurations fail",
+			config:       autoInjectConfig,
+			secret:       secret,
+			endpoints:    readyEndpoints,
+			expectedErrs: []string{"The \"linkerd-linkerd-proxy-injector\" MutatingWebhookConfiguration does not exist", "cannot verify the certificate without the webhook configuration", ""},
+		},
+		{
+			name:         "webhooks calling another service fail",
+			config:       autoInjectConfig,
+			webhook:      webhookConfig("other", caPEM),
+			secret:       secret,
+			endpoints:    readyEndpoints,
+			expectedErrs: []string{"The \"linkerd-linkerd-proxy-injector\" MutatingWebhookConfiguration does not call the \"linkerd/linkerd-proxy-injector\" service", "cannot verify the certificate without the webhook configuration", ""},
+		},
+		{
+			name:         "certificates from another CA fail",
+			config:       autoInjectConfig,
+			webhook:      webhookConfig("linkerd", otherCAPEM),
+			secret:       secret,
+			endpoints:    readyEndpoints,
+			expectedErrs: []string{"", "The certificate of the proxy injector is not trusted by its webhook configuration for linkerd-proxy-injector.linkerd.svc", ""},
+		},
+		{
+			name:         "certificates for another name fail",
+			config:       autoInjectConfig,
+			webhook:      webhookConfig("linkerd", otherCAPEM),
+			secret:       otherSecret,
+			endpoints:    readyEndpoints,
+			expectedErrs: []string{"", "The certificate of the proxy injector is not trusted by its webhook configuration for linkerd-proxy-injector.linkerd.svc", ""},
+		},
+		{
+			name:         "services without ready endpoints fail",
+			config:       autoInjectConfig,
+			webhook:      webhookConfig("linkerd", caPEM),
+			secret:       secret,
+			endpoints:    `{"subsets":[{"notReadyAddresses":[{"ip":"10.1.0.5"}]}]}`,
+			expectedErrs: []string{"", "", "The \"linkerd-proxy-injector\" service has no ready endpoints"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body interface{}
+				switch r.URL.Path {
+				case "/api/v1/namespaces/linkerd/configmaps/linkerd-config":
+					body = json.RawMessage(tc.config)
+				case "/apis/admissionregistration.k8s.io/v1beta1/mutatingwebhookconfigurations/linkerd-linkerd-proxy-injector":
+					if tc.webhook != nil {
+						body = tc.webhook
+					}
+				case "/api/v1/namespaces/linkerd/secrets/linkerd-proxy-injector-tls":
+					body = tc.secret
+				case "/api/v1/namespaces/linkerd/endpoints/linkerd-proxy-injector":
+					body = json.RawMessage(tc.endpoints)
+				}
+				if body == nil {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"kind":"Status","reason":"NotFound","code":404}`))
+					return
+				}
+				json.NewEncoder(w).Encode(body)
+			}))
+			defer server.Close()
+
+			hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+			hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+			results := hc.checkProxyInjector(context.Background())
+			if len(results) != len(tc.expectedErrs) {
+				t.Fatalf("Expected %d results, got %+v", len(tc.expectedErrs), results)
+			}
+			for i, expectedErr := range tc.expectedErrs {
+				if results[i].Category != LinkerdProxyInjectorCategory {
+					t.Fatalf("Unexpected category of result %d: %s", i, results[i].Category)
+				}
+				if expectedErr == "" {
+					if results[i].Err != nil {
+						t.Fatalf("Unexpected error in result %d: %v", i, results[i].Err)
+					}
+					continue
+				}
+				if results[i].Err == nil || !strings.Contains(results[i].Err.Error(), expectedErr) {
+					t.Fatalf("Expected error [%s] in result %d, got [%v]", expectedErr, i, results[i].Err)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckControlPlaneComponents(t *testing.T) {
+	testCases := []struct {
+		fixture  string
+		expected []string
+	}{
+		{
+			fixture: "control_plane_pods_healthy.json",
+			expected: []string{
+				"controller: 5/5 containers ready, 0 restarts",
+				"grafana: 2/2 containers ready, 0 restarts",
+				"prometheus: 2/2 containers ready, 1 restarts",
+				"web: 2/2 containers ready, 0 restarts",
+			},
+		},
+		{
+			fixture: "control_plane_pods_crashloop.json",
+			expected: []string{
+				"controller: 5/5 containers ready, 0 restarts",
+				"grafana: 2/2 containers ready, 0 restarts",
+				"prometheus: 2/2 containers ready, 0 restarts",
+				"web: 1/2 containers ready, 7 restarts: The \"web-5f6b8c7d9-7tjqr\" pod has a waiting \"web\" container: CrashLoopBackOff: Back-off 5m0s restarting failed container=web pod=web-5f6b8c7d9-7tjqr_linkerd(3c1e2b4a)\nMost recent warning for the \"web-5f6b8c7d9-7tjqr\" pod:\n\tBackOff: Back-off restarting failed container",
+			},
+		},
+		{
+			fixture: "control_plane_pods_imagepull.json",
+			expected: []string{
+				"controller: 5/5 containers ready, 0 restarts",
+				"grafana: 1/2 containers ready, 0 restarts: The \"grafana-6f5d9b7c4-2kq8z\" pod has a waiting \"grafana\" container: ImagePullBackOff: Back-off pulling image \"gcr.io/linkerd-io/grafana:stable-2.0.0\"",
+				"prometheus: 2/2 containers ready, 0 restarts",
+				"web: 2/2 containers ready, 0 restarts",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			pods, err := ioutil.ReadFile("testdata/" + tc.fixture)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v1/namespaces/linkerd/pods":
+					w.Write(pods)
+				case "/api/v1/namespaces/linkerd/events":
+					if r.URL.Query().Get("fieldSelector") == "involvedObject.kind=Pod,involvedObject.name=web-5f6b8c7d9-7tjqr" {
+						w.Write([]byte(`{"items":[
+							{"type":"Normal","reason":"Pulled","message":"Container image already present on machine","lastTimestamp":"2018-10-01T10:05:00Z"},
+							{"type":"Warning","reason":"BackOff","message":"Back-off restarting failed container","lastTimestamp":"2018-10-01T10:04:00Z"},
+							{"type":"Warning","reason":"Unhealthy","message":"Readiness probe failed","lastTimestamp":"2018-10-01T10:01:00Z"}
+						]}`))
+						return
+					}
+					w.Write([]byte(`{"items":[]}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+			hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+			results := hc.checkControlPlaneComponents(context.Background())
+
+			observed := make([]string, len(results))
+			for i, result := range results {
+				observed[i] = result.Description
+				if result.Err != nil {
+					observed[i] += ": " + result.Err.Error()
+				}
+				if result.Category != LinkerdControlPlaneCategory || !result.Retryable {
+					t.Fatalf("Unexpected result %+v", result)
+				}
+			}
+			if !reflect.DeepEqual(observed, tc.expected) {
+				t.Fatalf("Expected results %q, but got %q", tc.expected, observed)
+			}
+		})
+	}
+
+	t.Run("Reports components without pods", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"items":[]}`))
+		}))
+		defer server.Close()
+
+		hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+		hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		results := hc.checkControlPlaneComponents(context.Background())
+		expected := "No running pods for \"controller\""
+		if results[0].Err == nil || results[0].Err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, results[0].Err)
+		}
+	})
+}
+
+func TestCheckDataPlaneVersions(t *testing.T) {
+	pages := make([][]byte, 2)
+	for i := range pages {
+		var err error
+		pages[i], err = ioutil.ReadFile(fmt.Sprintf("testdata/data_plane_pods_page_%d.json", i+1))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/pods" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if selector := r.URL.Query().Get("labelSelector"); selector != "linkerd.io/control-plane-ns=linkerd" {
+			t.Errorf("Unexpected label selector [%s]", selector)
+		}
+		switch r.URL.Query().Get("continue") {
+		case "":
+			w.Write(pages[0])
+		case "page-2":
+			w.Write(pages[1])
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	newHealthChecker := func() *HealthChecker {
+		hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{
+			ControlPlaneNamespace: "linkerd",
+			VersionOverride:       "stable-2.0.0",
+		})
+		hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+		return hc
+	}
+
+	t.Run("Reports stale and unready proxies", func(t *testing.T) {
+		var progress []string
+		err := newHealthChecker().checkDataPlaneVersions(context.Background(), func(p string) {
+			progress = append(progress, p)
+		})
+
+		expected := `3 of 6 proxies are not running version stable-2.0.0:
+	emojivoto/voting-7c9d8f6b4-fj2lm: edge-18.9.1
+	booksapp/books-8d7c6b5a4-m2n3p: edge-18.9.1
+	booksapp/authors-5c4b3a2d1-k8j7h: edge-18.8.4
+1 of 6 proxies are not ready:
+	emojivoto/web-5d8f7b6c9-qw8rt: has a waiting "linkerd-proxy" container: CrashLoopBackOff: Back-off 10s restarting failed container`
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+
+		expectedProgress := []string{"checked 4 pods", "checked 8 pods"}
+		if !reflect.DeepEqual(progress, expectedProgress) {
+			t.Fatalf("Expected progress %q, got %q", expectedProgress, progress)
+		}
+	})
+
+	t.Run("Limits the number of listed proxies", func(t *testing.T) {
+		defer func(max int) { maxReportedProxies = max }(maxReportedProxies)
+		maxReportedProxies = 2
+
+		err := newHealthChecker().checkDataPlaneVersions(context.Background(), func(string) {})
+		if err == nil || !strings.Contains(err.Error(), "booksapp/books-8d7c6b5a4-m2n3p: edge-18.9.1\n\t... and 1 more\n") {
+			t.Fatalf("Expected the list of stale proxies to be truncated, got [%v]", err)
+		}
+	})
+
+	t.Run("Is reported as a warning with progress", func(t *testing.T) {
+		hc := newHealthChecker()
+		hc.addLinkerdDataPlaneChecks()
+		// Skip the readiness check, which fails on the unready proxy.
+		hc.checkers = hc.checkers[len(hc.checkers)-1:]
+
+		var results []*CheckResult
+		hc.RunChecks(context.Background(), func(result *CheckResult) {
+			if result.Description == "data plane proxies are up-to-date" {
+				results = append(results, result)
+			}
+		})
+
+		if len(results) != 3 {
+			t.Fatalf("Expected 2 progress results and a final one, got %d", len(results))
+		}
+		if results[0].Progress != "checked 4 pods" || results[0].Err != nil {
+			t.Fatalf("Unexpected progress result %+v", results[0])
+		}
+		if final := results[2]; final.Progress != "" || !final.Warning || final.Err == nil {
+			t.Fatalf("Unexpected final result %+v", final)
+		}
+	})
+}
+
+func TestProxyVersion(t *testing.T) {
+	annotated := v1.Pod{ObjectMeta: meta.ObjectMeta{Annotations: map[string]string{k8s.ProxyVersionAnnotation: "edge-18.8.4"}}}
+
+	testCases := []struct {
+		image    string
+		expected string
+	}{
+		{image: "gcr.io/linkerd-io/proxy:stable-2.0.0", expected: "stable-2.0.0"},
+		{image: "localhost:5000/linkerd-io/proxy:dev-1a2b3c4d", expected: "dev-1a2b3c4d"},
+		{image: "gcr.io/linkerd-io/proxy@sha256:5e8c3b2a1d", expected: "edge-18.8.4"},
+		{image: "localhost:5000/proxy", expected: "edge-18.8.4"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			if observed := proxyVersion(annotated, tc.image); observed != tc.expected {
+				t.Fatalf("Expected version [%s], got [%s]", tc.expected, observed)
+			}
+		})
+	}
+}
+
+// dataPlaneServer is a fake API server for the emojivoto namespace, listing
+// the pods of fixture, whose proxies answer readiness requests with 200 if
+// they are ready, or 503, and an Endpoints object for the proxy-api service.
+func dataPlaneServer(t *testing.T, fixture string, endpoints string) *httptest.Server {
+	body, err := ioutil.ReadFile("testdata/" + fixture)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var list v1.PodList
+	if err := json.Unmarshal(body, &list); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/emojivoto":
+			w.Write([]byte(`{"metadata":{"name":"emojivoto"}}`))
+			return
+		case "/api/v1/namespaces/emojivoto/pods":
+			w.Write(body)
+			return
+		case "/api/v1/namespaces/linkerd/endpoints/proxy-api":
+			w.Write([]byte(endpoints))
+			return
+		}
+
+		for _, pod := range list.Items {
+			port, path := proxyReadinessEndpoint(pod)
+			if port == "linkerd-metrics" {
+				port = "4191"
+			}
+			switch r.URL.Path {
+			case "/api/v1/namespaces/emojivoto/pods/" + pod.Name:
+				json.NewEncoder(w).Encode(pod)
+				return
+			case "/api/v1/namespaces/emojivoto/pods/" + pod.Name + ":" + port + "/proxy" + path:
+				if !k8s.IsPodReady(pod) {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+func TestDataPlaneChecks(t *testing.T) {
+	readyEndpoints := `{"subsets":[{"addresses":[{"ip":"10.1.0.12"}]}]}`
+
+	runChecks := func(server *httptest.Server) (bool, []string) {
+		hc := NewHealthChecker([]Checks{LinkerdDataPlaneChecks}, &HealthCheckOptions{
+			ControlPlaneNamespace: "linkerd",
+			DataPlaneNamespace:    "emojivoto",
+			VersionOverride:       "stable-2.0.0",
+		})
+		hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		observed := []string{}
+		success := hc.RunChecks(context.Background(), func(result *CheckResult) {
+			if result.Progress != "" {
+				return
+			}
+			res := fmt.Sprintf("%s %s", result.Category, result.Description)
+			if result.Err != nil {
+				res += fmt.Sprintf(": %s", result.Err)
+			}
+			observed = append(observed, res)
+		})
+		return success, observed
+	}
+
+	t.Run("Passes with healthy proxies", func(t *testing.T) {
+		server := dataPlaneServer(t, "data_plane_proxies_healthy.json", readyEndpoints)
+		defer server.Close()
+
+		success, observed := runChecks(server)
+		expected := []string{
+			"linkerd-data-plane data plane namespace exists",
+			"linkerd-data-plane data plane has meshed pods",
+			"linkerd-data-plane data plane proxies are ready",
+			"linkerd-data-plane data plane proxies respond to readiness requests",
+			"linkerd-data-plane control plane destination service is available",
+			"linkerd-data-plane data plane proxies are up-to-date",
+		}
+		if !success || !reflect.DeepEqual(observed, expected) {
+			t.Fatalf("Expected results %q, but got %q", expected, observed)
+		}
+	})
+
+	t.Run("Warns about namespaces without meshed pods", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/v1/namespaces/emojivoto":
+				w.Write([]byte(`{"metadata":{"name":"emojivoto"}}`))
+			case "/api/v1/namespaces/emojivoto/pods":
+				w.Write([]byte(`{"items":[]}`))
+			case "/api/v1/namespaces/linkerd/endpoints/proxy-api":
+				w.Write([]byte(readyEndpoints))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		success, observed := runChecks(server)
+		expected := "linkerd-data-plane data plane has meshed pods: No \"linkerd-proxy\" containers found in the \"emojivoto\" namespace"
+		if !success || len(observed) < 2 || observed[1] != expected {
+			t.Fatalf("Expected a warning [%s], got %q", expected, observed)
+		}
+	})
+
+	t.Run("Reports proxies that do not respond to readiness requests", func(t *testing.T) {
+		server := dataPlaneServer(t, "data_plane_proxies_unready.json", readyEndpoints)
+		defer server.Close()
+
+		hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+		hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+		pods, err := hc.kubeAPI.GetPodsFor(context.Background(), "emojivoto", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		err = hc.checkProxyReadiness(context.Background(), pods)
+		expected := "1 of 3 proxies did not respond to readiness requests:\n\temojivoto/voting-7c9d8f6b4-fj2lm: pod [voting-7c9d8f6b4-fj2lm] is not ready (phase Running)"
+		if err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Fatalf("Expected error starting with [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Fails without ready destination endpoints", func(t *testing.T) {
+		server := dataPlaneServer(t, "data_plane_proxies_healthy.json", `{"subsets":[{"notReadyAddresses":[{"ip":"10.1.0.12"}]}]}`)
+		defer server.Close()
+
+		hc := NewHealthChecker([]Checks{}, &HealthCheckOptions{ControlPlaneNamespace: "linkerd"})
+		hc.kubeAPI = &k8s.KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		err := hc.checkDestinationService(context.Background())
+		expected := "The \"proxy-api\" service in the \"linkerd\" namespace has no ready endpoints, so proxies cannot discover destinations"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
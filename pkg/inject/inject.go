@@ -0,0 +1,356 @@
+// Package inject adds the Linkerd proxy and its init container to pod
+// templates and pods. It is shared by `linkerd inject`, which injects the
+// workloads of Kubernetes configs, and by the proxy injector, which injects
+// pods as they are created.
+package inject
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/ports"
+	"k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// LocalhostDNSNameOverride allows override of the controlPlaneDNS. This
+	// must be in absolute form for the proxy to special-case it.
+	LocalhostDNSNameOverride = "localhost."
+
+	// PodNamespaceEnvVarName is the name of the variable used to pass the
+	// pod's namespace.
+	PodNamespaceEnvVarName = "LINKERD2_PROXY_POD_NAMESPACE"
+
+	// The names of the volumes injected with TLS enabled.
+	trustAnchorsVolumeName = "linkerd-trust-anchors"
+	secretsVolumeName      = "linkerd-secrets"
+)
+
+// Config describes the proxy and init container to inject. It is recorded as
+// JSON by `linkerd install`, for the proxy injector to inject pods the way
+// `linkerd inject` would with the install flags.
+type Config struct {
+	ControlPlaneNamespace string `json:"controlPlaneNamespace"`
+
+	// Version is recorded in the ProxyVersionAnnotation of injected pods, and
+	// CreatedBy in their CreatedByAnnotation.
+	Version   string `json:"version"`
+	CreatedBy string `json:"createdBy"`
+
+	ProxyImage      string `json:"proxyImage"`
+	InitImage       string `json:"initImage"`
+	ImagePullPolicy string `json:"imagePullPolicy"`
+
+	ProxyUID    int64  `json:"proxyUID"`
+	LogLevel    string `json:"logLevel"`
+	BindTimeout string `json:"bindTimeout"`
+
+	APIPort      uint `json:"apiPort"`
+	ControlPort  uint `json:"controlPort"`
+	MetricsPort  uint `json:"metricsPort"`
+	InboundPort  uint `json:"inboundPort"`
+	OutboundPort uint `json:"outboundPort"`
+
+	Resources v1.ResourceRequirements `json:"resources"`
+	EnableTLS bool                    `json:"enableTLS"`
+
+	// OutboundCapacity sets the outbound router capacity of the proxy of the
+	// pods with a container running one of its images.
+	OutboundCapacity map[string]uint `json:"outboundCapacity,omitempty"`
+}
+
+// InjectObjectMeta updates the ObjectMeta of a pod or pod template in place
+// with the annotations and labels of injected pods, and the given labels.
+func (c *Config) InjectObjectMeta(t *metaV1.ObjectMeta, k8sLabels map[string]string) {
+	if t.Annotations == nil {
+		t.Annotations = make(map[string]string)
+	}
+	t.Annotations[k8s.CreatedByAnnotation] = c.CreatedBy
+	t.Annotations[k8s.ProxyVersionAnnotation] = c.Version
+
+	if t.Labels == nil {
+		t.Labels = make(map[string]string)
+	}
+	t.Labels[k8s.ControllerNSLabel] = c.ControlPlaneNamespace
+	for k, v := range k8sLabels {
+		t.Labels[k] = v
+	}
+}
+
+/* Given a PodSpec, update the PodSpec in place with the sidecar
+ * and init-container injected, with the traffic to the given ports skipping
+ * the proxy. If the pod is unsuitable for having them injected, return the
+ * reason why.
+ */
+func (c *Config) InjectPodSpec(t *v1.PodSpec, identity k8s.TLSIdentity, controlPlaneDNSNameOverride string, inboundSkipPorts, outboundSkipPorts []ports.Range) string {
+	// Pods with `hostNetwork=true` share a network namespace with the host. The
+	// init-container would destroy the iptables configuration on the host, so
+	// skip the injection in this case.
+	if t.HostNetwork {
+		return "pods with hostNetwork: true share the network of their node, which the init container would reconfigure"
+	}
+	for _, containers := range [][]v1.Container{t.Containers, t.InitContainers} {
+		for _, container := range containers {
+			if container.Name == k8s.ProxyContainerName || container.Name == k8s.InitContainerName {
+				return fmt.Sprintf("it has a container named %s, which the injected one would conflict with", container.Name)
+			}
+		}
+	}
+
+	f := false
+	inboundSkipPortsStr := make([]string, len(inboundSkipPorts))
+	for i, r := range inboundSkipPorts {
+		inboundSkipPortsStr[i] = r.String()
+	}
+	inboundSkipPortsStr = append(inboundSkipPortsStr,
+		strconv.Itoa(int(c.ControlPort)),
+		strconv.Itoa(int(c.MetricsPort)))
+
+	outboundSkipPortsStr := make([]string, len(outboundSkipPorts))
+	for i, r := range outboundSkipPorts {
+		outboundSkipPortsStr[i] = r.String()
+	}
+
+	initArgs := []string{
+		"--incoming-proxy-port", fmt.Sprintf("%d", c.InboundPort),
+		"--outgoing-proxy-port", fmt.Sprintf("%d", c.OutboundPort),
+		"--proxy-uid", fmt.Sprintf("%d", c.ProxyUID),
+	}
+
+	if len(inboundSkipPortsStr) > 0 {
+		initArgs = append(initArgs, "--inbound-ports-to-ignore")
+		initArgs = append(initArgs, strings.Join(inboundSkipPortsStr, ","))
+	}
+
+	if len(outboundSkipPortsStr) > 0 {
+		initArgs = append(initArgs, "--outbound-ports-to-ignore")
+		initArgs = append(initArgs, strings.Join(outboundSkipPortsStr, ","))
+	}
+
+	initContainer := v1.Container{
+		Name:                     k8s.InitContainerName,
+		Image:                    c.InitImage,
+		ImagePullPolicy:          v1.PullPolicy(c.ImagePullPolicy),
+		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+		Args:                     initArgs,
+		SecurityContext: &v1.SecurityContext{
+			Capabilities: &v1.Capabilities{
+				Add: []v1.Capability{v1.Capability("NET_ADMIN")},
+			},
+			Privileged: &f,
+		},
+	}
+	controlPlaneDNS := fmt.Sprintf("proxy-api.%s.svc.cluster.local", c.ControlPlaneNamespace)
+	if controlPlaneDNSNameOverride != "" {
+		controlPlaneDNS = controlPlaneDNSNameOverride
+	}
+
+	metricsPort := intstr.IntOrString{
+		IntVal: int32(c.MetricsPort),
+	}
+	proxyProbe := v1.Probe{
+		Handler: v1.Handler{
+			HTTPGet: &v1.HTTPGetAction{
+				Path: "/metrics",
+				Port: metricsPort,
+			},
+		},
+		InitialDelaySeconds: 10,
+	}
+
+	proxyUID := c.ProxyUID
+	sidecar := v1.Container{
+		Name:                     k8s.ProxyContainerName,
+		Image:                    c.ProxyImage,
+		ImagePullPolicy:          v1.PullPolicy(c.ImagePullPolicy),
+		TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+		SecurityContext: &v1.SecurityContext{
+			RunAsUser: &proxyUID,
+		},
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "linkerd-proxy",
+				ContainerPort: int32(c.InboundPort),
+			},
+			{
+				Name:          "linkerd-metrics",
+				ContainerPort: int32(c.MetricsPort),
+			},
+		},
+		Env: []v1.EnvVar{
+			{Name: "LINKERD2_PROXY_LOG", Value: c.LogLevel},
+			{Name: "LINKERD2_PROXY_BIND_TIMEOUT", Value: c.BindTimeout},
+			{
+				Name:  "LINKERD2_PROXY_CONTROL_URL",
+				Value: fmt.Sprintf("tcp://%s:%d", controlPlaneDNS, c.APIPort),
+			},
+			{Name: "LINKERD2_PROXY_CONTROL_LISTENER", Value: fmt.Sprintf("tcp://0.0.0.0:%d", c.ControlPort)},
+			{Name: "LINKERD2_PROXY_METRICS_LISTENER", Value: fmt.Sprintf("tcp://0.0.0.0:%d", c.MetricsPort)},
+			{Name: "LINKERD2_PROXY_PRIVATE_LISTENER", Value: fmt.Sprintf("tcp://127.0.0.1:%d", c.OutboundPort)},
+			{Name: "LINKERD2_PROXY_PUBLIC_LISTENER", Value: fmt.Sprintf("tcp://0.0.0.0:%d", c.InboundPort)},
+			{
+				Name:      PodNamespaceEnvVarName,
+				ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.namespace"}},
+			},
+		},
+		Resources:      *c.Resources.DeepCopy(),
+		ReadinessProbe: &proxyProbe,
+		LivenessProbe:  &proxyProbe,
+	}
+
+	// Special case if the caller specifies that
+	// LINKERD2_PROXY_OUTBOUND_ROUTER_CAPACITY be set on the pod.
+	// We key off of any container image in the pod. Ideally we would instead key
+	// off of something at the top-level of the PodSpec, but there is nothing
+	// easily identifiable at that level.
+	// This is currently only used by the Prometheus pod in the control-plane.
+	for _, container := range t.Containers {
+		if capacity, ok := c.OutboundCapacity[container.Image]; ok {
+			sidecar.Env = append(sidecar.Env,
+				v1.EnvVar{
+					Name:  "LINKERD2_PROXY_OUTBOUND_ROUTER_CAPACITY",
+					Value: fmt.Sprintf("%d", capacity),
+				},
+			)
+			break
+		}
+	}
+
+	if c.EnableTLS {
+		yes := true
+
+		configMapVolume := v1.Volume{
+			Name: trustAnchorsVolumeName,
+			VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{Name: k8s.TLSTrustAnchorConfigMapName},
+					Optional:             &yes,
+				},
+			},
+		}
+		secretVolume := v1.Volume{
+			Name: secretsVolumeName,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: identity.ToSecretName(),
+					Optional:   &yes,
+				},
+			},
+		}
+
+		base := "/var/linkerd-io"
+		configMapBase := base + "/trust-anchors"
+		secretBase := base + "/identity"
+		tlsEnvVars := []v1.EnvVar{
+			{Name: "LINKERD2_PROXY_TLS_TRUST_ANCHORS", Value: configMapBase + "/" + k8s.TLSTrustAnchorFileName},
+			{Name: "LINKERD2_PROXY_TLS_CERT", Value: secretBase + "/" + k8s.TLSCertFileName},
+			{Name: "LINKERD2_PROXY_TLS_PRIVATE_KEY", Value: secretBase + "/" + k8s.TLSPrivateKeyFileName},
+			{
+				Name:  "LINKERD2_PROXY_TLS_POD_IDENTITY",
+				Value: identity.ToDNSName(),
+			},
+			{Name: "LINKERD2_PROXY_CONTROLLER_NAMESPACE", Value: c.ControlPlaneNamespace},
+			{Name: "LINKERD2_PROXY_TLS_CONTROLLER_IDENTITY", Value: identity.ToControllerIdentity().ToDNSName()},
+		}
+
+		sidecar.Env = append(sidecar.Env, tlsEnvVars...)
+		sidecar.VolumeMounts = []v1.VolumeMount{
+			{Name: configMapVolume.Name, MountPath: configMapBase, ReadOnly: true},
+			{Name: secretVolume.Name, MountPath: secretBase, ReadOnly: true},
+		}
+
+		t.Volumes = append(t.Volumes, configMapVolume, secretVolume)
+	}
+
+	t.Containers = append(t.Containers, sidecar)
+	t.InitContainers = append(t.InitContainers, initContainer)
+
+	return ""
+}
+
+// IsInjected returns whether the pod or pod template was already injected,
+// either because it was annotated by inject, or because it has both of the
+// containers inject adds, in case its annotations were not kept.
+func IsInjected(t *metaV1.ObjectMeta, podSpec *v1.PodSpec) bool {
+	if _, ok := t.Annotations[k8s.ProxyVersionAnnotation]; ok {
+		return true
+	}
+	return hasContainer(podSpec.Containers, k8s.ProxyContainerName) &&
+		hasContainer(podSpec.InitContainers, k8s.InitContainerName)
+}
+
+func hasContainer(containers []v1.Container, name string) bool {
+	for _, container := range containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveProxy removes the containers and volumes injected by InjectPodSpec
+// from the pod spec, so that it can be injected again, and returns what it
+// removed. The annotations and labels of the pod template are left, as
+// injecting it again replaces them.
+func RemoveProxy(t *v1.PodSpec) []string {
+	removed := []string{}
+
+	containers := []v1.Container{}
+	for _, container := range t.Containers {
+		if container.Name == k8s.ProxyContainerName {
+			removed = append(removed, "container "+container.Name)
+			continue
+		}
+		containers = append(containers, container)
+	}
+	t.Containers = containers
+
+	var initContainers []v1.Container
+	for _, container := range t.InitContainers {
+		if container.Name == k8s.InitContainerName {
+			removed = append(removed, "init container "+container.Name)
+			continue
+		}
+		initContainers = append(initContainers, container)
+	}
+	t.InitContainers = initContainers
+
+	var volumes []v1.Volume
+	for _, volume := range t.Volumes {
+		if volume.Name == trustAnchorsVolumeName || volume.Name == secretsVolumeName {
+			removed = append(removed, "volume "+volume.Name)
+			continue
+		}
+		volumes = append(volumes, volume)
+	}
+	t.Volumes = volumes
+
+	return removed
+}
+
+// SkippedPorts returns the ports given by flag, or, if it is not set, those
+// listed in the annotation of the pod template, so that re-injecting it keeps
+// the ports it was injected with, and so that workloads can declare the ports
+// that skip the proxy themselves.
+func SkippedPorts(flag []string, t *metaV1.ObjectMeta, annotation string) ([]ports.Range, error) {
+	if len(flag) > 0 {
+		return ports.ParseRanges(flag)
+	}
+	skipped, err := ports.ParseList(t.Annotations[annotation])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", annotation, err)
+	}
+	return skipped, nil
+}
+
+// RecordSkippedPorts sets the annotation of the pod template to the ports that
+// skip the proxy, if there are any.
+func RecordSkippedPorts(t *metaV1.ObjectMeta, annotation string, skipped []ports.Range) {
+	if len(skipped) > 0 {
+		t.Annotations[annotation] = ports.FormatList(skipped)
+	}
+}
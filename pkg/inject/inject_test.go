@@ -0,0 +1,175 @@
+package inject
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	"github.com/linkerd/linkerd2/pkg/ports"
+	"k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testConfig() *Config {
+	return &Config{
+		ControlPlaneNamespace: "linkerd",
+		Version:               "testinjectversion",
+		CreatedBy:             "linkerd/cli testinjectversion",
+		ProxyImage:            "gcr.io/linkerd-io/proxy:testinjectversion",
+		InitImage:             "gcr.io/linkerd-io/proxy-init:testinjectversion",
+		ImagePullPolicy:       "IfNotPresent",
+		ProxyUID:              2102,
+		LogLevel:              "warn,linkerd2_proxy=info",
+		BindTimeout:           "10s",
+		APIPort:               8086,
+		ControlPort:           4190,
+		MetricsPort:           4191,
+		InboundPort:           4143,
+		OutboundPort:          4140,
+	}
+}
+
+func testIdentity() k8s.TLSIdentity {
+	return k8s.TLSIdentity{Name: "web", Kind: "deployment", Namespace: "emojivoto", ControllerNamespace: "linkerd"}
+}
+
+func TestInjectPodSpec(t *testing.T) {
+	t.Run("Adds the proxy and init containers", func(t *testing.T) {
+		podSpec := &v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: "buoyantio/emojivoto-web:v3"}}}
+		skipped := []ports.Range{{Lower: 25, Upper: 25}, {Lower: 4222, Upper: 4223}}
+		if reason := testConfig().InjectPodSpec(podSpec, testIdentity(), "", skipped, skipped[:1]); reason != "" {
+			t.Fatalf("Unexpected reason: %s", reason)
+		}
+
+		if len(podSpec.Containers) != 2 || podSpec.Containers[1].Name != k8s.ProxyContainerName {
+			t.Fatalf("Expected the proxy container to be appended, got %v", podSpec.Containers)
+		}
+		if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Name != k8s.InitContainerName {
+			t.Fatalf("Expected the init container to be added, got %v", podSpec.InitContainers)
+		}
+		expected := []string{
+			"--incoming-proxy-port", "4143",
+			"--outgoing-proxy-port", "4140",
+			"--proxy-uid", "2102",
+			"--inbound-ports-to-ignore", "25,4222-4223,4190,4191",
+			"--outbound-ports-to-ignore", "25",
+		}
+		if !reflect.DeepEqual(podSpec.InitContainers[0].Args, expected) {
+			t.Fatalf("Expected init container args %v, got %v", expected, podSpec.InitContainers[0].Args)
+		}
+		if len(podSpec.Volumes) != 0 {
+			t.Fatalf("Expected no volumes without TLS, got %v", podSpec.Volumes)
+		}
+	})
+
+	t.Run("Mounts the identity of the pod with TLS", func(t *testing.T) {
+		config := testConfig()
+		config.EnableTLS = true
+		podSpec := &v1.PodSpec{Containers: []v1.Container{{Name: "web"}}}
+		if reason := config.InjectPodSpec(podSpec, testIdentity(), "", nil, nil); reason != "" {
+			t.Fatalf("Unexpected reason: %s", reason)
+		}
+
+		if len(podSpec.Volumes) != 2 || podSpec.Volumes[1].Secret == nil || podSpec.Volumes[1].Secret.SecretName != "web-deployment-tls-linkerd-io" {
+			t.Fatalf("Expected the trust anchors and the secret of the pod to be mounted, got %v", podSpec.Volumes)
+		}
+	})
+
+	t.Run("Sets the outbound capacity of the pods running the given images", func(t *testing.T) {
+		config := testConfig()
+		config.OutboundCapacity = map[string]uint{"prom/prometheus:v2.3.1": 10000}
+		podSpec := &v1.PodSpec{Containers: []v1.Container{{Name: "prometheus", Image: "prom/prometheus:v2.3.1"}}}
+		config.InjectPodSpec(podSpec, testIdentity(), "", nil, nil)
+
+		env := podSpec.Containers[1].Env
+		if last := env[len(env)-1]; last.Name != "LINKERD2_PROXY_OUTBOUND_ROUTER_CAPACITY" || last.Value != "10000" {
+			t.Fatalf("Expected the outbound router capacity to be set, got %v", last)
+		}
+	})
+
+	t.Run("Skips pods that cannot be injected", func(t *testing.T) {
+		for _, podSpec := range []*v1.PodSpec{
+			{HostNetwork: true, Containers: []v1.Container{{Name: "web"}}},
+			{Containers: []v1.Container{{Name: "web"}, {Name: k8s.ProxyContainerName}}},
+			{Containers: []v1.Container{{Name: "web"}}, InitContainers: []v1.Container{{Name: k8s.InitContainerName}}},
+		} {
+			containers := len(podSpec.Containers)
+			if reason := testConfig().InjectPodSpec(podSpec, testIdentity(), "", nil, nil); reason == "" {
+				t.Fatalf("Expected a reason for not injecting %v", podSpec)
+			}
+			if len(podSpec.Containers) != containers {
+				t.Fatalf("Expected the pod spec not to be changed, got %v", podSpec.Containers)
+			}
+		}
+	})
+}
+
+func TestIsInjected(t *testing.T) {
+	injected := &v1.PodSpec{
+		Containers:     []v1.Container{{Name: "web"}, {Name: k8s.ProxyContainerName}},
+		InitContainers: []v1.Container{{Name: k8s.InitContainerName}},
+	}
+	annotated := &metaV1.ObjectMeta{Annotations: map[string]string{k8s.ProxyVersionAnnotation: "v1"}}
+
+	testCases := []struct {
+		objectMeta *metaV1.ObjectMeta
+		podSpec    *v1.PodSpec
+		expected   bool
+	}{
+		{&metaV1.ObjectMeta{}, &v1.PodSpec{Containers: []v1.Container{{Name: "web"}}}, false},
+		{annotated, &v1.PodSpec{Containers: []v1.Container{{Name: "web"}}}, true},
+		{&metaV1.ObjectMeta{}, injected, true},
+		{&metaV1.ObjectMeta{}, &v1.PodSpec{Containers: injected.Containers}, false},
+	}
+
+	for i, tc := range testCases {
+		if injected := IsInjected(tc.objectMeta, tc.podSpec); injected != tc.expected {
+			t.Fatalf("test case %d: expected %t, got %t", i, tc.expected, injected)
+		}
+	}
+}
+
+func TestRemoveProxy(t *testing.T) {
+	config := testConfig()
+	config.EnableTLS = true
+	podSpec := &v1.PodSpec{
+		Containers: []v1.Container{{Name: "web"}},
+		Volumes:    []v1.Volume{{Name: "data"}},
+	}
+	original := podSpec.DeepCopy()
+	config.InjectPodSpec(podSpec, testIdentity(), "", nil, nil)
+
+	removed := RemoveProxy(podSpec)
+	expected := []string{"container linkerd-proxy", "init container linkerd-init", "volume linkerd-trust-anchors", "volume linkerd-secrets"}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Fatalf("Expected %v to be removed, got %v", expected, removed)
+	}
+	if !reflect.DeepEqual(podSpec, original) {
+		t.Fatalf("Expected the pod spec to be restored to %v, got %v", original, podSpec)
+	}
+}
+
+func TestSkippedPorts(t *testing.T) {
+	objectMeta := &metaV1.ObjectMeta{Annotations: map[string]string{k8s.ProxySkipInboundPortsAnnotation: "25,4222-4223"}}
+
+	skipped, err := SkippedPorts(nil, objectMeta, k8s.ProxySkipInboundPortsAnnotation)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ports.FormatList(skipped) != "25,4222-4223" {
+		t.Fatalf("Expected the annotated ports, got %v", skipped)
+	}
+
+	skipped, err = SkippedPorts([]string{"3306"}, objectMeta, k8s.ProxySkipInboundPortsAnnotation)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ports.FormatList(skipped) != "3306" {
+		t.Fatalf("Expected the ports given to replace the annotated ones, got %v", skipped)
+	}
+
+	objectMeta.Annotations[k8s.ProxySkipInboundPortsAnnotation] = "http"
+	if _, err := SkippedPorts(nil, objectMeta, k8s.ProxySkipInboundPortsAnnotation); err == nil {
+		t.Fatalf("Expected an error for an invalid annotation")
+	}
+}
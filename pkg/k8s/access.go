@@ -0,0 +1,160 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+)
+
+// maxConcurrentAccessReviews bounds the number of SelfSubjectAccessReviews
+// CanIAll has in flight at once.
+const maxConcurrentAccessReviews = 5
+
+// ResourceCheck describes an action to be verified with CanI. Group is empty
+// for the core API group, and Namespace is empty for cluster-scoped resources
+// or for an action in all namespaces.
+type ResourceCheck struct {
+	Verb      string
+	Group     string
+	Resource  string
+	Namespace string
+}
+
+func (c ResourceCheck) String() string {
+	resource := c.Resource
+	if c.Group != "" {
+		resource = c.Resource + "." + c.Group
+	}
+	if c.Namespace != "" {
+		return fmt.Sprintf("%s %s in namespace %s", c.Verb, resource, c.Namespace)
+	}
+	return fmt.Sprintf("%s %s", c.Verb, resource)
+}
+
+// ResourceCheckResult is the outcome of a ResourceCheck. Reason is the
+// explanation given by the authorizer, if any.
+type ResourceCheckResult struct {
+	ResourceCheck
+	Allowed bool
+	Reason  string
+}
+
+// CanI reports whether the current identity is allowed to perform verb on
+// resource, using a SelfSubjectAccessReview. The returned string is the
+// reason given by the authorizer, which is often empty when the action is
+// allowed.
+func (kubeAPI *KubernetesAPI) CanI(ctx context.Context, verb, group, resource, namespace string) (bool, string, error) {
+	review := authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:      verb,
+				Group:     group,
+				Resource:  resource,
+				Namespace: namespace,
+			},
+		},
+	}
+	review.APIVersion = "authorization.k8s.io/v1"
+	review.Kind = "SelfSubjectAccessReview"
+
+	var result authorizationv1.SelfSubjectAccessReview
+	err := kubeAPI.postJSON(ctx, "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews", &review, &result)
+	if IsNotFound(err) {
+		return false, "", fmt.Errorf("the Kubernetes API does not serve authorization.k8s.io/v1 SelfSubjectAccessReviews: %s", err)
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	if result.Status.EvaluationError != "" && result.Status.Reason == "" {
+		return result.Status.Allowed, result.Status.EvaluationError, nil
+	}
+	return result.Status.Allowed, result.Status.Reason, nil
+}
+
+// CanIAll runs CanI for each of checks, returning the results in the same
+// order. Duplicate checks are only reviewed once, and at most
+// maxConcurrentAccessReviews reviews are in flight at a time. If any review
+// fails, the first error is returned.
+func (kubeAPI *KubernetesAPI) CanIAll(ctx context.Context, checks []ResourceCheck) ([]ResourceCheckResult, error) {
+	var unique []ResourceCheck
+	index := make(map[ResourceCheck]int)
+	for _, check := range checks {
+		if _, ok := index[check]; !ok {
+			index[check] = len(unique)
+			unique = append(unique, check)
+		}
+	}
+
+	results := make([]ResourceCheckResult, len(unique))
+	errs := make([]error, len(unique))
+	sem := make(chan struct{}, maxConcurrentAccessReviews)
+
+	var wg sync.WaitGroup
+	for i, check := range unique {
+		wg.Add(1)
+		go func(i int, check ResourceCheck) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			allowed, reason, err := kubeAPI.CanI(ctx, check.Verb, check.Group, check.Resource, check.Namespace)
+			results[i] = ResourceCheckResult{ResourceCheck: check, Allowed: allowed, Reason: reason}
+			errs[i] = err
+		}(i, check)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("error checking whether you can %s: %s", unique[i], err)
+		}
+	}
+
+	ordered := make([]ResourceCheckResult, len(checks))
+	for i, check := range checks {
+		ordered[i] = results[index[check]]
+	}
+	return ordered, nil
+}
+
+// CanIInNamespaces reports the namespaces in which the current identity is
+// allowed to perform verb on resource. If it is allowed to in all namespaces,
+// both returned lists are nil. Otherwise, the namespaces of the cluster are
+// split between those in which it is allowed to, and those in which it is
+// not, which requires the identity to be allowed to list namespaces.
+func (kubeAPI *KubernetesAPI) CanIInNamespaces(ctx context.Context, verb, group, resource string) ([]string, []string, error) {
+	allNamespaces := ResourceCheck{Verb: verb, Group: group, Resource: resource}
+	allowed, _, err := kubeAPI.CanI(ctx, verb, group, resource, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error checking whether you can %s: %s", allNamespaces, err)
+	}
+	if allowed {
+		return nil, nil, nil
+	}
+
+	namespaces, err := kubeAPI.ListNamespaces(ctx, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("you are not allowed to %s in all namespaces, and the namespaces could not be listed: %s", allNamespaces, err)
+	}
+	checks := make([]ResourceCheck, len(namespaces))
+	for i, namespace := range namespaces {
+		checks[i] = ResourceCheck{Verb: verb, Group: group, Resource: resource, Namespace: namespace.Name}
+	}
+	results, err := kubeAPI.CanIAll(ctx, checks)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allowedIn, deniedIn := []string{}, []string{}
+	for _, result := range results {
+		if result.Allowed {
+			allowedIn = append(allowedIn, result.Namespace)
+		} else {
+			deniedIn = append(deniedIn, result.Namespace)
+		}
+	}
+	return allowedIn, deniedIn, nil
+}
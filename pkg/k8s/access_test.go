@@ -0,0 +1,200 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/rest"
+)
+
+// accessReviewServer answers SelfSubjectAccessReviews by looking up the
+// requested resource in denied, allowing everything else.
+func accessReviewServer(t *testing.T, denied map[string]string) (*httptest.Server, func() []authorizationv1.ResourceAttributes) {
+	var mu sync.Mutex
+	var reviewed []authorizationv1.ResourceAttributes
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews" {
+			t.Fatalf("Unexpected request [%s %s]", r.Method, r.URL.Path)
+		}
+
+		var review authorizationv1.SelfSubjectAccessReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			t.Fatalf("Unexpected error decoding review: %v", err)
+		}
+		attrs := review.Spec.ResourceAttributes
+
+		mu.Lock()
+		reviewed = append(reviewed, *attrs)
+		mu.Unlock()
+
+		reason, isDenied := denied[attrs.Resource]
+		review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: !isDenied, Reason: reason}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(review)
+	}))
+
+	return server, func() []authorizationv1.ResourceAttributes {
+		mu.Lock()
+		defer mu.Unlock()
+		return reviewed
+	}
+}
+
+func TestCanI(t *testing.T) {
+	t.Run("Returns allowed for permitted actions", func(t *testing.T) {
+		server, reviewed := accessReviewServer(t, nil)
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		allowed, _, err := api.CanI(context.Background(), "create", "apps", "deployments", "linkerd")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !allowed {
+			t.Fatalf("Expected action to be allowed")
+		}
+
+		expected := authorizationv1.ResourceAttributes{Verb: "create", Group: "apps", Resource: "deployments", Namespace: "linkerd"}
+		if attrs := reviewed(); len(attrs) != 1 || attrs[0] != expected {
+			t.Fatalf("Expected review of %+v, got %+v", expected, attrs)
+		}
+	})
+
+	t.Run("Returns the reason for denied actions", func(t *testing.T) {
+		server, _ := accessReviewServer(t, map[string]string{
+			"clusterroles": `no RBAC policy matched`,
+		})
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		allowed, reason, err := api.CanI(context.Background(), "create", "rbac.authorization.k8s.io", "clusterroles", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if allowed {
+			t.Fatalf("Expected action to be denied")
+		}
+		if reason != "no RBAC policy matched" {
+			t.Fatalf("Unexpected reason [%s]", reason)
+		}
+	})
+
+	t.Run("Explains when the API group is not served", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"the server could not find the requested resource","reason":"NotFound","code":404}`))
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		_, _, err := api.CanI(context.Background(), "create", "", "namespaces", "")
+		if err == nil || !strings.Contains(err.Error(), "does not serve authorization.k8s.io/v1") {
+			t.Fatalf("Expected an API group error, got [%v]", err)
+		}
+	})
+}
+
+func TestCanIAll(t *testing.T) {
+	server, reviewed := accessReviewServer(t, map[string]string{
+		"customresourcedefinitions": "forbidden by policy",
+	})
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	checks := []ResourceCheck{
+		{Verb: "create", Resource: "namespaces"},
+		{Verb: "create", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+		{Verb: "create", Group: "apps", Resource: "deployments", Namespace: "linkerd"},
+		{Verb: "create", Resource: "namespaces"},
+	}
+
+	results, err := api.CanIAll(context.Background(), checks)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(reviewed()) != 3 {
+		t.Fatalf("Expected duplicate checks to be reviewed once, got %d reviews", len(reviewed()))
+	}
+	if len(results) != len(checks) {
+		t.Fatalf("Expected %d results, got %d", len(checks), len(results))
+	}
+
+	expected := []bool{true, false, true, true}
+	for i, result := range results {
+		if result.ResourceCheck != checks[i] {
+			t.Fatalf("Expected result %d to be for [%s], got [%s]", i, checks[i], result.ResourceCheck)
+		}
+		if result.Allowed != expected[i] {
+			t.Fatalf("Expected [%s] allowed to be [%t], got [%t]", checks[i], expected[i], result.Allowed)
+		}
+	}
+	if results[1].Reason != "forbidden by policy" {
+		t.Fatalf("Unexpected reason [%s]", results[1].Reason)
+	}
+}
+
+func TestCanIInNamespaces(t *testing.T) {
+	// namespacedServer lists the books, default and emojivoto namespaces, and
+	// allows listing pods in the namespaces in allowed only.
+	namespacedServer := func(allowed map[string]bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" && r.URL.Path == "/api/v1/namespaces" {
+				w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"books"}},{"metadata":{"name":"default"}},{"metadata":{"name":"emojivoto"}}]}`))
+				return
+			}
+
+			var review authorizationv1.SelfSubjectAccessReview
+			if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+				t.Fatalf("Unexpected error decoding review: %v", err)
+			}
+			review.Status = authorizationv1.SubjectAccessReviewStatus{Allowed: allowed[review.Spec.ResourceAttributes.Namespace]}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(review)
+		}))
+	}
+
+	t.Run("Returns no namespaces if allowed in all namespaces", func(t *testing.T) {
+		server := namespacedServer(map[string]bool{"": true})
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		allowed, denied, err := api.CanIInNamespaces(context.Background(), "list", "", "pods")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if allowed != nil || denied != nil {
+			t.Fatalf("Expected no namespaces, got %v and %v", allowed, denied)
+		}
+	})
+
+	t.Run("Splits the namespaces otherwise", func(t *testing.T) {
+		server := namespacedServer(map[string]bool{"books": true, "emojivoto": true})
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		allowed, denied, err := api.CanIInNamespaces(context.Background(), "list", "", "pods")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Join(allowed, ",") != "books,emojivoto" {
+			t.Fatalf("Expected to be allowed in [books emojivoto], got %v", allowed)
+		}
+		if strings.Join(denied, ",") != "default" {
+			t.Fatalf("Expected to be denied in [default], got %v", denied)
+		}
+	})
+}
@@ -1,80 +1,413 @@
 package k8s
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/http/httpproxy"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/rest"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/flowcontrol"
 
 	// Load all the auth plugins for the cloud providers.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
-var minApiVersion = [3]int{1, 8, 0}
+// MinAPIVersion is the minimum Kubernetes version required by Linkerd.
+var MinAPIVersion = [3]int{1, 8, 0}
+
+// MaxTestedAPIVersion is the most recent Kubernetes minor version Linkerd has
+// been tested with. Its patch number is ignored.
+var MaxTestedAPIVersion = [3]int{1, 11, 0}
+
+// DefaultRequestTimeout is the timeout applied to each request made by
+// KubernetesAPI when no Timeout is configured.
+const DefaultRequestTimeout = 5 * time.Second
+
+const (
+	// DefaultRetryAttempts is the number of times KubernetesAPI attempts a
+	// request that fails transiently, when no RetryAttempts is configured.
+	DefaultRetryAttempts = 3
+
+	// DefaultRetryBaseDelay is the delay before the first retry, when no
+	// RetryBaseDelay is configured. Each following retry doubles the delay.
+	DefaultRetryBaseDelay = 100 * time.Millisecond
+)
 
 type KubernetesAPI struct {
 	*rest.Config
+
+	// Timeout bounds the duration of each request to the Kubernetes API. A zero
+	// value means DefaultRequestTimeout.
+	Timeout time.Duration
+
+	// RetryAttempts is the maximum number of attempts made for a request that
+	// fails with a connection error, a 429 or a 5xx response. A value of 1
+	// disables retries, and a zero value means DefaultRetryAttempts.
+	RetryAttempts int
+
+	// RetryBaseDelay is the delay before the first retry, doubled for each
+	// following retry and jittered. A zero value means DefaultRetryBaseDelay.
+	RetryBaseDelay time.Duration
+
+	// LogBodies additionally logs the headers and bodies of requests and
+	// responses, with credentials redacted, when debug logging is enabled.
+	LogBodies bool
+
+	// MaxEvents is the maximum number of events returned by GetEventsFor. A
+	// zero value means DefaultMaxEvents.
+	MaxEvents int
+
+	clientOnce sync.Once
+	client     *http.Client
+	clientErr  error
+
+	versionMu   sync.Mutex
+	versionInfo *version.Info
+
+	openShiftMu sync.Mutex
+	openShift   *bool
 }
 
+// APIOptions configures the KubernetesAPI returned by NewAPI. A nil
+// *APIOptions is equivalent to the zero value.
+type APIOptions struct {
+	// KubeContext selects a context from the kubeconfig. An empty value means
+	// the kubeconfig's current-context.
+	KubeContext string
+
+	// APIServerAddr overrides the API server URL from the kubeconfig (e.g.
+	// https://10.0.0.1:6443), while keeping its TLS and auth settings.
+	APIServerAddr string
+
+	// CAFile is the path to a PEM-encoded CA bundle used to verify the API
+	// server's certificate, replacing the kubeconfig's certificate authority.
+	CAFile string
+
+	// Insecure disables verification of the API server's certificate. It may
+	// not be combined with CAFile.
+	Insecure bool
+
+	// Impersonate is the user to impersonate for every request. An empty value
+	// disables impersonation.
+	Impersonate string
+
+	// ImpersonateGroups are the groups to impersonate for every request. They
+	// may only be set together with Impersonate.
+	ImpersonateGroups []string
+
+	// Token is a bearer token used to authenticate to the API server. It takes
+	// precedence over any credentials declared in the kubeconfig.
+	Token string
+
+	// TokenFile is the path to a file containing a bearer token, read again
+	// whenever it changes. It may not be combined with Token.
+	TokenFile string
+
+	// QPS and Burst limit the rate of requests made to the Kubernetes API.
+	// Zero values keep client-go's defaults.
+	QPS   float32
+	Burst int
+
+	// Timeout bounds the duration of each request to the Kubernetes API. A zero
+	// value means DefaultRequestTimeout.
+	Timeout time.Duration
+
+	// UserAgent is sent with every request to the Kubernetes API. An empty
+	// value means CLIUserAgent().
+	UserAgent string
+
+	// LogBodies additionally logs the headers and bodies of requests and
+	// responses, with credentials redacted, when debug logging is enabled.
+	LogBodies bool
+}
+
+// transportFor builds the transport used by NewClient. It is a variable so
+// that tests can inspect the config it is called with.
+var transportFor = newTransport
+
+// newTransport mirrors rest.TransportFor, but always builds its own base
+// transport so that the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables are honored, including IP addresses and CIDR ranges in NO_PROXY
+// for in-cluster addresses.
+func newTransport(config *rest.Config) (http.RoundTripper, error) {
+	if config.Transport != nil {
+		return rest.TransportFor(config)
+	}
+
+	tlsConfig, err := rest.TLSConfigFor(config)
+	if err != nil {
+		return nil, err
+	}
+
+	dial := config.Dial
+	if dial == nil {
+		dial = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+
+	base := utilnet.SetTransportDefaults(&http.Transport{
+		Proxy:               proxyFromEnvironment,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     tlsConfig,
+		DialContext:         dial,
+	})
+
+	return rest.HTTPWrappersForConfig(config, base)
+}
+
+// proxyFromEnvironment behaves like http.ProxyFromEnvironment, but reads the
+// environment on every call rather than only once per process, and also
+// matches IP addresses in NO_PROXY as described by noProxyMatchesIP.
+func proxyFromEnvironment(req *http.Request) (*url.URL, error) {
+	config := httpproxy.FromEnvironment()
+	if noProxyMatchesIP(config.NoProxy, req.URL.Hostname()) {
+		return nil, nil
+	}
+	return config.ProxyFunc()(req.URL)
+}
+
+// noProxyMatchesIP reports whether host is an IP address excluded from
+// proxying by an IP address or CIDR range in noProxy. httpproxy only matches
+// IPv6 entries written exactly like the bracketed host of the request, so here
+// entries may be bracketed or not, with or without a port, and zone
+// identifiers, as in fe80::1%eth0, are ignored on both sides.
+func noProxyMatchesIP(noProxy, host string) bool {
+	ip := net.ParseIP(stripZone(host))
+	if ip == nil {
+		return false
+	}
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.TrimSpace(entry)
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+
+		if entryHost, _, err := net.SplitHostPort(entry); err == nil {
+			entry = entryHost
+		}
+		entry = strings.TrimSuffix(strings.TrimPrefix(entry, "["), "]")
+		if entryIP := net.ParseIP(stripZone(entry)); entryIP != nil && entryIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func stripZone(host string) string {
+	if i := strings.IndexByte(host, '%'); i >= 0 {
+		return host[:i]
+	}
+	return host
+}
+
+// NewClient returns a new HTTP client for the Kubernetes API. When debug
+// logging is enabled, every request it makes is logged. Most callers should use
+// Client instead, which reuses connections across calls.
 func (kubeAPI *KubernetesAPI) NewClient() (*http.Client, error) {
-	secureTransport, err := rest.TransportFor(kubeAPI.Config)
+	config := kubeAPI.Config
+	if log.GetLevel() >= log.DebugLevel {
+		// Log requests closest to the wire, so that the headers added by the
+		// auth and impersonation wrappers are included.
+		configCopy := *kubeAPI.Config
+		wrap := configCopy.WrapTransport
+		logBodies := kubeAPI.LogBodies
+		configCopy.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+			rt = &loggingTransport{rt: rt, logBodies: logBodies}
+			if wrap != nil {
+				rt = wrap(rt)
+			}
+			return rt
+		}
+		config = &configCopy
+	}
+
+	secureTransport, err := transportFor(config)
 	if err != nil {
 		return nil, fmt.Errorf("error instantiating Kubernetes API client: %v", err)
 	}
 
+	// rest.TransportFor does not apply the rate limits configured on the
+	// rest.Config, since those are normally enforced by the REST client.
+	if kubeAPI.QPS > 0 {
+		burst := kubeAPI.Burst
+		if burst <= 0 {
+			burst = rest.DefaultBurst
+		}
+		secureTransport = &rateLimitedTransport{
+			limiter: flowcontrol.NewTokenBucketRateLimiter(kubeAPI.QPS, burst),
+			rt:      secureTransport,
+		}
+	}
+
 	return &http.Client{
 		Transport: secureTransport,
 	}, nil
 }
 
-func (kubeAPI *KubernetesAPI) GetVersionInfo(client *http.Client) (*version.Info, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// Client returns the HTTP client used by the KubernetesAPI methods. It is
+// created on first use and shared afterwards, so that connections and TLS
+// sessions are reused. Changes to the config after the first call have no
+// effect on the client.
+func (kubeAPI *KubernetesAPI) Client() (*http.Client, error) {
+	kubeAPI.clientOnce.Do(func() {
+		kubeAPI.client, kubeAPI.clientErr = kubeAPI.NewClient()
+	})
+	return kubeAPI.client, kubeAPI.clientErr
+}
+
+type rateLimitedTransport struct {
+	limiter flowcontrol.RateLimiter
+	rt      http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.limiter.Accept()
+	return t.rt.RoundTrip(req)
+}
+
+// GetVersionInfo returns version.Info for the Kubernetes cluster. The request
+// is bound to ctx, and is additionally subject to the configured Timeout. The
+// first successful result is cached, and returned by later calls until
+// InvalidateVersionCache is called.
+func (kubeAPI *KubernetesAPI) GetVersionInfo(ctx context.Context) (*version.Info, error) {
+	kubeAPI.versionMu.Lock()
+	defer kubeAPI.versionMu.Unlock()
+
+	if kubeAPI.versionInfo == nil {
+		client, err := kubeAPI.Client()
+		if err != nil {
+			return nil, err
+		}
+
+		versionInfo, err := kubeAPI.GetVersionInfoWithClient(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		kubeAPI.versionInfo = versionInfo
+	}
+
+	versionInfo := *kubeAPI.versionInfo
+	return &versionInfo, nil
+}
+
+// InvalidateVersionCache clears the version.Info cached by GetVersionInfo, so
+// that the next call queries the cluster again. Long-running processes can use
+// it to pick up cluster upgrades.
+func (kubeAPI *KubernetesAPI) InvalidateVersionCache() {
+	kubeAPI.versionMu.Lock()
+	defer kubeAPI.versionMu.Unlock()
+
+	kubeAPI.versionInfo = nil
+}
+
+// GetVersionInfoWithClient is like GetVersionInfo, but uses the given client.
+//
+// Deprecated: use GetVersionInfo, which reuses the client returned by Client.
+func (kubeAPI *KubernetesAPI) GetVersionInfoWithClient(ctx context.Context, client *http.Client) (*version.Info, error) {
+	versionInfo, _, err := kubeAPI.getVersionInfo(ctx, client)
+	return versionInfo, err
+}
+
+// getVersionInfo is like GetVersionInfoWithClient, but also returns the time
+// in the Date header of the response, which is zero if the header is missing
+// or invalid.
+func (kubeAPI *KubernetesAPI) getVersionInfo(ctx context.Context, client *http.Client) (*version.Info, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, kubeAPI.requestTimeout())
 	defer cancel()
 
 	rsp, err := kubeAPI.getRequest(ctx, client, "/version")
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 	defer rsp.Body.Close()
 
 	if rsp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Unexpected Kubernetes API response: %s", rsp.Status)
+		return nil, time.Time{}, kubeAPI.responseError(rsp)
 	}
 
 	bytes, err := ioutil.ReadAll(rsp.Body)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 
 	var versionInfo version.Info
-	err = json.Unmarshal(bytes, &versionInfo)
-	return &versionInfo, err
+	if err := json.Unmarshal(bytes, &versionInfo); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	date, _ := http.ParseTime(rsp.Header.Get("Date"))
+	return &versionInfo, date, nil
 }
 
+// CheckVersion validates that the Kubernetes cluster is on MinAPIVersion or a
+// more recent version. A pre-release of MinAPIVersion, or a version more recent
+// than MaxTestedAPIVersion, is accepted, but logs a warning. Use the
+// CheckVersion function to check against other versions, or to inspect the
+// parsed version.
 func (kubeAPI *KubernetesAPI) CheckVersion(versionInfo *version.Info) error {
-	apiVersion, err := getK8sVersion(versionInfo.String())
+	result, err := CheckVersion(versionInfo, MinAPIVersion, MaxTestedAPIVersion)
 	if err != nil {
 		return err
 	}
 
-	if !isCompatibleVersion(minApiVersion, apiVersion) {
-		return fmt.Errorf("Kubernetes is on version [%d.%d.%d], but version [%d.%d.%d] or more recent is required",
-			apiVersion[0], apiVersion[1], apiVersion[2],
-			minApiVersion[0], minApiVersion[1], minApiVersion[2])
+	if result.Warning != "" {
+		log.Warn(result.Warning)
 	}
 
-	return nil
+	return result.Err()
+}
+
+// NamespaceExists validates whether a given namespace exists. The request is
+// bound to ctx, and is additionally subject to the configured Timeout. If the
+// caller is not authorized to get the namespace, the returned error satisfies
+// IsForbidden.
+func (kubeAPI *KubernetesAPI) NamespaceExists(ctx context.Context, namespace string) (bool, error) {
+	_, err := kubeAPI.GetNamespace(ctx, namespace)
+	return existsFromError(err)
 }
 
-func (kubeAPI *KubernetesAPI) NamespaceExists(client *http.Client, namespace string) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// existsFromError converts the error returned by a GET for a single object into
+// the result of an existence check: a NotFound error means the object does not
+// exist, and any other error is returned as is.
+func existsFromError(err error) (bool, error) {
+	if IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// NamespaceExistsWithClient is like NamespaceExists, but uses the given client.
+//
+// Deprecated: use NamespaceExists, which reuses the client returned by Client.
+func (kubeAPI *KubernetesAPI) NamespaceExistsWithClient(ctx context.Context, client *http.Client, namespace string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, kubeAPI.requestTimeout())
 	defer cancel()
 
 	rsp, err := kubeAPI.getRequest(ctx, client, "/api/v1/namespaces/"+namespace)
@@ -84,44 +417,63 @@ func (kubeAPI *KubernetesAPI) NamespaceExists(client *http.Client, namespace str
 	defer rsp.Body.Close()
 
 	if rsp.StatusCode != http.StatusOK && rsp.StatusCode != http.StatusNotFound {
-		return false, fmt.Errorf("Unexpected Kubernetes API response: %s", rsp.Status)
+		return false, kubeAPI.responseError(rsp)
 	}
 
 	return rsp.StatusCode == http.StatusOK, nil
 }
 
 // GetPodsByNamespace returns all pods in a given namespace
-func (kubeAPI *KubernetesAPI) GetPodsByNamespace(client *http.Client, namespace string) ([]v1.Pod, error) {
-	return kubeAPI.getPods(client, "/api/v1/namespaces/"+namespace+"/pods")
+func (kubeAPI *KubernetesAPI) GetPodsByNamespace(ctx context.Context, namespace string) ([]v1.Pod, error) {
+	client, err := kubeAPI.Client()
+	if err != nil {
+		return nil, err
+	}
+	return kubeAPI.GetPodsByNamespaceWithClient(ctx, client, namespace)
+}
+
+// GetPodsByNamespaceWithClient is like GetPodsByNamespace, but uses the given
+// client.
+//
+// Deprecated: use GetPodsByNamespace, which reuses the client returned by
+// Client.
+func (kubeAPI *KubernetesAPI) GetPodsByNamespaceWithClient(ctx context.Context, client *http.Client, namespace string) ([]v1.Pod, error) {
+	return kubeAPI.getPods(ctx, client, "/api/v1/namespaces/"+namespace+"/pods", nil)
 }
 
 // GetPodsByControllerNamespace returns all pods that have been injected to
 // interface with a given controllerNamespace. If targetNamespace is provided,
 // only pods from that namespace are returned.
-func (kubeAPI *KubernetesAPI) GetPodsByControllerNamespace(client *http.Client, controllerNamespace, targetNamespace string) ([]v1.Pod, error) {
-	selector := url.QueryEscape(fmt.Sprintf("%s=%s", ControllerNSLabel, controllerNamespace))
-	var path string
-	if targetNamespace == "" {
-		path = "/api/v1/pods"
-	} else {
-		path = "/api/v1/namespaces/" + targetNamespace + "/pods"
+func (kubeAPI *KubernetesAPI) GetPodsByControllerNamespace(ctx context.Context, controllerNamespace, targetNamespace string) ([]v1.Pod, error) {
+	client, err := kubeAPI.Client()
+	if err != nil {
+		return nil, err
 	}
+	return kubeAPI.GetPodsByControllerNamespaceWithClient(ctx, client, controllerNamespace, targetNamespace)
+}
 
-	return kubeAPI.getPods(client, fmt.Sprintf("%s?labelSelector=%s", path, selector))
+// GetPodsByControllerNamespaceWithClient is like GetPodsByControllerNamespace,
+// but uses the given client.
+//
+// Deprecated: use GetPodsByControllerNamespace, which reuses the client
+// returned by Client.
+func (kubeAPI *KubernetesAPI) GetPodsByControllerNamespaceWithClient(ctx context.Context, client *http.Client, controllerNamespace, targetNamespace string) ([]v1.Pod, error) {
+	opts := ListOptions{LabelSelector: fmt.Sprintf("%s=%s", ControllerNSLabel, controllerNamespace)}
+	return kubeAPI.getPods(ctx, client, podsPath(targetNamespace), opts.Values())
 }
 
-func (kubeAPI *KubernetesAPI) getPods(client *http.Client, path string) ([]v1.Pod, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (kubeAPI *KubernetesAPI) getPods(ctx context.Context, client *http.Client, path string, query url.Values) ([]v1.Pod, error) {
+	ctx, cancel := context.WithTimeout(ctx, kubeAPI.requestTimeout())
 	defer cancel()
 
-	rsp, err := kubeAPI.getRequest(ctx, client, path)
+	rsp, err := kubeAPI.doRequest(ctx, client, "GET", path, query, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer rsp.Body.Close()
 
 	if rsp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Unexpected Kubernetes API response: %s", rsp.Status)
+		return nil, kubeAPI.responseError(rsp)
 	}
 
 	bytes, err := ioutil.ReadAll(rsp.Body)
@@ -138,32 +490,424 @@ func (kubeAPI *KubernetesAPI) getPods(client *http.Client, path string) ([]v1.Po
 	return podList.Items, nil
 }
 
-// UrlFor generates a URL based on the Kubernetes config.
+// getJSON GETs path with the client returned by Client, and decodes the JSON
+// response into obj. The request is bound to ctx, and is additionally subject
+// to the configured Timeout.
+func (kubeAPI *KubernetesAPI) getJSON(ctx context.Context, path string, obj interface{}) error {
+	return kubeAPI.requestJSON(ctx, "GET", path, nil, nil, obj)
+}
+
+// listJSON is like getJSON, but passes opts as query parameters, for list
+// requests.
+func (kubeAPI *KubernetesAPI) listJSON(ctx context.Context, path string, opts ListOptions, obj interface{}) error {
+	return kubeAPI.requestJSON(ctx, "GET", path, opts.Values(), nil, obj)
+}
+
+// deleteJSON is like getJSON, but DELETEs the object at path, sending in,
+// encoded as JSON, as the DeleteOptions, and accepts a 202 response as well as
+// a 200.
+func (kubeAPI *KubernetesAPI) deleteJSON(ctx context.Context, path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return kubeAPI.requestJSON(ctx, "DELETE", path, nil, body, out)
+}
+
+// postJSON is like getJSON, but POSTs in, encoded as JSON, and accepts a 201
+// response as well as a 200.
+func (kubeAPI *KubernetesAPI) postJSON(ctx context.Context, path string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return kubeAPI.requestJSON(ctx, "POST", path, nil, body, out)
+}
+
+func (kubeAPI *KubernetesAPI) requestJSON(ctx context.Context, method, path string, query url.Values, body []byte, obj interface{}) error {
+	client, err := kubeAPI.Client()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, kubeAPI.requestTimeout())
+	defer cancel()
+
+	rsp, err := kubeAPI.doRequest(ctx, client, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	created := method == "POST" && rsp.StatusCode == http.StatusCreated
+	accepted := method == "DELETE" && rsp.StatusCode == http.StatusAccepted
+	if rsp.StatusCode != http.StatusOK && !created && !accepted {
+		return kubeAPI.responseError(rsp)
+	}
+
+	bytes, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(bytes, obj)
+}
+
+// UrlFor generates a URL based on the Kubernetes config, for a resource in the
+// core API group. extraPathStartingWithSlash must not contain a query string;
+// use UrlForGroupVersion to add query parameters.
 func (kubeAPI *KubernetesAPI) UrlFor(namespace string, extraPathStartingWithSlash string) (*url.URL, error) {
-	return generateKubernetesApiBaseUrlFor(kubeAPI.Host, namespace, extraPathStartingWithSlash)
+	return kubeAPI.UrlForGroupVersion(namespace, "", "v1", extraPathStartingWithSlash, nil)
+}
+
+// UrlForGroupVersion is like UrlFor, but for a resource in the given API group
+// and version, e.g. "apps" and "v1", or "linkerd.io" and "v1alpha1", and with
+// the given query parameters, e.g. ListOptions.Values(). An empty group selects
+// the core API group. If namespace is empty, the URL is for a cluster-scoped
+// resource, or for a resource in all namespaces.
+func (kubeAPI *KubernetesAPI) UrlForGroupVersion(namespace, group, version, extraPathStartingWithSlash string, query url.Values) (*url.URL, error) {
+	return generateKubernetesApiUrlForGroupVersion(kubeAPI.Host, namespace, group, version, extraPathStartingWithSlash, query)
+}
+
+// APIError is returned by KubernetesAPI when the API server responds with an
+// unexpected status. When the response body is a metav1.Status, its reason
+// and message are included, since they usually explain why the request was
+// rejected (e.g. the RBAC rule that is missing). Callers can retrieve it with
+// a type assertion.
+type APIError struct {
+	// StatusCode and Status are the HTTP status of the response, e.g. 403 and
+	// "403 Forbidden".
+	StatusCode int
+	Status     string
+
+	// Reason and Message are decoded from the response body. They are empty if
+	// the body is not a metav1.Status.
+	Reason  metav1.StatusReason
+	Message string
+
+	// Path is the path of the request that failed.
+	Path string
+
+	// User is the impersonated user the request was made for, if any.
+	User string
 }
 
+func (e *APIError) Error() string {
+	var msg string
+	if e.StatusCode == http.StatusForbidden && e.User != "" {
+		msg = fmt.Sprintf("user [%s] is not authorized to access [%s]: %s", e.User, e.Path, e.Status)
+	} else {
+		msg = fmt.Sprintf("Unexpected Kubernetes API response: %s", e.Status)
+	}
+
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// IsForbidden reports whether err is an APIError for a 403 response, meaning
+// the caller is not authorized to make the request.
+func IsForbidden(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusForbidden
+}
+
+// IsNotFound reports whether err is an APIError for a 404 response, meaning
+// the requested object does not exist.
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// maxErrorBodySize bounds how much of an error response is read when looking
+// for a metav1.Status.
+const maxErrorBodySize = 1 << 20
+
+// responseError describes an unsuccessful response from the Kubernetes API.
+// When impersonation is configured, authorization failures are attributed to
+// the impersonated user.
+func (kubeAPI *KubernetesAPI) responseError(rsp *http.Response) error {
+	apiErr := &APIError{
+		StatusCode: rsp.StatusCode,
+		Status:     rsp.Status,
+		Path:       rsp.Request.URL.Path,
+		User:       kubeAPI.Impersonate.UserName,
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(rsp.Body, maxErrorBodySize))
+	if err != nil {
+		log.Debugf("error reading Kubernetes API response body: %v", err)
+		return apiErr
+	}
+
+	var status metav1.Status
+	if err := json.Unmarshal(body, &status); err == nil && status.Kind == "Status" {
+		apiErr.Reason = status.Reason
+		apiErr.Message = status.Message
+	}
+
+	return apiErr
+}
+
+func (kubeAPI *KubernetesAPI) requestTimeout() time.Duration {
+	if kubeAPI.Timeout <= 0 {
+		return DefaultRequestTimeout
+	}
+	return kubeAPI.Timeout
+}
+
+func (kubeAPI *KubernetesAPI) retryAttempts() int {
+	if kubeAPI.RetryAttempts <= 0 {
+		return DefaultRetryAttempts
+	}
+	return kubeAPI.RetryAttempts
+}
+
+func (kubeAPI *KubernetesAPI) retryBaseDelay() time.Duration {
+	if kubeAPI.RetryBaseDelay <= 0 {
+		return DefaultRetryBaseDelay
+	}
+	return kubeAPI.RetryBaseDelay
+}
+
+// getRequest issues a GET request for path. Since GETs are idempotent,
+// requests failing with a connection error, a 429 or a 5xx response are
+// retried with exponential backoff, for as long as ctx allows.
 func (kubeAPI *KubernetesAPI) getRequest(ctx context.Context, client *http.Client, path string) (*http.Response, error) {
-	endpoint, err := url.Parse(kubeAPI.Host + path)
+	return kubeAPI.doRequest(ctx, client, "GET", path, nil, nil)
+}
+
+// doRequest sends a request with the given method, query parameters and body
+// to path, retrying transient failures. The body is sent again with each
+// attempt, so only idempotent requests should be made with it. Query
+// parameters must be passed in query rather than in path, so that they are
+// encoded consistently.
+func (kubeAPI *KubernetesAPI) doRequest(ctx context.Context, client *http.Client, method, path string, query url.Values, body []byte) (*http.Response, error) {
+	if strings.Contains(path, "?") {
+		return nil, fmt.Errorf("path must not contain a query string, was [%s]", path)
+	}
+
+	endpoint, err := joinURL(kubeAPI.Host, path)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("invalid Kubernetes API host [%s]: %v", kubeAPI.Host, err)
+	}
+	endpoint.RawQuery = query.Encode()
+
+	newRequest := func() (*http.Request, error) {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, endpoint.String(), reqBody)
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if kubeAPI.UserAgent != "" {
+			req.Header.Set("User-Agent", kubeAPI.UserAgent)
+		}
+		return req.WithContext(ctx), nil
 	}
 
-	req, err := http.NewRequest("GET", endpoint.String(), nil)
+	delay := kubeAPI.retryBaseDelay()
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		rsp, err := client.Do(req)
+		if attempt >= kubeAPI.retryAttempts() || !isRetriable(ctx, rsp, err) {
+			return rsp, err
+		}
+
+		backoff := wait.Jitter(delay, 0.5)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return rsp, err
+		}
+
+		if rsp != nil {
+			io.Copy(ioutil.Discard, rsp.Body)
+			rsp.Body.Close()
+		}
+		log.Debugf("retrying request to [%s] in %s", endpoint, backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// isRetriable reports whether a request failed transiently. Errors caused by
+// cancellation of the request's context are not retriable.
+func isRetriable(ctx context.Context, rsp *http.Response, err error) bool {
 	if err != nil {
-		return nil, err
+		return ctx.Err() == nil
 	}
 
-	return client.Do(req.WithContext(ctx))
+	return rsp.StatusCode == http.StatusTooManyRequests || rsp.StatusCode >= http.StatusInternalServerError
 }
 
 // NewAPI validates a Kubernetes config and returns a client for accessing the
 // configured cluster
-func NewAPI(configPath string) (*KubernetesAPI, error) {
-	config, err := getConfig(configPath)
+func NewAPI(configPath string, options *APIOptions) (*KubernetesAPI, error) {
+	if options == nil {
+		options = &APIOptions{}
+	}
+
+	if options.Impersonate == "" && len(options.ImpersonateGroups) > 0 {
+		return nil, fmt.Errorf("impersonating groups requires a user to impersonate")
+	}
+
+	if options.APIServerAddr != "" {
+		if err := validateAPIServerAddr(options.APIServerAddr); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Insecure && options.CAFile != "" {
+		return nil, fmt.Errorf("a CA file cannot be used when TLS verification is disabled")
+	}
+
+	if options.Token != "" && options.TokenFile != "" {
+		return nil, fmt.Errorf("a token and a token file cannot both be used")
+	}
+
+	if options.TokenFile != "" {
+		if _, err := readTokenFile(options.TokenFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.CAFile != "" {
+		if _, err := certutil.NewPool(options.CAFile); err != nil {
+			return nil, fmt.Errorf("error loading CA file [%s]: %v", options.CAFile, err)
+		}
+	}
+
+	config, err := getConfig(configPath, options.KubeContext)
 	if err != nil {
 		return nil, fmt.Errorf("error configuring Kubernetes API client: %v", err)
 	}
 
-	return &KubernetesAPI{Config: config}, nil
+	if options.APIServerAddr != "" {
+		config.Host = options.APIServerAddr
+	}
+
+	if options.CAFile != "" {
+		config.TLSClientConfig.CAFile = options.CAFile
+		config.TLSClientConfig.CAData = nil
+	}
+
+	if options.Insecure {
+		log.Warnf("TLS verification of the Kubernetes API server at [%s] is disabled", config.Host)
+		config.TLSClientConfig.Insecure = true
+		config.TLSClientConfig.CAFile = ""
+		config.TLSClientConfig.CAData = nil
+	}
+
+	if options.Token != "" || options.TokenFile != "" {
+		config.BearerToken = options.Token
+		config.Username = ""
+		config.Password = ""
+		config.AuthProvider = nil
+		config.ExecProvider = nil
+	}
+
+	if options.TokenFile != "" {
+		wrap := config.WrapTransport
+		config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+			if wrap != nil {
+				rt = wrap(rt)
+			}
+			return newTokenFileTransport(options.TokenFile, rt)
+		}
+	}
+
+	if options.Impersonate != "" {
+		config.Impersonate = rest.ImpersonationConfig{
+			UserName: options.Impersonate,
+			Groups:   options.ImpersonateGroups,
+		}
+	}
+
+	config.UserAgent = options.UserAgent
+	if config.UserAgent == "" {
+		config.UserAgent = CLIUserAgent()
+	}
+
+	if options.QPS > 0 {
+		config.QPS = options.QPS
+	}
+	if options.Burst > 0 {
+		config.Burst = options.Burst
+	}
+
+	kubeAPI, err := NewAPIFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Timeout > 0 {
+		kubeAPI.Timeout = options.Timeout
+	}
+	kubeAPI.LogBodies = options.LogBodies
+
+	return kubeAPI, nil
+}
+
+func validateAPIServerAddr(addr string) error {
+	apiURL, err := url.Parse(addr)
+	if err != nil {
+		return fmt.Errorf("invalid Kubernetes API server address [%s]: %v", addr, err)
+	}
+
+	if (apiURL.Scheme != "https" && apiURL.Scheme != "http") || apiURL.Host == "" {
+		return fmt.Errorf("invalid Kubernetes API server address [%s]: must be an absolute http or https URL", addr)
+	}
+
+	if port := apiURL.Port(); port != "" {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("invalid Kubernetes API server address [%s]: invalid port [%s]", addr, port)
+		}
+	}
+
+	return nil
+}
+
+// NewAPIFromConfig validates an existing rest.Config, such as one built from
+// the in-cluster environment, and returns a client for accessing the
+// configured cluster. The given config is copied, so later changes to it do
+// not affect the returned client. If the config has no UserAgent, the client
+// sends CLIUserAgent(), as with NewAPI.
+func NewAPIFromConfig(config *rest.Config) (*KubernetesAPI, error) {
+	if config == nil {
+		return nil, fmt.Errorf("error configuring Kubernetes API client: config must not be nil")
+	}
+
+	if config.Host == "" {
+		return nil, fmt.Errorf("error configuring Kubernetes API client: config has no host")
+	}
+
+	if _, err := url.Parse(config.Host); err != nil {
+		return nil, fmt.Errorf("error configuring Kubernetes API client: invalid host [%s]: %v", config.Host, err)
+	}
+
+	configCopy := *config
+	if configCopy.UserAgent == "" {
+		configCopy.UserAgent = CLIUserAgent()
+	}
+
+	return &KubernetesAPI{
+		Config:         &configCopy,
+		Timeout:        DefaultRequestTimeout,
+		RetryAttempts:  DefaultRetryAttempts,
+		RetryBaseDelay: DefaultRetryBaseDelay,
+	}, nil
 }
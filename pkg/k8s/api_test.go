@@ -1,8 +1,26 @@
 package k8s
 
 import (
+	"context"
+	"encoding/pem"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/version"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
 )
 
 func TestKubernetesApiUrlFor(t *testing.T) {
@@ -11,7 +29,7 @@ func TestKubernetesApiUrlFor(t *testing.T) {
 
 	t.Run("Returns base config containing k8s endpoint listed in config.test", func(t *testing.T) {
 		expected := fmt.Sprintf("https://55.197.171.239/api/v1/namespaces/%s%s", namespace, extraPath)
-		api, err := NewAPI("testdata/config.test")
+		api, err := NewAPI("testdata/config.test", nil)
 		if err != nil {
 			t.Fatalf("Unexpected error creating Kubernetes API: %+v", err)
 		}
@@ -24,3 +42,1199 @@ func TestKubernetesApiUrlFor(t *testing.T) {
 		}
 	})
 }
+
+func TestNewAPIWithKubeContext(t *testing.T) {
+	t.Run("Uses the current-context when no context is given", func(t *testing.T) {
+		api, err := NewAPI("testdata/config.test", &APIOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error creating Kubernetes API: %v", err)
+		}
+		if api.Host != "https://55.197.171.239" {
+			t.Fatalf("Expected host [https://55.197.171.239], got [%s]", api.Host)
+		}
+	})
+
+	t.Run("Uses the server and user of the selected context", func(t *testing.T) {
+		api, err := NewAPI("testdata/config.test", &APIOptions{KubeContext: "cluster4"})
+		if err != nil {
+			t.Fatalf("Unexpected error creating Kubernetes API: %v", err)
+		}
+		if api.Host != "https://162.128.50.10" {
+			t.Fatalf("Expected host [https://162.128.50.10], got [%s]", api.Host)
+		}
+		if api.AuthProvider == nil || api.AuthProvider.Config["access-token"] != "4cc3sspassatempoq" {
+			t.Fatalf("Expected credentials of user [cluster4], got %+v", api.AuthProvider)
+		}
+	})
+
+	t.Run("Lists the available contexts when the context does not exist", func(t *testing.T) {
+		_, err := NewAPI("testdata/config.test", &APIOptions{KubeContext: "missing"})
+		if err == nil {
+			t.Fatalf("Expected error for missing context, got nil")
+		}
+
+		expected := "available contexts: [cluster1, cluster2, cluster3, cluster4, dev]"
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("Expected error to contain [%s], got [%s]", expected, err)
+		}
+	})
+}
+
+func TestKubernetesApiRequestCancellation(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-unblock:
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	requests := map[string]func(ctx context.Context) error{
+		"GetVersionInfo": func(ctx context.Context) error {
+			_, err := api.GetVersionInfo(ctx)
+			return err
+		},
+		"NamespaceExists": func(ctx context.Context) error {
+			_, err := api.NamespaceExists(ctx, "linkerd")
+			return err
+		},
+		"GetPodsByNamespace": func(ctx context.Context) error {
+			_, err := api.GetPodsByNamespace(ctx, "linkerd")
+			return err
+		},
+	}
+
+	for name, request := range requests {
+		t.Run(fmt.Sprintf("%s aborts the request when the context is cancelled", name), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			start := time.Now()
+			err := request(ctx)
+			if err == nil {
+				t.Fatalf("Expected error after cancellation, got nil")
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Fatalf("Expected request to be aborted promptly, took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestKubernetesApiTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	t.Run("Fails when the server is slower than the configured timeout", func(t *testing.T) {
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, Timeout: 50 * time.Millisecond}
+
+		_, err := api.GetVersionInfo(context.Background())
+		if err == nil {
+			t.Fatalf("Expected request to time out, but it succeeded")
+		}
+	})
+
+	t.Run("Succeeds when the server responds within the configured timeout", func(t *testing.T) {
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, Timeout: 2 * time.Second}
+
+		versionInfo, err := api.GetVersionInfo(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if versionInfo.GitVersion != "v1.10.0" {
+			t.Fatalf("Expected version [v1.10.0], got [%s]", versionInfo.GitVersion)
+		}
+	})
+
+	t.Run("Falls back to DefaultRequestTimeout when unset", func(t *testing.T) {
+		api, err := NewAPI("testdata/config.test", &APIOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error creating Kubernetes API: %v", err)
+		}
+		if api.requestTimeout() != DefaultRequestTimeout {
+			t.Fatalf("Expected timeout [%s], got [%s]", DefaultRequestTimeout, api.requestTimeout())
+		}
+	})
+}
+
+func TestKubernetesApiImpersonation(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		switch r.URL.Path {
+		case "/version":
+			w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{
+		Host: server.URL,
+		Impersonate: rest.ImpersonationConfig{
+			UserName: "jane",
+			Groups:   []string{"developers", "testers"},
+		},
+	}}
+
+	t.Run("Sends impersonation headers with every request", func(t *testing.T) {
+		versionInfo, err := api.GetVersionInfo(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := api.CheckVersion(versionInfo); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if user := headers.Get("Impersonate-User"); user != "jane" {
+			t.Fatalf("Expected Impersonate-User header [jane], got [%s]", user)
+		}
+		groups := headers["Impersonate-Group"]
+		if !reflect.DeepEqual(groups, []string{"developers", "testers"}) {
+			t.Fatalf("Expected Impersonate-Group headers [developers testers], got %v", groups)
+		}
+	})
+
+	t.Run("Reports authorization failures for the impersonated user", func(t *testing.T) {
+		_, err := api.NamespaceExists(context.Background(), "linkerd")
+		if err == nil {
+			t.Fatalf("Expected authorization error, got nil")
+		}
+
+		expected := "user [jane] is not authorized to access [/api/v1/namespaces/linkerd]: 403 Forbidden"
+		if err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%s]", expected, err)
+		}
+	})
+
+	t.Run("Rejects impersonated groups without a user", func(t *testing.T) {
+		_, err := NewAPI("testdata/config.test", &APIOptions{ImpersonateGroups: []string{"developers"}})
+		if err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Configures impersonation from options", func(t *testing.T) {
+		api, err := NewAPI("testdata/config.test", &APIOptions{Impersonate: "jane", ImpersonateGroups: []string{"developers"}})
+		if err != nil {
+			t.Fatalf("Unexpected error creating Kubernetes API: %v", err)
+		}
+
+		expected := rest.ImpersonationConfig{UserName: "jane", Groups: []string{"developers"}}
+		if !reflect.DeepEqual(api.Impersonate, expected) {
+			t.Fatalf("Expected impersonation config %+v, got %+v", expected, api.Impersonate)
+		}
+	})
+}
+
+func TestNewAPIFromConfig(t *testing.T) {
+	t.Run("Returns a KubernetesAPI for a valid config", func(t *testing.T) {
+		config := &rest.Config{Host: "https://10.96.0.1:443"}
+		api, err := NewAPIFromConfig(config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if api.Timeout != DefaultRequestTimeout {
+			t.Fatalf("Expected timeout [%s], got [%s]", DefaultRequestTimeout, api.Timeout)
+		}
+
+		if api.UserAgent != CLIUserAgent() {
+			t.Fatalf("Expected User-Agent [%s], got [%s]", CLIUserAgent(), api.UserAgent)
+		}
+		if config.UserAgent != "" {
+			t.Fatalf("Expected the original config to be unchanged, got User-Agent [%s]", config.UserAgent)
+		}
+
+		expected := "https://10.96.0.1:443/api/v1/namespaces/linkerd/services/http:api:http/proxy/"
+		actualURL, err := api.UrlFor("linkerd", "/services/http:api:http/proxy/")
+		if err != nil {
+			t.Fatalf("Unexpected error generating URL: %v", err)
+		}
+		if actualURL.String() != expected {
+			t.Fatalf("Expected generated URL to be [%s], but got [%s]", expected, actualURL.String())
+		}
+
+		config.Host = "https://changed"
+		if api.Host != "https://10.96.0.1:443" {
+			t.Fatalf("Expected KubernetesAPI to be unaffected by changes to the original config, got host [%s]", api.Host)
+		}
+	})
+
+	t.Run("Keeps the User-Agent of the config", func(t *testing.T) {
+		userAgent := ControllerUserAgent("public-api")
+		api, err := NewAPIFromConfig(&rest.Config{Host: "https://10.96.0.1:443", UserAgent: userAgent})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if api.UserAgent != userAgent {
+			t.Fatalf("Expected User-Agent [%s], got [%s]", userAgent, api.UserAgent)
+		}
+	})
+
+	t.Run("Rejects invalid configs", func(t *testing.T) {
+		configs := map[string]*rest.Config{
+			"nil config": nil,
+			"empty host": &rest.Config{},
+		}
+
+		for name, config := range configs {
+			if _, err := NewAPIFromConfig(config); err == nil {
+				t.Fatalf("Expected error for %s, got nil", name)
+			}
+		}
+	})
+}
+
+func TestKubernetesApiRateLimits(t *testing.T) {
+	origTransportFor := transportFor
+	defer func() { transportFor = origTransportFor }()
+
+	var transportConfig *rest.Config
+	transportFor = func(config *rest.Config) (http.RoundTripper, error) {
+		transportConfig = config
+		return origTransportFor(config)
+	}
+
+	t.Run("Applies QPS and Burst to the config used to build the transport", func(t *testing.T) {
+		api, err := NewAPI("testdata/config.test", &APIOptions{QPS: 100, Burst: 200})
+		if err != nil {
+			t.Fatalf("Unexpected error creating Kubernetes API: %v", err)
+		}
+
+		client, err := api.NewClient()
+		if err != nil {
+			t.Fatalf("Unexpected error creating client: %v", err)
+		}
+
+		if transportConfig.QPS != 100 || transportConfig.Burst != 200 {
+			t.Fatalf("Expected QPS [100] and Burst [200], got [%v] and [%d]", transportConfig.QPS, transportConfig.Burst)
+		}
+		if _, ok := client.Transport.(*rateLimitedTransport); !ok {
+			t.Fatalf("Expected a rate limited transport, got %T", client.Transport)
+		}
+	})
+
+	t.Run("Keeps client-go defaults when unset", func(t *testing.T) {
+		api, err := NewAPI("testdata/config.test", &APIOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error creating Kubernetes API: %v", err)
+		}
+
+		client, err := api.NewClient()
+		if err != nil {
+			t.Fatalf("Unexpected error creating client: %v", err)
+		}
+
+		if transportConfig.QPS != 0 || transportConfig.Burst != 0 {
+			t.Fatalf("Expected QPS and Burst to be unset, got [%v] and [%d]", transportConfig.QPS, transportConfig.Burst)
+		}
+		if _, ok := client.Transport.(*rateLimitedTransport); ok {
+			t.Fatalf("Expected transport not to be rate limited")
+		}
+	})
+}
+
+func TestNewAPIWithAPIServerAddr(t *testing.T) {
+	t.Run("Overrides the host while keeping the kubeconfig's TLS settings", func(t *testing.T) {
+		api, err := NewAPI("testdata/config.test", &APIOptions{APIServerAddr: "https://k8s.example.com:6443"})
+		if err != nil {
+			t.Fatalf("Unexpected error creating Kubernetes API: %v", err)
+		}
+
+		if api.Host != "https://k8s.example.com:6443" {
+			t.Fatalf("Expected host [https://k8s.example.com:6443], got [%s]", api.Host)
+		}
+		if !strings.Contains(string(api.TLSClientConfig.CAData), "que parada atrasada") {
+			t.Fatalf("Expected CA data from the kubeconfig, got [%s]", api.TLSClientConfig.CAData)
+		}
+
+		actualURL, err := api.UrlFor("linkerd", "/pods")
+		if err != nil {
+			t.Fatalf("Unexpected error generating URL: %v", err)
+		}
+		expected := "https://k8s.example.com:6443/api/v1/namespaces/linkerd/pods"
+		if actualURL.String() != expected {
+			t.Fatalf("Expected generated URL to be [%s], but got [%s]", expected, actualURL.String())
+		}
+	})
+
+	t.Run("Sends requests to the overridden host", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+		}))
+		defer server.Close()
+
+		api, err := NewAPI("testdata/config.test", &APIOptions{APIServerAddr: server.URL})
+		if err != nil {
+			t.Fatalf("Unexpected error creating Kubernetes API: %v", err)
+		}
+		api.TLSClientConfig = rest.TLSClientConfig{}
+		api.AuthProvider = nil
+
+		if _, err := api.GetVersionInfo(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Rejects unparseable addresses", func(t *testing.T) {
+		addrs := []string{
+			"k8s.example.com:6443",
+			"ftp://k8s.example.com",
+			"https://",
+			"https://k8s.example.com:99999",
+			"https://k8s example.com",
+		}
+
+		for _, addr := range addrs {
+			if _, err := NewAPI("testdata/config.test", &APIOptions{APIServerAddr: addr}); err == nil {
+				t.Fatalf("Expected error for address [%s], got nil", addr)
+			}
+		}
+	})
+}
+
+func TestNewAPIWithCustomTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.crt")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.TLS.Certificates[0].Certificate[0]})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	kubeconfig := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+clusters:
+- cluster:
+    server: %s
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+kind: Config
+users:
+- name: test
+  user: {}
+`, server.URL)), 0600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	getVersion := func(options *APIOptions) error {
+		api, err := NewAPI(kubeconfig, options)
+		if err != nil {
+			return err
+		}
+		_, err = api.GetVersionInfo(context.Background())
+		return err
+	}
+
+	t.Run("Fails to verify a self-signed certificate by default", func(t *testing.T) {
+		if err := getVersion(&APIOptions{}); err == nil {
+			t.Fatalf("Expected certificate verification error, got nil")
+		}
+	})
+
+	t.Run("Connects using a custom CA file", func(t *testing.T) {
+		if err := getVersion(&APIOptions{CAFile: caFile}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Connects when TLS verification is disabled", func(t *testing.T) {
+		if err := getVersion(&APIOptions{Insecure: true}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Rejects a CA file combined with insecure", func(t *testing.T) {
+		if _, err := NewAPI(kubeconfig, &APIOptions{CAFile: caFile, Insecure: true}); err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+	})
+
+	t.Run("Rejects an unreadable CA file", func(t *testing.T) {
+		if _, err := NewAPI(kubeconfig, &APIOptions{CAFile: filepath.Join(dir, "missing.crt")}); err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+	})
+}
+
+func TestKubernetesApiProxyFromEnvironment(t *testing.T) {
+	var proxiedURLs []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURLs = append(proxiedURLs, r.URL.String())
+		w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+	}))
+	defer proxy.Close()
+
+	getVersion := func(host string) error {
+		api := &KubernetesAPI{Config: &rest.Config{Host: host}, Timeout: 500 * time.Millisecond}
+		_, err := api.GetVersionInfo(context.Background())
+		return err
+	}
+
+	env := map[string]string{
+		"HTTP_PROXY":  proxy.URL,
+		"HTTPS_PROXY": proxy.URL,
+		"NO_PROXY":    "",
+		"http_proxy":  "",
+		"https_proxy": "",
+		"no_proxy":    "",
+	}
+
+	withEnv(t, env, func() {
+		t.Run("Routes requests through the proxy", func(t *testing.T) {
+			proxiedURLs = nil
+			if err := getVersion("http://kubernetes.example.com"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expected := []string{"http://kubernetes.example.com/version"}
+			if !reflect.DeepEqual(proxiedURLs, expected) {
+				t.Fatalf("Expected proxied requests %v, got %v", expected, proxiedURLs)
+			}
+		})
+	})
+
+	withEnv(t, env, func() {
+		t.Run("Routes requests for IPv6 hosts through the proxy", func(t *testing.T) {
+			proxiedURLs = nil
+			if err := getVersion("http://[fd00::1]:6443"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expected := []string{"http://[fd00::1]:6443/version"}
+			if !reflect.DeepEqual(proxiedURLs, expected) {
+				t.Fatalf("Expected proxied requests %v, got %v", expected, proxiedURLs)
+			}
+		})
+	})
+
+	env["NO_PROXY"] = "kubernetes.example.com,10.96.0.0/12,fd00::1,fd01::/16"
+	withEnv(t, env, func() {
+		t.Run("Does not proxy hosts excluded by NO_PROXY", func(t *testing.T) {
+			for _, host := range []string{"http://kubernetes.example.com", "http://10.96.0.1", "http://[fd00::1]:6443", "http://[fd01::10]"} {
+				proxiedURLs = nil
+				getVersion(host)
+
+				if len(proxiedURLs) != 0 {
+					t.Fatalf("Expected [%s] not to be proxied, got %v", host, proxiedURLs)
+				}
+			}
+		})
+	})
+}
+
+func TestNewAPIWithBearerToken(t *testing.T) {
+	var authorization []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/version":
+			w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	newAPI := func(t *testing.T, options *APIOptions) *KubernetesAPI {
+		options.APIServerAddr = server.URL
+		api, err := NewAPI("testdata/config.test", options)
+		if err != nil {
+			t.Fatalf("Unexpected error creating Kubernetes API: %v", err)
+		}
+		api.TLSClientConfig = rest.TLSClientConfig{}
+		return api
+	}
+
+	makeRequests := func(t *testing.T, api *KubernetesAPI) {
+		authorization = nil
+		api.InvalidateVersionCache()
+		if _, err := api.GetVersionInfo(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := api.NamespaceExists(context.Background(), "linkerd"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	t.Run("Sends the token instead of the kubeconfig's credentials", func(t *testing.T) {
+		api := newAPI(t, &APIOptions{Token: "s3cr3t"})
+		if api.AuthProvider != nil {
+			t.Fatalf("Expected the kubeconfig's auth provider to be cleared, got %+v", api.AuthProvider)
+		}
+
+		makeRequests(t, api)
+		expected := []string{"Bearer s3cr3t", "Bearer s3cr3t"}
+		if !reflect.DeepEqual(authorization, expected) {
+			t.Fatalf("Expected Authorization headers %v, got %v", expected, authorization)
+		}
+	})
+
+	t.Run("Reads the token file again when it changes", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "linkerd-token")
+		if err != nil {
+			t.Fatalf("Unexpected error creating temp dir: %v", err)
+		}
+		defer os.RemoveAll(dir)
+
+		tokenFile := filepath.Join(dir, "token")
+		if err := ioutil.WriteFile(tokenFile, []byte("first-token\n"), 0600); err != nil {
+			t.Fatalf("Unexpected error writing token file: %v", err)
+		}
+
+		api := newAPI(t, &APIOptions{TokenFile: tokenFile})
+
+		makeRequests(t, api)
+		expected := []string{"Bearer first-token", "Bearer first-token"}
+		if !reflect.DeepEqual(authorization, expected) {
+			t.Fatalf("Expected Authorization headers %v, got %v", expected, authorization)
+		}
+
+		if err := ioutil.WriteFile(tokenFile, []byte("rotated-token\n"), 0600); err != nil {
+			t.Fatalf("Unexpected error writing token file: %v", err)
+		}
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(tokenFile, later, later); err != nil {
+			t.Fatalf("Unexpected error updating token file: %v", err)
+		}
+
+		makeRequests(t, api)
+		expected = []string{"Bearer rotated-token", "Bearer rotated-token"}
+		if !reflect.DeepEqual(authorization, expected) {
+			t.Fatalf("Expected Authorization headers %v, got %v", expected, authorization)
+		}
+	})
+
+	t.Run("Rejects invalid token options", func(t *testing.T) {
+		options := []*APIOptions{
+			{Token: "s3cr3t", TokenFile: "testdata/config.test"},
+			{TokenFile: "testdata/does-not-exist"},
+		}
+
+		for _, opts := range options {
+			if _, err := NewAPI("testdata/config.test", opts); err == nil {
+				t.Fatalf("Expected error for options %+v, got nil", opts)
+			}
+		}
+	})
+}
+
+func TestKubernetesApiRetries(t *testing.T) {
+	newFlakyServer := func(failures int, fail func(w http.ResponseWriter)) (*httptest.Server, *int) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests <= failures {
+				fail(w)
+				return
+			}
+			w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+		}))
+		return server, &requests
+	}
+
+	failures := map[string]func(w http.ResponseWriter){
+		"502 responses": func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusBadGateway)
+		},
+		"429 responses": func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		},
+		"connection resets": func(w http.ResponseWriter) {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		},
+	}
+
+	for name, fail := range failures {
+		t.Run(fmt.Sprintf("Retries %s until the request succeeds", name), func(t *testing.T) {
+			server, requests := newFlakyServer(2, fail)
+			defer server.Close()
+
+			api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryBaseDelay: time.Millisecond}
+
+			versionInfo, err := api.GetVersionInfo(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if versionInfo.GitVersion != "v1.10.0" {
+				t.Fatalf("Expected version [v1.10.0], got [%s]", versionInfo.GitVersion)
+			}
+			if *requests != 3 {
+				t.Fatalf("Expected 3 requests, got %d", *requests)
+			}
+		})
+	}
+
+	t.Run("Does not retry when attempts is 1", func(t *testing.T) {
+		server, requests := newFlakyServer(2, failures["502 responses"])
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		_, err := api.NamespaceExists(context.Background(), "linkerd")
+		expected := "Unexpected Kubernetes API response: 502 Bad Gateway"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+		if *requests != 1 {
+			t.Fatalf("Expected 1 request, got %d", *requests)
+		}
+	})
+
+	t.Run("Does not retry other client errors", func(t *testing.T) {
+		server, requests := newFlakyServer(2, func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryBaseDelay: time.Millisecond}
+
+		if _, err := api.GetVersionInfo(context.Background()); err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+		if *requests != 1 {
+			t.Fatalf("Expected 1 request, got %d", *requests)
+		}
+	})
+
+	t.Run("Stops retrying at the request deadline", func(t *testing.T) {
+		server, requests := newFlakyServer(10, failures["502 responses"])
+		defer server.Close()
+
+		api := &KubernetesAPI{
+			Config:         &rest.Config{Host: server.URL},
+			Timeout:        100 * time.Millisecond,
+			RetryAttempts:  10,
+			RetryBaseDelay: time.Second,
+		}
+
+		start := time.Now()
+		if _, err := api.GetVersionInfo(context.Background()); err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("Expected retries to stop at the deadline, took %s", elapsed)
+		}
+		if *requests != 1 {
+			t.Fatalf("Expected 1 request, got %d", *requests)
+		}
+	})
+}
+
+func TestKubernetesApiErrors(t *testing.T) {
+	forbidden := `{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure","message":"namespaces \"linkerd\" is forbidden: User \"jane\" cannot get namespaces in the namespace \"linkerd\"","reason":"Forbidden","details":{"name":"linkerd","kind":"namespaces"},"code":403}`
+	notFound := `{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure","message":"the server could not find the requested resource","reason":"NotFound","details":{},"code":404}`
+
+	testCases := []struct {
+		name     string
+		status   int
+		body     string
+		user     string
+		request  func(api *KubernetesAPI) error
+		expected APIError
+		message  string
+	}{
+		{
+			name:   "Forbidden",
+			status: http.StatusForbidden,
+			body:   forbidden,
+			request: func(api *KubernetesAPI) error {
+				_, err := api.NamespaceExists(context.Background(), "linkerd")
+				return err
+			},
+			expected: APIError{
+				StatusCode: http.StatusForbidden,
+				Status:     "403 Forbidden",
+				Reason:     metav1.StatusReasonForbidden,
+				Message:    "namespaces \"linkerd\" is forbidden: User \"jane\" cannot get namespaces in the namespace \"linkerd\"",
+				Path:       "/api/v1/namespaces/linkerd",
+			},
+			message: "Unexpected Kubernetes API response: 403 Forbidden: namespaces \"linkerd\" is forbidden: User \"jane\" cannot get namespaces in the namespace \"linkerd\"",
+		},
+		{
+			name:   "Forbidden for an impersonated user",
+			status: http.StatusForbidden,
+			body:   forbidden,
+			user:   "jane",
+			request: func(api *KubernetesAPI) error {
+				_, err := api.GetPodsByNamespace(context.Background(), "linkerd")
+				return err
+			},
+			expected: APIError{
+				StatusCode: http.StatusForbidden,
+				Status:     "403 Forbidden",
+				Reason:     metav1.StatusReasonForbidden,
+				Message:    "namespaces \"linkerd\" is forbidden: User \"jane\" cannot get namespaces in the namespace \"linkerd\"",
+				Path:       "/api/v1/namespaces/linkerd/pods",
+				User:       "jane",
+			},
+			message: "user [jane] is not authorized to access [/api/v1/namespaces/linkerd/pods]: 403 Forbidden: namespaces \"linkerd\" is forbidden: User \"jane\" cannot get namespaces in the namespace \"linkerd\"",
+		},
+		{
+			name:   "NotFound",
+			status: http.StatusNotFound,
+			body:   notFound,
+			request: func(api *KubernetesAPI) error {
+				_, err := api.GetVersionInfo(context.Background())
+				return err
+			},
+			expected: APIError{
+				StatusCode: http.StatusNotFound,
+				Status:     "404 Not Found",
+				Reason:     metav1.StatusReasonNotFound,
+				Message:    "the server could not find the requested resource",
+				Path:       "/version",
+			},
+			message: "Unexpected Kubernetes API response: 404 Not Found: the server could not find the requested resource",
+		},
+		{
+			name:   "non-JSON body",
+			status: http.StatusBadRequest,
+			body:   "<html><body>Bad Request</body></html>",
+			request: func(api *KubernetesAPI) error {
+				_, err := api.GetVersionInfo(context.Background())
+				return err
+			},
+			expected: APIError{
+				StatusCode: http.StatusBadRequest,
+				Status:     "400 Bad Request",
+				Path:       "/version",
+			},
+			message: "Unexpected Kubernetes API response: 400 Bad Request",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Returns an APIError for %s responses", tc.name), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			api := &KubernetesAPI{Config: &rest.Config{
+				Host:        server.URL,
+				Impersonate: rest.ImpersonationConfig{UserName: tc.user},
+			}}
+			err := tc.request(api)
+			apiErr, ok := err.(*APIError)
+			if !ok {
+				t.Fatalf("Expected *APIError, got %T: %v", err, err)
+			}
+			if !reflect.DeepEqual(*apiErr, tc.expected) {
+				t.Fatalf("Expected error %+v, got %+v", tc.expected, *apiErr)
+			}
+			if apiErr.Error() != tc.message {
+				t.Fatalf("Expected error message [%s], got [%s]", tc.message, apiErr.Error())
+			}
+		})
+	}
+}
+
+func TestKubernetesApiUserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+	}))
+	defer server.Close()
+
+	t.Run("Sends the CLI User-Agent by default", func(t *testing.T) {
+		api, err := NewAPI("testdata/config.test", &APIOptions{APIServerAddr: server.URL})
+		if err != nil {
+			t.Fatalf("Unexpected error creating Kubernetes API: %v", err)
+		}
+		api.TLSClientConfig = rest.TLSClientConfig{}
+		api.AuthProvider = nil
+
+		userAgents = nil
+		if _, err := api.GetVersionInfo(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := api.NamespaceExists(context.Background(), "linkerd"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := fmt.Sprintf("linkerd/cli %s (%s/%s)", version.Version, runtime.GOOS, runtime.GOARCH)
+		if !reflect.DeepEqual(userAgents, []string{expected, expected}) {
+			t.Fatalf("Expected User-Agent headers [%s], got %v", expected, userAgents)
+		}
+	})
+
+	t.Run("Sends the configured User-Agent", func(t *testing.T) {
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL, UserAgent: ControllerUserAgent("public-api")}}
+
+		userAgents = nil
+		if _, err := api.GetVersionInfo(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := fmt.Sprintf("linkerd/controller public-api %s", version.Version)
+		if !reflect.DeepEqual(userAgents, []string{expected}) {
+			t.Fatalf("Expected User-Agent headers [%s], got %v", expected, userAgents)
+		}
+	})
+}
+
+func TestKubernetesApiClientReuse(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Returns the same client on every call", func(t *testing.T) {
+		first, err := api.Client()
+		if err != nil {
+			t.Fatalf("Unexpected error creating client: %v", err)
+		}
+		second, err := api.Client()
+		if err != nil {
+			t.Fatalf("Unexpected error creating client: %v", err)
+		}
+
+		if first != second || first.Transport != second.Transport {
+			t.Fatalf("Expected the client and transport to be reused")
+		}
+	})
+
+	t.Run("Reuses connections across calls", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			if _, err := api.GetVersionInfo(context.Background()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, err := api.NamespaceExists(context.Background(), "linkerd"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		if n := atomic.LoadInt32(&connections); n != 1 {
+			t.Fatalf("Expected 1 connection to the API server, got %d", n)
+		}
+	})
+}
+
+func TestKubernetesApiVersionCache(t *testing.T) {
+	var requests int32
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if fail {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Does not cache errors", func(t *testing.T) {
+		fail = true
+		defer func() { fail = false }()
+
+		for i := 0; i < 2; i++ {
+			if _, err := api.GetVersionInfo(context.Background()); err == nil {
+				t.Fatalf("Expected error, got nil")
+			}
+		}
+		if n := atomic.SwapInt32(&requests, 0); n != 2 {
+			t.Fatalf("Expected 2 requests, got %d", n)
+		}
+	})
+
+	t.Run("Makes a single request across repeated calls", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			versionInfo, err := api.GetVersionInfo(context.Background())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if versionInfo.GitVersion != "v1.10.0" {
+				t.Fatalf("Expected version [v1.10.0], got [%s]", versionInfo.GitVersion)
+			}
+		}
+		if n := atomic.SwapInt32(&requests, 0); n != 1 {
+			t.Fatalf("Expected 1 request, got %d", n)
+		}
+	})
+
+	t.Run("Queries the cluster again after invalidation", func(t *testing.T) {
+		api.InvalidateVersionCache()
+		if _, err := api.GetVersionInfo(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if n := atomic.SwapInt32(&requests, 0); n != 1 {
+			t.Fatalf("Expected 1 request, got %d", n)
+		}
+	})
+}
+
+func TestNamespaceExists(t *testing.T) {
+	testCases := []struct {
+		status    int
+		exists    bool
+		forbidden bool
+		err       bool
+	}{
+		{status: http.StatusOK, exists: true},
+		{status: http.StatusNotFound, exists: false},
+		{status: http.StatusForbidden, exists: false, forbidden: true, err: true},
+		{status: http.StatusInternalServerError, exists: false, err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Handles %d responses", tc.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				if tc.status == http.StatusOK {
+					w.Write([]byte(`{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"linkerd"}}`))
+				}
+			}))
+			defer server.Close()
+
+			api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+			exists, err := api.NamespaceExists(context.Background(), "linkerd")
+			if exists != tc.exists {
+				t.Fatalf("Expected NamespaceExists to return [%t], got [%t]", tc.exists, exists)
+			}
+			if (err != nil) != tc.err {
+				t.Fatalf("Expected error [%t], got [%v]", tc.err, err)
+			}
+			if IsForbidden(err) != tc.forbidden {
+				t.Fatalf("Expected IsForbidden to return [%t] for [%v]", tc.forbidden, err)
+			}
+		})
+	}
+}
+
+func TestKubernetesApiHostWithPathPrefix(t *testing.T) {
+	testCases := []struct {
+		serverPath string
+		prefix     string
+	}{
+		{serverPath: "", prefix: ""},
+		{serverPath: "/", prefix: ""},
+		{serverPath: "/k8s/clusters/c-abc123", prefix: "/k8s/clusters/c-abc123"},
+		{serverPath: "/k8s/clusters/c-abc123/", prefix: "/k8s/clusters/c-abc123"},
+	}
+
+	for _, tc := range testCases {
+		host := "https://rancher.example.com" + tc.serverPath
+
+		t.Run(fmt.Sprintf("Generates URLs for [%s]", host), func(t *testing.T) {
+			api := &KubernetesAPI{Config: &rest.Config{Host: host}}
+
+			actual, err := api.UrlFor("linkerd", "/pods")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expected := "https://rancher.example.com" + tc.prefix + "/api/v1/namespaces/linkerd/pods"
+			if actual.String() != expected {
+				t.Fatalf("Expected generated URL to be [%s], but got [%s]", expected, actual.String())
+			}
+
+			actual, err = api.UrlForGroupVersion("", "apps", "v1", "/deployments", nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expected = "https://rancher.example.com" + tc.prefix + "/apis/apps/v1/deployments"
+			if actual.String() != expected {
+				t.Fatalf("Expected generated URL to be [%s], but got [%s]", expected, actual.String())
+			}
+
+			actual, err = api.ServiceProxyUrlFor("linkerd", "api", "http", "http", "")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			expected = "https://rancher.example.com" + tc.prefix + "/api/v1/namespaces/linkerd/services/http:api:http/proxy/"
+			if actual.String() != expected {
+				t.Fatalf("Expected generated URL to be [%s], but got [%s]", expected, actual.String())
+			}
+		})
+
+		t.Run(fmt.Sprintf("Sends requests for [%s]", host), func(t *testing.T) {
+			var requestURIs []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestURIs = append(requestURIs, r.RequestURI)
+				switch r.URL.Path {
+				case tc.prefix + "/version":
+					w.Write([]byte(`{"major":"1","minor":"11","gitVersion":"v1.11.1"}`))
+				case tc.prefix + "/api/v1/namespaces/linkerd":
+					w.Write([]byte(`{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"linkerd"}}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			api := &KubernetesAPI{Config: &rest.Config{Host: server.URL + tc.serverPath}, RetryAttempts: 1}
+
+			if _, err := api.GetVersionInfo(context.Background()); err != nil {
+				t.Fatalf("Unexpected error getting version: %v", err)
+			}
+			exists, err := api.NamespaceExists(context.Background(), "linkerd")
+			if err != nil || !exists {
+				t.Fatalf("Expected namespace to exist, got [%t] and [%v]", exists, err)
+			}
+
+			expected := []string{tc.prefix + "/version", tc.prefix + "/api/v1/namespaces/linkerd"}
+			if !reflect.DeepEqual(requestURIs, expected) {
+				t.Fatalf("Expected requests for %v, got %v", expected, requestURIs)
+			}
+		})
+	}
+}
+
+func TestNoProxyMatchesIP(t *testing.T) {
+	testCases := []struct {
+		noProxy  string
+		host     string
+		expected bool
+	}{
+		{noProxy: "10.96.0.1", host: "10.96.0.1", expected: true},
+		{noProxy: "10.96.0.1:443", host: "10.96.0.1", expected: true},
+		{noProxy: "10.96.0.0/12", host: "10.96.0.1", expected: true},
+		{noProxy: "fd00::1", host: "fd00::1", expected: true},
+		{noProxy: "[fd00::1]", host: "fd00::1", expected: true},
+		{noProxy: "[fd00::1]:6443", host: "fd00::1", expected: true},
+		{noProxy: "fd00:0:0::1", host: "fd00::1", expected: true},
+		{noProxy: "fd00::/8", host: "fd00::1", expected: true},
+		{noProxy: "fe80::1", host: "fe80::1%eth0", expected: true},
+		{noProxy: "fe80::1%eth0", host: "fe80::1%eth0", expected: true},
+		{noProxy: "fe80::/10", host: "fe80::1%eth0", expected: true},
+		{noProxy: "kubernetes.example.com, fd00::2", host: "fd00::1", expected: false},
+		{noProxy: "fd00::1", host: "kubernetes.example.com", expected: false},
+		{noProxy: "", host: "fd00::1", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s matches %s", tc.noProxy, tc.host), func(t *testing.T) {
+			if actual := noProxyMatchesIP(tc.noProxy, tc.host); actual != tc.expected {
+				t.Fatalf("Expected noProxyMatchesIP to return [%t], got [%t]", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestKubernetesApiIPv6Hosts(t *testing.T) {
+	testCases := []struct {
+		host     string
+		hostname string
+		port     string
+	}{
+		{host: "https://[fd00::1]:6443", hostname: "fd00::1", port: "6443"},
+		{host: "https://[fd00::1]", hostname: "fd00::1", port: ""},
+		{host: "https://[fe80::1%25eth0]:6443", hostname: "fe80::1%eth0", port: "6443"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Generates URLs for [%s]", tc.host), func(t *testing.T) {
+			api := &KubernetesAPI{Config: &rest.Config{Host: tc.host}}
+
+			urls := make([]string, 0)
+			for _, generate := range []func() (*url.URL, error){
+				func() (*url.URL, error) { return api.UrlFor("linkerd", "/pods") },
+				func() (*url.URL, error) { return api.UrlForGroupVersion("linkerd", "apps", "v1", "/deployments", nil) },
+				func() (*url.URL, error) { return api.ServiceProxyUrlFor("linkerd", "api", "http", "http", "") },
+			} {
+				actual, err := generate()
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if actual.Hostname() != tc.hostname || actual.Port() != tc.port {
+					t.Fatalf("Expected host [%s] and port [%s] in [%s], got [%s] and [%s]", tc.hostname, tc.port, actual, actual.Hostname(), actual.Port())
+				}
+				urls = append(urls, actual.String())
+			}
+
+			expected := []string{
+				tc.host + "/api/v1/namespaces/linkerd/pods",
+				tc.host + "/apis/apps/v1/namespaces/linkerd/deployments",
+				tc.host + "/api/v1/namespaces/linkerd/services/http:api:http/proxy/",
+			}
+			if !reflect.DeepEqual(urls, expected) {
+				t.Fatalf("Expected generated URLs to be %v, but got %v", expected, urls)
+			}
+		})
+	}
+
+	t.Run("Sends requests to an IPv6 host", func(t *testing.T) {
+		listener, err := net.Listen("tcp6", "[::1]:0")
+		if err != nil {
+			t.Skipf("IPv6 is not available: %v", err)
+		}
+
+		var requestURIs []string
+		server := &httptest.Server{
+			Listener: listener,
+			Config: &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestURIs = append(requestURIs, r.RequestURI)
+				switch r.URL.Path {
+				case "/version":
+					w.Write([]byte(`{"major":"1","minor":"11","gitVersion":"v1.11.1"}`))
+				default:
+					w.Write([]byte(`{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"linkerd"}}`))
+				}
+			})},
+		}
+		server.Start()
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+		if !strings.HasPrefix(server.URL, "http://[::1]:") {
+			t.Fatalf("Expected an IPv6 server URL, got [%s]", server.URL)
+		}
+
+		if _, err := api.GetVersionInfo(context.Background()); err != nil {
+			t.Fatalf("Unexpected error getting version: %v", err)
+		}
+		exists, err := api.NamespaceExists(context.Background(), "linkerd")
+		if err != nil || !exists {
+			t.Fatalf("Expected namespace to exist, got [%t] and [%v]", exists, err)
+		}
+
+		expected := []string{"/version", "/api/v1/namespaces/linkerd"}
+		if !reflect.DeepEqual(requestURIs, expected) {
+			t.Fatalf("Expected requests for %v, got %v", expected, requestURIs)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package k8s
+
+import (
+	"context"
+
+	"k8s.io/api/core/v1"
+)
+
+// GetConfigMap returns the ConfigMap with the given name in namespace. If it
+// does not exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetConfigMap(ctx context.Context, namespace, name string) (*v1.ConfigMap, error) {
+	var configMap v1.ConfigMap
+	if err := kubeAPI.getJSON(ctx, "/api/v1/namespaces/"+namespace+"/configmaps/"+name, &configMap); err != nil {
+		return nil, err
+	}
+	return &configMap, nil
+}
@@ -0,0 +1,40 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestGetConfigMap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/linkerd/configmaps/linkerd-ca-bundle":
+			w.Write([]byte(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"linkerd-ca-bundle","namespace":"linkerd"},"data":{"caBundle":"pem"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","reason":"NotFound","code":404}`))
+		}
+	}))
+	defer server.Close()
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Returns the ConfigMap", func(t *testing.T) {
+		configMap, err := api.GetConfigMap(context.Background(), "linkerd", "linkerd-ca-bundle")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if configMap.Name != "linkerd-ca-bundle" || configMap.Data["caBundle"] != "pem" {
+			t.Fatalf("Unexpected ConfigMap: %+v", configMap)
+		}
+	})
+
+	t.Run("Returns a not found error for missing ConfigMaps", func(t *testing.T) {
+		if _, err := api.GetConfigMap(context.Background(), "emojivoto", "linkerd-ca-bundle"); !IsNotFound(err) {
+			t.Fatalf("Expected a not found error, got [%v]", err)
+		}
+	})
+}
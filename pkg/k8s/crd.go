@@ -0,0 +1,98 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceProfileCRDName is the name of the CustomResourceDefinition for
+// ServiceProfiles.
+const ServiceProfileCRDName = "serviceprofiles.linkerd.io"
+
+// crdAPIVersions are the apiextensions.k8s.io versions tried, in order, when
+// getting a CustomResourceDefinition. v1beta1 is served by every supported
+// Kubernetes version, but is removed from newer ones in favor of v1.
+var crdAPIVersions = []string{"v1beta1", "v1"}
+
+// customResourceDefinition holds the fields of a CustomResourceDefinition
+// that are common to the v1beta1 and v1 APIs and that Linkerd needs.
+type customResourceDefinition struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Status            struct {
+		Conditions []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Reason  string `json:"reason,omitempty"`
+			Message string `json:"message,omitempty"`
+		} `json:"conditions,omitempty"`
+	} `json:"status,omitempty"`
+}
+
+// CRDNotEstablishedError is returned by ServiceProfilesAvailable when the
+// CustomResourceDefinition exists but has not been established yet, meaning
+// that the API server does not serve the custom resource yet. Callers may
+// wait and try again.
+type CRDNotEstablishedError struct {
+	Name    string
+	Message string
+}
+
+func (e *CRDNotEstablishedError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("CustomResourceDefinition [%s] is not established yet: %s", e.Name, e.Message)
+	}
+	return fmt.Sprintf("CustomResourceDefinition [%s] is not established yet", e.Name)
+}
+
+// IsCRDNotEstablished reports whether err is a CRDNotEstablishedError.
+func IsCRDNotEstablished(err error) bool {
+	_, ok := err.(*CRDNotEstablishedError)
+	return ok
+}
+
+func (kubeAPI *KubernetesAPI) getCRD(ctx context.Context, name string) (*customResourceDefinition, error) {
+	var err error
+	for _, version := range crdAPIVersions {
+		var crd customResourceDefinition
+		err = kubeAPI.getJSON(ctx, "/apis/apiextensions.k8s.io/"+version+"/customresourcedefinitions/"+name, &crd)
+		if err == nil {
+			return &crd, nil
+		}
+		// A 404 is returned both when the CRD does not exist and when the API
+		// version is not served, so only the last version's answer is final.
+		if !IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// CRDExists validates whether the CustomResourceDefinition with the given
+// name, e.g. ServiceProfileCRDName, exists.
+func (kubeAPI *KubernetesAPI) CRDExists(ctx context.Context, name string) (bool, error) {
+	_, err := kubeAPI.getCRD(ctx, name)
+	return existsFromError(err)
+}
+
+// ServiceProfilesAvailable reports whether the ServiceProfile
+// CustomResourceDefinition is installed and established. If it is installed
+// but not established yet, the returned error satisfies IsCRDNotEstablished.
+func (kubeAPI *KubernetesAPI) ServiceProfilesAvailable(ctx context.Context) (bool, error) {
+	crd, err := kubeAPI.getCRD(ctx, ServiceProfileCRDName)
+	if exists, err := existsFromError(err); !exists || err != nil {
+		return exists, err
+	}
+
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == "Established" {
+			if condition.Status == "True" {
+				return true, nil
+			}
+			return false, &CRDNotEstablishedError{Name: ServiceProfileCRDName, Message: condition.Message}
+		}
+	}
+	return false, &CRDNotEstablishedError{Name: ServiceProfileCRDName}
+}
@@ -0,0 +1,117 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	establishedCRD = `{"kind":"CustomResourceDefinition","metadata":{"name":"serviceprofiles.linkerd.io"},"status":{"conditions":[{"type":"NamesAccepted","status":"True"},{"type":"Established","status":"True"}]}}`
+	pendingCRD     = `{"kind":"CustomResourceDefinition","metadata":{"name":"serviceprofiles.linkerd.io"},"status":{"conditions":[{"type":"NamesAccepted","status":"True"},{"type":"Established","status":"False","message":"not all names are accepted"}]}}`
+)
+
+func crdServer(versions map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for version, body := range versions {
+			if r.URL.Path == "/apis/apiextensions.k8s.io/"+version+"/customresourcedefinitions/"+ServiceProfileCRDName {
+				w.Write([]byte(body))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+}
+
+func TestCRDExists(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		versions map[string]string
+		exists   bool
+	}{
+		{desc: "served by v1beta1", versions: map[string]string{"v1beta1": establishedCRD}, exists: true},
+		{desc: "served by v1 only", versions: map[string]string{"v1": establishedCRD}, exists: true},
+		{desc: "not installed", versions: map[string]string{}, exists: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			server := crdServer(tc.versions)
+			defer server.Close()
+
+			api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+			exists, err := api.CRDExists(context.Background(), ServiceProfileCRDName)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if exists != tc.exists {
+				t.Fatalf("Expected CRDExists to return [%t], got [%t]", tc.exists, exists)
+			}
+		})
+	}
+
+	t.Run("Returns other errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		if _, err := api.CRDExists(context.Background(), ServiceProfileCRDName); !IsForbidden(err) {
+			t.Fatalf("Expected a Forbidden error, got [%v]", err)
+		}
+	})
+}
+
+func TestServiceProfilesAvailable(t *testing.T) {
+	t.Run("Returns true when the CRD is established", func(t *testing.T) {
+		server := crdServer(map[string]string{"v1": establishedCRD})
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		available, err := api.ServiceProfilesAvailable(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !available {
+			t.Fatalf("Expected ServiceProfiles to be available")
+		}
+	})
+
+	t.Run("Returns false when the CRD is not installed", func(t *testing.T) {
+		server := crdServer(map[string]string{})
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		available, err := api.ServiceProfilesAvailable(context.Background())
+		if available || err != nil {
+			t.Fatalf("Expected [false, nil], got [%t, %v]", available, err)
+		}
+	})
+
+	t.Run("Reports CRDs that are not established yet", func(t *testing.T) {
+		server := crdServer(map[string]string{"v1beta1": pendingCRD})
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		available, err := api.ServiceProfilesAvailable(context.Background())
+		if available {
+			t.Fatalf("Expected ServiceProfiles not to be available")
+		}
+		if !IsCRDNotEstablished(err) {
+			t.Fatalf("Expected a CRDNotEstablishedError, got [%v]", err)
+		}
+		expected := "CustomResourceDefinition [serviceprofiles.linkerd.io] is not established yet: not all names are accepted"
+		if err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%s]", expected, err)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DeploymentStatus summarizes the rollout status of a deployment.
+type DeploymentStatus struct {
+	Name             string
+	DesiredReplicas  int32
+	ReadyReplicas    int32
+	UpToDateReplicas int32
+	// Reason explains why the deployment is unhealthy, and is empty when it
+	// is healthy.
+	Reason string
+}
+
+// Healthy returns true if all of the deployment's desired replicas are up to
+// date and ready.
+func (s *DeploymentStatus) Healthy() bool {
+	return s.Reason == ""
+}
+
+// GetDeploymentStatus returns the rollout status of the deployment with the
+// given name in namespace. If the deployment does not exist, the returned
+// error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetDeploymentStatus(ctx context.Context, namespace, name string) (*DeploymentStatus, error) {
+	deployment, err := kubeAPI.getDeployment(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return NewDeploymentStatus(deployment), nil
+}
+
+// GetPodsForDeployment returns the pods matching the selector of the
+// deployment with the given name in namespace. If the deployment does not
+// exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetPodsForDeployment(ctx context.Context, namespace, name string) ([]v1.Pod, error) {
+	deployment, err := kubeAPI.getDeployment(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// deployments always have a selector, and listing pods with an empty one
+	// would return all the pods of the namespace
+	if deployment.Spec.Selector == nil {
+		return nil, fmt.Errorf("deployment [%s] has no selector", name)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector of deployment [%s]: %s", name, err)
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("deployment [%s] has no selector", name)
+	}
+	return kubeAPI.GetPodsFor(ctx, namespace, selector.String())
+}
+
+func (kubeAPI *KubernetesAPI) getDeployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error) {
+	var deployment appsv1.Deployment
+	if err := kubeAPI.getJSON(ctx, "/apis/apps/v1/namespaces/"+namespace+"/deployments/"+name, &deployment); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// ListDeployments returns the deployments in namespace matching
+// labelSelector, e.g. "linkerd.io/control-plane-component". If no deployments
+// match, the returned slice is empty rather than nil.
+func (kubeAPI *KubernetesAPI) ListDeployments(ctx context.Context, namespace, labelSelector string) ([]appsv1.Deployment, error) {
+	deployments := []appsv1.Deployment{}
+	continueToken := ""
+	for {
+		var list appsv1.DeploymentList
+		opts := ListOptions{LabelSelector: labelSelector, Limit: listPageSize, Continue: continueToken}
+		if err := kubeAPI.listJSON(ctx, "/apis/apps/v1/namespaces/"+namespace+"/deployments", opts, &list); err != nil {
+			return nil, err
+		}
+
+		deployments = append(deployments, list.Items...)
+
+		continueToken = list.Continue
+		if continueToken == "" {
+			return deployments, nil
+		}
+	}
+}
+
+// NewDeploymentStatus summarizes the status of deployment. A failed rollout is
+// explained with the message of the deployment's ReplicaFailure or
+// Progressing condition.
+func NewDeploymentStatus(deployment *appsv1.Deployment) *DeploymentStatus {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+
+	status := &DeploymentStatus{
+		Name:             deployment.Name,
+		DesiredReplicas:  desired,
+		ReadyReplicas:    deployment.Status.ReadyReplicas,
+		UpToDateReplicas: deployment.Status.UpdatedReplicas,
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		switch {
+		case condition.Type == appsv1.DeploymentReplicaFailure && condition.Status == v1.ConditionTrue:
+			status.Reason = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+			return status
+		case condition.Type == appsv1.DeploymentProgressing && condition.Status == v1.ConditionFalse:
+			status.Reason = fmt.Sprintf("%s: %s", condition.Reason, condition.Message)
+			return status
+		}
+	}
+
+	switch {
+	case deployment.Status.ObservedGeneration < deployment.Generation:
+		status.Reason = "waiting for the deployment spec update to be observed"
+	case status.UpToDateReplicas < desired:
+		status.Reason = fmt.Sprintf("%d of %d replicas are up to date", status.UpToDateReplicas, desired)
+	case deployment.Status.Replicas > status.UpToDateReplicas:
+		status.Reason = fmt.Sprintf("%d old replicas are pending termination", deployment.Status.Replicas-status.UpToDateReplicas)
+	case status.ReadyReplicas < desired:
+		status.Reason = fmt.Sprintf("%d of %d replicas are ready", status.ReadyReplicas, desired)
+	}
+
+	return status
+}
@@ -0,0 +1,184 @@
+package k8s
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestGetDeploymentStatus(t *testing.T) {
+	fixtures := map[string]string{
+		"controller": "testdata/deployment_healthy.json",
+		"web":        "testdata/deployment_scaling.json",
+		"prometheus": "testdata/deployment_replica_failure.json",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, fixture := range fixtures {
+			if r.URL.Path == "/apis/apps/v1/namespaces/linkerd/deployments/"+name {
+				body, err := ioutil.ReadFile(fixture)
+				if err != nil {
+					t.Fatalf("Unexpected error reading fixture: %v", err)
+				}
+				w.Write(body)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	testCases := []struct {
+		name     string
+		expected DeploymentStatus
+	}{
+		{
+			name: "controller",
+			expected: DeploymentStatus{
+				Name:             "controller",
+				DesiredReplicas:  1,
+				ReadyReplicas:    1,
+				UpToDateReplicas: 1,
+			},
+		},
+		{
+			name: "web",
+			expected: DeploymentStatus{
+				Name:             "web",
+				DesiredReplicas:  3,
+				ReadyReplicas:    1,
+				UpToDateReplicas: 3,
+				Reason:           "1 of 3 replicas are ready",
+			},
+		},
+		{
+			name: "prometheus",
+			expected: DeploymentStatus{
+				Name:            "prometheus",
+				DesiredReplicas: 1,
+				Reason:          `FailedCreate: pods "prometheus-5d8c7b9f6-" is forbidden: error looking up service account linkerd/linkerd-prometheus: serviceaccount "linkerd-prometheus" not found`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, err := api.GetDeploymentStatus(context.Background(), "linkerd", tc.name)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if *status != tc.expected {
+				t.Fatalf("Expected status %+v, got %+v", tc.expected, *status)
+			}
+			if status.Healthy() != (tc.expected.Reason == "") {
+				t.Fatalf("Expected Healthy to return [%t]", tc.expected.Reason == "")
+			}
+		})
+	}
+
+	t.Run("Returns a NotFound error for missing deployments", func(t *testing.T) {
+		_, err := api.GetDeploymentStatus(context.Background(), "linkerd", "grafana")
+		if !IsNotFound(err) {
+			t.Fatalf("Expected a NotFound error, got [%v]", err)
+		}
+	})
+}
+
+func TestGetPodsForDeployment(t *testing.T) {
+	var selectors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/apps/v1/namespaces/linkerd/deployments/controller":
+			body, err := ioutil.ReadFile("testdata/deployment_healthy.json")
+			if err != nil {
+				t.Fatalf("Unexpected error reading fixture: %v", err)
+			}
+			w.Write(body)
+		case "/apis/apps/v1/namespaces/linkerd/deployments/selectorless":
+			w.Write([]byte(`{"metadata":{"name":"selectorless"},"spec":{}}`))
+		case "/api/v1/namespaces/linkerd/pods":
+			selectors = append(selectors, r.URL.Query().Get("labelSelector"))
+			w.Write([]byte(`{"items":[{"metadata":{"name":"controller-6f78cbd47-bclfd"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Returns the pods matching the selector of the deployment", func(t *testing.T) {
+		pods, err := api.GetPodsForDeployment(context.Background(), "linkerd", "controller")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pods) != 1 || pods[0].Name != "controller-6f78cbd47-bclfd" {
+			t.Fatalf("Expected pods [controller-6f78cbd47-bclfd], got %+v", pods)
+		}
+		if len(selectors) != 1 || selectors[0] != "linkerd.io/control-plane-component=controller" {
+			t.Fatalf("Expected the pods to be listed by the selector of the deployment, got %v", selectors)
+		}
+	})
+
+	t.Run("Returns an error for deployments without a selector", func(t *testing.T) {
+		_, err := api.GetPodsForDeployment(context.Background(), "linkerd", "selectorless")
+		expected := "deployment [selectorless] has no selector"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Returns a NotFound error for missing deployments", func(t *testing.T) {
+		_, err := api.GetPodsForDeployment(context.Background(), "linkerd", "grafana")
+		if !IsNotFound(err) {
+			t.Fatalf("Expected a NotFound error, got [%v]", err)
+		}
+	})
+}
+
+func TestListDeployments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/apps/v1/namespaces/linkerd/deployments" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("labelSelector") != ControllerComponentLabel {
+			w.Write([]byte(`{"items":[]}`))
+			return
+		}
+		if r.URL.Query().Get("continue") == "" {
+			w.Write([]byte(`{"metadata":{"continue":"page-2"},"items":[{"metadata":{"name":"controller"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"items":[{"metadata":{"name":"web"}}]}`))
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Returns the matching deployments across pages", func(t *testing.T) {
+		deployments, err := api.ListDeployments(context.Background(), "linkerd", ControllerComponentLabel)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(deployments) != 2 || deployments[0].Name != "controller" || deployments[1].Name != "web" {
+			t.Fatalf("Expected deployments [controller web], got %+v", deployments)
+		}
+	})
+
+	t.Run("Returns an empty slice when no deployments match", func(t *testing.T) {
+		deployments, err := api.ListDeployments(context.Background(), "linkerd", "app=emoji")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if deployments == nil || len(deployments) != 0 {
+			t.Fatalf("Expected an empty slice, got %+v", deployments)
+		}
+	})
+}
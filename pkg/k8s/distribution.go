@@ -0,0 +1,41 @@
+package k8s
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// openShiftAPIGroups are API groups that are only served by OpenShift.
+var openShiftAPIGroups = map[string]bool{
+	"security.openshift.io": true,
+	"route.openshift.io":    true,
+}
+
+// IsOpenShift reports whether the cluster is running OpenShift, based on the
+// API groups listed by the /apis discovery endpoint. The first successful
+// result is cached.
+func (kubeAPI *KubernetesAPI) IsOpenShift(ctx context.Context) (bool, error) {
+	kubeAPI.openShiftMu.Lock()
+	defer kubeAPI.openShiftMu.Unlock()
+
+	if kubeAPI.openShift != nil {
+		return *kubeAPI.openShift, nil
+	}
+
+	var groups metav1.APIGroupList
+	if err := kubeAPI.getJSON(ctx, "/apis", &groups); err != nil {
+		return false, err
+	}
+
+	openShift := false
+	for _, group := range groups.Groups {
+		if openShiftAPIGroups[group.Name] {
+			openShift = true
+			break
+		}
+	}
+
+	kubeAPI.openShift = &openShift
+	return openShift, nil
+}
@@ -0,0 +1,90 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestIsOpenShift(t *testing.T) {
+	kubernetesGroups := `{
+  "kind": "APIGroupList",
+  "apiVersion": "v1",
+  "groups": [
+    {"name": "apps", "versions": [{"groupVersion": "apps/v1", "version": "v1"}], "preferredVersion": {"groupVersion": "apps/v1", "version": "v1"}},
+    {"name": "extensions", "versions": [{"groupVersion": "extensions/v1beta1", "version": "v1beta1"}], "preferredVersion": {"groupVersion": "extensions/v1beta1", "version": "v1beta1"}}
+  ]
+}`
+	openShiftGroups := `{
+  "kind": "APIGroupList",
+  "apiVersion": "v1",
+  "groups": [
+    {"name": "apps", "versions": [{"groupVersion": "apps/v1", "version": "v1"}], "preferredVersion": {"groupVersion": "apps/v1", "version": "v1"}},
+    {"name": "route.openshift.io", "versions": [{"groupVersion": "route.openshift.io/v1", "version": "v1"}], "preferredVersion": {"groupVersion": "route.openshift.io/v1", "version": "v1"}},
+    {"name": "security.openshift.io", "versions": [{"groupVersion": "security.openshift.io/v1", "version": "v1"}], "preferredVersion": {"groupVersion": "security.openshift.io/v1", "version": "v1"}}
+  ]
+}`
+
+	testCases := map[string]struct {
+		groups    string
+		openShift bool
+	}{
+		"Kubernetes": {kubernetesGroups, false},
+		"OpenShift":  {openShiftGroups, true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				if r.URL.Path != "/apis" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(tc.groups))
+			}))
+			defer server.Close()
+
+			api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+			for i := 0; i < 2; i++ {
+				openShift, err := api.IsOpenShift(context.Background())
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if openShift != tc.openShift {
+					t.Fatalf("Expected IsOpenShift to return [%t], got [%t]", tc.openShift, openShift)
+				}
+			}
+
+			if n := atomic.LoadInt32(&requests); n != 1 {
+				t.Fatalf("Expected the result to be cached after 1 request, got %d requests", n)
+			}
+		})
+	}
+
+	t.Run("Does not cache errors", func(t *testing.T) {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		for i := 0; i < 2; i++ {
+			if _, err := api.IsOpenShift(context.Background()); err == nil {
+				t.Fatalf("Expected error, got nil")
+			}
+		}
+		if n := atomic.LoadInt32(&requests); n != 2 {
+			t.Fatalf("Expected 2 requests, got %d", n)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package k8s
+
+import (
+	"context"
+
+	"k8s.io/api/core/v1"
+)
+
+// EndpointAddress is an address backing a service, as listed in its Endpoints
+// object.
+type EndpointAddress struct {
+	IP    string
+	Ready bool
+	// Pod is the pod the address belongs to, or nil if the address does not
+	// target a pod, e.g. for services with manually managed endpoints.
+	Pod *v1.ObjectReference
+}
+
+// GetServiceEndpoints returns the Endpoints object of the given service. If
+// the service does not exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetServiceEndpoints(ctx context.Context, namespace, service string) (*v1.Endpoints, error) {
+	var endpoints v1.Endpoints
+	if err := kubeAPI.getJSON(ctx, "/api/v1/namespaces/"+namespace+"/endpoints/"+service, &endpoints); err != nil {
+		return nil, err
+	}
+	return &endpoints, nil
+}
+
+// GetServiceEndpointAddresses is like GetServiceEndpoints, but flattens the
+// Endpoints object into the list of its ready and not ready addresses.
+func (kubeAPI *KubernetesAPI) GetServiceEndpointAddresses(ctx context.Context, namespace, service string) ([]EndpointAddress, error) {
+	endpoints, err := kubeAPI.GetServiceEndpoints(ctx, namespace, service)
+	if err != nil {
+		return nil, err
+	}
+	return EndpointAddresses(endpoints), nil
+}
+
+// EndpointAddresses returns the addresses of all subsets of endpoints, ready
+// addresses first. Addresses listed in several subsets, e.g. because the
+// service exposes several ports, are only returned once.
+func EndpointAddresses(endpoints *v1.Endpoints) []EndpointAddress {
+	addresses := []EndpointAddress{}
+	seen := make(map[EndpointAddress]bool)
+
+	add := func(address v1.EndpointAddress, ready bool) {
+		key := EndpointAddress{IP: address.IP, Ready: ready}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		endpoint := EndpointAddress{IP: address.IP, Ready: ready}
+		if address.TargetRef != nil && address.TargetRef.Kind == "Pod" {
+			pod := *address.TargetRef
+			endpoint.Pod = &pod
+		}
+		addresses = append(addresses, endpoint)
+	}
+
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			add(address, true)
+		}
+	}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.NotReadyAddresses {
+			add(address, false)
+		}
+	}
+
+	return addresses
+}
@@ -0,0 +1,73 @@
+package k8s
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestGetServiceEndpointAddresses(t *testing.T) {
+	fixture, err := ioutil.ReadFile("testdata/endpoints.json")
+	if err != nil {
+		t.Fatalf("Unexpected error reading fixture: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/namespaces/emojivoto/endpoints/web-svc" {
+			w.Write(fixture)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Flattens ready and not ready addresses", func(t *testing.T) {
+		addresses, err := api.GetServiceEndpointAddresses(context.Background(), "emojivoto", "web-svc")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []struct {
+			ip    string
+			ready bool
+			pod   string
+		}{
+			{ip: "10.32.0.14", ready: true, pod: "web-5f86686c4d-58p7k"},
+			{ip: "192.168.10.5", ready: true},
+			{ip: "10.32.1.9", ready: false, pod: "web-5f86686c4d-x2qvm"},
+		}
+		if len(addresses) != len(expected) {
+			t.Fatalf("Expected %d addresses, got %d: %+v", len(expected), len(addresses), addresses)
+		}
+
+		for i, exp := range expected {
+			address := addresses[i]
+			if address.IP != exp.ip || address.Ready != exp.ready {
+				t.Fatalf("Expected address [%s] with ready [%t], got [%s] with ready [%t]", exp.ip, exp.ready, address.IP, address.Ready)
+			}
+
+			if exp.pod == "" {
+				if address.Pod != nil {
+					t.Fatalf("Expected address [%s] not to reference a pod, got [%s]", address.IP, address.Pod.Name)
+				}
+				continue
+			}
+			if address.Pod == nil || address.Pod.Name != exp.pod || address.Pod.Namespace != "emojivoto" {
+				t.Fatalf("Expected address [%s] to reference pod [emojivoto/%s], got %+v", address.IP, exp.pod, address.Pod)
+			}
+		}
+	})
+
+	t.Run("Returns a NotFound error for missing services", func(t *testing.T) {
+		_, err := api.GetServiceEndpoints(context.Background(), "emojivoto", "missing")
+		if !IsNotFound(err) {
+			t.Fatalf("Expected a NotFound error, got [%v]", err)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+)
+
+// DefaultMaxEvents is the number of events returned by GetEventsFor when no
+// MaxEvents is configured.
+const DefaultMaxEvents = 10
+
+// GetEventsFor returns the most recent events in namespace about the object of
+// the given kind and name, e.g. "Pod" and "controller-6f78cbd47-bc557", most
+// recent first. At most MaxEvents events are returned.
+func (kubeAPI *KubernetesAPI) GetEventsFor(ctx context.Context, namespace, kind, name string) ([]v1.Event, error) {
+	fieldSelector := fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind, name)
+	path := "/api/v1/namespaces/" + namespace + "/events"
+
+	events := []v1.Event{}
+	continueToken := ""
+	for {
+		var list v1.EventList
+		opts := ListOptions{FieldSelector: fieldSelector, Limit: listPageSize, Continue: continueToken}
+		if err := kubeAPI.listJSON(ctx, path, opts, &list); err != nil {
+			return nil, err
+		}
+
+		events = append(events, list.Items...)
+
+		continueToken = list.Continue
+		if continueToken == "" {
+			break
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).After(eventTime(events[j]))
+	})
+
+	if max := kubeAPI.maxEvents(); len(events) > max {
+		events = events[:max]
+	}
+	return events, nil
+}
+
+// GetWarningEventsFor is like GetEventsFor, but only returns Warning events,
+// e.g. FailedScheduling, FailedMount or BackOff.
+func (kubeAPI *KubernetesAPI) GetWarningEventsFor(ctx context.Context, namespace, kind, name string) ([]v1.Event, error) {
+	events, err := kubeAPI.GetEventsFor(ctx, namespace, kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := []v1.Event{}
+	for _, event := range events {
+		if event.Type == v1.EventTypeWarning {
+			warnings = append(warnings, event)
+		}
+	}
+	return warnings, nil
+}
+
+// FormatEvents formats events as one "Reason: message" line per event, each
+// indented with a tab, for inclusion in error messages.
+func FormatEvents(events []v1.Event) string {
+	lines := make([]string, len(events))
+	for i, event := range events {
+		lines[i] = fmt.Sprintf("\t%s: %s", event.Reason, strings.TrimSpace(event.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (kubeAPI *KubernetesAPI) maxEvents() int {
+	if kubeAPI.MaxEvents <= 0 {
+		return DefaultMaxEvents
+	}
+	return kubeAPI.MaxEvents
+}
+
+// eventTime returns the time an event last occurred. Events recorded by newer
+// clients may only set EventTime.
+func eventTime(event v1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.FirstTimestamp.Time
+	}
+}
@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+const podEvents = `{"kind":"EventList","apiVersion":"v1","metadata":{},"items":[
+	{"metadata":{"name":"web-1.1"},"involvedObject":{"kind":"Pod","name":"web-1"},"reason":"Scheduled","message":"Successfully assigned emojivoto/web-1 to node-1","type":"Normal","lastTimestamp":"2018-09-01T10:00:00Z"},
+	{"metadata":{"name":"web-1.2"},"involvedObject":{"kind":"Pod","name":"web-1"},"reason":"BackOff","message":"Back-off restarting failed container","type":"Warning","lastTimestamp":"2018-09-01T10:05:00Z"},
+	{"metadata":{"name":"web-1.3"},"involvedObject":{"kind":"Pod","name":"web-1"},"reason":"FailedMount","message":"MountVolume.SetUp failed for volume \"config\" : configmap \"web-config\" not found\n","type":"Warning","lastTimestamp":"2018-09-01T10:02:00Z"},
+	{"metadata":{"name":"web-1.4"},"involvedObject":{"kind":"Pod","name":"web-1"},"reason":"Pulled","message":"Container image \"buoyantio/emojivoto-web:v6\" already present on machine","type":"Normal","eventTime":"2018-09-01T10:03:00.000000Z"}
+]}`
+
+func TestGetEventsFor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/emojivoto/events" {
+			t.Fatalf("Unexpected path [%s]", r.URL.Path)
+		}
+		if !strings.Contains(r.URL.RawQuery, "fieldSelector=involvedObject.kind%3DPod%2CinvolvedObject.name%3D") {
+			t.Fatalf("Expected field selector to be URL-encoded, got [%s]", r.URL.RawQuery)
+		}
+
+		switch r.URL.Query().Get("fieldSelector") {
+		case "involvedObject.kind=Pod,involvedObject.name=web-1":
+			w.Write([]byte(podEvents))
+		default:
+			w.Write([]byte(`{"kind":"EventList","apiVersion":"v1","metadata":{},"items":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	t.Run("Returns events most recent first", func(t *testing.T) {
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		events, err := api.GetEventsFor(context.Background(), "emojivoto", "Pod", "web-1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []string{"BackOff", "Pulled", "FailedMount", "Scheduled"}
+		if len(events) != len(expected) {
+			t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+		}
+		for i, reason := range expected {
+			if events[i].Reason != reason {
+				t.Fatalf("Expected event %d to be [%s], got [%s]", i, reason, events[i].Reason)
+			}
+		}
+	})
+
+	t.Run("Caps the number of events", func(t *testing.T) {
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, MaxEvents: 2}
+
+		events, err := api.GetEventsFor(context.Background(), "emojivoto", "Pod", "web-1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(events) != 2 || events[1].Reason != "Pulled" {
+			t.Fatalf("Expected the 2 most recent events, got %v", events)
+		}
+	})
+
+	t.Run("Returns an empty slice when there are no events", func(t *testing.T) {
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		events, err := api.GetEventsFor(context.Background(), "emojivoto", "Pod", "web-2")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if events == nil || len(events) != 0 {
+			t.Fatalf("Expected an empty slice, got %#v", events)
+		}
+	})
+
+	t.Run("Filters and formats warnings", func(t *testing.T) {
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		events, err := api.GetWarningEventsFor(context.Background(), "emojivoto", "Pod", "web-1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "\tBackOff: Back-off restarting failed container\n\tFailedMount: MountVolume.SetUp failed for volume \"config\" : configmap \"web-config\" not found"
+		if formatted := FormatEvents(events); formatted != expected {
+			t.Fatalf("Expected [%s], got [%s]", expected, formatted)
+		}
+	})
+}
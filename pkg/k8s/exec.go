@@ -0,0 +1,193 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/client-go/util/exec"
+)
+
+// ExecExitError is returned by ExecInPod when the command exits with a
+// non-zero code.
+type ExecExitError struct {
+	Pod       string
+	Container string
+	Command   []string
+	Code      int
+}
+
+func (e *ExecExitError) Error() string {
+	return fmt.Sprintf("command [%s] in container [%s] of pod [%s] exited with code %d", strings.Join(e.Command, " "), e.Container, e.Pod, e.Code)
+}
+
+// ContainerNotFoundError is returned by ExecInPod when the pod has no
+// container with the given name.
+type ContainerNotFoundError struct {
+	Pod        string
+	Container  string
+	Containers []string
+}
+
+func (e *ContainerNotFoundError) Error() string {
+	return fmt.Sprintf("pod [%s] has no container named [%s], choose one of: %s", e.Pod, e.Container, strings.Join(e.Containers, ", "))
+}
+
+// CommandNotFoundError is returned by ExecInPod when the container runtime
+// cannot find the command, e.g. a shell in a container built without one.
+type CommandNotFoundError struct {
+	Pod       string
+	Container string
+	Command   string
+	Message   string
+}
+
+func (e *CommandNotFoundError) Error() string {
+	return fmt.Sprintf("command [%s] not found in container [%s] of pod [%s]: %s", e.Command, e.Container, e.Pod, e.Message)
+}
+
+// IsCommandNotFound reports whether err, returned by ExecInPod, means the
+// command does not exist in the container. Depending on the container runtime,
+// this is reported either as a failure to exec, or, like shells do, as exit
+// code 126 or 127.
+func IsCommandNotFound(err error) bool {
+	switch err := err.(type) {
+	case *CommandNotFoundError:
+		return true
+	case *ExecExitError:
+		return err.Code == 126 || err.Code == 127
+	}
+	return false
+}
+
+// ExecInPod runs command in a container of the given pod, like kubectl exec,
+// streaming stdin to it and its output to stdout and stderr, any of which may
+// be nil. container may be empty for pods with a single container. A non-zero
+// exit of the command is returned as an ExecExitError. Unlike other requests,
+// ExecInPod is not subject to the configured Timeout; cancelling ctx closes
+// the connection to the pod.
+func (kubeAPI *KubernetesAPI) ExecInPod(ctx context.Context, namespace, pod, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(command) == 0 {
+		return fmt.Errorf("no command specified to run in pod [%s]", pod)
+	}
+
+	p, err := kubeAPI.GetPod(ctx, namespace, pod)
+	if err != nil {
+		return err
+	}
+	container, err = execContainer(p, container)
+	if err != nil {
+		return err
+	}
+
+	u, err := kubeAPI.execURL(namespace, pod, container, command, stdin != nil, stdout != nil, stderr != nil)
+	if err != nil {
+		return err
+	}
+
+	rt, upgrader, err := kubeAPI.UpgradeRoundTripper()
+	if err != nil {
+		return err
+	}
+	executor, err := remotecommand.NewSPDYExecutorForTransports(
+		&contextRoundTripper{ctx: ctx, rt: rt},
+		&contextUpgrader{ctx: ctx, upgrader: upgrader},
+		"POST", u,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = executor.Stream(remotecommand.StreamOptions{Stdin: stdin, Stdout: stdout, Stderr: stderr})
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err == nil {
+		return nil
+	}
+
+	if exitErr, ok := err.(exec.CodeExitError); ok {
+		return &ExecExitError{Pod: pod, Container: container, Command: command, Code: exitErr.Code}
+	}
+	if msg := err.Error(); strings.Contains(msg, "executable file not found") || strings.Contains(msg, "no such file or directory") {
+		return &CommandNotFoundError{Pod: pod, Container: container, Command: command[0], Message: msg}
+	}
+	return fmt.Errorf("error running command [%s] in container [%s] of pod [%s]: %v", strings.Join(command, " "), container, pod, err)
+}
+
+// execContainer validates container against the containers of pod, defaulting
+// it to the only container of single-container pods.
+func execContainer(pod *v1.Pod, container string) (string, error) {
+	names := make([]string, len(pod.Spec.Containers))
+	for i, c := range pod.Spec.Containers {
+		if c.Name == container {
+			return container, nil
+		}
+		names[i] = c.Name
+	}
+
+	if container == "" {
+		if len(names) == 1 {
+			return names[0], nil
+		}
+		return "", fmt.Errorf("pod [%s] has several containers, choose one of: %s", pod.Name, strings.Join(names, ", "))
+	}
+	return "", &ContainerNotFoundError{Pod: pod.Name, Container: container, Containers: names}
+}
+
+// execURL returns the URL of the exec subresource of pod. Each argument of
+// command is passed as a separate command parameter.
+func (kubeAPI *KubernetesAPI) execURL(namespace, pod, container string, command []string, stdin, stdout, stderr bool) (*url.URL, error) {
+	query := url.Values{
+		"command":   command,
+		"container": []string{container},
+		"tty":       []string{"false"},
+	}
+	for name, enabled := range map[string]bool{"stdin": stdin, "stdout": stdout, "stderr": stderr} {
+		if enabled {
+			query.Set(name, "true")
+		}
+	}
+	return kubeAPI.UrlForGroupVersion(namespace, "", "v1", "/pods/"+pod+"/exec", query)
+}
+
+// contextRoundTripper binds the requests it sends to ctx, for executors that
+// create their own requests.
+type contextRoundTripper struct {
+	ctx context.Context
+	rt  http.RoundTripper
+}
+
+func (c *contextRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return c.rt.RoundTrip(req.WithContext(c.ctx))
+}
+
+// contextUpgrader closes the connections it upgrades once ctx is done, since
+// cancelling the context of a request has no effect after the upgrade.
+type contextUpgrader struct {
+	ctx      context.Context
+	upgrader spdy.Upgrader
+}
+
+func (c *contextUpgrader) NewConnection(rsp *http.Response) (httpstream.Connection, error) {
+	conn, err := c.upgrader.NewConnection(rsp)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		select {
+		case <-c.ctx.Done():
+			conn.Close()
+		case <-conn.CloseChan():
+		}
+	}()
+	return conn, nil
+}
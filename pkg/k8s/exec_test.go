@@ -0,0 +1,228 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/client-go/rest"
+)
+
+func TestExecURL(t *testing.T) {
+	api := &KubernetesAPI{Config: &rest.Config{Host: "https://55.197.171.239"}}
+
+	testCases := []struct {
+		command  []string
+		stdin    bool
+		expected string
+	}{
+		{
+			command:  []string{"iptables", "-t", "nat", "-L"},
+			expected: "https://55.197.171.239/api/v1/namespaces/emojivoto/pods/web-1/exec?command=iptables&command=-t&command=nat&command=-L&container=linkerd-proxy&stderr=true&stdout=true&tty=false",
+		},
+		{
+			command:  []string{"sh", "-c", "nslookup web-svc && echo ok"},
+			stdin:    true,
+			expected: "https://55.197.171.239/api/v1/namespaces/emojivoto/pods/web-1/exec?command=sh&command=-c&command=nslookup+web-svc+%26%26+echo+ok&container=linkerd-proxy&stderr=true&stdin=true&stdout=true&tty=false",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strings.Join(tc.command, " "), func(t *testing.T) {
+			u, err := api.execURL("emojivoto", "web-1", "linkerd-proxy", tc.command, tc.stdin, true, true)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if u.String() != tc.expected {
+				t.Fatalf("Expected exec URL to be [%s], but got [%s]", tc.expected, u.String())
+			}
+		})
+	}
+}
+
+// execServer is a fake API server for a pod with a web and a linkerd-proxy
+// container, which runs a few commands in them over the v4 remote command
+// protocol.
+func execServer(t *testing.T) *httptest.Server {
+	pod := v1.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "emojivoto"},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web"}, {Name: "linkerd-proxy"}}},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/emojivoto/pods/web-1":
+			json.NewEncoder(w).Encode(pod)
+			return
+		case "/api/v1/namespaces/emojivoto/pods/web-1/exec":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		query := r.URL.Query()
+		if query.Get("tty") != "false" {
+			t.Errorf("Expected tty=false, got [%s]", query.Get("tty"))
+		}
+
+		// The error stream, and one for each of stdin, stdout and stderr used.
+		expected := 1
+		for _, name := range []string{"stdin", "stdout", "stderr"} {
+			if query.Get(name) == "true" {
+				expected++
+			}
+		}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		wg.Add(expected)
+		streams := map[string]httpstream.Stream{}
+
+		if _, err := httpstream.Handshake(r, w, []string{"v4.channel.k8s.io"}); err != nil {
+			return
+		}
+		conn := spdy.NewResponseUpgrader().UpgradeResponse(w, r, func(stream httpstream.Stream, replySent <-chan struct{}) error {
+			go func() {
+				<-replySent
+				mu.Lock()
+				streams[stream.Headers().Get(v1.StreamType)] = stream
+				mu.Unlock()
+				wg.Done()
+			}()
+			return nil
+		})
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+		wg.Wait()
+
+		status := metav1.Status{Status: metav1.StatusSuccess}
+		switch command := query["command"]; command[0] {
+		case "echo":
+			streams[v1.StreamTypeStdout].Write([]byte(strings.Join(command[1:], " ")))
+		case "cat":
+			io.Copy(streams[v1.StreamTypeStdout], streams[v1.StreamTypeStdin])
+		case "false":
+			status = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Reason:  "NonZeroExitCode",
+				Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{{Type: "ExitCode", Message: "1"}}},
+			}
+		case "sleep":
+			<-conn.CloseChan()
+			return
+		default:
+			streams[v1.StreamTypeStderr].Write([]byte("error: " + command[0] + " not found"))
+			status = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: `OCI runtime exec failed: exec failed: container_linux.go:348: starting container process caused "exec: \"` + command[0] + `\": executable file not found in $PATH": unknown`,
+			}
+		}
+
+		streams[v1.StreamTypeStdout].Close()
+		streams[v1.StreamTypeStderr].Close()
+		body, _ := json.Marshal(status)
+		streams[v1.StreamTypeError].Write(body)
+		streams[v1.StreamTypeError].Close()
+		<-conn.CloseChan()
+	}))
+}
+
+func TestExecInPod(t *testing.T) {
+	server := execServer(t)
+	defer server.Close()
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Streams the output of the command", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		err := api.ExecInPod(context.Background(), "emojivoto", "web-1", "linkerd-proxy", []string{"echo", "hello", "world"}, nil, &stdout, &stderr)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if stdout.String() != "hello world" {
+			t.Fatalf("Expected stdout [hello world], got [%s]", stdout.String())
+		}
+	})
+
+	t.Run("Streams stdin to the command", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		err := api.ExecInPod(context.Background(), "emojivoto", "web-1", "web", []string{"cat"}, strings.NewReader("some input"), &stdout, &stderr)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if stdout.String() != "some input" {
+			t.Fatalf("Expected stdout [some input], got [%s]", stdout.String())
+		}
+	})
+
+	t.Run("Returns the exit code of the command", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		err := api.ExecInPod(context.Background(), "emojivoto", "web-1", "web", []string{"false"}, nil, &stdout, &stderr)
+		exitErr, ok := err.(*ExecExitError)
+		if !ok {
+			t.Fatalf("Expected an ExecExitError, got [%v]", err)
+		}
+		if exitErr.Code != 1 || IsCommandNotFound(err) {
+			t.Fatalf("Expected exit code 1, got [%v]", err)
+		}
+		expected := "command [false] in container [web] of pod [web-1] exited with code 1"
+		if err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%s]", expected, err)
+		}
+	})
+
+	t.Run("Returns an error for commands that do not exist", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		err := api.ExecInPod(context.Background(), "emojivoto", "web-1", "linkerd-proxy", []string{"sh", "-c", "iptables -L"}, nil, &stdout, &stderr)
+		if _, ok := err.(*CommandNotFoundError); !ok || !IsCommandNotFound(err) {
+			t.Fatalf("Expected a CommandNotFoundError, got [%v]", err)
+		}
+	})
+
+	t.Run("Returns an error for containers that do not exist", func(t *testing.T) {
+		err := api.ExecInPod(context.Background(), "emojivoto", "web-1", "proxy-init", []string{"iptables", "-L"}, nil, nil, nil)
+		expected := "pod [web-1] has no container named [proxy-init], choose one of: web, linkerd-proxy"
+		if _, ok := err.(*ContainerNotFoundError); !ok || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Requires a container for pods with several", func(t *testing.T) {
+		err := api.ExecInPod(context.Background(), "emojivoto", "web-1", "", []string{"true"}, nil, nil, nil)
+		expected := "pod [web-1] has several containers, choose one of: web, linkerd-proxy"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Returns a not found error for pods that do not exist", func(t *testing.T) {
+		err := api.ExecInPod(context.Background(), "emojivoto", "web-2", "web", []string{"true"}, nil, nil, nil)
+		if !IsNotFound(err) {
+			t.Fatalf("Expected a not found error, got [%v]", err)
+		}
+	})
+
+	t.Run("Stops when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		var stdout bytes.Buffer
+		err := api.ExecInPod(ctx, "emojivoto", "web-1", "web", []string{"sleep", "60"}, nil, &stdout, nil)
+		if err != context.DeadlineExceeded {
+			t.Fatalf("Expected [%v], got [%v]", context.DeadlineExceeded, err)
+		}
+	})
+}
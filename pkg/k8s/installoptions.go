@@ -0,0 +1,47 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// InstallOptions records the flags `linkerd install` was run with, so that
+// checks can take them into account, and upgrades can apply them again.
+type InstallOptions struct {
+	Flags []InstallFlag `json:"flags"`
+}
+
+// InstallFlag is a flag that was set on the command line, and its value.
+type InstallFlag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Flag returns the value of the flag with the given name, and whether it was
+// set.
+func (o *InstallOptions) Flag(name string) (string, bool) {
+	for _, flag := range o.Flags {
+		if flag.Name == name {
+			return flag.Value, true
+		}
+	}
+	return "", false
+}
+
+// GetInstallOptions returns the options recorded in the InstallConfigMapName
+// ConfigMap of the control plane namespace. If it does not exist, e.g. for
+// control planes installed by older versions, the returned error satisfies
+// IsNotFound.
+func (kubeAPI *KubernetesAPI) GetInstallOptions(ctx context.Context, namespace string) (*InstallOptions, error) {
+	configMap, err := kubeAPI.GetConfigMap(ctx, namespace, InstallConfigMapName)
+	if err != nil {
+		return nil, err
+	}
+
+	var options InstallOptions
+	if err := json.Unmarshal([]byte(configMap.Data[InstallOptionsKey]), &options); err != nil {
+		return nil, fmt.Errorf("invalid install options in ConfigMap [%s/%s]: %v", namespace, InstallConfigMapName, err)
+	}
+	return &options, nil
+}
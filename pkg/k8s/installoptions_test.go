@@ -0,0 +1,54 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestGetInstallOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/linkerd/configmaps/linkerd-config":
+			w.Write([]byte(`{"kind":"ConfigMap","apiVersion":"v1","data":{"install":"{\"flags\":[{\"name\":\"ha\",\"value\":\"true\"}]}\n"}}`))
+		case "/api/v1/namespaces/invalid/configmaps/linkerd-config":
+			w.Write([]byte(`{"kind":"ConfigMap","apiVersion":"v1","data":{"install":"--ha"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","reason":"NotFound","code":404}`))
+		}
+	}))
+	defer server.Close()
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Returns the recorded flags", func(t *testing.T) {
+		options, err := api.GetInstallOptions(context.Background(), "linkerd")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if value, ok := options.Flag("ha"); !ok || value != "true" {
+			t.Fatalf("Expected flag [ha] to be [true], got [%s] and %t", value, ok)
+		}
+		if _, ok := options.Flag("controller-replicas"); ok {
+			t.Fatalf("Expected flag [controller-replicas] not to be set")
+		}
+	})
+
+	t.Run("Rejects invalid options", func(t *testing.T) {
+		_, err := api.GetInstallOptions(context.Background(), "invalid")
+		if err == nil || !strings.HasPrefix(err.Error(), "invalid install options in ConfigMap [invalid/linkerd-config]") {
+			t.Fatalf("Expected an invalid options error, got [%v]", err)
+		}
+	})
+
+	t.Run("Returns a not found error for older installs", func(t *testing.T) {
+		_, err := api.GetInstallOptions(context.Background(), "emojivoto")
+		if !IsNotFound(err) {
+			t.Fatalf("Expected a not found error, got [%v]", err)
+		}
+	})
+}
@@ -2,10 +2,17 @@ package k8s
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
 )
 
 const (
@@ -31,42 +38,168 @@ var StatAllResourceTypes = []string{
 }
 
 func generateKubernetesApiBaseUrlFor(schemeHostAndPort string, namespace string, extraPathStartingWithSlash string) (*url.URL, error) {
-	if string(extraPathStartingWithSlash[0]) != "/" {
+	return generateKubernetesApiUrlForGroupVersion(schemeHostAndPort, namespace, "", "v1", extraPathStartingWithSlash, nil)
+}
+
+// generateKubernetesApiUrlForGroupVersion generates a URL for a resource in
+// the given API group and version, e.g. "apps" and "v1". The core API group is
+// selected with an empty group. If namespace is empty, the URL is for a
+// cluster-scoped resource, or for a resource in all namespaces. Query
+// parameters must be passed in query; an extra path containing a query string
+// is rejected, since it would be encoded twice.
+func generateKubernetesApiUrlForGroupVersion(schemeHostAndPort, namespace, group, version, extraPathStartingWithSlash string, query url.Values) (*url.URL, error) {
+	if extraPathStartingWithSlash != "" && extraPathStartingWithSlash[0] != '/' {
 		return nil, fmt.Errorf("Path must start with a [/], was [%s]", extraPathStartingWithSlash)
 	}
+	if strings.Contains(extraPathStartingWithSlash, "?") {
+		return nil, fmt.Errorf("Path must not contain a query string, was [%s]", extraPathStartingWithSlash)
+	}
+	if version == "" {
+		return nil, fmt.Errorf("API version must be specified for group [%s]", group)
+	}
 
-	baseURL, err := generateBaseKubernetesApiUrl(schemeHostAndPort)
-	if err != nil {
-		return nil, err
+	path := "/api/" + version
+	if group != "" {
+		path = "/apis/" + group + "/" + version
+	}
+	if namespace != "" {
+		path += "/namespaces/" + url.PathEscape(namespace)
 	}
+	path += extraPathStartingWithSlash
 
-	urlString := fmt.Sprintf("%snamespaces/%s%s", baseURL.String(), namespace, extraPathStartingWithSlash)
-	url, err := url.Parse(urlString)
+	url, err := joinURL(schemeHostAndPort, path)
 	if err != nil {
-		return nil, fmt.Errorf("error generating namespace URL for Kubernetes API from [%s]", urlString)
+		return nil, fmt.Errorf("error generating URL for Kubernetes API from [%s%s]", schemeHostAndPort, path)
 	}
+	url.RawQuery = query.Encode()
 
 	return url, nil
 }
 
 func generateBaseKubernetesApiUrl(schemeHostAndPort string) (*url.URL, error) {
-	urlString := fmt.Sprintf("%s/api/v1/", schemeHostAndPort)
-	url, err := url.Parse(urlString)
+	url, err := joinURL(schemeHostAndPort, "/api/v1/")
 	if err != nil {
-		return nil, fmt.Errorf("error generating base URL for Kubernetes API from [%s]", urlString)
+		return nil, fmt.Errorf("error generating base URL for Kubernetes API from [%s/api/v1/]", schemeHostAndPort)
 	}
 	return url, nil
 }
 
-func getConfig(fpath string) (*rest.Config, error) {
+// joinURL returns the URL of the percent-encoded escapedPath on
+// schemeHostAndPort. Any path prefix of schemeHostAndPort is kept, as with
+// Rancher's https://rancher.example.com/k8s/clusters/c-abc123, and exactly one
+// slash separates it from escapedPath. Unlike url.URL.ResolveReference, dot
+// segments are not removed, since they may be meaningful to a proxied service.
+func joinURL(schemeHostAndPort, escapedPath string) (*url.URL, error) {
+	base, err := url.Parse(schemeHostAndPort)
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("expected a URL with a scheme and host, was [%s]", schemeHostAndPort)
+	}
+
+	rawPath := strings.TrimRight(base.EscapedPath(), "/") + "/" + strings.TrimLeft(escapedPath, "/")
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &url.URL{
+		Scheme:  base.Scheme,
+		User:    base.User,
+		Host:    base.Host,
+		Path:    path,
+		RawPath: rawPath,
+	}, nil
+}
+
+var (
+	// serviceAccountTokenPath and serviceAccountCAPath are the locations where
+	// Kubernetes mounts the service account credentials into a pod.
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAPath    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// getConfig builds a rest.Config from the kubeconfig at fpath. If fpath is
+// empty, every file listed in the KUBECONFIG environment variable is merged
+// the same way kubectl merges them (the first file to define a key wins),
+// falling back to ~/.kube/config when KUBECONFIG is unset. If kubeContext is
+// not empty, the named context is used instead of the kubeconfig's
+// current-context. If fpath is empty and no kubeconfig file exists, the
+// in-cluster service account configuration is used instead.
+func getConfig(fpath, kubeContext string) (*rest.Config, error) {
 	rules := clientcmd.NewDefaultClientConfigLoadingRules()
 	if fpath != "" {
 		rules.ExplicitPath = fpath
+	} else if kubeconfigEnv := os.Getenv(clientcmd.RecommendedConfigPathEnvVar); kubeconfigEnv != "" {
+		rules.Precedence = filepath.SplitList(kubeconfigEnv)
+	}
+
+	if fpath == "" && !anyFileExists(rules.GetLoadingPrecedence()) {
+		config, err := inClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("no kubeconfig found at [%s], and in-cluster configuration failed: %v",
+				strings.Join(rules.GetLoadingPrecedence(), ", "), err)
+		}
+		return config, nil
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	if kubeContext != "" {
+		rawConfig, err := clientConfig.RawConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := rawConfig.Contexts[kubeContext]; !ok {
+			contexts := make([]string, 0, len(rawConfig.Contexts))
+			for name := range rawConfig.Contexts {
+				contexts = append(contexts, name)
+			}
+			sort.Strings(contexts)
+
+			return nil, fmt.Errorf("context [%s] not found in kubeconfig, available contexts: [%s]",
+				kubeContext, strings.Join(contexts, ", "))
+		}
+	}
+
+	return clientConfig.ClientConfig()
+}
+
+// inClusterConfig mirrors rest.InClusterConfig, reading the API server address
+// from the environment and the credentials from the service account mount.
+func inClusterConfig() (*rest.Config, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be defined")
+	}
+
+	token, err := ioutil.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := certutil.NewPool(serviceAccountCAPath); err != nil {
+		return nil, fmt.Errorf("failed to load CA from [%s]: %v", serviceAccountCAPath, err)
+	}
+
+	return &rest.Config{
+		Host:        "https://" + net.JoinHostPort(host, port),
+		BearerToken: string(token),
+		TLSClientConfig: rest.TLSClientConfig{
+			CAFile: serviceAccountCAPath,
+		},
+	}, nil
+}
+
+func anyFileExists(paths []string) bool {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
 	}
-	overrides := &clientcmd.ConfigOverrides{}
-	return clientcmd.
-		NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).
-		ClientConfig()
+	return false
 }
 
 // CanonicalResourceNameFromFriendlyName returns a canonical name from common shorthands used in command line tools.
@@ -1,7 +1,13 @@
 package k8s
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	certutil "k8s.io/client-go/util/cert"
 )
 
 func TestGenerateKubernetesApiBaseUrlFor(t *testing.T) {
@@ -33,6 +39,125 @@ func TestGenerateKubernetesApiBaseUrlFor(t *testing.T) {
 	})
 }
 
+func TestGenerateKubernetesApiUrlForGroupVersion(t *testing.T) {
+	const schemeHostAndPort = "https://55.197.171.239"
+
+	testCases := []struct {
+		namespace string
+		group     string
+		version   string
+		extraPath string
+		expected  string
+	}{
+		{
+			namespace: "linkerd",
+			version:   "v1",
+			extraPath: "/pods",
+			expected:  "https://55.197.171.239/api/v1/namespaces/linkerd/pods",
+		},
+		{
+			namespace: "linkerd",
+			group:     "apps",
+			version:   "v1",
+			extraPath: "/deployments/controller",
+			expected:  "https://55.197.171.239/apis/apps/v1/namespaces/linkerd/deployments/controller",
+		},
+		{
+			namespace: "emojivoto",
+			group:     "linkerd.io",
+			version:   "v1alpha1",
+			extraPath: "/serviceprofiles",
+			expected:  "https://55.197.171.239/apis/linkerd.io/v1alpha1/namespaces/emojivoto/serviceprofiles",
+		},
+		{
+			group:     "rbac.authorization.k8s.io",
+			version:   "v1",
+			extraPath: "/clusterroles/linkerd-linkerd-controller",
+			expected:  "https://55.197.171.239/apis/rbac.authorization.k8s.io/v1/clusterroles/linkerd-linkerd-controller",
+		},
+		{
+			version:   "v1",
+			extraPath: "/nodes",
+			expected:  "https://55.197.171.239/api/v1/nodes",
+		},
+		{
+			namespace: "linkerd",
+			version:   "v1",
+			expected:  "https://55.197.171.239/api/v1/namespaces/linkerd",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			url, err := generateKubernetesApiUrlForGroupVersion(schemeHostAndPort, tc.namespace, tc.group, tc.version, tc.extraPath, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if url.String() != tc.expected {
+				t.Fatalf("Expected generated URL to be [%s], but got [%s]", tc.expected, url.String())
+			}
+		})
+	}
+
+	t.Run("Return error if extra path doesn't start with slash", func(t *testing.T) {
+		_, err := generateKubernetesApiUrlForGroupVersion(schemeHostAndPort, "linkerd", "apps", "v1", "deployments", nil)
+		if err == nil {
+			t.Fatalf("Expected error when trying to generate URL with extra path without leading slash, got nothing")
+		}
+	})
+
+	t.Run("Encodes query parameters", func(t *testing.T) {
+		testCases := []struct {
+			opts     ListOptions
+			expected string
+		}{
+			{
+				opts:     ListOptions{LabelSelector: "app=web"},
+				expected: "https://55.197.171.239/api/v1/namespaces/emojivoto/pods?labelSelector=app%3Dweb",
+			},
+			{
+				opts:     ListOptions{LabelSelector: "app=web,tier!=frontend"},
+				expected: "https://55.197.171.239/api/v1/namespaces/emojivoto/pods?labelSelector=app%3Dweb%2Ctier%21%3Dfrontend",
+			},
+			{
+				opts:     ListOptions{LabelSelector: "env in (prod,staging)"},
+				expected: "https://55.197.171.239/api/v1/namespaces/emojivoto/pods?labelSelector=env+in+%28prod%2Cstaging%29",
+			},
+			{
+				opts:     ListOptions{LabelSelector: "!canary", FieldSelector: "status.phase=Running", Limit: 500, Continue: "eyJ2IjoibWV0YS5rOHMuaW8vdjEifQ=="},
+				expected: "https://55.197.171.239/api/v1/namespaces/emojivoto/pods?continue=eyJ2IjoibWV0YS5rOHMuaW8vdjEifQ%3D%3D&fieldSelector=status.phase%3DRunning&labelSelector=%21canary&limit=500",
+			},
+		}
+
+		for _, tc := range testCases {
+			url, err := generateKubernetesApiUrlForGroupVersion(schemeHostAndPort, "emojivoto", "", "v1", "/pods", tc.opts.Values())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if url.String() != tc.expected {
+				t.Fatalf("Expected generated URL to be [%s], but got [%s]", tc.expected, url.String())
+			}
+			if got := url.Query().Get("labelSelector"); got != tc.opts.LabelSelector {
+				t.Fatalf("Expected label selector to round-trip as [%s], got [%s]", tc.opts.LabelSelector, got)
+			}
+		}
+	})
+
+	t.Run("Return error if extra path contains a query string", func(t *testing.T) {
+		_, err := generateKubernetesApiUrlForGroupVersion(schemeHostAndPort, "emojivoto", "", "v1", "/pods?labelSelector=app=web", nil)
+		if err == nil {
+			t.Fatalf("Expected error when trying to generate URL with a query string in the extra path, got nothing")
+		}
+	})
+
+	t.Run("Return error if version is missing", func(t *testing.T) {
+		_, err := generateKubernetesApiUrlForGroupVersion(schemeHostAndPort, "linkerd", "apps", "", "/deployments", nil)
+		if err == nil {
+			t.Fatalf("Expected error when trying to generate URL without a version, got nothing")
+		}
+	})
+}
+
 func TestGenerateBaseKubernetesApiUrl(t *testing.T) {
 	t.Run("Generates correct URL when all elements are present", func(t *testing.T) {
 		schemeHostAndPort := "gopher://some-server.example.com:661"
@@ -60,7 +185,7 @@ func TestGenerateBaseKubernetesApiUrl(t *testing.T) {
 
 func TestGetConfig(t *testing.T) {
 	t.Run("Gets host correctly form existing file", func(t *testing.T) {
-		config, err := getConfig("testdata/config.test")
+		config, err := getConfig("testdata/config.test", "")
 		if err != nil {
 			t.Fatalf("Unexpected error: %v", err)
 		}
@@ -72,13 +197,148 @@ func TestGetConfig(t *testing.T) {
 	})
 
 	t.Run("Returns error if configuration cannot be found", func(t *testing.T) {
-		_, err := getConfig("/this/doest./not/exist.config")
+		_, err := getConfig("/this/doest./not/exist.config", "")
 		if err == nil {
 			t.Fatalf("Expecting error when config file doesnt exist, got nothing")
 		}
 	})
 }
 
+func TestGetConfigWithMultipleKubeconfigs(t *testing.T) {
+	kubeconfig := strings.Join([]string{
+		"testdata/config.merge-1.test",
+		"testdata/config.merge-2.test",
+	}, string(filepath.ListSeparator))
+
+	withEnv(t, map[string]string{"KUBECONFIG": kubeconfig}, func() {
+		t.Run("Resolves a context whose cluster is defined in another file", func(t *testing.T) {
+			config, err := getConfig("", "")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expectedHost := "https://10.2.2.2"
+			if config.Host != expectedHost {
+				t.Fatalf("Expected host to be [%s] got [%s]", expectedHost, config.Host)
+			}
+		})
+
+		t.Run("Resolves conflicting keys in favor of the first file", func(t *testing.T) {
+			config, err := getConfig("", "shared")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expectedHost := "https://10.1.1.1"
+			if config.Host != expectedHost {
+				t.Fatalf("Expected host to be [%s] got [%s]", expectedHost, config.Host)
+			}
+			if config.BearerToken != "first-file-token" {
+				t.Fatalf("Expected token from the first file, got [%s]", config.BearerToken)
+			}
+		})
+	})
+}
+
+func TestGetConfigInCluster(t *testing.T) {
+	dir, err := ioutil.TempDir("", "serviceaccount")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	caPEM, _, err := certutil.GenerateSelfSignedCertKey("kubernetes", nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tokenPath := filepath.Join(dir, "token")
+	caPath := filepath.Join(dir, "ca.crt")
+	if err := ioutil.WriteFile(tokenPath, []byte("service-account-token"), 0600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := ioutil.WriteFile(caPath, caPEM, 0600); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	origTokenPath, origCAPath := serviceAccountTokenPath, serviceAccountCAPath
+	serviceAccountTokenPath, serviceAccountCAPath = tokenPath, caPath
+	defer func() {
+		serviceAccountTokenPath, serviceAccountCAPath = origTokenPath, origCAPath
+	}()
+
+	withEnv(t, map[string]string{
+		"KUBECONFIG":              filepath.Join(dir, "does-not-exist"),
+		"KUBERNETES_SERVICE_HOST": "10.96.0.1",
+		"KUBERNETES_SERVICE_PORT": "443",
+	}, func() {
+		t.Run("Falls back to the in-cluster configuration", func(t *testing.T) {
+			config, err := getConfig("", "")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if config.Host != "https://10.96.0.1:443" {
+				t.Fatalf("Expected host to be [https://10.96.0.1:443] got [%s]", config.Host)
+			}
+			if config.BearerToken != "service-account-token" {
+				t.Fatalf("Expected service account token, got [%s]", config.BearerToken)
+			}
+			if config.TLSClientConfig.CAFile != caPath {
+				t.Fatalf("Expected CA file to be [%s] got [%s]", caPath, config.TLSClientConfig.CAFile)
+			}
+		})
+
+		t.Run("Prefers an explicit kubeconfig path", func(t *testing.T) {
+			config, err := getConfig("testdata/config.test", "")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if config.Host != "https://55.197.171.239" {
+				t.Fatalf("Expected host to be [https://55.197.171.239] got [%s]", config.Host)
+			}
+		})
+	})
+
+	withEnv(t, map[string]string{
+		"KUBECONFIG":              filepath.Join(dir, "does-not-exist"),
+		"KUBERNETES_SERVICE_HOST": "",
+		"KUBERNETES_SERVICE_PORT": "",
+	}, func() {
+		t.Run("Mentions both sources when neither is available", func(t *testing.T) {
+			_, err := getConfig("", "")
+			if err == nil {
+				t.Fatalf("Expected error when no configuration is available, got nothing")
+			}
+
+			msg := err.Error()
+			if !strings.Contains(msg, "no kubeconfig found") || !strings.Contains(msg, "in-cluster configuration failed") {
+				t.Fatalf("Expected error to mention kubeconfig and in-cluster configuration, got [%s]", msg)
+			}
+		})
+	})
+}
+
+// withEnv runs fn with the given environment variables set, restoring their
+// previous values afterwards.
+func withEnv(t *testing.T, env map[string]string, fn func()) {
+	orig := make(map[string]string)
+	for key, value := range env {
+		orig[key] = os.Getenv(key)
+		if err := os.Setenv(key, value); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	defer func() {
+		for key, value := range orig {
+			os.Setenv(key, value)
+		}
+	}()
+
+	fn()
+}
+
 func TestCanonicalResourceNameFromFriendlyName(t *testing.T) {
 	t.Run("Returns canonical name for all known variants", func(t *testing.T) {
 		expectations := map[string]string{
@@ -115,3 +375,39 @@ func TestCanonicalResourceNameFromFriendlyName(t *testing.T) {
 		}
 	})
 }
+
+func TestJoinURL(t *testing.T) {
+	testCases := []struct {
+		schemeHostAndPort string
+		escapedPath       string
+		expected          string
+	}{
+		{"https://55.197.171.239", "/api/v1/", "https://55.197.171.239/api/v1/"},
+		{"https://55.197.171.239/", "/api/v1/", "https://55.197.171.239/api/v1/"},
+		{"https://55.197.171.239:6443//", "//version", "https://55.197.171.239:6443/version"},
+		{"https://rancher.example.com/k8s/clusters/c-abc123", "/version", "https://rancher.example.com/k8s/clusters/c-abc123/version"},
+		{"https://rancher.example.com/k8s/clusters/c-abc123/", "/version", "https://rancher.example.com/k8s/clusters/c-abc123/version"},
+		{"https://example.com/a%20b", "/proxy/x%3Fy/../z", "https://example.com/a%20b/proxy/x%3Fy/../z"},
+		{"https://[fd00::1]:6443", "/api/v1/", "https://[fd00::1]:6443/api/v1/"},
+		{"https://[fd00::1]", "/api/v1/", "https://[fd00::1]/api/v1/"},
+		{"https://[fe80::1%25eth0]:6443/", "/api/v1/", "https://[fe80::1%25eth0]:6443/api/v1/"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			url, err := joinURL(tc.schemeHostAndPort, tc.escapedPath)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if url.String() != tc.expected {
+				t.Fatalf("Expected joined URL to be [%s], but got [%s]", tc.expected, url.String())
+			}
+		})
+	}
+
+	t.Run("Returns an error for hosts without a scheme", func(t *testing.T) {
+		if _, err := joinURL("55.197.171.239", "/version"); err == nil {
+			t.Fatalf("Expected error, got nothing")
+		}
+	})
+}
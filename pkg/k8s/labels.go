@@ -22,8 +22,9 @@ const (
 	// control plane (e.g. web, controller).
 	ControllerComponentLabel = "linkerd.io/control-plane-component"
 
-	// ControllerNSLabel is injected into mesh-enabled apps, identifying the
-	// namespace of the Linkerd control plane.
+	// ControllerNSLabel is injected into mesh-enabled apps, and set on the
+	// resources created by `linkerd install`, identifying the namespace of the
+	// Linkerd control plane.
 	ControllerNSLabel = "linkerd.io/control-plane-ns"
 
 	// ProxyDeploymentLabel is injected into mesh-enabled apps, identifying the
@@ -42,6 +43,10 @@ const (
 	// this proxy belongs to.
 	ProxyJobLabel = "linkerd.io/proxy-job"
 
+	// ProxyCronJobLabel is injected into mesh-enabled apps, identifying the
+	// CronJob that this proxy belongs to.
+	ProxyCronJobLabel = "linkerd.io/proxy-cronjob"
+
 	// ProxyDaemonSetLabel is injected into mesh-enabled apps, identifying the
 	// DaemonSet that this proxy belongs to.
 	ProxyDaemonSetLabel = "linkerd.io/proxy-daemonset"
@@ -62,10 +67,34 @@ const (
 	// (e.g. v0.1.3).
 	ProxyVersionAnnotation = "linkerd.io/proxy-version"
 
+	// ProxySkipInboundPortsAnnotation lists the ports and ranges of ports whose
+	// inbound traffic skips the proxy (e.g. 25,4222-4223).
+	ProxySkipInboundPortsAnnotation = "linkerd.io/skip-inbound-ports"
+
+	// ProxySkipOutboundPortsAnnotation lists the ports and ranges of ports
+	// whose outbound traffic skips the proxy (e.g. 25,4222-4223).
+	ProxySkipOutboundPortsAnnotation = "linkerd.io/skip-outbound-ports"
+
+	// ProxyInjectAnnotation, set to ProxyInjectEnabled or ProxyInjectDisabled
+	// on a pod or its namespace, decides whether the proxy injector injects
+	// the pod. The annotation of the pod takes precedence over that of its
+	// namespace, which takes precedence over the default of the cluster.
+	ProxyInjectAnnotation = "linkerd.io/inject"
+	ProxyInjectEnabled    = "enabled"
+	ProxyInjectDisabled   = "disabled"
+
 	/*
 	 * Component Names
 	 */
 
+	// ProxyContainerName is the name of the proxy container injected into
+	// mesh-enabled apps.
+	ProxyContainerName = "linkerd-proxy"
+
+	// InitContainerName is the name of the init container injected into
+	// mesh-enabled apps, which redirects their traffic to the proxy.
+	InitContainerName = "linkerd-init"
+
 	// TLSTrustAnchorConfigMapName is the name of the ConfigMap that holds the
 	// trust anchors (trusted root certificates).
 	TLSTrustAnchorConfigMapName = "linkerd-ca-bundle"
@@ -74,6 +103,26 @@ const (
 	// that contains the actual trust anchor bundle.
 	TLSTrustAnchorFileName = "trust-anchors.pem"
 
+	// InstallConfigMapName is the name of the ConfigMap that records the
+	// options the control plane was installed with.
+	InstallConfigMapName = "linkerd-config"
+
+	// InstallOptionsKey is the key within the install ConfigMap that contains
+	// the JSON-encoded InstallOptions.
+	InstallOptionsKey = "install"
+
+	// ProxyConfigKey is the key within the install ConfigMap that contains the
+	// JSON-encoded configuration of the proxy injected by the proxy injector.
+	ProxyConfigKey = "proxy"
+
+	// ProxyInjectorServiceName is the name of the Service of the proxy
+	// injector webhook, and ProxyInjectorTLSSecretName the name of the Secret
+	// holding the certificate it serves, along with the CA bundle the API
+	// server verifies it with under ProxyInjectorCABundleKey.
+	ProxyInjectorServiceName   = "linkerd-proxy-injector"
+	ProxyInjectorTLSSecretName = "linkerd-proxy-injector-tls"
+	ProxyInjectorCABundleKey   = "ca.crt"
+
 	TLSCertFileName       = "certificate.crt"
 	TLSPrivateKeyFileName = "private-key.p8"
 )
@@ -84,6 +133,13 @@ func CreatedByAnnotationValue() string {
 	return fmt.Sprintf("linkerd/cli %s", version.Version)
 }
 
+// ProxyInjectorWebhookConfigName returns the name of the
+// MutatingWebhookConfiguration of the proxy injector of the control plane in
+// controlPlaneNamespace.
+func ProxyInjectorWebhookConfigName(controlPlaneNamespace string) string {
+	return fmt.Sprintf("linkerd-%s-proxy-injector", controlPlaneNamespace)
+}
+
 // GetPodLabels returns the set of prometheus owner labels for a given pod
 func GetPodLabels(ownerKind, ownerName string, pod *coreV1.Pod) map[string]string {
 	labels := map[string]string{"pod": pod.Name}
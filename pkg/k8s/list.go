@@ -0,0 +1,43 @@
+package k8s
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// listPageSize is the number of objects requested per page by the list
+// helpers. Larger collections are fetched in several requests, following the
+// continue token returned by the API server.
+const listPageSize = 500
+
+// ListOptions are the query parameters of a list request. Empty fields are
+// omitted.
+type ListOptions struct {
+	// LabelSelector and FieldSelector filter the listed objects, e.g.
+	// "app=web,env in (prod,staging)" and "status.phase=Running".
+	LabelSelector string
+	FieldSelector string
+
+	// Limit is the maximum number of objects returned in one response, and
+	// Continue is the token returned with the previous page, if any.
+	Limit    int64
+	Continue string
+}
+
+// Values returns opts as encodable query parameters.
+func (opts ListOptions) Values() url.Values {
+	query := url.Values{}
+	if opts.LabelSelector != "" {
+		query.Set("labelSelector", opts.LabelSelector)
+	}
+	if opts.FieldSelector != "" {
+		query.Set("fieldSelector", opts.FieldSelector)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.FormatInt(opts.Limit, 10))
+	}
+	if opts.Continue != "" {
+		query.Set("continue", opts.Continue)
+	}
+	return query
+}
@@ -0,0 +1,134 @@
+package k8s
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// maxLoggedBodySize bounds how much of each request and response body is
+// logged by loggingTransport.
+const maxLoggedBodySize = 10 * 1024
+
+// loggingTransport logs the method, URL, status and duration of each request
+// at debug level. If logBodies is set, headers and bodies are logged as well,
+// with credentials redacted.
+type loggingTransport struct {
+	rt        http.RoundTripper
+	logBodies bool
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.logBodies {
+		req = t.logRequest(req)
+	}
+
+	start := time.Now()
+	rsp, err := t.rt.RoundTrip(req)
+	duration := time.Since(start)
+
+	if err != nil {
+		log.Debugf("%s %s failed after %s: %v", req.Method, req.URL, duration, err)
+		return rsp, err
+	}
+	log.Debugf("%s %s %s in %s", req.Method, req.URL, rsp.Status, duration)
+
+	if t.logBodies {
+		log.Debugf("Response headers: %v", redactHeaders(rsp.Header))
+		rsp.Body = &loggingBody{ReadCloser: rsp.Body, prefix: "Response body: "}
+	}
+
+	return rsp, nil
+}
+
+// logRequest logs the headers and body of req. Since the body can only be read
+// once, it returns a copy of req with the body restored.
+func (t *loggingTransport) logRequest(req *http.Request) *http.Request {
+	log.Debugf("Request headers: %v", redactHeaders(req.Header))
+
+	if req.Body == nil {
+		return req
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		log.Debugf("error reading request body: %v", err)
+	}
+	log.Debugf("Request body: %s", truncateBody(body))
+
+	req = utilnet.CloneRequest(req)
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return req
+}
+
+// loggingBody logs the beginning of a response body once it has been read to
+// the end or closed, without interfering with how the caller consumes it.
+type loggingBody struct {
+	io.ReadCloser
+	prefix string
+
+	buf  bytes.Buffer
+	once sync.Once
+}
+
+func (b *loggingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if remaining := maxLoggedBodySize + 1 - b.buf.Len(); remaining > 0 {
+		if remaining > n {
+			remaining = n
+		}
+		b.buf.Write(p[:remaining])
+	}
+	if err == io.EOF {
+		b.log()
+	}
+	return n, err
+}
+
+func (b *loggingBody) Close() error {
+	b.log()
+	return b.ReadCloser.Close()
+}
+
+func (b *loggingBody) log() {
+	b.once.Do(func() {
+		log.Debugf("%s%s", b.prefix, truncateBody(b.buf.Bytes()))
+	})
+}
+
+func truncateBody(body []byte) string {
+	if len(body) > maxLoggedBodySize {
+		return string(body[:maxLoggedBodySize]) + "... (truncated)"
+	}
+	return string(body)
+}
+
+// redactHeaders returns a copy of headers in which credentials are replaced,
+// keeping the authorization scheme for context.
+func redactHeaders(headers http.Header) http.Header {
+	redacted := make(http.Header, len(headers))
+	for name, values := range headers {
+		if http.CanonicalHeaderKey(name) != "Authorization" {
+			redacted[name] = values
+			continue
+		}
+
+		for _, value := range values {
+			scheme := strings.SplitN(value, " ", 2)[0]
+			if scheme == value {
+				redacted.Add(name, "[REDACTED]")
+			} else {
+				redacted.Add(name, scheme+" [REDACTED]")
+			}
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/rest"
+)
+
+func TestLoggingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+	}))
+	defer server.Close()
+
+	captureLogs := func(t *testing.T, logBodies bool) string {
+		var output bytes.Buffer
+		out, level := log.StandardLogger().Out, log.GetLevel()
+		log.SetOutput(&output)
+		log.SetLevel(log.DebugLevel)
+		defer func() {
+			log.SetOutput(out)
+			log.SetLevel(level)
+		}()
+
+		api := &KubernetesAPI{
+			Config:    &rest.Config{Host: server.URL, BearerToken: "s3cr3t"},
+			LogBodies: logBodies,
+		}
+
+		versionInfo, err := api.GetVersionInfo(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if versionInfo.GitVersion != "v1.10.0" {
+			t.Fatalf("Expected version [v1.10.0], got [%s]", versionInfo.GitVersion)
+		}
+
+		return output.String()
+	}
+
+	t.Run("Logs the method, URL and status of each request", func(t *testing.T) {
+		output := captureLogs(t, false)
+
+		expected := "GET " + server.URL + "/version 200 OK in "
+		if !strings.Contains(output, expected) {
+			t.Fatalf("Expected log output to contain [%s], got [%s]", expected, output)
+		}
+		if strings.Contains(output, "Response body") {
+			t.Fatalf("Expected bodies not to be logged, got [%s]", output)
+		}
+	})
+
+	t.Run("Logs bodies and redacts the Authorization header", func(t *testing.T) {
+		output := captureLogs(t, true)
+
+		if strings.Contains(output, "s3cr3t") {
+			t.Fatalf("Expected the bearer token to be redacted, got [%s]", output)
+		}
+		if !strings.Contains(output, "Bearer [REDACTED]") {
+			t.Fatalf("Expected log output to contain the redacted Authorization header, got [%s]", output)
+		}
+		if !strings.Contains(output, `v1.10.0`) {
+			t.Fatalf("Expected log output to contain the response body, got [%s]", output)
+		}
+	})
+
+	t.Run("Does not log when debug logging is disabled", func(t *testing.T) {
+		var output bytes.Buffer
+		out, level := log.StandardLogger().Out, log.GetLevel()
+		log.SetOutput(&output)
+		log.SetLevel(log.InfoLevel)
+		defer func() {
+			log.SetOutput(out)
+			log.SetLevel(level)
+		}()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+		if _, err := api.GetVersionInfo(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if output.Len() != 0 {
+			t.Fatalf("Expected no log output, got [%s]", output.String())
+		}
+	})
+}
+
+func TestRedactHeaders(t *testing.T) {
+	headers := http.Header{
+		"Authorization": []string{"Bearer s3cr3t", "opaque"},
+		"User-Agent":    []string{"linkerd/cli"},
+	}
+
+	redacted := redactHeaders(headers)
+
+	expected := []string{"Bearer [REDACTED]", "[REDACTED]"}
+	if got := redacted["Authorization"]; len(got) != 2 || got[0] != expected[0] || got[1] != expected[1] {
+		t.Fatalf("Expected Authorization headers %v, got %v", expected, got)
+	}
+	if ua := redacted.Get("User-Agent"); ua != "linkerd/cli" {
+		t.Fatalf("Expected User-Agent header [linkerd/cli], got [%s]", ua)
+	}
+	if headers.Get("Authorization") != "Bearer s3cr3t" {
+		t.Fatalf("Expected the original headers to be unchanged, got %v", headers)
+	}
+}
@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// LogOptions configures the logs returned by StreamPodLogs.
+type LogOptions struct {
+	// Follow keeps the stream open, returning new log lines as they are
+	// written, until the context is cancelled.
+	Follow bool
+
+	// TailLines limits the logs to the most recent lines. A zero value means
+	// all lines.
+	TailLines int64
+
+	// SinceSeconds limits the logs to those written in the last SinceSeconds
+	// seconds. A zero value means no limit.
+	SinceSeconds int64
+
+	// Timestamps prefixes each line with the time it was written.
+	Timestamps bool
+}
+
+func (opts LogOptions) query(container string) url.Values {
+	query := url.Values{}
+	if container != "" {
+		query.Set("container", container)
+	}
+	if opts.Follow {
+		query.Set("follow", "true")
+	}
+	if opts.TailLines > 0 {
+		query.Set("tailLines", strconv.FormatInt(opts.TailLines, 10))
+	}
+	if opts.SinceSeconds > 0 {
+		query.Set("sinceSeconds", strconv.FormatInt(opts.SinceSeconds, 10))
+	}
+	if opts.Timestamps {
+		query.Set("timestamps", "true")
+	}
+	return query
+}
+
+// StreamPodLogs returns the logs of a container of the given pod. An empty
+// container name is only accepted by the API server for pods with a single
+// container. The caller must close the returned stream.
+//
+// Since logs are streamed, the configured Timeout is not applied. Reads from
+// the stream end with io.EOF when ctx is cancelled.
+func (kubeAPI *KubernetesAPI) StreamPodLogs(ctx context.Context, namespace, pod, container string, opts LogOptions) (io.ReadCloser, error) {
+	client, err := kubeAPI.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/api/v1/namespaces/" + namespace + "/pods/" + pod + "/log"
+	rsp, err := kubeAPI.doRequest(ctx, client, "GET", path, opts.query(container), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		defer rsp.Body.Close()
+		return nil, kubeAPI.responseError(rsp)
+	}
+
+	return &contextReader{ctx: ctx, ReadCloser: rsp.Body}, nil
+}
+
+// contextReader reports the end of the stream, rather than an error, once its
+// context has been cancelled.
+type contextReader struct {
+	ctx context.Context
+	io.ReadCloser
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && r.ctx.Err() != nil {
+		return n, io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,139 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestStreamPodLogs(t *testing.T) {
+	t.Run("Builds the log query", func(t *testing.T) {
+		testCases := []struct {
+			container string
+			opts      LogOptions
+			query     string
+		}{
+			{query: ""},
+			{container: "linkerd-proxy", query: "container=linkerd-proxy"},
+			{
+				container: "linkerd-proxy",
+				opts:      LogOptions{Follow: true, TailLines: 100, SinceSeconds: 300, Timestamps: true},
+				query:     "container=linkerd-proxy&follow=true&sinceSeconds=300&tailLines=100&timestamps=true",
+			},
+		}
+
+		for i, tc := range testCases {
+			t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path != "/api/v1/namespaces/emojivoto/pods/web-1/log" {
+						t.Fatalf("Unexpected path [%s]", r.URL.Path)
+					}
+					if r.URL.RawQuery != tc.query {
+						t.Fatalf("Expected query [%s], got [%s]", tc.query, r.URL.RawQuery)
+					}
+					fmt.Fprint(w, "log line\n")
+				}))
+				defer server.Close()
+
+				api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+				logs, err := api.StreamPodLogs(context.Background(), "emojivoto", "web-1", tc.container, tc.opts)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				defer logs.Close()
+
+				body, err := ioutil.ReadAll(logs)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if string(body) != "log line\n" {
+					t.Fatalf("Unexpected logs [%s]", body)
+				}
+			})
+		}
+	})
+
+	t.Run("Streams logs until the context is cancelled", func(t *testing.T) {
+		next := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			flusher := w.(http.Flusher)
+			for i := 1; ; i++ {
+				fmt.Fprintf(w, "line %d\n", i)
+				flusher.Flush()
+
+				select {
+				case <-next:
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		logs, err := api.StreamPodLogs(ctx, "emojivoto", "web-1", "", LogOptions{Follow: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer logs.Close()
+
+		// Each line is only written once the previous one has been read, so
+		// this would block if the body were buffered.
+		reader := bufio.NewReader(logs)
+		for i := 1; i <= 3; i++ {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if expected := fmt.Sprintf("line %d\n", i); line != expected {
+				t.Fatalf("Expected [%s], got [%s]", expected, line)
+			}
+			if i < 3 {
+				next <- struct{}{}
+			}
+		}
+
+		cancel()
+
+		done := make(chan error)
+		go func() {
+			_, err := ioutil.ReadAll(reader)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != nil && err != io.EOF {
+				t.Fatalf("Expected the stream to end cleanly, got [%v]", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for the stream to end")
+		}
+	})
+
+	t.Run("Returns API errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"a container name must be specified for pod web-1, choose one of: [web linkerd-proxy]","reason":"BadRequest","code":400}`))
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		_, err := api.StreamPodLogs(context.Background(), "emojivoto", "web-1", "", LogOptions{})
+		expected := "Unexpected Kubernetes API response: 400 Bad Request: a container name must be specified for pod web-1, choose one of: [web linkerd-proxy]"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package k8s
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linkerd/linkerd2/pkg/prometheus"
+	promclient "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	requestDuration = promclient.NewHistogramVec(
+		promclient.HistogramOpts{
+			Name:    "kubernetes_api_request_duration_seconds",
+			Help:    "A histogram of latencies for requests to the Kubernetes API in seconds.",
+			Buckets: prometheus.RequestDurationBucketsSeconds,
+		},
+		[]string{"verb", "path", "code"},
+	)
+
+	responsesTotal = promclient.NewCounterVec(
+		promclient.CounterOpts{
+			Name: "kubernetes_api_responses_total",
+			Help: "A counter for responses from the Kubernetes API.",
+		},
+		[]string{"verb", "path", "code"},
+	)
+
+	registerMetrics sync.Once
+)
+
+// InstrumentTransport wraps rt so that the duration and status of each request
+// to the Kubernetes API is recorded in Prometheus metrics. The metrics are
+// registered against the default Prometheus registry the first time this is
+// called, so processes that never instrument a transport, such as the CLI,
+// register no collectors.
+func InstrumentTransport(rt http.RoundTripper) http.RoundTripper {
+	registerMetrics.Do(func() {
+		promclient.MustRegister(requestDuration, responsesTotal)
+	})
+	return &instrumentedTransport{rt: rt}
+}
+
+type instrumentedTransport struct {
+	rt http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	rsp, err := t.rt.RoundTrip(req)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(rsp.StatusCode)
+	}
+	path := pathPattern(req.URL.Path)
+
+	requestDuration.WithLabelValues(req.Method, path, code).Observe(time.Since(start).Seconds())
+	responsesTotal.WithLabelValues(req.Method, path, code).Inc()
+
+	return rsp, err
+}
+
+// pathPattern replaces the namespace and object name in a Kubernetes API path
+// with placeholders, e.g. /api/v1/namespaces/linkerd/pods/web-1234 becomes
+// /api/v1/namespaces/{namespace}/pods/{name}, so that metrics are not labeled
+// by unbounded values. Anything following a subresource, such as the target
+// of a proxy request, is dropped.
+func pathPattern(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var prefixLen int
+	switch segments[0] {
+	case "api":
+		prefixLen = 2 // /api/{version}
+	case "apis":
+		prefixLen = 3 // /apis/{group}/{version}
+	default:
+		if len(segments) > 1 {
+			return "/" + segments[0] + "/{path}"
+		}
+		return path
+	}
+
+	if len(segments) <= prefixLen {
+		return path
+	}
+
+	pattern := segments[:prefixLen]
+	rest := segments[prefixLen:]
+
+	if rest[0] == "watch" {
+		pattern = append(pattern, "watch")
+		rest = rest[1:]
+	}
+
+	if len(rest) >= 2 && rest[0] == "namespaces" {
+		pattern = append(pattern, "namespaces", "{namespace}")
+		rest = rest[2:]
+	}
+
+	if len(rest) >= 1 {
+		pattern = append(pattern, rest[0])
+	}
+	if len(rest) >= 2 {
+		pattern = append(pattern, "{name}")
+	}
+	if len(rest) >= 3 {
+		pattern = append(pattern, rest[2])
+	}
+
+	return "/" + strings.Join(pattern, "/")
+}
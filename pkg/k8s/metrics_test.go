@@ -0,0 +1,122 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/rest"
+)
+
+func TestInstrumentTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/version":
+			w.Write([]byte(`{"major":"1","minor":"10","gitVersion":"v1.10.0"}`))
+		case "/api/v1/namespaces/linkerd":
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{
+		Host:          server.URL,
+		WrapTransport: InstrumentTransport,
+	}}
+
+	// the metrics are global, so only their changes are compared, for the
+	// test to pass when run again in the same process
+	before := gatherAPIMetrics(t)
+
+	if _, err := api.GetVersionInfo(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, ns := range []string{"linkerd", "emojivoto"} {
+		if _, err := api.NamespaceExists(context.Background(), ns); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	after := gatherAPIMetrics(t)
+
+	expected := map[string]float64{
+		"GET /version 200":                       1,
+		"GET /api/v1/namespaces/{namespace} 200": 1,
+		"GET /api/v1/namespaces/{namespace} 404": 1,
+	}
+	for _, name := range []string{"kubernetes_api_responses_total", "kubernetes_api_request_duration_seconds"} {
+		changes := map[string]float64{}
+		for key, value := range after[name] {
+			if change := value - before[name][key]; change != 0 {
+				changes[key] = change
+			}
+		}
+		if !reflect.DeepEqual(changes, expected) {
+			t.Fatalf("Expected %s series to change by %v, got %v", name, expected, changes)
+		}
+	}
+}
+
+// gatherAPIMetrics returns the values of the counters, and the sample counts
+// of the histograms, of the Kubernetes API metrics, by metric name and by
+// verb, path and code.
+func gatherAPIMetrics(t *testing.T) map[string]map[string]float64 {
+	families, err := promclient.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Unexpected error gathering metrics: %v", err)
+	}
+
+	counts := map[string]map[string]float64{}
+	for _, family := range families {
+		switch family.GetName() {
+		case "kubernetes_api_responses_total", "kubernetes_api_request_duration_seconds":
+		default:
+			continue
+		}
+
+		counts[family.GetName()] = map[string]float64{}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			key := labels["verb"] + " " + labels["path"] + " " + labels["code"]
+
+			if metric.GetCounter() != nil {
+				counts[family.GetName()][key] = metric.GetCounter().GetValue()
+			} else {
+				counts[family.GetName()][key] = float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return counts
+}
+
+func TestPathPattern(t *testing.T) {
+	testCases := map[string]string{
+		"/version":                        "/version",
+		"/api":                            "/api",
+		"/api/v1/namespaces":              "/api/v1/namespaces",
+		"/api/v1/namespaces/linkerd":      "/api/v1/namespaces/{namespace}",
+		"/api/v1/pods":                    "/api/v1/pods",
+		"/api/v1/namespaces/linkerd/pods": "/api/v1/namespaces/{namespace}/pods",
+		"/api/v1/namespaces/linkerd/pods/web-1234":                                   "/api/v1/namespaces/{namespace}/pods/{name}",
+		"/api/v1/namespaces/linkerd/pods/web-1234/log":                               "/api/v1/namespaces/{namespace}/pods/{name}/log",
+		"/api/v1/watch/namespaces/linkerd/pods":                                      "/api/v1/watch/namespaces/{namespace}/pods",
+		"/api/v1/namespaces/linkerd/services/http:api:http/proxy/api/v1/StatSummary": "/api/v1/namespaces/{namespace}/services/{name}/proxy",
+		"/apis/apps/v1/namespaces/linkerd/deployments/controller":                    "/apis/apps/v1/namespaces/{namespace}/deployments/{name}",
+		"/apis/apps/v1/deployments":                                                  "/apis/apps/v1/deployments",
+		"/openapi/v2/some/thing":                                                     "/openapi/{path}",
+	}
+
+	for path, expected := range testCases {
+		if actual := pathPattern(path); actual != expected {
+			t.Fatalf("Expected pattern [%s] for path [%s], got [%s]", expected, path, actual)
+		}
+	}
+}
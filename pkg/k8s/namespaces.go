@@ -0,0 +1,39 @@
+package k8s
+
+import (
+	"context"
+
+	"k8s.io/api/core/v1"
+)
+
+// GetNamespace returns the namespace with the given name. If it does not
+// exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetNamespace(ctx context.Context, name string) (*v1.Namespace, error) {
+	var namespace v1.Namespace
+	if err := kubeAPI.getJSON(ctx, "/api/v1/namespaces/"+name, &namespace); err != nil {
+		return nil, err
+	}
+	return &namespace, nil
+}
+
+// ListNamespaces returns the namespaces matching labelSelector, e.g.
+// "linkerd.io/control-plane-ns=linkerd". An empty selector returns all
+// namespaces.
+func (kubeAPI *KubernetesAPI) ListNamespaces(ctx context.Context, labelSelector string) ([]v1.Namespace, error) {
+	var namespaces []v1.Namespace
+	continueToken := ""
+	for {
+		var list v1.NamespaceList
+		opts := ListOptions{LabelSelector: labelSelector, Limit: listPageSize, Continue: continueToken}
+		if err := kubeAPI.listJSON(ctx, "/api/v1/namespaces", opts, &list); err != nil {
+			return nil, err
+		}
+
+		namespaces = append(namespaces, list.Items...)
+
+		continueToken = list.Continue
+		if continueToken == "" {
+			return namespaces, nil
+		}
+	}
+}
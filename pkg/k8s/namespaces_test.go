@@ -0,0 +1,171 @@
+package k8s
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestGetNamespace(t *testing.T) {
+	fixture, err := ioutil.ReadFile("testdata/namespace.json")
+	if err != nil {
+		t.Fatalf("Unexpected error reading fixture: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/emojivoto":
+			w.Write(fixture)
+		case "/api/v1/namespaces/kube-system":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"namespaces \"kube-system\" is forbidden","reason":"Forbidden","code":403}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"namespaces \"missing\" not found","reason":"NotFound","code":404}`))
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Decodes the namespace", func(t *testing.T) {
+		ns, err := api.GetNamespace(context.Background(), "emojivoto")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if ns.Name != "emojivoto" {
+			t.Fatalf("Expected namespace [emojivoto], got [%s]", ns.Name)
+		}
+		if ns.Labels[ControllerNSLabel] != "linkerd" {
+			t.Fatalf("Expected label [%s=linkerd], got %v", ControllerNSLabel, ns.Labels)
+		}
+		if ns.Annotations["linkerd.io/inject"] != "enabled" {
+			t.Fatalf("Expected annotation [linkerd.io/inject=enabled], got %v", ns.Annotations)
+		}
+		if ns.Status.Phase != v1.NamespaceActive {
+			t.Fatalf("Expected phase [%s], got [%s]", v1.NamespaceActive, ns.Status.Phase)
+		}
+	})
+
+	t.Run("Returns a NotFound error for missing namespaces", func(t *testing.T) {
+		_, err := api.GetNamespace(context.Background(), "missing")
+		if !IsNotFound(err) {
+			t.Fatalf("Expected a NotFound error, got [%v]", err)
+		}
+
+		exists, err := api.NamespaceExists(context.Background(), "missing")
+		if exists || err != nil {
+			t.Fatalf("Expected NamespaceExists to return [false, nil], got [%t, %v]", exists, err)
+		}
+	})
+
+	t.Run("Returns a Forbidden error for unauthorized requests", func(t *testing.T) {
+		_, err := api.GetNamespace(context.Background(), "kube-system")
+		if !IsForbidden(err) {
+			t.Fatalf("Expected a Forbidden error, got [%v]", err)
+		}
+		if IsNotFound(err) {
+			t.Fatalf("Expected a Forbidden error not to be NotFound, got [%v]", err)
+		}
+	})
+}
+
+func TestListNamespaces(t *testing.T) {
+	t.Run("Encodes the label selector", func(t *testing.T) {
+		selector := "linkerd.io/control-plane-ns=linkerd,environment!=production"
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v1/namespaces" {
+				t.Fatalf("Unexpected path [%s]", r.URL.Path)
+			}
+			if !strings.Contains(r.URL.RawQuery, "labelSelector=linkerd.io%2Fcontrol-plane-ns%3Dlinkerd%2Cenvironment%21%3Dproduction") {
+				t.Fatalf("Expected label selector to be URL-encoded, got [%s]", r.URL.RawQuery)
+			}
+			if got := r.URL.Query().Get("labelSelector"); got != selector {
+				t.Fatalf("Expected label selector [%s], got [%s]", selector, got)
+			}
+			w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"emojivoto"}}]}`))
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		namespaces, err := api.ListNamespaces(context.Background(), selector)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(namespaces) != 1 || namespaces[0].Name != "emojivoto" {
+			t.Fatalf("Expected namespace [emojivoto], got %v", namespaces)
+		}
+	})
+
+	t.Run("Omits an empty label selector", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.URL.Query()["labelSelector"]; ok {
+				t.Fatalf("Expected no label selector, got [%s]", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"default"}},{"metadata":{"name":"linkerd"}}]}`))
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		namespaces, err := api.ListNamespaces(context.Background(), "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(namespaces) != 2 {
+			t.Fatalf("Expected 2 namespaces, got %d", len(namespaces))
+		}
+	})
+
+	t.Run("Follows continue tokens", func(t *testing.T) {
+		var requests []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.RawQuery)
+			if r.URL.Query().Get("limit") == "" {
+				t.Fatalf("Expected a page size limit, got [%s]", r.URL.RawQuery)
+			}
+
+			switch r.URL.Query().Get("continue") {
+			case "":
+				w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","metadata":{"continue":"eyJ2IjoibWV0YS5rOHMuaW8vdjEiLCJydiI6MTAzODQxMiwic3RhcnQiOiJrdWJlLXB1YmxpY1x1MDAwMCJ9"},"items":[{"metadata":{"name":"default"}},{"metadata":{"name":"kube-public"}}]}`))
+			case "eyJ2IjoibWV0YS5rOHMuaW8vdjEiLCJydiI6MTAzODQxMiwic3RhcnQiOiJrdWJlLXB1YmxpY1x1MDAwMCJ9":
+				if got := r.URL.Query().Get("labelSelector"); got != "team=emoji" {
+					t.Fatalf("Expected label selector to be preserved across pages, got [%s]", got)
+				}
+				w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"kube-system"}}]}`))
+			default:
+				t.Fatalf("Unexpected continue token in [%s]", r.URL.RawQuery)
+			}
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		namespaces, err := api.ListNamespaces(context.Background(), "team=emoji")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(requests) != 2 {
+			t.Fatalf("Expected 2 requests, got %d: %v", len(requests), requests)
+		}
+
+		expected := []string{"default", "kube-public", "kube-system"}
+		if len(namespaces) != len(expected) {
+			t.Fatalf("Expected namespaces %v, got %v", expected, namespaces)
+		}
+		for i, name := range expected {
+			if namespaces[i].Name != name {
+				t.Fatalf("Expected namespace [%s] at position %d, got [%s]", name, i, namespaces[i].Name)
+			}
+		}
+	})
+}
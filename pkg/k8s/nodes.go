@@ -0,0 +1,121 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"k8s.io/api/core/v1"
+)
+
+// SupportedNodeOperatingSystems are the node operating systems the Linkerd
+// data plane can run on. The proxy-init container configures iptables, so
+// only Linux is supported.
+var SupportedNodeOperatingSystems = []string{"linux"}
+
+// SupportedNodeArchitectures are the node architectures Linkerd images are
+// published for.
+var SupportedNodeArchitectures = []string{"amd64"}
+
+// MinNodeKernelVersion is the oldest Linux kernel version, as major and minor
+// numbers, with the iptables support required by proxy-init.
+var MinNodeKernelVersion = [2]int{3, 10}
+
+var kernelVersionFormat = regexp.MustCompile(`^(\d+)\.(\d+)`)
+
+// NodeCompatibility is the result of checking a node against the supported
+// operating systems, architectures and kernel versions.
+type NodeCompatibility struct {
+	Name string
+	Info v1.NodeSystemInfo
+	// Problems explains why the node is not compatible, and is empty if it is.
+	Problems []string
+}
+
+// Compatible returns true if no problems were found with the node.
+func (n NodeCompatibility) Compatible() bool {
+	return len(n.Problems) == 0
+}
+
+// ListNodes returns all of the cluster's nodes.
+func (kubeAPI *KubernetesAPI) ListNodes(ctx context.Context) ([]v1.Node, error) {
+	nodes := []v1.Node{}
+	continueToken := ""
+	for {
+		var list v1.NodeList
+		opts := ListOptions{Limit: listPageSize, Continue: continueToken}
+		if err := kubeAPI.listJSON(ctx, "/api/v1/nodes", opts, &list); err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, list.Items...)
+
+		continueToken = list.Continue
+		if continueToken == "" {
+			return nodes, nil
+		}
+	}
+}
+
+// CheckNodeCompatibility checks each of nodes against the supported operating
+// systems, architectures and kernel versions, returning one result per node.
+func CheckNodeCompatibility(nodes []v1.Node) []NodeCompatibility {
+	results := make([]NodeCompatibility, len(nodes))
+	for i, node := range nodes {
+		info := node.Status.NodeInfo
+		result := NodeCompatibility{Name: node.Name, Info: info}
+
+		if !contains(SupportedNodeOperatingSystems, info.OperatingSystem) {
+			result.Problems = append(result.Problems, fmt.Sprintf("unsupported operating system [%s]", info.OperatingSystem))
+		} else if problem := checkKernelVersion(info.KernelVersion); problem != "" {
+			result.Problems = append(result.Problems, problem)
+		}
+
+		if !contains(SupportedNodeArchitectures, info.Architecture) {
+			result.Problems = append(result.Problems, fmt.Sprintf("unsupported architecture [%s]", info.Architecture))
+		}
+
+		results[i] = result
+	}
+	return results
+}
+
+// IsNodeSchedulable returns true if new pods without tolerations can be
+// scheduled on node: it is neither cordoned nor tainted with a NoSchedule or
+// NoExecute effect, as masters usually are.
+func IsNodeSchedulable(node v1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == v1.TaintEffectNoSchedule || taint.Effect == v1.TaintEffectNoExecute {
+			return false
+		}
+	}
+	return true
+}
+
+func checkKernelVersion(kernelVersion string) string {
+	match := kernelVersionFormat.FindStringSubmatch(kernelVersion)
+	if match == nil {
+		return fmt.Sprintf("unrecognized kernel version [%s]", kernelVersion)
+	}
+
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	if major < MinNodeKernelVersion[0] || (major == MinNodeKernelVersion[0] && minor < MinNodeKernelVersion[1]) {
+		return fmt.Sprintf("kernel version [%s] is older than the minimum supported version [%d.%d]",
+			kernelVersion, MinNodeKernelVersion[0], MinNodeKernelVersion[1])
+	}
+	return ""
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
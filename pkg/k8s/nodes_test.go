@@ -0,0 +1,86 @@
+package k8s
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestCheckNodeCompatibility(t *testing.T) {
+	fixture, err := ioutil.ReadFile("testdata/nodes.json")
+	if err != nil {
+		t.Fatalf("Unexpected error reading fixture: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/nodes" {
+			t.Fatalf("Unexpected path [%s]", r.URL.Path)
+		}
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	nodes, err := api.ListNodes(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results := CheckNodeCompatibility(nodes)
+
+	expected := map[string][]string{
+		"gke-default-pool-1": nil,
+		"arm-pool-1":         {"unsupported architecture [arm64]"},
+		"win-pool-1":         {"unsupported operating system [windows]"},
+		"legacy-1":           {"kernel version [2.6.32-754.el6.x86_64] is older than the minimum supported version [3.10]"},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results))
+	}
+
+	for _, result := range results {
+		problems, ok := expected[result.Name]
+		if !ok {
+			t.Fatalf("Unexpected node [%s]", result.Name)
+		}
+		if !reflect.DeepEqual(result.Problems, problems) {
+			t.Fatalf("Expected problems %v for node [%s], got %v", problems, result.Name, result.Problems)
+		}
+		if result.Compatible() != (problems == nil) {
+			t.Fatalf("Expected Compatible to return [%t] for node [%s]", problems == nil, result.Name)
+		}
+	}
+
+	if runtime := results[1].Info.ContainerRuntimeVersion; runtime != "containerd://1.1.3" {
+		t.Fatalf("Expected node info to be included in results, got runtime [%s]", runtime)
+	}
+}
+
+func TestIsNodeSchedulable(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spec     v1.NodeSpec
+		expected bool
+	}{
+		{"Accepts untainted nodes", v1.NodeSpec{}, true},
+		{"Accepts nodes with PreferNoSchedule taints", v1.NodeSpec{Taints: []v1.Taint{{Key: "dedicated", Effect: v1.TaintEffectPreferNoSchedule}}}, true},
+		{"Rejects cordoned nodes", v1.NodeSpec{Unschedulable: true}, false},
+		{"Rejects masters", v1.NodeSpec{Taints: []v1.Taint{{Key: "node-role.kubernetes.io/master", Effect: v1.TaintEffectNoSchedule}}}, false},
+		{"Rejects nodes with NoExecute taints", v1.NodeSpec{Taints: []v1.Taint{{Key: "node.kubernetes.io/unreachable", Effect: v1.TaintEffectNoExecute}}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if schedulable := IsNodeSchedulable(v1.Node{Spec: tc.spec}); schedulable != tc.expected {
+				t.Fatalf("Expected IsNodeSchedulable to return %t, got %t", tc.expected, schedulable)
+			}
+		})
+	}
+}
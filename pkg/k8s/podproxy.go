@@ -0,0 +1,102 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"k8s.io/api/core/v1"
+)
+
+// podProxyPath returns the path of the API server's proxy to port of the given
+// pod, e.g. /api/v1/namespaces/emojivoto/pods/web-5f86686c4d-qh5lm:4191/proxy/metrics.
+// The returned path is percent-encoded. Unlike with services, the API server
+// does not resolve port names for pods, so port must be a number.
+func podProxyPath(namespace, pod, port, path string) (string, error) {
+	if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+		return "", fmt.Errorf("invalid port number [%s], must be between 1 and 65535", port)
+	}
+
+	return podsPath(namespace) + "/" + pod + ":" + port + "/proxy/" + escapePath(strings.TrimPrefix(path, "/")), nil
+}
+
+// PodProxyUrlFor returns the URL of path on port of the given pod, through the
+// API server's pod proxy. port must be a port number; use
+// GetPodProxyResponse to address a pod's port by name. path is
+// percent-encoded.
+func (kubeAPI *KubernetesAPI) PodProxyUrlFor(namespace, pod, port, path string) (*url.URL, error) {
+	proxyPath, err := podProxyPath(namespace, pod, port, path)
+	if err != nil {
+		return nil, err
+	}
+	return generateProxyUrl(kubeAPI.Host, proxyPath)
+}
+
+// GetPodProxyResponse GETs path on port of the given pod through the API
+// server's pod proxy, as described by PodProxyUrlFor, and returns the response
+// body. port is either a port number or the name of a port declared by one of
+// the pod's containers, e.g. "linkerd-metrics". If the pod cannot serve the
+// request because it is not ready, the returned error includes the pod's
+// phase.
+func (kubeAPI *KubernetesAPI) GetPodProxyResponse(ctx context.Context, namespace, name, port, path string) ([]byte, error) {
+	pod, err := kubeAPI.GetPod(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	number, err := resolvePodPort(pod, port)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyPath, err := podProxyPath(namespace, name, number, path)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := kubeAPI.getProxyResponse(ctx, proxyPath)
+	if err != nil {
+		if apiErr, ok := err.(*APIError); ok && apiErr.StatusCode == http.StatusServiceUnavailable {
+			return nil, fmt.Errorf("pod [%s] is not ready (phase %s): %s", name, pod.Status.Phase, err)
+		}
+		return nil, err
+	}
+	return body, nil
+}
+
+// resolvePodPort returns the number of the given port of pod. port is either
+// a port number, which is returned as is, or the name of a container port.
+// Containers share the pod's network namespace, so several of them declaring
+// the same name is only ambiguous if they disagree on the number.
+func resolvePodPort(pod *v1.Pod, port string) (string, error) {
+	if err := validatePort(port); err != nil {
+		return "", err
+	}
+	if port == "" {
+		return "", fmt.Errorf("no port specified for pod [%s]", pod.Name)
+	}
+	if _, err := strconv.Atoi(port); err == nil {
+		return port, nil
+	}
+
+	var number int32
+	for _, container := range pod.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if containerPort.Name != port {
+				continue
+			}
+			if number != 0 && number != containerPort.ContainerPort {
+				return "", fmt.Errorf("port [%s] is ambiguous in pod [%s]: declared as both %d and %d", port, pod.Name, number, containerPort.ContainerPort)
+			}
+			number = containerPort.ContainerPort
+		}
+	}
+
+	if number == 0 {
+		return "", fmt.Errorf("pod [%s] has no port named [%s]", pod.Name, port)
+	}
+	return strconv.Itoa(int(number)), nil
+}
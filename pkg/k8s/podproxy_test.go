@@ -0,0 +1,142 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestPodProxyUrlFor(t *testing.T) {
+	api := &KubernetesAPI{Config: &rest.Config{Host: "https://55.197.171.239"}}
+
+	testCases := []struct {
+		port     string
+		path     string
+		expected string
+	}{
+		{
+			port:     "4191",
+			path:     "/metrics",
+			expected: "https://55.197.171.239/api/v1/namespaces/emojivoto/pods/web-1:4191/proxy/metrics",
+		},
+		{
+			port:     "4191",
+			expected: "https://55.197.171.239/api/v1/namespaces/emojivoto/pods/web-1:4191/proxy/",
+		},
+		{
+			port:     "8080",
+			path:     "debug/vars?x=1",
+			expected: "https://55.197.171.239/api/v1/namespaces/emojivoto/pods/web-1:8080/proxy/debug/vars%3Fx=1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			url, err := api.PodProxyUrlFor("emojivoto", "web-1", tc.port, tc.path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if url.String() != tc.expected {
+				t.Fatalf("Expected generated URL to be [%s], but got [%s]", tc.expected, url.String())
+			}
+		})
+	}
+
+	t.Run("Rejects ports that are not numbers", func(t *testing.T) {
+		for _, port := range []string{"", "0", "65536", "linkerd-metrics"} {
+			if _, err := api.PodProxyUrlFor("emojivoto", "web-1", port, ""); err == nil {
+				t.Fatalf("Expected error for port [%s], got nothing", port)
+			}
+		}
+	})
+}
+
+func TestGetPodProxyResponse(t *testing.T) {
+	pod := v1.Pod{
+		TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "emojivoto"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  "web",
+					Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 8080}, {Name: "admin", ContainerPort: 9990}},
+				},
+				{
+					Name:  "linkerd-proxy",
+					Ports: []v1.ContainerPort{{Name: "linkerd-metrics", ContainerPort: 4191}, {Name: "admin", ContainerPort: 9995}},
+				},
+				{
+					Name:  "sidecar",
+					Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+				},
+			},
+		},
+		Status: v1.PodStatus{Phase: v1.PodPending},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/emojivoto/pods/web-1":
+			json.NewEncoder(w).Encode(pod)
+		case "/api/v1/namespaces/emojivoto/pods/web-1:4191/proxy/metrics":
+			w.Write([]byte("request_total 1\n"))
+		case "/api/v1/namespaces/emojivoto/pods/web-1:8080/proxy/ready":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+	t.Run("Resolves port names", func(t *testing.T) {
+		for _, port := range []string{"linkerd-metrics", "4191"} {
+			body, err := api.GetPodProxyResponse(context.Background(), "emojivoto", "web-1", port, "/metrics")
+			if err != nil {
+				t.Fatalf("Unexpected error for port [%s]: %v", port, err)
+			}
+			if string(body) != "request_total 1\n" {
+				t.Fatalf("Unexpected body for port [%s]: [%s]", port, body)
+			}
+		}
+	})
+
+	t.Run("Includes the pod phase in 503 errors", func(t *testing.T) {
+		// Both the web and sidecar containers declare http as 8080.
+		_, err := api.GetPodProxyResponse(context.Background(), "emojivoto", "web-1", "http", "/ready")
+		expected := "pod [web-1] is not ready (phase Pending): Unexpected Kubernetes API response: 503 Service Unavailable"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Returns an error for ambiguous and unknown port names", func(t *testing.T) {
+		expected := map[string]string{
+			"admin":   "port [admin] is ambiguous in pod [web-1]: declared as both 9990 and 9995",
+			"grpc":    "pod [web-1] has no port named [grpc]",
+			"":        "no port specified for pod [web-1]",
+			"HTTP":    "invalid port [HTTP], must be a port number or an IANA service name",
+			"1234567": "invalid port number [1234567], must be between 1 and 65535",
+		}
+		for port, msg := range expected {
+			_, err := api.GetPodProxyResponse(context.Background(), "emojivoto", "web-1", port, "/metrics")
+			if err == nil || err.Error() != msg {
+				t.Fatalf("Expected error [%s] for port [%s], got [%v]", msg, port, err)
+			}
+		}
+	})
+
+	t.Run("Returns a not found error for missing pods", func(t *testing.T) {
+		_, err := api.GetPodProxyResponse(context.Background(), "emojivoto", "web-2", "4191", "/metrics")
+		if !IsNotFound(err) {
+			t.Fatalf("Expected a not found error, got [%v]", err)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"context"
+
+	"k8s.io/api/core/v1"
+)
+
+// RunningPodsFieldSelector is a field selector that matches only pods in the
+// Running phase, for use with GetPodsForSelectors.
+const RunningPodsFieldSelector = "status.phase=Running"
+
+// GetPodsFor returns the pods in namespace matching labelSelector, e.g.
+// "linkerd.io/control-plane-component=controller". If namespace is empty,
+// pods from all namespaces are returned. If no pods match, the returned slice
+// is empty rather than nil.
+func (kubeAPI *KubernetesAPI) GetPodsFor(ctx context.Context, namespace, labelSelector string) ([]v1.Pod, error) {
+	return kubeAPI.GetPodsForSelectors(ctx, namespace, labelSelector, "")
+}
+
+// GetPodsForSelectors is like GetPodsFor, but additionally filters pods by
+// fieldSelector, e.g. RunningPodsFieldSelector. Empty selectors match all
+// pods.
+func (kubeAPI *KubernetesAPI) GetPodsForSelectors(ctx context.Context, namespace, labelSelector, fieldSelector string) ([]v1.Pod, error) {
+	pods, _, err := kubeAPI.listPods(ctx, namespace, labelSelector, fieldSelector)
+	return pods, err
+}
+
+// VisitPods calls visit with each page of the pods in namespace matching
+// labelSelector, as they are listed, so that callers going through the pods
+// of large clusters can report their progress. If visit returns an error,
+// listing stops and the error is returned.
+func (kubeAPI *KubernetesAPI) VisitPods(ctx context.Context, namespace, labelSelector string, visit func([]v1.Pod) error) error {
+	_, err := kubeAPI.visitPods(ctx, namespace, labelSelector, "", visit)
+	return err
+}
+
+// listPods lists the matching pods, and also returns the resource version of
+// the list, from which a watch can be started.
+func (kubeAPI *KubernetesAPI) listPods(ctx context.Context, namespace, labelSelector, fieldSelector string) ([]v1.Pod, string, error) {
+	pods := []v1.Pod{}
+	resourceVersion, err := kubeAPI.visitPods(ctx, namespace, labelSelector, fieldSelector, func(page []v1.Pod) error {
+		pods = append(pods, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return pods, resourceVersion, nil
+}
+
+func (kubeAPI *KubernetesAPI) visitPods(ctx context.Context, namespace, labelSelector, fieldSelector string, visit func([]v1.Pod) error) (string, error) {
+	path := podsPath(namespace)
+	continueToken := ""
+	for {
+		var list v1.PodList
+		opts := ListOptions{LabelSelector: labelSelector, FieldSelector: fieldSelector, Limit: listPageSize, Continue: continueToken}
+		if err := kubeAPI.listJSON(ctx, path, opts, &list); err != nil {
+			return "", err
+		}
+
+		if err := visit(list.Items); err != nil {
+			return "", err
+		}
+
+		continueToken = list.Continue
+		if continueToken == "" {
+			return list.ResourceVersion, nil
+		}
+	}
+}
+
+func podsPath(namespace string) string {
+	if namespace == "" {
+		return "/api/v1/pods"
+	}
+	return "/api/v1/namespaces/" + namespace + "/pods"
+}
+
+// GetPod returns the pod with the given name in namespace. If it does not
+// exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetPod(ctx context.Context, namespace, name string) (*v1.Pod, error) {
+	var pod v1.Pod
+	if err := kubeAPI.getJSON(ctx, podsPath(namespace)+"/"+name, &pod); err != nil {
+		return nil, err
+	}
+	return &pod, nil
+}
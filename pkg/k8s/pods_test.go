@@ -0,0 +1,167 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestGetPodsFor(t *testing.T) {
+	t.Run("Requests pods in the namespace matching the selectors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v1/namespaces/linkerd/pods" {
+				t.Fatalf("Unexpected path [%s]", r.URL.Path)
+			}
+			if got := r.URL.Query().Get("labelSelector"); got != "linkerd.io/control-plane-component=controller" {
+				t.Fatalf("Unexpected label selector [%s]", got)
+			}
+			if got := r.URL.Query().Get("fieldSelector"); got != RunningPodsFieldSelector {
+				t.Fatalf("Expected field selector [%s], got [%s]", RunningPodsFieldSelector, got)
+			}
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"linkerd-controller-6f78cbd47-bc557","namespace":"linkerd"},"status":{"phase":"Running"}}]}`))
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		pods, err := api.GetPodsForSelectors(context.Background(), "linkerd", "linkerd.io/control-plane-component=controller", RunningPodsFieldSelector)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pods) != 1 || pods[0].Name != "linkerd-controller-6f78cbd47-bc557" {
+			t.Fatalf("Expected pod [linkerd-controller-6f78cbd47-bc557], got %v", pods)
+		}
+	})
+
+	t.Run("Requests pods from all namespaces when none is given", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v1/pods" {
+				t.Fatalf("Unexpected path [%s]", r.URL.Path)
+			}
+			if _, ok := r.URL.Query()["fieldSelector"]; ok {
+				t.Fatalf("Expected no field selector, got [%s]", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`))
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		if _, err := api.GetPodsFor(context.Background(), "", "app=web"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Returns an empty slice when no pods match", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{}}`))
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		pods, err := api.GetPodsFor(context.Background(), "emojivoto", "app=missing")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pods == nil || len(pods) != 0 {
+			t.Fatalf("Expected an empty slice, got %#v", pods)
+		}
+	})
+
+	t.Run("Follows continue tokens", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if got := r.URL.Query().Get("labelSelector"); got != "app=web" {
+				t.Fatalf("Expected label selector to be preserved across pages, got [%s]", got)
+			}
+
+			switch r.URL.Query().Get("continue") {
+			case "":
+				w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"continue":"page-2"},"items":[{"metadata":{"name":"web-1"}},{"metadata":{"name":"web-2"}}]}`))
+			case "page-2":
+				w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"web-3"}}]}`))
+			default:
+				t.Fatalf("Unexpected continue token in [%s]", r.URL.RawQuery)
+			}
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		pods, err := api.GetPodsFor(context.Background(), "emojivoto", "app=web")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if requests != 2 {
+			t.Fatalf("Expected 2 requests, got %d", requests)
+		}
+		if len(pods) != 3 || pods[2].Name != "web-3" {
+			t.Fatalf("Expected pods [web-1 web-2 web-3], got %v", pods)
+		}
+	})
+
+	t.Run("Returns API errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		if _, err := api.GetPodsFor(context.Background(), "linkerd", ""); !IsForbidden(err) {
+			t.Fatalf("Expected a Forbidden error, got [%v]", err)
+		}
+	})
+}
+
+func TestVisitPods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("continue") {
+		case "":
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"continue":"page-2"},"items":[{"metadata":{"name":"web-1"}},{"metadata":{"name":"web-2"}}]}`))
+		case "page-2":
+			w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"web-3"}}]}`))
+		default:
+			t.Fatalf("Unexpected continue token in [%s]", r.URL.RawQuery)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Visits each page", func(t *testing.T) {
+		var pages [][]v1.Pod
+		err := api.VisitPods(context.Background(), "emojivoto", "app=web", func(page []v1.Pod) error {
+			pages = append(pages, page)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(pages) != 2 || len(pages[0]) != 2 || len(pages[1]) != 1 || pages[1][0].Name != "web-3" {
+			t.Fatalf("Expected pages [[web-1 web-2] [web-3]], got %v", pages)
+		}
+	})
+
+	t.Run("Stops at the first error", func(t *testing.T) {
+		pages := 0
+		stop := errors.New("stop")
+		err := api.VisitPods(context.Background(), "emojivoto", "app=web", func(page []v1.Pod) error {
+			pages++
+			return stop
+		})
+		if err != stop {
+			t.Fatalf("Expected [%v], got [%v]", stop, err)
+		}
+		if pages != 1 {
+			t.Fatalf("Expected 1 page to be visited, got %d", pages)
+		}
+	})
+}
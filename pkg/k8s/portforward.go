@@ -0,0 +1,334 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+)
+
+// portForwardProtocol is the streaming protocol for port-forwarding, as
+// spoken by the kubelet.
+const portForwardProtocol = "portforward.k8s.io"
+
+// reconnectTimeout is how long Run keeps trying to reconnect after losing the
+// connection to a pod, e.g. while the pod is replaced by a new one, waiting
+// reconnectInterval between attempts.
+var (
+	reconnectTimeout  = time.Minute
+	reconnectInterval = time.Second
+)
+
+// PortForward forwards connections on a local port to a port of a pod, through
+// the API server, like kubectl port-forward.
+type PortForward struct {
+	kubeAPI       *KubernetesAPI
+	namespace     string
+	labelSelector string
+	remotePort    int
+	listener      net.Listener
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	mu        sync.Mutex
+	pod       string
+	conn      httpstream.Connection
+	requestID int
+}
+
+// NewPortForward returns a PortForward to remotePort of a running pod in
+// namespace matching labelSelector, e.g.
+// "linkerd.io/control-plane-component=web", and starts listening on
+// localPort of 127.0.0.1. If localPort is 0, a free port is chosen, which
+// URLFor reports. Connections are only forwarded once Run is called.
+func NewPortForward(kubeAPI *KubernetesAPI, namespace, labelSelector string, localPort, remotePort int) (*PortForward, error) {
+	if remotePort < 1 || remotePort > 65535 {
+		return nil, fmt.Errorf("invalid remote port [%d], must be between 1 and 65535", remotePort)
+	}
+	if localPort < 0 || localPort > 65535 {
+		return nil, fmt.Errorf("invalid local port [%d], must be between 0 and 65535", localPort)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort)))
+	if err != nil {
+		if isAddrInUse(err) {
+			return nil, fmt.Errorf("local port %d is already in use, choose another one, or 0 to pick a free one", localPort)
+		}
+		return nil, fmt.Errorf("error listening on local port %d: %v", localPort, err)
+	}
+
+	return &PortForward{
+		kubeAPI:       kubeAPI,
+		namespace:     namespace,
+		labelSelector: labelSelector,
+		remotePort:    remotePort,
+		listener:      listener,
+		stopCh:        make(chan struct{}),
+	}, nil
+}
+
+func isAddrInUse(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	if !ok {
+		return false
+	}
+	syscallErr, ok := opErr.Err.(*os.SyscallError)
+	return ok && syscallErr.Err == syscall.EADDRINUSE
+}
+
+// LocalPort returns the local port connections are forwarded from.
+func (pf *PortForward) LocalPort() int {
+	return pf.listener.Addr().(*net.TCPAddr).Port
+}
+
+// URLFor returns the URL of path on the local end of the forward, e.g.
+// http://127.0.0.1:50750/metrics.
+func (pf *PortForward) URLFor(path string) (*url.URL, error) {
+	return joinURL(fmt.Sprintf("http://127.0.0.1:%d", pf.LocalPort()), escapePath(path))
+}
+
+// Run forwards connections to a pod matching the PortForward's selector, and
+// blocks until Stop is called or ctx is cancelled, in which case it returns
+// nil. Ready pods are preferred. If the connection to the pod is lost, e.g.
+// because the pod restarted, Run selects a pod again and reconnects, retrying
+// for up to a minute until a running pod matches, and returns an error if none
+// does.
+func (pf *PortForward) Run(ctx context.Context) error {
+	if pf.stopped() {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		pf.Stop()
+	}()
+
+	if err := pf.connect(ctx); err != nil {
+		if pf.stopped() {
+			return nil
+		}
+		return err
+	}
+
+	acceptErr := make(chan error, 1)
+	go func() {
+		acceptErr <- pf.accept()
+	}()
+
+	for {
+		pf.mu.Lock()
+		pod, conn := pf.pod, pf.conn
+		pf.mu.Unlock()
+
+		select {
+		case <-pf.stopCh:
+			return nil
+		case err := <-acceptErr:
+			if pf.stopped() {
+				return nil
+			}
+			return fmt.Errorf("error accepting connections on local port %d: %v", pf.LocalPort(), err)
+		case <-conn.CloseChan():
+		}
+
+		if pf.stopped() {
+			return nil
+		}
+
+		log.Infof("lost connection to pod [%s/%s], reconnecting", pf.namespace, pod)
+		if err := pf.reconnect(ctx); err != nil {
+			if pf.stopped() {
+				return nil
+			}
+			return fmt.Errorf("lost connection to pod [%s/%s] and failed to reconnect: %v", pf.namespace, pod, err)
+		}
+	}
+}
+
+// reconnect calls connect until it succeeds, reconnectTimeout passes, or the
+// PortForward is stopped, returning the last error.
+func (pf *PortForward) reconnect(ctx context.Context) error {
+	deadline := time.Now().Add(reconnectTimeout)
+	for {
+		err := pf.connect(ctx)
+		if err == nil || pf.stopped() || !time.Now().Add(reconnectInterval).Before(deadline) {
+			return err
+		}
+
+		log.Debugf("error reconnecting to a pod in namespace [%s]: %v", pf.namespace, err)
+		select {
+		case <-time.After(reconnectInterval):
+		case <-pf.stopCh:
+			return err
+		}
+	}
+}
+
+// Stop stops forwarding connections, and closes the local port. It may be
+// called more than once, and before Run.
+func (pf *PortForward) Stop() {
+	pf.stopOnce.Do(func() {
+		close(pf.stopCh)
+		pf.listener.Close()
+
+		pf.mu.Lock()
+		defer pf.mu.Unlock()
+		if pf.conn != nil {
+			pf.conn.Close()
+		}
+	})
+}
+
+func (pf *PortForward) stopped() bool {
+	select {
+	case <-pf.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// connect selects a pod and opens a streaming connection to it, replacing
+// the current one, if any.
+func (pf *PortForward) connect(ctx context.Context) error {
+	pod, err := pf.selectPod(ctx)
+	if err != nil {
+		return err
+	}
+
+	u, err := pf.kubeAPI.UrlFor(pf.namespace, "/pods/"+pod+"/portforward")
+	if err != nil {
+		return err
+	}
+
+	conn, _, err := pf.kubeAPI.DialStream(ctx, "POST", u, portForwardProtocol)
+	if err != nil {
+		return fmt.Errorf("error forwarding port %d of pod [%s/%s]: %v", pf.remotePort, pf.namespace, pod, err)
+	}
+
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+	if pf.stopped() {
+		conn.Close()
+		return fmt.Errorf("port forward stopped")
+	}
+	if pf.conn != nil {
+		pf.conn.Close()
+	}
+	pf.pod, pf.conn = pod, conn
+	log.Debugf("forwarding 127.0.0.1:%d to port %d of pod [%s/%s]", pf.LocalPort(), pf.remotePort, pf.namespace, pod)
+	return nil
+}
+
+// selectPod returns the name of a running pod matching the selector,
+// preferring ready ones.
+func (pf *PortForward) selectPod(ctx context.Context) (string, error) {
+	pods, err := pf.kubeAPI.GetPodsForSelectors(ctx, pf.namespace, pf.labelSelector, RunningPodsFieldSelector)
+	if err != nil {
+		return "", err
+	}
+	if len(pods) == 0 {
+		return "", fmt.Errorf("no running pods in namespace [%s] match [%s]", pf.namespace, pf.labelSelector)
+	}
+
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil && IsPodReady(pod) {
+			return pod.Name, nil
+		}
+	}
+	return pods[0].Name, nil
+}
+
+func (pf *PortForward) accept() error {
+	for {
+		local, err := pf.listener.Accept()
+		if err != nil {
+			return err
+		}
+		go pf.handleConnection(local)
+	}
+}
+
+// handleConnection forwards a local connection over a pair of streams to the
+// pod: one for data, and one the kubelet reports errors on.
+func (pf *PortForward) handleConnection(local net.Conn) {
+	defer local.Close()
+
+	pf.mu.Lock()
+	pf.requestID++
+	requestID, pod, conn := pf.requestID, pf.pod, pf.conn
+	pf.mu.Unlock()
+
+	headers := http.Header{}
+	headers.Set(v1.StreamType, v1.StreamTypeError)
+	headers.Set(v1.PortHeader, strconv.Itoa(pf.remotePort))
+	headers.Set(v1.PortForwardRequestIDHeader, strconv.Itoa(requestID))
+	errorStream, err := conn.CreateStream(headers)
+	if err != nil {
+		log.Errorf("error creating error stream to pod [%s/%s]: %v", pf.namespace, pod, err)
+		return
+	}
+	// Nothing is ever written to the error stream.
+	errorStream.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		message, err := ioutil.ReadAll(errorStream)
+		if err == nil && len(message) > 0 {
+			err = fmt.Errorf("%s", message)
+		}
+		errCh <- err
+	}()
+
+	headers.Set(v1.StreamType, v1.StreamTypeData)
+	dataStream, err := conn.CreateStream(headers)
+	if err != nil {
+		log.Errorf("error creating data stream to pod [%s/%s]: %v", pf.namespace, pod, err)
+		return
+	}
+
+	remoteDone := make(chan struct{})
+	go func() {
+		io.Copy(local, dataStream)
+		close(remoteDone)
+	}()
+
+	localErr := make(chan struct{})
+	go func() {
+		// Closing the data stream tells the pod no more data is coming.
+		defer dataStream.Close()
+		if _, err := io.Copy(dataStream, local); err != nil {
+			close(localErr)
+		}
+	}()
+
+	select {
+	case <-remoteDone:
+	case <-localErr:
+	case <-conn.CloseChan():
+		return
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			log.Errorf("error forwarding to port %d of pod [%s/%s]: %v", pf.remotePort, pf.namespace, pod, err)
+		}
+	case <-conn.CloseChan():
+	}
+}
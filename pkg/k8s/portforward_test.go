@@ -0,0 +1,360 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/client-go/rest"
+)
+
+// portForwardServer is a fake API server that lists running pods and answers
+// port-forwarding requests to them by writing the name of the pod to each data
+// stream.
+type portForwardServer struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	pods  []string
+	conns map[string]httpstream.Connection
+}
+
+func newPortForwardServer(pods ...string) *portForwardServer {
+	s := &portForwardServer{pods: pods, conns: map[string]httpstream.Connection{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *portForwardServer) setPods(pods ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pods = pods
+}
+
+// terminate closes the connection to pod, as when the pod terminates.
+func (s *portForwardServer) terminate(pod string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if conn, ok := s.conns[pod]; ok {
+		conn.Close()
+	}
+}
+
+func (s *portForwardServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/v1/namespaces/emojivoto/pods" {
+		s.mu.Lock()
+		list := v1.PodList{}
+		for _, name := range s.pods {
+			list.Items = append(list.Items, v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "emojivoto"},
+				Status: v1.PodStatus{
+					Phase:      v1.PodRunning,
+					Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}},
+				},
+			})
+		}
+		s.mu.Unlock()
+		json.NewEncoder(w).Encode(list)
+		return
+	}
+
+	pod := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/emojivoto/pods/"), "/portforward")
+	if r.Method != "POST" || pod == r.URL.Path {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if _, err := httpstream.Handshake(r, w, []string{portForwardProtocol}); err != nil {
+		return
+	}
+	conn := spdy.NewResponseUpgrader().UpgradeResponse(w, r, func(stream httpstream.Stream, replySent <-chan struct{}) error {
+		go func() {
+			<-replySent
+			if stream.Headers().Get(v1.StreamType) == v1.StreamTypeData {
+				stream.Write([]byte(pod))
+			}
+			stream.Close()
+		}()
+		return nil
+	})
+	if conn == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.conns[pod] = conn
+	s.mu.Unlock()
+	<-conn.CloseChan()
+}
+
+// readFrom connects to port and returns what is sent back.
+func readFrom(port int) (string, error) {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", port), time.Second)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	body, err := ioutil.ReadAll(conn)
+	return string(body), err
+}
+
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestPortForward(t *testing.T) {
+	t.Run("Picks a free local port and forwards connections", func(t *testing.T) {
+		server := newPortForwardServer("web-1")
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		pf, err := NewPortForward(api, "emojivoto", "app=web", 0, 8080)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pf.LocalPort() == 0 {
+			t.Fatalf("Expected a local port to be chosen")
+		}
+
+		url, err := pf.URLFor("/metrics")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := fmt.Sprintf("http://127.0.0.1:%d/metrics", pf.LocalPort())
+		if url.String() != expected {
+			t.Fatalf("Expected URL to be [%s], got [%s]", expected, url.String())
+		}
+
+		done := make(chan error, 1)
+		go func() { done <- pf.Run(context.Background()) }()
+
+		for i := 0; i < 2; i++ {
+			body, err := readFrom(pf.LocalPort())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if body != "web-1" {
+				t.Fatalf("Expected connection to be forwarded to [web-1], got [%s]", body)
+			}
+		}
+
+		pf.Stop()
+		if err := <-done; err != nil {
+			t.Fatalf("Expected Run to return nil after Stop, got [%v]", err)
+		}
+		if _, err := readFrom(pf.LocalPort()); err == nil {
+			t.Fatalf("Expected local port to be closed after Stop")
+		}
+	})
+
+	t.Run("Honors the requested local port", func(t *testing.T) {
+		port := freePort(t)
+		pf, err := NewPortForward(&KubernetesAPI{Config: &rest.Config{Host: "http://127.0.0.1:1"}}, "emojivoto", "app=web", port, 8080)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer pf.Stop()
+
+		if pf.LocalPort() != port {
+			t.Fatalf("Expected local port [%d], got [%d]", port, pf.LocalPort())
+		}
+	})
+
+	t.Run("Returns a clear error when the local port is in use", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer listener.Close()
+		port := listener.Addr().(*net.TCPAddr).Port
+
+		_, err = NewPortForward(&KubernetesAPI{Config: &rest.Config{Host: "http://127.0.0.1:1"}}, "emojivoto", "app=web", port, 8080)
+		expected := fmt.Sprintf("local port %d is already in use, choose another one, or 0 to pick a free one", port)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Rejects invalid ports", func(t *testing.T) {
+		api := &KubernetesAPI{Config: &rest.Config{Host: "http://127.0.0.1:1"}}
+		for _, ports := range [][2]int{{0, 0}, {0, 65536}, {-1, 8080}, {65536, 8080}} {
+			if _, err := NewPortForward(api, "emojivoto", "app=web", ports[0], ports[1]); err == nil {
+				t.Fatalf("Expected error for ports %v, got nothing", ports)
+			}
+		}
+	})
+
+	t.Run("Reconnects when the pod terminates", func(t *testing.T) {
+		defer func(timeout, interval time.Duration) {
+			reconnectTimeout, reconnectInterval = timeout, interval
+		}(reconnectTimeout, reconnectInterval)
+		reconnectTimeout, reconnectInterval = 200*time.Millisecond, 10*time.Millisecond
+
+		server := newPortForwardServer("web-1")
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		pf, err := NewPortForward(api, "emojivoto", "app=web", 0, 8080)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer pf.Stop()
+
+		done := make(chan error, 1)
+		go func() { done <- pf.Run(context.Background()) }()
+
+		if body, err := readFrom(pf.LocalPort()); err != nil || body != "web-1" {
+			t.Fatalf("Expected connection to be forwarded to [web-1], got [%s] and [%v]", body, err)
+		}
+
+		server.setPods("web-2")
+		server.terminate("web-1")
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			body, _ := readFrom(pf.LocalPort())
+			if body == "web-2" {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected connections to be forwarded to [web-2], got [%s]", body)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		server.setPods()
+		server.terminate("web-2")
+
+		select {
+		case err := <-done:
+			expected := "lost connection to pod [emojivoto/web-2] and failed to reconnect: no running pods in namespace [emojivoto] match [app=web]"
+			if err == nil || err.Error() != expected {
+				t.Fatalf("Expected error [%s], got [%v]", expected, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected Run to return after failing to reconnect")
+		}
+	})
+
+	t.Run("Waits for a new pod to start after the pod terminates", func(t *testing.T) {
+		defer func(interval time.Duration) { reconnectInterval = interval }(reconnectInterval)
+		reconnectInterval = 10 * time.Millisecond
+
+		server := newPortForwardServer("web-1")
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		pf, err := NewPortForward(api, "emojivoto", "app=web", 0, 8080)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer pf.Stop()
+
+		done := make(chan error, 1)
+		go func() { done <- pf.Run(context.Background()) }()
+
+		if body, err := readFrom(pf.LocalPort()); err != nil || body != "web-1" {
+			t.Fatalf("Expected connection to be forwarded to [web-1], got [%s] and [%v]", body, err)
+		}
+
+		server.setPods()
+		server.terminate("web-1")
+		time.Sleep(100 * time.Millisecond)
+		server.setPods("web-2")
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			body, _ := readFrom(pf.LocalPort())
+			if body == "web-2" {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected connections to be forwarded to [web-2], got [%s]", body)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		select {
+		case err := <-done:
+			t.Fatalf("Expected Run to keep forwarding, got [%v]", err)
+		default:
+		}
+	})
+
+	t.Run("Stops when the context is cancelled", func(t *testing.T) {
+		server := newPortForwardServer("web-1")
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		pf, err := NewPortForward(api, "emojivoto", "app=web", 0, 8080)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- pf.Run(ctx) }()
+
+		if body, err := readFrom(pf.LocalPort()); err != nil || body != "web-1" {
+			t.Fatalf("Expected connection to be forwarded to [web-1], got [%s] and [%v]", body, err)
+		}
+
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Expected Run to return nil after cancellation, got [%v]", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected Run to return after cancellation")
+		}
+	})
+
+	t.Run("Returns immediately when stopped before Run", func(t *testing.T) {
+		pf, err := NewPortForward(&KubernetesAPI{Config: &rest.Config{Host: "http://127.0.0.1:1"}}, "emojivoto", "app=web", 0, 8080)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		pf.Stop()
+		pf.Stop()
+		if err := pf.Run(context.Background()); err != nil {
+			t.Fatalf("Expected Run to return nil, got [%v]", err)
+		}
+	})
+
+	t.Run("Returns an error when no pods match", func(t *testing.T) {
+		server := newPortForwardServer()
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		pf, err := NewPortForward(api, "emojivoto", "app=web", 0, 8080)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer pf.Stop()
+
+		expected := "no running pods in namespace [emojivoto] match [app=web]"
+		if err := pf.Run(context.Background()); err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"os"
+	"strconv"
+	"syscall"
 
 	log "github.com/sirupsen/logrus"
 	"k8s.io/client-go/rest"
@@ -12,26 +15,53 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
+// DefaultProxyAddress is the address a KubernetesProxy listens on if none is
+// given, so that it can only be used from the local host.
+const DefaultProxyAddress = "127.0.0.1"
+
 type KubernetesProxy struct {
 	listener net.Listener
 	server   *proxy.Server
 }
 
-// NewProxy returns a new KubernetesProxy object and starts listening on a
-// network address.
-func NewProxy(configPath string, proxyPort int) (*KubernetesProxy, error) {
-	config, err := getConfig(configPath)
+// AddressInUseError is returned by NewProxy when another process is already
+// listening on the requested address and port.
+type AddressInUseError struct {
+	Address string
+	Err     error
+}
+
+func (e *AddressInUseError) Error() string {
+	return fmt.Sprintf("address [%s] is already in use: %s", e.Address, e.Err)
+}
+
+// NewProxy returns a new KubernetesProxy object and starts listening on the
+// given address and port, or on DefaultProxyAddress if address is empty. If
+// proxyPort is 0, a free port is picked, which is reflected in the URLs
+// returned by URLFor and ServiceProxyURLFor. If kubeContext is empty, the
+// kubeconfig's current-context is used. Since the proxy authenticates with
+// the kubeconfig's credentials, listening on a non-loopback address lets
+// anyone who can reach it use them.
+func NewProxy(configPath, kubeContext, address string, proxyPort int) (*KubernetesProxy, error) {
+	if address == "" {
+		address = DefaultProxyAddress
+	}
+
+	config, err := getConfig(configPath, kubeContext)
 	if err != nil {
 		return nil, fmt.Errorf("error configuring Kubernetes API client: %v", err)
 	}
 
-	server, err := proxyCreate(config)
+	server, err := proxyCreate(config, IsLoopbackAddress(address))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to create proxy: %+v", err)
 	}
 
-	listener, err := proxyListen(server, proxyPort)
+	listener, err := Listen(address, proxyPort)
 	if err != nil {
+		if _, ok := err.(*AddressInUseError); ok {
+			return nil, err
+		}
 		return nil, fmt.Errorf("Failed to listen with proxy: %+v", err)
 	}
 
@@ -55,15 +85,63 @@ func (kp *KubernetesProxy) Run() error {
 
 // URLFor generates a URL based on the configured KubernetesProxy.
 func (kp *KubernetesProxy) URLFor(namespace string, extraPathStartingWithSlash string) (*url.URL, error) {
-	schemeHostAndPort := fmt.Sprintf("http://127.0.0.1:%d", kp.listener.Addr().(*net.TCPAddr).Port)
-	return generateKubernetesApiBaseUrlFor(schemeHostAndPort, namespace, extraPathStartingWithSlash)
+	return generateKubernetesApiBaseUrlFor(kp.schemeHostAndPort(), namespace, extraPathStartingWithSlash)
+}
+
+// ServiceProxyURLFor is like KubernetesAPI.ServiceProxyUrlFor, but for the
+// configured KubernetesProxy.
+func (kp *KubernetesProxy) ServiceProxyURLFor(namespace, service, port, scheme, path string) (*url.URL, error) {
+	proxyPath, err := serviceProxyPath(namespace, service, port, scheme, path)
+	if err != nil {
+		return nil, err
+	}
+	return generateProxyUrl(kp.schemeHostAndPort(), proxyPath)
+}
+
+// Port returns the port the proxy is listening on.
+func (kp *KubernetesProxy) Port() int {
+	return kp.listener.Addr().(*net.TCPAddr).Port
 }
 
-func proxyCreate(config *rest.Config) (*proxy.Server, error) {
+// schemeHostAndPort returns the base URL of the proxy.
+func (kp *KubernetesProxy) schemeHostAndPort() string {
+	return ListenerURL(kp.listener)
+}
+
+// ListenerURL returns the base URL of an HTTP server on listener, e.g.
+// http://127.0.0.1:50750, addressing servers listening on all interfaces
+// through the loopback interface.
+func ListenerURL(listener net.Listener) string {
+	addr := listener.Addr().(*net.TCPAddr)
+	host := DefaultProxyAddress
+	if !addr.IP.IsUnspecified() {
+		host = addr.IP.String()
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(addr.Port))
+}
+
+// IsLoopbackAddress reports whether address, a host name or IP, only accepts
+// connections from the local host.
+func IsLoopbackAddress(address string) bool {
+	if address == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsLoopback()
+}
+
+// proxyCreate creates the proxy server. Unless it only listens on a loopback
+// address, requests for any Host are accepted, since they are addressed to
+// the host's external name or IP.
+func proxyCreate(config *rest.Config, loopback bool) (*proxy.Server, error) {
+	acceptHosts := proxy.DefaultHostAcceptRE
+	if !loopback {
+		acceptHosts = "^.*$"
+	}
 	filter := &proxy.FilterServer{
 		AcceptPaths:   proxy.MakeRegexpArrayOrDie(proxy.DefaultPathAcceptRE),
 		RejectPaths:   proxy.MakeRegexpArrayOrDie(proxy.DefaultPathRejectRE),
-		AcceptHosts:   proxy.MakeRegexpArrayOrDie(proxy.DefaultHostAcceptRE),
+		AcceptHosts:   proxy.MakeRegexpArrayOrDie(acceptHosts),
 		RejectMethods: proxy.MakeRegexpArrayOrDie(proxy.DefaultMethodRejectRE),
 	}
 	server, err := proxy.NewServer("", "/", "/static/", filter, config)
@@ -74,15 +152,30 @@ func proxyCreate(config *rest.Config) (*proxy.Server, error) {
 	return server, nil
 }
 
-func proxyListen(server *proxy.Server, proxyPort int) (net.Listener, error) {
-	listener, err := server.Listen("127.0.0.1", proxyPort)
+// Listen listens on the given address and port, or on a free port if port is
+// 0, returning an AddressInUseError if the port is already taken.
+func Listen(address string, port int) (net.Listener, error) {
+	hostPort := net.JoinHostPort(address, strconv.Itoa(port))
+	listener, err := net.Listen("tcp", hostPort)
 	if err != nil {
+		if isAddressInUse(err) {
+			return nil, &AddressInUseError{Address: hostPort, Err: err}
+		}
 		return nil, fmt.Errorf("Failed to listen via proxy server: %+v", err)
 	}
 
 	return listener, nil
 }
 
+func isAddressInUse(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	if !ok {
+		return false
+	}
+	sysErr, ok := opErr.Err.(*os.SyscallError)
+	return ok && sysErr.Err == syscall.EADDRINUSE
+}
+
 func proxyServe(server *proxy.Server, listener net.Listener) error {
 	log.Infof("Starting to serve on %s", listener.Addr().String())
 
@@ -8,7 +8,7 @@ import (
 
 func TestInitK8sProxy(t *testing.T) {
 	t.Run("Returns an initialized Kubernetes Proxy object", func(t *testing.T) {
-		kp, err := NewProxy( "testdata/config.test", 0)
+		kp, err := NewProxy("testdata/config.test", "", "", 0)
 		if err != nil {
 			t.Fatalf("Unexpected error creating Kubernetes API: %+v", err)
 		}
@@ -24,7 +24,7 @@ func TestKubernetesProxyUrlFor(t *testing.T) {
 	const extraPath = "/some/extra/path"
 
 	t.Run("Returns proxy URL based on the initialized KubernetesProxy", func(t *testing.T) {
-		kp, err := NewProxy( "testdata/config.test", 0)
+		kp, err := NewProxy("testdata/config.test", "", "", 0)
 		if err != nil {
 			t.Fatalf("Unexpected error creating Kubernetes API: %+v", err)
 		}
@@ -41,4 +41,115 @@ func TestKubernetesProxyUrlFor(t *testing.T) {
 	})
 }
 
+func TestKubernetesProxyServiceProxyURLFor(t *testing.T) {
+	kp, err := NewProxy("testdata/config.test", "", "", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error creating Kubernetes API: %+v", err)
+	}
+
+	actualURL, err := kp.ServiceProxyURLFor("linkerd", "web", "http", "", "")
+	if err != nil {
+		t.Fatalf("Unexpected error generating URL: %+v", err)
+	}
+
+	expected := fmt.Sprintf("http://127.0.0.1:%d/api/v1/namespaces/linkerd/services/web:http/proxy/", kp.listener.Addr().(*net.TCPAddr).Port)
+	if url := actualURL.String(); url != expected {
+		t.Fatalf("Expected generated URL to be [%s], but got [%s]", expected, url)
+	}
+}
+
 // TODO: test kb.Run()
+
+func TestNewProxyPort(t *testing.T) {
+	freePort := func() int {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer listener.Close()
+		return listener.Addr().(*net.TCPAddr).Port
+	}
+
+	t.Run("Listens on the given port", func(t *testing.T) {
+		port := freePort()
+		kp, err := NewProxy("testdata/config.test", "", "", port)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer kp.listener.Close()
+
+		if kp.Port() != port {
+			t.Fatalf("Expected proxy to listen on port %d, got %d", port, kp.Port())
+		}
+	})
+
+	t.Run("Picks a free port for port 0", func(t *testing.T) {
+		kp, err := NewProxy("testdata/config.test", "", "", 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer kp.listener.Close()
+
+		if kp.Port() == 0 {
+			t.Fatalf("Expected proxy to listen on a free port")
+		}
+		u, err := kp.URLFor("linkerd", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if expected := fmt.Sprintf("127.0.0.1:%d", kp.Port()); u.Host != expected {
+			t.Fatalf("Expected URL host [%s], got [%s]", expected, u.Host)
+		}
+	})
+
+	t.Run("Returns an AddressInUseError for ports in use", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer listener.Close()
+		port := listener.Addr().(*net.TCPAddr).Port
+
+		_, err = NewProxy("testdata/config.test", "", "", port)
+		inUse, ok := err.(*AddressInUseError)
+		if !ok {
+			t.Fatalf("Expected an AddressInUseError, got [%v]", err)
+		}
+		if expected := fmt.Sprintf("127.0.0.1:%d", port); inUse.Address != expected {
+			t.Fatalf("Expected address [%s], got [%s]", expected, inUse.Address)
+		}
+	})
+
+	t.Run("Addresses proxies listening on all interfaces through the loopback interface", func(t *testing.T) {
+		kp, err := NewProxy("testdata/config.test", "", "0.0.0.0", 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer kp.listener.Close()
+
+		u, err := kp.URLFor("linkerd", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if expected := fmt.Sprintf("127.0.0.1:%d", kp.Port()); u.Host != expected {
+			t.Fatalf("Expected URL host [%s], got [%s]", expected, u.Host)
+		}
+	})
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	for address, expected := range map[string]bool{
+		"localhost":   true,
+		"127.0.0.1":   true,
+		"::1":         true,
+		"0.0.0.0":     false,
+		"10.0.0.4":    false,
+		"bastion.lan": false,
+	} {
+		t.Run(address, func(t *testing.T) {
+			if IsLoopbackAddress(address) != expected {
+				t.Fatalf("Expected IsLoopbackAddress(%s) to be %t", address, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podSecurityPolicyGroupVersion is the API the PodSecurityPolicies rendered by
+// `linkerd install --enable-psp` are created with.
+const podSecurityPolicyGroupVersion = "policy/v1beta1"
+
+// PodSecurityPoliciesEnforced reports whether the cluster is likely to reject
+// pods that are not allowed by a PodSecurityPolicy. Whether the admission
+// controller is enabled is not exposed by the API, so this probes the policy
+// API group instead: PodSecurityPolicies are enforced if it serves them and
+// at least one exists, since the admission controller rejects every pod of a
+// cluster without policies.
+func (kubeAPI *KubernetesAPI) PodSecurityPoliciesEnforced(ctx context.Context) (bool, error) {
+	var resources metav1.APIResourceList
+	if err := kubeAPI.getJSON(ctx, "/apis/"+podSecurityPolicyGroupVersion, &resources); err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	served := false
+	for _, resource := range resources.APIResources {
+		if resource.Name == "podsecuritypolicies" {
+			served = true
+			break
+		}
+	}
+	if !served {
+		return false, nil
+	}
+
+	var policies struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := kubeAPI.listJSON(ctx, "/apis/"+podSecurityPolicyGroupVersion+"/podsecuritypolicies", ListOptions{Limit: 1}, &policies); err != nil {
+		return false, err
+	}
+	return len(policies.Items) > 0, nil
+}
@@ -0,0 +1,93 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestPodSecurityPoliciesEnforced(t *testing.T) {
+	policyResources := `{"kind":"APIResourceList","groupVersion":"policy/v1beta1","resources":[{"name":"poddisruptionbudgets","namespaced":true,"kind":"PodDisruptionBudget"},{"name":"podsecuritypolicies","namespaced":false,"kind":"PodSecurityPolicy"}]}`
+	disruptionBudgetsOnly := `{"kind":"APIResourceList","groupVersion":"policy/v1beta1","resources":[{"name":"poddisruptionbudgets","namespaced":true,"kind":"PodDisruptionBudget"}]}`
+
+	testCases := []struct {
+		name             string
+		resourcesStatus  int
+		resources        string
+		policiesStatus   int
+		policies         string
+		expectedEnforced bool
+		expectedErr      bool
+	}{
+		{
+			name:            "Not enforced without the policy API group",
+			resourcesStatus: http.StatusNotFound,
+			resources:       `{"kind":"Status","reason":"NotFound","code":404}`,
+		},
+		{
+			name:            "Not enforced if the policy API group does not serve PodSecurityPolicies",
+			resourcesStatus: http.StatusOK,
+			resources:       disruptionBudgetsOnly,
+		},
+		{
+			name:            "Not enforced without any PodSecurityPolicy",
+			resourcesStatus: http.StatusOK,
+			resources:       policyResources,
+			policiesStatus:  http.StatusOK,
+			policies:        `{"kind":"PodSecurityPolicyList","items":[]}`,
+		},
+		{
+			name:             "Enforced with a PodSecurityPolicy",
+			resourcesStatus:  http.StatusOK,
+			resources:        policyResources,
+			policiesStatus:   http.StatusOK,
+			policies:         `{"kind":"PodSecurityPolicyList","items":[{"metadata":{"name":"restricted"}}]}`,
+			expectedEnforced: true,
+		},
+		{
+			name:            "Fails if PodSecurityPolicies cannot be listed",
+			resourcesStatus: http.StatusOK,
+			resources:       policyResources,
+			policiesStatus:  http.StatusForbidden,
+			policies:        `{"kind":"Status","reason":"Forbidden","code":403}`,
+			expectedErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/apis/policy/v1beta1":
+					w.WriteHeader(tc.resourcesStatus)
+					w.Write([]byte(tc.resources))
+				case "/apis/policy/v1beta1/podsecuritypolicies":
+					w.WriteHeader(tc.policiesStatus)
+					w.Write([]byte(tc.policies))
+				default:
+					t.Errorf("Unexpected request to [%s]", r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+			api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+			enforced, err := api.PodSecurityPoliciesEnforced(context.Background())
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got nothing")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if enforced != tc.expectedEnforced {
+				t.Fatalf("Expected PodSecurityPoliciesEnforced to return [%t], got [%t]", tc.expectedEnforced, enforced)
+			}
+		})
+	}
+}
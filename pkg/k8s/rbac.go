@@ -0,0 +1,126 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// GetClusterRole returns the ClusterRole with the given name. If it does not
+// exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetClusterRole(ctx context.Context, name string) (*rbacv1.ClusterRole, error) {
+	var role rbacv1.ClusterRole
+	if err := kubeAPI.getJSON(ctx, "/apis/rbac.authorization.k8s.io/v1/clusterroles/"+name, &role); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetClusterRoleBinding returns the ClusterRoleBinding with the given name. If
+// it does not exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetClusterRoleBinding(ctx context.Context, name string) (*rbacv1.ClusterRoleBinding, error) {
+	var binding rbacv1.ClusterRoleBinding
+	if err := kubeAPI.getJSON(ctx, "/apis/rbac.authorization.k8s.io/v1/clusterrolebindings/"+name, &binding); err != nil {
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// GetRole returns the Role with the given name in namespace. If it does not
+// exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetRole(ctx context.Context, namespace, name string) (*rbacv1.Role, error) {
+	var role rbacv1.Role
+	if err := kubeAPI.getJSON(ctx, "/apis/rbac.authorization.k8s.io/v1/namespaces/"+namespace+"/roles/"+name, &role); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetRoleBinding returns the RoleBinding with the given name in namespace. If
+// it does not exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetRoleBinding(ctx context.Context, namespace, name string) (*rbacv1.RoleBinding, error) {
+	var binding rbacv1.RoleBinding
+	if err := kubeAPI.getJSON(ctx, "/apis/rbac.authorization.k8s.io/v1/namespaces/"+namespace+"/rolebindings/"+name, &binding); err != nil {
+		return nil, err
+	}
+	return &binding, nil
+}
+
+// ValidateClusterRoleBinding verifies that the ClusterRoleBinding with the
+// given name exists, that it binds the serviceAccount in namespace, and that
+// the ClusterRole it references exists. The returned error describes the
+// first problem found; a binding whose subject is in another namespace usually
+// means that the control plane was reinstalled into a different namespace.
+func (kubeAPI *KubernetesAPI) ValidateClusterRoleBinding(ctx context.Context, name, namespace, serviceAccount string) error {
+	binding, err := kubeAPI.GetClusterRoleBinding(ctx, name)
+	if IsNotFound(err) {
+		return fmt.Errorf("ClusterRoleBinding [%s] does not exist", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := CheckClusterRoleBindingSubject(binding, namespace, serviceAccount); err != nil {
+		return err
+	}
+
+	_, err = kubeAPI.GetClusterRole(ctx, binding.RoleRef.Name)
+	if IsNotFound(err) {
+		return fmt.Errorf("ClusterRoleBinding [%s] references ClusterRole [%s], which does not exist", name, binding.RoleRef.Name)
+	}
+	return err
+}
+
+// ValidateRoleBinding is the namespaced counterpart of
+// ValidateClusterRoleBinding: it verifies that the RoleBinding with the given
+// name in namespace exists, that it binds the serviceAccount in namespace, and
+// that the Role it references exists in namespace.
+func (kubeAPI *KubernetesAPI) ValidateRoleBinding(ctx context.Context, namespace, name, serviceAccount string) error {
+	binding, err := kubeAPI.GetRoleBinding(ctx, namespace, name)
+	if IsNotFound(err) {
+		return fmt.Errorf("RoleBinding [%s/%s] does not exist", namespace, name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := checkBindingSubject("RoleBinding", binding.Name, binding.Subjects, namespace, serviceAccount); err != nil {
+		return err
+	}
+
+	if binding.RoleRef.Kind != "Role" {
+		return fmt.Errorf("RoleBinding [%s/%s] references %s [%s], expected a Role", namespace, name, binding.RoleRef.Kind, binding.RoleRef.Name)
+	}
+	_, err = kubeAPI.GetRole(ctx, namespace, binding.RoleRef.Name)
+	if IsNotFound(err) {
+		return fmt.Errorf("RoleBinding [%s/%s] references Role [%s], which does not exist", namespace, name, binding.RoleRef.Name)
+	}
+	return err
+}
+
+// CheckClusterRoleBindingSubject returns an error if binding does not have the
+// serviceAccount in namespace as a subject.
+func CheckClusterRoleBindingSubject(binding *rbacv1.ClusterRoleBinding, namespace, serviceAccount string) error {
+	return checkBindingSubject("ClusterRoleBinding", binding.Name, binding.Subjects, namespace, serviceAccount)
+}
+
+func checkBindingSubject(kind, name string, subjects []rbacv1.Subject, namespace, serviceAccount string) error {
+	var otherNamespaces []string
+	for _, subject := range subjects {
+		if subject.Kind != rbacv1.ServiceAccountKind || subject.Name != serviceAccount {
+			continue
+		}
+		if subject.Namespace == namespace {
+			return nil
+		}
+		otherNamespaces = append(otherNamespaces, subject.Namespace)
+	}
+
+	if len(otherNamespaces) > 0 {
+		return fmt.Errorf("%s [%s] binds ServiceAccount [%s] in namespace [%s], expected namespace [%s]",
+			kind, name, serviceAccount, strings.Join(otherNamespaces, ", "), namespace)
+	}
+	return fmt.Errorf("%s [%s] does not bind ServiceAccount [%s/%s]", kind, name, namespace, serviceAccount)
+}
@@ -0,0 +1,115 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestValidateClusterRoleBinding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/rbac.authorization.k8s.io/v1/clusterroles/linkerd-linkerd-controller":
+			w.Write([]byte(`{"kind":"ClusterRole","apiVersion":"rbac.authorization.k8s.io/v1","metadata":{"name":"linkerd-linkerd-controller"},"rules":[{"apiGroups":[""],"resources":["pods"],"verbs":["list","get","watch"]}]}`))
+		case "/apis/rbac.authorization.k8s.io/v1/clusterrolebindings/linkerd-linkerd-controller":
+			w.Write([]byte(`{"kind":"ClusterRoleBinding","apiVersion":"rbac.authorization.k8s.io/v1","metadata":{"name":"linkerd-linkerd-controller"},"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"ClusterRole","name":"linkerd-linkerd-controller"},"subjects":[{"kind":"ServiceAccount","name":"linkerd-controller","namespace":"linkerd"}]}`))
+		case "/apis/rbac.authorization.k8s.io/v1/clusterrolebindings/linkerd-linkerd-prometheus":
+			w.Write([]byte(`{"kind":"ClusterRoleBinding","apiVersion":"rbac.authorization.k8s.io/v1","metadata":{"name":"linkerd-linkerd-prometheus"},"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"ClusterRole","name":"linkerd-linkerd-prometheus"},"subjects":[{"kind":"ServiceAccount","name":"linkerd-prometheus","namespace":"linkerd-old"}]}`))
+		case "/apis/rbac.authorization.k8s.io/v1/clusterrolebindings/linkerd-linkerd-ca":
+			w.Write([]byte(`{"kind":"ClusterRoleBinding","apiVersion":"rbac.authorization.k8s.io/v1","metadata":{"name":"linkerd-linkerd-ca"},"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"ClusterRole","name":"linkerd-linkerd-ca"},"subjects":[{"kind":"ServiceAccount","name":"linkerd-ca","namespace":"linkerd"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	testCases := []struct {
+		binding        string
+		serviceAccount string
+		err            string
+	}{
+		{binding: "linkerd-linkerd-controller", serviceAccount: "linkerd-controller"},
+		{binding: "linkerd-linkerd-controller", serviceAccount: "linkerd-web", err: "does not bind ServiceAccount [linkerd/linkerd-web]"},
+		{binding: "linkerd-linkerd-prometheus", serviceAccount: "linkerd-prometheus", err: "binds ServiceAccount [linkerd-prometheus] in namespace [linkerd-old], expected namespace [linkerd]"},
+		{binding: "linkerd-linkerd-ca", serviceAccount: "linkerd-ca", err: "references ClusterRole [linkerd-linkerd-ca], which does not exist"},
+		{binding: "linkerd-linkerd-missing", serviceAccount: "linkerd-controller", err: "ClusterRoleBinding [linkerd-linkerd-missing] does not exist"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.binding+"/"+tc.serviceAccount, func(t *testing.T) {
+			err := api.ValidateClusterRoleBinding(context.Background(), tc.binding, "linkerd", tc.serviceAccount)
+			if tc.err == "" && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Fatalf("Expected error to contain [%s], got [%v]", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestValidateRoleBinding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/rbac.authorization.k8s.io/v1/namespaces/linkerd/roles/linkerd-controller":
+			w.Write([]byte(`{"kind":"Role","apiVersion":"rbac.authorization.k8s.io/v1","metadata":{"name":"linkerd-controller","namespace":"linkerd"},"rules":[{"apiGroups":[""],"resources":["pods"],"verbs":["list","get","watch"]}]}`))
+		case "/apis/rbac.authorization.k8s.io/v1/namespaces/linkerd/rolebindings/linkerd-controller":
+			w.Write([]byte(`{"kind":"RoleBinding","apiVersion":"rbac.authorization.k8s.io/v1","metadata":{"name":"linkerd-controller","namespace":"linkerd"},"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"Role","name":"linkerd-controller"},"subjects":[{"kind":"ServiceAccount","name":"linkerd-controller","namespace":"linkerd"}]}`))
+		case "/apis/rbac.authorization.k8s.io/v1/namespaces/linkerd/rolebindings/linkerd-prometheus":
+			w.Write([]byte(`{"kind":"RoleBinding","apiVersion":"rbac.authorization.k8s.io/v1","metadata":{"name":"linkerd-prometheus","namespace":"linkerd"},"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"Role","name":"linkerd-prometheus"},"subjects":[{"kind":"ServiceAccount","name":"linkerd-prometheus","namespace":"linkerd"}]}`))
+		case "/apis/rbac.authorization.k8s.io/v1/namespaces/linkerd/rolebindings/linkerd-ca":
+			w.Write([]byte(`{"kind":"RoleBinding","apiVersion":"rbac.authorization.k8s.io/v1","metadata":{"name":"linkerd-ca","namespace":"linkerd"},"roleRef":{"apiGroup":"rbac.authorization.k8s.io","kind":"ClusterRole","name":"admin"},"subjects":[{"kind":"ServiceAccount","name":"linkerd-ca","namespace":"linkerd"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	testCases := []struct {
+		binding        string
+		serviceAccount string
+		err            string
+	}{
+		{binding: "linkerd-controller", serviceAccount: "linkerd-controller"},
+		{binding: "linkerd-controller", serviceAccount: "linkerd-web", err: "RoleBinding [linkerd-controller] does not bind ServiceAccount [linkerd/linkerd-web]"},
+		{binding: "linkerd-prometheus", serviceAccount: "linkerd-prometheus", err: "references Role [linkerd-prometheus], which does not exist"},
+		{binding: "linkerd-ca", serviceAccount: "linkerd-ca", err: "references ClusterRole [admin], expected a Role"},
+		{binding: "linkerd-missing", serviceAccount: "linkerd-controller", err: "RoleBinding [linkerd/linkerd-missing] does not exist"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.binding+"/"+tc.serviceAccount, func(t *testing.T) {
+			err := api.ValidateRoleBinding(context.Background(), "linkerd", tc.binding, tc.serviceAccount)
+			if tc.err == "" && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Fatalf("Expected error to contain [%s], got [%v]", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestGetClusterRoleBinding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	if _, err := api.GetClusterRoleBinding(context.Background(), "missing"); !IsNotFound(err) {
+		t.Fatalf("Expected a NotFound error, got [%v]", err)
+	}
+	if _, err := api.GetClusterRole(context.Background(), "missing"); !IsNotFound(err) {
+		t.Fatalf("Expected a NotFound error, got [%v]", err)
+	}
+}
@@ -0,0 +1,92 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourceType is a kind of object served by the Kubernetes API.
+type ResourceType struct {
+	// Kind is the kind of the objects, e.g. "ClusterRole", and Resource the
+	// name of their collection, e.g. "clusterroles".
+	Kind     string
+	Resource string
+
+	// Group is the API group of the objects, empty for the core group, and
+	// Version its version, e.g. "rbac.authorization.k8s.io" and "v1".
+	Group   string
+	Version string
+
+	Namespaced bool
+}
+
+// Resource is an object served by the Kubernetes API. Namespace is empty for
+// cluster-scoped objects.
+type Resource struct {
+	ResourceType
+	Namespace string
+	Name      string
+}
+
+func (r Resource) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s/%s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s/%s in namespace %s", r.Kind, r.Name, r.Namespace)
+}
+
+// collectionPath returns the path of the collection of objects of the type in
+// namespace, which is ignored for cluster-scoped types.
+func (t ResourceType) collectionPath(namespace string) string {
+	path := "/apis/" + t.Group + "/" + t.Version
+	if t.Group == "" {
+		path = "/api/" + t.Version
+	}
+	if t.Namespaced {
+		path += "/namespaces/" + namespace
+	}
+	return path + "/" + t.Resource
+}
+
+// ListResources returns the objects of resourceType in namespace matching
+// labelSelector. namespace is ignored for cluster-scoped types. If the API
+// does not serve resourceType, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) ListResources(ctx context.Context, resourceType ResourceType, namespace, labelSelector string) ([]Resource, error) {
+	resources := []Resource{}
+	continueToken := ""
+	for {
+		var list struct {
+			metav1.ListMeta `json:"metadata"`
+			Items           []struct {
+				metav1.ObjectMeta `json:"metadata"`
+			} `json:"items"`
+		}
+		opts := ListOptions{LabelSelector: labelSelector, Limit: listPageSize, Continue: continueToken}
+		if err := kubeAPI.listJSON(ctx, resourceType.collectionPath(namespace), opts, &list); err != nil {
+			return nil, err
+		}
+
+		for _, item := range list.Items {
+			resources = append(resources, Resource{ResourceType: resourceType, Namespace: item.Namespace, Name: item.Name})
+		}
+
+		continueToken = list.Continue
+		if continueToken == "" {
+			return resources, nil
+		}
+	}
+}
+
+// DeleteResource deletes resource, along with the objects it owns, such as the
+// ReplicaSets and pods of a Deployment, which are deleted in the background.
+// If it does not exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) DeleteResource(ctx context.Context, resource Resource) error {
+	propagation := metav1.DeletePropagationBackground
+	options := metav1.DeleteOptions{
+		TypeMeta:          metav1.TypeMeta{Kind: "DeleteOptions", APIVersion: "v1"},
+		PropagationPolicy: &propagation,
+	}
+	return kubeAPI.deleteJSON(ctx, resource.collectionPath(resource.Namespace)+"/"+resource.Name, options, &struct{}{})
+}
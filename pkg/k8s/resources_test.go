@@ -0,0 +1,117 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	clusterRoles = ResourceType{Kind: "ClusterRole", Resource: "clusterroles", Group: "rbac.authorization.k8s.io", Version: "v1"}
+	services     = ResourceType{Kind: "Service", Resource: "services", Version: "v1", Namespaced: true}
+)
+
+func TestListResources(t *testing.T) {
+	t.Run("Lists every page of the resources matching the selector", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v1/namespaces/linkerd/services" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if selector := r.URL.Query().Get("labelSelector"); selector != "app=web" {
+				t.Errorf("Unexpected selector [%s]", selector)
+			}
+			if r.URL.Query().Get("continue") == "" {
+				w.Write([]byte(`{"metadata":{"continue":"next"},"items":[{"metadata":{"name":"api","namespace":"linkerd"}}]}`))
+				return
+			}
+			w.Write([]byte(`{"items":[{"metadata":{"name":"web","namespace":"linkerd"}}]}`))
+		}))
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		resources, err := api.ListResources(context.Background(), services, "linkerd", "app=web")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []Resource{
+			{ResourceType: services, Namespace: "linkerd", Name: "api"},
+			{ResourceType: services, Namespace: "linkerd", Name: "web"},
+		}
+		if !reflect.DeepEqual(resources, expected) {
+			t.Fatalf("Expected %v, got %v", expected, resources)
+		}
+	})
+
+	t.Run("Ignores the namespace of cluster-scoped resources", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/apis/rbac.authorization.k8s.io/v1/clusterroles" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"items":[{"metadata":{"name":"linkerd-linkerd-controller"}}]}`))
+		}))
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		resources, err := api.ListResources(context.Background(), clusterRoles, "linkerd", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(resources) != 1 || resources[0].String() != "ClusterRole/linkerd-linkerd-controller" {
+			t.Fatalf("Unexpected resources: %v", resources)
+		}
+	})
+
+	t.Run("Returns a not found error for types that are not served", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		if _, err := api.ListResources(context.Background(), clusterRoles, "", ""); !IsNotFound(err) {
+			t.Fatalf("Expected a not found error, got [%v]", err)
+		}
+	})
+}
+
+func TestDeleteResource(t *testing.T) {
+	var path string
+	var options metav1.DeleteOptions
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("Unexpected method [%s]", r.Method)
+		}
+		path = r.URL.Path
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &options); err != nil {
+			t.Errorf("Unexpected body [%s]: %v", body, err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+	resource := Resource{ResourceType: services, Namespace: "linkerd", Name: "web"}
+	if err := api.DeleteResource(context.Background(), resource); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if path != "/api/v1/namespaces/linkerd/services/web" {
+		t.Fatalf("Unexpected path [%s]", path)
+	}
+	if options.PropagationPolicy == nil || *options.PropagationPolicy != metav1.DeletePropagationBackground {
+		t.Fatalf("Expected background propagation, got %+v", options)
+	}
+	if resource.String() != "Service/web in namespace linkerd" {
+		t.Fatalf("Unexpected description [%s]", resource)
+	}
+}
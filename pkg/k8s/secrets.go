@@ -0,0 +1,97 @@
+package k8s
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"k8s.io/api/core/v1"
+)
+
+// TLSSecret is a certificate and private key read from a kubernetes.io/tls
+// secret.
+type TLSSecret struct {
+	// Certificate is the first certificate in tls.crt, and Chain holds the
+	// rest of them, if any.
+	Certificate *x509.Certificate
+	Chain       []*x509.Certificate
+	PrivateKey  crypto.PrivateKey
+}
+
+// CheckValidity returns an error if the certificate is not valid at now,
+// because it has expired or is not valid yet.
+func (s *TLSSecret) CheckValidity(now time.Time) error {
+	if now.After(s.Certificate.NotAfter) {
+		return fmt.Errorf("certificate [%s] expired on %s", s.Certificate.Subject.CommonName, s.Certificate.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(s.Certificate.NotBefore) {
+		return fmt.Errorf("certificate [%s] is not valid before %s", s.Certificate.Subject.CommonName, s.Certificate.NotBefore.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// GetSecret returns the secret with the given name in namespace. If it does
+// not exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetSecret(ctx context.Context, namespace, name string) (*v1.Secret, error) {
+	var secret v1.Secret
+	if err := kubeAPI.getJSON(ctx, "/api/v1/namespaces/"+namespace+"/secrets/"+name, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// GetTLSSecret is like GetSecret, but parses the certificate and private key
+// stored in the secret's tls.crt and tls.key data.
+func (kubeAPI *KubernetesAPI) GetTLSSecret(ctx context.Context, namespace, name string) (*TLSSecret, error) {
+	secret, err := kubeAPI.GetSecret(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTLSSecret(secret)
+}
+
+// ParseTLSSecret parses the certificate and private key stored in the tls.crt
+// and tls.key data of secret. It returns an error if either key is missing,
+// if they do not hold valid PEM, or if they do not form a key pair.
+func ParseTLSSecret(secret *v1.Secret) (*TLSSecret, error) {
+	id := secret.Namespace + "/" + secret.Name
+
+	certPEM, ok := secret.Data[v1.TLSCertKey]
+	if !ok {
+		return nil, fmt.Errorf("secret [%s] has no [%s] key", id, v1.TLSCertKey)
+	}
+	keyPEM, ok := secret.Data[v1.TLSPrivateKeyKey]
+	if !ok {
+		return nil, fmt.Errorf("secret [%s] has no [%s] key", id, v1.TLSPrivateKeyKey)
+	}
+
+	if block, _ := pem.Decode(certPEM); block == nil {
+		return nil, fmt.Errorf("secret [%s] key [%s] does not contain PEM data", id, v1.TLSCertKey)
+	}
+	if block, _ := pem.Decode(keyPEM); block == nil {
+		return nil, fmt.Errorf("secret [%s] key [%s] does not contain PEM data", id, v1.TLSPrivateKeyKey)
+	}
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("secret [%s] does not contain a valid certificate and key pair: %s", id, err)
+	}
+
+	certs := make([]*x509.Certificate, len(pair.Certificate))
+	for i, der := range pair.Certificate {
+		certs[i], err = x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("secret [%s] key [%s] contains an invalid certificate: %s", id, v1.TLSCertKey, err)
+		}
+	}
+
+	return &TLSSecret{
+		Certificate: certs[0],
+		Chain:       certs[1:],
+		PrivateKey:  pair.PrivateKey,
+	}, nil
+}
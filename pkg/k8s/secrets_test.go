@@ -0,0 +1,138 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+func readSecretFixture(t *testing.T, path string) *v1.Secret {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error reading fixture: %v", err)
+	}
+
+	var secret v1.Secret
+	if err := json.Unmarshal(body, &secret); err != nil {
+		t.Fatalf("Unexpected error decoding fixture: %v", err)
+	}
+	return &secret
+}
+
+func TestGetTLSSecret(t *testing.T) {
+	fixtures := map[string]string{
+		"webhook-valid":   "testdata/secret_tls_valid.json",
+		"webhook-expired": "testdata/secret_tls_expired.json",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/linkerd/secrets/webhook-valid", "/api/v1/namespaces/linkerd/secrets/webhook-expired":
+			name := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/linkerd/secrets/")
+			body, err := ioutil.ReadFile(fixtures[name])
+			if err != nil {
+				t.Fatalf("Unexpected error reading fixture: %v", err)
+			}
+			w.Write(body)
+		case "/api/v1/namespaces/linkerd/secrets/unreadable":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+	now := time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Parses a valid certificate", func(t *testing.T) {
+		secret, err := api.GetTLSSecret(context.Background(), "linkerd", "webhook-valid")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cn := secret.Certificate.Subject.CommonName; cn != "linkerd-proxy-injector.linkerd.svc" {
+			t.Fatalf("Unexpected common name [%s]", cn)
+		}
+		if secret.PrivateKey == nil {
+			t.Fatalf("Expected a private key")
+		}
+		if err := secret.CheckValidity(now); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Reports expired certificates", func(t *testing.T) {
+		secret, err := api.GetTLSSecret(context.Background(), "linkerd", "webhook-expired")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		err = secret.CheckValidity(now)
+		expected := "certificate [linkerd-proxy-injector.linkerd.svc] expired on 2018-09-01T00:00:00Z"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Distinguishes missing and unreadable secrets", func(t *testing.T) {
+		if _, err := api.GetTLSSecret(context.Background(), "linkerd", "missing"); !IsNotFound(err) {
+			t.Fatalf("Expected a NotFound error, got [%v]", err)
+		}
+		if _, err := api.GetTLSSecret(context.Background(), "linkerd", "unreadable"); !IsForbidden(err) {
+			t.Fatalf("Expected a Forbidden error, got [%v]", err)
+		}
+	})
+}
+
+func TestParseTLSSecret(t *testing.T) {
+	valid := readSecretFixture(t, "testdata/secret_tls_valid.json")
+	expired := readSecretFixture(t, "testdata/secret_tls_expired.json")
+
+	testCases := []struct {
+		desc string
+		data map[string][]byte
+		err  string
+	}{
+		{
+			desc: "missing certificate",
+			data: map[string][]byte{v1.TLSPrivateKeyKey: valid.Data[v1.TLSPrivateKeyKey]},
+			err:  "secret [linkerd/webhook] has no [tls.crt] key",
+		},
+		{
+			desc: "missing key",
+			data: map[string][]byte{v1.TLSCertKey: valid.Data[v1.TLSCertKey]},
+			err:  "secret [linkerd/webhook] has no [tls.key] key",
+		},
+		{
+			desc: "certificate is not PEM",
+			data: map[string][]byte{v1.TLSCertKey: []byte("not a certificate"), v1.TLSPrivateKeyKey: valid.Data[v1.TLSPrivateKeyKey]},
+			err:  "secret [linkerd/webhook] key [tls.crt] does not contain PEM data",
+		},
+		{
+			desc: "mismatched key",
+			data: map[string][]byte{v1.TLSCertKey: valid.Data[v1.TLSCertKey], v1.TLSPrivateKeyKey: expired.Data[v1.TLSPrivateKeyKey]},
+			err:  "secret [linkerd/webhook] does not contain a valid certificate and key pair",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			secret := &v1.Secret{Data: tc.data}
+			secret.Namespace = "linkerd"
+			secret.Name = "webhook"
+
+			_, err := ParseTLSSecret(secret)
+			if err == nil || !strings.HasPrefix(err.Error(), tc.err) {
+				t.Fatalf("Expected error [%s], got [%v]", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/version"
+)
+
+const (
+	// KubernetesAPICategory is the category of the SelfCheck results about
+	// reaching and authenticating to the Kubernetes API.
+	KubernetesAPICategory = "kubernetes-api"
+
+	// KubernetesVersionCategory is the category of the SelfCheck results
+	// about the version of the cluster.
+	KubernetesVersionCategory = "kubernetes-version"
+
+	// KubernetesClockCategory is the category of the SelfCheck result about
+	// the skew between the local clock and the clock of the API server.
+	KubernetesClockCategory = "kubernetes-clock"
+)
+
+// MaxClockSkew is the largest difference between the local clock and the
+// clock of the Kubernetes API server that SelfCheck accepts. Larger skews can
+// cause certificates and tokens to be rejected as not yet, or no longer,
+// valid.
+var MaxClockSkew = time.Minute
+
+// CheckResult is the result of one of the checks run by SelfCheck.
+type CheckResult struct {
+	Category    string
+	Description string
+
+	// HintAnchor identifies the section of the troubleshooting docs that
+	// covers failures of this check, e.g. "k8s-api".
+	HintAnchor string
+
+	// Warning is set for checks whose failure does not prevent using the
+	// cluster.
+	Warning bool
+
+	// Retryable is set for checks that can fail transiently, such as when the
+	// API server is restarting.
+	Retryable bool
+
+	// Err is nil if the check succeeded.
+	Err error
+}
+
+// SelfCheck checks that the Kubernetes API can be reached, that its
+// credentials are accepted, that the clock of the API server agrees with the
+// local one, and that the cluster is on MinAPIVersion or a more recent
+// version. If the API cannot be reached, the other checks are skipped, and
+// only the first result is returned. A successful check populates the cache of
+// GetVersionInfo.
+func (kubeAPI *KubernetesAPI) SelfCheck(ctx context.Context) []CheckResult {
+	connectivity := CheckResult{
+		Category:    KubernetesAPICategory,
+		Description: "can query the Kubernetes API",
+		HintAnchor:  "k8s-api",
+	}
+
+	client, err := kubeAPI.Client()
+	if err != nil {
+		connectivity.Err = err
+		return []CheckResult{connectivity}
+	}
+
+	sent := time.Now()
+	versionInfo, serverTime, err := kubeAPI.getVersionInfo(ctx, client)
+	received := time.Now()
+	if err != nil {
+		// Connection errors and server errors can be transient, but other
+		// responses, e.g. a 401, are not.
+		apiErr, ok := err.(*APIError)
+		connectivity.Retryable = !ok || apiErr.StatusCode >= 500
+		connectivity.Err = err
+		return []CheckResult{connectivity}
+	}
+
+	kubeAPI.versionMu.Lock()
+	kubeAPI.versionInfo = versionInfo
+	kubeAPI.versionMu.Unlock()
+
+	return []CheckResult{
+		connectivity,
+		{
+			Category:    KubernetesAPICategory,
+			Description: "can authenticate to the Kubernetes API",
+			HintAnchor:  "k8s-api-auth",
+			Err:         kubeAPI.checkAuthentication(ctx),
+		},
+		{
+			Category:    KubernetesClockCategory,
+			Description: "clock is in sync with the Kubernetes API",
+			HintAnchor:  "k8s-api-clock-skew",
+			Warning:     true,
+			Err:         checkClockSkew(serverTime, sent, received),
+		},
+		{
+			Category:    KubernetesVersionCategory,
+			Description: "is running the minimum Kubernetes API version",
+			HintAnchor:  "k8s-version",
+			Err:         checkMinVersion(versionInfo),
+		},
+	}
+}
+
+// checkAuthentication lists namespaces, which, unlike /version, requires the
+// request to be authenticated. Being forbidden from listing them still means
+// the credentials were accepted, unless the request was treated as anonymous.
+func (kubeAPI *KubernetesAPI) checkAuthentication(ctx context.Context) error {
+	err := kubeAPI.listJSON(ctx, "/api/v1/namespaces", ListOptions{Limit: 1}, &struct{}{})
+	if apiErr, ok := err.(*APIError); ok {
+		switch {
+		case apiErr.StatusCode == 401:
+			return fmt.Errorf("the Kubernetes API rejected the credentials in the kubeconfig: %s", apiErr)
+		case IsForbidden(apiErr) && strings.Contains(apiErr.Message, "system:anonymous"):
+			return fmt.Errorf("the Kubernetes API treated the request as anonymous, check the credentials in the kubeconfig: %s", apiErr)
+		case IsForbidden(apiErr):
+			return nil
+		}
+	}
+	return err
+}
+
+// checkClockSkew compares serverTime, from the Date header of a response, to
+// the local times the request was sent and its response received. The Date
+// header only has a resolution of one second.
+func checkClockSkew(serverTime, sent, received time.Time) error {
+	if serverTime.IsZero() {
+		return fmt.Errorf("the Kubernetes API did not return a valid Date header")
+	}
+
+	var skew time.Duration
+	switch {
+	case serverTime.Before(sent.Add(-time.Second)):
+		skew = serverTime.Sub(sent)
+	case serverTime.After(received):
+		skew = serverTime.Sub(received)
+	}
+
+	switch {
+	case skew > MaxClockSkew:
+		return fmt.Errorf("the local clock is %s behind the Kubernetes API server, more than the %s allowed",
+			skew.Round(time.Second), MaxClockSkew)
+	case skew < -MaxClockSkew:
+		return fmt.Errorf("the local clock is %s ahead of the Kubernetes API server, more than the %s allowed",
+			(-skew).Round(time.Second), MaxClockSkew)
+	}
+	return nil
+}
+
+func checkMinVersion(versionInfo *version.Info) error {
+	result, err := CheckVersion(versionInfo, MinAPIVersion, MaxTestedAPIVersion)
+	if err != nil {
+		return err
+	}
+	return result.Err()
+}
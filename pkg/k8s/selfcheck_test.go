@@ -0,0 +1,188 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// selfCheckServer is a fake API server reporting gitVersion, answering
+// requests to list namespaces with namespacesStatus and namespacesBody, and
+// setting the Date header to the local time shifted by skew.
+func selfCheckServer(gitVersion string, namespacesStatus int, namespacesBody string, skew time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(skew).UTC().Format(http.TimeFormat))
+		switch r.URL.Path {
+		case "/version":
+			w.Write([]byte(`{"gitVersion":"` + gitVersion + `"}`))
+		case "/api/v1/namespaces":
+			w.WriteHeader(namespacesStatus)
+			w.Write([]byte(namespacesBody))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestSelfCheck(t *testing.T) {
+	descriptions := []string{
+		"can query the Kubernetes API",
+		"can authenticate to the Kubernetes API",
+		"clock is in sync with the Kubernetes API",
+		"is running the minimum Kubernetes API version",
+	}
+
+	testCases := []struct {
+		name             string
+		gitVersion       string
+		namespacesStatus int
+		namespacesBody   string
+		skew             time.Duration
+		failed           string
+		expectedErr      string
+	}{
+		{
+			name:             "Passes against a healthy cluster",
+			gitVersion:       "v1.10.0",
+			namespacesStatus: http.StatusOK,
+			namespacesBody:   `{"items":[]}`,
+		},
+		{
+			name:             "Passes when the user is authenticated but forbidden from listing namespaces",
+			gitVersion:       "v1.10.0",
+			namespacesStatus: http.StatusForbidden,
+			namespacesBody:   `{"kind":"Status","reason":"Forbidden","message":"namespaces is forbidden: User \"jane\" cannot list namespaces at the cluster scope"}`,
+		},
+		{
+			name:             "Fails when the credentials are rejected",
+			gitVersion:       "v1.10.0",
+			namespacesStatus: http.StatusUnauthorized,
+			namespacesBody:   `{"kind":"Status","reason":"Unauthorized","message":"Unauthorized"}`,
+			failed:           "can authenticate to the Kubernetes API",
+			expectedErr:      "the Kubernetes API rejected the credentials in the kubeconfig",
+		},
+		{
+			name:             "Fails when the request is anonymous",
+			gitVersion:       "v1.10.0",
+			namespacesStatus: http.StatusForbidden,
+			namespacesBody:   `{"kind":"Status","reason":"Forbidden","message":"namespaces is forbidden: User \"system:anonymous\" cannot list namespaces at the cluster scope"}`,
+			failed:           "can authenticate to the Kubernetes API",
+			expectedErr:      "the Kubernetes API treated the request as anonymous",
+		},
+		{
+			name:             "Fails when the local clock is behind",
+			gitVersion:       "v1.10.0",
+			namespacesStatus: http.StatusOK,
+			namespacesBody:   `{"items":[]}`,
+			skew:             10 * time.Minute,
+			failed:           "clock is in sync with the Kubernetes API",
+			expectedErr:      "behind the Kubernetes API server, more than the 1m0s allowed",
+		},
+		{
+			name:             "Fails when the local clock is ahead",
+			gitVersion:       "v1.10.0",
+			namespacesStatus: http.StatusOK,
+			namespacesBody:   `{"items":[]}`,
+			skew:             -2 * time.Hour,
+			failed:           "clock is in sync with the Kubernetes API",
+			expectedErr:      "ahead of the Kubernetes API server, more than the 1m0s allowed",
+		},
+		{
+			name:             "Fails when the cluster is too old",
+			gitVersion:       "v1.7.0",
+			namespacesStatus: http.StatusOK,
+			namespacesBody:   `{"items":[]}`,
+			failed:           "is running the minimum Kubernetes API version",
+			expectedErr:      "Kubernetes is on version [1.7.0], but version [1.8.0] or more recent is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := selfCheckServer(tc.gitVersion, tc.namespacesStatus, tc.namespacesBody, tc.skew)
+			defer server.Close()
+			api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+			results := api.SelfCheck(context.Background())
+			if len(results) != len(descriptions) {
+				t.Fatalf("Expected %d results, got %d: %+v", len(descriptions), len(results), results)
+			}
+
+			for i, result := range results {
+				if result.Description != descriptions[i] {
+					t.Fatalf("Expected result %d to be [%s], got [%s]", i, descriptions[i], result.Description)
+				}
+				if result.HintAnchor == "" {
+					t.Fatalf("Expected result [%s] to have a hint anchor", result.Description)
+				}
+
+				if result.Description != tc.failed {
+					if result.Err != nil {
+						t.Fatalf("Expected [%s] to pass, got [%s]", result.Description, result.Err)
+					}
+					continue
+				}
+				if result.Err == nil || !strings.Contains(result.Err.Error(), tc.expectedErr) {
+					t.Fatalf("Expected [%s] to fail with [%s], got [%v]", result.Description, tc.expectedErr, result.Err)
+				}
+			}
+
+			if results[0].Category != KubernetesAPICategory || results[2].Category != KubernetesClockCategory || results[3].Category != KubernetesVersionCategory {
+				t.Fatalf("Unexpected categories: %+v", results)
+			}
+			if !results[2].Warning {
+				t.Fatalf("Expected clock skew to be reported as a warning")
+			}
+		})
+	}
+
+	t.Run("Only reports connectivity when the API is unreachable", func(t *testing.T) {
+		server := selfCheckServer("v1.10.0", http.StatusOK, `{"items":[]}`, 0)
+		server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		results := api.SelfCheck(context.Background())
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result, got %d: %+v", len(results), results)
+		}
+		if results[0].Description != descriptions[0] || results[0].Err == nil {
+			t.Fatalf("Expected [%s] to fail, got [%+v]", descriptions[0], results[0])
+		}
+	})
+
+	t.Run("Populates the version cache", func(t *testing.T) {
+		server := selfCheckServer("v1.10.0", http.StatusOK, `{"items":[]}`, 0)
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+		api.SelfCheck(context.Background())
+		server.Close()
+
+		versionInfo, err := api.GetVersionInfo(context.Background())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if versionInfo.GitVersion != "v1.10.0" {
+			t.Fatalf("Expected cached version [v1.10.0], got [%s]", versionInfo.GitVersion)
+		}
+	})
+}
+
+func TestCheckClockSkew(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Accepts a Date header truncated to the second", func(t *testing.T) {
+		if err := checkClockSkew(now.Truncate(time.Second), now, now.Add(time.Millisecond)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Rejects a missing Date header", func(t *testing.T) {
+		if err := checkClockSkew(time.Time{}, now, now); err == nil {
+			t.Fatalf("Expected an error, got nothing")
+		}
+	})
+}
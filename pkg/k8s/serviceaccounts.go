@@ -0,0 +1,62 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/api/core/v1"
+)
+
+// GetServiceAccount returns the service account with the given name in
+// namespace. If it does not exist, the returned error satisfies IsNotFound.
+func (kubeAPI *KubernetesAPI) GetServiceAccount(ctx context.Context, namespace, name string) (*v1.ServiceAccount, error) {
+	var sa v1.ServiceAccount
+	if err := kubeAPI.getJSON(ctx, "/api/v1/namespaces/"+namespace+"/serviceaccounts/"+name, &sa); err != nil {
+		return nil, err
+	}
+	return &sa, nil
+}
+
+// ServiceAccountExists validates whether a given service account exists. If
+// the caller is not authorized to get the service account, the returned error
+// satisfies IsForbidden.
+func (kubeAPI *KubernetesAPI) ServiceAccountExists(ctx context.Context, namespace, name string) (bool, error) {
+	_, err := kubeAPI.GetServiceAccount(ctx, namespace, name)
+	return existsFromError(err)
+}
+
+// ServiceAccountExistsWithToken is like ServiceAccountExists, but additionally
+// verifies that pods using the service account will have a token mounted: the
+// service account must not disable token automounting, and must reference a
+// token secret that exists. If the service account exists but one of those
+// conditions does not hold, the returned error explains why.
+func (kubeAPI *KubernetesAPI) ServiceAccountExistsWithToken(ctx context.Context, namespace, name string) (bool, error) {
+	sa, err := kubeAPI.GetServiceAccount(ctx, namespace, name)
+	if exists, err := existsFromError(err); !exists || err != nil {
+		return exists, err
+	}
+
+	if sa.AutomountServiceAccountToken != nil && !*sa.AutomountServiceAccountToken {
+		return false, fmt.Errorf("service account [%s/%s] has automountServiceAccountToken disabled", namespace, name)
+	}
+
+	if len(sa.Secrets) == 0 {
+		return false, fmt.Errorf("service account [%s/%s] does not reference a token secret; is the token controller running?", namespace, name)
+	}
+
+	for _, ref := range sa.Secrets {
+		var secret v1.Secret
+		err := kubeAPI.getJSON(ctx, "/api/v1/namespaces/"+namespace+"/secrets/"+ref.Name, &secret)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if secret.Type == v1.SecretTypeServiceAccountToken {
+			return true, nil
+		}
+	}
+
+	return false, fmt.Errorf("service account [%s/%s] references no existing token secret", namespace, name)
+}
@@ -0,0 +1,102 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestServiceAccountExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/linkerd/serviceaccounts/linkerd-controller":
+			w.Write([]byte(`{"kind":"ServiceAccount","apiVersion":"v1","metadata":{"name":"linkerd-controller","namespace":"linkerd"},"secrets":[{"name":"linkerd-controller-token-x7k2p"}]}`))
+		case "/api/v1/namespaces/kube-system/serviceaccounts/default":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	testCases := []struct {
+		namespace string
+		name      string
+		exists    bool
+		forbidden bool
+	}{
+		{namespace: "linkerd", name: "linkerd-controller", exists: true},
+		{namespace: "linkerd", name: "linkerd-prometheus", exists: false},
+		{namespace: "kube-system", name: "default", exists: false, forbidden: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.namespace+"/"+tc.name, func(t *testing.T) {
+			exists, err := api.ServiceAccountExists(context.Background(), tc.namespace, tc.name)
+			if exists != tc.exists {
+				t.Fatalf("Expected ServiceAccountExists to return [%t], got [%t]", tc.exists, exists)
+			}
+			if IsForbidden(err) != tc.forbidden {
+				t.Fatalf("Expected IsForbidden to return [%t] for [%v]", tc.forbidden, err)
+			}
+			if !tc.forbidden && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestServiceAccountExistsWithToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/linkerd/serviceaccounts/with-token":
+			w.Write([]byte(`{"metadata":{"name":"with-token"},"secrets":[{"name":"with-token-token-abcde"}]}`))
+		case "/api/v1/namespaces/linkerd/secrets/with-token-token-abcde":
+			w.Write([]byte(`{"metadata":{"name":"with-token-token-abcde"},"type":"kubernetes.io/service-account-token"}`))
+		case "/api/v1/namespaces/linkerd/serviceaccounts/automount-disabled":
+			w.Write([]byte(`{"metadata":{"name":"automount-disabled"},"automountServiceAccountToken":false,"secrets":[{"name":"with-token-token-abcde"}]}`))
+		case "/api/v1/namespaces/linkerd/serviceaccounts/no-secrets":
+			w.Write([]byte(`{"metadata":{"name":"no-secrets"}}`))
+		case "/api/v1/namespaces/linkerd/serviceaccounts/deleted-secret":
+			w.Write([]byte(`{"metadata":{"name":"deleted-secret"},"secrets":[{"name":"deleted-secret-token-fghij"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	testCases := []struct {
+		name   string
+		exists bool
+		err    string
+	}{
+		{name: "with-token", exists: true},
+		{name: "missing", exists: false},
+		{name: "automount-disabled", err: "automountServiceAccountToken disabled"},
+		{name: "no-secrets", err: "does not reference a token secret"},
+		{name: "deleted-secret", err: "references no existing token secret"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exists, err := api.ServiceAccountExistsWithToken(context.Background(), "linkerd", tc.name)
+			if exists != tc.exists {
+				t.Fatalf("Expected ServiceAccountExistsWithToken to return [%t], got [%t]", tc.exists, exists)
+			}
+			if tc.err == "" && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Fatalf("Expected error to contain [%s], got [%v]", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package k8s
+
+import (
+	"fmt"
+)
+
+// ServiceProfileName returns the name of the ServiceProfile of a service,
+// which is the fully-qualified name of the service.
+func ServiceProfileName(service, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc.cluster.local", service, namespace)
+}
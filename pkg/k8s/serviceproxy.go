@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// portNameFormat matches IANA service names, which is the format Kubernetes
+// requires of port names.
+var portNameFormat = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// serviceProxyPath returns the path of the API server's proxy to port of the
+// given service, e.g. /api/v1/namespaces/linkerd/services/http:api:http/proxy/
+// for an empty path. The returned path is percent-encoded. port is either a
+// port name or number, and may be empty for services with a single port.
+// scheme is "http" or "https", and may be empty to mean "http".
+func serviceProxyPath(namespace, service, port, scheme, path string) (string, error) {
+	if err := validatePort(port); err != nil {
+		return "", err
+	}
+	if scheme != "" && scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("invalid scheme [%s], must be one of: http, https", scheme)
+	}
+
+	target := service
+	if port != "" {
+		target += ":" + port
+	}
+	if scheme != "" {
+		target = scheme + ":" + target
+	}
+
+	return "/api/v1/namespaces/" + namespace + "/services/" + target + "/proxy/" + escapePath(strings.TrimPrefix(path, "/")), nil
+}
+
+func validatePort(port string) error {
+	if port == "" {
+		return nil
+	}
+	if number, err := strconv.Atoi(port); err == nil {
+		if number < 1 || number > 65535 {
+			return fmt.Errorf("invalid port number [%s], must be between 1 and 65535", port)
+		}
+		return nil
+	}
+	if len(port) > 15 || !portNameFormat.MatchString(port) || !strings.ContainsAny(port, "abcdefghijklmnopqrstuvwxyz") {
+		return fmt.Errorf("invalid port [%s], must be a port number or an IANA service name", port)
+	}
+	return nil
+}
+
+// escapePath percent-encodes each of the segments of path.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// generateProxyUrl returns the URL of the percent-encoded path on
+// schemeHostAndPort.
+func generateProxyUrl(schemeHostAndPort, escapedPath string) (*url.URL, error) {
+	url, err := joinURL(schemeHostAndPort, escapedPath)
+	if err != nil {
+		return nil, fmt.Errorf("error generating proxy URL for Kubernetes API from [%s%s]", schemeHostAndPort, escapedPath)
+	}
+	return url, nil
+}
+
+// ServiceProxyUrlFor returns the URL of path on port of the given service,
+// through the API server's service proxy. port is either a port name or number,
+// and may be empty for services with a single port. scheme is "http", "https"
+// or empty, meaning "http". path is percent-encoded.
+func (kubeAPI *KubernetesAPI) ServiceProxyUrlFor(namespace, service, port, scheme, path string) (*url.URL, error) {
+	proxyPath, err := serviceProxyPath(namespace, service, port, scheme, path)
+	if err != nil {
+		return nil, err
+	}
+	return generateProxyUrl(kubeAPI.Host, proxyPath)
+}
+
+// GetServiceProxyResponse GETs path on port of the given service through the
+// API server's service proxy, as described by ServiceProxyUrlFor, and returns
+// the response body. The request is bound to ctx, and is additionally subject
+// to the configured Timeout.
+func (kubeAPI *KubernetesAPI) GetServiceProxyResponse(ctx context.Context, namespace, service, port, scheme, path string) ([]byte, error) {
+	proxyPath, err := serviceProxyPath(namespace, service, port, scheme, path)
+	if err != nil {
+		return nil, err
+	}
+	return kubeAPI.getProxyResponse(ctx, proxyPath)
+}
+
+// getProxyResponse GETs the percent-encoded proxyPath and returns the response
+// body.
+func (kubeAPI *KubernetesAPI) getProxyResponse(ctx context.Context, proxyPath string) ([]byte, error) {
+	client, err := kubeAPI.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, kubeAPI.requestTimeout())
+	defer cancel()
+
+	rsp, err := kubeAPI.doRequest(ctx, client, "GET", proxyPath, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, kubeAPI.responseError(rsp)
+	}
+
+	return ioutil.ReadAll(rsp.Body)
+}
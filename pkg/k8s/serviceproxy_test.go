@@ -0,0 +1,102 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestServiceProxyUrlFor(t *testing.T) {
+	api := &KubernetesAPI{Config: &rest.Config{Host: "https://55.197.171.239"}}
+
+	testCases := []struct {
+		service  string
+		port     string
+		scheme   string
+		path     string
+		expected string
+	}{
+		{
+			service:  "api",
+			port:     "http",
+			scheme:   "http",
+			expected: "https://55.197.171.239/api/v1/namespaces/linkerd/services/http:api:http/proxy/",
+		},
+		{
+			service:  "web",
+			port:     "http",
+			expected: "https://55.197.171.239/api/v1/namespaces/linkerd/services/web:http/proxy/",
+		},
+		{
+			service:  "prometheus",
+			port:     "9090",
+			path:     "/-/ready",
+			expected: "https://55.197.171.239/api/v1/namespaces/linkerd/services/prometheus:9090/proxy/-/ready",
+		},
+		{
+			service:  "grafana",
+			scheme:   "https",
+			path:     "d/linkerd-deployment/deployment?var=x",
+			expected: "https://55.197.171.239/api/v1/namespaces/linkerd/services/https:grafana/proxy/d/linkerd-deployment/deployment%3Fvar=x",
+		},
+		{
+			service:  "web",
+			port:     "http",
+			path:     "namespaces/emojivoto/deployments/web svc",
+			expected: "https://55.197.171.239/api/v1/namespaces/linkerd/services/web:http/proxy/namespaces/emojivoto/deployments/web%20svc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			url, err := api.ServiceProxyUrlFor("linkerd", tc.service, tc.port, tc.scheme, tc.path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if url.String() != tc.expected {
+				t.Fatalf("Expected generated URL to be [%s], but got [%s]", tc.expected, url.String())
+			}
+		})
+	}
+
+	t.Run("Rejects invalid ports and schemes", func(t *testing.T) {
+		for _, port := range []string{"0", "65536", "HTTP", "-http", "a-very-long-port-name", "80a!"} {
+			if _, err := api.ServiceProxyUrlFor("linkerd", "web", port, "", ""); err == nil {
+				t.Fatalf("Expected error for port [%s], got nothing", port)
+			}
+		}
+		if _, err := api.ServiceProxyUrlFor("linkerd", "web", "http", "ftp", ""); err == nil {
+			t.Fatalf("Expected error for scheme [ftp], got nothing")
+		}
+	})
+}
+
+func TestGetServiceProxyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/namespaces/linkerd/services/prometheus:admin-http/proxy/-/ready":
+			w.Write([]byte("Prometheus is Ready.\n"))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+	body, err := api.GetServiceProxyResponse(context.Background(), "linkerd", "prometheus", "admin-http", "", "/-/ready")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(body) != "Prometheus is Ready.\n" {
+		t.Fatalf("Unexpected body [%s]", body)
+	}
+
+	_, err = api.GetServiceProxyResponse(context.Background(), "linkerd", "grafana", "http", "", "/api/health")
+	if err == nil || err.Error() != "Unexpected Kubernetes API response: 503 Service Unavailable" {
+		t.Fatalf("Expected a 503 error, got [%v]", err)
+	}
+}
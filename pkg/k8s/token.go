@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+// tokenFileTransport authenticates requests with a bearer token read from a
+// file. The file is read again whenever its modification time or size
+// changes, so that rotated tokens are picked up without restarting the
+// process.
+type tokenFileTransport struct {
+	path string
+	rt   http.RoundTripper
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	token   string
+}
+
+func newTokenFileTransport(path string, rt http.RoundTripper) *tokenFileTransport {
+	return &tokenFileTransport{path: path, rt: rt}
+}
+
+func (t *tokenFileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return t.rt.RoundTrip(req)
+	}
+
+	token, err := t.currentToken()
+	if err != nil {
+		return nil, err
+	}
+
+	req = utilnet.CloneRequest(req)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.rt.RoundTrip(req)
+}
+
+func (t *tokenFileTransport) currentToken() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, err := os.Stat(t.path)
+	if err != nil {
+		return "", fmt.Errorf("error reading token file [%s]: %v", t.path, err)
+	}
+
+	if t.token != "" && info.ModTime().Equal(t.modTime) && info.Size() == t.size {
+		return t.token, nil
+	}
+
+	token, err := readTokenFile(t.path)
+	if err != nil {
+		return "", err
+	}
+
+	t.modTime = info.ModTime()
+	t.size = info.Size()
+	t.token = token
+	return token, nil
+}
+
+func readTokenFile(path string) (string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading token file [%s]: %v", path, err)
+	}
+
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", fmt.Errorf("token file [%s] is empty", path)
+	}
+
+	return token, nil
+}
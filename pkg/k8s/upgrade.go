@@ -0,0 +1,49 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// UpgradeRoundTripper returns a round tripper that can upgrade connections to
+// SPDY, as required by streaming endpoints like pod exec and attach, along
+// with the upgrader that turns upgraded responses into stream connections.
+// Authentication, impersonation and TLS are configured from the rest.Config,
+// as for NewClient. Unlike NewClient's, this round tripper always reads the
+// proxy environment variables through http.ProxyFromEnvironment.
+func (kubeAPI *KubernetesAPI) UpgradeRoundTripper() (http.RoundTripper, spdy.Upgrader, error) {
+	rt, upgrader, err := spdy.RoundTripperFor(kubeAPI.Config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error instantiating Kubernetes API upgrade round tripper: %v", err)
+	}
+	return rt, upgrader, nil
+}
+
+// DialStream sends a request with the given method to u, e.g. one returned by
+// UrlFor(namespace, "/pods/web-1/exec"), and upgrades the connection to SPDY,
+// offering protocols in order of preference. It returns the stream connection
+// and the protocol chosen by the API server, which is empty if the server did
+// not negotiate one. The caller must close the connection. ctx only bounds the
+// upgrade itself, not the lifetime of the connection.
+func (kubeAPI *KubernetesAPI) DialStream(ctx context.Context, method string, u *url.URL, protocols ...string) (httpstream.Connection, string, error) {
+	rt, upgrader, err := kubeAPI.UpgradeRoundTripper()
+	if err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	conn, protocol, err := spdy.Negotiate(upgrader, &http.Client{Transport: rt}, req.WithContext(ctx), protocols...)
+	if err != nil {
+		return nil, "", fmt.Errorf("error upgrading connection to [%s]: %v", u, err)
+	}
+	return conn, protocol, nil
+}
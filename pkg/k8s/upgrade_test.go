@@ -0,0 +1,90 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	"k8s.io/client-go/rest"
+)
+
+func TestDialStream(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		if r.URL.Path != "/api/v1/namespaces/emojivoto/pods/web-1/exec" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer some-token" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		if _, err := httpstream.Handshake(r, w, []string{"v4.channel.k8s.io", "v3.channel.k8s.io"}); err != nil {
+			return
+		}
+		conn := spdy.NewResponseUpgrader().UpgradeResponse(w, r, func(httpstream.Stream, <-chan struct{}) error { return nil })
+		if conn == nil {
+			return
+		}
+		<-conn.CloseChan()
+	}))
+	defer server.Close()
+
+	config := &rest.Config{Host: server.URL, BearerToken: "some-token", UserAgent: "linkerd2-cli/test"}
+	api := &KubernetesAPI{Config: config}
+
+	t.Run("Sends the upgrade headers and negotiates a protocol", func(t *testing.T) {
+		u, err := api.UrlFor("emojivoto", "/pods/web-1/exec")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		conn, protocol, err := api.DialStream(ctx, "POST", u, "v4.channel.k8s.io", "v1.channel.k8s.io")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer conn.Close()
+
+		if protocol != "v4.channel.k8s.io" {
+			t.Fatalf("Expected protocol [v4.channel.k8s.io], got [%s]", protocol)
+		}
+
+		expected := map[string]string{
+			"Connection":    "Upgrade",
+			"Upgrade":       "SPDY/3.1",
+			"Authorization": "Bearer some-token",
+			"User-Agent":    "linkerd2-cli/test",
+		}
+		for name, value := range expected {
+			if actual := headers.Get(name); actual != value {
+				t.Fatalf("Expected header [%s] to be [%s], got [%s]", name, value, actual)
+			}
+		}
+		if protocols := headers[httpstream.HeaderProtocolVersion]; strings.Join(protocols, ",") != "v4.channel.k8s.io,v1.channel.k8s.io" {
+			t.Fatalf("Expected protocols to be offered in order, got %v", protocols)
+		}
+	})
+
+	t.Run("Returns an error when the server does not upgrade", func(t *testing.T) {
+		u, err := url.Parse(server.URL + "/api/v1/namespaces/emojivoto/pods/web-2/exec")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		_, _, err = api.DialStream(context.Background(), "POST", u, "v4.channel.k8s.io")
+		if err == nil || !strings.HasPrefix(err.Error(), "error upgrading connection to ["+u.String()+"]") {
+			t.Fatalf("Expected an upgrade error, got [%v]", err)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package k8s
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/linkerd/linkerd2/pkg/version"
+)
+
+// CLIUserAgent returns the User-Agent sent by the linkerd CLI to the
+// Kubernetes API, e.g. "linkerd/cli stable-2.0.0 (linux/amd64)".
+func CLIUserAgent() string {
+	return fmt.Sprintf("linkerd/cli %s (%s/%s)", version.Version, runtime.GOOS, runtime.GOARCH)
+}
+
+// ControllerUserAgent returns the User-Agent sent by a control plane
+// component to the Kubernetes API, e.g. "linkerd/controller destination
+// stable-2.0.0".
+func ControllerUserAgent(component string) string {
+	return fmt.Sprintf("linkerd/controller %s %s", component, version.Version)
+}
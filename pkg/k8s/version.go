@@ -5,29 +5,138 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"k8s.io/apimachinery/pkg/version"
 )
 
-var revisionSeparator = regexp.MustCompile("[^0-9.]")
+// k8sVersionFormat matches a semantic version with an optional "v" prefix,
+// pre-release (e.g. "-rc.1", "-gke.6") and build metadata (e.g. "+d4cacc0").
+var k8sVersionFormat = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)((?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)$`)
 
-func getK8sVersion(versionString string) ([3]int, error) {
+// getK8sVersion parses a Kubernetes version string such as "v1.13.0-rc.1" into
+// its major, minor and patch numbers, and the raw pre-release and build
+// metadata suffix, e.g. "-rc.1".
+func getK8sVersion(versionString string) ([3]int, string, error) {
 	var version [3]int
-	justTheVersionString := strings.TrimPrefix(versionString, "v")
-	justTheMajorMinorRevisionNumbers := revisionSeparator.Split(justTheVersionString, -1)[0]
-	split := strings.Split(justTheMajorMinorRevisionNumbers, ".")
-
-	if len(split) < 3 {
-		return version, fmt.Errorf("unknown version string format [%s]", versionString)
+	match := k8sVersionFormat.FindStringSubmatch(strings.TrimSpace(versionString))
+	if match == nil {
+		return version, "", fmt.Errorf("unknown version string format [%s]", versionString)
 	}
 
-	for i, segment := range split {
-		v, err := strconv.Atoi(strings.TrimSpace(segment))
+	for i, segment := range match[1:4] {
+		v, err := strconv.Atoi(segment)
 		if err != nil {
-			return version, fmt.Errorf("unknown version string format [%s]", versionString)
+			return version, "", fmt.Errorf("unknown version string format [%s]", versionString)
 		}
 		version[i] = v
 	}
 
-	return version, nil
+	return version, match[4], nil
+}
+
+// isPreRelease reports whether a version suffix returned by getK8sVersion
+// denotes a pre-release, which semver orders before the release itself.
+func isPreRelease(suffix string) bool {
+	return strings.HasPrefix(suffix, "-")
+}
+
+func formatK8sVersion(version [3]int, suffix string) string {
+	return fmt.Sprintf("%d.%d.%d%s", version[0], version[1], version[2], suffix)
+}
+
+// getK8sVersionFromInfo parses the version of a Kubernetes cluster from its
+// gitVersion, e.g. "v1.10.7-gke.6". Some providers report a gitVersion that
+// can't be parsed, in which case the numeric major and minor fields are used,
+// ignoring the trailing "+" that providers such as GKE and EKS append to the
+// minor version (e.g. "12+").
+func getK8sVersionFromInfo(versionInfo *version.Info) ([3]int, string, error) {
+	v, suffix, err := getK8sVersion(versionInfo.GitVersion)
+	if err == nil {
+		return v, suffix, nil
+	}
+
+	major, majorErr := strconv.Atoi(strings.TrimRight(versionInfo.Major, "+"))
+	minor, minorErr := strconv.Atoi(strings.TrimRight(versionInfo.Minor, "+"))
+	if majorErr != nil || minorErr != nil {
+		return v, "", err
+	}
+
+	return [3]int{major, minor, 0}, "", nil
+}
+
+// VersionCheckResult describes how the version of a Kubernetes cluster
+// compares to a required minimum version and to the most recent tested
+// version.
+type VersionCheckResult struct {
+	// Version and Suffix are the parsed version of the cluster, e.g. [1 13 0]
+	// and "-rc.1".
+	Version [3]int
+	Suffix  string
+
+	// MinVersion is the required minimum version.
+	MinVersion [3]int
+
+	// MaxTestedVersion is the most recent tested minor version.
+	MaxTestedVersion [3]int
+
+	// Compatible reports whether Version satisfies MinVersion.
+	Compatible bool
+
+	// Untested reports whether Version is a more recent minor version than
+	// MaxTestedVersion.
+	Untested bool
+
+	// Warning describes a concern that doesn't make the cluster incompatible,
+	// such as running a pre-release of MinVersion or an untested version. It is
+	// empty if there is none.
+	Warning string
+}
+
+// Err returns an error describing why the cluster is incompatible, or nil if
+// it is compatible.
+func (r *VersionCheckResult) Err() error {
+	if r.Compatible {
+		return nil
+	}
+
+	return fmt.Errorf("Kubernetes is on version [%s], but version [%s] or more recent is required",
+		formatK8sVersion(r.Version, r.Suffix), formatK8sVersion(r.MinVersion, ""))
+}
+
+// CheckVersion compares the version of a Kubernetes cluster, as returned by
+// KubernetesAPI.GetVersionInfo, to min and maxTested. A zero maxTested skips
+// the comparison to the most recent tested version. An error is only returned
+// if the version can't be parsed; an incompatible version is reported in the
+// result.
+func CheckVersion(versionInfo *version.Info, min, maxTested [3]int) (*VersionCheckResult, error) {
+	apiVersion, suffix, err := getK8sVersionFromInfo(versionInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VersionCheckResult{
+		Version:          apiVersion,
+		Suffix:           suffix,
+		MinVersion:       min,
+		MaxTestedVersion: maxTested,
+		Compatible:       isCompatibleVersion(min, apiVersion),
+	}
+
+	if maxTested != [3]int{} {
+		nextUntested := [3]int{maxTested[0], maxTested[1] + 1, 0}
+		result.Untested = isCompatibleVersion(nextUntested, apiVersion)
+	}
+
+	switch {
+	case apiVersion == min && isPreRelease(suffix):
+		result.Warning = fmt.Sprintf("Kubernetes is on pre-release version [%s] of the minimum required version [%s]",
+			formatK8sVersion(apiVersion, suffix), formatK8sVersion(min, ""))
+	case result.Untested:
+		result.Warning = fmt.Sprintf("Kubernetes is on version [%s], but Linkerd has only been tested with versions up to [%d.%d.x]",
+			formatK8sVersion(apiVersion, suffix), maxTested[0], maxTested[1])
+	}
+
+	return result, nil
 }
 
 func isCompatibleVersion(minimalRequirementVersion [3]int, actualVersion [3]int) bool {
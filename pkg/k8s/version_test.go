@@ -2,6 +2,8 @@ package k8s
 
 import (
 	"testing"
+
+	"k8s.io/apimachinery/pkg/version"
 )
 
 func TestGetK8sVersion(t *testing.T) {
@@ -12,10 +14,14 @@ func TestGetK8sVersion(t *testing.T) {
 			"v2.0.1":               {2, 0, 1},
 			"v1.9.0-beta.2":        {1, 9, 0},
 			"v1.7.9+7f63532e4ff4f": {1, 7, 9},
+			"v1.13.0-rc.1":         {1, 13, 0},
+			"v1.14.0-alpha.3":      {1, 14, 0},
+			"v1.11.0+d4cacc0":      {1, 11, 0},
+			"1.12.3-beta.0+abc123": {1, 12, 3},
 		}
 
 		for k, expectedVersion := range versions {
-			actualVersion, err := getK8sVersion(k)
+			actualVersion, _, err := getK8sVersion(k)
 			if err != nil {
 				t.Fatalf("Error parsing string: %v", err)
 			}
@@ -34,11 +40,16 @@ func TestGetK8sVersion(t *testing.T) {
 			"1.9-beta.2",
 			"v1.7+7f63532e4ff4f",
 			"Client Version: v1.8.4",
+			"v1.8.4-",
+			"v1.8.4+",
+			"v1.8.4 beta",
+			"not a version",
+			"v1.x.4",
 			"Version.Info{Major:\"1\", Minor:\"8\", GitVersion:\"v1.8.4\", GitCommit:\"9befc2b8928a9426501d3bf62f72849d5cbcd5a3\", GitTreeState:\"clean\", BuildDate:\"2017-11-20T05:28:34Z\", GoVersion:\"go1.8.3\", Compiler:\"gc\", Platform:\"darwin/amd64\"}",
 		}
 
 		for _, invalidVersion := range versions {
-			_, err := getK8sVersion(invalidVersion)
+			_, _, err := getK8sVersion(invalidVersion)
 
 			if err == nil {
 				t.Fatalf("Expected error parsing string: %s", invalidVersion)
@@ -47,6 +58,108 @@ func TestGetK8sVersion(t *testing.T) {
 	})
 }
 
+func TestGetK8sVersionSuffix(t *testing.T) {
+	suffixes := map[string]string{
+		"v1.8.4":                "",
+		"v1.13.0-rc.1":          "-rc.1",
+		"v1.14.0-alpha.3":       "-alpha.3",
+		"v1.11.0+d4cacc0":       "+d4cacc0",
+		"v1.12.3-beta.0+abc123": "-beta.0+abc123",
+	}
+
+	for k, expectedSuffix := range suffixes {
+		_, suffix, err := getK8sVersion(k)
+		if err != nil {
+			t.Fatalf("Error parsing string: %v", err)
+		}
+		if suffix != expectedSuffix {
+			t.Fatalf("Expecting %s to have suffix [%s] but got [%s]", k, expectedSuffix, suffix)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	api := &KubernetesAPI{}
+
+	t.Run("Accepts pre-releases and vendor builds of supported versions", func(t *testing.T) {
+		versions := []string{"v1.13.0-rc.1", "v1.14.0-alpha.3", "v1.11.0+d4cacc0", "v1.8.0-beta.1"}
+		for _, v := range versions {
+			if err := api.CheckVersion(&version.Info{GitVersion: v}); err != nil {
+				t.Fatalf("Expected version [%s] to be compatible, got: %v", v, err)
+			}
+		}
+	})
+
+	t.Run("Reports the raw version of incompatible clusters", func(t *testing.T) {
+		err := api.CheckVersion(&version.Info{GitVersion: "v1.7.9+7f63532e4ff4f"})
+		expected := "Kubernetes is on version [1.7.9+7f63532e4ff4f], but version [1.8.0] or more recent is required"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Returns a clear error for garbage versions", func(t *testing.T) {
+		err := api.CheckVersion(&version.Info{GitVersion: "garbage"})
+		expected := "unknown version string format [garbage]"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestGetK8sVersionFromInfo(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		info     version.Info
+		expected [3]int
+	}{
+		{
+			desc:     "plain version",
+			info:     version.Info{Major: "1", Minor: "9", GitVersion: "v1.9.3"},
+			expected: [3]int{1, 9, 3},
+		},
+		{
+			desc:     "GKE version",
+			info:     version.Info{Major: "1", Minor: "10+", GitVersion: "v1.10.7-gke.6"},
+			expected: [3]int{1, 10, 7},
+		},
+		{
+			desc:     "EKS version",
+			info:     version.Info{Major: "1", Minor: "11+", GitVersion: "v1.11.5-eks-6bad6d"},
+			expected: [3]int{1, 11, 5},
+		},
+		{
+			desc:     "unparseable gitVersion with a \"+\" minor version",
+			info:     version.Info{Major: "1", Minor: "12+", GitVersion: "1.12+"},
+			expected: [3]int{1, 12, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			actualVersion, _, err := getK8sVersionFromInfo(&tc.info)
+			if err != nil {
+				t.Fatalf("Error parsing version: %v", err)
+			}
+			if actualVersion != tc.expected {
+				t.Fatalf("Expecting %+v to be parsed into %v but got %v", tc.info, tc.expected, actualVersion)
+			}
+
+			api := &KubernetesAPI{}
+			if err := api.CheckVersion(&tc.info); err != nil {
+				t.Fatalf("Expected version %+v to be compatible, got: %v", tc.info, err)
+			}
+		})
+	}
+
+	t.Run("Returns error if neither gitVersion nor major and minor can be parsed", func(t *testing.T) {
+		info := version.Info{Major: "", Minor: "12+", GitVersion: "1.12+"}
+		if _, _, err := getK8sVersionFromInfo(&info); err == nil {
+			t.Fatalf("Expected error parsing version %+v", info)
+		}
+	})
+}
+
 func TestIsCompatibleVersion(t *testing.T) {
 	t.Run("Success when compatible versions", func(t *testing.T) {
 		compatibleVersions := map[[3]int][3]int{
@@ -82,3 +195,115 @@ func TestIsCompatibleVersion(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckVersionAgainstMinimum(t *testing.T) {
+	t.Run("Reports a compatible version", func(t *testing.T) {
+		result, err := CheckVersion(&version.Info{GitVersion: "v1.10.7-gke.6"}, [3]int{1, 9, 0}, [3]int{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := VersionCheckResult{
+			Version:    [3]int{1, 10, 7},
+			Suffix:     "-gke.6",
+			MinVersion: [3]int{1, 9, 0},
+			Compatible: true,
+		}
+		if *result != expected {
+			t.Fatalf("Expected result %+v, got %+v", expected, *result)
+		}
+		if result.Err() != nil {
+			t.Fatalf("Expected no error for a compatible version, got: %v", result.Err())
+		}
+	})
+
+	t.Run("Reports an incompatible version", func(t *testing.T) {
+		result, err := CheckVersion(&version.Info{GitVersion: "v1.8.4"}, [3]int{1, 9, 0}, [3]int{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := VersionCheckResult{
+			Version:    [3]int{1, 8, 4},
+			MinVersion: [3]int{1, 9, 0},
+			Compatible: false,
+		}
+		if *result != expected {
+			t.Fatalf("Expected result %+v, got %+v", expected, *result)
+		}
+
+		expectedErr := "Kubernetes is on version [1.8.4], but version [1.9.0] or more recent is required"
+		if result.Err() == nil || result.Err().Error() != expectedErr {
+			t.Fatalf("Expected error [%s], got [%v]", expectedErr, result.Err())
+		}
+	})
+
+	t.Run("Warns about a pre-release of the minimum version", func(t *testing.T) {
+		result, err := CheckVersion(&version.Info{GitVersion: "v1.9.0-beta.1"}, [3]int{1, 9, 0}, [3]int{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !result.Compatible {
+			t.Fatalf("Expected a pre-release of the minimum version to be compatible")
+		}
+		expected := "Kubernetes is on pre-release version [1.9.0-beta.1] of the minimum required version [1.9.0]"
+		if result.Warning != expected {
+			t.Fatalf("Expected warning [%s], got [%s]", expected, result.Warning)
+		}
+	})
+
+	t.Run("Returns an error if the version can't be parsed", func(t *testing.T) {
+		result, err := CheckVersion(&version.Info{GitVersion: "garbage"}, [3]int{1, 9, 0}, [3]int{})
+		if err == nil {
+			t.Fatalf("Expected error, got result %+v", result)
+		}
+	})
+}
+
+func TestCheckVersionAgainstMaxTested(t *testing.T) {
+	min := [3]int{1, 8, 0}
+	maxTested := [3]int{1, 11, 0}
+
+	testCases := []struct {
+		gitVersion string
+		compatible bool
+		untested   bool
+		warning    string
+	}{
+		{gitVersion: "v1.7.9", compatible: false, untested: false},
+		{gitVersion: "v1.9.3", compatible: true, untested: false},
+		{gitVersion: "v1.11.5-eks-6bad6d", compatible: true, untested: false},
+		{
+			gitVersion: "v1.12.0-rc.1",
+			compatible: true,
+			untested:   true,
+			warning:    "Kubernetes is on version [1.12.0-rc.1], but Linkerd has only been tested with versions up to [1.11.x]",
+		},
+		{
+			gitVersion: "v2.0.0",
+			compatible: true,
+			untested:   true,
+			warning:    "Kubernetes is on version [2.0.0], but Linkerd has only been tested with versions up to [1.11.x]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.gitVersion, func(t *testing.T) {
+			result, err := CheckVersion(&version.Info{GitVersion: tc.gitVersion}, min, maxTested)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if result.Compatible != tc.compatible {
+				t.Fatalf("Expected compatible [%t], got [%t]", tc.compatible, result.Compatible)
+			}
+			if result.Untested != tc.untested {
+				t.Fatalf("Expected untested [%t], got [%t]", tc.untested, result.Untested)
+			}
+			if result.Warning != tc.warning {
+				t.Fatalf("Expected warning [%s], got [%s]", tc.warning, result.Warning)
+			}
+		})
+	}
+}
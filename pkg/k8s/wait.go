@@ -0,0 +1,194 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/api/core/v1"
+)
+
+// podReadyPollInterval is how often WaitForPodsReady lists pods.
+var podReadyPollInterval = 2 * time.Second
+
+// namespaceDeletionPollInterval is how often WaitForNamespaceDeletion gets the
+// namespace.
+var namespaceDeletionPollInterval = 2 * time.Second
+
+// WaitForPodsReady polls the pods in namespace matching labelSelector until
+// there is at least one pod and all of them have a Ready=True condition. If
+// progress is not nil, it is called with the pods returned by each poll, so
+// that callers can report progress to the user.
+//
+// If the pods are not ready within timeout, the returned error lists the pods
+// that are not ready and, where known, why their containers are waiting, e.g.
+// ImagePullBackOff or CrashLoopBackOff, along with their most recent Warning
+// events.
+func (kubeAPI *KubernetesAPI) WaitForPodsReady(ctx context.Context, namespace, labelSelector string, timeout time.Duration, progress func([]v1.Pod)) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(podReadyPollInterval)
+	defer ticker.Stop()
+
+	var pods []v1.Pod
+	for {
+		current, err := kubeAPI.GetPodsFor(ctx, namespace, labelSelector)
+		if err != nil {
+			if ctx.Err() == nil {
+				return err
+			}
+		} else {
+			pods = current
+			if progress != nil {
+				progress(pods)
+			}
+			if len(pods) > 0 && len(notReadyPods(pods)) == 0 {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return kubeAPI.podsNotReadyError(namespace, labelSelector, timeout, pods)
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForNamespaceDeletion polls the namespace with the given name until it no
+// longer exists, which, once it has been deleted, happens after all of the
+// objects in it have been deleted too. If it still exists after timeout, the
+// returned error includes its phase, and the finalizers it is waiting for.
+func (kubeAPI *KubernetesAPI) WaitForNamespaceDeletion(ctx context.Context, name string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(namespaceDeletionPollInterval)
+	defer ticker.Stop()
+
+	var namespace *v1.Namespace
+	for {
+		current, err := kubeAPI.GetNamespace(ctx, name)
+		switch {
+		case IsNotFound(err):
+			return nil
+		case err != nil:
+			if ctx.Err() == nil {
+				return err
+			}
+		default:
+			namespace = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return namespaceNotDeletedError(name, timeout, namespace)
+		case <-ticker.C:
+		}
+	}
+}
+
+func namespaceNotDeletedError(name string, timeout time.Duration, namespace *v1.Namespace) error {
+	if namespace == nil {
+		return fmt.Errorf("timed out after %s waiting for namespace [%s] to be deleted", timeout, name)
+	}
+	if len(namespace.Spec.Finalizers) == 0 {
+		return fmt.Errorf("timed out after %s waiting for namespace [%s] to be deleted: it is %s", timeout, name, namespace.Status.Phase)
+	}
+
+	finalizers := make([]string, len(namespace.Spec.Finalizers))
+	for i, finalizer := range namespace.Spec.Finalizers {
+		finalizers[i] = string(finalizer)
+	}
+	return fmt.Errorf("timed out after %s waiting for namespace [%s] to be deleted: it is %s, waiting for finalizers %s", timeout, name, namespace.Status.Phase, strings.Join(finalizers, ", "))
+}
+
+// IsPodReady returns true if the pod has a Ready=True condition.
+func IsPodReady(pod v1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			return condition.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// SelectReadyPod returns a ready pod in namespace matching labelSelector. If
+// there is none, the returned error describes why each of the pods is not
+// ready, e.g. that a container is waiting with CrashLoopBackOff.
+func (kubeAPI *KubernetesAPI) SelectReadyPod(ctx context.Context, namespace, labelSelector string) (*v1.Pod, error) {
+	pods, err := kubeAPI.GetPodsFor(ctx, namespace, labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no pods in namespace [%s] match [%s]", namespace, labelSelector)
+	}
+
+	for i := range pods {
+		if pods[i].DeletionTimestamp == nil && IsPodReady(pods[i]) {
+			return &pods[i], nil
+		}
+	}
+
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	descriptions := make([]string, len(pods))
+	for i, pod := range pods {
+		descriptions[i] = describeNotReadyPod(pod)
+	}
+	return nil, fmt.Errorf("no ready pods in namespace [%s] match [%s]:\n\t%s", namespace, labelSelector, strings.Join(descriptions, "\n\t"))
+}
+
+func notReadyPods(pods []v1.Pod) []v1.Pod {
+	var notReady []v1.Pod
+	for _, pod := range pods {
+		if !IsPodReady(pod) {
+			notReady = append(notReady, pod)
+		}
+	}
+	return notReady
+}
+
+func (kubeAPI *KubernetesAPI) podsNotReadyError(namespace, labelSelector string, timeout time.Duration, pods []v1.Pod) error {
+	if len(pods) == 0 {
+		return fmt.Errorf("timed out after %s waiting for pods matching [%s] in namespace [%s]: no pods found", timeout, labelSelector, namespace)
+	}
+
+	notReady := notReadyPods(pods)
+	sort.Slice(notReady, func(i, j int) bool { return notReady[i].Name < notReady[j].Name })
+
+	var descriptions []string
+	for _, pod := range notReady {
+		description := describeNotReadyPod(pod)
+
+		// The caller's context has expired by now, so events are fetched with
+		// a new one, bounded by the request timeout.
+		events, err := kubeAPI.GetWarningEventsFor(context.Background(), pod.Namespace, "Pod", pod.Name)
+		if err == nil && len(events) > 0 {
+			description += "\n\t" + strings.Replace(FormatEvents(events), "\n", "\n\t", -1)
+		}
+		descriptions = append(descriptions, description)
+	}
+
+	return fmt.Errorf("timed out after %s waiting for pods matching [%s] in namespace [%s] to be ready:\n\t%s", timeout, labelSelector, namespace, strings.Join(descriptions, "\n\t"))
+}
+
+func describeNotReadyPod(pod v1.Pod) string {
+	var reasons []string
+	var statuses []v1.ContainerStatus
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			reasons = append(reasons, fmt.Sprintf("container [%s] is waiting: %s", status.Name, status.State.Waiting.Reason))
+		}
+	}
+
+	if len(reasons) == 0 {
+		return fmt.Sprintf("pod [%s] is not ready (phase %s)", pod.Name, pod.Status.Phase)
+	}
+	return fmt.Sprintf("pod [%s] is not ready (%s)", pod.Name, strings.Join(reasons, ", "))
+}
@@ -0,0 +1,251 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func newPod(name string, phase v1.PodPhase, ready bool, waitingReason string) v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "linkerd"},
+		Status: v1.PodStatus{
+			Phase:      phase,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+	if waitingReason != "" {
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{
+			{
+				Name:  "linkerd-proxy",
+				State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: waitingReason}},
+			},
+		}
+	}
+	return pod
+}
+
+func podListServer(t *testing.T, responses func(poll int) []v1.Pod) (*httptest.Server, *int) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/events") {
+			w.Write([]byte(`{"kind":"EventList","apiVersion":"v1","metadata":{},"items":[{"reason":"BackOff","message":"Back-off restarting failed container","type":"Warning","lastTimestamp":"2018-09-01T10:05:00Z"}]}`))
+			return
+		}
+
+		polls++
+		list := v1.PodList{Items: responses(polls)}
+		if err := json.NewEncoder(w).Encode(list); err != nil {
+			t.Fatalf("Unexpected error encoding pods: %v", err)
+		}
+	}))
+	return server, &polls
+}
+
+func TestWaitForPodsReady(t *testing.T) {
+	defaultInterval := podReadyPollInterval
+	podReadyPollInterval = 10 * time.Millisecond
+	defer func() { podReadyPollInterval = defaultInterval }()
+
+	t.Run("Returns once pods transition from Pending to Ready", func(t *testing.T) {
+		server, polls := podListServer(t, func(poll int) []v1.Pod {
+			switch poll {
+			case 1:
+				return nil
+			case 2:
+				return []v1.Pod{
+					newPod("linkerd-controller-1", v1.PodPending, false, "ContainerCreating"),
+					newPod("linkerd-web-1", v1.PodPending, false, "ContainerCreating"),
+				}
+			case 3:
+				return []v1.Pod{
+					newPod("linkerd-controller-1", v1.PodRunning, true, ""),
+					newPod("linkerd-web-1", v1.PodRunning, false, ""),
+				}
+			default:
+				return []v1.Pod{
+					newPod("linkerd-controller-1", v1.PodRunning, true, ""),
+					newPod("linkerd-web-1", v1.PodRunning, true, ""),
+				}
+			}
+		})
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		progressCalls := 0
+		err := api.WaitForPodsReady(context.Background(), "linkerd", ControllerNSLabel+"=linkerd", time.Second, func(pods []v1.Pod) {
+			progressCalls++
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if *polls != 4 {
+			t.Fatalf("Expected 4 polls, got %d", *polls)
+		}
+		if progressCalls != *polls {
+			t.Fatalf("Expected progress to be reported on each of %d polls, got %d", *polls, progressCalls)
+		}
+	})
+
+	t.Run("Describes pods stuck in CrashLoopBackOff", func(t *testing.T) {
+		server, _ := podListServer(t, func(poll int) []v1.Pod {
+			return []v1.Pod{
+				newPod("linkerd-controller-1", v1.PodRunning, true, ""),
+				newPod("linkerd-prometheus-1", v1.PodRunning, false, "CrashLoopBackOff"),
+			}
+		})
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		err := api.WaitForPodsReady(context.Background(), "linkerd", "", 50*time.Millisecond, nil)
+		if err == nil {
+			t.Fatalf("Expected an error, got none")
+		}
+
+		for _, expected := range []string{
+			"timed out after 50ms",
+			"pod [linkerd-prometheus-1] is not ready (container [linkerd-proxy] is waiting: CrashLoopBackOff)\n\t\tBackOff: Back-off restarting failed container",
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Fatalf("Expected error to contain [%s], got [%s]", expected, err)
+			}
+		}
+		if strings.Contains(err.Error(), "linkerd-controller-1") {
+			t.Fatalf("Expected error not to list ready pods, got [%s]", err)
+		}
+	})
+
+	t.Run("Reports when no pods are found", func(t *testing.T) {
+		server, _ := podListServer(t, func(poll int) []v1.Pod { return nil })
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		err := api.WaitForPodsReady(context.Background(), "linkerd", "app=missing", 30*time.Millisecond, nil)
+		if err == nil || !strings.Contains(err.Error(), "no pods found") {
+			t.Fatalf("Expected a no pods found error, got [%v]", err)
+		}
+	})
+
+	t.Run("Returns API errors immediately", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		err := api.WaitForPodsReady(context.Background(), "linkerd", "", time.Minute, nil)
+		if !IsForbidden(err) {
+			t.Fatalf("Expected a Forbidden error, got [%v]", err)
+		}
+	})
+}
+
+func TestWaitForNamespaceDeletion(t *testing.T) {
+	defaultInterval := namespaceDeletionPollInterval
+	namespaceDeletionPollInterval = 10 * time.Millisecond
+	defer func() { namespaceDeletionPollInterval = defaultInterval }()
+
+	terminating := `{"metadata":{"name":"linkerd"},"spec":{"finalizers":["kubernetes"]},"status":{"phase":"Terminating"}}`
+
+	t.Run("Returns once the namespace no longer exists", func(t *testing.T) {
+		polls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			polls++
+			if polls < 3 {
+				w.Write([]byte(terminating))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		if err := api.WaitForNamespaceDeletion(context.Background(), "linkerd", time.Second); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if polls != 3 {
+			t.Fatalf("Expected 3 polls, got %d", polls)
+		}
+	})
+
+	t.Run("Reports the finalizers the namespace is waiting for", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(terminating))
+		}))
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}, RetryAttempts: 1}
+
+		err := api.WaitForNamespaceDeletion(context.Background(), "linkerd", 50*time.Millisecond)
+		expected := "timed out after 50ms waiting for namespace [linkerd] to be deleted: it is Terminating, waiting for finalizers kubernetes"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestSelectReadyPod(t *testing.T) {
+	t.Run("Returns a ready pod", func(t *testing.T) {
+		server, _ := podListServer(t, func(int) []v1.Pod {
+			return []v1.Pod{
+				newPod("web-1", v1.PodPending, false, "ContainerCreating"),
+				newPod("web-2", v1.PodRunning, true, ""),
+			}
+		})
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		pod, err := api.SelectReadyPod(context.Background(), "linkerd", "app=web")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if pod.Name != "web-2" {
+			t.Fatalf("Expected pod [web-2], got [%s]", pod.Name)
+		}
+	})
+
+	t.Run("Describes pods that are not ready", func(t *testing.T) {
+		server, _ := podListServer(t, func(int) []v1.Pod {
+			return []v1.Pod{
+				newPod("web-2", v1.PodRunning, false, "CrashLoopBackOff"),
+				newPod("web-1", v1.PodPending, false, ""),
+			}
+		})
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		_, err := api.SelectReadyPod(context.Background(), "linkerd", "app=web")
+		expected := "no ready pods in namespace [linkerd] match [app=web]:\n\tpod [web-1] is not ready (phase Pending)\n\tpod [web-2] is not ready (container [linkerd-proxy] is waiting: CrashLoopBackOff)"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+
+	t.Run("Reports when no pods are found", func(t *testing.T) {
+		server, _ := podListServer(t, func(int) []v1.Pod { return nil })
+		defer server.Close()
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		_, err := api.SelectReadyPod(context.Background(), "linkerd", "app=web")
+		expected := "no pods in namespace [linkerd] match [app=web]"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
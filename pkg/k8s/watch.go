@@ -0,0 +1,200 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// PodEvent is a change to a pod, as reported by WatchPods.
+type PodEvent struct {
+	// Type is watch.Added, watch.Modified or watch.Deleted, or watch.Error if
+	// the watch failed.
+	Type watch.EventType
+	Pod  v1.Pod
+	// Err is the reason the watch failed, for events of type watch.Error.
+	// Such an event is always the last one sent before the channel is closed.
+	Err error
+}
+
+// watchEvent is an event in the stream returned by a watch request.
+type watchEvent struct {
+	Type   watch.EventType `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// WatchPods lists the pods in namespace matching labelSelector, and then
+// watches them for changes. An empty namespace watches pods in all
+// namespaces. The returned channel first receives an Added event for each
+// existing pod, followed by an event for each change. It is closed when ctx is
+// cancelled or when the API server ends the watch.
+//
+// If the API server reports that the watch's resource version is too old, the
+// pods are listed again, and the differences with the last known state are
+// sent as events before the watch is resumed.
+func (kubeAPI *KubernetesAPI) WatchPods(ctx context.Context, namespace, labelSelector string) (<-chan PodEvent, error) {
+	pods, resourceVersion, err := kubeAPI.listPods(ctx, namespace, labelSelector, "")
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan PodEvent)
+	go kubeAPI.watchPods(ctx, namespace, labelSelector, pods, resourceVersion, events)
+	return events, nil
+}
+
+func (kubeAPI *KubernetesAPI) watchPods(ctx context.Context, namespace, labelSelector string, pods []v1.Pod, resourceVersion string, events chan<- PodEvent) {
+	defer close(events)
+
+	known := make(map[string]v1.Pod)
+	send := func(event PodEvent) bool {
+		key := event.Pod.Namespace + "/" + event.Pod.Name
+		switch event.Type {
+		case watch.Added, watch.Modified:
+			known[key] = event.Pod
+		case watch.Deleted:
+			delete(known, key)
+		}
+
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for _, pod := range pods {
+		if !send(PodEvent{Type: watch.Added, Pod: pod}) {
+			return
+		}
+	}
+
+	// Events received from the watch advance the resource version from which
+	// the watch is resumed; those sent after listing pods do not.
+	handle := func(event PodEvent) bool {
+		if event.Pod.ResourceVersion != "" {
+			resourceVersion = event.Pod.ResourceVersion
+		}
+		return send(event)
+	}
+
+	for {
+		gone, err := kubeAPI.streamPodEvents(ctx, namespace, labelSelector, resourceVersion, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			send(PodEvent{Type: watch.Error, Err: err})
+			return
+		}
+		if !gone {
+			return
+		}
+
+		current, listVersion, err := kubeAPI.listPods(ctx, namespace, labelSelector, "")
+		if err != nil {
+			if ctx.Err() == nil {
+				send(PodEvent{Type: watch.Error, Err: err})
+			}
+			return
+		}
+		for _, event := range resyncEvents(known, current) {
+			if !send(event) {
+				return
+			}
+		}
+		resourceVersion = listVersion
+	}
+}
+
+// resyncEvents returns the events that turn the known pods into the current
+// ones.
+func resyncEvents(known map[string]v1.Pod, current []v1.Pod) []PodEvent {
+	var events []PodEvent
+	seen := make(map[string]bool)
+	for _, pod := range current {
+		key := pod.Namespace + "/" + pod.Name
+		seen[key] = true
+		if _, ok := known[key]; ok {
+			events = append(events, PodEvent{Type: watch.Modified, Pod: pod})
+		} else {
+			events = append(events, PodEvent{Type: watch.Added, Pod: pod})
+		}
+	}
+	for key, pod := range known {
+		if !seen[key] {
+			events = append(events, PodEvent{Type: watch.Deleted, Pod: pod})
+		}
+	}
+	return events
+}
+
+// streamPodEvents watches pods from resourceVersion, calling handle with each
+// event until the stream ends, ctx is cancelled, or handle returns false. It
+// returns true if the resource version is too old, in which case the pods
+// must be listed again.
+func (kubeAPI *KubernetesAPI) streamPodEvents(ctx context.Context, namespace, labelSelector, resourceVersion string, handle func(PodEvent) bool) (bool, error) {
+	client, err := kubeAPI.Client()
+	if err != nil {
+		return false, err
+	}
+
+	query := url.Values{}
+	query.Set("watch", "true")
+	query.Set("resourceVersion", resourceVersion)
+	if labelSelector != "" {
+		query.Set("labelSelector", labelSelector)
+	}
+
+	// Watches are long-lived, so the request timeout is not applied.
+	rsp, err := kubeAPI.doRequest(ctx, client, "GET", podsPath(namespace), query, nil)
+	if err != nil {
+		return false, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode == http.StatusGone {
+		return true, nil
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return false, kubeAPI.responseError(rsp)
+	}
+
+	decoder := json.NewDecoder(rsp.Body)
+	for {
+		var event watchEvent
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return false, nil
+			}
+			return false, fmt.Errorf("error decoding watch event: %s", err)
+		}
+
+		if event.Type == watch.Error {
+			var status metav1.Status
+			if err := json.Unmarshal(event.Object, &status); err != nil {
+				return false, fmt.Errorf("error decoding watch error: %s", err)
+			}
+			if status.Code == http.StatusGone {
+				return true, nil
+			}
+			return false, fmt.Errorf("watch failed: %s", status.Message)
+		}
+
+		var pod v1.Pod
+		if err := json.Unmarshal(event.Object, &pod); err != nil {
+			return false, fmt.Errorf("error decoding watch event: %s", err)
+		}
+		if !handle(PodEvent{Type: event.Type, Pod: pod}) {
+			return false, nil
+		}
+	}
+}
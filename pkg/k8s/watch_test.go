@@ -0,0 +1,186 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+)
+
+func podJSON(name, resourceVersion, phase string) string {
+	return fmt.Sprintf(`{"metadata":{"name":"%s","namespace":"emojivoto","resourceVersion":"%s"},"status":{"phase":"%s"}}`, name, resourceVersion, phase)
+}
+
+// streamEvents writes each event to w as a separate chunk.
+func streamEvents(t *testing.T, w http.ResponseWriter, events ...string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		t.Fatalf("Expected the response writer to support flushing")
+	}
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for _, event := range events {
+		fmt.Fprintln(w, event)
+		flusher.Flush()
+	}
+}
+
+type observedEvent struct {
+	Type  watch.EventType
+	Name  string
+	Phase string
+}
+
+func collectEvents(t *testing.T, events <-chan PodEvent) []observedEvent {
+	var observed []observedEvent
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return observed
+			}
+			if event.Err != nil {
+				t.Fatalf("Unexpected watch error: %v", event.Err)
+			}
+			observed = append(observed, observedEvent{event.Type, event.Pod.Name, string(event.Pod.Status.Phase)})
+		case <-timeout:
+			t.Fatalf("Timed out waiting for the event channel to be closed, got %v", observed)
+		}
+	}
+}
+
+func TestWatchPods(t *testing.T) {
+	t.Run("Decodes the watch stream", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v1/namespaces/emojivoto/pods" {
+				t.Fatalf("Unexpected path [%s]", r.URL.Path)
+			}
+			if got := r.URL.Query().Get("labelSelector"); got != "app=web" {
+				t.Fatalf("Unexpected label selector [%s]", got)
+			}
+
+			if r.URL.Query().Get("watch") != "true" {
+				fmt.Fprintf(w, `{"kind":"PodList","metadata":{"resourceVersion":"100"},"items":[%s]}`, podJSON("web-1", "99", "Pending"))
+				return
+			}
+
+			if got := r.URL.Query().Get("resourceVersion"); got != "100" {
+				t.Fatalf("Expected watch to start from resource version [100], got [%s]", got)
+			}
+			streamEvents(t, w,
+				`{"type":"ADDED","object":`+podJSON("web-2", "101", "Pending")+`}`,
+				`{"type":"MODIFIED","object":`+podJSON("web-1", "102", "Running")+`}`,
+				`{"type":"DELETED","object":`+podJSON("web-2", "103", "Pending")+`}`,
+			)
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		events, err := api.WatchPods(context.Background(), "emojivoto", "app=web")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []observedEvent{
+			{watch.Added, "web-1", "Pending"},
+			{watch.Added, "web-2", "Pending"},
+			{watch.Modified, "web-1", "Running"},
+			{watch.Deleted, "web-2", "Pending"},
+		}
+		if observed := collectEvents(t, events); !reflect.DeepEqual(observed, expected) {
+			t.Fatalf("Expected events %v, got %v", expected, observed)
+		}
+	})
+
+	t.Run("Lists pods again when the resource version is too old", func(t *testing.T) {
+		lists := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("watch") != "true" {
+				lists++
+				if lists == 1 {
+					fmt.Fprintf(w, `{"kind":"PodList","metadata":{"resourceVersion":"100"},"items":[%s,%s]}`,
+						podJSON("web-1", "98", "Pending"), podJSON("web-2", "99", "Running"))
+				} else {
+					fmt.Fprintf(w, `{"kind":"PodList","metadata":{"resourceVersion":"200"},"items":[%s,%s]}`,
+						podJSON("web-1", "150", "Running"), podJSON("web-3", "160", "Pending"))
+				}
+				return
+			}
+
+			switch r.URL.Query().Get("resourceVersion") {
+			case "100":
+				streamEvents(t, w, `{"type":"ERROR","object":{"kind":"Status","apiVersion":"v1","status":"Failure","message":"too old resource version: 100 (150)","reason":"Gone","code":410}}`)
+			case "200":
+				streamEvents(t, w, `{"type":"MODIFIED","object":`+podJSON("web-3", "201", "Running")+`}`)
+			default:
+				t.Fatalf("Unexpected resource version in [%s]", r.URL.RawQuery)
+			}
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		events, err := api.WatchPods(context.Background(), "emojivoto", "app=web")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []observedEvent{
+			{watch.Added, "web-1", "Pending"},
+			{watch.Added, "web-2", "Running"},
+			{watch.Modified, "web-1", "Running"},
+			{watch.Added, "web-3", "Pending"},
+			{watch.Deleted, "web-2", "Running"},
+			{watch.Modified, "web-3", "Running"},
+		}
+		if observed := collectEvents(t, events); !reflect.DeepEqual(observed, expected) {
+			t.Fatalf("Expected events %v, got %v", expected, observed)
+		}
+		if lists != 2 {
+			t.Fatalf("Expected pods to be listed twice, got %d", lists)
+		}
+	})
+
+	t.Run("Closes the channel when the context is cancelled", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("watch") != "true" {
+				fmt.Fprint(w, `{"kind":"PodList","metadata":{"resourceVersion":"100"},"items":[]}`)
+				return
+			}
+			streamEvents(t, w, `{"type":"ADDED","object":`+podJSON("web-1", "101", "Pending")+`}`)
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		}))
+		defer server.Close()
+
+		api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := api.WatchPods(ctx, "emojivoto", "")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if event := <-events; event.Type != watch.Added || event.Pod.Name != "web-1" {
+			t.Fatalf("Unexpected event %+v", event)
+		}
+		cancel()
+
+		if observed := collectEvents(t, events); len(observed) != 0 {
+			t.Fatalf("Expected no more events after cancellation, got %v", observed)
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package k8s
+
+import (
+	"context"
+
+	admissionregistrationV1beta1 "k8s.io/api/admissionregistration/v1beta1"
+)
+
+// GetMutatingWebhookConfiguration returns the MutatingWebhookConfiguration
+// with the given name. If it does not exist, the returned error satisfies
+// IsNotFound.
+func (kubeAPI *KubernetesAPI) GetMutatingWebhookConfiguration(ctx context.Context, name string) (*admissionregistrationV1beta1.MutatingWebhookConfiguration, error) {
+	var config admissionregistrationV1beta1.MutatingWebhookConfiguration
+	if err := kubeAPI.getJSON(ctx, "/apis/admissionregistration.k8s.io/v1beta1/mutatingwebhookconfigurations/"+name, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
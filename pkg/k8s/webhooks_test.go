@@ -0,0 +1,40 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestGetMutatingWebhookConfiguration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/admissionregistration.k8s.io/v1beta1/mutatingwebhookconfigurations/linkerd-linkerd-proxy-injector":
+			w.Write([]byte(`{"kind":"MutatingWebhookConfiguration","apiVersion":"admissionregistration.k8s.io/v1beta1","metadata":{"name":"linkerd-linkerd-proxy-injector"},"webhooks":[{"name":"proxy-injector.linkerd.io","clientConfig":{"service":{"namespace":"linkerd","name":"linkerd-proxy-injector"},"caBundle":"cGVt"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"kind":"Status","reason":"NotFound","code":404}`))
+		}
+	}))
+	defer server.Close()
+	api := &KubernetesAPI{Config: &rest.Config{Host: server.URL}}
+
+	t.Run("Returns the MutatingWebhookConfiguration", func(t *testing.T) {
+		config, err := api.GetMutatingWebhookConfiguration(context.Background(), "linkerd-linkerd-proxy-injector")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(config.Webhooks) != 1 || config.Webhooks[0].ClientConfig.Service.Name != "linkerd-proxy-injector" || string(config.Webhooks[0].ClientConfig.CABundle) != "pem" {
+			t.Fatalf("Unexpected MutatingWebhookConfiguration: %+v", config)
+		}
+	})
+
+	t.Run("Returns a not found error for missing configurations", func(t *testing.T) {
+		if _, err := api.GetMutatingWebhookConfiguration(context.Background(), "linkerd-other-proxy-injector"); !IsNotFound(err) {
+			t.Fatalf("Expected a not found error, got [%v]", err)
+		}
+	})
+}
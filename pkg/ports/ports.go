@@ -0,0 +1,97 @@
+// Package ports parses the ports and ranges of ports that skip the proxy, as
+// given to `linkerd inject` and recorded in annotations, e.g. "25,4222-4223".
+package ports
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Range is a range of TCP ports, from Lower to Upper inclusive. A single port
+// is a range whose Lower and Upper are equal.
+type Range struct {
+	Lower uint16
+	Upper uint16
+}
+
+// String returns the port, e.g. "25", or the range of ports, e.g.
+// "4222-4223", as accepted by ParseRange.
+func (r Range) String() string {
+	if r.Lower == r.Upper {
+		return strconv.Itoa(int(r.Lower))
+	}
+	return fmt.Sprintf("%d-%d", r.Lower, r.Upper)
+}
+
+// ParseRange parses a port, e.g. "25", or a range of ports, e.g. "4222-4223".
+func ParseRange(spec string) (Range, error) {
+	parts := strings.SplitN(spec, "-", 2)
+	lower, err := parsePort(parts[0])
+	if err != nil {
+		return Range{}, fmt.Errorf("[%s] is not a port or a range of ports, e.g. 25 or 4222-4223", spec)
+	}
+	upper := lower
+	if len(parts) == 2 {
+		if upper, err = parsePort(parts[1]); err != nil {
+			return Range{}, fmt.Errorf("[%s] is not a port or a range of ports, e.g. 25 or 4222-4223", spec)
+		}
+	}
+	if upper < lower {
+		return Range{}, fmt.Errorf("port range [%s] ends before it starts", spec)
+	}
+	return Range{Lower: lower, Upper: upper}, nil
+}
+
+func parsePort(s string) (uint16, error) {
+	port, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return 0, err
+	}
+	if port == 0 {
+		return 0, fmt.Errorf("port 0 is not a valid TCP port")
+	}
+	return uint16(port), nil
+}
+
+// ParseRanges parses each of specs with ParseRange, and rejects ranges that
+// overlap, as one of them is a mistake. The ranges are returned in the order
+// of specs.
+func ParseRanges(specs []string) ([]Range, error) {
+	ranges := make([]Range, len(specs))
+	for i, spec := range specs {
+		r, err := ParseRange(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, err
+		}
+		ranges[i] = r
+	}
+
+	sorted := append([]Range{}, ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Lower < sorted[j].Lower })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Lower <= sorted[i-1].Upper {
+			return nil, fmt.Errorf("port ranges [%s] and [%s] overlap", sorted[i-1], sorted[i])
+		}
+	}
+	return ranges, nil
+}
+
+// ParseList is like ParseRanges, for a comma-separated list of specs. An
+// empty list has no ranges.
+func ParseList(list string) ([]Range, error) {
+	if strings.TrimSpace(list) == "" {
+		return []Range{}, nil
+	}
+	return ParseRanges(strings.Split(list, ","))
+}
+
+// FormatList returns ranges as a comma-separated list accepted by ParseList.
+func FormatList(ranges []Range) string {
+	specs := make([]string, len(ranges))
+	for i, r := range ranges {
+		specs[i] = r.String()
+	}
+	return strings.Join(specs, ",")
+}
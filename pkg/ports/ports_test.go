@@ -0,0 +1,59 @@
+package ports
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRanges(t *testing.T) {
+	t.Run("Parses ports and ranges of ports", func(t *testing.T) {
+		ranges, err := ParseRanges([]string{"25", "4222-4223", " 3306"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := []Range{{25, 25}, {4222, 4223}, {3306, 3306}}
+		if !reflect.DeepEqual(ranges, expected) {
+			t.Fatalf("Expected %v, got %v", expected, ranges)
+		}
+		if list := FormatList(ranges); list != "25,4222-4223,3306" {
+			t.Fatalf("Unexpected list [%s]", list)
+		}
+	})
+
+	t.Run("Rejects invalid ports and ranges", func(t *testing.T) {
+		for spec, expected := range map[string]string{
+			"smtp":           "[smtp] is not a port or a range of ports, e.g. 25 or 4222-4223",
+			"0":              "[0] is not a port or a range of ports, e.g. 25 or 4222-4223",
+			"65536":          "[65536] is not a port or a range of ports, e.g. 25 or 4222-4223",
+			"4222-":          "[4222-] is not a port or a range of ports, e.g. 25 or 4222-4223",
+			"4222-4223-4224": "[4222-4223-4224] is not a port or a range of ports, e.g. 25 or 4222-4223",
+			"4223-4222":      "port range [4223-4222] ends before it starts",
+		} {
+			if _, err := ParseRanges([]string{spec}); err == nil || err.Error() != expected {
+				t.Fatalf("Expected error [%s] for [%s], got [%v]", expected, spec, err)
+			}
+		}
+	})
+
+	t.Run("Rejects overlapping ranges", func(t *testing.T) {
+		_, err := ParseRanges([]string{"4230", "4222-4230"})
+		expected := "port ranges [4222-4230] and [4230] overlap"
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestParseList(t *testing.T) {
+	ranges, err := ParseList("25,4222-4223")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ranges, []Range{{25, 25}, {4222, 4223}}) {
+		t.Fatalf("Unexpected ranges %v", ranges)
+	}
+
+	if ranges, err := ParseList(""); err != nil || len(ranges) != 0 {
+		t.Fatalf("Expected no ranges, got %v and %v", ranges, err)
+	}
+}
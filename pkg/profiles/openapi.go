@@ -0,0 +1,265 @@
+package profiles
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+)
+
+// openAPIMethods are the fields of a path item that are operations, in the
+// order their routes are listed in. trace is only an operation in OpenAPI 3.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// pathTemplateParam matches the parameters of a path template, such as {id}.
+var pathTemplateParam = regexp.MustCompile(`\{[^{}/]*\}`)
+
+// openAPIDocument holds the fields of Swagger 2.0 and OpenAPI 3 documents
+// that routes are built from.
+type openAPIDocument struct {
+	Swagger  string                                `json:"swagger"`
+	OpenAPI  string                                `json:"openapi"`
+	BasePath string                                `json:"basePath"`
+	Servers  []openAPIServer                       `json:"servers"`
+	Paths    map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+type openAPIServer struct {
+	URL       string `json:"url"`
+	Variables map[string]struct {
+		Default string `json:"default"`
+	} `json:"variables"`
+}
+
+// openAPIServers holds the servers of operations, which replace those of
+// their path item and of the document in OpenAPI 3, as those of path items
+// replace those of the document.
+type openAPIServers struct {
+	Servers json.RawMessage `json:"servers"`
+}
+
+// ProfileFromOpenAPI returns the ServiceProfile of the service named service
+// in namespace whose routes are the operations of the Swagger 2.0 or
+// OpenAPI 3 document in data, in YAML or JSON. Each operation is a route
+// named after its method and path template, such as "GET /books/{id}", whose
+// condition is its method and an anchored regex of its path, prefixed with
+// the basePath, or the paths of the servers, of the document. In path
+// templates, {param} matches a path segment, or part of one, {param+} and
+// {param*} match one or more, and zero or more, characters of any number of
+// segments, and * matches zero or more characters of a segment. The constructs that are not supported are skipped, each with a warning.
+func ProfileFromOpenAPI(data []byte, namespace, service string) (*sp.ServiceProfile, []string, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse OpenAPI document: %s", err)
+	}
+	var doc openAPIDocument
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse OpenAPI document: %s", err)
+	}
+
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+
+	var openAPI3 bool
+	var prefixes []string
+	switch {
+	case doc.Swagger == "2.0":
+		prefixes = []string{strings.TrimSuffix(doc.BasePath, "/")}
+	case strings.HasPrefix(doc.OpenAPI, "3."):
+		openAPI3 = true
+		prefixes = serverPrefixes(doc.Servers, warn)
+	default:
+		return nil, nil, fmt.Errorf("expected a Swagger 2.0 or OpenAPI 3 document, got swagger [%s] and openapi [%s]", doc.Swagger, doc.OpenAPI)
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	routes := []*sp.RouteSpec{}
+	for _, path := range paths {
+		pathRegex, err := pathTemplateRegex(path)
+		if err != nil {
+			warn("skipped path [%s]: %s", path, err)
+			continue
+		}
+
+		item := doc.Paths[path]
+		if _, ok := item["$ref"]; ok {
+			warn("skipped path [%s]: path items with $ref are not supported", path)
+			continue
+		}
+		for field := range item {
+			if !isPathItemField(field, openAPI3) {
+				warn("skipped field [%s] of path [%s]: not an operation", field, path)
+			}
+		}
+
+		pathPrefixes := prefixes
+		if openAPI3 {
+			pathPrefixes = overridePrefixes(pathPrefixes, item["servers"], path, warn)
+		}
+
+		for _, method := range openAPIMethods {
+			operation, ok := item[method]
+			if !ok || (method == "trace" && !openAPI3) {
+				continue
+			}
+
+			operationPrefixes := pathPrefixes
+			if openAPI3 {
+				var servers openAPIServers
+				if err := json.Unmarshal(operation, &servers); err != nil {
+					warn("skipped servers of operation [%s %s]: %s", strings.ToUpper(method), path, err)
+				}
+				operationPrefixes = overridePrefixes(operationPrefixes, servers.Servers, path, warn)
+			}
+
+			routes = append(routes, &sp.RouteSpec{
+				Name: strings.ToUpper(method) + " " + path,
+				Condition: &sp.RequestMatch{
+					PathRegex: "^" + prefixesRegex(operationPrefixes) + pathRegex + "$",
+					Method:    strings.ToUpper(method),
+				},
+			})
+		}
+	}
+	if len(routes) == 0 {
+		warn("no operations found; the ServiceProfile has no routes")
+	}
+
+	profile := newServiceProfile(namespace, service)
+	profile.Spec.Routes = routes
+	return profile, warnings, nil
+}
+
+// isPathItemField reports whether field is a field of a path item that is
+// either an operation, or known not to change the routes of the path.
+func isPathItemField(field string, openAPI3 bool) bool {
+	if strings.HasPrefix(field, "x-") {
+		return true
+	}
+	switch field {
+	case "get", "put", "post", "delete", "options", "head", "patch", "parameters":
+		return true
+	case "trace", "summary", "description", "servers":
+		return openAPI3
+	}
+	return false
+}
+
+// overridePrefixes returns the path prefixes of servers, the JSON of the
+// servers of a path item or of an operation, if there are any, and prefixes
+// otherwise.
+func overridePrefixes(prefixes []string, servers json.RawMessage, path string, warn func(string, ...interface{})) []string {
+	if servers == nil {
+		return prefixes
+	}
+
+	var overrides []openAPIServer
+	if err := json.Unmarshal(servers, &overrides); err != nil {
+		warn("skipped servers of path [%s]: %s", path, err)
+		return prefixes
+	}
+	if len(overrides) == 0 {
+		return prefixes
+	}
+	return serverPrefixes(overrides, warn)
+}
+
+// serverPrefixes returns the paths of the URLs of servers, with their
+// variables replaced by their default values, without trailing slashes. The
+// servers whose paths cannot be determined are skipped.
+func serverPrefixes(servers []openAPIServer, warn func(string, ...interface{})) []string {
+	prefixes := []string{}
+	for _, server := range servers {
+		serverURL := server.URL
+		for name, variable := range server.Variables {
+			serverURL = strings.Replace(serverURL, "{"+name+"}", variable.Default, -1)
+		}
+
+		u, err := url.Parse(serverURL)
+		if err != nil {
+			warn("skipped server [%s]: %s", server.URL, err)
+			continue
+		}
+		if strings.ContainsAny(u.Path, "{}") {
+			warn("skipped server [%s]: its path has variables without default values", server.URL)
+			continue
+		}
+		prefixes = append(prefixes, strings.TrimSuffix("/"+strings.Trim(u.Path, "/"), "/"))
+	}
+
+	// the default server of OpenAPI 3 is /
+	if len(prefixes) == 0 {
+		return []string{""}
+	}
+	return prefixes
+}
+
+// prefixesRegex returns the regex matching any of prefixes.
+func prefixesRegex(prefixes []string) string {
+	unique := make(map[string]struct{})
+	for _, prefix := range prefixes {
+		unique[regexp.QuoteMeta(prefix)] = struct{}{}
+	}
+	quoted := make([]string, 0, len(unique))
+	for prefix := range unique {
+		quoted = append(quoted, prefix)
+	}
+	sort.Strings(quoted)
+
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return "(?:" + strings.Join(quoted, "|") + ")"
+}
+
+// pathTemplateRegex returns the regex matching the paths of the path
+// template path, as documented on ProfileFromOpenAPI.
+func pathTemplateRegex(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("paths must start with /")
+	}
+
+	var regex strings.Builder
+	literal := func(s string) error {
+		if strings.ContainsAny(s, "{}") {
+			return fmt.Errorf("unbalanced braces of path parameters")
+		}
+		regex.WriteString(strings.Replace(regexp.QuoteMeta(s), `\*`, `[^/]*`, -1))
+		return nil
+	}
+
+	last := 0
+	for _, match := range pathTemplateParam.FindAllStringIndex(path, -1) {
+		if err := literal(path[last:match[0]]); err != nil {
+			return "", err
+		}
+		switch param := path[match[0]:match[1]]; {
+		case param == "{}":
+			return "", fmt.Errorf("path parameters must be named")
+		case strings.HasSuffix(param, "+}"):
+			regex.WriteString(`.+`)
+		case strings.HasSuffix(param, "*}"):
+			regex.WriteString(`.*`)
+		default:
+			regex.WriteString(`[^/]+`)
+		}
+		last = match[1]
+	}
+	if err := literal(path[last:]); err != nil {
+		return "", err
+	}
+
+	return regex.String(), nil
+}
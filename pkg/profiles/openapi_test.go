@@ -0,0 +1,123 @@
+package profiles
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestProfileFromOpenAPI(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		golden   string
+		warnings []string
+	}{
+		{
+			spec:   "petstore-swagger.json",
+			golden: "petstore-swagger.golden",
+			warnings: []string{
+				"skipped field [trace] of path [/user/{username}]: not an operation",
+			},
+		},
+		{
+			spec:   "petstore-openapi.yaml",
+			golden: "petstore-openapi.golden",
+			warnings: []string{
+				"skipped field [subscribe] of path [/health]: not an operation",
+				"skipped path [/owners/{}]: path parameters must be named",
+				"skipped path [/toys]: path items with $ref are not supported",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.spec, func(t *testing.T) {
+			spec, err := ioutil.ReadFile("testdata/" + tc.spec)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			profile, warnings, err := ProfileFromOpenAPI(spec, "default", "petstore")
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(warnings, tc.warnings) {
+				t.Fatalf("Expected warnings %v, got %v", tc.warnings, warnings)
+			}
+
+			var out bytes.Buffer
+			if err := RenderProfile(profile, &out); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if err := Validate(out.Bytes()); err != nil {
+				t.Fatalf("Expected a valid ServiceProfile, got: %s", err)
+			}
+
+			expected, err := ioutil.ReadFile("testdata/" + tc.golden)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if out.String() != string(expected) {
+				t.Fatalf("Expected ServiceProfile:\n%s\nGot:\n%s", expected, out.String())
+			}
+		})
+	}
+
+	t.Run("Returns a skeleton profile for documents without operations", func(t *testing.T) {
+		profile, warnings, err := ProfileFromOpenAPI([]byte("swagger: '2.0'\npaths: {}\n"), "default", "petstore")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(profile.Spec.Routes) != 0 || profile.Name != "petstore.default.svc.cluster.local" {
+			t.Fatalf("Expected a profile without routes, got %+v", profile)
+		}
+		expected := []string{"no operations found; the ServiceProfile has no routes"}
+		if !reflect.DeepEqual(warnings, expected) {
+			t.Fatalf("Expected warnings %v, got %v", expected, warnings)
+		}
+	})
+
+	t.Run("Rejects documents that are not Swagger 2.0 or OpenAPI 3", func(t *testing.T) {
+		expected := "expected a Swagger 2.0 or OpenAPI 3 document, got swagger [1.2] and openapi []"
+		_, _, err := ProfileFromOpenAPI([]byte("swagger: '1.2'\n"), "default", "petstore")
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s], got [%v]", expected, err)
+		}
+	})
+}
+
+func TestPathTemplateRegex(t *testing.T) {
+	expectations := map[string]string{
+		"/":                          `/`,
+		"/books":                     `/books`,
+		"/books/{id}":                `/books/[^/]+`,
+		"/books/{id}/authors/{name}": `/books/[^/]+/authors/[^/]+`,
+		"/books/{id}.json":           `/books/[^/]+\.json`,
+		"/files/{path+}":             `/files/.+`,
+		"/files/{path*}":             `/files/.*`,
+		"/admin/*/stats":             `/admin/[^/]*/stats`,
+	}
+	for path, expected := range expectations {
+		regex, err := pathTemplateRegex(path)
+		if err != nil {
+			t.Fatalf("Unexpected error for [%s]: %s", path, err)
+		}
+		if regex != expected {
+			t.Fatalf("Expected regex [%s] for [%s], got [%s]", expected, path, regex)
+		}
+	}
+
+	errors := map[string]string{
+		"books":        "paths must start with /",
+		"/books/{id":   "unbalanced braces of path parameters",
+		"/books/{}":    "path parameters must be named",
+		"/books/{a/b}": "unbalanced braces of path parameters",
+	}
+	for path, expected := range errors {
+		if _, err := pathTemplateRegex(path); err == nil || err.Error() != expected {
+			t.Fatalf("Expected error [%s] for [%s], got [%v]", expected, path, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package profiles
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/ghodss/yaml"
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+	"github.com/linkerd/linkerd2/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kind is the kind of the ServiceProfile custom resource.
+const Kind = "ServiceProfile"
+
+// Validate checks that data is the YAML, or JSON, of a valid ServiceProfile:
+// one without unknown fields, which is valid according to
+// ValidateServiceProfile. Errors about a route name it by its index in the
+// routes of the spec.
+func Validate(data []byte) error {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse ServiceProfile: %s", err)
+	}
+
+	// routes are decoded one at a time, so that their errors name them
+	var profile struct {
+		metav1.TypeMeta   `json:",inline"`
+		metav1.ObjectMeta `json:"metadata,omitempty"`
+		Spec              struct {
+			Routes []json.RawMessage `json:"routes"`
+		} `json:"spec"`
+	}
+	if err := decodeStrict(jsonData, &profile); err != nil {
+		return fmt.Errorf("failed to parse ServiceProfile: %s", err)
+	}
+	if profile.APIVersion != sp.SchemeGroupVersion.String() || profile.Kind != Kind {
+		return fmt.Errorf("expected a %s of apiVersion [%s], got a [%s] of apiVersion [%s]",
+			Kind, sp.SchemeGroupVersion, profile.Kind, profile.APIVersion)
+	}
+
+	serviceProfile := &sp.ServiceProfile{
+		TypeMeta:   profile.TypeMeta,
+		ObjectMeta: profile.ObjectMeta,
+	}
+	for i, data := range profile.Spec.Routes {
+		var route *sp.RouteSpec
+		if err := decodeStrict(data, &route); err != nil {
+			return fmt.Errorf("spec.routes[%d]: %s", i, err)
+		}
+		serviceProfile.Spec.Routes = append(serviceProfile.Spec.Routes, route)
+	}
+
+	return ValidateServiceProfile(serviceProfile)
+}
+
+// ValidateServiceProfile checks that profile is named, and that its routes
+// are named uniquely and have a condition, whose path regex, if any, is a
+// valid regular expression.
+func ValidateServiceProfile(profile *sp.ServiceProfile) error {
+	if profile.Name == "" {
+		return fmt.Errorf("metadata.name is required")
+	}
+
+	routeIndexes := make(map[string]int)
+	for i, route := range profile.Spec.Routes {
+		if route == nil || route.Name == "" {
+			return fmt.Errorf("spec.routes[%d]: name is required", i)
+		}
+		if j, ok := routeIndexes[route.Name]; ok {
+			return fmt.Errorf("spec.routes[%d]: duplicate route name [%s], already the name of spec.routes[%d]", i, route.Name, j)
+		}
+		routeIndexes[route.Name] = i
+
+		if route.Condition == nil {
+			return fmt.Errorf("spec.routes[%d] [%s]: condition is required", i, route.Name)
+		}
+		if _, err := regexp.Compile(route.Condition.PathRegex); err != nil {
+			return fmt.Errorf("spec.routes[%d] [%s]: invalid condition.pathRegex [%s]: %s", i, route.Name, route.Condition.PathRegex, err)
+		}
+	}
+
+	return nil
+}
+
+// newServiceProfile returns the ServiceProfile of the service named service
+// in namespace, without routes.
+func newServiceProfile(namespace, service string) *sp.ServiceProfile {
+	return &sp.ServiceProfile{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: sp.SchemeGroupVersion.String(),
+			Kind:       Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      k8s.ServiceProfileName(service, namespace),
+			Namespace: namespace,
+		},
+		Spec: sp.ServiceProfileSpec{
+			Routes: []*sp.RouteSpec{},
+		},
+	}
+}
+
+// RenderProfile writes profile to w in YAML, ready to be applied. Only the
+// name and namespace of its metadata are written.
+func RenderProfile(profile *sp.ServiceProfile, w io.Writer) error {
+	routes := profile.Spec.Routes
+	if routes == nil {
+		routes = []*sp.RouteSpec{}
+	}
+
+	// the zero creationTimestamp of ObjectMeta would be written as null
+	out, err := yaml.Marshal(struct {
+		metav1.TypeMeta `json:",inline"`
+		Metadata        struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Spec sp.ServiceProfileSpec `json:"spec"`
+	}{
+		TypeMeta: profile.TypeMeta,
+		Metadata: struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		}{profile.Name, profile.Namespace},
+		Spec: sp.ServiceProfileSpec{Routes: routes},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// decodeStrict decodes the JSON data into v, failing on the fields of data
+// that v does not have.
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
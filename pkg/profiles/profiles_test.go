@@ -0,0 +1,218 @@
+package profiles
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const booksProfile = `apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.default.svc.cluster.local
+  namespace: default
+spec:
+  routes:
+  - condition:
+      method: GET
+      pathRegex: /books/\d+
+    name: GET /books/{id}
+  - condition:
+      method: POST
+      pathRegex: /books
+    name: POST /books
+`
+
+func TestServiceProfileSerialization(t *testing.T) {
+	expected := &sp.ServiceProfile{
+		TypeMeta: metav1.TypeMeta{APIVersion: "linkerd.io/v1alpha1", Kind: "ServiceProfile"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "books.default.svc.cluster.local",
+			Namespace: "default",
+		},
+		Spec: sp.ServiceProfileSpec{
+			Routes: []*sp.RouteSpec{
+				{Name: "GET /books/{id}", Condition: &sp.RequestMatch{PathRegex: `/books/\d+`, Method: "GET"}},
+				{Name: "POST /books", Condition: &sp.RequestMatch{PathRegex: "/books", Method: "POST"}},
+			},
+		},
+	}
+
+	t.Run("Round-trips ServiceProfiles through YAML", func(t *testing.T) {
+		var profile sp.ServiceProfile
+		if err := yaml.Unmarshal([]byte(booksProfile), &profile); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !reflect.DeepEqual(&profile, expected) {
+			t.Fatalf("Expected ServiceProfile %+v, got %+v", expected, profile)
+		}
+
+		out, err := yaml.Marshal(&profile)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		// the creationTimestamp of ObjectMeta is always serialized
+		expectedOut := bytes.Replace([]byte(booksProfile), []byte("metadata:\n"), []byte("metadata:\n  creationTimestamp: null\n"), 1)
+		if string(out) != string(expectedOut) {
+			t.Fatalf("Expected YAML:\n%s\nGot:\n%s", expectedOut, out)
+		}
+	})
+
+	t.Run("Deep copies ServiceProfiles", func(t *testing.T) {
+		profile := expected.DeepCopy()
+		if !reflect.DeepEqual(profile, expected) {
+			t.Fatalf("Expected copy %+v, got %+v", expected, profile)
+		}
+
+		profile.Spec.Routes[0].Condition.Method = "PUT"
+		if expected.Spec.Routes[0].Condition.Method != "GET" {
+			t.Fatalf("Expected the copy not to share the conditions of its routes")
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	header := `apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: books.default.svc.cluster.local
+  namespace: default
+`
+
+	testCases := []struct {
+		name     string
+		profile  string
+		expected string
+	}{
+		{
+			name:    "a valid profile",
+			profile: booksProfile,
+		},
+		{
+			name:    "a profile without routes",
+			profile: header + "spec:\n  routes: []\n",
+		},
+		{
+			name:     "a profile of another kind",
+			profile:  "apiVersion: v1\nkind: Service\nmetadata:\n  name: books\n",
+			expected: "expected a ServiceProfile of apiVersion [linkerd.io/v1alpha1], got a [Service] of apiVersion [v1]",
+		},
+		{
+			name:     "a profile without name",
+			profile:  "apiVersion: linkerd.io/v1alpha1\nkind: ServiceProfile\nspec:\n  routes: []\n",
+			expected: "metadata.name is required",
+		},
+		{
+			name:     "an unknown field of the spec",
+			profile:  header + "spec:\n  routes: []\n  retries: 3\n",
+			expected: `failed to parse ServiceProfile: json: unknown field "retries"`,
+		},
+		{
+			name: "an unknown field of a route",
+			profile: header + `spec:
+  routes:
+  - name: GET /books
+    condition:
+      method: GET
+  - name: POST /books
+    timeout: 10s
+    condition:
+      method: POST
+`,
+			expected: `spec.routes[1]: json: unknown field "timeout"`,
+		},
+		{
+			name: "an unknown field of a condition",
+			profile: header + `spec:
+  routes:
+  - name: GET /books
+    condition:
+      path: /books
+`,
+			expected: `spec.routes[0]: json: unknown field "path"`,
+		},
+		{
+			name: "a route without name",
+			profile: header + `spec:
+  routes:
+  - condition:
+      method: GET
+`,
+			expected: "spec.routes[0]: name is required",
+		},
+		{
+			name: "duplicate route names",
+			profile: header + `spec:
+  routes:
+  - name: books
+    condition:
+      method: GET
+  - name: authors
+    condition:
+      method: GET
+  - name: books
+    condition:
+      method: POST
+`,
+			expected: "spec.routes[2]: duplicate route name [books], already the name of spec.routes[0]",
+		},
+		{
+			name: "a route without condition",
+			profile: header + `spec:
+  routes:
+  - name: GET /books
+`,
+			expected: "spec.routes[0] [GET /books]: condition is required",
+		},
+		{
+			name: "an invalid path regex",
+			profile: header + `spec:
+  routes:
+  - name: GET /books
+    condition:
+      pathRegex: /books/(\d+
+`,
+			expected: "spec.routes[0] [GET /books]: invalid condition.pathRegex [/books/(\\d+]: error parsing regexp: missing closing ): `/books/(\\d+`",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate([]byte(tc.profile))
+			if tc.expected == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.expected {
+				t.Fatalf("Expected error [%s], got [%v]", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	t.Run("Renders a valid ServiceProfile of the service", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := RenderTemplate("library", "books", &buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := Validate(buf.Bytes()); err != nil {
+			t.Fatalf("Expected the template to be valid, got: %s", err)
+		}
+
+		var profile sp.ServiceProfile
+		if err := yaml.Unmarshal(buf.Bytes(), &profile); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if profile.Name != "books.library.svc.cluster.local" || profile.Namespace != "library" {
+			t.Fatalf("Expected the profile of books in library, got %s in %s", profile.Name, profile.Namespace)
+		}
+	})
+}
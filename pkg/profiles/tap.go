@@ -0,0 +1,133 @@
+package profiles
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	sp "github.com/linkerd/linkerd2/controller/gen/apis/serviceprofile/v1alpha1"
+)
+
+// pathParams are the path segments that are collapsed into the parameters of
+// path templates, in the order they are tried. Hashes must be long enough
+// not to be mistaken for words made of hexadecimal letters, such as "cafe".
+var pathParams = []struct {
+	name    string
+	segment *regexp.Regexp
+}{
+	{"{id}", regexp.MustCompile(`^[0-9]+$`)},
+	{"{uuid}", regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)},
+	{"{date}", regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}([Tt][0-9:.]+([Zz]|[+-][0-9:]+)?)?$`)},
+	{"{hash}", regexp.MustCompile(`^[0-9a-fA-F]*[0-9][0-9a-fA-F]*$`)},
+}
+
+// minHashLength is the shortest hexadecimal segment collapsed into {hash}.
+const minHashLength = 16
+
+// Request is a request observed by tap, by which the routes of a
+// ServiceProfile are built.
+type Request struct {
+	Method string
+	Path   string
+}
+
+// routeCandidate is a route of the requests that have the same method and
+// path template.
+type routeCandidate struct {
+	method    string
+	template  string
+	pathRegex string
+	count     int
+}
+
+func (r *routeCandidate) name() string {
+	return r.method + " " + r.template
+}
+
+// ProfileFromRequests returns the ServiceProfile of the service named service
+// in namespace whose routes are the routeLimit routes of the most requests.
+// Requests have the same route if they have the same method and path
+// template: their paths, without query, where numbers, UUIDs, dates and
+// hexadecimal hashes are collapsed into the {id}, {uuid}, {date} and {hash}
+// parameters, which match any path segment. Routes of as many requests are
+// ordered by name, so that the same requests always have the same profile.
+// With no requests, the profile has no routes, and a warning is returned.
+func ProfileFromRequests(requests []Request, namespace, service string, routeLimit int) (*sp.ServiceProfile, []string) {
+	candidates := make(map[string]*routeCandidate)
+	for _, req := range requests {
+		template, pathRegex := pathTemplate(req.Path)
+		candidate := &routeCandidate{method: strings.ToUpper(req.Method), template: template, pathRegex: pathRegex}
+		if existing, ok := candidates[candidate.name()]; ok {
+			candidate = existing
+		} else {
+			candidates[candidate.name()] = candidate
+		}
+		candidate.count++
+	}
+
+	sorted := make([]*routeCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		sorted = append(sorted, candidate)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].count != sorted[j].count {
+			return sorted[i].count > sorted[j].count
+		}
+		return sorted[i].name() < sorted[j].name()
+	})
+	if len(sorted) > routeLimit {
+		sorted = sorted[:routeLimit]
+	}
+
+	profile := newServiceProfile(namespace, service)
+	for _, candidate := range sorted {
+		profile.Spec.Routes = append(profile.Spec.Routes, &sp.RouteSpec{
+			Name: candidate.name(),
+			Condition: &sp.RequestMatch{
+				PathRegex: candidate.pathRegex,
+				Method:    candidate.method,
+			},
+		})
+	}
+
+	var warnings []string
+	if len(requests) == 0 {
+		warnings = append(warnings, fmt.Sprintf("no requests observed; the ServiceProfile of [%s] has no routes", service))
+	}
+	return profile, warnings
+}
+
+// pathTemplate returns the template of path, without its query, and the
+// anchored regex matching the paths of the template.
+func pathTemplate(path string) (string, string) {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	segments := strings.Split(path, "/")
+	regexSegments := make([]string, len(segments))
+	for i, segment := range segments {
+		regexSegments[i] = regexp.QuoteMeta(segment)
+		if param := pathParam(segment); param != "" {
+			segments[i] = param
+			regexSegments[i] = `[^/]+`
+		}
+	}
+
+	return strings.Join(segments, "/"), "^" + strings.Join(regexSegments, "/") + "$"
+}
+
+// pathParam returns the parameter that segment is collapsed into, if any.
+func pathParam(segment string) string {
+	for _, param := range pathParams {
+		if !param.segment.MatchString(segment) {
+			continue
+		}
+		if param.name == "{hash}" && len(segment) < minHashLength {
+			continue
+		}
+		return param.name
+	}
+	return ""
+}
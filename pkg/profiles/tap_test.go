@@ -0,0 +1,103 @@
+package profiles
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPathTemplate(t *testing.T) {
+	expectations := []struct {
+		path      string
+		template  string
+		pathRegex string
+	}{
+		{"/", "/", `^/$`},
+		{"/books", "/books", `^/books$`},
+		{"/books/123", "/books/{id}", `^/books/[^/]+$`},
+		{"/books/123/authors/4", "/books/{id}/authors/{id}", `^/books/[^/]+/authors/[^/]+$`},
+		{"/books/123?format=json#top", "/books/{id}", `^/books/[^/]+$`},
+		{"/users/7c9e6679-7425-40de-944b-e07fc1f90ae7", "/users/{uuid}", `^/users/[^/]+$`},
+		{"/users/7C9E6679-7425-40DE-944B-E07FC1F90AE7/avatar", "/users/{uuid}/avatar", `^/users/[^/]+/avatar$`},
+		{"/reports/2018-10-14", "/reports/{date}", `^/reports/[^/]+$`},
+		{"/events/2018-10-14T10:20:30Z/details", "/events/{date}/details", `^/events/[^/]+/details$`},
+		{"/events/2018-10-14t10:20:30.123+02:00", "/events/{date}", `^/events/[^/]+$`},
+		{"/commits/da39a3ee5e6b4b0d3255bfef95601890afd80709", "/commits/{hash}", `^/commits/[^/]+$`},
+		{"/blobs/d41d8cd98f00b204e9800998ecf8427e/raw", "/blobs/{hash}/raw", `^/blobs/[^/]+/raw$`},
+		// hexadecimal words, short hashes and versions are not parameters
+		{"/cafe/deadbeefdeadbeef", "/cafe/deadbeefdeadbeef", `^/cafe/deadbeefdeadbeef$`},
+		{"/commits/da39a3e", "/commits/da39a3e", `^/commits/da39a3e$`},
+		{"/api/v1/books", "/api/v1/books", `^/api/v1/books$`},
+		{"/files/book.v2.json", "/files/book.v2.json", `^/files/book\.v2\.json$`},
+	}
+
+	for _, exp := range expectations {
+		template, pathRegex := pathTemplate(exp.path)
+		if template != exp.template || pathRegex != exp.pathRegex {
+			t.Fatalf("Expected [%s] to have template [%s] and regex [%s], got [%s] and [%s]", exp.path, exp.template, exp.pathRegex, template, pathRegex)
+		}
+	}
+}
+
+func TestProfileFromRequests(t *testing.T) {
+	requests := []Request{
+		{"GET", "/books/1"},
+		{"GET", "/books/2"},
+		{"GET", "/books/3?format=json"},
+		{"POST", "/books"},
+		{"POST", "/books"},
+		{"GET", "/authors"},
+		{"GET", "/authors"},
+		{"DELETE", "/books/4"},
+		{"get", "/health"},
+	}
+
+	routeNames := func(requests []Request, limit int) []string {
+		profile, warnings := ProfileFromRequests(requests, "default", "books", limit)
+		if len(warnings) != 0 {
+			t.Fatalf("Unexpected warnings: %v", warnings)
+		}
+		if err := ValidateServiceProfile(profile); err != nil {
+			t.Fatalf("Expected a valid ServiceProfile, got: %s", err)
+		}
+		var names []string
+		for _, route := range profile.Spec.Routes {
+			names = append(names, route.Name)
+		}
+		return names
+	}
+
+	t.Run("Returns the routes of the most requests, then by name", func(t *testing.T) {
+		expected := []string{"GET /books/{id}", "GET /authors", "POST /books", "DELETE /books/{id}", "GET /health"}
+		if names := routeNames(requests, 20); !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Expected routes %v, got %v", expected, names)
+		}
+	})
+
+	t.Run("Returns at most the route limit", func(t *testing.T) {
+		expected := []string{"GET /books/{id}", "GET /authors"}
+		if names := routeNames(requests, 2); !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Expected routes %v, got %v", expected, names)
+		}
+	})
+
+	t.Run("Returns the same routes whatever the order of the requests", func(t *testing.T) {
+		reversed := make([]Request, len(requests))
+		for i, req := range requests {
+			reversed[len(requests)-1-i] = req
+		}
+		if names, expected := routeNames(reversed, 20), routeNames(requests, 20); !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Expected routes %v, got %v", expected, names)
+		}
+	})
+
+	t.Run("Returns a skeleton profile with a warning without requests", func(t *testing.T) {
+		profile, warnings := ProfileFromRequests(nil, "default", "books", 20)
+		if profile.Name != "books.default.svc.cluster.local" || len(profile.Spec.Routes) != 0 {
+			t.Fatalf("Expected the profile of books without routes, got %+v", profile)
+		}
+		expected := []string{"no requests observed; the ServiceProfile of [books] has no routes"}
+		if !reflect.DeepEqual(warnings, expected) {
+			t.Fatalf("Expected warnings %v, got %v", expected, warnings)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package profiles
+
+import (
+	"io"
+	"text/template"
+
+	"github.com/linkerd/linkerd2/pkg/k8s"
+)
+
+// Template is the template of the ServiceProfile of a service, whose routes
+// are examples to be edited.
+const Template = `### ServiceProfile for {{.ServiceName}}.{{.ServiceNamespace}} ###
+apiVersion: linkerd.io/v1alpha1
+kind: ServiceProfile
+metadata:
+  name: {{.ProfileName}}
+  namespace: {{.ServiceNamespace}}
+spec:
+  # A service profile defines a list of routes. Linkerd reports metrics like
+  # request volume, latency, and success rate by route.
+  routes:
+  - name: '/authors/{id}'
+
+    # Each route must define a condition. The requests that match the
+    # condition are counted as belonging to that route.
+    condition:
+      # The path of the request must match this regular expression.
+      pathRegex: '/authors/\d+'
+
+      # The method of the request must be this one.
+      method: GET
+
+  # Route names must be unique within a profile.
+  - name: 'POST /authors'
+    condition:
+      pathRegex: '/authors'
+      method: POST
+`
+
+type templateConfig struct {
+	ServiceName      string
+	ServiceNamespace string
+	ProfileName      string
+}
+
+// RenderTemplate writes the Template of the ServiceProfile of the service
+// named service in namespace to w.
+func RenderTemplate(namespace, service string, w io.Writer) error {
+	tmpl, err := template.New("profile").Parse(Template)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, templateConfig{
+		ServiceName:      service,
+		ServiceNamespace: namespace,
+		ProfileName:      k8s.ServiceProfileName(service, namespace),
+	})
+}
@@ -0,0 +1,116 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pb "github.com/linkerd/linkerd2/controller/gen/public"
+)
+
+// releaseVersionFormat matches release versions such as "stable-2.0.0" and
+// "edge-18.8.1".
+var releaseVersionFormat = regexp.MustCompile(`^(?:([a-z]+)-)?v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// Skew describes how the versions of the CLI and the control plane differ.
+type Skew struct {
+	ClientVersion string
+	ServerVersion string
+
+	// Comparable is false if either version is a development version, such as
+	// "dev-0e7a9b3e-jane" or "git-0e7a9b3e", or can't be parsed. Development
+	// versions are never reported as skewed.
+	Comparable bool
+
+	// ChannelDiffers, MajorDiffers and MinorDiffers report which parts of the
+	// versions differ. They are only set if Comparable is true.
+	ChannelDiffers bool
+	MajorDiffers   bool
+	MinorDiffers   bool
+}
+
+// Err returns an error if the CLI and the control plane are on different
+// major versions of the same release channel, which are not expected to work
+// together. Versions of different channels are numbered independently, so
+// their major versions are not compared.
+func (s *Skew) Err() error {
+	if s.Comparable && !s.ChannelDiffers && s.MajorDiffers {
+		return fmt.Errorf("cli is running version %s but the control plane is running version %s, which is incompatible",
+			s.ClientVersion, s.ServerVersion)
+	}
+	return nil
+}
+
+// Warning describes a difference in release channels or minor versions
+// between the CLI and the control plane, which usually work together but may
+// not support the same features. It is empty if neither differs, or if Err
+// returns an error.
+func (s *Skew) Warning() string {
+	switch {
+	case !s.Comparable:
+		return ""
+	case s.ChannelDiffers:
+		return fmt.Sprintf("cli is running version %s but the control plane is running version %s, from a different release channel",
+			s.ClientVersion, s.ServerVersion)
+	case !s.MajorDiffers && s.MinorDiffers:
+		return fmt.Sprintf("cli is running version %s but the control plane is running version %s",
+			s.ClientVersion, s.ServerVersion)
+	}
+	return ""
+}
+
+// GetSkew compares the version of the CLI to the version of the control
+// plane.
+func GetSkew(clientVersion, serverVersion string) *Skew {
+	skew := &Skew{
+		ClientVersion: clientVersion,
+		ServerVersion: serverVersion,
+	}
+
+	clientChannel, clientMajor, clientMinor, clientErr := parseReleaseVersion(clientVersion)
+	serverChannel, serverMajor, serverMinor, serverErr := parseReleaseVersion(serverVersion)
+	if clientErr != nil || serverErr != nil {
+		return skew
+	}
+
+	skew.Comparable = true
+	skew.ChannelDiffers = clientChannel != serverChannel
+	skew.MajorDiffers = clientMajor != serverMajor
+	skew.MinorDiffers = clientMinor != serverMinor
+	return skew
+}
+
+// GetServerSkew fetches the version of the control plane from the public API,
+// and compares it to the version of the CLI.
+func GetServerSkew(ctx context.Context, apiClient pb.ApiClient) (*Skew, error) {
+	rsp, err := apiClient.Version(ctx, &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSkew(Version, rsp.GetReleaseVersion()), nil
+}
+
+func parseReleaseVersion(v string) (string, int, int, error) {
+	if v == undefinedVersion || strings.HasPrefix(v, "dev-") || strings.HasPrefix(v, "git-") {
+		return "", 0, 0, fmt.Errorf("development version [%s]", v)
+	}
+
+	match := releaseVersionFormat.FindStringSubmatch(v)
+	if match == nil {
+		return "", 0, 0, fmt.Errorf("unknown version format [%s]", v)
+	}
+
+	major, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, 0, err
+	}
+	minor, err := strconv.Atoi(match[3])
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	return match[1], major, minor, nil
+}
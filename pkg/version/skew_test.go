@@ -0,0 +1,102 @@
+package version_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/linkerd/linkerd2/pkg/version"
+)
+
+func TestGetSkew(t *testing.T) {
+	testCases := []struct {
+		clientVersion string
+		serverVersion string
+		err           string
+		warning       string
+	}{
+		{
+			clientVersion: "stable-2.0.0",
+			serverVersion: "stable-2.0.1",
+		},
+		{
+			clientVersion: "stable-2.0.0",
+			serverVersion: "stable-2.1.0",
+			warning:       "cli is running version stable-2.0.0 but the control plane is running version stable-2.1.0",
+		},
+		{
+			clientVersion: "stable-2.0.0",
+			serverVersion: "stable-3.0.0",
+			err:           "cli is running version stable-2.0.0 but the control plane is running version stable-3.0.0, which is incompatible",
+		},
+		{
+			clientVersion: "edge-18.8.1",
+			serverVersion: "stable-2.0.0",
+			warning:       "cli is running version edge-18.8.1 but the control plane is running version stable-2.0.0, from a different release channel",
+		},
+		{
+			clientVersion: "edge-2.0.0",
+			serverVersion: "stable-2.0.0",
+			warning:       "cli is running version edge-2.0.0 but the control plane is running version stable-2.0.0, from a different release channel",
+		},
+		{
+			clientVersion: "stable-2.1.0",
+			serverVersion: "edge-2.0.0",
+			warning:       "cli is running version stable-2.1.0 but the control plane is running version edge-2.0.0, from a different release channel",
+		},
+		{
+			clientVersion: "edge-18.8.1",
+			serverVersion: "edge-19.1.1",
+			err:           "cli is running version edge-18.8.1 but the control plane is running version edge-19.1.1, which is incompatible",
+		},
+		{
+			clientVersion: "dev-0e7a9b3e-jane",
+			serverVersion: "stable-2.0.0",
+		},
+		{
+			clientVersion: "stable-2.0.0",
+			serverVersion: "git-0e7a9b3e",
+		},
+		{
+			clientVersion: "undefined",
+			serverVersion: "stable-2.0.0",
+		},
+		{
+			clientVersion: "stable-2.0.0",
+			serverVersion: "garbage",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.clientVersion+" vs "+tc.serverVersion, func(t *testing.T) {
+			skew := version.GetSkew(tc.clientVersion, tc.serverVersion)
+
+			err := skew.Err()
+			if tc.err == "" && err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if tc.err != "" && (err == nil || err.Error() != tc.err) {
+				t.Fatalf("Expected error [%s], got [%v]", tc.err, err)
+			}
+
+			if warning := skew.Warning(); warning != tc.warning {
+				t.Fatalf("Expected warning [%s], got [%s]", tc.warning, warning)
+			}
+		})
+	}
+}
+
+func TestGetServerSkew(t *testing.T) {
+	t.Run("Compares the control plane version to the CLI version", func(t *testing.T) {
+		apiClient := createMockPublicApi("stable-2.1.0")
+
+		skew, err := version.GetServerSkew(context.Background(), apiClient)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if skew.ClientVersion != version.Version || skew.ServerVersion != "stable-2.1.0" {
+			t.Fatalf("Expected versions [%s] and [stable-2.1.0], got [%s] and [%s]",
+				version.Version, skew.ClientVersion, skew.ServerVersion)
+		}
+	})
+}
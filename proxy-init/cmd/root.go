@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/linkerd/linkerd2/proxy-init/iptables"
 	"github.com/spf13/cobra"
@@ -12,8 +14,8 @@ type rootOptions struct {
 	outgoingProxyPort     int
 	proxyUserId           int
 	portsToRedirect       []int
-	inboundPortsToIgnore  []int
-	outboundPortsToIgnore []int
+	inboundPortsToIgnore  []string
+	outboundPortsToIgnore []string
 	simulateOnly          bool
 }
 
@@ -23,8 +25,8 @@ func newRootOptions() *rootOptions {
 		outgoingProxyPort:     -1,
 		proxyUserId:           -1,
 		portsToRedirect:       make([]int, 0),
-		inboundPortsToIgnore:  make([]int, 0),
-		outboundPortsToIgnore: make([]int, 0),
+		inboundPortsToIgnore:  make([]string, 0),
+		outboundPortsToIgnore: make([]string, 0),
 		simulateOnly:          false,
 	}
 }
@@ -49,8 +51,8 @@ func NewRootCmd() *cobra.Command {
 	cmd.PersistentFlags().IntVarP(&options.outgoingProxyPort, "outgoing-proxy-port", "o", options.outgoingProxyPort, "Port to redirect outgoing traffic")
 	cmd.PersistentFlags().IntVarP(&options.proxyUserId, "proxy-uid", "u", options.proxyUserId, "User ID that the proxy is running under. Any traffic coming from this user will be ignored to avoid infinite redirection loops.")
 	cmd.PersistentFlags().IntSliceVarP(&options.portsToRedirect, "ports-to-redirect", "r", options.portsToRedirect, "Port to redirect to proxy, if no port is specified then ALL ports are redirected")
-	cmd.PersistentFlags().IntSliceVar(&options.inboundPortsToIgnore, "inbound-ports-to-ignore", options.inboundPortsToIgnore, "Inbound ports to ignore and not redirect to proxy. This has higher precedence than any other parameters.")
-	cmd.PersistentFlags().IntSliceVar(&options.outboundPortsToIgnore, "outbound-ports-to-ignore", options.outboundPortsToIgnore, "Outbound ports to ignore and not redirect to proxy. This has higher precedence than any other parameters.")
+	cmd.PersistentFlags().StringSliceVar(&options.inboundPortsToIgnore, "inbound-ports-to-ignore", options.inboundPortsToIgnore, "Inbound ports and ranges of ports (e.g. 4222-4223) to ignore and not redirect to proxy. This has higher precedence than any other parameters.")
+	cmd.PersistentFlags().StringSliceVar(&options.outboundPortsToIgnore, "outbound-ports-to-ignore", options.outboundPortsToIgnore, "Outbound ports and ranges of ports (e.g. 4222-4223) to ignore and not redirect to proxy. This has higher precedence than any other parameters.")
 	cmd.PersistentFlags().BoolVar(&options.simulateOnly, "simulate", options.simulateOnly, "Don't execute any command, just print what would be executed")
 
 	return cmd
@@ -65,13 +67,23 @@ func buildFirewallConfiguration(options *rootOptions) (*iptables.FirewallConfigu
 		return nil, fmt.Errorf("--outgoing-proxy-port must be a valid TCP port number")
 	}
 
+	inboundPortsToIgnore, err := parsePortRanges(options.inboundPortsToIgnore)
+	if err != nil {
+		return nil, fmt.Errorf("--inbound-ports-to-ignore: %s", err)
+	}
+
+	outboundPortsToIgnore, err := parsePortRanges(options.outboundPortsToIgnore)
+	if err != nil {
+		return nil, fmt.Errorf("--outbound-ports-to-ignore: %s", err)
+	}
+
 	firewallConfiguration := &iptables.FirewallConfiguration{
 		ProxyInboundPort:       options.incomingProxyPort,
 		ProxyOutgoingPort:      options.outgoingProxyPort,
 		ProxyUid:               options.proxyUserId,
 		PortsToRedirectInbound: options.portsToRedirect,
-		InboundPortsToIgnore:   options.inboundPortsToIgnore,
-		OutboundPortsToIgnore:  options.outboundPortsToIgnore,
+		InboundPortsToIgnore:   inboundPortsToIgnore,
+		OutboundPortsToIgnore:  outboundPortsToIgnore,
 		SimulateOnly:           options.simulateOnly,
 	}
 
@@ -83,3 +95,25 @@ func buildFirewallConfiguration(options *rootOptions) (*iptables.FirewallConfigu
 
 	return firewallConfiguration, nil
 }
+
+// parsePortRanges parses ports, e.g. "25", and ranges of ports, e.g.
+// "4222-4223".
+func parsePortRanges(specs []string) ([]iptables.PortRange, error) {
+	ranges := make([]iptables.PortRange, 0, len(specs))
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "-", 2)
+		lower, err := strconv.Atoi(parts[0])
+		if err != nil || lower < 1 || lower > 65535 {
+			return nil, fmt.Errorf("[%s] is not a valid TCP port or range of ports", spec)
+		}
+		upper := lower
+		if len(parts) == 2 {
+			upper, err = strconv.Atoi(parts[1])
+			if err != nil || upper < lower || upper > 65535 {
+				return nil, fmt.Errorf("[%s] is not a valid TCP port or range of ports", spec)
+			}
+		}
+		ranges = append(ranges, iptables.PortRange{Lower: lower, Upper: upper})
+	}
+	return ranges, nil
+}
@@ -15,8 +15,8 @@ func TestBuildFirewallConfiguration(t *testing.T) {
 		expectedConfig := &iptables.FirewallConfiguration{
 			Mode: iptables.RedirectAllMode,
 			PortsToRedirectInbound: make([]int, 0),
-			InboundPortsToIgnore:   make([]int, 0),
-			OutboundPortsToIgnore:  make([]int, 0),
+			InboundPortsToIgnore:   make([]iptables.PortRange, 0),
+			OutboundPortsToIgnore:  make([]iptables.PortRange, 0),
 			ProxyInboundPort:       expectedIncomingProxyPort,
 			ProxyOutgoingPort:      expectedOutgoingProxyPort,
 			ProxyUid:               expectedProxyUserId,
@@ -38,6 +38,28 @@ func TestBuildFirewallConfiguration(t *testing.T) {
 		}
 	})
 
+	t.Run("It parses the ports and ranges of ports to ignore", func(t *testing.T) {
+		options := newRootOptions()
+		options.incomingProxyPort = 1234
+		options.outgoingProxyPort = 2345
+		options.inboundPortsToIgnore = []string{"25", "4222-4223"}
+		options.outboundPortsToIgnore = []string{"3306"}
+
+		config, err := buildFirewallConfiguration(options)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		expectedInbound := []iptables.PortRange{{Lower: 25, Upper: 25}, {Lower: 4222, Upper: 4223}}
+		if !reflect.DeepEqual(config.InboundPortsToIgnore, expectedInbound) {
+			t.Fatalf("Expected inbound ports to ignore [%v] but got [%v]", expectedInbound, config.InboundPortsToIgnore)
+		}
+		expectedOutbound := []iptables.PortRange{{Lower: 3306, Upper: 3306}}
+		if !reflect.DeepEqual(config.OutboundPortsToIgnore, expectedOutbound) {
+			t.Fatalf("Expected outbound ports to ignore [%v] but got [%v]", expectedOutbound, config.OutboundPortsToIgnore)
+		}
+	})
+
 	t.Run("It rejects invalid config options", func(t *testing.T) {
 		for _, tt := range []struct {
 			options      *rootOptions
@@ -71,6 +93,22 @@ func TestBuildFirewallConfiguration(t *testing.T) {
 				},
 				errorMessage: "--outgoing-proxy-port must be a valid TCP port number",
 			},
+			{
+				options: &rootOptions{
+					incomingProxyPort:    1234,
+					outgoingProxyPort:    2345,
+					inboundPortsToIgnore: []string{"http"},
+				},
+				errorMessage: "--inbound-ports-to-ignore: [http] is not a valid TCP port or range of ports",
+			},
+			{
+				options: &rootOptions{
+					incomingProxyPort:     1234,
+					outgoingProxyPort:     2345,
+					outboundPortsToIgnore: []string{"4223-4222"},
+				},
+				errorMessage: "--outbound-ports-to-ignore: [4223-4222] is not a valid TCP port or range of ports",
+			},
 		} {
 			_, err := buildFirewallConfiguration(tt.options)
 			if err == nil {
@@ -20,11 +20,25 @@ var (
 	ExecutionTraceId = strconv.Itoa(int(time.Now().Unix()))
 )
 
+// PortRange is a range of ports, from Lower to Upper inclusive. A single port
+// is a range whose Lower and Upper are equal.
+type PortRange struct {
+	Lower int
+	Upper int
+}
+
+func (r PortRange) String() string {
+	if r.Lower == r.Upper {
+		return strconv.Itoa(r.Lower)
+	}
+	return fmt.Sprintf("%d-%d", r.Lower, r.Upper)
+}
+
 type FirewallConfiguration struct {
 	Mode                   string
 	PortsToRedirectInbound []int
-	InboundPortsToIgnore   []int
-	OutboundPortsToIgnore  []int
+	InboundPortsToIgnore   []PortRange
+	OutboundPortsToIgnore  []PortRange
 	ProxyInboundPort       int
 	ProxyOutgoingPort      int
 	ProxyUid               int
@@ -134,11 +148,11 @@ func addRulesForInboundPortRedirect(firewallConfiguration FirewallConfiguration,
 	return commands
 }
 
-func addRulesForIgnoredPorts(portsToIgnore []int, chainName string, commands []*exec.Cmd) []*exec.Cmd {
-	for _, ignoredPort := range portsToIgnore {
-		log.Printf("Will ignore port %d on chain %s", ignoredPort, chainName)
+func addRulesForIgnoredPorts(portsToIgnore []PortRange, chainName string, commands []*exec.Cmd) []*exec.Cmd {
+	for _, ignoredPorts := range portsToIgnore {
+		log.Printf("Will ignore port %s on chain %s", ignoredPorts, chainName)
 
-		commands = append(commands, makeIgnorePort(chainName, ignoredPort, fmt.Sprintf("ignore-port-%d", ignoredPort)))
+		commands = append(commands, makeIgnorePort(chainName, ignoredPorts, fmt.Sprintf("ignore-port-%s", ignoredPorts)))
 	}
 	return commands
 }
@@ -199,12 +213,17 @@ func makeRedirectChainToPort(chainName string, portToRedirect int, comment strin
 		"--comment", formatComment(comment))
 }
 
-func makeIgnorePort(chainName string, portToIgnore int, comment string) *exec.Cmd {
+func makeIgnorePort(chainName string, portsToIgnore PortRange, comment string) *exec.Cmd {
+	// iptables takes a range of ports as "lower:upper".
+	destinationPort := strconv.Itoa(portsToIgnore.Lower)
+	if portsToIgnore.Upper != portsToIgnore.Lower {
+		destinationPort = fmt.Sprintf("%d:%d", portsToIgnore.Lower, portsToIgnore.Upper)
+	}
 	return exec.Command("iptables",
 		"-t", "nat",
 		"-A", chainName,
 		"-p", "tcp",
-		"--destination-port", strconv.Itoa(portToIgnore),
+		"--destination-port", destinationPort,
 		"-j", "RETURN",
 		"-m", "comment",
 		"--comment", formatComment(comment))
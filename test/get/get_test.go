@@ -46,7 +46,7 @@ var (
 //////////////////////
 
 func TestCliGet(t *testing.T) {
-	out, err := TestHelper.LinkerdRun("inject", "testdata/to_be_injected_application.yaml")
+	out, err := TestHelper.LinkerdRunOutput("inject", "testdata/to_be_injected_application.yaml")
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
@@ -121,17 +121,17 @@ func checkPodOutput(cmdOutput string, expectedPodCounts map[string]int, namespac
 	}
 
 	var actualPods []string
-	for _, line := range lines {
-		sanitizedLine := strings.TrimSpace(line)
-		if sanitizedLine == "" {
+	// skip the header of the table
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
 			continue
 		}
-
-		ns, pod, err := TestHelper.ParseNamespacedResource(sanitizedLine)
-		if err != nil {
-			return fmt.Errorf("Unexpected error: %v", err)
+		if len(fields) < 2 {
+			return fmt.Errorf("Expected the name and namespace of a pod, got [%s]", line)
 		}
 
+		pod, ns := fields[0], fields[1]
 		if ns == namespace {
 			podPrefix, err := parsePodPrefix(pod)
 
@@ -146,10 +146,7 @@ func TestCheckPostInstall(t *testing.T) {
 
 func TestDashboard(t *testing.T) {
 	dashboardPort := 52237
-	dashboardURL := fmt.Sprintf(
-		"http://127.0.0.1:%d/api/v1/namespaces/%s/services/web:http/proxy",
-		dashboardPort, TestHelper.GetLinkerdNamespace(),
-	)
+	dashboardURL := fmt.Sprintf("http://127.0.0.1:%d", dashboardPort)
 
 	outputStream, err := TestHelper.LinkerdRunStream("dashboard", "-p",
 		strconv.Itoa(dashboardPort), "--show", "url")
@@ -185,9 +182,9 @@ func TestInject(t *testing.T) {
 		cmd = append(cmd, []string{"--tls", "optional"}...)
 	}
 
-	out, err := TestHelper.LinkerdRun(cmd...)
+	out, err := TestHelper.LinkerdRunOutput(cmd...)
 	if err != nil {
-		t.Fatalf("linkerd inject command failed\n%s", out)
+		t.Fatalf("linkerd inject command failed: %s", err)
 	}
 
 	prefixedNs := TestHelper.GetTestNamespace("smoke-test")
@@ -73,9 +73,9 @@ var (
 //////////////////////
 
 func TestCliTap(t *testing.T) {
-	out, err := TestHelper.LinkerdRun("inject", "testdata/tap_application.yaml")
+	out, err := TestHelper.LinkerdRunOutput("inject", "testdata/tap_application.yaml")
 	if err != nil {
-		t.Fatalf("linkerd inject command failed\n%s", out)
+		t.Fatalf("linkerd inject command failed: %s", err)
 	}
 
 	prefixedNs := TestHelper.GetTestNamespace("tap-test")
@@ -134,6 +134,19 @@ func (h *TestHelper) LinkerdRun(arg ...string) (string, error) {
 	return h.CombinedOutput(h.linkerd, withNamespace...)
 }
 
+// LinkerdRunOutput is like LinkerdRun, but returns what the command writes to
+// stdout only, so that what it reports on stderr, as `linkerd inject` does,
+// is not mixed with its output. If the command fails, its stderr is returned
+// in the error.
+func (h *TestHelper) LinkerdRunOutput(arg ...string) (string, error) {
+	withNamespace := append(arg, "--linkerd-namespace", h.namespace)
+	bytes, err := exec.Command(h.linkerd, withNamespace...).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return string(bytes), fmt.Errorf("%s: %s", exitErr, exitErr.Stderr)
+	}
+	return string(bytes), err
+}
+
 // LinkerdRunStream initiates a linkerd command appended with the
 // --linkerd-namespace flag, and returns a Stream that can be used to read the
 // command's output while it is still executing.